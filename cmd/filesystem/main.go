@@ -2,15 +2,41 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
+	"github.com/portertech/filesystem-mcp-server/internal/confirm"
+	"github.com/portertech/filesystem-mcp-server/internal/dav"
+	"github.com/portertech/filesystem-mcp-server/internal/ffmpeg"
+	"github.com/portertech/filesystem-mcp-server/internal/grant"
+	"github.com/portertech/filesystem-mcp-server/internal/journal"
+	"github.com/portertech/filesystem-mcp-server/internal/membudget"
+	"github.com/portertech/filesystem-mcp-server/internal/mimetype"
+	"github.com/portertech/filesystem-mcp-server/internal/network"
+	"github.com/portertech/filesystem-mcp-server/internal/overlay"
+	"github.com/portertech/filesystem-mcp-server/internal/pathutil"
+	"github.com/portertech/filesystem-mcp-server/internal/perms"
+	"github.com/portertech/filesystem-mcp-server/internal/protect"
+	"github.com/portertech/filesystem-mcp-server/internal/quarantine"
 	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/reputation"
+	"github.com/portertech/filesystem-mcp-server/internal/retention"
+	"github.com/portertech/filesystem-mcp-server/internal/scan"
+	"github.com/portertech/filesystem-mcp-server/internal/security"
 	"github.com/portertech/filesystem-mcp-server/internal/server"
+	"github.com/portertech/filesystem-mcp-server/internal/shadow"
+	"github.com/portertech/filesystem-mcp-server/internal/statedir"
+	"github.com/portertech/filesystem-mcp-server/internal/tools"
+	"github.com/portertech/filesystem-mcp-server/internal/trash"
 )
 
 var version = "dev"
@@ -19,6 +45,72 @@ func main() {
 	verbose := flag.Bool("verbose", false, "Enable verbose logging")
 	showVersion := flag.Bool("version", false, "Print version and exit")
 	listDirs := flag.Bool("list", false, "List allowed directories and exit")
+	overlayDir := flag.String("overlay", "", "Enable overlay mode, staging all writes in this directory until committed")
+	quarantineDir := flag.String("quarantine", "", "Copy media files read by read_media_file into this directory and record their hashes before returning data")
+	scanCommand := flag.String("scan-command", "", "Scan media files with this command before returning data; the file path is appended, and exit status 1 means infected (e.g. \"clamscan --no-summary\")")
+	scanClamd := flag.String("scan-clamd", "", "Scan media files with a ClamAV daemon at unix:/path/to/clamd.sock or tcp:host:port before returning data")
+	scanAction := flag.String("scan-action", "block", "What to do with files the scanner flags: block or warn")
+	hashDenylist := flag.String("hash-denylist", "", "File of known-bad MD5, SHA-1, or SHA-256 hashes, one per line, checked before read tools return data")
+	hashAllowlist := flag.String("hash-allowlist", "", "File of known-good MD5, SHA-1, or SHA-256 hashes, one per line; listed media files skip the content scanner")
+	hashAction := flag.String("hash-action", "block", "What to do with denylisted files: block or warn")
+	shadowReads := flag.Bool("shadow-reads", false, "Serve whole-file reads of a file that changes while being read from its last committed version instead of a torn read")
+	shadowBytes := flag.Int64("shadow-cache-bytes", shadow.DefaultMaxBytes, "Total size of the last committed file versions kept for -shadow-reads")
+	confirmTools := flag.String("confirm", "", "Comma-separated tools whose destructive operations take a second call with a confirmation token, an advisory check that makes the agent ask the user first (approve_changes, copy_directory, copy_file, delete_directory, empty_trash, replace_directory, undo_operation)")
+	memoryBudget := flag.Int64("memory-budget", 0, "Bytes of file content that whole file reads may buffer at once across in-flight calls; reads that do not fit wait briefly, then are refused (0 disables)")
+	ffmpegPath := flag.String("ffmpeg", "", "Path to ffmpeg, letting read_media_file trim and resample audio other than PCM WAV and extract video frames (e.g. \"ffmpeg\" to find it on PATH)")
+	maxDeleteFiles := flag.Int("max-delete-files", 0, "Refuse to delete more than this many files in one call without force=true (0 disables)")
+	maxDeleteBytes := flag.Int64("max-delete-bytes", 0, "Refuse to delete more than this many bytes in one call without force=true (0 disables)")
+	stateDir := flag.String("state-dir", statedir.Default(), "Directory for persistent server state: bookmarks, annotations, saved searches, usage samples, and the -trash and -journal of allowed directories on the same filesystem; empty keeps state in memory only")
+	strictFilenames := flag.Bool("strict-filenames", false, "Refuse to create files or directories whose names are not portable across Linux, macOS, and Windows")
+	fileMode := flag.String("file-mode", "0644", "Permissions of files the tools create, in octal; existing files keep theirs")
+	dirMode := flag.String("dir-mode", "0755", "Permissions of directories the tools create, in octal")
+	umask := flag.String("umask", "", "Set the process umask, in octal, which also masks -file-mode and -dir-mode (e.g. 002 for group-writable files); empty keeps the inherited umask (Unix only)")
+	allowChown := flag.Bool("allow-chown", false, "Register the change_owner tool, letting clients change file ownership (Unix only)")
+	allowNetwork := flag.String("allow-network", "", "Comma-separated domains the fetch_to_file and upload_file tools may reach, with *.example.com for subdomains (default: no network access)")
+	networkMaxBytes := flag.Int64("network-max-bytes", network.DefaultMaxBytes, "Largest transfer -allow-network permits, in bytes")
+	useTrash := flag.Bool("trash", false, "Move files and directories deleted by delete_file and delete_directory into a .mcp-trash directory in their allowed directory, and register list_trash, restore_from_trash, and empty_trash")
+	trashMaxAge := flag.Duration("trash-max-age", 0, "Permanently delete -trash entries deleted longer ago than this (0 keeps them until emptied)")
+	trashMaxBytes := flag.Int64("trash-max-bytes", 0, "Permanently delete the oldest -trash entries once an allowed directory's trash exceeds this many bytes (0 disables)")
+	useJournal := flag.Bool("journal", false, "Record the prior state of files changed by write_file, write_file_range, edit_file, filter_file, move_file, delete_file, batch_operations, and apply_patch in a .mcp-journal directory in their allowed directory, and register list_operations and undo_operation")
+	journalMaxAge := flag.Duration("journal-max-age", 0, "Drop -journal operations older than this (0 keeps them until -journal-max-bytes is reached)")
+	journalMaxBytes := flag.Int64("journal-max-bytes", journal.DefaultMaxBytes, "Drop the oldest -journal operations once an allowed directory's snapshots exceed this many bytes (0 disables)")
+	pruneInterval := flag.Duration("prune-interval", time.Hour, "How often to delete recovery data outside the -trash-max-age, -trash-max-bytes, and -journal-max-age limits (0 disables)")
+	allowLogLevel := flag.Bool("allow-log-level", false, "Register the set_log_level tool, letting clients switch logging between info and debug at runtime")
+	aliases := make(map[string]string)
+	flag.Func("alias", "Alias for an allowed directory as name=dir, usable in paths as name:/relative/path (repeatable)", func(v string) error {
+		name, dir, ok := strings.Cut(v, "=")
+		if !ok || name == "" || dir == "" {
+			return fmt.Errorf("expected name=dir, got %q", v)
+		}
+		aliases[name] = dir
+		return nil
+	})
+	mimeTypes := make(map[string]string)
+	flag.Func("mime-type", "Serve files with this extension from read_media_file as this media type instead of sniffing their content, as .ext=type/subtype (repeatable)", func(v string) error {
+		ext, mediaType, err := mimetype.ParseOverride(v)
+		if err != nil {
+			return err
+		}
+		mimeTypes[ext] = mediaType
+		return nil
+	})
+	var readOnlyDirs []string
+	flag.Func("readonly", "Make an allowed directory read-only except under an operator-issued write grant (repeatable)", func(v string) error {
+		readOnlyDirs = append(readOnlyDirs, v)
+		return nil
+	})
+	var protectedDirs []string
+	flag.Func("protect", "Require a second, out-of-band approval token for deletes and moves affecting this subtree of an allowed directory (repeatable)", func(v string) error {
+		protectedDirs = append(protectedDirs, v)
+		return nil
+	})
+	grantKey := flag.String("grant-key", "", "Key file for signing and verifying write grants for -readonly directories; must be outside the allowed directories")
+	issueGrant := flag.String("issue-grant", "", "Print a write grant token for this read-only directory, signed with -grant-key, and exit")
+	grantTTL := flag.Duration("grant-ttl", 15*time.Minute, "How long a grant issued with -issue-grant lasts")
+	grantOps := flag.Int("grant-ops", 0, "Number of write operations a grant issued with -issue-grant allows (0 for no limit)")
+	grantReason := flag.String("grant-reason", "", "Reason recorded with a grant issued with -issue-grant")
+	usageInterval := flag.Duration("usage-interval", time.Hour, "How often to sample disk usage of the allowed directories (0 disables)")
+	webdavAddr := flag.String("webdav", "", "Also serve a read-only WebDAV view of the allowed directories at this address (e.g. 127.0.0.1:8080), for people to browse what the tools can see; it has no authentication")
 	flag.Parse()
 
 	if *showVersion {
@@ -26,9 +118,22 @@ func main() {
 		os.Exit(0)
 	}
 
-	level := slog.LevelInfo
+	if *issueGrant != "" {
+		if *grantKey == "" {
+			fmt.Fprintln(os.Stderr, "-issue-grant requires -grant-key")
+			os.Exit(1)
+		}
+		if err := printGrant(*grantKey, *issueGrant, *grantTTL, *grantOps, *grantReason); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// A LevelVar lets set_log_level change the level later
+	level := new(slog.LevelVar)
 	if *verbose {
-		level = slog.LevelDebug
+		level.Set(slog.LevelDebug)
 	}
 	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
 
@@ -46,6 +151,272 @@ func main() {
 		os.Exit(0)
 	}
 
+	// Set the umask before anything creates files
+	modes, err := parseModes(*fileMode, *dirMode, *umask)
+	if err != nil {
+		logger.Error("invalid permissions", "error", err)
+		os.Exit(1)
+	}
+	reg.SetModes(modes)
+	if flagSet("file-mode") || flagSet("dir-mode") || *umask != "" {
+		logger.Info("permissions of created files set", "fileMode", fmt.Sprintf("%04o", modes.File), "dirMode", fmt.Sprintf("%04o", modes.Dir))
+	}
+	if mapping, err := perms.DetectMapping(); err != nil {
+		logger.Debug("failed to read the user namespace mapping", "error", err)
+	} else {
+		if mapping.Namespaced {
+			logger.Info("running in a user namespace", "uid", mapping.UID, "hostUid", mapping.HostUID)
+		}
+		if warning := mapping.Warning(); warning != "" {
+			logger.Warn(warning)
+		}
+	}
+
+	if len(aliases) > 0 {
+		if err := reg.SetAliases(aliases); err != nil {
+			logger.Error("invalid alias", "error", err)
+			os.Exit(1)
+		}
+		for _, a := range reg.Aliases() {
+			logger.Info("root alias", "alias", a.Name, "dir", a.Dir)
+		}
+	}
+
+	if len(readOnlyDirs) > 0 {
+		if err := reg.SetReadOnly(readOnlyDirs); err != nil {
+			logger.Error("invalid read-only directory", "error", err)
+			os.Exit(1)
+		}
+		logger.Info("read-only directories", "dirs", readOnlyDirs)
+	}
+
+	if *grantKey != "" {
+		key, err := grant.LoadKey(*grantKey)
+		if err != nil {
+			logger.Error("failed to load grant key", "error", err)
+			os.Exit(1)
+		}
+		keyPath, err := pathutil.NormalizePath(*grantKey)
+		if err != nil || security.IsPathWithinAllowedDirectories(keyPath, reg.Get()) || security.IsPathWithinAllowedDirectories(keyPath, reg.GetResolved()) {
+			logger.Error("grant key must be outside the allowed directories", "path", *grantKey)
+			os.Exit(1)
+		}
+		reg.SetGrants(grant.NewManager(key))
+		logger.Info("write grants enabled")
+	}
+
+	if *overlayDir != "" {
+		ov, err := overlay.New(*overlayDir)
+		if err != nil {
+			logger.Error("failed to initialize overlay", "dir", *overlayDir, "error", err)
+			os.Exit(1)
+		}
+		if security.IsPathWithinAllowedDirectories(ov.Dir(), reg.GetResolved()) {
+			logger.Error("overlay directory must be outside the allowed directories", "dir", ov.Dir())
+			os.Exit(1)
+		}
+		reg.SetOverlay(ov)
+		logger.Info("overlay mode enabled", "dir", ov.Dir())
+	}
+
+	if *quarantineDir != "" {
+		q, err := quarantine.New(*quarantineDir)
+		if err != nil {
+			logger.Error("failed to initialize quarantine", "dir", *quarantineDir, "error", err)
+			os.Exit(1)
+		}
+		if security.IsPathWithinAllowedDirectories(q.Dir(), reg.GetResolved()) {
+			logger.Error("quarantine directory must be outside the allowed directories", "dir", q.Dir())
+			os.Exit(1)
+		}
+		reg.SetQuarantine(q)
+		logger.Info("media quarantine enabled", "dir", q.Dir())
+	}
+
+	var state *statedir.Dir
+	if *stateDir != "" {
+		var err error
+		state, err = statedir.Open(*stateDir, logger)
+		if err != nil {
+			logger.Error("failed to open state directory", "dir", *stateDir, "error", err)
+			os.Exit(1)
+		}
+		if security.IsPathWithinAllowedDirectories(state.Path(), reg.Get()) || security.IsPathWithinAllowedDirectories(state.Path(), reg.GetResolved()) {
+			if flagSet("state-dir") {
+				logger.Error("state directory must be outside the allowed directories", "dir", state.Path())
+				os.Exit(1)
+			}
+			// Only the default is in an allowed directory, e.g. the home
+			// directory, so run as if none were set
+			logger.Warn("default state directory is inside an allowed directory, keeping state in memory; set -state-dir to persist it", "dir", state.Path())
+			state = nil
+			*stateDir = ""
+		} else {
+			logger.Info("state directory", "dir", state.Path(), "version", statedir.Version)
+		}
+	}
+
+	if *scanCommand != "" || *scanClamd != "" {
+		if *scanCommand != "" && *scanClamd != "" {
+			logger.Error("-scan-command and -scan-clamd are mutually exclusive")
+			os.Exit(1)
+		}
+		if *scanAction != "block" && *scanAction != "warn" {
+			logger.Error("invalid scan action, expected block or warn", "action", *scanAction)
+			os.Exit(1)
+		}
+		var scanner scan.Scanner
+		var err error
+		if *scanCommand != "" {
+			scanner, err = scan.NewCommand(*scanCommand)
+		} else {
+			scanner, err = scan.NewClamd(*scanClamd)
+		}
+		if err != nil {
+			logger.Error("invalid content scanner", "error", err)
+			os.Exit(1)
+		}
+		reg.SetScanner(scanner, *scanAction == "block")
+		logger.Info("content scanning enabled", "action", *scanAction)
+	}
+
+	if *hashDenylist != "" || *hashAllowlist != "" {
+		if *hashAction != "block" && *hashAction != "warn" {
+			logger.Error("invalid hash action, expected block or warn", "action", *hashAction)
+			os.Exit(1)
+		}
+		lists, err := reputation.Load(*hashDenylist, *hashAllowlist)
+		if err != nil {
+			logger.Error("failed to load hash lists", "error", err)
+			os.Exit(1)
+		}
+		reg.SetReputation(lists, *hashAction == "block")
+		deny, allow := lists.Len()
+		logger.Info("hash reputation enabled", "denylisted", deny, "allowlisted", allow, "action", *hashAction)
+	}
+
+	if *shadowReads {
+		if *shadowBytes <= 0 {
+			logger.Error("-shadow-cache-bytes must be positive", "bytes", *shadowBytes)
+			os.Exit(1)
+		}
+		reg.SetShadow(shadow.New(*shadowBytes))
+		logger.Info("shadow reads enabled", "cacheBytes", *shadowBytes)
+	}
+
+	if *memoryBudget < 0 {
+		logger.Error("-memory-budget must not be negative", "bytes", *memoryBudget)
+		os.Exit(1)
+	}
+	if *memoryBudget > 0 {
+		reg.SetMemoryBudget(membudget.New(*memoryBudget, membudget.DefaultMaxWait))
+		logger.Info("memory budget enabled", "bytes", *memoryBudget)
+	}
+
+	if *ffmpegPath != "" {
+		ff, err := ffmpeg.New(*ffmpegPath)
+		if err != nil {
+			logger.Error("invalid -ffmpeg", "error", err)
+			os.Exit(1)
+		}
+		reg.SetFFmpeg(ff)
+		logger.Info("ffmpeg enabled", "path", ff.Path)
+	}
+
+	if *allowNetwork != "" {
+		access, err := network.New(strings.Split(*allowNetwork, ","), *networkMaxBytes, func(t network.Transfer) {
+			if t.Err != nil {
+				logger.Warn("network transfer failed", "direction", t.Direction, "url", t.URL, "path", t.Path, "bytes", t.Bytes, "status", t.Status, "error", t.Err)
+				return
+			}
+			logger.Info("network transfer", "direction", t.Direction, "url", t.URL, "path", t.Path, "bytes", t.Bytes, "status", t.Status)
+		})
+		if err != nil {
+			logger.Error("invalid -allow-network", "error", err)
+			os.Exit(1)
+		}
+		reg.SetNetwork(access)
+		logger.Info("network access enabled", "domains", access.Domains(), "maxBytes", access.MaxBytes())
+	}
+
+	if *useTrash {
+		if *overlayDir != "" {
+			logger.Error("-trash cannot be combined with -overlay, whose deletes are already staged")
+			os.Exit(1)
+		}
+		reg.SetTrash(trash.New(retention.Policy{MaxAge: *trashMaxAge, MaxBytes: *trashMaxBytes}, state.Locator("trash", trash.DirName)))
+		logger.Info("trash enabled", "maxAge", *trashMaxAge, "maxBytes", *trashMaxBytes)
+	}
+
+	if *useJournal {
+		if *overlayDir != "" {
+			logger.Error("-journal cannot be combined with -overlay, whose changes are already staged")
+			os.Exit(1)
+		}
+		reg.SetJournal(journal.New(retention.Policy{MaxAge: *journalMaxAge, MaxBytes: *journalMaxBytes}, state.Locator("journal", journal.DirName)))
+		logger.Info("undo journal enabled", "maxAge", *journalMaxAge, "maxBytes", *journalMaxBytes)
+	}
+
+	if len(mimeTypes) > 0 {
+		reg.SetMIMETypes(mimeTypes)
+	}
+
+	reg.SetLimits(registry.Limits{
+		MaxDeleteFiles: *maxDeleteFiles,
+		MaxDeleteBytes: *maxDeleteBytes,
+	})
+
+	if *strictFilenames {
+		reg.SetStrictFilenames(true)
+		logger.Info("strict filenames enabled")
+	}
+
+	if *allowChown {
+		if !tools.ChownSupported {
+			logger.Error("-allow-chown is only supported on Unix")
+			os.Exit(1)
+		}
+		reg.SetAllowChown(true)
+		logger.Info("ownership changes enabled")
+	}
+
+	if *confirmTools != "" {
+		var names []string
+		for _, name := range strings.Split(*confirmTools, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if _, ok := tools.ConfirmableTools[name]; !ok {
+				logger.Error("tool does not support confirmation", "tool", name)
+				os.Exit(1)
+			}
+			names = append(names, name)
+		}
+		reg.SetConfirmations(confirm.New(names, confirm.DefaultTTL))
+		logger.Info("confirmation required", "tools", names)
+	}
+
+	if len(protectedDirs) > 0 {
+		// Approval tokens go to the operator's log only; tool results
+		// never include them.
+		guard, err := protect.New(protectedDirs, confirm.DefaultTTL, func(r protect.Request) {
+			logger.Warn("approval required", "tool", r.Tool, "action", r.Action, "paths", r.Paths, "approvalToken", r.Token, "expires", r.Expires)
+		})
+		if err != nil {
+			logger.Error("invalid protected path", "error", err)
+			os.Exit(1)
+		}
+		for _, dir := range guard.Dirs() {
+			if !security.IsPathWithinAllowedDirectories(dir, reg.Get()) && !security.IsPathWithinAllowedDirectories(dir, reg.GetResolved()) {
+				logger.Error("protected path must be within the allowed directories", "path", dir)
+				os.Exit(1)
+			}
+		}
+		reg.SetProtection(guard)
+		logger.Info("protected paths", "dirs", guard.Dirs())
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -57,9 +428,121 @@ func main() {
 		cancel()
 	}()
 
-	srv := server.New(reg, logger)
+	if *webdavAddr != "" {
+		if err := serveWebDAV(ctx, *webdavAddr, reg, logger); err != nil {
+			logger.Error("failed to start WebDAV", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	if state != nil {
+		defer state.Close()
+		for _, dir := range reg.GetResolved() {
+			others, err := state.Register(dir)
+			if err != nil {
+				logger.Warn("failed to check for other server instances", "dir", dir, "error", err)
+				continue
+			}
+			if others > 0 {
+				logger.Warn("other server instances are serving this directory; the trash and undo journal are shared safely, but concurrent changes to the same files can overwrite each other", "dir", dir, "instances", others)
+			}
+		}
+	}
+
+	opts := []server.Option{
+		server.WithStateDir(*stateDir),
+		server.WithUsageInterval(*usageInterval),
+		server.WithPruneInterval(*pruneInterval),
+		server.WithVersion(version),
+	}
+	if *allowLogLevel {
+		opts = append(opts, server.WithLogLevel(level))
+		logger.Info("runtime log level changes enabled")
+	}
+	srv := server.New(reg, logger, opts...)
 	if err := srv.Run(ctx); err != nil {
 		logger.Error("server error", "error", err)
 		os.Exit(1)
 	}
 }
+
+// serveWebDAV serves the read-only WebDAV view on addr until ctx is done.
+func serveWebDAV(ctx context.Context, addr string, reg *registry.Registry, logger *slog.Logger) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	host, _, _ := net.SplitHostPort(addr)
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		logger.Warn("WebDAV view is reachable from other hosts and has no authentication", "addr", ln.Addr().String())
+	}
+
+	srv := &http.Server{Handler: dav.Handler(reg, logger, addr), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			logger.Error("WebDAV server error", "error", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+	logger.Info("WebDAV view enabled", "addr", ln.Addr().String())
+	return nil
+}
+
+// printGrant issues a write grant token for dir and prints it to stdout.
+func printGrant(keyPath, dir string, ttl time.Duration, maxOps int, reason string) error {
+	key, err := grant.LoadKey(keyPath)
+	if err != nil {
+		return err
+	}
+	root, err := pathutil.NormalizePath(dir)
+	if err != nil {
+		return err
+	}
+	token, g, err := grant.Issue(key, root, ttl, maxOps, reason)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "grant %s for %s expires %s\n", g.ID, g.Root, g.Expires.Local().Format(time.RFC3339))
+	fmt.Println(token)
+	return nil
+}
+
+// parseModes parses -file-mode and -dir-mode, and sets the process umask
+// to umask unless it is empty. Tools that set a file's mode explicitly
+// bypass the umask, so the modes returned are masked with it.
+func parseModes(fileMode, dirMode, umask string) (registry.Modes, error) {
+	file, err := perms.ParseMode(fileMode)
+	if err != nil {
+		return registry.Modes{}, fmt.Errorf("-file-mode: %w", err)
+	}
+	dir, err := perms.ParseMode(dirMode)
+	if err != nil {
+		return registry.Modes{}, fmt.Errorf("-dir-mode: %w", err)
+	}
+	if umask != "" {
+		if !perms.UmaskSupported {
+			return registry.Modes{}, errors.New("-umask is only supported on Unix")
+		}
+		mask, err := perms.ParseMode(umask)
+		if err != nil {
+			return registry.Modes{}, fmt.Errorf("-umask: %w", err)
+		}
+		perms.SetUmask(mask)
+	}
+	mask := perms.Umask()
+	return registry.Modes{File: file &^ mask, Dir: dir &^ mask}, nil
+}
+
+// flagSet reports whether the named flag was given on the command line.
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
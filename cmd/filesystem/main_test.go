@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/portertech/filesystem-mcp-server/internal/perms"
 )
 
 func TestMain(m *testing.M) {
@@ -139,3 +141,29 @@ func TestInvalidFlag(t *testing.T) {
 		}
 	}
 }
+
+func TestInvalidFileMode(t *testing.T) {
+	bin := binaryPath(t)
+	cmd := exec.Command(bin, "-file-mode", "0999", "-state-dir", "", t.TempDir())
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatal("expected error for invalid -file-mode")
+	}
+	if !strings.Contains(string(output), "-file-mode") {
+		t.Errorf("expected the error to name -file-mode, got: %s", output)
+	}
+}
+
+func TestParseModes(t *testing.T) {
+	modes, err := parseModes("0666", "0777", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Without -umask, the inherited umask still masks the modes
+	if want := os.FileMode(0666) &^ perms.Umask(); modes.File != want {
+		t.Errorf("file mode %o, want %o", modes.File, want)
+	}
+	if _, err := parseModes("0644", "rwx", ""); err == nil {
+		t.Error("expected error for invalid -dir-mode")
+	}
+}
@@ -0,0 +1,176 @@
+// Package annotation stores short notes and tags attached to paths, so agents
+// can leave breadcrumbs that survive across calls and sessions. Annotations
+// are kept in a JSON state file when a path is configured, and in memory
+// otherwise.
+package annotation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// MaxNoteLength is the longest note accepted, in bytes.
+	MaxNoteLength = 4096
+	// MaxTags is the most tags one path may carry.
+	MaxTags = 32
+	// MaxTagLength is the longest tag accepted, in bytes.
+	MaxTagLength = 64
+)
+
+// Annotation is the note and tags attached to one path.
+type Annotation struct {
+	Path    string    `json:"path"`
+	Note    string    `json:"note,omitempty"`
+	Tags    []string  `json:"tags,omitempty"`
+	Updated time.Time `json:"updated"`
+}
+
+// HasTag reports whether a carries tag.
+func (a Annotation) HasTag(tag string) bool {
+	for _, t := range a.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Store holds annotations keyed by path.
+type Store struct {
+	mu          sync.Mutex
+	path        string
+	annotations map[string]Annotation
+}
+
+// NewStore creates a store persisting to path, loading any annotations
+// already stored there. An empty path keeps annotations in memory only.
+func NewStore(path string) (*Store, error) {
+	s := &Store{
+		path:        path,
+		annotations: make(map[string]Annotation),
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.annotations); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Set replaces the annotation for path. Tags are trimmed, de-duplicated, and
+// sorted. Setting an empty note and no tags removes the annotation.
+func (s *Store) Set(path, note string, tags []string) (Annotation, error) {
+	if len(note) > MaxNoteLength {
+		return Annotation{}, fmt.Errorf("note exceeds %d bytes", MaxNoteLength)
+	}
+	tags, err := normalizeTags(tags)
+	if err != nil {
+		return Annotation{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := Annotation{Path: path, Note: note, Tags: tags, Updated: time.Now().UTC()}
+	if note == "" && len(tags) == 0 {
+		delete(s.annotations, path)
+	} else {
+		s.annotations[path] = a
+	}
+	return a, s.saveLocked()
+}
+
+// Remove deletes the annotation for path, reporting whether there was one.
+func (s *Store) Remove(path string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.annotations[path]; !ok {
+		return false, nil
+	}
+	delete(s.annotations, path)
+	return true, s.saveLocked()
+}
+
+// Get returns the annotation for path.
+func (s *Store) Get(path string) (Annotation, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.annotations[path]
+	return a, ok
+}
+
+// List returns the annotations for dir and everything beneath it, sorted by
+// path. An empty dir lists all annotations.
+func (s *Store) List(dir string) []Annotation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prefix := strings.TrimSuffix(dir, string(filepath.Separator)) + string(filepath.Separator)
+	var result []Annotation
+	for path, a := range s.annotations {
+		if dir == "" || path == dir || strings.HasPrefix(path, prefix) {
+			result = append(result, a)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+	return result
+}
+
+// saveLocked writes the state file atomically.
+func (s *Store) saveLocked() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(s.annotations)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func normalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var result []string
+	for _, t := range tags {
+		t = strings.TrimSpace(t)
+		if t == "" || seen[t] {
+			continue
+		}
+		if len(t) > MaxTagLength {
+			return nil, fmt.Errorf("tag %q exceeds %d bytes", t, MaxTagLength)
+		}
+		seen[t] = true
+		result = append(result, t)
+	}
+	if len(result) > MaxTags {
+		return nil, fmt.Errorf("at most %d tags are allowed", MaxTags)
+	}
+	sort.Strings(result)
+	return result, nil
+}
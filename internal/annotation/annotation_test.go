@@ -0,0 +1,91 @@
+package annotation
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStorePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "annotations.json")
+
+	s, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Set("/root/a.go", "looked fine", []string{"reviewed", " reviewed ", "", "api"}); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, ok := reopened.Get("/root/a.go")
+	if !ok {
+		t.Fatal("annotation not persisted")
+	}
+	if a.Note != "looked fine" || !reflect.DeepEqual(a.Tags, []string{"api", "reviewed"}) {
+		t.Errorf("unexpected annotation: %+v", a)
+	}
+	if !a.HasTag("api") || a.HasTag("todo") {
+		t.Errorf("HasTag mismatch for %v", a.Tags)
+	}
+}
+
+func TestStoreSetEmptyRemoves(t *testing.T) {
+	s, _ := NewStore("")
+	if _, err := s.Set("/root/a.go", "note", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Set("/root/a.go", "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.Get("/root/a.go"); ok {
+		t.Error("expected annotation to be removed")
+	}
+
+	removed, err := s.Remove("/root/a.go")
+	if err != nil || removed {
+		t.Errorf("Remove of missing annotation = %v, %v", removed, err)
+	}
+}
+
+func TestStoreLimits(t *testing.T) {
+	s, _ := NewStore("")
+	if _, err := s.Set("/a", strings.Repeat("x", MaxNoteLength+1), nil); err == nil {
+		t.Error("expected error for long note")
+	}
+	if _, err := s.Set("/a", "", []string{strings.Repeat("t", MaxTagLength+1)}); err == nil {
+		t.Error("expected error for long tag")
+	}
+	tags := make([]string, MaxTags+1)
+	for i := range tags {
+		tags[i] = strings.Repeat("t", i+1)
+	}
+	if _, err := s.Set("/a", "", tags); err == nil {
+		t.Error("expected error for too many tags")
+	}
+}
+
+func TestStoreList(t *testing.T) {
+	s, _ := NewStore("")
+	for _, p := range []string{"/root/src/b.go", "/root/src", "/root/srcx/c.go", "/root/src/a.go", "/other/d.go"} {
+		if _, err := s.Set(p, "n", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var got []string
+	for _, a := range s.List("/root/src") {
+		got = append(got, a.Path)
+	}
+	want := []string{"/root/src", "/root/src/a.go", "/root/src/b.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("List = %v, want %v", got, want)
+	}
+	if n := len(s.List("")); n != 5 {
+		t.Errorf("List(\"\") returned %d annotations, want 5", n)
+	}
+}
@@ -0,0 +1,183 @@
+// Package audio cuts clips out of audio files so an agent can fetch just the
+// part it needs. PCM WAV files are trimmed natively; other formats, and
+// resampling, go through the ffmpeg package when the operator has configured
+// it.
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ErrNeedsFFmpeg is returned by TrimWAV for requests it cannot handle
+// natively, such as resampling or compressed WAV data.
+var ErrNeedsFFmpeg = errors.New("requires ffmpeg")
+
+// Clip selects part of an audio file.
+type Clip struct {
+	// Start is the offset of the clip in seconds.
+	Start float64
+	// Duration is the length of the clip in seconds; zero runs to the end.
+	Duration float64
+	// SampleRate resamples the clip to this rate in Hz; zero keeps the
+	// original rate.
+	SampleRate int
+	// MaxBytes caps the size of the encoded clip.
+	MaxBytes int64
+}
+
+// WAV format codes that TrimWAV can cut at any sample frame.
+const (
+	formatPCM        = 1
+	formatFloat      = 3
+	formatExtensible = 0xFFFE
+)
+
+// WAVInfo describes a WAV file's format and where its samples are.
+type WAVInfo struct {
+	Format        uint16
+	Channels      int
+	SampleRate    int
+	ByteRate      int
+	BlockAlign    int
+	BitsPerSample int
+	// DataOffset and DataSize locate the sample data in the file.
+	DataOffset int64
+	DataSize   int64
+	// fmtChunk is the raw fmt chunk, copied into trimmed files.
+	fmtChunk []byte
+}
+
+// Duration returns the length of the audio in seconds.
+func (w WAVInfo) Duration() float64 {
+	if w.ByteRate == 0 {
+		return 0
+	}
+	return float64(w.DataSize) / float64(w.ByteRate)
+}
+
+// ParseWAV reads the RIFF header of a WAV file.
+func ParseWAV(r io.ReadSeeker) (WAVInfo, error) {
+	var header [12]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return WAVInfo{}, fmt.Errorf("not a WAV file: %w", err)
+	}
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return WAVInfo{}, errors.New("not a WAV file")
+	}
+
+	var info WAVInfo
+	offset := int64(12)
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			return WAVInfo{}, errors.New("WAV file has no data chunk")
+		}
+		id, size := string(chunk[0:4]), int64(binary.LittleEndian.Uint32(chunk[4:8]))
+		offset += 8
+		switch id {
+		case "fmt ":
+			if size < 16 || size > 1024 {
+				return WAVInfo{}, fmt.Errorf("invalid WAV fmt chunk of %d bytes", size)
+			}
+			info.fmtChunk = make([]byte, size)
+			if _, err := io.ReadFull(r, info.fmtChunk); err != nil {
+				return WAVInfo{}, fmt.Errorf("failed to read WAV format: %w", err)
+			}
+			f := info.fmtChunk
+			info.Format = binary.LittleEndian.Uint16(f[0:2])
+			info.Channels = int(binary.LittleEndian.Uint16(f[2:4]))
+			info.SampleRate = int(binary.LittleEndian.Uint32(f[4:8]))
+			info.ByteRate = int(binary.LittleEndian.Uint32(f[8:12]))
+			info.BlockAlign = int(binary.LittleEndian.Uint16(f[12:14]))
+			info.BitsPerSample = int(binary.LittleEndian.Uint16(f[14:16]))
+			if size%2 == 1 {
+				if _, err := r.Seek(1, io.SeekCurrent); err != nil {
+					return WAVInfo{}, err
+				}
+			}
+		case "data":
+			if info.fmtChunk == nil {
+				return WAVInfo{}, errors.New("WAV data chunk precedes its fmt chunk")
+			}
+			info.DataOffset, info.DataSize = offset, size
+			return info, nil
+		default:
+			// Chunks are padded to an even length
+			if _, err := r.Seek(size+size%2, io.SeekCurrent); err != nil {
+				return WAVInfo{}, err
+			}
+		}
+		offset += size + size%2
+	}
+}
+
+// TrimWAV returns the clip of the WAV file at path as a WAV file of its own,
+// cut at sample frame boundaries. It returns ErrNeedsFFmpeg for compressed
+// data or when the clip asks for resampling.
+func TrimWAV(path string, clip Clip) ([]byte, WAVInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, WAVInfo{}, err
+	}
+	defer f.Close()
+
+	info, err := ParseWAV(f)
+	if err != nil {
+		return nil, WAVInfo{}, err
+	}
+	if clip.SampleRate != 0 && clip.SampleRate != info.SampleRate {
+		return nil, info, fmt.Errorf("resampling %w", ErrNeedsFFmpeg)
+	}
+	if info.Format != formatPCM && info.Format != formatFloat && info.Format != formatExtensible {
+		return nil, info, fmt.Errorf("WAV format %#x %w", info.Format, ErrNeedsFFmpeg)
+	}
+	if info.BlockAlign == 0 || info.ByteRate == 0 {
+		return nil, info, errors.New("invalid WAV format")
+	}
+
+	// Some writers leave the data size unset when streaming
+	if st, err := f.Stat(); err == nil {
+		info.DataSize = min(info.DataSize, st.Size()-info.DataOffset)
+	}
+	frames := info.DataSize / int64(info.BlockAlign)
+	start := int64(clip.Start * float64(info.SampleRate))
+	if start >= frames {
+		return nil, info, fmt.Errorf("start %gs is past the end of the audio (%.3fs)", clip.Start, info.Duration())
+	}
+	count := frames - start
+	if clip.Duration > 0 {
+		count = min(count, int64(clip.Duration*float64(info.SampleRate)+0.5))
+	}
+	size := count * int64(info.BlockAlign)
+	if clip.MaxBytes > 0 && size > clip.MaxBytes {
+		return nil, info, fmt.Errorf("clip is %d bytes, more than the %d allowed", size, clip.MaxBytes)
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(int(44 + size))
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(4+8+len(info.fmtChunk)+len(info.fmtChunk)%2+8+int(size)))
+	buf.WriteString("WAVEfmt ")
+	binary.Write(&buf, binary.LittleEndian, uint32(len(info.fmtChunk)))
+	buf.Write(info.fmtChunk)
+	if len(info.fmtChunk)%2 == 1 {
+		buf.WriteByte(0)
+	}
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(size))
+	if _, err := f.Seek(info.DataOffset+start*int64(info.BlockAlign), io.SeekStart); err != nil {
+		return nil, info, err
+	}
+	if _, err := io.CopyN(&buf, f, size); err != nil {
+		return nil, info, fmt.Errorf("failed to read samples: %w", err)
+	}
+
+	info.DataOffset = int64(buf.Len()) - size
+	info.DataSize = size
+	return buf.Bytes(), info, nil
+}
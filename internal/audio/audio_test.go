@@ -0,0 +1,83 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeWAV writes a mono 16-bit PCM WAV file at rate Hz whose nth sample is
+// n, with a LIST chunk before the data.
+func writeWAV(t *testing.T, path string, rate, samples int) {
+	t.Helper()
+	var fmtChunk bytes.Buffer
+	binary.Write(&fmtChunk, binary.LittleEndian, []uint16{formatPCM, 1})
+	binary.Write(&fmtChunk, binary.LittleEndian, []uint32{uint32(rate), uint32(rate * 2)})
+	binary.Write(&fmtChunk, binary.LittleEndian, []uint16{2, 16})
+
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(4+8+16+8+3+1+8+samples*2))
+	buf.WriteString("WAVEfmt ")
+	binary.Write(&buf, binary.LittleEndian, uint32(16))
+	buf.Write(fmtChunk.Bytes())
+	buf.WriteString("LIST")
+	binary.Write(&buf, binary.LittleEndian, uint32(3))
+	buf.WriteString("abc\x00")
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(samples*2))
+	for n := range samples {
+		binary.Write(&buf, binary.LittleEndian, int16(n))
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTrimWAV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tone.wav")
+	writeWAV(t, path, 1000, 3000)
+
+	data, info, err := TrimWAV(path, Clip{Start: 1, Duration: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.DataSize != 1000 || info.Duration() != 0.5 {
+		t.Errorf("clip is %d bytes, %gs", info.DataSize, info.Duration())
+	}
+
+	// The clip is a valid WAV file of its own
+	parsed, err := ParseWAV(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.SampleRate != 1000 || parsed.DataSize != 1000 || parsed.DataOffset+parsed.DataSize != int64(len(data)) {
+		t.Errorf("unexpected clip %+v of %d bytes", parsed, len(data))
+	}
+	if got := binary.LittleEndian.Uint32(data[4:8]); int(got) != len(data)-8 {
+		t.Errorf("RIFF size %d, want %d", got, len(data)-8)
+	}
+	first := int16(binary.LittleEndian.Uint16(data[parsed.DataOffset:]))
+	last := int16(binary.LittleEndian.Uint16(data[len(data)-2:]))
+	if first != 1000 || last != 1499 {
+		t.Errorf("clip holds samples %d to %d, want 1000 to 1499", first, last)
+	}
+
+	// Without a duration the clip runs to the end
+	if _, info, err := TrimWAV(path, Clip{Start: 2.5}); err != nil || info.DataSize != 1000 {
+		t.Errorf("open-ended clip: %d bytes, %v", info.DataSize, err)
+	}
+	if _, _, err := TrimWAV(path, Clip{Start: 3}); err == nil || !strings.Contains(err.Error(), "past the end") {
+		t.Errorf("start past end: got %v", err)
+	}
+	if _, _, err := TrimWAV(path, Clip{SampleRate: 8000}); !errors.Is(err, ErrNeedsFFmpeg) {
+		t.Errorf("resampling: got %v, want ErrNeedsFFmpeg", err)
+	}
+	if _, _, err := TrimWAV(path, Clip{MaxBytes: 100}); err == nil {
+		t.Error("expected the size cap to refuse the clip")
+	}
+}
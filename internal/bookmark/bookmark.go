@@ -0,0 +1,142 @@
+// Package bookmark stores named shortcuts to frequently used directories, so
+// tool inputs can say @name instead of a deep path. Bookmarks are kept in a
+// JSON state file when a path is configured, and in memory otherwise.
+package bookmark
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxNameLength is the longest bookmark name accepted, in bytes.
+const MaxNameLength = 64
+
+// ErrNotFound is returned for a name with no bookmark.
+var ErrNotFound = errors.New("bookmark not found")
+
+// namePattern matches bookmark names, which appear in paths as @name.
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// Bookmark is a named shortcut to a directory.
+type Bookmark struct {
+	Name    string    `json:"name"`
+	Path    string    `json:"path"`
+	Created time.Time `json:"created"`
+}
+
+// Store holds bookmarks keyed by name.
+type Store struct {
+	mu        sync.Mutex
+	path      string
+	bookmarks map[string]Bookmark
+}
+
+// NewStore creates a store persisting to path, loading any bookmarks already
+// stored there. An empty path keeps bookmarks in memory only.
+func NewStore(path string) (*Store, error) {
+	s := &Store{
+		path:      path,
+		bookmarks: make(map[string]Bookmark),
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.bookmarks); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Add stores a bookmark from name to dir, replacing any bookmark of that
+// name. A leading @ on name is ignored.
+func (s *Store) Add(name, dir string) (Bookmark, error) {
+	name = strings.TrimPrefix(strings.TrimSpace(name), "@")
+	if name == "" {
+		return Bookmark{}, errors.New("name is required")
+	}
+	if len(name) > MaxNameLength {
+		return Bookmark{}, fmt.Errorf("name exceeds %d bytes", MaxNameLength)
+	}
+	if !namePattern.MatchString(name) {
+		return Bookmark{}, fmt.Errorf("invalid name %q: use letters, digits, '.', '-' and '_'", name)
+	}
+	b := Bookmark{Name: name, Path: dir, Created: time.Now().UTC()}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.bookmarks[name] = b
+	return b, s.saveLocked()
+}
+
+// Lookup returns the directory bookmarked under name.
+func (s *Store) Lookup(name string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.bookmarks[name]
+	return b.Path, ok
+}
+
+// Delete removes the bookmark saved under name.
+func (s *Store) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name = strings.TrimPrefix(strings.TrimSpace(name), "@")
+	if _, ok := s.bookmarks[name]; !ok {
+		return ErrNotFound
+	}
+	delete(s.bookmarks, name)
+	return s.saveLocked()
+}
+
+// List returns all bookmarks sorted by name.
+func (s *Store) List() []Bookmark {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]Bookmark, 0, len(s.bookmarks))
+	for _, b := range s.bookmarks {
+		result = append(result, b)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// saveLocked writes the state file atomically.
+func (s *Store) saveLocked() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(s.bookmarks)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,69 @@
+package bookmark
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStorePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "bookmarks.json")
+
+	s, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Add(" @tests ", "/repo/internal/service/tests"); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, ok := reopened.Lookup("tests")
+	if !ok || dir != "/repo/internal/service/tests" {
+		t.Errorf("Lookup(tests) = %q, %v", dir, ok)
+	}
+
+	if err := reopened.Delete("@tests"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := reopened.Lookup("tests"); ok {
+		t.Error("bookmark still present after delete")
+	}
+	if err := reopened.Delete("tests"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+	}
+}
+
+func TestStoreAddValidatesName(t *testing.T) {
+	s, _ := NewStore("")
+	for _, name := range []string{"", "@", "a/b", "-x", "with space", strings.Repeat("n", MaxNameLength+1)} {
+		if _, err := s.Add(name, "/dir"); err == nil {
+			t.Errorf("expected error for name %q", name)
+		}
+	}
+	for _, name := range []string{"tests", "api-v2", "pkg.util", "a_b"} {
+		if _, err := s.Add(name, "/dir"); err != nil {
+			t.Errorf("unexpected error for name %q: %v", name, err)
+		}
+	}
+}
+
+func TestStoreList(t *testing.T) {
+	s, _ := NewStore("")
+	for _, name := range []string{"b", "a", "c"} {
+		if _, err := s.Add(name, "/"+name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var names []string
+	for _, b := range s.List() {
+		names = append(names, b.Name)
+	}
+	if strings.Join(names, ",") != "a,b,c" {
+		t.Errorf("List() names = %v, want sorted", names)
+	}
+}
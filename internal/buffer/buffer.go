@@ -0,0 +1,146 @@
+// Package buffer holds named blobs in memory between tool calls, like a
+// clipboard, so content can move from one file to another without passing
+// through the client. Buffers expire after a TTL, and both each buffer and
+// all buffers together are limited in size.
+package buffer
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTTL is how long a buffer is kept after it was last filled.
+	DefaultTTL = time.Hour
+	// DefaultMaxBytes is the size limit of a single buffer.
+	DefaultMaxBytes = 10 << 20
+	// DefaultMaxTotalBytes is the size limit of all buffers together.
+	DefaultMaxTotalBytes = 100 << 20
+	// maxNameLength is the longest buffer name accepted.
+	maxNameLength = 128
+)
+
+// Sentinel errors returned by Store.
+var (
+	ErrNotFound = errors.New("buffer not found")
+	ErrExpired  = errors.New("buffer has expired")
+)
+
+// Buffer is a named blob.
+type Buffer struct {
+	Name    string
+	Content []byte
+	// Source describes where the content came from, such as a path and
+	// line range.
+	Source  string
+	Created time.Time
+	Expires time.Time
+}
+
+// Store holds buffers in memory.
+type Store struct {
+	mu       sync.Mutex
+	buffers  map[string]*Buffer
+	ttl      time.Duration
+	maxBytes int64
+	maxTotal int64
+	now      func() time.Time
+}
+
+// NewStore creates a store whose buffers expire after ttl, hold at most
+// maxBytes each, and at most maxTotal together.
+func NewStore(ttl time.Duration, maxBytes, maxTotal int64) *Store {
+	return &Store{
+		buffers:  make(map[string]*Buffer),
+		ttl:      ttl,
+		maxBytes: maxBytes,
+		maxTotal: maxTotal,
+		now:      time.Now,
+	}
+}
+
+// MaxBytes returns the size limit of a single buffer.
+func (s *Store) MaxBytes() int64 {
+	return s.maxBytes
+}
+
+// Put stores content under name, replacing any buffer of that name.
+func (s *Store) Put(name string, content []byte, source string) (*Buffer, error) {
+	if name == "" || len(name) > maxNameLength {
+		return nil, fmt.Errorf("buffer name must be 1-%d bytes", maxNameLength)
+	}
+	size := int64(len(content))
+	if size > s.maxBytes {
+		return nil, fmt.Errorf("content is %d bytes, over the buffer limit of %d bytes", size, s.maxBytes)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pruneLocked()
+
+	total := size
+	for n, b := range s.buffers {
+		if n != name {
+			total += int64(len(b.Content))
+		}
+	}
+	if total > s.maxTotal {
+		return nil, fmt.Errorf("buffers would hold %d bytes, over the limit of %d bytes; paste or let other buffers expire first", total, s.maxTotal)
+	}
+
+	now := s.now()
+	b := &Buffer{
+		Name:    name,
+		Content: content,
+		Source:  source,
+		Created: now,
+		Expires: now.Add(s.ttl),
+	}
+	s.buffers[name] = b
+	return b, nil
+}
+
+// Get returns the buffer with the given name.
+func (s *Store) Get(name string) (*Buffer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.buffers[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if s.now().After(b.Expires) {
+		delete(s.buffers, name)
+		return nil, ErrExpired
+	}
+	return b, nil
+}
+
+// List returns the buffers, by name.
+func (s *Store) List() []*Buffer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pruneLocked()
+
+	list := make([]*Buffer, 0, len(s.buffers))
+	for _, b := range s.buffers {
+		list = append(list, b)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// pruneLocked drops expired buffers.
+func (s *Store) pruneLocked() {
+	now := s.now()
+	for name, b := range s.buffers {
+		if now.After(b.Expires) {
+			delete(s.buffers, name)
+		}
+	}
+}
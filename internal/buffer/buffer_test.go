@@ -0,0 +1,67 @@
+package buffer
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStorePutGet(t *testing.T) {
+	s := NewStore(DefaultTTL, DefaultMaxBytes, DefaultMaxTotalBytes)
+
+	if _, err := s.Put("header", []byte("package a\n"), "a.go:1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := s.Put("header", []byte("package b\n"), "b.go:1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := s.Get("header")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(b.Content) != "package b\n" || b.Source != "b.go:1" {
+		t.Errorf("expected the buffer to be replaced, got %+v", b)
+	}
+	if got := len(s.List()); got != 1 {
+		t.Errorf("expected 1 buffer, got %d", got)
+	}
+	if _, err := s.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := s.Put("", nil, ""); err == nil {
+		t.Error("expected an empty name to be refused")
+	}
+}
+
+func TestStoreLimits(t *testing.T) {
+	s := NewStore(DefaultTTL, 4, 6)
+
+	if _, err := s.Put("a", []byte("12345"), ""); err == nil {
+		t.Error("expected a buffer over the size limit to be refused")
+	}
+	if _, err := s.Put("a", []byte("1234"), ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := s.Put("b", []byte("123"), ""); err == nil {
+		t.Error("expected buffers over the total limit to be refused")
+	}
+	// Replacing a buffer only counts its new size
+	if _, err := s.Put("a", []byte("1234"), ""); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestStoreExpiry(t *testing.T) {
+	s := NewStore(time.Minute, DefaultMaxBytes, DefaultMaxTotalBytes)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	s.Put("a", []byte("x"), "")
+	now = now.Add(2 * time.Minute)
+	if _, err := s.Get("a"); !errors.Is(err, ErrExpired) {
+		t.Errorf("expected ErrExpired, got %v", err)
+	}
+	if got := len(s.List()); got != 0 {
+		t.Errorf("expected expired buffers to be dropped, got %d", got)
+	}
+}
@@ -0,0 +1,121 @@
+// Package confirm implements a confirmation round-trip for destructive tool
+// calls. A gated call is first refused with a single-use token bound to the
+// exact arguments of that call; repeating the call with the token attached
+// lets it proceed. Clients are expected to show the pending operation to the
+// user and only resend it once the user agrees. The token goes back to the
+// caller, so the round-trip is advisory; see package protect for approval
+// the caller cannot give itself.
+package confirm
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// TokenParam is the tool argument that carries a confirmation token.
+const TokenParam = "confirmationToken"
+
+// ApprovalParam is the tool argument that carries a second, out-of-band
+// approval token for operations in protected paths.
+const ApprovalParam = "approvalToken"
+
+// DefaultTTL is how long an issued token stays valid.
+const DefaultTTL = 5 * time.Minute
+
+// Gate tracks which tools require confirmation and the tokens issued for them.
+type Gate struct {
+	mu      sync.Mutex
+	tools   map[string]bool
+	pending map[string]pending
+	ttl     time.Duration
+	now     func() time.Time
+}
+
+type pending struct {
+	fingerprint string
+	expires     time.Time
+}
+
+// New creates a gate requiring confirmation for the named tools.
+func New(tools []string, ttl time.Duration) *Gate {
+	g := &Gate{
+		tools:   make(map[string]bool, len(tools)),
+		pending: make(map[string]pending),
+		ttl:     ttl,
+		now:     time.Now,
+	}
+	for _, t := range tools {
+		g.tools[t] = true
+	}
+	return g
+}
+
+// Requires reports whether calls to tool must be confirmed. A nil gate
+// requires nothing.
+func (g *Gate) Requires(tool string) bool {
+	if g == nil {
+		return false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.tools[tool]
+}
+
+// Issue returns a new token that confirms a call to tool with args.
+func (g *Gate) Issue(tool string, args map[string]any) (string, error) {
+	tokenBytes := make([]byte, 12)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(tokenBytes)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := g.now()
+	for t, p := range g.pending {
+		if now.After(p.expires) {
+			delete(g.pending, t)
+		}
+	}
+	g.pending[token] = pending{
+		fingerprint: Fingerprint(tool, args),
+		expires:     now.Add(g.ttl),
+	}
+	return token, nil
+}
+
+// Confirm consumes token and reports whether it was issued for this exact
+// call. A token is spent even when it does not match.
+func (g *Gate) Confirm(token, tool string, args map[string]any) bool {
+	if token == "" {
+		return false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	p, ok := g.pending[token]
+	if !ok {
+		return false
+	}
+	delete(g.pending, token)
+	return !g.now().After(p.expires) && p.fingerprint == Fingerprint(tool, args)
+}
+
+// Fingerprint identifies a tool call by name and arguments, ignoring any
+// confirmation or approval token.
+func Fingerprint(tool string, args map[string]any) string {
+	stripped := make(map[string]any, len(args))
+	for k, v := range args {
+		if k != TokenParam && k != ApprovalParam {
+			stripped[k] = v
+		}
+	}
+	// Map keys are marshaled in sorted order, so equal arguments encode equally
+	data, _ := json.Marshal(stripped)
+	sum := sha256.Sum256(append([]byte(tool+"\x00"), data...))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,70 @@
+package confirm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGateRequires(t *testing.T) {
+	g := New([]string{"delete_directory"}, DefaultTTL)
+	if !g.Requires("delete_directory") {
+		t.Error("expected delete_directory to require confirmation")
+	}
+	if g.Requires("copy_file") {
+		t.Error("expected copy_file not to require confirmation")
+	}
+
+	var nilGate *Gate
+	if nilGate.Requires("delete_directory") {
+		t.Error("nil gate should require nothing")
+	}
+}
+
+func TestGateConfirm(t *testing.T) {
+	g := New([]string{"delete_directory"}, DefaultTTL)
+	args := map[string]any{"path": "/tmp/x", "recursive": true}
+
+	token, err := g.Issue("delete_directory", args)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withToken := map[string]any{"path": "/tmp/x", "recursive": true, TokenParam: token}
+	if !g.Confirm(token, "delete_directory", withToken) {
+		t.Error("expected token to confirm the matching call")
+	}
+	if g.Confirm(token, "delete_directory", withToken) {
+		t.Error("expected token to be single-use")
+	}
+}
+
+func TestGateConfirmMismatch(t *testing.T) {
+	g := New([]string{"delete_directory"}, DefaultTTL)
+
+	token, err := g.Issue("delete_directory", map[string]any{"path": "/tmp/x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Confirm(token, "delete_directory", map[string]any{"path": "/tmp/y"}) {
+		t.Error("expected token not to confirm a different call")
+	}
+	if g.Confirm(token, "delete_directory", map[string]any{"path": "/tmp/x"}) {
+		t.Error("expected mismatched attempt to spend the token")
+	}
+}
+
+func TestGateConfirmExpired(t *testing.T) {
+	g := New([]string{"copy_file"}, time.Minute)
+	now := time.Now()
+	g.now = func() time.Time { return now }
+	args := map[string]any{"source": "a", "destination": "b"}
+
+	token, err := g.Issue("copy_file", args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(2 * time.Minute)
+	if g.Confirm(token, "copy_file", args) {
+		t.Error("expected expired token to be rejected")
+	}
+}
@@ -0,0 +1,481 @@
+// Package convert converts structured data between JSON, YAML, CSV, and TSV.
+//
+// Tabular formats are the records of a header row followed by data rows. A
+// JSON or YAML document converts to a table only if it is an array of
+// objects; the first object's keys, in order, become the header. Conversions
+// that read CSV, TSV, or JSON stream record by record. YAML documents are
+// converted in memory.
+package convert
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is a structured data format.
+type Format string
+
+// Supported formats.
+const (
+	JSON Format = "json"
+	YAML Format = "yaml"
+	CSV  Format = "csv"
+	TSV  Format = "tsv"
+)
+
+// MaxYAMLSize caps the size of a YAML document, which is converted in
+// memory.
+const MaxYAMLSize = 32 * 1024 * 1024
+
+// ParseFormat parses a format name.
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case JSON, YAML, CSV, TSV:
+		return f, nil
+	case "yml":
+		return YAML, nil
+	}
+	return "", fmt.Errorf("unsupported format %q: expected json, yaml, csv, or tsv", name)
+}
+
+// FormatForPath infers a format from a file extension.
+func FormatForPath(path string) (Format, error) {
+	ext := strings.TrimPrefix(filepath.Ext(path), ".")
+	if ext == "" {
+		return "", fmt.Errorf("cannot infer format of %s from its extension", path)
+	}
+	return ParseFormat(ext)
+}
+
+func (f Format) tabular() bool {
+	return f == CSV || f == TSV
+}
+
+// Convert reads data in format from from r and writes it to w in format to.
+// It returns the number of records converted: data rows for a table,
+// elements for an array, and 1 for any other document.
+func Convert(r io.Reader, w io.Writer, from, to Format) (int, error) {
+	if from == to {
+		return 0, fmt.Errorf("source and destination are both %s", from)
+	}
+	bw := bufio.NewWriter(w)
+	var (
+		n   int
+		err error
+	)
+	switch {
+	case from.tabular():
+		n, err = fromTable(newTableReader(r, from), bw, to)
+	case from == JSON && to.tabular():
+		n, err = jsonToTable(json.NewDecoder(r), newTableWriter(bw, to))
+	case from == JSON:
+		n, err = jsonToYAML(r, bw)
+	default:
+		n, err = fromYAML(r, bw, to)
+	}
+	if err != nil {
+		return n, err
+	}
+	return n, bw.Flush()
+}
+
+func newTableReader(r io.Reader, f Format) *csv.Reader {
+	cr := csv.NewReader(r)
+	if f == TSV {
+		cr.Comma = '\t'
+		cr.LazyQuotes = true
+	}
+	cr.ReuseRecord = true
+	return cr
+}
+
+func newTableWriter(w io.Writer, f Format) *csv.Writer {
+	cw := csv.NewWriter(w)
+	if f == TSV {
+		cw.Comma = '\t'
+	}
+	return cw
+}
+
+// fromTable converts a table, streaming one row at a time.
+func fromTable(cr *csv.Reader, w *bufio.Writer, to Format) (int, error) {
+	header, err := cr.Read()
+	if err == io.EOF {
+		return 0, errors.New("input has no header row")
+	}
+	if err != nil {
+		return 0, err
+	}
+	header = append([]string(nil), header...)
+	cr.FieldsPerRecord = len(header)
+
+	var emit func(row []string) error
+	finish := func() error { return nil }
+	switch to {
+	case CSV, TSV:
+		cw := newTableWriter(w, to)
+		if err := cw.Write(header); err != nil {
+			return 0, err
+		}
+		emit = cw.Write
+		finish = func() error { cw.Flush(); return cw.Error() }
+	case JSON:
+		first := true
+		w.WriteString("[")
+		emit = func(row []string) error {
+			if !first {
+				w.WriteString(",")
+			}
+			first = false
+			w.WriteString("\n  ")
+			return writeJSONRow(w, header, row)
+		}
+		finish = func() error {
+			if !first {
+				w.WriteString("\n")
+			}
+			_, err := w.WriteString("]\n")
+			return err
+		}
+	case YAML:
+		emit = func(row []string) error { return writeYAMLRow(w, header, row) }
+		finish = func() error { return nil }
+	}
+
+	n := 0
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return n, err
+		}
+		if err := emit(row); err != nil {
+			return n, err
+		}
+		n++
+	}
+	if n == 0 && to == YAML {
+		w.WriteString("[]\n")
+	}
+	return n, finish()
+}
+
+// writeJSONRow writes a row as a JSON object with keys in header order.
+func writeJSONRow(w *bufio.Writer, header, row []string) error {
+	w.WriteString("{")
+	for i, key := range header {
+		if i > 0 {
+			w.WriteString(", ")
+		}
+		k, _ := json.Marshal(key)
+		v, _ := json.Marshal(row[i])
+		w.Write(k)
+		w.WriteString(": ")
+		w.Write(v)
+	}
+	_, err := w.WriteString("}")
+	return err
+}
+
+// writeYAMLRow writes a row as an item of a YAML sequence of mappings.
+func writeYAMLRow(w io.Writer, header, row []string) error {
+	item := &yaml.Node{Kind: yaml.MappingNode}
+	for i, key := range header {
+		item.Content = append(item.Content, stringNode(key), stringNode(row[i]))
+	}
+	data, err := yaml.Marshal(&yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{item}})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func stringNode(s string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s}
+}
+
+// jsonToTable converts a JSON array of objects, streaming one element at a
+// time. Nested values are written as compact JSON.
+func jsonToTable(dec *json.Decoder, cw *csv.Writer) (int, error) {
+	dec.UseNumber()
+	if tok, err := dec.Token(); err != nil {
+		return 0, err
+	} else if tok != json.Delim('[') {
+		return 0, errors.New("JSON input must be an array of objects to convert to a table")
+	}
+
+	var header []string
+	var columns map[string]int
+	n := 0
+	for dec.More() {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return n, err
+		}
+		keys, values, err := orderedObject(raw)
+		if err != nil {
+			return n, fmt.Errorf("element %d: %w", n+1, err)
+		}
+		if header == nil {
+			header, columns = keys, make(map[string]int, len(keys))
+			for i, key := range keys {
+				columns[key] = i
+			}
+			if err := cw.Write(header); err != nil {
+				return n, err
+			}
+		}
+		row := make([]string, len(header))
+		for i, key := range keys {
+			col, ok := columns[key]
+			if !ok {
+				return n, fmt.Errorf("element %d: key %q is not in the first element", n+1, key)
+			}
+			row[col] = values[i]
+		}
+		if err := cw.Write(row); err != nil {
+			return n, err
+		}
+		n++
+	}
+	if _, err := dec.Token(); err != nil {
+		return n, err
+	}
+	if dec.More() {
+		return n, errors.New("unexpected data after JSON array")
+	}
+	cw.Flush()
+	return n, cw.Error()
+}
+
+// orderedObject returns the keys of a JSON object in order, with each value
+// rendered as a table cell.
+func orderedObject(raw json.RawMessage) ([]string, []string, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if tok, err := dec.Token(); err != nil {
+		return nil, nil, err
+	} else if tok != json.Delim('{') {
+		return nil, nil, errors.New("expected an object")
+	}
+	var keys, values []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, nil, err
+		}
+		keys = append(keys, tok.(string))
+		values = append(values, cell(value))
+	}
+	return keys, values, nil
+}
+
+// cell renders a JSON value as a table cell: strings unquoted, null empty,
+// and anything else as compact JSON.
+func cell(value json.RawMessage) string {
+	var s string
+	if json.Unmarshal(value, &s) == nil {
+		return s
+	}
+	if string(value) == "null" {
+		return ""
+	}
+	var compact bytes.Buffer
+	if json.Compact(&compact, value) != nil {
+		return string(value)
+	}
+	return compact.String()
+}
+
+// jsonToYAML converts a JSON document to YAML, keeping key order.
+func jsonToYAML(r io.Reader, w io.Writer) (int, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	node, err := jsonNode(dec)
+	if err != nil {
+		return 0, err
+	}
+	if dec.More() {
+		return 0, errors.New("unexpected data after JSON document")
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return 0, err
+	}
+	return records(node), enc.Close()
+}
+
+// jsonNode reads the next JSON value from dec as a YAML node.
+func jsonNode(dec *json.Decoder) (*yaml.Node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch v := tok.(type) {
+	case json.Delim:
+		kind := yaml.SequenceNode
+		if v == '{' {
+			kind = yaml.MappingNode
+		}
+		node := &yaml.Node{Kind: kind}
+		for dec.More() {
+			if kind == yaml.MappingNode {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, stringNode(key.(string)))
+			}
+			child, err := jsonNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case string:
+		return stringNode(v), nil
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(v.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: v.String()}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(v)}, nil
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	}
+}
+
+// fromYAML converts a single YAML document, in memory.
+func fromYAML(r io.Reader, w *bufio.Writer, to Format) (int, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxYAMLSize+1))
+	if err != nil {
+		return 0, err
+	}
+	if len(data) > MaxYAMLSize {
+		return 0, fmt.Errorf("YAML input is larger than %d bytes", MaxYAMLSize)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	var doc yaml.Node
+	if err := dec.Decode(&doc); err != nil {
+		if err == io.EOF {
+			return 0, errors.New("YAML input is empty")
+		}
+		return 0, err
+	}
+	var extra yaml.Node
+	if err := dec.Decode(&extra); err != io.EOF {
+		return 0, errors.New("YAML input has more than one document")
+	}
+
+	var buf bytes.Buffer
+	if err := writeJSONNode(&buf, &doc, 0); err != nil {
+		return 0, err
+	}
+	if to.tabular() {
+		return jsonToTable(json.NewDecoder(&buf), newTableWriter(w, to))
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", "  "); err != nil {
+		return 0, err
+	}
+	out.WriteString("\n")
+	_, err = w.Write(out.Bytes())
+	return records(&doc), err
+}
+
+// maxAliasDepth and maxExpandedSize bound alias expansion, so a small
+// document of nested aliases cannot expand without limit.
+const (
+	maxAliasDepth   = 32
+	maxExpandedSize = 4 * MaxYAMLSize
+)
+
+// writeJSONNode writes a YAML node as compact JSON, keeping key order.
+func writeJSONNode(buf *bytes.Buffer, node *yaml.Node, aliases int) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		return writeJSONNode(buf, node.Content[0], aliases)
+	case yaml.AliasNode:
+		if aliases >= maxAliasDepth || buf.Len() > maxExpandedSize {
+			return errors.New("YAML aliases expand too far")
+		}
+		return writeJSONNode(buf, node.Alias, aliases+1)
+	case yaml.SequenceNode:
+		buf.WriteString("[")
+		for i, child := range node.Content {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			if err := writeJSONNode(buf, child, aliases); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("]")
+		return nil
+	case yaml.MappingNode:
+		buf.WriteString("{")
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			key, _ := json.Marshal(node.Content[i].Value)
+			buf.Write(key)
+			buf.WriteString(":")
+			if err := writeJSONNode(buf, node.Content[i+1], aliases); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("}")
+		return nil
+	default:
+		var v any
+		if err := node.Decode(&v); err != nil {
+			return err
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		buf.Write(data)
+		return nil
+	}
+}
+
+// records counts the records in a document: the elements of a top-level
+// sequence, or 1.
+func records(node *yaml.Node) int {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind == yaml.SequenceNode {
+		return len(node.Content)
+	}
+	return 1
+}
@@ -0,0 +1,138 @@
+package convert
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    Format
+		to      Format
+		input   string
+		want    string
+		records int
+	}{
+		{
+			name:    "csv to json",
+			from:    CSV,
+			to:      JSON,
+			input:   "name,port\napi,8080\n\"web, public\",80\n",
+			want:    "[\n  {\"name\": \"api\", \"port\": \"8080\"},\n  {\"name\": \"web, public\", \"port\": \"80\"}\n]\n",
+			records: 2,
+		},
+		{
+			name:    "json to csv keeps first object's key order",
+			from:    JSON,
+			to:      CSV,
+			input:   `[{"port": 8080, "name": "api", "tags": ["a"]}, {"name": "web", "port": null}]`,
+			want:    "port,name,tags\n8080,api,\"[\"\"a\"\"]\"\n,web,\n",
+			records: 2,
+		},
+		{
+			name:    "csv to tsv",
+			from:    CSV,
+			to:      TSV,
+			input:   "a,b\n1,2\n",
+			want:    "a\tb\n1\t2\n",
+			records: 1,
+		},
+		{
+			name:    "tsv to csv",
+			from:    TSV,
+			to:      CSV,
+			input:   "a\tb\nx,y\tz\n",
+			want:    "a,b\n\"x,y\",z\n",
+			records: 1,
+		},
+		{
+			name:    "yaml to json keeps key order",
+			from:    YAML,
+			to:      JSON,
+			input:   "zeta: 1\nalpha:\n  - true\n  - 2.5\n  - null\n",
+			want:    "{\n  \"zeta\": 1,\n  \"alpha\": [\n    true,\n    2.5,\n    null\n  ]\n}\n",
+			records: 1,
+		},
+		{
+			name:    "json to yaml",
+			from:    JSON,
+			to:      YAML,
+			input:   `{"zeta": "1", "alpha": [1, 2.5, false, null]}`,
+			want:    "zeta: \"1\"\nalpha:\n  - 1\n  - 2.5\n  - false\n  - null\n",
+			records: 1,
+		},
+		{
+			name:    "csv to yaml",
+			from:    CSV,
+			to:      YAML,
+			input:   "name,port\napi,8080\n",
+			want:    "- name: api\n  port: \"8080\"\n",
+			records: 1,
+		},
+		{
+			name:    "yaml to csv",
+			from:    YAML,
+			to:      CSV,
+			input:   "- name: api\n  port: 8080\n- name: web\n  port: 80\n",
+			want:    "name,port\napi,8080\nweb,80\n",
+			records: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			n, err := Convert(strings.NewReader(tt.input), &out, tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("Convert failed: %v", err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", out.String(), tt.want)
+			}
+			if n != tt.records {
+				t.Errorf("expected %d records, got %d", tt.records, n)
+			}
+		})
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		from  Format
+		to    Format
+		input string
+		want  string
+	}{
+		{"json object to table", JSON, CSV, `{"a": 1}`, "array of objects"},
+		{"json element not an object", JSON, CSV, `[{"a": 1}, 2]`, "element 2"},
+		{"json key missing from header", JSON, CSV, `[{"a": 1}, {"b": 2}]`, `key "b"`},
+		{"ragged csv", CSV, JSON, "a,b\n1\n", "wrong number of fields"},
+		{"empty csv", CSV, JSON, "", "no header row"},
+		{"multiple yaml documents", YAML, JSON, "a: 1\n---\nb: 2\n", "more than one document"},
+		{"same format", CSV, CSV, "a\n", "both csv"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Convert(strings.NewReader(tt.input), &bytes.Buffer{}, tt.from, tt.to)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestFormatForPath(t *testing.T) {
+	for path, want := range map[string]Format{"a.json": JSON, "b.YML": YAML, "c.yaml": YAML, "d.csv": CSV, "e.tsv": TSV} {
+		if got, err := FormatForPath(path); err != nil || got != want {
+			t.Errorf("FormatForPath(%q) = %q, %v; want %q", path, got, err, want)
+		}
+	}
+	if _, err := FormatForPath("notes.txt"); err == nil {
+		t.Error("expected an error for an unsupported extension")
+	}
+	if _, err := FormatForPath("Makefile"); err == nil {
+		t.Error("expected an error without an extension")
+	}
+}
@@ -0,0 +1,358 @@
+package convert
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// PreviewSampleRows is how many data rows PreviewTable reads from the
+	// start of a table to infer column types and estimate the row count.
+	PreviewSampleRows = 1000
+	// maxTailWindow caps how much of the end of a table PreviewTable reads
+	// to find its last rows.
+	maxTailWindow = 1024 * 1024
+)
+
+// Column types PreviewTable infers. A column holds the narrowest type that
+// all of its non-empty sampled values have.
+const (
+	TypeEmpty    = "empty"
+	TypeInteger  = "integer"
+	TypeNumber   = "number"
+	TypeBoolean  = "boolean"
+	TypeDate     = "date"
+	TypeDatetime = "datetime"
+	TypeString   = "string"
+)
+
+// PreviewOptions controls PreviewTable.
+type PreviewOptions struct {
+	// Delimiter separates fields; 0 detects it from the first line.
+	Delimiter rune
+	// Header says whether the first row holds column names; nil infers it.
+	Header *bool
+	// Head and Tail are how many rows to return from the start and the end.
+	Head, Tail int
+	// Exact counts every row instead of estimating the count.
+	Exact bool
+}
+
+// Column is a column of a previewed table.
+type Column struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Empty is how many sampled values were empty.
+	Empty int `json:"empty"`
+}
+
+// Preview is a summary of a table.
+type Preview struct {
+	Delimiter string   `json:"delimiter"`
+	Header    bool     `json:"header"`
+	Columns   []Column `json:"columns"`
+	// Rows is the number of data rows, estimated from the sampled rows
+	// unless the whole table was read.
+	Rows      int64 `json:"rows"`
+	Estimated bool  `json:"estimated"`
+	// Sampled is how many rows column types were inferred from.
+	Sampled int        `json:"sampled"`
+	Head    [][]string `json:"head"`
+	Tail    [][]string `json:"tail"`
+	// TailStart is the number of the first tail row, counting data rows
+	// from 1, when Rows is exact.
+	TailStart int64 `json:"tailStart,omitempty"`
+}
+
+// PreviewTable reads the first and last rows of the CSV or TSV table in r,
+// which is size bytes long, infers its column names and types, and counts
+// or estimates its rows. Only the sampled rows and the end of the table
+// are read unless opts.Exact is set, which streams through the rest.
+func PreviewTable(ctx context.Context, r io.ReadSeeker, size int64, opts PreviewOptions) (*Preview, error) {
+	br := bufio.NewReaderSize(r, 64*1024)
+	var bom int64
+	if b, _ := br.Peek(3); bytes.Equal(b, []byte("\xef\xbb\xbf")) {
+		br.Discard(3)
+		bom = 3
+	}
+	delim := opts.Delimiter
+	if delim == 0 {
+		head, _ := br.Peek(br.Size())
+		delim = sniffDelimiter(head)
+	}
+
+	cr := newPreviewReader(br, delim)
+	first, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return &Preview{Delimiter: string(delim), Columns: []Column{}, Head: [][]string{}, Tail: [][]string{}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", tableName(delim), err)
+	}
+
+	p := &Preview{Delimiter: string(delim), Head: [][]string{}, Tail: [][]string{}}
+	header := opts.Header == nil && looksLikeHeader(first) || opts.Header != nil && *opts.Header
+	var stats columnStats
+	// ring holds the last opts.Tail rows read, the oldest at count%len
+	ring := make([][]string, 0, opts.Tail)
+	var count int64
+	keep := func(row []string) {
+		if len(p.Head) < opts.Head {
+			p.Head = append(p.Head, row)
+		}
+		if count < PreviewSampleRows {
+			stats.add(row)
+			p.Sampled++
+		}
+		if len(ring) < opts.Tail {
+			ring = append(ring, row)
+		} else if opts.Tail > 0 {
+			ring[count%int64(opts.Tail)] = row
+		}
+		count++
+	}
+	var headerEnd int64
+	if header {
+		p.Header = true
+		headerEnd = cr.InputOffset()
+	} else {
+		keep(first)
+	}
+
+	eof := false
+	for opts.Exact || count < PreviewSampleRows || len(p.Head) < opts.Head {
+		if count%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			eof = true
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", tableName(delim), err)
+		}
+		keep(row)
+	}
+	p.Columns = stats.columns(first, header)
+
+	if eof {
+		p.Rows = count
+		if len(ring) == opts.Tail && opts.Tail > 0 {
+			i := count % int64(opts.Tail)
+			ring = append(ring[i:], ring[:i]...)
+		}
+		p.Tail = ring
+		if len(ring) > 0 {
+			p.TailStart = count - int64(len(ring)) + 1
+		}
+		return p, nil
+	}
+
+	// Scale the rows sampled by the bytes they took to the rest of the table
+	p.Estimated = true
+	if used := cr.InputOffset() - headerEnd; used > 0 {
+		p.Rows = max(count, int64(float64(count)*float64(size-bom-headerEnd)/float64(used)+0.5))
+	} else {
+		p.Rows = count
+	}
+	if opts.Tail > 0 {
+		tail, err := tailRows(r, bom+cr.InputOffset(), size, delim, opts.Tail)
+		if err != nil {
+			return nil, err
+		}
+		p.Tail = tail
+	}
+	return p, nil
+}
+
+// newPreviewReader returns a lenient reader, since a preview should show a
+// messy table rather than refuse it.
+func newPreviewReader(r io.Reader, delim rune) *csv.Reader {
+	cr := csv.NewReader(r)
+	cr.Comma = delim
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	return cr
+}
+
+// tailRows returns the last n rows of a table, reading back from its end no
+// further than from. The window grows until it holds n rows or reaches
+// from or maxTailWindow.
+func tailRows(r io.ReadSeeker, from, size int64, delim rune, n int) ([][]string, error) {
+	for window := int64(64 * 1024); ; window *= 2 {
+		start := max(from, size-window)
+		if _, err := r.Seek(start, io.SeekStart); err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size-start)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		rows := parseTail(buf, start > from, delim)
+		if len(rows) >= n || start == from || window >= maxTailWindow {
+			return rows[max(0, len(rows)-n):], nil
+		}
+	}
+}
+
+// parseTail parses the rows in buf, which starts partway through a row if
+// partial is set. A line break may be inside a quoted field, so a start
+// that does not parse moves on to the next line.
+func parseTail(buf []byte, partial bool, delim rune) [][]string {
+	for range 10 {
+		if partial {
+			i := bytes.IndexByte(buf, '\n')
+			if i < 0 {
+				return nil
+			}
+			buf = buf[i+1:]
+		}
+		partial = true
+		rows, err := newPreviewReader(bytes.NewReader(buf), delim).ReadAll()
+		if err == nil {
+			return rows
+		}
+	}
+	return nil
+}
+
+// sniffDelimiter picks the most frequent of comma, tab, semicolon, and
+// pipe outside quotes in the first line of data, or comma if there are none.
+func sniffDelimiter(data []byte) rune {
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		data = data[:i]
+	}
+	counts := map[byte]int{}
+	quoted := false
+	for _, c := range data {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case !quoted && (c == ',' || c == '\t' || c == ';' || c == '|'):
+			counts[c]++
+		}
+	}
+	best := byte(',')
+	for _, c := range []byte{'\t', ';', '|'} {
+		if counts[c] > counts[best] {
+			best = c
+		}
+	}
+	return rune(best)
+}
+
+// looksLikeHeader reports whether the first row of a table is a header: one
+// whose cells are all text, not numbers, booleans, or dates.
+func looksLikeHeader(row []string) bool {
+	for _, cell := range row {
+		if t := cellType(cell); t != TypeString && t != TypeEmpty {
+			return false
+		}
+	}
+	return true
+}
+
+// tableName names a table by its delimiter for error messages.
+func tableName(delim rune) string {
+	if delim == '\t' {
+		return "TSV"
+	}
+	return "CSV"
+}
+
+// columnStats accumulates the types of sampled values by column.
+type columnStats struct {
+	types []string
+	empty []int
+}
+
+func (s *columnStats) add(row []string) {
+	for len(s.types) < len(row) {
+		s.types = append(s.types, "")
+		s.empty = append(s.empty, 0)
+	}
+	for i, cell := range row {
+		t := cellType(cell)
+		if t == TypeEmpty {
+			s.empty[i]++
+			continue
+		}
+		s.types[i] = mergeTypes(s.types[i], t)
+	}
+}
+
+// columns names the columns after the header, or column1, column2, and so
+// on where there is none.
+func (s *columnStats) columns(first []string, header bool) []Column {
+	n := len(s.types)
+	if header {
+		n = max(n, len(first))
+	}
+	cols := make([]Column, n)
+	for i := range cols {
+		cols[i] = Column{Name: fmt.Sprintf("column%d", i+1), Type: TypeEmpty}
+		if header && i < len(first) && strings.TrimSpace(first[i]) != "" {
+			cols[i].Name = first[i]
+		}
+		if i < len(s.types) {
+			if s.types[i] != "" {
+				cols[i].Type = s.types[i]
+			}
+			cols[i].Empty = s.empty[i]
+		}
+	}
+	return cols
+}
+
+// cellType infers the type of a value.
+func cellType(s string) string {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "":
+		return TypeEmpty
+	case strings.EqualFold(s, "true") || strings.EqualFold(s, "false"):
+		return TypeBoolean
+	}
+	if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return TypeInteger
+	}
+	// ParseFloat also accepts words such as Inf and NaN, which are text
+	if c := s[len(s)-1]; c >= '0' && c <= '9' || c == '.' {
+		if _, err := strconv.ParseFloat(s, 64); err == nil {
+			return TypeNumber
+		}
+	}
+	if _, err := time.Parse(time.DateOnly, s); err == nil {
+		return TypeDate
+	}
+	for _, layout := range []string{time.RFC3339Nano, time.DateTime, "2006-01-02T15:04:05"} {
+		if _, err := time.Parse(layout, s); err == nil {
+			return TypeDatetime
+		}
+	}
+	return TypeString
+}
+
+// mergeTypes returns the narrowest type that covers values of types a and b.
+func mergeTypes(a, b string) string {
+	switch {
+	case a == "" || a == b:
+		return b
+	case a == TypeInteger && b == TypeNumber, a == TypeNumber && b == TypeInteger:
+		return TypeNumber
+	case a == TypeDate && b == TypeDatetime, a == TypeDatetime && b == TypeDate:
+		return TypeDatetime
+	}
+	return TypeString
+}
@@ -0,0 +1,134 @@
+package convert
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func preview(t *testing.T, data string, opts PreviewOptions) *Preview {
+	t.Helper()
+	p, err := PreviewTable(context.Background(), strings.NewReader(data), int64(len(data)), opts)
+	if err != nil {
+		t.Fatalf("PreviewTable: %v", err)
+	}
+	return p
+}
+
+func TestPreviewTable(t *testing.T) {
+	data := "\xef\xbb\xbfid,name,price,active,since,note\n" +
+		"1,apple,3,true,2024-01-02,\n" +
+		"2,pear,2.5,false,2024-01-03T10:00:00Z,\"ripe, soft\"\n" +
+		"3,plum,4,TRUE,2024-02-01,\n"
+	p := preview(t, data, PreviewOptions{Head: 2, Tail: 2})
+
+	if !p.Header || p.Delimiter != "," || p.Rows != 3 || p.Estimated || p.Sampled != 3 {
+		t.Errorf("unexpected summary %+v", p)
+	}
+	want := []Column{
+		{Name: "id", Type: TypeInteger},
+		{Name: "name", Type: TypeString},
+		{Name: "price", Type: TypeNumber},
+		{Name: "active", Type: TypeBoolean},
+		{Name: "since", Type: TypeDatetime},
+		{Name: "note", Type: TypeString, Empty: 2},
+	}
+	if !reflect.DeepEqual(p.Columns, want) {
+		t.Errorf("columns = %+v, want %+v", p.Columns, want)
+	}
+	if len(p.Head) != 2 || p.Head[0][1] != "apple" || p.Head[1][5] != "ripe, soft" {
+		t.Errorf("unexpected head %v", p.Head)
+	}
+	if len(p.Tail) != 2 || p.Tail[0][1] != "pear" || p.Tail[1][1] != "plum" || p.TailStart != 2 {
+		t.Errorf("unexpected tail %v starting at %d", p.Tail, p.TailStart)
+	}
+}
+
+func TestPreviewTableNoHeader(t *testing.T) {
+	p := preview(t, "1\t2\n3\t4\t5\n", PreviewOptions{Head: 5})
+	if p.Header || p.Delimiter != "\t" || p.Rows != 2 {
+		t.Errorf("unexpected summary %+v", p)
+	}
+	names := []string{p.Columns[0].Name, p.Columns[1].Name, p.Columns[2].Name}
+	if !reflect.DeepEqual(names, []string{"column1", "column2", "column3"}) || p.Columns[2].Type != TypeInteger {
+		t.Errorf("unexpected columns %+v", p.Columns)
+	}
+
+	// An explicit header wins over inference
+	header := true
+	p = preview(t, "2023,2024\n1,2\n", PreviewOptions{Header: &header})
+	if !p.Header || p.Columns[0].Name != "2023" || p.Rows != 1 {
+		t.Errorf("unexpected preview %+v", p)
+	}
+}
+
+func TestPreviewTableEstimate(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("n;label\n")
+	const rows = 5000
+	for i := 1; i <= rows; i++ {
+		fmt.Fprintf(&b, "%04d;row %04d\n", i, i)
+	}
+	data := b.String()
+
+	p := preview(t, data, PreviewOptions{Head: 1, Tail: 3})
+	if !p.Estimated || p.Sampled != PreviewSampleRows || p.Delimiter != ";" {
+		t.Errorf("unexpected summary %+v", p)
+	}
+	// Rows of the same length scale exactly
+	if p.Rows != rows {
+		t.Errorf("estimated %d rows, want %d", p.Rows, rows)
+	}
+	want := [][]string{{"4998", "row 4998"}, {"4999", "row 4999"}, {"5000", "row 5000"}}
+	if !reflect.DeepEqual(p.Tail, want) || p.TailStart != 0 {
+		t.Errorf("tail = %v starting at %d, want %v", p.Tail, p.TailStart, want)
+	}
+
+	p = preview(t, data, PreviewOptions{Tail: 1, Exact: true})
+	if p.Estimated || p.Rows != rows || p.TailStart != rows || p.Tail[0][0] != "5000" {
+		t.Errorf("unexpected exact preview: %d rows, tail %v at %d", p.Rows, p.Tail, p.TailStart)
+	}
+}
+
+func TestPreviewTableTailQuotedNewlines(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("id,text\n")
+	for i := range 3000 {
+		fmt.Fprintf(&b, "%d,\"line one\nline two of %d\"\n", i, i)
+	}
+	data := b.String()
+	p := preview(t, data, PreviewOptions{Tail: 2})
+	if len(p.Tail) != 2 || p.Tail[1][0] != "2999" || p.Tail[1][1] != "line one\nline two of 2999" {
+		t.Errorf("unexpected tail %q", p.Tail)
+	}
+}
+
+func TestPreviewTableEmpty(t *testing.T) {
+	p := preview(t, "", PreviewOptions{Head: 5, Tail: 5})
+	if p.Rows != 0 || len(p.Columns) != 0 || p.Head == nil || p.Tail == nil {
+		t.Errorf("unexpected preview %+v", p)
+	}
+}
+
+func TestCellType(t *testing.T) {
+	for in, want := range map[string]string{
+		"":                     TypeEmpty,
+		" 42 ":                 TypeInteger,
+		"-7":                   TypeInteger,
+		"3.14":                 TypeNumber,
+		"1e6":                  TypeNumber,
+		"Inf":                  TypeString,
+		"NaN":                  TypeString,
+		"False":                TypeBoolean,
+		"2024-05-06":           TypeDate,
+		"2024-05-06 07:08:09":  TypeDatetime,
+		"2024-05-06T07:08:09Z": TypeDatetime,
+		"hello":                TypeString,
+	} {
+		if got := cellType(in); got != want {
+			t.Errorf("cellType(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,403 @@
+// Package dav serves a read-only WebDAV view of the allowed directories, so
+// people can browse exactly what the tools can see. Paths go through the
+// same registry validation as tool calls, and in overlay mode the view
+// includes pending changes.
+package dav
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"golang.org/x/net/webdav"
+)
+
+// allowedMethods are the methods that only read.
+const allowedMethods = "OPTIONS, GET, HEAD, PROPFIND"
+
+// Handler returns an HTTP handler serving the allowed directories over
+// WebDAV. The root lists one directory per allowed directory, named by its
+// alias if it has one. Only reading methods are allowed; a GET of a
+// directory returns an HTML listing for browsers. Requests are refused
+// unless their Host is the host of addr, the address the view listens on,
+// localhost, or a loopback IP, so that a web page cannot reach the view by
+// DNS rebinding.
+func Handler(reg *registry.Registry, logger *slog.Logger, addr string) http.Handler {
+	fsys := &fileSystem{reg: reg}
+	dav := &webdav.Handler{
+		FileSystem: fsys,
+		LockSystem: webdav.NewMemLS(),
+		Logger: func(r *http.Request, err error) {
+			if err != nil {
+				logger.Debug("webdav request failed", "method", r.Method, "path", r.URL.Path, "error", err)
+			}
+		},
+	}
+	listenHost, _, _ := net.SplitHostPort(addr)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !allowedHost(r.Host, listenHost) {
+			logger.Debug("webdav request refused", "host", r.Host, "path", r.URL.Path)
+			http.Error(w, "unexpected Host header", http.StatusForbidden)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			if info, err := fsys.Stat(r.Context(), r.URL.Path); err == nil && info.IsDir() {
+				serveIndex(w, r, fsys)
+				return
+			}
+		case http.MethodOptions, "PROPFIND":
+		default:
+			w.Header().Set("Allow", allowedMethods)
+			http.Error(w, "the WebDAV view is read-only", http.StatusMethodNotAllowed)
+			return
+		}
+		dav.ServeHTTP(w, r)
+	})
+}
+
+// allowedHost reports whether a request's Host header names the view.
+// Behind an unspecified listen address, such as 0.0.0.0, any IP is
+// accepted, since a rebound domain name never is one.
+func allowedHost(hostHeader, listenHost string) bool {
+	host := hostHeader
+	if h, _, err := net.SplitHostPort(hostHeader); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	if strings.EqualFold(host, "localhost") || (listenHost != "" && strings.EqualFold(host, listenHost)) {
+		return true
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	if ip.IsLoopback() {
+		return true
+	}
+	listenIP := net.ParseIP(listenHost)
+	return listenHost == "" || (listenIP != nil && listenIP.IsUnspecified())
+}
+
+// serveIndex writes an HTML listing of the directory at r's path.
+func serveIndex(w http.ResponseWriter, r *http.Request, fsys *fileSystem) {
+	f, err := fsys.OpenFile(r.Context(), r.URL.Path, os.O_RDONLY, 0)
+	if err != nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	defer f.Close()
+	infos, err := f.Readdir(-1)
+	if err != nil {
+		http.Error(w, "failed to list directory", http.StatusInternalServerError)
+		return
+	}
+
+	dir := path.Clean("/" + r.URL.Path)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if r.Method == http.MethodHead {
+		return
+	}
+	fmt.Fprintf(w, "<!DOCTYPE html>\n<title>%s</title>\n<h1>%s</h1>\n<ul>\n", html.EscapeString(dir), html.EscapeString(dir))
+	if dir != "/" {
+		fmt.Fprintf(w, "<li><a href=\"%s\">../</a></li>\n", html.EscapeString(escapePath(path.Dir(dir)+"/")))
+	}
+	for _, info := range infos {
+		name, href := info.Name(), path.Join(dir, info.Name())
+		if info.IsDir() {
+			name += "/"
+			href += "/"
+		}
+		fmt.Fprintf(w, "<li><a href=\"%s\">%s</a></li>\n", html.EscapeString(escapePath(href)), html.EscapeString(name))
+	}
+	fmt.Fprint(w, "</ul>\n")
+}
+
+// escapePath escapes p for use in a URL.
+func escapePath(p string) string {
+	return (&url.URL{Path: p}).EscapedPath()
+}
+
+// fileSystem is a read-only webdav.FileSystem over the registry.
+type fileSystem struct {
+	reg *registry.Registry
+}
+
+// errReadOnly is returned for any attempt to change the tree.
+var errReadOnly = fmt.Errorf("the WebDAV view is read-only: %w", os.ErrPermission)
+
+func (fsys *fileSystem) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	return errReadOnly
+}
+
+func (fsys *fileSystem) RemoveAll(ctx context.Context, name string) error {
+	return errReadOnly
+}
+
+func (fsys *fileSystem) Rename(ctx context.Context, oldName, newName string) error {
+	return errReadOnly
+}
+
+func (fsys *fileSystem) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND) != 0 {
+		return nil, errReadOnly
+	}
+	root, target, resolved, err := fsys.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	if resolved == "" {
+		return &dirFile{info: rootInfo{}, entries: fsys.rootInfos()}, nil
+	}
+
+	info, err := os.Stat(target)
+	if err != nil {
+		return nil, err
+	}
+	if root != "" {
+		info = renamedInfo{info, root}
+	}
+	if !info.IsDir() {
+		f, err := os.Open(target)
+		if err != nil {
+			return nil, err
+		}
+		return readOnlyFile{f}, nil
+	}
+	entries, err := fsys.readDir(resolved)
+	if err != nil {
+		return nil, err
+	}
+	return &dirFile{info: info, entries: entries}, nil
+}
+
+func (fsys *fileSystem) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	root, target, resolved, err := fsys.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	if resolved == "" {
+		return rootInfo{}, nil
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return nil, err
+	}
+	if root != "" {
+		return renamedInfo{info, root}, nil
+	}
+	return info, nil
+}
+
+// roots names the allowed directories for the top level of the view: by
+// alias if they have one, otherwise by base name, adding a number to
+// repeated names.
+func (fsys *fileSystem) roots() map[string]string {
+	aliases := make(map[string]string)
+	for _, a := range fsys.reg.Aliases() {
+		if _, ok := aliases[a.Dir]; !ok {
+			aliases[a.Dir] = a.Name
+		}
+	}
+	roots := make(map[string]string)
+	for _, dir := range fsys.reg.Get() {
+		name, ok := aliases[dir]
+		if !ok {
+			name = filepath.Base(dir)
+		}
+		unique := name
+		for i := 2; roots[unique] != ""; i++ {
+			unique = fmt.Sprintf("%s-%d", name, i)
+		}
+		roots[unique] = dir
+	}
+	return roots
+}
+
+// resolve maps a WebDAV path to the allowed directory it is under, the
+// path reads are served from, and its validated path. root is the name of
+// the allowed directory when the path is that directory itself, and the
+// validated path is empty for the root of the view. Paths that fail
+// validation are reported as permission errors, which PROPFIND skips.
+func (fsys *fileSystem) resolve(name string) (root, target, resolved string, err error) {
+	clean := strings.TrimPrefix(path.Clean("/"+name), "/")
+	if clean == "" {
+		return "", "", "", nil
+	}
+	first, rest, _ := strings.Cut(clean, "/")
+	dir, ok := fsys.roots()[first]
+	if !ok {
+		return "", "", "", &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	if rest == "" {
+		root = first
+	}
+
+	reg := fsys.reg
+	full := filepath.Join(dir, filepath.FromSlash(rest))
+	resolved, err = reg.Validate(full)
+	if err != nil && os.IsNotExist(err) && reg.Overlay() != nil {
+		// The path may exist only in the overlay
+		resolved, err = reg.ValidateForCreation(full)
+	}
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", "", "", &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+		}
+		return "", "", "", &os.PathError{Op: "open", Path: name, Err: os.ErrPermission}
+	}
+	target = resolved
+	if ov := reg.Overlay(); ov != nil {
+		if target, err = ov.ReadPath(resolved); err != nil {
+			return "", "", "", err
+		}
+	}
+	return root, target, resolved, nil
+}
+
+// readDir lists a directory as the tools see it, leaving out symlinks that
+// lead outside the allowed directories.
+func (fsys *fileSystem) readDir(resolved string) ([]os.FileInfo, error) {
+	var entries []os.DirEntry
+	var err error
+	if ov := fsys.reg.Overlay(); ov != nil {
+		entries, err = ov.ReadDir(resolved)
+	} else {
+		entries, err = os.ReadDir(resolved)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]os.FileInfo, 0, len(entries))
+	for _, e := range entries {
+		p := filepath.Join(resolved, e.Name())
+		if e.Type()&os.ModeSymlink != 0 {
+			target, err := fsys.reg.Validate(p)
+			if err != nil {
+				continue
+			}
+			info, err := os.Stat(target)
+			if err != nil {
+				continue
+			}
+			infos = append(infos, renamedInfo{info, e.Name()})
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// rootInfos describes the top level of the view.
+func (fsys *fileSystem) rootInfos() []os.FileInfo {
+	roots := fsys.roots()
+	names := make([]string, 0, len(roots))
+	for name := range roots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var infos []os.FileInfo
+	for _, name := range names {
+		if info, err := os.Stat(roots[name]); err == nil {
+			infos = append(infos, renamedInfo{info, name})
+		}
+	}
+	return infos
+}
+
+// readOnlyFile is an open regular file that refuses writes.
+type readOnlyFile struct {
+	*os.File
+}
+
+func (f readOnlyFile) Write(p []byte) (int, error) {
+	return 0, errReadOnly
+}
+
+// dirFile is an open directory whose entries were listed when it was
+// opened.
+type dirFile struct {
+	info    os.FileInfo
+	entries []os.FileInfo
+	pos     int
+}
+
+func (d *dirFile) Close() error {
+	return nil
+}
+
+func (d *dirFile) Read(p []byte) (int, error) {
+	return 0, &os.PathError{Op: "read", Path: d.info.Name(), Err: errors.New("is a directory")}
+}
+
+func (d *dirFile) Write(p []byte) (int, error) {
+	return 0, errReadOnly
+}
+
+func (d *dirFile) Seek(offset int64, whence int) (int64, error) {
+	if offset == 0 && whence == io.SeekStart {
+		d.pos = 0
+		return 0, nil
+	}
+	return 0, &os.PathError{Op: "seek", Path: d.info.Name(), Err: errors.New("is a directory")}
+}
+
+func (d *dirFile) Readdir(count int) ([]fs.FileInfo, error) {
+	remaining := d.entries[d.pos:]
+	if count <= 0 {
+		d.pos = len(d.entries)
+		return remaining, nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	if count > len(remaining) {
+		count = len(remaining)
+	}
+	d.pos += count
+	return remaining[:count], nil
+}
+
+func (d *dirFile) Stat() (fs.FileInfo, error) {
+	return d.info, nil
+}
+
+// renamedInfo is a FileInfo under another name.
+type renamedInfo struct {
+	os.FileInfo
+	name string
+}
+
+func (r renamedInfo) Name() string {
+	return r.name
+}
+
+// rootInfo describes the root of the view.
+type rootInfo struct{}
+
+func (rootInfo) Name() string       { return "/" }
+func (rootInfo) Size() int64        { return 0 }
+func (rootInfo) Mode() os.FileMode  { return os.ModeDir | 0555 }
+func (rootInfo) ModTime() time.Time { return time.Time{} }
+func (rootInfo) IsDir() bool        { return true }
+func (rootInfo) Sys() any           { return nil }
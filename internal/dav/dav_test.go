@@ -0,0 +1,137 @@
+package dav
+
+import (
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+)
+
+func setup(t *testing.T) (*httptest.Server, string) {
+	t.Helper()
+	dir := t.TempDir()
+	outside := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "docs"), 0755)
+	os.WriteFile(filepath.Join(dir, "docs", "a.txt"), []byte("hello"), 0644)
+	os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0644)
+	os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(dir, "docs", "escape.txt"))
+	os.Symlink(filepath.Join(dir, "docs", "a.txt"), filepath.Join(dir, "docs", "link.txt"))
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	reg := registry.New([]string{dir}, logger)
+	if err := reg.SetAliases(map[string]string{"project": dir}); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(Handler(reg, logger, "127.0.0.1:0"))
+	t.Cleanup(srv.Close)
+	return srv, dir
+}
+
+func request(t *testing.T, method, url string, body io.Reader, header map[string]string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(data)
+}
+
+func TestHandler(t *testing.T) {
+	srv, dir := setup(t)
+
+	status, body := request(t, "PROPFIND", srv.URL+"/", nil, map[string]string{"Depth": "1"})
+	if status != http.StatusMultiStatus || !strings.Contains(body, "/project/") {
+		t.Errorf("expected the root to list the aliased directory, got %d %s", status, body)
+	}
+
+	status, body = request(t, "PROPFIND", srv.URL+"/project/docs/", nil, map[string]string{"Depth": "1"})
+	if status != http.StatusMultiStatus || !strings.Contains(body, "a.txt") || !strings.Contains(body, "link.txt") {
+		t.Errorf("unexpected listing %d %s", status, body)
+	}
+	if strings.Contains(body, "escape.txt") {
+		t.Error("expected a symlink leading outside the allowed directories to be hidden")
+	}
+
+	if status, body := request(t, http.MethodGet, srv.URL+"/project/docs/link.txt", nil, nil); status != http.StatusOK || body != "hello" {
+		t.Errorf("unexpected read %d %q", status, body)
+	}
+	if status, _ := request(t, http.MethodGet, srv.URL+"/project/docs/escape.txt", nil, nil); status == http.StatusOK {
+		t.Error("expected a symlink leading outside the allowed directories to be refused")
+	}
+	if status, _ := request(t, http.MethodGet, srv.URL+"/project/../../etc/passwd", nil, nil); status == http.StatusOK {
+		t.Error("expected a path outside the view to be refused")
+	}
+
+	status, body = request(t, http.MethodGet, srv.URL+"/project/docs/", nil, nil)
+	if status != http.StatusOK || !strings.Contains(body, `href="/project/docs/a.txt"`) {
+		t.Errorf("expected an HTML listing, got %d %s", status, body)
+	}
+
+	for _, method := range []string{http.MethodPut, http.MethodDelete, "MKCOL", "MOVE", "COPY", "PROPPATCH", "LOCK"} {
+		if status, _ := request(t, method, srv.URL+"/project/docs/a.txt", strings.NewReader("changed"), map[string]string{"Destination": srv.URL + "/project/b.txt"}); status != http.StatusMethodNotAllowed {
+			t.Errorf("%s: expected 405, got %d", method, status)
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "docs", "a.txt")); string(data) != "hello" {
+		t.Errorf("expected the file to be unchanged, got %q", data)
+	}
+}
+
+func TestHandlerHost(t *testing.T) {
+	srv, _ := setup(t)
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(srv.URL, "http://"))
+
+	for host, want := range map[string]int{
+		"attacker.example":                  http.StatusForbidden,
+		"attacker.example:80":               http.StatusForbidden,
+		net.JoinHostPort("localhost", port): http.StatusOK,
+		net.JoinHostPort("127.0.0.1", port): http.StatusOK,
+		net.JoinHostPort("::1", port):       http.StatusOK,
+		net.JoinHostPort("192.0.2.1", port): http.StatusForbidden,
+		"LOCALHOST.":                        http.StatusForbidden,
+	} {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/project/docs/a.txt", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Host = host
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("Host %s: expected %d, got %d", host, want, resp.StatusCode)
+		}
+	}
+
+	for _, tt := range []struct {
+		host, listen string
+		want         bool
+	}{
+		{"files.internal:8080", "files.internal", true},
+		{"192.0.2.1:8080", "0.0.0.0", true},
+		{"192.0.2.1:8080", "", true},
+		{"attacker.example:8080", "0.0.0.0", false},
+	} {
+		if got := allowedHost(tt.host, tt.listen); got != tt.want {
+			t.Errorf("allowedHost(%q, %q) = %v, want %v", tt.host, tt.listen, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Package ffmpeg runs an operator-configured ffmpeg binary to cut audio and
+// extract video frames that the server cannot handle natively. Output is
+// collected in memory and capped, so a runaway encode cannot exhaust it.
+package ffmpeg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/portertech/filesystem-mcp-server/internal/audio"
+)
+
+// Runner runs an ffmpeg binary.
+type Runner struct {
+	Path string
+}
+
+// New checks that the ffmpeg binary at path, or found on PATH, can be run.
+func New(path string) (*Runner, error) {
+	resolved, err := exec.LookPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg not found: %w", err)
+	}
+	return &Runner{Path: resolved}, nil
+}
+
+// Transcode returns the clip of the audio file at path, encoded in the given
+// ffmpeg output format such as "mp3" or "wav".
+func (r *Runner) Transcode(ctx context.Context, path, format string, clip audio.Clip) ([]byte, error) {
+	args := []string{"-nostdin", "-v", "error", "-ss", strconv.FormatFloat(clip.Start, 'f', -1, 64)}
+	if clip.Duration > 0 {
+		args = append(args, "-t", strconv.FormatFloat(clip.Duration, 'f', -1, 64))
+	}
+	args = append(args, "-i", path, "-map", "0:a:0", "-map_metadata", "-1")
+	if clip.SampleRate > 0 {
+		args = append(args, "-ar", strconv.Itoa(clip.SampleRate))
+	}
+	args = append(args, "-f", format, "pipe:1")
+
+	out, err := r.run(ctx, args, clip.MaxBytes)
+	if err == nil && len(out) == 0 {
+		return nil, errors.New("ffmpeg produced no audio; is start past the end?")
+	}
+	return out, err
+}
+
+// Frame returns the frame of the video file at path shown the given number
+// of seconds in, encoded as a PNG of at most maxBytes.
+func (r *Runner) Frame(ctx context.Context, path string, at float64, maxBytes int64) ([]byte, error) {
+	args := []string{"-nostdin", "-v", "error", "-ss", strconv.FormatFloat(at, 'f', -1, 64),
+		"-i", path, "-map", "0:v:0", "-frames:v", "1", "-c:v", "png", "-f", "image2pipe", "pipe:1"}
+	out, err := r.run(ctx, args, maxBytes)
+	if err == nil && len(out) == 0 {
+		return nil, errors.New("ffmpeg produced no frame; is the time past the end?")
+	}
+	return out, err
+}
+
+// run runs ffmpeg with args and returns what it writes to stdout, failing
+// once that exceeds maxBytes.
+func (r *Runner) run(ctx context.Context, args []string, maxBytes int64) ([]byte, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, r.Path, args...)
+	out := &limitedBuffer{max: maxBytes, cancel: cancel}
+	var stderr bytes.Buffer
+	cmd.Stdout = out
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if out.exceeded {
+		return nil, fmt.Errorf("output is more than the %d bytes allowed", maxBytes)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("ffmpeg: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("ffmpeg: %w", err)
+	}
+	return out.buf.Bytes(), nil
+}
+
+// limitedBuffer collects ffmpeg's output, stopping it once more than max
+// bytes arrive. The buffer is not embedded so that its ReadFrom method does
+// not bypass the limit.
+type limitedBuffer struct {
+	buf      bytes.Buffer
+	max      int64
+	cancel   func()
+	exceeded bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.max > 0 && int64(b.buf.Len()+len(p)) > b.max {
+		b.exceeded = true
+		b.cancel()
+		return 0, errors.New("output too large")
+	}
+	return b.buf.Write(p)
+}
@@ -0,0 +1,63 @@
+package ffmpeg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/portertech/filesystem-mcp-server/internal/audio"
+)
+
+// echoRunner returns a Runner whose stand-in for ffmpeg prints its
+// arguments as its output.
+func echoRunner(t *testing.T) *Runner {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script in place of ffmpeg")
+	}
+	script := filepath.Join(t.TempDir(), "ffmpeg")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho \"$@\"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	ff, err := New(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ff
+}
+
+func TestTranscode(t *testing.T) {
+	ff := echoRunner(t)
+
+	out, err := ff.Transcode(context.Background(), "/in.mp3", "mp3", audio.Clip{Start: 1.5, Duration: 2, SampleRate: 16000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "-nostdin -v error -ss 1.5 -t 2 -i /in.mp3 -map 0:a:0 -map_metadata -1 -ar 16000 -f mp3 pipe:1\n"
+	if string(out) != want {
+		t.Errorf("got args %q, want %q", out, want)
+	}
+
+	if _, err := ff.Transcode(context.Background(), "/in.mp3", "mp3", audio.Clip{MaxBytes: 10}); err == nil || !strings.Contains(err.Error(), "allowed") {
+		t.Errorf("expected the size cap to stop ffmpeg, got %v", err)
+	}
+	if _, err := New(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected a missing binary to be rejected")
+	}
+}
+
+func TestFrame(t *testing.T) {
+	ff := echoRunner(t)
+
+	out, err := ff.Frame(context.Background(), "/in.mp4", 12.5, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "-nostdin -v error -ss 12.5 -i /in.mp4 -map 0:v:0 -frames:v 1 -c:v png -f image2pipe pipe:1\n"
+	if string(out) != want {
+		t.Errorf("got args %q, want %q", out, want)
+	}
+}
@@ -0,0 +1,43 @@
+package filelock
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+// maxRetryDelay caps the wait between attempts to take a lock file.
+const maxRetryDelay = 50 * time.Millisecond
+
+// Acquire takes an exclusive lock on the file at path, creating it if
+// needed, for processes that coordinate through a lock file. It waits up
+// to wait for another process to release the lock, and returns ErrLocked
+// if it does not. Where files cannot be locked, it returns at once with a
+// release that does nothing, leaving callers to coordinate within the
+// process.
+func Acquire(path string, wait time.Duration) (release func(), err error) {
+	if !Supported {
+		return func() {}, nil
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(wait)
+	delay := time.Millisecond
+	for {
+		err := tryLock(f)
+		if err == nil {
+			return func() {
+				unlock(f)
+				f.Close()
+			}, nil
+		}
+		if !errors.Is(err, ErrLocked) || !time.Now().Before(deadline) {
+			f.Close()
+			return nil, err
+		}
+		time.Sleep(delay)
+		delay = min(2*delay, maxRetryDelay)
+	}
+}
@@ -0,0 +1,179 @@
+// Package filelock takes advisory locks on files on behalf of clients, with
+// a lease after which the lock is released even if the client never
+// unlocks it. Locks are taken with flock on Unix and LockFileEx on Windows,
+// so external processes using the same primitive see them.
+package filelock
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultLease is how long a lock lasts unless the client asks for
+	// another lease.
+	DefaultLease = 5 * time.Minute
+	// MaxLease is the longest lease a client may ask for.
+	MaxLease = time.Hour
+)
+
+var (
+	// ErrLocked is returned when another process holds the lock.
+	ErrLocked = errors.New("locked by another process")
+	// ErrNotHeld is returned for a path and token that name no lease.
+	ErrNotHeld = errors.New("no lock held with this token")
+	// ErrUnsupported is returned on platforms without file locking.
+	ErrUnsupported = errors.New("file locking is not supported on this platform")
+)
+
+// Lease is a lock held by the server for a client.
+type Lease struct {
+	Path  string `json:"path"`
+	Owner string `json:"owner,omitempty"`
+	// Token identifies the lease to renew or release it.
+	Token    string    `json:"token,omitempty"`
+	Acquired time.Time `json:"acquired"`
+	Expires  time.Time `json:"expires"`
+}
+
+// Status is the lock state of a file.
+type Status struct {
+	// Lease is set, without its token, when the server holds the lock.
+	Lease *Lease
+	// External is set when another process holds the lock.
+	External bool
+}
+
+// held is a lease and the open file its lock is on.
+type held struct {
+	Lease
+	file  *os.File
+	timer *time.Timer
+}
+
+// Manager holds the leases of the server. It is safe for concurrent use.
+type Manager struct {
+	mu     sync.Mutex
+	leases map[string]*held
+	now    func() time.Time
+}
+
+// NewManager creates a Manager holding no leases.
+func NewManager() *Manager {
+	return &Manager{leases: make(map[string]*held), now: time.Now}
+}
+
+// Lock takes an exclusive lock on the file at path for lease, without
+// waiting, on behalf of owner.
+func (m *Manager) Lock(path, owner string, lease time.Duration) (Lease, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if h, ok := m.leases[path]; ok {
+		if h.Owner != "" {
+			return Lease{}, fmt.Errorf("already locked by %s until %s", h.Owner, h.Expires.Format(time.RFC3339))
+		}
+		return Lease{}, fmt.Errorf("already locked until %s", h.Expires.Format(time.RFC3339))
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return Lease{}, err
+	}
+	if err := tryLock(f); err != nil {
+		f.Close()
+		return Lease{}, err
+	}
+
+	b := make([]byte, 16)
+	rand.Read(b)
+	now := m.now()
+	h := &held{
+		Lease: Lease{Path: path, Owner: owner, Token: hex.EncodeToString(b), Acquired: now, Expires: now.Add(lease)},
+		file:  f,
+	}
+	h.timer = time.AfterFunc(lease, func() { m.expire(h) })
+	m.leases[path] = h
+	return h.Lease, nil
+}
+
+// Renew extends the lease on path with the given token to lease from now.
+func (m *Manager) Renew(path, token string, lease time.Duration) (Lease, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h, ok := m.leases[path]
+	if !ok || h.Token != token {
+		return Lease{}, ErrNotHeld
+	}
+	h.Expires = m.now().Add(lease)
+	h.timer.Reset(lease)
+	return h.Lease, nil
+}
+
+// Unlock releases the lease on path with the given token.
+func (m *Manager) Unlock(path, token string) (Lease, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h, ok := m.leases[path]
+	if !ok || h.Token != token {
+		return Lease{}, ErrNotHeld
+	}
+	h.timer.Stop()
+	return h.Lease, m.releaseLocked(h)
+}
+
+// Status reports whether the file at path is locked, by the server or by
+// another process. Checking for another process's lock briefly takes and
+// releases the lock.
+func (m *Manager) Status(path string) (Status, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if h, ok := m.leases[path]; ok {
+		lease := h.Lease
+		lease.Token = ""
+		return Status{Lease: &lease}, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return Status{}, err
+	}
+	defer f.Close()
+	if err := tryLock(f); err != nil {
+		if errors.Is(err, ErrLocked) {
+			return Status{External: true}, nil
+		}
+		return Status{}, err
+	}
+	return Status{}, unlock(f)
+}
+
+// expire releases h when its lease runs out, unless it was renewed or
+// released in the meantime.
+func (m *Manager) expire(h *held) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.leases[h.Path] != h || m.now().Before(h.Expires) {
+		return
+	}
+	m.releaseLocked(h)
+}
+
+// releaseLocked unlocks and closes the file of h and forgets it.
+func (m *Manager) releaseLocked(h *held) error {
+	delete(m.leases, h.Path)
+	err := unlock(h.file)
+	if cerr := h.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,113 @@
+package filelock
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLockUnlock(t *testing.T) {
+	if !Supported {
+		t.Skip(ErrUnsupported)
+	}
+	path := filepath.Join(t.TempDir(), "f")
+	os.WriteFile(path, []byte("x"), 0644)
+	m := NewManager()
+
+	lease, err := m.Lock(path, "agent-a", time.Minute)
+	if err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+	if _, err := m.Lock(path, "agent-b", time.Minute); err == nil {
+		t.Error("expected a held lock to be refused")
+	}
+	if status, err := m.Status(path); err != nil || status.Lease == nil || status.Lease.Owner != "agent-a" || status.Lease.Token != "" {
+		t.Errorf("unexpected status: %+v, %v", status, err)
+	}
+
+	// Another process sees the lock
+	f, _ := os.Open(path)
+	defer f.Close()
+	if err := tryLock(f); !errors.Is(err, ErrLocked) {
+		t.Errorf("expected the file to be locked, got %v", err)
+	}
+
+	if _, err := m.Unlock(path, "wrong"); !errors.Is(err, ErrNotHeld) {
+		t.Errorf("expected a wrong token to be refused, got %v", err)
+	}
+	if _, err := m.Unlock(path, lease.Token); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+
+	if err := tryLock(f); err != nil {
+		t.Fatalf("expected the file to be unlocked, got %v", err)
+	}
+	if status, err := m.Status(path); err != nil || !status.External {
+		t.Errorf("expected a lock held elsewhere to be reported, got %+v, %v", status, err)
+	}
+	if _, err := m.Lock(path, "", time.Minute); !errors.Is(err, ErrLocked) {
+		t.Errorf("expected a lock held elsewhere to be refused, got %v", err)
+	}
+}
+
+func TestLeaseExpiry(t *testing.T) {
+	if !Supported {
+		t.Skip(ErrUnsupported)
+	}
+	path := filepath.Join(t.TempDir(), "f")
+	os.WriteFile(path, []byte("x"), 0644)
+	m := NewManager()
+
+	lease, err := m.Lock(path, "", 20*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+	if _, err := m.Renew(path, lease.Token, 200*time.Millisecond); err != nil {
+		t.Fatalf("Renew: %v", err)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if status, _ := m.Status(path); status.Lease == nil {
+		t.Fatal("expected the renewed lease to be held")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if status, _ := m.Status(path); status.Lease == nil && !status.External {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the lease to expire")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestAcquire(t *testing.T) {
+	if !Supported {
+		t.Skip(ErrUnsupported)
+	}
+	path := filepath.Join(t.TempDir(), "lock")
+	release, err := Acquire(path, 0)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	if _, err := Acquire(path, 20*time.Millisecond); !errors.Is(err, ErrLocked) {
+		t.Errorf("expected ErrLocked while held, got %v", err)
+	}
+
+	done := make(chan error)
+	go func() {
+		r, err := Acquire(path, 5*time.Second)
+		if err == nil {
+			r()
+		}
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	release()
+	if err := <-done; err != nil {
+		t.Errorf("expected a waiting Acquire to get the lock once released, got %v", err)
+	}
+}
@@ -0,0 +1,304 @@
+// Package font reads the names and glyph counts of font files without
+// rendering them. It understands TrueType and OpenType fonts, TrueType
+// collections, and WOFF; WOFF2 needs Brotli, which the standard library
+// lacks, and is reported as unsupported.
+package font
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"unicode/utf16"
+)
+
+// maxTableSize caps the size of a decompressed WOFF table.
+const maxTableSize = 16 * 1024 * 1024
+
+// ErrUnsupported is returned for files that are not fonts this package can
+// read.
+var ErrUnsupported = errors.New("unsupported font format")
+
+var errTruncated = errors.New("font data is truncated")
+
+// Face describes one font in a file. Collections hold several.
+type Face struct {
+	Family         string `json:"family,omitempty"`
+	Style          string `json:"style,omitempty"`
+	FullName       string `json:"fullName,omitempty"`
+	PostScriptName string `json:"postScriptName,omitempty"`
+	Version        string `json:"version,omitempty"`
+	Copyright      string `json:"copyright,omitempty"`
+	Glyphs         int    `json:"glyphs"`
+	// Weight is the OS/2 weight class, 100 (thin) to 900 (black).
+	Weight int `json:"weight,omitempty"`
+	// Outlines is "TrueType" for glyf outlines or "CFF" for PostScript
+	// outlines.
+	Outlines string   `json:"outlines,omitempty"`
+	Tables   []string `json:"tables"`
+}
+
+// Info is what Parse learns about a font file.
+type Info struct {
+	// Format is "truetype", "opentype", "collection", or "woff".
+	Format string `json:"format"`
+	Faces  []Face `json:"faces"`
+}
+
+// table locates one table of a font.
+type table struct {
+	offset, length uint32
+	// compLength is the compressed length of a WOFF table, or zero.
+	compLength uint32
+}
+
+// Parse reads the font in data.
+func Parse(data []byte) (Info, error) {
+	if len(data) < 12 {
+		return Info{}, ErrUnsupported
+	}
+	switch tag := string(data[0:4]); tag {
+	case "ttcf":
+		count := int(binary.BigEndian.Uint32(data[8:12]))
+		if count == 0 || count > 1024 || 12+4*count > len(data) {
+			return Info{}, errors.New("invalid font collection header")
+		}
+		info := Info{Format: "collection"}
+		for i := range count {
+			off := binary.BigEndian.Uint32(data[12+4*i:])
+			face, err := parseSFNT(data, off)
+			if err != nil {
+				return Info{}, fmt.Errorf("font %d: %w", i, err)
+			}
+			info.Faces = append(info.Faces, face)
+		}
+		return info, nil
+	case "wOFF":
+		face, err := parseWOFF(data)
+		if err != nil {
+			return Info{}, err
+		}
+		return Info{Format: "woff", Faces: []Face{face}}, nil
+	case "wOF2":
+		return Info{}, fmt.Errorf("%w: WOFF2 needs Brotli decompression", ErrUnsupported)
+	case "\x00\x01\x00\x00", "true", "OTTO":
+		face, err := parseSFNT(data, 0)
+		if err != nil {
+			return Info{}, err
+		}
+		format := "truetype"
+		if tag == "OTTO" {
+			format = "opentype"
+		}
+		return Info{Format: format, Faces: []Face{face}}, nil
+	default:
+		return Info{}, ErrUnsupported
+	}
+}
+
+// parseSFNT reads the font whose offset table starts at off.
+func parseSFNT(data []byte, off uint32) (Face, error) {
+	if uint64(off)+12 > uint64(len(data)) {
+		return Face{}, errTruncated
+	}
+	numTables := int(binary.BigEndian.Uint16(data[off+4:]))
+	dir := data[off+12:]
+	if len(dir) < 16*numTables {
+		return Face{}, errTruncated
+	}
+	tables := make(map[string]table, numTables)
+	for i := range numTables {
+		rec := dir[16*i:]
+		tables[string(rec[0:4])] = table{offset: binary.BigEndian.Uint32(rec[8:]), length: binary.BigEndian.Uint32(rec[12:])}
+	}
+	return readFace(tables, func(t table) ([]byte, error) {
+		end := uint64(t.offset) + uint64(t.length)
+		if end > uint64(len(data)) {
+			return nil, errTruncated
+		}
+		return data[t.offset:end], nil
+	})
+}
+
+// parseWOFF reads a WOFF font, whose tables may be zlib compressed.
+func parseWOFF(data []byte) (Face, error) {
+	if len(data) < 44 {
+		return Face{}, errTruncated
+	}
+	numTables := int(binary.BigEndian.Uint16(data[12:]))
+	dir := data[44:]
+	if len(dir) < 20*numTables {
+		return Face{}, errTruncated
+	}
+	tables := make(map[string]table, numTables)
+	for i := range numTables {
+		rec := dir[20*i:]
+		tables[string(rec[0:4])] = table{
+			offset:     binary.BigEndian.Uint32(rec[4:]),
+			compLength: binary.BigEndian.Uint32(rec[8:]),
+			length:     binary.BigEndian.Uint32(rec[12:]),
+		}
+	}
+	return readFace(tables, func(t table) ([]byte, error) {
+		end := uint64(t.offset) + uint64(t.compLength)
+		if end > uint64(len(data)) {
+			return nil, errTruncated
+		}
+		raw := data[t.offset:end]
+		if t.compLength >= t.length {
+			return raw, nil
+		}
+		if t.length > maxTableSize {
+			return nil, fmt.Errorf("table of %d bytes is too large", t.length)
+		}
+		zr, err := zlib.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		out := make([]byte, t.length)
+		if _, err := io.ReadFull(zr, out); err != nil {
+			return nil, fmt.Errorf("failed to decompress table: %w", err)
+		}
+		return out, nil
+	})
+}
+
+// readFace fills in a Face from a font's tables, loading each with load.
+func readFace(tables map[string]table, load func(table) ([]byte, error)) (Face, error) {
+	face := Face{Tables: make([]string, 0, len(tables))}
+	for tag := range tables {
+		face.Tables = append(face.Tables, tag)
+	}
+	sort.Strings(face.Tables)
+
+	switch {
+	case hasTable(tables, "glyf"):
+		face.Outlines = "TrueType"
+	case hasTable(tables, "CFF "), hasTable(tables, "CFF2"):
+		face.Outlines = "CFF"
+	}
+
+	if t, ok := tables["maxp"]; ok {
+		maxp, err := load(t)
+		if err != nil {
+			return Face{}, fmt.Errorf("maxp table: %w", err)
+		}
+		if len(maxp) >= 6 {
+			face.Glyphs = int(binary.BigEndian.Uint16(maxp[4:]))
+		}
+	}
+	if t, ok := tables["OS/2"]; ok {
+		os2, err := load(t)
+		if err != nil {
+			return Face{}, fmt.Errorf("OS/2 table: %w", err)
+		}
+		if len(os2) >= 6 {
+			face.Weight = int(binary.BigEndian.Uint16(os2[4:]))
+		}
+	}
+	if t, ok := tables["name"]; ok {
+		name, err := load(t)
+		if err != nil {
+			return Face{}, fmt.Errorf("name table: %w", err)
+		}
+		names := parseNames(name)
+		face.Copyright = names[0]
+		face.Family = firstNonEmpty(names[16], names[1])
+		face.Style = firstNonEmpty(names[17], names[2])
+		face.FullName = names[4]
+		face.Version = names[5]
+		face.PostScriptName = names[6]
+	}
+	return face, nil
+}
+
+func hasTable(tables map[string]table, tag string) bool {
+	_, ok := tables[tag]
+	return ok
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Platform IDs of name records.
+const (
+	platformUnicode = 0
+	platformMac     = 1
+	platformWindows = 3
+)
+
+// parseNames returns the strings of a name table by name ID, preferring
+// Windows English names, then Unicode, then Mac names.
+func parseNames(data []byte) map[int]string {
+	names := make(map[int]string)
+	if len(data) < 6 {
+		return names
+	}
+	count := int(binary.BigEndian.Uint16(data[2:]))
+	storage := int(binary.BigEndian.Uint16(data[4:]))
+	rank := make(map[int]int)
+	for i := range count {
+		if 6+12*(i+1) > len(data) {
+			break
+		}
+		rec := data[6+12*i:]
+		platform := binary.BigEndian.Uint16(rec[0:])
+		encoding := binary.BigEndian.Uint16(rec[2:])
+		language := binary.BigEndian.Uint16(rec[4:])
+		id := int(binary.BigEndian.Uint16(rec[6:]))
+		length := int(binary.BigEndian.Uint16(rec[8:]))
+		offset := storage + int(binary.BigEndian.Uint16(rec[10:]))
+		if offset+length > len(data) {
+			continue
+		}
+		raw := data[offset : offset+length]
+
+		var r int
+		var s string
+		switch {
+		case platform == platformWindows && (encoding == 1 || encoding == 10):
+			r, s = 3, decodeUTF16(raw)
+			if language == 0x409 {
+				r = 4
+			}
+		case platform == platformUnicode:
+			r, s = 2, decodeUTF16(raw)
+		case platform == platformMac && encoding == 0:
+			r, s = 1, decodeLatin1(raw)
+		default:
+			continue
+		}
+		if r > rank[id] {
+			rank[id], names[id] = r, s
+		}
+	}
+	return names
+}
+
+func decodeUTF16(b []byte) string {
+	u := make([]uint16, len(b)/2)
+	for i := range u {
+		u[i] = binary.BigEndian.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(u))
+}
+
+// decodeLatin1 decodes Mac Roman names, which match Latin-1 for ASCII and
+// are close enough elsewhere for display.
+func decodeLatin1(b []byte) string {
+	r := make([]rune, len(b))
+	for i, c := range b {
+		r[i] = rune(c)
+	}
+	return string(r)
+}
@@ -0,0 +1,155 @@
+package font
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"unicode/utf16"
+)
+
+// nameTable builds a name table with Windows English records for names.
+func nameTable(names map[uint16]string) []byte {
+	var records, storage bytes.Buffer
+	for _, id := range []uint16{0, 1, 2, 4, 5, 6, 16, 17} {
+		s, ok := names[id]
+		if !ok {
+			continue
+		}
+		var encoded []byte
+		for _, u := range utf16.Encode([]rune(s)) {
+			encoded = binary.BigEndian.AppendUint16(encoded, u)
+		}
+		binary.Write(&records, binary.BigEndian, []uint16{platformWindows, 1, 0x409, id, uint16(len(encoded)), uint16(storage.Len())})
+		storage.Write(encoded)
+	}
+	count := records.Len() / 12
+	out := binary.BigEndian.AppendUint16(nil, 0)
+	out = binary.BigEndian.AppendUint16(out, uint16(count))
+	out = binary.BigEndian.AppendUint16(out, uint16(6+records.Len()))
+	return append(append(out, records.Bytes()...), storage.Bytes()...)
+}
+
+// sfnt builds a font with the given tables, in tag order.
+func sfnt(version string, tables map[string][]byte) []byte {
+	tags := []string{"OS/2", "glyf", "maxp", "name"}
+	var present []string
+	for _, tag := range tags {
+		if _, ok := tables[tag]; ok {
+			present = append(present, tag)
+		}
+	}
+	out := []byte(version)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(present)))
+	out = append(out, make([]byte, 6)...)
+	offset := 12 + 16*len(present)
+	var body []byte
+	for _, tag := range present {
+		out = append(out, tag...)
+		out = binary.BigEndian.AppendUint32(out, 0)
+		out = binary.BigEndian.AppendUint32(out, uint32(offset+len(body)))
+		out = binary.BigEndian.AppendUint32(out, uint32(len(tables[tag])))
+		body = append(body, tables[tag]...)
+	}
+	return append(out, body...)
+}
+
+func testTables() map[string][]byte {
+	maxp := []byte{0, 0, 0x50, 0, 0x01, 0x2C} // version 0.5, 300 glyphs
+	os2 := []byte{0, 4, 0, 0, 0x02, 0xBC}     // weight 700
+	return map[string][]byte{
+		"maxp": maxp,
+		"OS/2": os2,
+		"glyf": {},
+		"name": nameTable(map[uint16]string{1: "Example", 2: "Bold", 4: "Example Bold", 5: "Version 1.002", 6: "Example-Bold", 16: "Example Sans"}),
+	}
+}
+
+func TestParseTrueType(t *testing.T) {
+	info, err := Parse(sfnt("\x00\x01\x00\x00", testTables()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Format != "truetype" || len(info.Faces) != 1 {
+		t.Fatalf("unexpected info %+v", info)
+	}
+	face := info.Faces[0]
+	// The typographic family (name 16) wins over the legacy one
+	if face.Family != "Example Sans" || face.Style != "Bold" || face.FullName != "Example Bold" || face.PostScriptName != "Example-Bold" || face.Version != "Version 1.002" {
+		t.Errorf("unexpected names %+v", face)
+	}
+	if face.Glyphs != 300 || face.Weight != 700 || face.Outlines != "TrueType" || len(face.Tables) != 4 {
+		t.Errorf("unexpected face %+v", face)
+	}
+}
+
+func TestParseCollection(t *testing.T) {
+	font := sfnt("OTTO", testTables())
+	data := []byte("ttcf\x00\x01\x00\x00")
+	data = binary.BigEndian.AppendUint32(data, 2)
+	data = binary.BigEndian.AppendUint32(data, 20)
+	data = binary.BigEndian.AppendUint32(data, 20)
+	// Table offsets are relative to the file, so shift them past the header
+	shifted := bytes.Clone(font)
+	for i := range 4 {
+		rec := shifted[12+16*i:]
+		binary.BigEndian.PutUint32(rec[8:], binary.BigEndian.Uint32(rec[8:])+20)
+	}
+	info, err := Parse(append(data, shifted...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Format != "collection" || len(info.Faces) != 2 || info.Faces[1].Glyphs != 300 {
+		t.Errorf("unexpected info %+v", info)
+	}
+}
+
+func TestParseWOFF(t *testing.T) {
+	tables := testTables()
+	tags := []string{"OS/2", "glyf", "maxp", "name"}
+	dir := []byte{}
+	var body []byte
+	offset := 44 + 20*len(tags)
+	for _, tag := range tags {
+		data := tables[tag]
+		stored := data
+		if tag == "name" {
+			var z bytes.Buffer
+			zw := zlib.NewWriter(&z)
+			zw.Write(data)
+			zw.Close()
+			stored = z.Bytes()
+		}
+		dir = append(dir, tag...)
+		dir = binary.BigEndian.AppendUint32(dir, uint32(offset+len(body)))
+		dir = binary.BigEndian.AppendUint32(dir, uint32(len(stored)))
+		dir = binary.BigEndian.AppendUint32(dir, uint32(len(data)))
+		dir = binary.BigEndian.AppendUint32(dir, 0)
+		body = append(body, stored...)
+	}
+	header := make([]byte, 44)
+	copy(header, "wOFF\x00\x01\x00\x00")
+	binary.BigEndian.PutUint16(header[12:], uint16(len(tags)))
+
+	info, err := Parse(append(append(header, dir...), body...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Format != "woff" || info.Faces[0].FullName != "Example Bold" || info.Faces[0].Glyphs != 300 {
+		t.Errorf("unexpected info %+v", info)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	if _, err := Parse([]byte("wOF2 and then some bytes")); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("woff2: got %v, want ErrUnsupported", err)
+	}
+	if _, err := Parse([]byte("plain text, not a font")); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("text: got %v, want ErrUnsupported", err)
+	}
+	truncated := sfnt("\x00\x01\x00\x00", testTables())[:40]
+	if _, err := Parse(truncated); err == nil {
+		t.Error("expected an error for a truncated font")
+	}
+}
@@ -0,0 +1,220 @@
+// Package grant implements operator-issued write grants: signed tokens that
+// make a read-only directory writable for a limited time and, optionally, a
+// limited number of operations. Tokens are signed with a key only the
+// operator holds, so an agent cannot mint its own; it can only activate one
+// it was given.
+package grant
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenPrefix marks and versions grant tokens.
+const tokenPrefix = "fsgrant1"
+
+// MinKeyLength is the minimum size in bytes of a signing key.
+const MinKeyLength = 32
+
+// ErrInvalidToken is returned for tokens that are malformed or not signed
+// with the server's key.
+var ErrInvalidToken = errors.New("invalid grant token")
+
+// Grant is what a token authorizes.
+type Grant struct {
+	ID      string    `json:"id"`
+	Root    string    `json:"root"`
+	Expires time.Time `json:"expires"`
+	// MaxOps caps the number of write operations; 0 means no cap.
+	MaxOps int    `json:"maxOps,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Status is an activated grant and how much of it has been used.
+type Status struct {
+	Grant
+	Activated time.Time `json:"activated"`
+	Used      int       `json:"used"`
+}
+
+// Remaining returns the operations left, or -1 when the grant has no cap.
+func (s Status) Remaining() int {
+	if s.MaxOps == 0 {
+		return -1
+	}
+	return s.MaxOps - s.Used
+}
+
+// LoadKey reads a signing key from path.
+func LoadKey(path string) ([]byte, error) {
+	key, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) < MinKeyLength {
+		return nil, fmt.Errorf("grant key %s is %d bytes, need at least %d", path, len(key), MinKeyLength)
+	}
+	return key, nil
+}
+
+// Issue creates a token granting writes under root until ttl has passed.
+func Issue(key []byte, root string, ttl time.Duration, maxOps int, reason string) (string, Grant, error) {
+	if ttl <= 0 {
+		return "", Grant{}, errors.New("grant duration must be positive")
+	}
+	if maxOps < 0 {
+		return "", Grant{}, errors.New("grant operation count cannot be negative")
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", Grant{}, err
+	}
+	g := Grant{
+		ID:      hex.EncodeToString(id),
+		Root:    root,
+		Expires: time.Now().Add(ttl).UTC().Truncate(time.Second),
+		MaxOps:  maxOps,
+		Reason:  reason,
+	}
+	payload, err := json.Marshal(g)
+	if err != nil {
+		return "", Grant{}, err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return tokenPrefix + "." + encoded + "." + sign(key, encoded), g, nil
+}
+
+// Verify checks token's signature and returns the grant it carries. It does
+// not check expiry.
+func Verify(key []byte, token string) (Grant, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 || parts[0] != tokenPrefix {
+		return Grant{}, ErrInvalidToken
+	}
+	if !hmac.Equal([]byte(parts[2]), []byte(sign(key, parts[1]))) {
+		return Grant{}, ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return Grant{}, ErrInvalidToken
+	}
+	var g Grant
+	if err := json.Unmarshal(payload, &g); err != nil || g.ID == "" || g.Root == "" {
+		return Grant{}, ErrInvalidToken
+	}
+	return g, nil
+}
+
+func sign(key []byte, payload string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(tokenPrefix + "." + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Manager tracks activated grants. Each token can be activated once.
+type Manager struct {
+	mu     sync.Mutex
+	key    []byte
+	active map[string]*Status
+	seen   map[string]bool
+	now    func() time.Time
+}
+
+// NewManager creates a manager accepting tokens signed with key.
+func NewManager(key []byte) *Manager {
+	return &Manager{
+		key:    key,
+		active: make(map[string]*Status),
+		seen:   make(map[string]bool),
+		now:    time.Now,
+	}
+}
+
+// Verify checks token against the manager's key without activating it.
+func (m *Manager) Verify(token string) (Grant, error) {
+	return Verify(m.key, token)
+}
+
+// Activate verifies token and starts the grant it carries.
+func (m *Manager) Activate(token string) (Status, error) {
+	g, err := Verify(m.key, token)
+	if err != nil {
+		return Status{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	if !now.Before(g.Expires) {
+		return Status{}, fmt.Errorf("grant %s expired at %s", g.ID, g.Expires.Format(time.RFC3339))
+	}
+	if m.seen[g.ID] {
+		return Status{}, fmt.Errorf("grant %s has already been activated", g.ID)
+	}
+	m.seen[g.ID] = true
+	s := &Status{Grant: g, Activated: now}
+	m.active[g.ID] = s
+	return *s, nil
+}
+
+// Use spends one operation of an active grant for root, preferring the one
+// expiring soonest, and returns its updated status. It reports false when
+// no grant for root is active.
+func (m *Manager) Use(root string) (Status, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pruneLocked()
+
+	var best *Status
+	for _, s := range m.active {
+		if s.Root == root && (best == nil || s.Expires.Before(best.Expires)) {
+			best = s
+		}
+	}
+	if best == nil {
+		return Status{}, false
+	}
+	best.Used++
+	used := *best
+	if best.Remaining() == 0 {
+		delete(m.active, best.ID)
+	}
+	return used, true
+}
+
+// Active returns the grants still in effect, soonest expiring first.
+func (m *Manager) Active() []Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pruneLocked()
+
+	result := make([]Status, 0, len(m.active))
+	for _, s := range m.active {
+		result = append(result, *s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Expires.Before(result[j].Expires)
+	})
+	return result
+}
+
+// pruneLocked drops expired grants.
+func (m *Manager) pruneLocked() {
+	now := m.now()
+	for id, s := range m.active {
+		if !now.Before(s.Expires) {
+			delete(m.active, id)
+		}
+	}
+}
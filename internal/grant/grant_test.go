@@ -0,0 +1,120 @@
+package grant
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testKey = []byte(strings.Repeat("k", MinKeyLength))
+
+func TestIssueVerify(t *testing.T) {
+	token, g, err := Issue(testKey, "/srv/data", time.Hour, 3, "fix config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Verify(testKey, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != g {
+		t.Errorf("Verify() = %+v, want %+v", got, g)
+	}
+
+	otherKey := []byte(strings.Repeat("x", MinKeyLength))
+	if _, err := Verify(otherKey, token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("wrong key: got %v", err)
+	}
+	parts := strings.Split(token, ".")
+	forged := parts[0] + "." + parts[1] + "x." + parts[2]
+	if _, err := Verify(testKey, forged); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("tampered payload: got %v", err)
+	}
+	if _, err := Verify(testKey, "garbage"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("garbage: got %v", err)
+	}
+
+	if _, _, err := Issue(testKey, "/srv/data", 0, 0, ""); err == nil {
+		t.Error("expected error for zero duration")
+	}
+}
+
+func TestManager(t *testing.T) {
+	m := NewManager(testKey)
+	now := time.Now()
+	m.now = func() time.Time { return now }
+
+	token, _, err := Issue(testKey, "/srv/data", time.Hour, 2, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.Use("/srv/data"); ok {
+		t.Error("Use() before activation should fail")
+	}
+	if _, err := m.Activate(token); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Activate(token); err == nil {
+		t.Error("expected error activating a token twice")
+	}
+
+	if _, ok := m.Use("/srv/other"); ok {
+		t.Error("grant should not cover another root")
+	}
+	for i := 1; i <= 2; i++ {
+		s, ok := m.Use("/srv/data")
+		if !ok || s.Used != i {
+			t.Fatalf("Use() #%d = %+v, %v", i, s, ok)
+		}
+	}
+	if _, ok := m.Use("/srv/data"); ok {
+		t.Error("grant should be exhausted")
+	}
+	if len(m.Active()) != 0 {
+		t.Error("exhausted grant should not be active")
+	}
+
+	token, _, err = Issue(testKey, "/srv/data", time.Hour, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Activate(token); err != nil {
+		t.Fatal(err)
+	}
+	if s := m.Active(); len(s) != 1 || s[0].Remaining() != -1 {
+		t.Errorf("Active() = %+v", s)
+	}
+	now = now.Add(2 * time.Hour)
+	if _, ok := m.Use("/srv/data"); ok {
+		t.Error("expired grant should not be usable")
+	}
+
+	expired, _, err := Issue(testKey, "/srv/data", time.Minute, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Activate(expired); err == nil {
+		t.Error("expected error activating an expired token")
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+	dir := t.TempDir()
+	short := filepath.Join(dir, "short")
+	if err := os.WriteFile(short, []byte("tiny"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadKey(short); err == nil {
+		t.Error("expected error for short key")
+	}
+	good := filepath.Join(dir, "good")
+	if err := os.WriteFile(good, testKey, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadKey(good); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,104 @@
+// Package idempotency remembers the results of mutating tool calls by a
+// client-chosen key, so a call retried after a client timeout returns the
+// original result instead of applying the change a second time. Keys share
+// one namespace across all tools, so a key identifies one operation.
+package idempotency
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// KeyParam is the tool argument that carries an idempotency key.
+const KeyParam = "idempotencyKey"
+
+// DefaultTTL is how long a result is remembered.
+const DefaultTTL = time.Hour
+
+// ErrKeyReused is returned when a key is sent with a different tool or
+// different arguments than the call it was first used for.
+var ErrKeyReused = errors.New("idempotency key was already used for a different call")
+
+// Store holds the results of calls by key.
+type Store struct {
+	mu      sync.Mutex
+	entries map[string]*entry
+	ttl     time.Duration
+	now     func() time.Time
+}
+
+type entry struct {
+	fingerprint string
+	done        chan struct{}
+	result      any
+	kept        bool
+	expires     time.Time
+}
+
+// New creates a store remembering results for ttl.
+func New(ttl time.Duration) *Store {
+	return &Store{
+		entries: make(map[string]*entry),
+		ttl:     ttl,
+		now:     time.Now,
+	}
+}
+
+// Do runs fn for the first call with key and returns its result. A later
+// call with the same key and fingerprint gets that result back, with
+// replayed set, without running fn; if the first call is still running, it
+// waits for it. fn reports whether its result should be kept. A result that
+// is not kept, such as an error, releases the key so a retry runs again.
+func (s *Store) Do(key, fingerprint string, fn func() (any, bool)) (result any, replayed bool, err error) {
+	for {
+		s.mu.Lock()
+		s.pruneLocked()
+		e, ok := s.entries[key]
+		if !ok {
+			e = &entry{fingerprint: fingerprint, done: make(chan struct{})}
+			s.entries[key] = e
+			s.mu.Unlock()
+			return s.run(key, e, fn), false, nil
+		}
+		s.mu.Unlock()
+
+		if e.fingerprint != fingerprint {
+			return nil, false, ErrKeyReused
+		}
+		<-e.done
+		if e.kept {
+			return e.result, true, nil
+		}
+		// The first call's result was not kept and the key was released;
+		// try to claim it again
+	}
+}
+
+func (s *Store) run(key string, e *entry, fn func() (any, bool)) any {
+	kept := false
+	defer func() {
+		s.mu.Lock()
+		if kept {
+			e.kept = true
+			e.expires = s.now().Add(s.ttl)
+		} else if s.entries[key] == e {
+			delete(s.entries, key)
+		}
+		s.mu.Unlock()
+		close(e.done)
+	}()
+	result, keep := fn()
+	e.result, kept = result, keep
+	return result
+}
+
+// pruneLocked drops expired results. Calls still running are kept.
+func (s *Store) pruneLocked() {
+	now := s.now()
+	for key, e := range s.entries {
+		if e.kept && now.After(e.expires) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package idempotency
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDoReplays(t *testing.T) {
+	s := New(DefaultTTL)
+	runs := 0
+	fn := func() (any, bool) {
+		runs++
+		return runs, true
+	}
+
+	result, replayed, err := s.Do("k1", "fp", fn)
+	if err != nil || replayed || result != 1 {
+		t.Fatalf("first call: got %v, %v, %v", result, replayed, err)
+	}
+	result, replayed, err = s.Do("k1", "fp", fn)
+	if err != nil || !replayed || result != 1 {
+		t.Fatalf("retry: got %v, %v, %v", result, replayed, err)
+	}
+	if runs != 1 {
+		t.Errorf("expected one run, got %d", runs)
+	}
+
+	if _, _, err := s.Do("k1", "other", fn); !errors.Is(err, ErrKeyReused) {
+		t.Errorf("expected ErrKeyReused, got %v", err)
+	}
+}
+
+func TestDoReleasesUnkeptResults(t *testing.T) {
+	s := New(DefaultTTL)
+	runs := 0
+	fn := func() (any, bool) {
+		runs++
+		return runs, runs > 1
+	}
+
+	if result, _, _ := s.Do("k", "fp", fn); result != 1 {
+		t.Fatalf("expected first run, got %v", result)
+	}
+	result, replayed, _ := s.Do("k", "fp", fn)
+	if replayed || result != 2 {
+		t.Fatalf("expected a failed call to run again, got %v, %v", result, replayed)
+	}
+	if result, replayed, _ = s.Do("k", "fp", fn); !replayed || result != 2 {
+		t.Errorf("expected kept result to replay, got %v, %v", result, replayed)
+	}
+}
+
+func TestDoWaitsForRunningCall(t *testing.T) {
+	s := New(DefaultTTL)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var mu sync.Mutex
+	runs := 0
+	fn := func() (any, bool) {
+		mu.Lock()
+		runs++
+		mu.Unlock()
+		close(started)
+		<-release
+		return "done", true
+	}
+
+	go s.Do("k", "fp", fn)
+	<-started
+
+	done := make(chan any)
+	go func() {
+		result, _, _ := s.Do("k", "fp", fn)
+		done <- result
+	}()
+	close(release)
+	if result := <-done; result != "done" {
+		t.Errorf("expected the first call's result, got %v", result)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if runs != 1 {
+		t.Errorf("expected one run, got %d", runs)
+	}
+}
+
+func TestDoExpires(t *testing.T) {
+	s := New(time.Minute)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	runs := 0
+	fn := func() (any, bool) {
+		runs++
+		return runs, true
+	}
+
+	s.Do("k", "fp", fn)
+	now = now.Add(2 * time.Minute)
+	if result, replayed, _ := s.Do("k", "fp", fn); replayed || result != 2 {
+		t.Errorf("expected expired key to run again, got %v, %v", result, replayed)
+	}
+}
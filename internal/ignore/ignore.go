@@ -0,0 +1,104 @@
+// Package ignore matches paths against .gitignore files found while walking
+// a directory tree.
+package ignore
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+)
+
+// Matcher accumulates gitignore patterns for a tree rooted at a directory.
+// Directories must be loaded parent-first, as a top-down walk visits them,
+// so that deeper .gitignore files take precedence.
+type Matcher struct {
+	root     string
+	patterns []gitignore.Pattern
+	source   func(path string) (string, error)
+}
+
+// New creates a matcher for the tree at root, loading root's own
+// .gitignore and .git/info/exclude.
+func New(root string) (*Matcher, error) {
+	return NewWithSource(root, nil)
+}
+
+// NewWithSource is New for a tree whose files are read from elsewhere, such
+// as an overlay: source maps the path of each ignore file to the path to
+// read it from. A source reporting that the file does not exist is the same
+// as the file not existing.
+func NewWithSource(root string, source func(path string) (string, error)) (*Matcher, error) {
+	m := &Matcher{root: root, source: source}
+	if err := m.loadFile(filepath.Join(root, ".git", "info", "exclude"), nil); err != nil {
+		return nil, err
+	}
+	if err := m.loadFile(filepath.Join(root, ".gitignore"), nil); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// LoadDir adds the patterns from dir/.gitignore. Loading root is a no-op as
+// New already did so.
+func (m *Matcher) LoadDir(dir string) error {
+	domain := m.components(dir)
+	if len(domain) == 0 {
+		return nil
+	}
+	return m.loadFile(filepath.Join(dir, ".gitignore"), domain)
+}
+
+// Match reports whether path is ignored. The .git directory is always
+// ignored.
+func (m *Matcher) Match(path string, isDir bool) bool {
+	parts := m.components(path)
+	if len(parts) == 0 {
+		return false
+	}
+	if isDir && parts[len(parts)-1] == ".git" {
+		return true
+	}
+	return gitignore.NewMatcher(m.patterns).Match(parts, isDir)
+}
+
+// components splits path relative to the root.
+func (m *Matcher) components(path string) []string {
+	rel, err := filepath.Rel(m.root, path)
+	if err != nil || rel == "." {
+		return nil
+	}
+	return strings.Split(filepath.ToSlash(rel), "/")
+}
+
+func (m *Matcher) loadFile(path string, domain []string) error {
+	if m.source != nil {
+		var err error
+		if path, err = m.source(path); err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+			continue
+		}
+		m.patterns = append(m.patterns, gitignore.ParsePattern(line, domain))
+	}
+	return scanner.Err()
+}
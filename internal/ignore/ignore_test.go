@@ -0,0 +1,49 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatcher(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".gitignore"), []byte("# comment\n*.log\nbuild/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "sub", ".gitignore"), []byte("*.tmp\n!keep.log\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := New(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.LoadDir(filepath.Join(root, "sub")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"app.log", false, true},
+		{"main.go", false, false},
+		{"build", true, true},
+		{"build", false, false},
+		{".git", true, true},
+		{"sub/a.tmp", false, true},
+		{"a.tmp", false, false},
+		{"sub/keep.log", false, false},
+		{"sub/other.log", false, true},
+	}
+	for _, tt := range tests {
+		if got := m.Match(filepath.Join(root, filepath.FromSlash(tt.path)), tt.isDir); got != tt.want {
+			t.Errorf("Match(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+}
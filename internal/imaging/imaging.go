@@ -0,0 +1,182 @@
+// Package imaging downscales images before they are returned to a client, so
+// a full-resolution screenshot costs a few hundred kilobytes instead of
+// megabytes, and strips metadata such as GPS locations from them. Only the
+// standard library's codecs are used: JPEG, PNG, and GIF decode; JPEG and PNG
+// encode. Stripping rewrites the container without decoding, so it also
+// handles WebP, and so does ReadInfo, which reads the dimensions and EXIF
+// fields of an image from its headers alone.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // registers the GIF decoder
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+const (
+	// MaxPixels caps the size of an image that will be decoded, guarding
+	// against small files that expand to huge bitmaps.
+	MaxPixels = 50_000_000
+	// DefaultQuality is the JPEG quality used when none is requested.
+	DefaultQuality = 85
+)
+
+// ErrUnsupported is returned for images in a format that cannot be decoded.
+var ErrUnsupported = errors.New("unsupported image format")
+
+// Options controls how Resize scales and encodes an image.
+type Options struct {
+	// MaxWidth and MaxHeight bound the result; zero leaves a dimension
+	// unbounded. Images are only ever scaled down.
+	MaxWidth  int
+	MaxHeight int
+	// Quality is the JPEG quality, 1 to 100. When set, the result is always
+	// a JPEG. Otherwise JPEGs stay JPEGs at DefaultQuality and other formats
+	// become PNGs.
+	Quality int
+}
+
+// Result is a re-encoded image.
+type Result struct {
+	Data           []byte
+	MIMEType       string
+	Width          int
+	Height         int
+	OriginalWidth  int
+	OriginalHeight int
+}
+
+// Probe reads the dimensions and format name of the image in r without
+// decoding it. Images over MaxPixels are refused.
+func Probe(r io.Reader) (image.Config, string, error) {
+	cfg, format, err := image.DecodeConfig(r)
+	if errors.Is(err, image.ErrFormat) {
+		return cfg, format, ErrUnsupported
+	}
+	if err != nil {
+		return cfg, format, err
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > MaxPixels {
+		return cfg, format, fmt.Errorf("image is %dx%d, more than %d pixels", cfg.Width, cfg.Height, MaxPixels)
+	}
+	return cfg, format, nil
+}
+
+// DecodedSize estimates the memory Resize needs for an image: the decoded
+// image and the RGBA copy it is scaled from.
+func DecodedSize(cfg image.Config) int64 {
+	return int64(cfg.Width) * int64(cfg.Height) * 8
+}
+
+// Fit returns the dimensions of a w by h image scaled down to fit within
+// maxW by maxH, preserving its aspect ratio. A zero bound is ignored.
+func Fit(w, h, maxW, maxH int) (int, int) {
+	scale := 1.0
+	if maxW > 0 && w > maxW {
+		scale = float64(maxW) / float64(w)
+	}
+	if maxH > 0 && h > maxH {
+		scale = min(scale, float64(maxH)/float64(h))
+	}
+	if scale == 1 {
+		return w, h
+	}
+	return max(1, int(float64(w)*scale+0.5)), max(1, int(float64(h)*scale+0.5))
+}
+
+// Resize decodes the image in r, scales it down to fit opts, and encodes
+// the result. Call Probe first to check the image's size.
+func Resize(r io.Reader, opts Options) (Result, error) {
+	src, format, err := image.Decode(r)
+	if errors.Is(err, image.ErrFormat) {
+		return Result{}, ErrUnsupported
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	bounds := src.Bounds()
+	result := Result{OriginalWidth: bounds.Dx(), OriginalHeight: bounds.Dy()}
+	result.Width, result.Height = Fit(bounds.Dx(), bounds.Dy(), opts.MaxWidth, opts.MaxHeight)
+	img := src
+	if result.Width != bounds.Dx() || result.Height != bounds.Dy() {
+		img = downscale(src, result.Width, result.Height)
+	}
+
+	var buf bytes.Buffer
+	if opts.Quality > 0 || format == "jpeg" {
+		quality := opts.Quality
+		if quality == 0 {
+			quality = DefaultQuality
+		}
+		err = jpeg.Encode(&buf, flatten(img), &jpeg.Options{Quality: quality})
+		result.MIMEType = "image/jpeg"
+	} else {
+		err = (&png.Encoder{CompressionLevel: png.BestCompression}).Encode(&buf, img)
+		result.MIMEType = "image/png"
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to encode image: %w", err)
+	}
+	result.Data = buf.Bytes()
+	return result, nil
+}
+
+// downscale scales src to w by h by averaging the block of source pixels
+// that falls on each destination pixel.
+func downscale(src image.Image, w, h int) *image.RGBA {
+	// Copying into RGBA first takes the fast paths in image/draw and gives
+	// premultiplied pixels, which average correctly across transparency
+	b := src.Bounds()
+	rgba, ok := src.(*image.RGBA)
+	if !ok {
+		rgba = image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(rgba, rgba.Bounds(), src, b.Min, draw.Src)
+	}
+	sw, sh := rgba.Rect.Dx(), rgba.Rect.Dy()
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		y0, y1 := y*sh/h, max((y+1)*sh/h, y*sh/h+1)
+		for x := range w {
+			x0, x1 := x*sw/w, max((x+1)*sw/w, x*sw/w+1)
+			var r, g, bl, a uint64
+			for sy := y0; sy < y1; sy++ {
+				row := rgba.Pix[sy*rgba.Stride+x0*4 : sy*rgba.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					r += uint64(row[i])
+					g += uint64(row[i+1])
+					bl += uint64(row[i+2])
+					a += uint64(row[i+3])
+				}
+			}
+			n := uint64((y1 - y0) * (x1 - x0))
+			off := y*dst.Stride + x*4
+			dst.Pix[off] = uint8((r + n/2) / n)
+			dst.Pix[off+1] = uint8((g + n/2) / n)
+			dst.Pix[off+2] = uint8((bl + n/2) / n)
+			dst.Pix[off+3] = uint8((a + n/2) / n)
+		}
+	}
+	return dst
+}
+
+// flatten composites img onto white, since JPEG has no transparency and
+// transparent pixels would otherwise turn black.
+func flatten(img image.Image) image.Image {
+	if opaque, ok := img.(interface{ Opaque() bool }); ok && opaque.Opaque() {
+		return img
+	}
+	b := img.Bounds()
+	dst := image.NewRGBA(b)
+	draw.Draw(dst, b, image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, b, img, b.Min, draw.Over)
+	return dst
+}
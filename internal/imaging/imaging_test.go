@@ -0,0 +1,133 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		w, h, maxW, maxH int
+		wantW, wantH     int
+	}{
+		{4000, 3000, 1000, 0, 1000, 750},
+		{4000, 3000, 0, 600, 800, 600},
+		{4000, 3000, 1000, 600, 800, 600},
+		{800, 600, 1000, 1000, 800, 600},
+		{800, 600, 0, 0, 800, 600},
+		{10000, 1, 100, 0, 100, 1},
+	}
+	for _, tt := range tests {
+		w, h := Fit(tt.w, tt.h, tt.maxW, tt.maxH)
+		if w != tt.wantW || h != tt.wantH {
+			t.Errorf("Fit(%d, %d, %d, %d) = %d, %d; want %d, %d", tt.w, tt.h, tt.maxW, tt.maxH, w, h, tt.wantW, tt.wantH)
+		}
+	}
+}
+
+func TestResize(t *testing.T) {
+	// Left half black, right half white
+	src := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	for y := range 20 {
+		for x := range 40 {
+			c := color.NRGBA{A: 255}
+			if x >= 20 {
+				c = color.NRGBA{255, 255, 255, 255}
+			}
+			src.Set(x, y, c)
+		}
+	}
+	data := encodePNG(t, src)
+
+	cfg, format, err := Probe(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Width != 40 || cfg.Height != 20 || format != "png" {
+		t.Fatalf("Probe = %dx%d %s", cfg.Width, cfg.Height, format)
+	}
+
+	res, err := Resize(bytes.NewReader(data), Options{MaxWidth: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.MIMEType != "image/png" || res.Width != 4 || res.Height != 2 || res.OriginalWidth != 40 || res.OriginalHeight != 20 {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	out, err := png.Decode(bytes.NewReader(res.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := out.Bounds(); b.Dx() != 4 || b.Dy() != 2 {
+		t.Fatalf("decoded %v", b)
+	}
+	if r, _, _, _ := out.At(0, 0).RGBA(); r != 0 {
+		t.Errorf("left pixel red = %d, want 0", r)
+	}
+	if r, _, _, _ := out.At(3, 1).RGBA(); r != 0xffff {
+		t.Errorf("right pixel red = %d, want 0xffff", r)
+	}
+
+	// A quality converts to JPEG, flattening transparency onto white
+	clear := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	res, err = Resize(bytes.NewReader(encodePNG(t, clear)), Options{Quality: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.MIMEType != "image/jpeg" || res.Width != 8 {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	out, err = jpeg.Decode(bytes.NewReader(res.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, _, _, _ := out.At(4, 4).RGBA(); r < 0xf000 {
+		t.Errorf("transparent pixel red = %#x, want white", r)
+	}
+}
+
+func TestResizeJPEGStaysJPEG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 64, 32)), nil); err != nil {
+		t.Fatal(err)
+	}
+	res, err := Resize(&buf, Options{MaxHeight: 16})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.MIMEType != "image/jpeg" || res.Width != 32 || res.Height != 16 {
+		t.Fatalf("unexpected result %+v", res)
+	}
+}
+
+func TestProbeErrors(t *testing.T) {
+	if _, _, err := Probe(strings.NewReader("RIFF....WEBPVP8 ")); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("webp: got %v, want ErrUnsupported", err)
+	}
+
+	// A PNG header claiming 10000x10000 pixels
+	header := encodePNG(t, image.NewGray(image.Rect(0, 0, 1, 1)))
+	header = bytes.Clone(header[:33])
+	copy(header[16:24], []byte{0, 0, 0x27, 0x10, 0, 0, 0x27, 0x10})
+	binary.BigEndian.PutUint32(header[29:], crc32.ChecksumIEEE(header[12:29]))
+	if _, _, err := Probe(bytes.NewReader(header)); err == nil || !strings.Contains(err.Error(), "pixels") {
+		t.Errorf("huge image: got %v, want pixel limit error", err)
+	}
+}
@@ -0,0 +1,396 @@
+package imaging
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// maxEXIFSize caps the EXIF block ReadInfo reads. JPEG segments cannot be
+// larger, and nothing useful needs more.
+const maxEXIFSize = 64 * 1024
+
+// Info describes an image from its headers.
+type Info struct {
+	Format string `json:"format"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	EXIF   *EXIF  `json:"exif,omitempty"`
+}
+
+// EXIF holds selected EXIF fields. The GPS position itself is not read,
+// only whether the image carries one.
+type EXIF struct {
+	Make         string  `json:"make,omitempty"`
+	Model        string  `json:"model,omitempty"`
+	LensModel    string  `json:"lensModel,omitempty"`
+	Software     string  `json:"software,omitempty"`
+	DateTime     string  `json:"dateTime,omitempty"`
+	Taken        string  `json:"taken,omitempty"`
+	Orientation  int     `json:"orientation,omitempty"`
+	ExposureTime string  `json:"exposureTime,omitempty"`
+	FNumber      float64 `json:"fNumber,omitempty"`
+	ISO          int     `json:"iso,omitempty"`
+	FocalLength  float64 `json:"focalLength,omitempty"`
+	GPS          bool    `json:"gps,omitempty"`
+}
+
+// Rotated reports whether the orientation turns the image on its side, so
+// it displays with its width and height swapped.
+func (e *EXIF) Rotated() bool {
+	return e != nil && e.Orientation >= 5 && e.Orientation <= 8
+}
+
+// ReadInfo reads the format, dimensions, and EXIF fields of the JPEG, PNG,
+// GIF, WebP, or BMP image in r from its headers, without decoding any
+// pixels. Other formats return ErrUnsupported.
+func ReadInfo(r io.ReadSeeker) (*Info, error) {
+	head := make([]byte, 30)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrUnsupported
+		}
+		return nil, err
+	}
+	head = head[:n]
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	switch {
+	case bytes.HasPrefix(head, []byte{0xFF, 0xD8}):
+		return jpegInfo(r)
+	case bytes.HasPrefix(head, []byte("\x89PNG\r\n\x1a\n")):
+		return pngInfo(r)
+	case bytes.HasPrefix(head, []byte("GIF87a")) || bytes.HasPrefix(head, []byte("GIF89a")):
+		if len(head) < 10 {
+			return nil, errTruncated
+		}
+		return &Info{Format: "gif", Width: int(binary.LittleEndian.Uint16(head[6:8])), Height: int(binary.LittleEndian.Uint16(head[8:10]))}, nil
+	case len(head) >= 12 && string(head[0:4]) == "RIFF" && string(head[8:12]) == "WEBP":
+		return webpInfo(r)
+	case bytes.HasPrefix(head, []byte("BM")):
+		if len(head) < 26 {
+			return nil, errTruncated
+		}
+		// Negative heights mark rows stored top-down
+		h := int(int32(binary.LittleEndian.Uint32(head[22:26])))
+		return &Info{Format: "bmp", Width: int(int32(binary.LittleEndian.Uint32(head[18:22]))), Height: max(h, -h)}, nil
+	}
+	return nil, ErrUnsupported
+}
+
+// jpegInfo reads the segments before the image data for the frame size and
+// the EXIF block.
+func jpegInfo(r io.Reader) (*Info, error) {
+	br := bufio.NewReader(r)
+	br.Discard(2)
+	info := &Info{Format: "jpeg"}
+	for {
+		b, err := br.ReadByte()
+		if err != nil || b != 0xFF {
+			return nil, errTruncated
+		}
+		marker := byte(0xFF)
+		// Markers may be preceded by any number of fill bytes
+		for marker == 0xFF {
+			if marker, err = br.ReadByte(); err != nil {
+				return nil, errTruncated
+			}
+		}
+		if marker == 0x01 || marker >= 0xD0 && marker <= 0xD7 {
+			continue
+		}
+		if marker == 0xDA || marker == 0xD9 {
+			break
+		}
+		var size [2]byte
+		if _, err := io.ReadFull(br, size[:]); err != nil {
+			return nil, errTruncated
+		}
+		length := int(binary.BigEndian.Uint16(size[:])) - 2
+		if length < 0 {
+			return nil, errTruncated
+		}
+		// Start of frame markers, other than DHT, JPG, and DAC, which share
+		// their range
+		sof := marker >= 0xC0 && marker <= 0xCF && marker != 0xC4 && marker != 0xC8 && marker != 0xCC
+		if !sof && (marker != 0xE1 || info.EXIF != nil) {
+			if _, err := br.Discard(length); err != nil {
+				return nil, errTruncated
+			}
+			continue
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(br, payload); err != nil {
+			return nil, errTruncated
+		}
+		switch {
+		case sof && len(payload) >= 5:
+			info.Height = int(binary.BigEndian.Uint16(payload[1:3]))
+			info.Width = int(binary.BigEndian.Uint16(payload[3:5]))
+		case !sof && bytes.HasPrefix(payload, []byte("Exif\x00\x00")):
+			info.EXIF = parseEXIF(payload[6:])
+		}
+	}
+	if info.Width == 0 && info.Height == 0 {
+		return nil, errors.New("JPEG has no frame header")
+	}
+	return info, nil
+}
+
+// pngInfo reads the chunks before the image data for the header and the
+// EXIF block.
+func pngInfo(r io.ReadSeeker) (*Info, error) {
+	if _, err := r.Seek(8, io.SeekStart); err != nil {
+		return nil, err
+	}
+	info := &Info{Format: "png"}
+	for {
+		var hdr [8]byte
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			return nil, errTruncated
+		}
+		length := int64(binary.BigEndian.Uint32(hdr[0:4]))
+		switch typ := string(hdr[4:8]); {
+		case typ == "IHDR" && length >= 8:
+			var dims [8]byte
+			if _, err := io.ReadFull(r, dims[:]); err != nil {
+				return nil, errTruncated
+			}
+			info.Width = int(binary.BigEndian.Uint32(dims[0:4]))
+			info.Height = int(binary.BigEndian.Uint32(dims[4:8]))
+			length -= 8
+		case typ == "eXIf" && length <= maxEXIFSize:
+			data := make([]byte, length)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return nil, errTruncated
+			}
+			info.EXIF = parseEXIF(data)
+			length = 0
+		case typ == "IDAT" || typ == "IEND":
+			return info, nil
+		}
+		// Skip the rest of the chunk and its CRC
+		if _, err := r.Seek(length+4, io.SeekCurrent); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// webpInfo reads the size from the VP8X, VP8, or VP8L chunk and seeks past
+// the image data to the EXIF chunk.
+func webpInfo(r io.ReadSeeker) (*Info, error) {
+	end, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.Seek(12, io.SeekStart); err != nil {
+		return nil, err
+	}
+	info := &Info{Format: "webp"}
+	extended := false
+	for pos := int64(12); pos+8 <= end; {
+		var hdr [8]byte
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			return nil, errTruncated
+		}
+		size := int64(binary.LittleEndian.Uint32(hdr[4:8]))
+		var data []byte
+		switch typ := string(hdr[0:4]); {
+		case typ == "VP8X" || typ == "VP8 " && !extended || typ == "VP8L" && !extended:
+			data = make([]byte, min(size, 10))
+		case typ == "EXIF" && size <= maxEXIFSize:
+			data = make([]byte, size)
+		}
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, errTruncated
+		}
+		switch typ := string(hdr[0:4]); {
+		case typ == "VP8X" && len(data) >= 10:
+			extended = true
+			info.Width = int(uint32(data[4])|uint32(data[5])<<8|uint32(data[6])<<16) + 1
+			info.Height = int(uint32(data[7])|uint32(data[8])<<8|uint32(data[9])<<16) + 1
+		case typ == "VP8 " && len(data) >= 10:
+			// After the frame tag and start code, 14 bits each
+			info.Width = int(binary.LittleEndian.Uint16(data[6:8]) & 0x3FFF)
+			info.Height = int(binary.LittleEndian.Uint16(data[8:10]) & 0x3FFF)
+		case typ == "VP8L" && len(data) >= 5:
+			// After the signature byte, 14 bits each of width and height
+			// less one
+			bits := binary.LittleEndian.Uint32(data[1:5])
+			info.Width = int(bits&0x3FFF) + 1
+			info.Height = int(bits>>14&0x3FFF) + 1
+		case typ == "EXIF" && data != nil:
+			// Some writers keep the JPEG segment's identifier
+			info.EXIF = parseEXIF(bytes.TrimPrefix(data, []byte("Exif\x00\x00")))
+		}
+		// Chunks are padded to an even length
+		pos += 8 + size + size%2
+		if _, err := r.Seek(pos, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+	if info.Width == 0 && info.Height == 0 {
+		return nil, errors.New("WebP has no image header")
+	}
+	return info, nil
+}
+
+// EXIF tags ReadInfo reports.
+const (
+	tagMake             = 0x010F
+	tagModel            = 0x0110
+	tagOrientation      = 0x0112
+	tagSoftware         = 0x0131
+	tagDateTime         = 0x0132
+	tagExifIFD          = 0x8769
+	tagGPSIFD           = 0x8825
+	tagExposureTime     = 0x829A
+	tagFNumber          = 0x829D
+	tagISO              = 0x8827
+	tagDateTimeOriginal = 0x9003
+	tagFocalLength      = 0x920A
+	tagLensModel        = 0xA434
+)
+
+// tiffTypeSizes holds the size in bytes of a value of each TIFF field type.
+var tiffTypeSizes = map[uint16]int{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 7: 1, 9: 4, 10: 8}
+
+// tiffField is a field of an image file directory.
+type tiffField struct {
+	typ   uint16
+	count int
+	value []byte
+}
+
+// parseEXIF reads selected fields from an EXIF block, a TIFF structure. It
+// returns nil if the block is malformed or holds none of them.
+func parseEXIF(data []byte) *EXIF {
+	if len(data) < 8 {
+		return nil
+	}
+	var order binary.ByteOrder
+	switch string(data[0:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil
+	}
+	if order.Uint16(data[2:4]) != 42 {
+		return nil
+	}
+	ifd0 := readIFD(data, order, order.Uint32(data[4:8]))
+	if ifd0 == nil {
+		return nil
+	}
+	e := &EXIF{
+		Make:        tiffString(ifd0[tagMake]),
+		Model:       tiffString(ifd0[tagModel]),
+		Software:    tiffString(ifd0[tagSoftware]),
+		DateTime:    tiffString(ifd0[tagDateTime]),
+		Orientation: int(tiffUint(ifd0[tagOrientation], order)),
+	}
+	if f, ok := ifd0[tagExifIFD]; ok {
+		sub := readIFD(data, order, tiffUint(f, order))
+		e.Taken = tiffString(sub[tagDateTimeOriginal])
+		e.LensModel = tiffString(sub[tagLensModel])
+		e.ISO = int(tiffUint(sub[tagISO], order))
+		e.FNumber = round2(tiffRational(sub[tagFNumber], order))
+		e.FocalLength = round2(tiffRational(sub[tagFocalLength], order))
+		if num, den := tiffFraction(sub[tagExposureTime], order); num > 0 && den > 0 {
+			if num < den {
+				e.ExposureTime = fmt.Sprintf("1/%d", int(math.Round(float64(den)/float64(num))))
+			} else {
+				e.ExposureTime = fmt.Sprintf("%g", round2(float64(num)/float64(den)))
+			}
+		}
+	}
+	if _, ok := ifd0[tagGPSIFD]; ok {
+		e.GPS = true
+	}
+	if *e == (EXIF{}) {
+		return nil
+	}
+	return e
+}
+
+// readIFD reads the fields of the image file directory at offset, or
+// returns nil if it does not fit in data.
+func readIFD(data []byte, order binary.ByteOrder, offset uint32) map[uint16]tiffField {
+	off := int(offset)
+	if off <= 0 || off+2 > len(data) {
+		return nil
+	}
+	n := int(order.Uint16(data[off : off+2]))
+	fields := make(map[uint16]tiffField, n)
+	for i := range n {
+		entry := off + 2 + i*12
+		if entry+12 > len(data) {
+			break
+		}
+		typ := order.Uint16(data[entry+2 : entry+4])
+		count := int(order.Uint32(data[entry+4 : entry+8]))
+		size, ok := tiffTypeSizes[typ]
+		if !ok || count < 0 || count > len(data) {
+			continue
+		}
+		// Values of up to four bytes are stored in the entry itself
+		start := entry + 8
+		if size*count > 4 {
+			start = int(order.Uint32(data[entry+8 : entry+12]))
+		}
+		if start < 0 || start+size*count > len(data) {
+			continue
+		}
+		fields[order.Uint16(data[entry:entry+2])] = tiffField{typ: typ, count: count, value: data[start : start+size*count]}
+	}
+	return fields
+}
+
+// tiffString returns an ASCII field without its terminating NUL.
+func tiffString(f tiffField) string {
+	if f.typ != 2 {
+		return ""
+	}
+	s, _, _ := strings.Cut(string(f.value), "\x00")
+	return strings.TrimSpace(s)
+}
+
+// tiffUint returns the first value of a SHORT or LONG field.
+func tiffUint(f tiffField, order binary.ByteOrder) uint32 {
+	switch {
+	case f.typ == 3 && len(f.value) >= 2:
+		return uint32(order.Uint16(f.value))
+	case f.typ == 4 && len(f.value) >= 4:
+		return order.Uint32(f.value)
+	}
+	return 0
+}
+
+// tiffFraction returns the numerator and denominator of a RATIONAL field.
+func tiffFraction(f tiffField, order binary.ByteOrder) (uint32, uint32) {
+	if f.typ != 5 || len(f.value) < 8 {
+		return 0, 0
+	}
+	return order.Uint32(f.value[0:4]), order.Uint32(f.value[4:8])
+}
+
+func tiffRational(f tiffField, order binary.ByteOrder) float64 {
+	num, den := tiffFraction(f, order)
+	if den == 0 {
+		return 0
+	}
+	return float64(num) / float64(den)
+}
+
+func round2(f float64) float64 {
+	return math.Round(f*100) / 100
+}
@@ -0,0 +1,136 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"reflect"
+	"testing"
+)
+
+// tiffEntry appends a big-endian image file directory entry.
+func tiffEntry(b []byte, tag, typ uint16, count, value uint32) []byte {
+	b = binary.BigEndian.AppendUint16(b, tag)
+	b = binary.BigEndian.AppendUint16(b, typ)
+	b = binary.BigEndian.AppendUint32(b, count)
+	return binary.BigEndian.AppendUint32(b, value)
+}
+
+// exifBlock builds a big-endian EXIF block with a camera make, an
+// orientation, exposure settings, and a GPS directory.
+func exifBlock() []byte {
+	b := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	b = binary.BigEndian.AppendUint16(b, 4)
+	b = tiffEntry(b, tagMake, 2, 6, 62)
+	b = tiffEntry(b, tagOrientation, 3, 1, 6<<16)
+	b = tiffEntry(b, tagExifIFD, 4, 1, 68)
+	b = tiffEntry(b, tagGPSIFD, 4, 1, 0)
+	b = append(b, 0, 0, 0, 0)
+	b = append(b, "Canon\x00"...)
+	b = binary.BigEndian.AppendUint16(b, 3)
+	b = tiffEntry(b, tagExposureTime, 5, 1, 110)
+	b = tiffEntry(b, tagFNumber, 5, 1, 118)
+	b = tiffEntry(b, tagISO, 3, 1, 400<<16)
+	b = append(b, 0, 0, 0, 0)
+	for _, v := range []uint32{1, 250, 28, 10} {
+		b = binary.BigEndian.AppendUint32(b, v)
+	}
+	return b
+}
+
+func TestReadInfoJPEG(t *testing.T) {
+	var plain bytes.Buffer
+	if err := jpeg.Encode(&plain, image.NewGray(image.Rect(0, 0, 40, 30)), nil); err != nil {
+		t.Fatal(err)
+	}
+	var tagged []byte
+	tagged = append(tagged, plain.Bytes()[:2]...)
+	tagged = append(tagged, jpegSegment(0xE1, "Exif\x00\x00"+string(exifBlock()))...)
+	tagged = append(tagged, plain.Bytes()[2:]...)
+
+	info, err := ReadInfo(bytes.NewReader(tagged))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Info{Format: "jpeg", Width: 40, Height: 30, EXIF: &EXIF{
+		Make: "Canon", Orientation: 6, ExposureTime: "1/250", FNumber: 2.8, ISO: 400, GPS: true,
+	}}
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("ReadInfo() = %+v %+v, want %+v %+v", info, info.EXIF, want, want.EXIF)
+	}
+	if !info.EXIF.Rotated() {
+		t.Error("expected orientation 6 to be rotated")
+	}
+
+	info, err = ReadInfo(bytes.NewReader(plain.Bytes()))
+	if err != nil || info.EXIF != nil || info.Width != 40 {
+		t.Errorf("unexpected info for a plain JPEG: %+v, %v", info, err)
+	}
+}
+
+func TestReadInfoFormats(t *testing.T) {
+	img := image.NewPaletted(image.Rect(0, 0, 7, 5), []color.Color{color.Black, color.White})
+	var pngData, gifData bytes.Buffer
+	if err := png.Encode(&pngData, img); err != nil {
+		t.Fatal(err)
+	}
+	if err := gif.Encode(&gifData, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	// An eXIf chunk goes before the image data, after the header
+	withEXIF := append([]byte{}, pngData.Bytes()[:33]...)
+	withEXIF = append(withEXIF, pngChunk("eXIf", string(exifBlock()))...)
+	withEXIF = append(withEXIF, pngData.Bytes()[33:]...)
+
+	bmp := make([]byte, 54)
+	copy(bmp, "BM")
+	binary.LittleEndian.PutUint32(bmp[18:], 12)
+	binary.LittleEndian.PutUint32(bmp[22:], uint32(0xFFFFFFF7)) // -9, top-down
+
+	// A lossless WebP header: signature, then width and height less one
+	vp8l := []byte{0x2f, 0, 0, 0, 0}
+	binary.LittleEndian.PutUint32(vp8l[1:], 99|49<<14)
+	webp := []byte("RIFF\x00\x00\x00\x00WEBPVP8L\x05\x00\x00\x00")
+	webp = append(webp, vp8l...)
+	webp = append(webp, 0)
+	webp = append(webp, "EXIF"...)
+	webp = binary.LittleEndian.AppendUint32(webp, uint32(len(exifBlock())))
+	webp = append(webp, exifBlock()...)
+
+	for _, tt := range []struct {
+		name          string
+		data          []byte
+		format        string
+		width, height int
+		exif          bool
+	}{
+		{"png", pngData.Bytes(), "png", 7, 5, false},
+		{"png exif", withEXIF, "png", 7, 5, true},
+		{"gif", gifData.Bytes(), "gif", 7, 5, false},
+		{"bmp", bmp, "bmp", 12, 9, false},
+		{"webp", webp, "webp", 100, 50, true},
+	} {
+		info, err := ReadInfo(bytes.NewReader(tt.data))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if info.Format != tt.format || info.Width != tt.width || info.Height != tt.height || (info.EXIF != nil) != tt.exif {
+			t.Errorf("%s: unexpected info %+v", tt.name, info)
+		}
+	}
+
+	for _, data := range [][]byte{nil, []byte("plain text"), []byte{0xFF, 0xD8, 0xFF}} {
+		if _, err := ReadInfo(bytes.NewReader(data)); err == nil {
+			t.Errorf("expected an error for %q", data)
+		}
+	}
+	if _, err := ReadInfo(bytes.NewReader([]byte("plain text"))); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// errTruncated is returned for images whose structure runs past the end of
+// the data.
+var errTruncated = errors.New("image data is truncated")
+
+// StripMetadata removes EXIF (including GPS), XMP, IPTC, comments, and text
+// chunks from a JPEG, PNG, or WebP image without re-encoding its pixels. It
+// reports whether anything was removed. Other formats return ErrUnsupported.
+//
+// The EXIF orientation tag goes with the rest, so photos that rely on it
+// may display rotated.
+func StripMetadata(data []byte) ([]byte, bool, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8}):
+		return stripJPEG(data)
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return stripPNG(data)
+	case len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return stripWebP(data)
+	default:
+		return nil, false, ErrUnsupported
+	}
+}
+
+// jpegMetadata holds the JPEG markers that carry metadata: APP1 (EXIF and
+// XMP), APP12 (Ducky), APP13 (IPTC), and comments. APP0 (JFIF), APP2 (ICC
+// profiles), and APP14 (Adobe color transforms) affect how the image is
+// decoded and are kept.
+var jpegMetadata = map[byte]bool{0xE1: true, 0xEC: true, 0xED: true, 0xFE: true}
+
+func stripJPEG(data []byte) ([]byte, bool, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:2]...)
+	removed := false
+	i := 2
+	for {
+		// Markers may be preceded by any number of fill bytes
+		for i < len(data) && data[i] == 0xFF && i+1 < len(data) && data[i+1] == 0xFF {
+			i++
+		}
+		if i+2 > len(data) || data[i] != 0xFF {
+			return nil, false, errTruncated
+		}
+		marker := data[i+1]
+		// Standalone markers have no length
+		if marker == 0x01 || marker >= 0xD0 && marker <= 0xD7 {
+			out = append(out, data[i:i+2]...)
+			i += 2
+			continue
+		}
+		// Entropy-coded data follows the start of scan; keep everything
+		// from here on, since metadata belongs before it
+		if marker == 0xDA || marker == 0xD9 {
+			return append(out, data[i:]...), removed, nil
+		}
+		if i+4 > len(data) {
+			return nil, false, errTruncated
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:i+4]))
+		if end > len(data) {
+			return nil, false, errTruncated
+		}
+		if jpegMetadata[marker] {
+			removed = true
+		} else {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+}
+
+// pngMetadata holds the PNG chunk types that carry metadata.
+var pngMetadata = map[string]bool{"eXIf": true, "tEXt": true, "zTXt": true, "iTXt": true, "tIME": true}
+
+func stripPNG(data []byte) ([]byte, bool, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:8]...)
+	removed := false
+	for i := 8; i < len(data); {
+		if i+8 > len(data) {
+			return nil, false, errTruncated
+		}
+		// Length, type, data, and CRC
+		end := i + 12 + int(binary.BigEndian.Uint32(data[i:i+4]))
+		if end > len(data) || end < i {
+			return nil, false, errTruncated
+		}
+		if pngMetadata[string(data[i+4:i+8])] {
+			removed = true
+		} else {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	return out, removed, nil
+}
+
+// VP8X flags announcing EXIF and XMP chunks.
+const (
+	webpFlagEXIF = 0x08
+	webpFlagXMP  = 0x04
+)
+
+func stripWebP(data []byte) ([]byte, bool, error) {
+	out := make([]byte, 12, len(data))
+	copy(out, data[:12])
+	removed := false
+	for i := 12; i < len(data); {
+		if i+8 > len(data) {
+			return nil, false, errTruncated
+		}
+		size := int(binary.LittleEndian.Uint32(data[i+4 : i+8]))
+		// Chunks are padded to an even length
+		end := i + 8 + size + size%2
+		if end > len(data) || end < i {
+			return nil, false, errTruncated
+		}
+		switch string(data[i : i+4]) {
+		case "EXIF", "XMP ":
+			removed = true
+		case "VP8X":
+			start := len(out)
+			out = append(out, data[i:end]...)
+			if size > 0 {
+				out[start+8] &^= webpFlagEXIF | webpFlagXMP
+			}
+		default:
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	binary.LittleEndian.PutUint32(out[4:8], uint32(len(out)-8))
+	return out, removed, nil
+}
@@ -0,0 +1,122 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// jpegSegment builds a JPEG marker segment with the given payload.
+func jpegSegment(marker byte, payload string) []byte {
+	seg := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(payload)+2))
+	return append(seg, payload...)
+}
+
+// pngChunk builds a PNG chunk with a valid CRC.
+func pngChunk(kind, payload string) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(payload)))
+	chunk = append(chunk, kind...)
+	chunk = append(chunk, payload...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE([]byte(kind+payload)))
+}
+
+func TestStripMetadataJPEG(t *testing.T) {
+	var plain bytes.Buffer
+	if err := jpeg.Encode(&plain, image.NewGray(image.Rect(0, 0, 8, 8)), nil); err != nil {
+		t.Fatal(err)
+	}
+	// Insert EXIF with GPS, XMP, and a comment after SOI
+	var tagged []byte
+	tagged = append(tagged, plain.Bytes()[:2]...)
+	tagged = append(tagged, jpegSegment(0xE1, "Exif\x00\x00GPS 51.5N 0.1W")...)
+	tagged = append(tagged, jpegSegment(0xE1, "http://ns.adobe.com/xap/1.0/\x00<x/>")...)
+	tagged = append(tagged, jpegSegment(0xFE, "taken at home")...)
+	tagged = append(tagged, plain.Bytes()[2:]...)
+
+	out, removed, err := StripMetadata(tagged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !removed || !bytes.Equal(out, plain.Bytes()) {
+		t.Errorf("expected the original JPEG back (removed=%v, %d bytes, want %d)", removed, len(out), plain.Len())
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(out)); err != nil {
+		t.Errorf("stripped JPEG does not decode: %v", err)
+	}
+
+	// Nothing to strip
+	out, removed, err = StripMetadata(plain.Bytes())
+	if err != nil || removed || !bytes.Equal(out, plain.Bytes()) {
+		t.Errorf("clean JPEG changed: removed=%v err=%v", removed, err)
+	}
+}
+
+func TestStripMetadataPNG(t *testing.T) {
+	var plain bytes.Buffer
+	if err := png.Encode(&plain, image.NewGray(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	// Insert metadata chunks after IHDR, which is 8+25 bytes in
+	var tagged []byte
+	tagged = append(tagged, plain.Bytes()[:33]...)
+	tagged = append(tagged, pngChunk("eXIf", "MM\x00*GPS")...)
+	tagged = append(tagged, pngChunk("tEXt", "Author\x00me")...)
+	tagged = append(tagged, plain.Bytes()[33:]...)
+
+	out, removed, err := StripMetadata(tagged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !removed || !bytes.Equal(out, plain.Bytes()) {
+		t.Errorf("expected the original PNG back (removed=%v)", removed)
+	}
+}
+
+func TestStripMetadataWebP(t *testing.T) {
+	vp8x := make([]byte, 10)
+	vp8x[0] = webpFlagEXIF | webpFlagXMP | 0x10 // 0x10: alpha, which is kept
+	chunk := func(kind string, payload []byte) []byte {
+		c := append([]byte(kind), binary.LittleEndian.AppendUint32(nil, uint32(len(payload)))...)
+		c = append(c, payload...)
+		if len(payload)%2 == 1 {
+			c = append(c, 0)
+		}
+		return c
+	}
+	body := []byte("WEBP")
+	body = append(body, chunk("VP8X", vp8x)...)
+	body = append(body, chunk("VP8L", []byte("pixels"))...)
+	body = append(body, chunk("EXIF", []byte("GPS"))...)
+	body = append(body, chunk("XMP ", []byte("<x/>"))...)
+	data := append(append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...), body...)
+
+	out, removed, err := StripMetadata(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !removed || bytes.Contains(out, []byte("EXIF")) || bytes.Contains(out, []byte("XMP ")) {
+		t.Fatalf("metadata left in %q", out)
+	}
+	if got := binary.LittleEndian.Uint32(out[4:8]); int(got) != len(out)-8 {
+		t.Errorf("RIFF size %d, want %d", got, len(out)-8)
+	}
+	if flags := out[20]; flags != 0x10 {
+		t.Errorf("VP8X flags %#x, want 0x10", flags)
+	}
+}
+
+func TestStripMetadataErrors(t *testing.T) {
+	if _, _, err := StripMetadata([]byte("GIF89a")); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("gif: got %v, want ErrUnsupported", err)
+	}
+	truncated := append([]byte{0xFF, 0xD8}, jpegSegment(0xE1, "Exif")[:5]...)
+	if _, _, err := StripMetadata(truncated); err == nil {
+		t.Error("expected an error for a truncated JPEG")
+	}
+}
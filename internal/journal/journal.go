@@ -0,0 +1,570 @@
+// Package journal records the prior state of the files a tool changes, so
+// the change can be undone. Each allowed directory has a journal directory,
+// in the state directory or a .mcp-journal directory at its top, holding a
+// manifest of operations and a snapshot of every file an operation
+// overwrote or deleted. Storage is bounded by a retention
+// policy; the oldest operations are dropped first. Server instances sharing
+// a journal directory take its lock file around every manifest update, so
+// they do not lose each other's operations.
+package journal
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/portertech/filesystem-mcp-server/internal/filelock"
+	"github.com/portertech/filesystem-mcp-server/internal/retention"
+)
+
+// DirName is the name of the journal directory kept in an allowed
+// directory.
+const DirName = ".mcp-journal"
+
+// DefaultMaxBytes is the default limit on the snapshots kept per allowed
+// directory.
+const DefaultMaxBytes = 100 << 20
+
+// manifestName is the name of the manifest in a journal directory.
+const manifestName = "journal.json"
+
+// lockName is the name of the lock file in a journal directory.
+const lockName = "lock"
+
+// lockWait is how long to wait for another server instance to finish
+// updating a journal directory.
+const lockWait = 10 * time.Second
+
+var (
+	// ErrNotFound is returned for an ID that is not in any journal.
+	ErrNotFound = errors.New("operation not found")
+	// ErrConflict is returned when undoing an operation would discard a
+	// later change.
+	ErrConflict = errors.New("changed since the operation")
+)
+
+// Change is the state of one path before an operation.
+type Change struct {
+	Path string `json:"path"`
+	// Snapshot names the file holding the prior content of Path; it is
+	// empty if Path did not exist.
+	Snapshot string      `json:"snapshot,omitempty"`
+	Mode     fs.FileMode `json:"mode,omitempty"`
+	// ModTime is restored with the snapshot, so undoing a series of
+	// operations newest first finds each file as the one before left it.
+	ModTime time.Time `json:"modTime,omitempty"`
+	// MovedTo is set when the operation renamed Path.
+	MovedTo string `json:"movedTo,omitempty"`
+	// After is the state the operation left behind, at Path or MovedTo;
+	// undo refuses to discard anything newer.
+	After *State `json:"after,omitempty"`
+}
+
+// State is the size and modification time of a file.
+type State struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// Operation is a recorded tool call.
+type Operation struct {
+	ID      string    `json:"id"`
+	Tool    string    `json:"tool"`
+	Time    time.Time `json:"time"`
+	Root    string    `json:"root"`
+	Changes []Change  `json:"changes"`
+	// Size is the total size of the snapshots.
+	Size int64 `json:"size"`
+	// Error says why the operation cannot be undone.
+	Error string `json:"error,omitempty"`
+}
+
+// Journal records operations in the journal directories. It is safe for
+// concurrent use.
+type Journal struct {
+	mu     sync.Mutex
+	policy retention.Policy
+	locate func(root string) string
+	now    func() time.Time
+}
+
+// New creates a Journal keeping operations within policy. locate gives the
+// journal directory of an allowed directory; if it is nil, that is DirName
+// at its top.
+func New(policy retention.Policy, locate func(root string) string) *Journal {
+	if locate == nil {
+		locate = func(root string) string {
+			return filepath.Join(root, DirName)
+		}
+	}
+	return &Journal{policy: policy, locate: locate, now: time.Now}
+}
+
+// Dir returns the journal directory of root.
+func (j *Journal) Dir(root string) string {
+	return j.locate(root)
+}
+
+// Pending is an operation being recorded. A nil Pending records nothing,
+// so callers need not check whether journaling is on.
+type Pending struct {
+	j  *Journal
+	op Operation
+	// err is why the operation cannot be undone.
+	err error
+}
+
+// Begin starts recording an operation of tool on files within root. It
+// returns nil if j is nil or root is empty.
+func (j *Journal) Begin(tool, root string) *Pending {
+	if j == nil || root == "" {
+		return nil
+	}
+	now := j.now()
+	return &Pending{j: j, op: Operation{ID: newID(now), Tool: tool, Time: now.UTC(), Root: root, Changes: []Change{}}}
+}
+
+// Save records the current state of path, which the operation is about to
+// overwrite, create, or delete.
+func (p *Pending) Save(path string) {
+	if p == nil || p.err != nil {
+		return
+	}
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		p.op.Changes = append(p.op.Changes, Change{Path: path})
+		return
+	}
+	if err != nil {
+		p.err = err
+		return
+	}
+	if !info.Mode().IsRegular() {
+		p.err = fmt.Errorf("%s is not a regular file", path)
+		return
+	}
+	if max := p.j.policy.MaxBytes; max > 0 && p.op.Size+info.Size() > max {
+		p.err = fmt.Errorf("%s is larger than the journal's limit of %d bytes", path, max)
+		return
+	}
+
+	name := strconv.Itoa(len(p.op.Changes))
+	if err := copyFile(path, filepath.Join(p.dir(), name), 0600); err != nil {
+		p.err = fmt.Errorf("failed to snapshot %s: %w", path, err)
+		return
+	}
+	p.op.Size += info.Size()
+	p.op.Changes = append(p.op.Changes, Change{Path: path, Snapshot: name, Mode: info.Mode().Perm(), ModTime: info.ModTime()})
+}
+
+// Move records that the operation is about to rename src to dst, which
+// does not exist.
+func (p *Pending) Move(src, dst string) {
+	if p == nil {
+		return
+	}
+	p.op.Changes = append(p.op.Changes, Change{Path: src, MovedTo: dst})
+}
+
+// Discard drops an operation that did not happen.
+func (p *Pending) Discard() {
+	if p == nil {
+		return
+	}
+	os.RemoveAll(p.dir())
+}
+
+// Commit records the operation, which has happened, and drops the oldest
+// operations of its root that no longer fit the retention policy. An
+// operation that could not be snapshotted is recorded as not undoable, and
+// the reason is returned.
+func (p *Pending) Commit() error {
+	if p == nil {
+		return nil
+	}
+	for i := range p.op.Changes {
+		c := &p.op.Changes[i]
+		path := c.Path
+		if c.MovedTo != "" {
+			path = c.MovedTo
+		}
+		if info, err := os.Lstat(path); err == nil {
+			c.After = &State{Size: info.Size(), ModTime: info.ModTime()}
+		}
+	}
+	if p.err != nil {
+		p.Discard()
+		p.op.Error = p.err.Error()
+		p.op.Size = 0
+	}
+
+	j := p.j
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	journalDir := j.Dir(p.op.Root)
+	if err := os.MkdirAll(journalDir, 0700); err != nil {
+		p.Discard()
+		return fmt.Errorf("failed to create journal directory: %w", err)
+	}
+	release, err := lockDir(journalDir)
+	if err != nil {
+		p.Discard()
+		return err
+	}
+	defer release()
+	ops, err := readManifest(journalDir)
+	if err != nil {
+		p.Discard()
+		return err
+	}
+	ops = append(ops, p.op)
+	items := make([]retention.Item, len(ops))
+	for i, op := range ops {
+		items[i] = retention.Item{Time: op.Time, Size: op.Size}
+	}
+	if err := removeLocked(journalDir, ops, j.policy.Expired(j.now(), items)); err != nil {
+		return err
+	}
+	if p.err != nil {
+		return fmt.Errorf("the operation cannot be undone: %w", p.err)
+	}
+	return nil
+}
+
+// dir returns the directory holding the operation's snapshots.
+func (p *Pending) dir() string {
+	return filepath.Join(p.j.Dir(p.op.Root), p.op.ID)
+}
+
+// List returns the operations in the journal of each root, newest first.
+func (j *Journal) List(roots []string) ([]Operation, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var all []Operation
+	for _, root := range roots {
+		ops, err := readManifest(j.Dir(root))
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, ops...)
+	}
+	sort.SliceStable(all, func(a, b int) bool {
+		return all[a].Time.After(all[b].Time)
+	})
+	return all, nil
+}
+
+// Undo restores the state before the operation with the given ID and drops
+// it from the journal. Unless force is set, it refuses if any file the
+// operation changed has changed again since. check is called with every
+// path Undo is about to change, and stops it with the error it returns.
+func (j *Journal) Undo(roots []string, id string, force bool, check func(path string) error) (Operation, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	journalDir, err := j.find(roots, id)
+	if err != nil {
+		return Operation{}, err
+	}
+	release, err := lockDir(journalDir)
+	if err != nil {
+		return Operation{}, err
+	}
+	defer release()
+	// Read again under the lock, which another instance may have held
+	ops, err := readManifest(journalDir)
+	if err != nil {
+		return Operation{}, err
+	}
+	i := slices.IndexFunc(ops, func(op Operation) bool { return op.ID == id })
+	if i < 0 {
+		return Operation{}, ErrNotFound
+	}
+	op := ops[i]
+	if op.Error != "" {
+		return op, fmt.Errorf("%s cannot be undone: %s", id, op.Error)
+	}
+	for _, c := range op.Changes {
+		path := c.Path
+		if c.MovedTo != "" {
+			path = c.MovedTo
+			if _, err := os.Lstat(c.Path); err == nil {
+				return op, fmt.Errorf("%s exists again", c.Path)
+			}
+		}
+		for _, p := range []string{c.Path, c.MovedTo} {
+			if p == "" {
+				continue
+			}
+			if err := check(p); err != nil {
+				return op, err
+			}
+		}
+		if !force && !unchanged(path, c.After) {
+			return op, fmt.Errorf("%s %w", path, ErrConflict)
+		}
+	}
+
+	opDir := filepath.Join(journalDir, op.ID)
+	for n := len(op.Changes) - 1; n >= 0; n-- {
+		if err := revert(opDir, op.Changes[n]); err != nil {
+			return op, fmt.Errorf("undo stopped after %d of %d changes: %w", len(op.Changes)-1-n, len(op.Changes), err)
+		}
+	}
+	drop := make([]bool, len(ops))
+	drop[i] = true
+	return op, removeLocked(journalDir, ops, drop)
+}
+
+// Prune drops the operations outside the retention policy from the journal
+// of each root, and returns how many it dropped and the size of their
+// snapshots.
+func (j *Journal) Prune(roots []string) (int, int64, error) {
+	if !j.policy.Enabled() {
+		return 0, 0, nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var count int
+	var size int64
+	for _, root := range roots {
+		n, s, err := j.pruneDir(j.Dir(root))
+		count += n
+		size += s
+		if err != nil {
+			return count, size, err
+		}
+	}
+	return count, size, nil
+}
+
+// pruneDir drops the operations outside the retention policy from
+// journalDir, holding its lock file.
+func (j *Journal) pruneDir(journalDir string) (int, int64, error) {
+	if _, err := os.Stat(journalDir); os.IsNotExist(err) {
+		return 0, 0, nil
+	}
+	release, err := lockDir(journalDir)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer release()
+	ops, err := readManifest(journalDir)
+	if err != nil {
+		return 0, 0, err
+	}
+	items := make([]retention.Item, len(ops))
+	for i, op := range ops {
+		items[i] = retention.Item{Time: op.Time, Size: op.Size}
+	}
+	expired := j.policy.Expired(j.now(), items)
+	var n int
+	var size int64
+	for i, e := range expired {
+		if e {
+			n++
+			size += ops[i].Size
+		}
+	}
+	if n == 0 {
+		return 0, 0, nil
+	}
+	if err := removeLocked(journalDir, ops, expired); err != nil {
+		return 0, 0, err
+	}
+	return n, size, nil
+}
+
+// lockDir takes the lock file of journalDir, waiting for another server
+// instance that holds it.
+func lockDir(journalDir string) (func(), error) {
+	release, err := filelock.Acquire(filepath.Join(journalDir, lockName), lockWait)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock journal directory %s: %w", journalDir, err)
+	}
+	return release, nil
+}
+
+// unchanged reports whether the file at path is still in state after, or
+// still absent if after is nil.
+func unchanged(path string, after *State) bool {
+	info, err := os.Lstat(path)
+	if after == nil {
+		return os.IsNotExist(err)
+	}
+	return err == nil && info.Size() == after.Size && info.ModTime().Equal(after.ModTime)
+}
+
+// revert undoes one change, using the snapshots in opDir.
+func revert(opDir string, c Change) error {
+	switch {
+	case c.MovedTo != "":
+		if err := os.MkdirAll(filepath.Dir(c.Path), 0755); err != nil {
+			return err
+		}
+		return os.Rename(c.MovedTo, c.Path)
+	case c.Snapshot != "":
+		if info, err := os.Lstat(c.Path); err == nil && info.IsDir() {
+			return fmt.Errorf("%s is now a directory", c.Path)
+		}
+		if err := os.MkdirAll(filepath.Dir(c.Path), 0755); err != nil {
+			return err
+		}
+		tmp := filepath.Join(filepath.Dir(c.Path), ".tmp-undo-"+filepath.Base(opDir)+"-"+c.Snapshot)
+		if err := copyFile(filepath.Join(opDir, c.Snapshot), tmp, c.Mode); err != nil {
+			os.Remove(tmp)
+			return err
+		}
+		if err := os.Chtimes(tmp, c.ModTime, c.ModTime); err != nil {
+			os.Remove(tmp)
+			return err
+		}
+		if err := os.Rename(tmp, c.Path); err != nil {
+			os.Remove(tmp)
+			return err
+		}
+		return nil
+	default:
+		if err := os.Remove(c.Path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+}
+
+// removeLocked writes the operations of journalDir not marked in drop back
+// to its manifest, then deletes the snapshots of the dropped ones.
+func removeLocked(journalDir string, ops []Operation, drop []bool) error {
+	var kept []Operation
+	for i, op := range ops {
+		if !drop[i] {
+			kept = append(kept, op)
+		}
+	}
+	if err := writeManifest(journalDir, kept); err != nil {
+		return err
+	}
+	for i, op := range ops {
+		if drop[i] {
+			if err := os.RemoveAll(filepath.Join(journalDir, op.ID)); err != nil {
+				return fmt.Errorf("failed to remove snapshots of %s: %w", op.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// find returns the journal directory holding id.
+func (j *Journal) find(roots []string, id string) (string, error) {
+	for _, root := range roots {
+		journalDir := j.Dir(root)
+		ops, err := readManifest(journalDir)
+		if err != nil {
+			return "", err
+		}
+		if slices.ContainsFunc(ops, func(op Operation) bool { return op.ID == id }) {
+			return journalDir, nil
+		}
+	}
+	return "", ErrNotFound
+}
+
+// copyFile copies src to dst, which must not exist, with mode perm.
+func copyFile(src, dst string, perm fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Chmod(perm); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// newID returns a new operation ID that sorts by time.
+func newID(now time.Time) string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return now.UTC().Format("20060102T150405") + "-" + hex.EncodeToString(b)
+}
+
+// readManifest reads the manifest of journalDir, which may not exist yet.
+func readManifest(journalDir string) ([]Operation, error) {
+	path := filepath.Join(journalDir, manifestName)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	var ops []Operation
+	if err := json.Unmarshal(data, &ops); err != nil {
+		return nil, fmt.Errorf("failed to parse journal %s: %w", path, err)
+	}
+	// IDs and snapshots name files in journalDir, so one that is not a
+	// plain name could only come from a tampered manifest
+	for _, op := range ops {
+		if !plainName(op.ID) {
+			return nil, fmt.Errorf("journal %s has an invalid operation ID %q", path, op.ID)
+		}
+		for _, c := range op.Changes {
+			if c.Snapshot != "" && !plainName(c.Snapshot) {
+				return nil, fmt.Errorf("journal %s has an invalid snapshot name %q", path, c.Snapshot)
+			}
+		}
+	}
+	return ops, nil
+}
+
+// plainName reports whether name is a single path component.
+func plainName(name string) bool {
+	return name != "" && name != "." && name != ".." && filepath.Base(name) == name
+}
+
+// writeManifest replaces the manifest of journalDir atomically.
+func writeManifest(journalDir string, ops []Operation) error {
+	if ops == nil {
+		ops = []Operation{}
+	}
+	data, err := json.MarshalIndent(ops, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(journalDir, manifestName+".tmp")
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(journalDir, manifestName)); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package journal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/portertech/filesystem-mcp-server/internal/retention"
+)
+
+func allow(string) error { return nil }
+
+func TestUndoOverwriteAndCreate(t *testing.T) {
+	root := t.TempDir()
+	j := New(retention.Policy{MaxBytes: DefaultMaxBytes}, nil)
+	existing := filepath.Join(root, "a.txt")
+	created := filepath.Join(root, "sub", "b.txt")
+	os.WriteFile(existing, []byte("before"), 0640)
+
+	p := j.Begin("write_file", root)
+	p.Save(existing)
+	p.Save(created)
+	os.WriteFile(existing, []byte("after"), 0640)
+	os.MkdirAll(filepath.Dir(created), 0755)
+	os.WriteFile(created, []byte("new"), 0644)
+	if err := p.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	ops, err := j.List([]string{root})
+	if err != nil || len(ops) != 1 || len(ops[0].Changes) != 2 || ops[0].Size != 6 {
+		t.Fatalf("unexpected journal: %v, %+v", err, ops)
+	}
+	if _, err := j.Undo([]string{root}, ops[0].ID, false, allow); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "before" {
+		t.Errorf("unexpected content: %q", data)
+	}
+	if info, _ := os.Stat(existing); info.Mode().Perm() != 0640 {
+		t.Errorf("expected the mode to be restored, got %v", info.Mode().Perm())
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Error("expected the created file to be removed")
+	}
+	if ops, _ := j.List([]string{root}); len(ops) != 0 {
+		t.Errorf("expected the operation to be dropped, got %+v", ops)
+	}
+	if _, err := os.Stat(filepath.Join(j.Dir(root), ops[0].ID)); !os.IsNotExist(err) {
+		t.Error("expected the snapshots to be deleted")
+	}
+}
+
+func TestUndoMoveAndConflict(t *testing.T) {
+	root := t.TempDir()
+	j := New(retention.Policy{}, nil)
+	src := filepath.Join(root, "src.txt")
+	dst := filepath.Join(root, "dst.txt")
+	os.WriteFile(src, []byte("x"), 0644)
+
+	p := j.Begin("move_file", root)
+	p.Move(src, dst)
+	os.Rename(src, dst)
+	p.Commit()
+	ops, _ := j.List([]string{root})
+
+	os.WriteFile(dst, []byte("changed later"), 0644)
+	if _, err := j.Undo([]string{root}, ops[0].ID, false, allow); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+	denied := errors.New("denied")
+	if _, err := j.Undo([]string{root}, ops[0].ID, true, func(string) error { return denied }); !errors.Is(err, denied) {
+		t.Fatalf("expected check to stop the undo, got %v", err)
+	}
+	if _, err := j.Undo([]string{root}, ops[0].ID, true, allow); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if data, _ := os.ReadFile(src); string(data) != "changed later" {
+		t.Errorf("unexpected content: %q", data)
+	}
+	if _, err := j.Undo([]string{root}, ops[0].ID, false, allow); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the operation to be gone, got %v", err)
+	}
+}
+
+func TestRetention(t *testing.T) {
+	root := t.TempDir()
+	j := New(retention.Policy{MaxAge: time.Hour, MaxBytes: 10}, nil)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	j.now = func() time.Time { return now }
+	path := filepath.Join(root, "f")
+
+	for _, content := range []string{"aaaa", "bbbbbb", "cccccc", "this is too large"} {
+		os.WriteFile(path, []byte(content), 0644)
+		p := j.Begin("write_file", root)
+		p.Save(path)
+		err := p.Commit()
+		if content == "this is too large" && err == nil {
+			t.Error("expected a snapshot over the limit to be reported")
+		}
+		now = now.Add(time.Minute)
+	}
+	ops, _ := j.List([]string{root})
+	if len(ops) != 2 || ops[0].Error == "" || ops[1].Size != 6 {
+		t.Fatalf("expected the oldest operations to be dropped, got %+v", ops)
+	}
+	if _, err := j.Undo([]string{root}, ops[0].ID, false, allow); err == nil {
+		t.Error("expected an operation without snapshots to be refused")
+	}
+
+	now = now.Add(2 * time.Hour)
+	if n, _, err := j.Prune([]string{root}); err != nil || n != 2 {
+		t.Errorf("Prune: %d, %v", n, err)
+	}
+}
@@ -0,0 +1,275 @@
+package jsonquery
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// filter is a filter expression, [?(...)], tested against each member or
+// element of a value.
+type filter struct {
+	// op is "&&", "||", or "!" for logical operators, a comparison
+	// operator, or "" to test that left exists
+	op          string
+	left, right *operand
+	args        []*filter
+}
+
+// operand is a side of a comparison: a path relative to @, or a literal.
+type operand struct {
+	path    []string
+	literal any
+}
+
+// parseFilter parses a filter expression after the ?, returning the rest
+// of s after it.
+func parseFilter(s string) (*filter, string, error) {
+	p := &filterParser{s: s}
+	f, err := p.or()
+	if err != nil {
+		return nil, "", err
+	}
+	return f, p.s, nil
+}
+
+// filterParser parses a filter expression by recursive descent.
+type filterParser struct {
+	s string
+}
+
+// accept consumes tok if s starts with it after spaces.
+func (p *filterParser) accept(tok string) bool {
+	p.s = strings.TrimLeft(p.s, " ")
+	if strings.HasPrefix(p.s, tok) {
+		p.s = p.s[len(tok):]
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) or() (*filter, error) {
+	f, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		g, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		f = &filter{op: "||", args: []*filter{f, g}}
+	}
+	return f, nil
+}
+
+func (p *filterParser) and() (*filter, error) {
+	f, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		g, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		f = &filter{op: "&&", args: []*filter{f, g}}
+	}
+	return f, nil
+}
+
+func (p *filterParser) unary() (*filter, error) {
+	if p.accept("!") {
+		f, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &filter{op: "!", args: []*filter{f}}, nil
+	}
+	if p.accept("(") {
+		f, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, errors.New("missing ')' in filter")
+		}
+		return f, nil
+	}
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.accept(op) {
+			right, err := p.operand()
+			if err != nil {
+				return nil, err
+			}
+			return &filter{op: op, left: left, right: right}, nil
+		}
+	}
+	if left.path == nil {
+		return nil, errors.New("filter tests a literal instead of a path")
+	}
+	return &filter{left: left}, nil
+}
+
+func (p *filterParser) operand() (*operand, error) {
+	p.s = strings.TrimLeft(p.s, " ")
+	switch {
+	case p.s == "":
+		return nil, errors.New("unterminated filter")
+	case p.s[0] == '@':
+		p.s = p.s[1:]
+		path := []string{}
+		for {
+			switch {
+			case strings.HasPrefix(p.s, "."):
+				end := strings.IndexAny(p.s[1:], ".[ )=!<>&|]")
+				if end < 0 {
+					end = len(p.s) - 1
+				}
+				if end == 0 {
+					return nil, errors.New("missing name after '.' in filter")
+				}
+				path = append(path, p.s[1:1+end])
+				p.s = p.s[1+end:]
+			case strings.HasPrefix(p.s, "['"), strings.HasPrefix(p.s, `["`):
+				name, rest, err := parseString(p.s[1:])
+				if err != nil {
+					return nil, err
+				}
+				if !strings.HasPrefix(rest, "]") {
+					return nil, errors.New("missing ']' in filter")
+				}
+				path, p.s = append(path, name), rest[1:]
+			case strings.HasPrefix(p.s, "["):
+				end := strings.IndexByte(p.s, ']')
+				if end < 0 {
+					return nil, errors.New("missing ']' in filter")
+				}
+				if _, err := strconv.Atoi(p.s[1:end]); err != nil {
+					return nil, fmt.Errorf("invalid index %q in filter", p.s[1:end])
+				}
+				path, p.s = append(path, p.s[1:end]), p.s[end+1:]
+			default:
+				return &operand{path: path}, nil
+			}
+		}
+	case p.s[0] == '\'' || p.s[0] == '"':
+		str, rest, err := parseString(p.s)
+		if err != nil {
+			return nil, err
+		}
+		p.s = rest
+		return &operand{literal: str}, nil
+	}
+	end := strings.IndexAny(p.s, " )&|=!<>]")
+	if end < 0 {
+		end = len(p.s)
+	}
+	word := p.s[:end]
+	p.s = p.s[end:]
+	switch word {
+	case "true":
+		return &operand{literal: true}, nil
+	case "false":
+		return &operand{literal: false}, nil
+	case "null":
+		return &operand{literal: nil}, nil
+	}
+	n, err := strconv.ParseFloat(word, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected %q in filter", word)
+	}
+	return &operand{literal: n}, nil
+}
+
+// test reports whether v, a decoded JSON value, passes the filter.
+func (f *filter) test(v any) bool {
+	switch f.op {
+	case "&&":
+		return f.args[0].test(v) && f.args[1].test(v)
+	case "||":
+		return f.args[0].test(v) || f.args[1].test(v)
+	case "!":
+		return !f.args[0].test(v)
+	case "":
+		_, ok := f.left.value(v)
+		return ok
+	}
+	a, ok := f.left.value(v)
+	if !ok {
+		return false
+	}
+	b, ok := f.right.value(v)
+	if !ok {
+		return false
+	}
+	switch f.op {
+	case "==":
+		return reflect.DeepEqual(a, b)
+	case "!=":
+		return !reflect.DeepEqual(a, b)
+	}
+	// Order compares two numbers or two strings; anything else fails
+	var order int
+	switch a := a.(type) {
+	case float64:
+		b, ok := b.(float64)
+		if !ok {
+			return false
+		}
+		order = cmp.Compare(a, b)
+	case string:
+		b, ok := b.(string)
+		if !ok {
+			return false
+		}
+		order = strings.Compare(a, b)
+	default:
+		return false
+	}
+	switch f.op {
+	case "<":
+		return order < 0
+	case "<=":
+		return order <= 0
+	case ">":
+		return order > 0
+	default:
+		return order >= 0
+	}
+}
+
+// value returns the operand's value for v, and whether it exists.
+func (o *operand) value(v any) (any, bool) {
+	if o.path == nil {
+		return o.literal, true
+	}
+	for _, step := range o.path {
+		switch c := v.(type) {
+		case map[string]any:
+			var ok bool
+			if v, ok = c[step]; !ok {
+				return nil, false
+			}
+		case []any:
+			i, err := strconv.Atoi(step)
+			if i < 0 {
+				i += len(c)
+			}
+			if err != nil || i < 0 || i >= len(c) {
+				return nil, false
+			}
+			v = c[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
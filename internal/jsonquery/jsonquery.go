@@ -0,0 +1,454 @@
+// Package jsonquery evaluates JSONPath expressions, and the dotted paths of
+// gjson, against a JSON document read from a stream.
+//
+// Member names, non-negative indexes, wildcards, and forward slices are
+// matched as the document streams past, so values that do not match are
+// skipped without being kept. Recursive descent, filters, negative indexes,
+// and lengths need the value they apply to in memory, and read it whole.
+//
+// Supported JSONPath syntax: $, .name, ['name'], [n] (negative counts from
+// the end), [*] and .*, [start:end:step], unions such as ['a','b'] or
+// [0,2], ..name for recursive descent, and filters such as
+// [?(@.price < 10 && @.tags)], comparing with ==, !=, <, <=, >, and >= or
+// testing that a member exists. An expression that does not start with $ is
+// a gjson path: names separated by dots, with \. for a literal dot, * and ?
+// as wildcards within a name, numbers as array indexes, and # for the
+// length of an array, or, followed by more of the path, each of its
+// elements.
+package jsonquery
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ErrLimit is returned by Eval when emit returns it, to stop early.
+var ErrLimit = errors.New("match limit reached")
+
+// Match is a value the query selected.
+type Match struct {
+	// Path is the normalized JSONPath of the value.
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// segmentKind is the kind of a step in a query.
+type segmentKind int
+
+const (
+	segName segmentKind = iota
+	segGlob
+	segIndex
+	segWildcard
+	segSlice
+	segUnion
+	segDescend
+	segFilter
+	segLength
+)
+
+// segment is a step in a query.
+type segment struct {
+	kind  segmentKind
+	name  string
+	index int
+	// slice bounds; hasStart and hasEnd say whether they were given
+	start, end, step int
+	hasStart, hasEnd bool
+	union            []segment
+	sub              *segment
+	filter           *filter
+}
+
+// Query is a parsed query.
+type Query struct {
+	expr     string
+	segments []segment
+}
+
+// String returns the expression the query was parsed from.
+func (q Query) String() string {
+	return q.expr
+}
+
+// Parse parses a JSONPath expression, or a gjson path if expr does not
+// start with $.
+func Parse(expr string) (Query, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return Query{}, errors.New("empty query")
+	}
+	var segs []segment
+	var err error
+	if strings.HasPrefix(expr, "$") {
+		segs, err = parseJSONPath(expr[1:])
+	} else {
+		segs, err = parseGJSON(expr)
+	}
+	if err != nil {
+		return Query{}, fmt.Errorf("invalid query %q: %w", expr, err)
+	}
+	return Query{expr: expr, segments: segs}, nil
+}
+
+// Streams reports whether the query selects values as the document is
+// read, keeping only the matches in memory. A query that does not may read
+// a whole array or object into memory.
+func (q Query) Streams() bool {
+	for _, seg := range q.segments {
+		if !seg.streams() {
+			return false
+		}
+	}
+	return true
+}
+
+// Eval evaluates the query against the JSON document read from r, calling
+// emit for each match in document order. It stops at the first error emit
+// returns; ErrLimit stops it without being returned.
+func (q Query) Eval(r io.Reader, emit func(Match) error) error {
+	dec := json.NewDecoder(r)
+	err := eval(dec, "$", q.segments, emit)
+	if errors.Is(err, ErrLimit) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("invalid JSON: unexpected data after the top-level value")
+	}
+	return nil
+}
+
+// streams reports whether seg can be applied to a value as it is read.
+func (seg segment) streams() bool {
+	switch seg.kind {
+	case segName, segGlob, segWildcard:
+		return true
+	case segIndex:
+		return seg.index >= 0
+	case segSlice:
+		return seg.step > 0 && seg.start >= 0 && (!seg.hasEnd || seg.end >= 0)
+	case segUnion:
+		for _, u := range seg.union {
+			if !u.streams() {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// matchesName reports whether seg selects the object member name.
+func (seg segment) matchesName(name string) bool {
+	switch seg.kind {
+	case segName:
+		return seg.name == name
+	case segGlob:
+		ok, _ := path.Match(seg.name, name)
+		return ok
+	case segWildcard:
+		return true
+	case segUnion:
+		for _, u := range seg.union {
+			if u.matchesName(name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchesIndex reports whether seg selects element i of an array of n
+// elements; n is -1 while the array is streamed.
+func (seg segment) matchesIndex(i, n int) bool {
+	switch seg.kind {
+	case segName:
+		// gjson paths and .0 in JSONPath name elements by number
+		idx, err := strconv.Atoi(seg.name)
+		return err == nil && idx == i && seg.name == strconv.Itoa(idx)
+	case segIndex:
+		idx := seg.index
+		if idx < 0 {
+			idx += n
+		}
+		return idx == i
+	case segWildcard:
+		return true
+	case segSlice:
+		// Negative bounds count from the end and clamp to the first
+		// element, or to just before it when the step walks backwards
+		lower := 0
+		if seg.step < 0 {
+			lower = -1
+		}
+		start, end := seg.start, seg.end
+		if start < 0 {
+			start = max(lower, start+n)
+		}
+		if !seg.hasEnd {
+			end = -1
+		} else if end < 0 {
+			end = max(lower, end+n)
+		}
+		if seg.step > 0 {
+			return i >= start && (end < 0 || i < end) && (i-start)%seg.step == 0
+		}
+		// A negative step walks back from start, which defaults to the end
+		if !seg.hasStart {
+			start = n - 1
+		}
+		if !seg.hasEnd {
+			end = -1
+		}
+		return i <= start && i > end && (start-i)%(-seg.step) == 0
+	case segUnion:
+		for _, u := range seg.union {
+			if u.matchesIndex(i, n) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// eval applies segs to the value dec is at, consuming the value.
+func eval(dec *json.Decoder, at string, segs []segment, emit func(Match) error) error {
+	if len(segs) == 0 {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return invalid(err)
+		}
+		return emit(Match{Path: at, Value: raw})
+	}
+	seg := segs[0]
+	if !seg.streams() {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return invalid(err)
+		}
+		return evalValue(raw, at, segs, emit)
+	}
+
+	tok, err := dec.Token()
+	if err != nil {
+		return invalid(err)
+	}
+	switch tok {
+	case json.Delim('{'):
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return invalid(err)
+			}
+			name, _ := key.(string)
+			if seg.matchesName(name) {
+				err = eval(dec, at+formatName(name), segs[1:], emit)
+			} else {
+				err = skip(dec)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	case json.Delim('['):
+		for i := 0; dec.More(); i++ {
+			if seg.matchesIndex(i, -1) {
+				err = eval(dec, at+"["+strconv.Itoa(i)+"]", segs[1:], emit)
+			} else {
+				err = skip(dec)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		// Nothing within a scalar matches
+		return nil
+	}
+	if _, err := dec.Token(); err != nil {
+		return invalid(err)
+	}
+	return nil
+}
+
+// evalValue applies segs to raw, a value in memory.
+func evalValue(raw json.RawMessage, at string, segs []segment, emit func(Match) error) error {
+	if len(segs) == 0 || segs[0].streams() {
+		return eval(json.NewDecoder(bytes.NewReader(raw)), at, segs, emit)
+	}
+	seg := segs[0]
+	switch seg.kind {
+	case segDescend:
+		// The step after .. applies here, then to every descendant
+		if err := evalValue(raw, at, append([]segment{*seg.sub}, segs[1:]...), emit); err != nil {
+			return err
+		}
+		return eachChild(raw, at, func(child json.RawMessage, childAt string) error {
+			return evalValue(child, childAt, segs, emit)
+		})
+	case segLength:
+		n := -1
+		switch firstByte(raw) {
+		case '[':
+			var elems []json.RawMessage
+			if err := json.Unmarshal(raw, &elems); err != nil {
+				return invalid(err)
+			}
+			n = len(elems)
+		case '{':
+			n = 0
+			if err := eachChild(raw, at, func(json.RawMessage, string) error { n++; return nil }); err != nil {
+				return err
+			}
+		case '"':
+			var s string
+			if err := json.Unmarshal(raw, &s); err != nil {
+				return invalid(err)
+			}
+			n = len([]rune(s))
+		}
+		if n < 0 {
+			return nil
+		}
+		return evalValue(json.RawMessage(strconv.Itoa(n)), at+".length()", segs[1:], emit)
+	case segFilter:
+		return eachChild(raw, at, func(child json.RawMessage, childAt string) error {
+			var v any
+			if err := json.Unmarshal(child, &v); err != nil {
+				return invalid(err)
+			}
+			if !seg.filter.test(v) {
+				return nil
+			}
+			return evalValue(child, childAt, segs[1:], emit)
+		})
+	default:
+		// Indexes and slices that count from the end need the length
+		if firstByte(raw) != '[' {
+			return nil
+		}
+		var elems []json.RawMessage
+		if err := json.Unmarshal(raw, &elems); err != nil {
+			return invalid(err)
+		}
+		for _, i := range selectIndexes(seg, len(elems)) {
+			if err := evalValue(elems[i], at+"["+strconv.Itoa(i)+"]", segs[1:], emit); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// selectIndexes returns the elements of an array of n elements that seg
+// selects, in the order it selects them.
+func selectIndexes(seg segment, n int) []int {
+	var out []int
+	switch {
+	case seg.kind == segUnion:
+		for _, u := range seg.union {
+			out = append(out, selectIndexes(u, n)...)
+		}
+	case seg.kind == segSlice && seg.step < 0:
+		for i := n - 1; i >= 0; i-- {
+			if seg.matchesIndex(i, n) {
+				out = append(out, i)
+			}
+		}
+	default:
+		for i := range n {
+			if seg.matchesIndex(i, n) {
+				out = append(out, i)
+			}
+		}
+	}
+	return out
+}
+
+// eachChild calls fn with each member value of an object or element of an
+// array, and does nothing for a scalar.
+func eachChild(raw json.RawMessage, at string, fn func(json.RawMessage, string) error) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	tok, err := dec.Token()
+	if err != nil {
+		return invalid(err)
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return nil
+	}
+	for i := 0; dec.More(); i++ {
+		childAt := at + "[" + strconv.Itoa(i) + "]"
+		if delim == '{' {
+			key, err := dec.Token()
+			if err != nil {
+				return invalid(err)
+			}
+			name, _ := key.(string)
+			childAt = at + formatName(name)
+		}
+		var child json.RawMessage
+		if err := dec.Decode(&child); err != nil {
+			return invalid(err)
+		}
+		if err := fn(child, childAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skip consumes the value dec is at.
+func skip(dec *json.Decoder) error {
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return invalid(err)
+	}
+	return nil
+}
+
+// firstByte returns the first non-space byte of raw.
+func firstByte(raw json.RawMessage) byte {
+	raw = bytes.TrimLeft(raw, " \t\r\n")
+	if len(raw) == 0 {
+		return 0
+	}
+	return raw[0]
+}
+
+// formatName returns the path step for an object member: .name for plain
+// identifiers and ['name'] otherwise.
+func formatName(name string) string {
+	plain := name != ""
+	for i, r := range name {
+		if !(r == '_' || r == '$' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && r >= '0' && r <= '9') {
+			plain = false
+			break
+		}
+	}
+	if plain {
+		return "." + name
+	}
+	return "['" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(name) + "']"
+}
+
+// invalid wraps a decoding error.
+func invalid(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return errors.New("invalid JSON: unexpected end of input")
+	}
+	var syntax *json.SyntaxError
+	if errors.As(err, &syntax) {
+		return fmt.Errorf("invalid JSON at byte %d: %w", syntax.Offset, err)
+	}
+	return err
+}
@@ -0,0 +1,141 @@
+package jsonquery
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const store = `{
+	"store": {
+		"book": [
+			{"category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95},
+			{"category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 12.99},
+			{"category": "fiction", "author": "Herman Melville", "title": "Moby Dick", "isbn": "0-553-21311-3", "price": 8.99},
+			{"category": "fiction", "author": "J. R. R. Tolkien", "title": "The Lord of the Rings", "isbn": "0-395-19395-8", "price": 22.99}
+		],
+		"bicycle": {"color": "red", "price": 19.95}
+	},
+	"file.name": "x",
+	"my key": 1
+}`
+
+func query(t *testing.T, doc, expr string) ([]string, []string) {
+	t.Helper()
+	q, err := Parse(expr)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", expr, err)
+	}
+	var paths, values []string
+	err = q.Eval(strings.NewReader(doc), func(m Match) error {
+		paths = append(paths, m.Path)
+		values = append(values, string(m.Value))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Eval(%q): %v", expr, err)
+	}
+	return paths, values
+}
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		expr   string
+		values []string
+	}{
+		{"$.store.bicycle.color", []string{`"red"`}},
+		{"$['store']['bicycle']['price']", []string{`19.95`}},
+		{"$.store.book[0].author", []string{`"Nigel Rees"`}},
+		{"$.store.book[-1].title", []string{`"The Lord of the Rings"`}},
+		{"$.store.book[*].price", []string{`8.95`, `12.99`, `8.99`, `22.99`}},
+		{"$.store.book[1:3].price", []string{`12.99`, `8.99`}},
+		{"$.store.book[-2:].price", []string{`8.99`, `22.99`}},
+		{"$.store.book[::-2].price", []string{`22.99`, `12.99`}},
+		{"$.store.book[2:-10:-1].price", []string{`8.99`, `12.99`, `8.95`}},
+		{"$.store.book[-10::-1].price", nil},
+		{"$.store.book[0,2].price", []string{`8.95`, `8.99`}},
+		{"$.store.bicycle['color','price']", []string{`"red"`, `19.95`}},
+		{"$..price", []string{`8.95`, `12.99`, `8.99`, `22.99`, `19.95`}},
+		{"$..book[?(@.isbn)].title", []string{`"Moby Dick"`, `"The Lord of the Rings"`}},
+		{"$.store.book[?(@.price < 10)].title", []string{`"Sayings of the Century"`, `"Moby Dick"`}},
+		{"$.store.book[?(@.category == 'fiction' && @.price >= 12.99)].price", []string{`12.99`, `22.99`}},
+		{"$.store.book[?(@.price > 20 || @.category == \"reference\")].price", []string{`8.95`, `22.99`}},
+		{"$.store.book[?(!@.isbn)].price", []string{`8.95`, `12.99`}},
+		{"$.store.book.length()", []string{`4`}},
+		{"$['my key']", []string{`1`}},
+		{"$.missing", nil},
+		{"$.store.bicycle.color.x", nil},
+
+		{"store.bicycle.color", []string{`"red"`}},
+		{"store.book.1.author", []string{`"Evelyn Waugh"`}},
+		{"store.book.#", []string{`4`}},
+		{"store.book.#.author", []string{`"Nigel Rees"`, `"Evelyn Waugh"`, `"Herman Melville"`, `"J. R. R. Tolkien"`}},
+		{"store.bi*.col?r", []string{`"red"`}},
+		{`file\.name`, []string{`"x"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			if _, got := query(t, store, tt.expr); !reflect.DeepEqual(got, tt.values) {
+				t.Errorf("got %v, want %v", got, tt.values)
+			}
+		})
+	}
+}
+
+func TestEvalPaths(t *testing.T) {
+	paths, _ := query(t, store, "$..isbn")
+	want := []string{"$.store.book[2].isbn", "$.store.book[3].isbn"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("got %v, want %v", paths, want)
+	}
+	if paths, _ := query(t, store, "file\\.name"); !reflect.DeepEqual(paths, []string{"$['file.name']"}) {
+		t.Errorf("got %v", paths)
+	}
+	if paths, _ := query(t, store, "$"); !reflect.DeepEqual(paths, []string{"$"}) {
+		t.Errorf("got %v", paths)
+	}
+}
+
+func TestEvalLimit(t *testing.T) {
+	q, err := Parse("$..price")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	err = q.Eval(strings.NewReader(store), func(Match) error {
+		if n == 2 {
+			return ErrLimit
+		}
+		n++
+		return nil
+	})
+	if err != nil || n != 2 {
+		t.Errorf("got %d matches and %v, want 2 and nil", n, err)
+	}
+}
+
+func TestEvalInvalidJSON(t *testing.T) {
+	for _, doc := range []string{`{"a": `, `{"a": 1} x`, `{"a" 1}`} {
+		q, _ := Parse("$.a")
+		if err := q.Eval(strings.NewReader(doc), func(Match) error { return nil }); err == nil || !strings.Contains(err.Error(), "invalid JSON") {
+			t.Errorf("%q: expected invalid JSON error, got %v", doc, err)
+		}
+	}
+}
+
+func TestEvalStopsOnEmitError(t *testing.T) {
+	q, _ := Parse("$.store.book[*]")
+	stop := errors.New("stop")
+	if err := q.Eval(strings.NewReader(store), func(Match) error { return stop }); err != stop {
+		t.Errorf("expected emit error, got %v", err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{"", "$.", "$[", "$[1:2:0]", "$[?(@.a ==)]", "$[?(1)]", "$['a", "$x", "a..b"} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q): expected error", expr)
+		}
+	}
+}
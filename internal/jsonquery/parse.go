@@ -0,0 +1,219 @@
+package jsonquery
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseJSONPath parses the steps of a JSONPath expression after the $.
+func parseJSONPath(s string) ([]segment, error) {
+	var segs []segment
+	for s != "" {
+		var seg segment
+		var err error
+		switch {
+		case strings.HasPrefix(s, ".."):
+			s = s[2:]
+			var sub segment
+			if strings.HasPrefix(s, "[") {
+				sub, s, err = parseBracket(s)
+			} else {
+				sub, s, err = parseDotted(s)
+			}
+			seg = segment{kind: segDescend, sub: &sub}
+		case strings.HasPrefix(s, "."):
+			seg, s, err = parseDotted(s[1:])
+		case strings.HasPrefix(s, "["):
+			seg, s, err = parseBracket(s)
+		default:
+			return nil, fmt.Errorf("unexpected %q", s)
+		}
+		if err != nil {
+			return nil, err
+		}
+		segs = append(segs, seg)
+	}
+	return segs, nil
+}
+
+// parseDotted parses the step after a dot: a name, *, or length().
+func parseDotted(s string) (segment, string, error) {
+	if strings.HasPrefix(s, "*") {
+		return segment{kind: segWildcard}, s[1:], nil
+	}
+	end := strings.IndexAny(s, ".[ ")
+	if end < 0 {
+		end = len(s)
+	}
+	name := s[:end]
+	if name == "" {
+		return segment{}, "", errors.New("missing name after '.'")
+	}
+	if name == "length()" {
+		return segment{kind: segLength}, s[end:], nil
+	}
+	return segment{kind: segName, name: name}, s[end:], nil
+}
+
+// parseBracket parses a bracketed step.
+func parseBracket(s string) (segment, string, error) {
+	s = strings.TrimLeft(s[1:], " ")
+	if strings.HasPrefix(s, "?") {
+		f, rest, err := parseFilter(s[1:])
+		if err != nil {
+			return segment{}, "", err
+		}
+		rest = strings.TrimLeft(rest, " ")
+		if !strings.HasPrefix(rest, "]") {
+			return segment{}, "", errors.New("missing ']' after filter")
+		}
+		return segment{kind: segFilter, filter: f}, rest[1:], nil
+	}
+
+	var items []segment
+	for {
+		var item segment
+		var err error
+		s = strings.TrimLeft(s, " ")
+		switch {
+		case strings.HasPrefix(s, "*"):
+			item, s = segment{kind: segWildcard}, s[1:]
+		case strings.HasPrefix(s, "'"), strings.HasPrefix(s, `"`):
+			var name string
+			name, s, err = parseString(s)
+			item = segment{kind: segName, name: name}
+		default:
+			item, s, err = parseIndexOrSlice(s)
+		}
+		if err != nil {
+			return segment{}, "", err
+		}
+		items = append(items, item)
+		s = strings.TrimLeft(s, " ")
+		if strings.HasPrefix(s, ",") {
+			s = s[1:]
+			continue
+		}
+		if !strings.HasPrefix(s, "]") {
+			return segment{}, "", errors.New("missing ']'")
+		}
+		s = s[1:]
+		break
+	}
+	if len(items) == 1 {
+		return items[0], s, nil
+	}
+	return segment{kind: segUnion, union: items}, s, nil
+}
+
+// parseIndexOrSlice parses n or start:end:step.
+func parseIndexOrSlice(s string) (segment, string, error) {
+	end := strings.IndexAny(s, ",]")
+	if end < 0 {
+		return segment{}, "", errors.New("missing ']'")
+	}
+	text, rest := strings.TrimSpace(s[:end]), s[end:]
+	parts := strings.Split(text, ":")
+	if len(parts) == 1 {
+		n, err := strconv.Atoi(text)
+		if err != nil {
+			return segment{}, "", fmt.Errorf("invalid index %q", text)
+		}
+		return segment{kind: segIndex, index: n}, rest, nil
+	}
+	if len(parts) > 3 {
+		return segment{}, "", fmt.Errorf("invalid slice %q", text)
+	}
+	seg := segment{kind: segSlice, step: 1}
+	for i, p := range parts {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return segment{}, "", fmt.Errorf("invalid slice %q", text)
+		}
+		switch i {
+		case 0:
+			seg.start, seg.hasStart = n, true
+		case 1:
+			seg.end, seg.hasEnd = n, true
+		case 2:
+			if n == 0 {
+				return segment{}, "", errors.New("slice step must not be 0")
+			}
+			seg.step = n
+		}
+	}
+	return seg, rest, nil
+}
+
+// parseString parses a quoted string with backslash escapes.
+func parseString(s string) (string, string, error) {
+	quote := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == quote:
+			return b.String(), s[i+1:], nil
+		case c == '\\' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", "", errors.New("unterminated string")
+}
+
+// parseGJSON parses a gjson path.
+func parseGJSON(s string) ([]segment, error) {
+	var parts []string
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s):
+			i++
+			// Keep escaped wildcards escaped for path.Match
+			if s[i] == '*' || s[i] == '?' {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(s[i])
+		case s[i] == '.':
+			parts = append(parts, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	parts = append(parts, b.String())
+
+	var segs []segment
+	for i, p := range parts {
+		switch {
+		case p == "":
+			return nil, errors.New("empty name")
+		case p == "#" && i == len(parts)-1:
+			segs = append(segs, segment{kind: segLength})
+		case p == "#":
+			segs = append(segs, segment{kind: segWildcard})
+		case p == "*":
+			segs = append(segs, segment{kind: segWildcard})
+		case strings.ContainsAny(p, "*?"):
+			segs = append(segs, segment{kind: segGlob, name: p})
+		default:
+			segs = append(segs, segment{kind: segName, name: p})
+		}
+	}
+	return segs, nil
+}
@@ -0,0 +1,142 @@
+// Package membudget caps the bytes of file content that tool calls may
+// buffer at once. Reads reserve their size before loading a file and release
+// it once the response is built; a read that does not fit waits briefly for
+// others to finish and is then refused, so a burst of large reads cannot
+// exhaust the memory of a constrained container.
+package membudget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultMaxWait is how long a reservation waits for room before it is
+// refused.
+const DefaultMaxWait = 10 * time.Second
+
+var (
+	// ErrTooLarge is returned for a reservation larger than the whole
+	// budget, which could never be granted.
+	ErrTooLarge = errors.New("larger than the memory budget")
+	// ErrExhausted is returned when no room was freed within the wait.
+	ErrExhausted = errors.New("memory budget exhausted")
+)
+
+// Stats is a snapshot of a budget's usage.
+type Stats struct {
+	Limit int64 `json:"limit"`
+	InUse int64 `json:"inUse"`
+	Peak  int64 `json:"peak"`
+	// Active is the number of reservations currently held.
+	Active int `json:"active"`
+	// Waiting is the number of reservations queued for room.
+	Waiting  int   `json:"waiting"`
+	Rejected int64 `json:"rejected"`
+}
+
+// Budget tracks reserved bytes against a limit.
+type Budget struct {
+	mu       sync.Mutex
+	limit    int64
+	maxWait  time.Duration
+	inUse    int64
+	peak     int64
+	active   int
+	waiting  int
+	rejected int64
+	// freed is closed, and replaced, whenever a reservation is released.
+	freed chan struct{}
+}
+
+// New creates a budget of limit bytes whose reservations wait up to maxWait
+// for room.
+func New(limit int64, maxWait time.Duration) *Budget {
+	return &Budget{limit: limit, maxWait: maxWait, freed: make(chan struct{})}
+}
+
+// Reserve reserves n bytes, waiting for room if the budget is full. The
+// returned function releases the reservation; it is safe to call more than
+// once.
+func (b *Budget) Reserve(ctx context.Context, n int64) (func(), error) {
+	if n < 0 {
+		n = 0
+	}
+	if n > b.limit {
+		b.mu.Lock()
+		b.rejected++
+		b.mu.Unlock()
+		return nil, fmt.Errorf("%w: %d bytes requested, budget is %d bytes", ErrTooLarge, n, b.limit)
+	}
+
+	var timeout <-chan time.Time
+	b.mu.Lock()
+	for b.inUse+n > b.limit {
+		if timeout == nil {
+			timer := time.NewTimer(b.maxWait)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+		freed := b.freed
+		b.waiting++
+		b.mu.Unlock()
+
+		var err error
+		select {
+		case <-freed:
+		case <-timeout:
+			err = fmt.Errorf("%w: %d bytes requested, %d of %d bytes in use", ErrExhausted, n, b.InUse(), b.limit)
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+
+		b.mu.Lock()
+		b.waiting--
+		if err != nil {
+			b.rejected++
+			b.mu.Unlock()
+			return nil, err
+		}
+	}
+	b.inUse += n
+	b.active++
+	b.peak = max(b.peak, b.inUse)
+	b.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { b.release(n) })
+	}, nil
+}
+
+func (b *Budget) release(n int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.inUse -= n
+	b.active--
+	close(b.freed)
+	b.freed = make(chan struct{})
+}
+
+// InUse returns the bytes currently reserved.
+func (b *Budget) InUse() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.inUse
+}
+
+// Stats returns a snapshot of the budget's usage.
+func (b *Budget) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return Stats{
+		Limit:    b.limit,
+		InUse:    b.inUse,
+		Peak:     b.peak,
+		Active:   b.active,
+		Waiting:  b.waiting,
+		Rejected: b.rejected,
+	}
+}
@@ -0,0 +1,79 @@
+package membudget
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReserveAndRelease(t *testing.T) {
+	b := New(100, time.Second)
+	release, err := b.Reserve(context.Background(), 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := b.Stats(); got.InUse != 60 || got.Active != 1 || got.Peak != 60 {
+		t.Errorf("after reserve: %+v", got)
+	}
+	release()
+	release()
+	if got := b.Stats(); got.InUse != 0 || got.Active != 0 || got.Peak != 60 {
+		t.Errorf("after release: %+v", got)
+	}
+}
+
+func TestReserveTooLarge(t *testing.T) {
+	b := New(100, time.Second)
+	if _, err := b.Reserve(context.Background(), 101); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected ErrTooLarge, got %v", err)
+	}
+	if got := b.Stats(); got.Rejected != 1 {
+		t.Errorf("expected the rejection to be counted, got %+v", got)
+	}
+}
+
+func TestReserveWaitsForRoom(t *testing.T) {
+	b := New(100, 5*time.Second)
+	release, err := b.Reserve(context.Background(), 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		r, err := b.Reserve(context.Background(), 50)
+		if err == nil {
+			r()
+		}
+		done <- err
+	}()
+
+	// Wait until the second reservation is queued
+	for b.Stats().Waiting == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	release()
+	if err := <-done; err != nil {
+		t.Errorf("expected the queued reservation to be granted, got %v", err)
+	}
+}
+
+func TestReserveTimesOut(t *testing.T) {
+	b := New(100, 20*time.Millisecond)
+	if _, err := b.Reserve(context.Background(), 100); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Reserve(context.Background(), 1); !errors.Is(err, ErrExhausted) {
+		t.Errorf("expected ErrExhausted, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := b.Reserve(ctx, 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if got := b.Stats(); got.Waiting != 0 || got.Rejected != 2 || got.InUse != 100 {
+		t.Errorf("unexpected stats: %+v", got)
+	}
+}
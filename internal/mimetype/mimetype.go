@@ -0,0 +1,158 @@
+// Package mimetype detects the media type of files from their content, so
+// that files with unusual extensions or none are recognized. It knows the
+// image, audio, and video formats read_media_file serves.
+package mimetype
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// SniffLen is the number of leading bytes Detect looks at.
+const SniffLen = 512
+
+// Detect returns the media type of the content starting with head, or ""
+// if it is not a recognized media format.
+func Detect(head []byte) string {
+	has := func(off int, sig string) bool {
+		return len(head) >= off+len(sig) && string(head[off:off+len(sig)]) == sig
+	}
+	switch {
+	case has(0, "\x89PNG\r\n\x1a\n"):
+		return "image/png"
+	case has(0, "\xff\xd8\xff"):
+		return "image/jpeg"
+	case has(0, "GIF87a"), has(0, "GIF89a"):
+		return "image/gif"
+	case has(0, "BM") && len(head) >= 14 && head[6] == 0 && head[7] == 0 && head[8] == 0 && head[9] == 0:
+		// The reserved bytes after the file size tell bitmaps from text
+		// that happens to start with BM
+		return "image/bmp"
+	case has(0, "RIFF") && has(8, "WEBP"):
+		return "image/webp"
+	case has(0, "RIFF") && has(8, "WAVE"):
+		return "audio/wav"
+	case has(0, "RIFF") && has(8, "AVI "):
+		return "video/x-msvideo"
+	case has(0, "fLaC"):
+		return "audio/flac"
+	case has(0, "OggS"):
+		// Theora streams announce themselves in the first page
+		if bytes.Contains(head, []byte("\x80theora")) {
+			return "video/ogg"
+		}
+		return "audio/ogg"
+	case has(0, "ID3"), isMPEGAudio(head):
+		return "audio/mpeg"
+	case has(4, "ftyp"):
+		return isoMediaType(head)
+	case has(0, "\x1a\x45\xdf\xa3"):
+		// Matroska and WebM share the EBML header, which names the
+		// document type
+		if bytes.Contains(head, []byte("webm")) {
+			return "video/webm"
+		}
+		return "video/x-matroska"
+	case isSVG(head):
+		return "image/svg+xml"
+	}
+	return ""
+}
+
+// isoMediaType names an ISO base media file (MP4 and relatives) by its
+// major brand.
+func isoMediaType(head []byte) string {
+	if len(head) < 12 {
+		return ""
+	}
+	switch brand := string(head[8:12]); brand {
+	case "qt  ":
+		return "video/quicktime"
+	case "M4A ", "M4B ", "M4P ":
+		return "audio/mp4"
+	case "heic", "heix", "heim", "heis", "mif1", "msf1":
+		return "image/heic"
+	case "avif", "avis":
+		return "image/avif"
+	case "3gp4", "3gp5", "3gp6", "3g2a":
+		return "video/3gpp"
+	default:
+		return "video/mp4"
+	}
+}
+
+// isMPEGAudio reports whether head starts with an MPEG audio frame header:
+// an 11-bit sync, a valid version and layer, and a valid bitrate and sample
+// rate.
+func isMPEGAudio(head []byte) bool {
+	if len(head) < 4 || head[0] != 0xff || head[1]&0xe0 != 0xe0 {
+		return false
+	}
+	version := head[1] >> 3 & 0x3
+	layer := head[1] >> 1 & 0x3
+	bitrate := head[2] >> 4
+	sampleRate := head[2] >> 2 & 0x3
+	return version != 1 && layer != 0 && bitrate != 0 && bitrate != 0xf && sampleRate != 3
+}
+
+// isSVG reports whether head is XML text whose first element is <svg>,
+// skipping the XML declaration, comments, and a DOCTYPE.
+func isSVG(head []byte) bool {
+	if bytes.IndexByte(head, 0) >= 0 {
+		return false
+	}
+	s := strings.TrimPrefix(string(head), "\ufeff")
+	for {
+		s = strings.TrimLeft(s, " \t\r\n")
+		var end string
+		switch {
+		case strings.HasPrefix(s, "<?"):
+			end = "?>"
+		case strings.HasPrefix(s, "<!--"):
+			end = "-->"
+		case strings.HasPrefix(s, "<!"):
+			end = ">"
+		default:
+			return strings.HasPrefix(s, "<svg") && len(s) > 4 && strings.ContainsRune(" \t\r\n>/", rune(s[4]))
+		}
+		i := strings.Index(s, end)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(end):]
+	}
+}
+
+// DetectFile returns the media type of the file at path from its first
+// SniffLen bytes, or "" if it is not a recognized media format.
+func DetectFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	head := make([]byte, SniffLen)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	return Detect(head[:n]), nil
+}
+
+// ParseOverride parses an override of the form .ext=type/subtype, returning
+// the lowercased extension with its dot and the media type.
+func ParseOverride(v string) (string, string, error) {
+	ext, mediaType, ok := strings.Cut(v, "=")
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	if !ok || ext == "" || !strings.Contains(mediaType, "/") {
+		return "", "", fmt.Errorf("expected .ext=type/subtype, got %q", v)
+	}
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext, mediaType, nil
+}
@@ -0,0 +1,71 @@
+package mimetype
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		head string
+		want string
+	}{
+		{"png", "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR", "image/png"},
+		{"jpeg", "\xff\xd8\xff\xe0\x00\x10JFIF", "image/jpeg"},
+		{"gif", "GIF89a\x01\x00", "image/gif"},
+		{"bmp", "BM\x36\x00\x00\x00\x00\x00\x00\x00\x36\x00\x00\x00", "image/bmp"},
+		{"text starting with BM", "BMW owners manual, chapter one", ""},
+		{"webp", "RIFF\x24\x00\x00\x00WEBPVP8 ", "image/webp"},
+		{"wav", "RIFF\x24\x00\x00\x00WAVEfmt ", "audio/wav"},
+		{"avi", "RIFF\x24\x00\x00\x00AVI LIST", "video/x-msvideo"},
+		{"flac", "fLaC\x00\x00\x00\x22", "audio/flac"},
+		{"ogg vorbis", "OggS\x00\x02\x00\x00\x01vorbis", "audio/ogg"},
+		{"ogg theora", "OggS\x00\x02\x00\x00\x80theora", "video/ogg"},
+		{"mp3 with tag", "ID3\x04\x00\x00", "audio/mpeg"},
+		{"mp3 frame", "\xff\xfb\x90\x64\x00", "audio/mpeg"},
+		{"invalid frame sync", "\xff\xff\xff\xff", ""},
+		{"mp4", "\x00\x00\x00\x18ftypisom\x00\x00\x02\x00", "video/mp4"},
+		{"quicktime", "\x00\x00\x00\x14ftypqt  \x00\x00\x00\x00", "video/quicktime"},
+		{"m4a", "\x00\x00\x00\x20ftypM4A \x00\x00\x00\x00", "audio/mp4"},
+		{"heic", "\x00\x00\x00\x18ftypheic\x00\x00\x00\x00", "image/heic"},
+		{"webm", "\x1a\x45\xdf\xa3\x9f\x42\x86\x81\x01\x42\x82\x84webm", "video/webm"},
+		{"matroska", "\x1a\x45\xdf\xa3\x9f\x42\x86\x81\x01\x42\x82\x88matroska", "video/x-matroska"},
+		{"svg", "<svg xmlns=\"http://www.w3.org/2000/svg\"/>", "image/svg+xml"},
+		{"svg with prolog", "\ufeff<?xml version=\"1.0\"?>\n<!-- icon -->\n<!DOCTYPE svg PUBLIC \"-//W3C//DTD SVG 1.1//EN\" \"x\">\n<svg>", "image/svg+xml"},
+		{"other xml", "<?xml version=\"1.0\"?><svgfile/>", ""},
+		{"html", "<html><svg></svg></html>", ""},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		if got := Detect([]byte(tt.head)); got != tt.want {
+			t.Errorf("%s: Detect = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDetectFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "no-extension")
+	if err := os.WriteFile(path, []byte("GIF87a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := DetectFile(path); err != nil || got != "image/gif" {
+		t.Errorf("DetectFile = %q, %v; want image/gif", got, err)
+	}
+	if _, err := DetectFile(path + ".missing"); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestParseOverride(t *testing.T) {
+	ext, mediaType, err := ParseOverride("CR2=Image/X-Canon-CR2")
+	if err != nil || ext != ".cr2" || mediaType != "image/x-canon-cr2" {
+		t.Errorf("ParseOverride = %q, %q, %v", ext, mediaType, err)
+	}
+	for _, bad := range []string{".cr2", "=image/png", ".cr2=png"} {
+		if _, _, err := ParseOverride(bad); err == nil {
+			t.Errorf("ParseOverride(%q): expected an error", bad)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Package network decides which hosts the tools that download and upload
+// files may reach. Access is off unless the operator lists domains, every
+// URL and redirect is checked against them, and each transfer is reported
+// for the audit log.
+package network
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultMaxBytes caps the size of a single transfer unless the operator
+// sets another limit.
+const DefaultMaxBytes = 1 << 30
+
+// maxRedirects is how many redirects a request may follow.
+const maxRedirects = 10
+
+// headerTimeout is how long a server may take to start responding.
+const headerTimeout = 30 * time.Second
+
+// Transfer describes a completed or failed transfer for the audit log.
+type Transfer struct {
+	// Direction is "download" or "upload".
+	Direction string
+	URL       string
+	Path      string
+	Bytes     int64
+	// Status is the HTTP status code, or 0 if no response was received.
+	Status int
+	Err    error
+}
+
+// Access holds the domains tools may reach and the size limit of a
+// transfer.
+type Access struct {
+	domains  []string
+	maxBytes int64
+	audit    func(Transfer)
+}
+
+// New returns access to the given domains. A domain matches itself, and a
+// domain starting with "*." matches its subdomains only. Transfers are
+// limited to maxBytes, and each is passed to audit, which may be nil.
+func New(domains []string, maxBytes int64, audit func(Transfer)) (*Access, error) {
+	if maxBytes <= 0 {
+		return nil, fmt.Errorf("maximum transfer size must be positive: %d", maxBytes)
+	}
+	a := &Access{maxBytes: maxBytes, audit: audit}
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if d == "" {
+			continue
+		}
+		host := strings.TrimPrefix(d, "*.")
+		if host == "" || strings.ContainsAny(host, "/:*@ ") {
+			return nil, fmt.Errorf("invalid domain %q: expected a host name such as example.com or *.example.com", d)
+		}
+		a.domains = append(a.domains, d)
+	}
+	if len(a.domains) == 0 {
+		return nil, errors.New("no domains given")
+	}
+	return a, nil
+}
+
+// Domains returns the allowed domains.
+func (a *Access) Domains() []string {
+	return append([]string(nil), a.domains...)
+}
+
+// MaxBytes returns the size limit of a transfer.
+func (a *Access) MaxBytes() int64 {
+	return a.maxBytes
+}
+
+// Allows reports whether host, without a port, may be reached.
+func (a *Access) Allows(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, d := range a.domains {
+		if suffix, ok := strings.CutPrefix(d, "*"); ok {
+			if strings.HasSuffix(host, suffix) {
+				return true
+			}
+		} else if host == d {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckURL parses rawURL and checks that it is an http or https URL of an
+// allowed host.
+func (a *Access) CheckURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported URL scheme %q: only http and https are allowed", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, errors.New("URL has no host")
+	}
+	if !a.Allows(u.Hostname()) {
+		return nil, fmt.Errorf("host %s is not in the network allowlist", u.Hostname())
+	}
+	return u, nil
+}
+
+// Client returns an HTTP client that only follows redirects to allowed
+// hosts.
+func (a *Access) Client() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = headerTimeout
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			if _, err := a.CheckURL(req.URL.String()); err != nil {
+				return fmt.Errorf("redirect refused: %w", err)
+			}
+			return nil
+		},
+	}
+}
+
+// Audit reports a transfer.
+func (a *Access) Audit(t Transfer) {
+	if a.audit != nil {
+		a.audit(t)
+	}
+}
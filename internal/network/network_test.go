@@ -0,0 +1,76 @@
+package network
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestAllows(t *testing.T) {
+	a, err := New([]string{"example.com", "*.github.com"}, DefaultMaxBytes, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for host, want := range map[string]bool{
+		"example.com":          true,
+		"EXAMPLE.com.":         true,
+		"www.example.com":      false,
+		"api.github.com":       true,
+		"github.com":           false,
+		"evilgithub.com":       false,
+		"example.com.evil.org": false,
+	} {
+		if got := a.Allows(host); got != want {
+			t.Errorf("Allows(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	for _, domains := range [][]string{nil, {""}, {"https://example.com"}, {"*"}, {"example.com/path"}} {
+		if _, err := New(domains, DefaultMaxBytes, nil); err == nil {
+			t.Errorf("New(%q): expected an error", domains)
+		}
+	}
+	if _, err := New([]string{"example.com"}, 0, nil); err == nil {
+		t.Error("expected a zero size limit to be refused")
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	a, _ := New([]string{"example.com"}, DefaultMaxBytes, nil)
+	if _, err := a.CheckURL("https://example.com:8443/file.tar.gz"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for _, u := range []string{"ftp://example.com/file", "file:///etc/passwd", "https://other.org/", "https://example.com@other.org/"} {
+		if _, err := a.CheckURL(u); err == nil {
+			t.Errorf("CheckURL(%q): expected an error", u)
+		}
+	}
+}
+
+func TestClientRedirects(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer target.Close()
+	redirect := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusFound))
+	defer redirect.Close()
+
+	// Both test servers listen on 127.0.0.1, so redirect to the target
+	// under another name that is not allowed.
+	targetURL, _ := url.Parse(target.URL)
+	other := httptest.NewServer(http.RedirectHandler("http://localhost:"+targetURL.Port(), http.StatusFound))
+	defer other.Close()
+
+	a, _ := New([]string{"127.0.0.1"}, DefaultMaxBytes, nil)
+	resp, err := a.Client().Get(redirect.URL)
+	if err != nil {
+		t.Fatalf("expected an allowed redirect to be followed: %v", err)
+	}
+	resp.Body.Close()
+	if _, err := a.Client().Get(other.URL); err == nil {
+		t.Error("expected a redirect to a host that is not allowed to be refused")
+	}
+}
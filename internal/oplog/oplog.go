@@ -0,0 +1,105 @@
+// Package oplog records the mutating tool calls of a session so they can be
+// reviewed or replayed elsewhere. Each call is kept with the arguments the
+// tool acted on, minus the ones that only make sense in the session that
+// issued them, such as confirmation tokens.
+package oplog
+
+import (
+	"sync"
+	"time"
+
+	"github.com/portertech/filesystem-mcp-server/internal/confirm"
+	"github.com/portertech/filesystem-mcp-server/internal/idempotency"
+)
+
+// DefaultMaxOperations is how many operations a log keeps before dropping
+// the oldest.
+const DefaultMaxOperations = 10000
+
+// PlanVersion is the version of the plan format written by ToPlan.
+const PlanVersion = 1
+
+// transientParams are arguments bound to the session that issued a call.
+// Paths have already been resolved against root when a call is recorded.
+var transientParams = []string{confirm.TokenParam, confirm.ApprovalParam, idempotency.KeyParam, "root"}
+
+// Step is one tool call in a plan.
+type Step struct {
+	Tool      string         `json:"tool"`
+	Arguments map[string]any `json:"arguments"`
+}
+
+// Plan is a replayable list of tool calls.
+type Plan struct {
+	Version int    `json:"version"`
+	Steps   []Step `json:"steps"`
+}
+
+// Operation is a recorded tool call.
+type Operation struct {
+	Seq  int       `json:"seq"`
+	Time time.Time `json:"time"`
+	Step
+	// Error is set for calls that failed.
+	Error string `json:"error,omitempty"`
+}
+
+// Log holds the operations of a session, oldest first.
+type Log struct {
+	mu      sync.Mutex
+	ops     []Operation
+	max     int
+	seq     int
+	dropped int
+	now     func() time.Time
+}
+
+// New creates a log keeping at most max operations.
+func New(max int) *Log {
+	return &Log{max: max, now: time.Now}
+}
+
+// Record appends a call to tool with args. failure is the error the call
+// returned, or "" if it succeeded.
+func (l *Log) Record(tool string, args map[string]any, failure string) {
+	kept := make(map[string]any, len(args))
+	for k, v := range args {
+		kept[k] = v
+	}
+	for _, k := range transientParams {
+		delete(kept, k)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.seq++
+	l.ops = append(l.ops, Operation{
+		Seq:   l.seq,
+		Time:  l.now(),
+		Step:  Step{Tool: tool, Arguments: kept},
+		Error: failure,
+	})
+	if over := len(l.ops) - l.max; over > 0 {
+		l.ops = append([]Operation(nil), l.ops[over:]...)
+		l.dropped += over
+	}
+}
+
+// Operations returns the recorded operations and how many older ones were
+// dropped to stay within the limit.
+func (l *Log) Operations() ([]Operation, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Operation(nil), l.ops...), l.dropped
+}
+
+// ToPlan returns the successful operations as a plan.
+func ToPlan(ops []Operation) Plan {
+	plan := Plan{Version: PlanVersion, Steps: []Step{}}
+	for _, op := range ops {
+		if op.Error == "" {
+			plan.Steps = append(plan.Steps, op.Step)
+		}
+	}
+	return plan
+}
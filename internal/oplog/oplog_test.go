@@ -0,0 +1,51 @@
+package oplog
+
+import (
+	"testing"
+
+	"github.com/portertech/filesystem-mcp-server/internal/confirm"
+	"github.com/portertech/filesystem-mcp-server/internal/idempotency"
+)
+
+func TestRecord(t *testing.T) {
+	l := New(DefaultMaxOperations)
+	args := map[string]any{
+		"path":                "/tmp/a",
+		"root":                "app",
+		confirm.TokenParam:    "abc",
+		confirm.ApprovalParam: "def",
+		idempotency.KeyParam:  "k1",
+	}
+	l.Record("delete_file", args, "")
+	l.Record("write_file", map[string]any{"path": "/tmp/b"}, "permission denied")
+
+	ops, dropped := l.Operations()
+	if len(ops) != 2 || dropped != 0 {
+		t.Fatalf("expected 2 operations, got %d (dropped %d)", len(ops), dropped)
+	}
+	if ops[0].Seq != 1 || ops[1].Seq != 2 {
+		t.Errorf("unexpected sequence numbers %d, %d", ops[0].Seq, ops[1].Seq)
+	}
+	if len(ops[0].Arguments) != 1 || ops[0].Arguments["path"] != "/tmp/a" {
+		t.Errorf("expected session-bound arguments to be dropped, got %v", ops[0].Arguments)
+	}
+	if len(args) != 5 {
+		t.Error("Record should not modify the caller's arguments")
+	}
+
+	plan := ToPlan(ops)
+	if plan.Version != PlanVersion || len(plan.Steps) != 1 || plan.Steps[0].Tool != "delete_file" {
+		t.Errorf("expected plan with only the successful call, got %+v", plan)
+	}
+}
+
+func TestRecordDropsOldest(t *testing.T) {
+	l := New(2)
+	for _, tool := range []string{"a", "b", "c"} {
+		l.Record(tool, nil, "")
+	}
+	ops, dropped := l.Operations()
+	if dropped != 1 || len(ops) != 2 || ops[0].Tool != "b" || ops[0].Seq != 2 {
+		t.Errorf("expected oldest operation dropped, got %+v (dropped %d)", ops, dropped)
+	}
+}
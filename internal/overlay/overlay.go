@@ -0,0 +1,408 @@
+// Package overlay implements a copy-on-write shadow of the allowed directories.
+// In overlay mode every mutation lands in a shadow directory while reads fall
+// through to the real tree for anything the overlay has not touched. Pending
+// changes can then be reviewed, committed to the real tree, or discarded.
+package overlay
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	filesDirName   = "files"
+	whiteoutsName  = "whiteouts.json"
+	whiteoutsTmpFx = ".tmp-whiteouts.json"
+)
+
+// Change kinds reported by Changes.
+const (
+	KindAdded    = "added"
+	KindModified = "modified"
+	KindDeleted  = "deleted"
+)
+
+// Change describes a single pending overlay change against the real tree.
+type Change struct {
+	Path        string `json:"path"`
+	Kind        string `json:"kind"`
+	IsDirectory bool   `json:"isDirectory"`
+}
+
+// Overlay records writes in a shadow directory and deletions as whiteouts.
+// Paths passed to its methods are real, already validated absolute paths.
+type Overlay struct {
+	mu        sync.Mutex
+	dir       string
+	filesDir  string
+	whiteouts map[string]struct{}
+}
+
+// New creates an overlay rooted at dir, loading any whiteouts persisted by a
+// previous session so pending changes survive restarts.
+func New(dir string) (*Overlay, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	o := &Overlay{
+		dir:       abs,
+		filesDir:  filepath.Join(abs, filesDirName),
+		whiteouts: make(map[string]struct{}),
+	}
+	if err := os.MkdirAll(o.filesDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create overlay directory: %w", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(abs, whiteoutsName))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read overlay whiteouts: %w", err)
+	}
+	if len(data) > 0 {
+		var paths []string
+		if err := json.Unmarshal(data, &paths); err != nil {
+			return nil, fmt.Errorf("failed to parse overlay whiteouts: %w", err)
+		}
+		for _, p := range paths {
+			o.whiteouts[p] = struct{}{}
+		}
+	}
+
+	return o, nil
+}
+
+// Dir returns the overlay root directory.
+func (o *Overlay) Dir() string {
+	return o.dir
+}
+
+// FilesDir returns the directory that holds shadow copies of files.
+func (o *Overlay) FilesDir() string {
+	return o.filesDir
+}
+
+// ShadowPath returns where the overlay stores the given real path.
+func (o *Overlay) ShadowPath(realPath string) string {
+	vol := filepath.VolumeName(realPath)
+	rest := realPath[len(vol):]
+	return filepath.Join(o.filesDir, strings.ReplaceAll(vol, ":", ""), rest)
+}
+
+// realPath is the inverse of ShadowPath.
+func (o *Overlay) realPath(shadowPath string) (string, error) {
+	rel, err := filepath.Rel(o.filesDir, shadowPath)
+	if err != nil {
+		return "", err
+	}
+	if runtime.GOOS == "windows" {
+		vol, rest, _ := strings.Cut(rel, string(filepath.Separator))
+		return vol + ":" + string(filepath.Separator) + rest, nil
+	}
+	return string(filepath.Separator) + rel, nil
+}
+
+// ReadPath returns the path a read of realPath should be served from: the
+// shadow copy if the overlay has one, otherwise realPath itself. It returns an
+// error satisfying os.IsNotExist if the path was deleted in the overlay.
+func (o *Overlay) ReadPath(realPath string) (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.whitedOutLocked(realPath) {
+		return "", &fs.PathError{Op: "stat", Path: realPath, Err: fs.ErrNotExist}
+	}
+	shadow := o.ShadowPath(realPath)
+	if _, err := os.Lstat(shadow); err == nil {
+		return shadow, nil
+	}
+	return realPath, nil
+}
+
+// PrepareWrite makes realPath writable in the overlay and returns the shadow
+// path to write to. Parent directories are created in the shadow tree and any
+// whiteout covering the path is lifted.
+func (o *Overlay) PrepareWrite(realPath string) (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if err := o.unwhiteoutLocked(realPath); err != nil {
+		return "", err
+	}
+	shadow := o.ShadowPath(realPath)
+	if err := os.MkdirAll(filepath.Dir(shadow), 0700); err != nil {
+		return "", err
+	}
+	return shadow, nil
+}
+
+// MkdirAll creates realPath as a directory in the overlay.
+func (o *Overlay) MkdirAll(realPath string) (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if err := o.unwhiteoutLocked(realPath); err != nil {
+		return "", err
+	}
+	shadow := o.ShadowPath(realPath)
+	if err := os.MkdirAll(shadow, 0755); err != nil {
+		return "", err
+	}
+	return shadow, nil
+}
+
+// Remove deletes realPath in the overlay: any shadow copy is dropped and, if
+// the path exists in the real tree, a whiteout hides it from reads.
+func (o *Overlay) Remove(realPath string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if err := os.RemoveAll(o.ShadowPath(realPath)); err != nil {
+		return err
+	}
+	if _, err := os.Lstat(realPath); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	o.whiteouts[realPath] = struct{}{}
+	return o.saveLocked()
+}
+
+// ReadDir lists realDir as seen through the overlay: real entries minus
+// whiteouts, with shadow entries added or taking precedence. Entries are
+// sorted by name.
+func (o *Overlay) ReadDir(realDir string) ([]os.DirEntry, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.whitedOutLocked(realDir) {
+		return nil, &fs.PathError{Op: "readdir", Path: realDir, Err: fs.ErrNotExist}
+	}
+
+	merged := make(map[string]os.DirEntry)
+	realEntries, realErr := os.ReadDir(realDir)
+	if realErr != nil && !os.IsNotExist(realErr) {
+		return nil, realErr
+	}
+	for _, e := range realEntries {
+		if _, ok := o.whiteouts[filepath.Join(realDir, e.Name())]; ok {
+			continue
+		}
+		merged[e.Name()] = e
+	}
+
+	shadowEntries, shadowErr := os.ReadDir(o.ShadowPath(realDir))
+	if shadowErr != nil && !os.IsNotExist(shadowErr) {
+		return nil, shadowErr
+	}
+	if os.IsNotExist(realErr) && os.IsNotExist(shadowErr) {
+		return nil, realErr
+	}
+	for _, e := range shadowEntries {
+		merged[e.Name()] = e
+	}
+
+	entries := make([]os.DirEntry, 0, len(merged))
+	for _, e := range merged {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, nil
+}
+
+// Changes returns the pending changes, sorted by path. Directories are only
+// reported when they do not exist in the real tree.
+func (o *Overlay) Changes() ([]Change, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var changes []Change
+	err := filepath.WalkDir(o.filesDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == o.filesDir {
+			return nil
+		}
+		realPath, err := o.realPath(p)
+		if err != nil {
+			return err
+		}
+		_, statErr := os.Lstat(realPath)
+		if d.IsDir() {
+			if os.IsNotExist(statErr) {
+				changes = append(changes, Change{Path: realPath, Kind: KindAdded, IsDirectory: true})
+			}
+			return nil
+		}
+		kind := KindModified
+		if os.IsNotExist(statErr) {
+			kind = KindAdded
+		}
+		changes = append(changes, Change{Path: realPath, Kind: kind})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for p := range o.whiteouts {
+		info, err := os.Lstat(p)
+		if err != nil {
+			// Already gone from the real tree, nothing left to delete
+			continue
+		}
+		changes = append(changes, Change{Path: p, Kind: KindDeleted, IsDirectory: info.IsDir()})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes, nil
+}
+
+// Forget drops the overlay state recorded for realPath without touching the
+// real tree. It is used both to discard a change and to clear a change once it
+// has been committed. A shadow directory is only dropped once it is empty, so
+// callers forgetting a subtree should go deepest-first.
+func (o *Overlay) Forget(realPath string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	shadow := o.ShadowPath(realPath)
+	if info, err := os.Lstat(shadow); err == nil && shadow != o.filesDir {
+		if info.IsDir() {
+			entries, err := os.ReadDir(shadow)
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				if err := os.Remove(shadow); err != nil {
+					return err
+				}
+			}
+		} else if err := os.Remove(shadow); err != nil {
+			return err
+		}
+	}
+
+	for p := range o.whiteouts {
+		if p == realPath || isWithin(p, realPath) {
+			delete(o.whiteouts, p)
+		}
+	}
+	return o.saveLocked()
+}
+
+// Reset discards every pending change.
+func (o *Overlay) Reset() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if err := os.RemoveAll(o.filesDir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(o.filesDir, 0700); err != nil {
+		return err
+	}
+	o.whiteouts = make(map[string]struct{})
+	return o.saveLocked()
+}
+
+// whitedOutLocked reports whether realPath or one of its ancestors is whited out.
+func (o *Overlay) whitedOutLocked(realPath string) bool {
+	for p := realPath; ; p = filepath.Dir(p) {
+		if _, ok := o.whiteouts[p]; ok {
+			return true
+		}
+		if filepath.Dir(p) == p {
+			return false
+		}
+	}
+}
+
+// unwhiteoutLocked lifts any whiteout covering realPath. When an ancestor
+// directory was deleted, its other real children stay hidden so that
+// recreating one path does not resurrect the rest of the deleted tree.
+func (o *Overlay) unwhiteoutLocked(realPath string) error {
+	var chain []string
+	for p := realPath; ; p = filepath.Dir(p) {
+		chain = append(chain, p)
+		if filepath.Dir(p) == p {
+			break
+		}
+	}
+
+	changed := false
+	hidden := false
+	// Walk from the root down so hidden ancestors propagate to their children
+	for i := len(chain) - 1; i >= 0; i-- {
+		p := chain[i]
+		if _, ok := o.whiteouts[p]; ok {
+			delete(o.whiteouts, p)
+			changed = true
+			hidden = true
+		}
+		if !hidden || i == 0 {
+			continue
+		}
+		entries, err := os.ReadDir(p)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		next := chain[i-1]
+		for _, e := range entries {
+			child := filepath.Join(p, e.Name())
+			if child != next {
+				o.whiteouts[child] = struct{}{}
+			}
+		}
+	}
+	if hidden {
+		// A deleted directory that is recreated starts out empty. ReadDir fails
+		// harmlessly when the target is a file or does not exist.
+		if entries, err := os.ReadDir(realPath); err == nil {
+			for _, e := range entries {
+				o.whiteouts[filepath.Join(realPath, e.Name())] = struct{}{}
+			}
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return o.saveLocked()
+}
+
+// saveLocked persists the whiteout set atomically.
+func (o *Overlay) saveLocked() error {
+	paths := make([]string, 0, len(o.whiteouts))
+	for p := range o.whiteouts {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	data, err := json.MarshalIndent(paths, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(o.dir, whiteoutsTmpFx)
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(o.dir, whiteoutsName))
+}
+
+// isWithin reports whether path is strictly below dir.
+func isWithin(path, dir string) bool {
+	return strings.HasPrefix(path, dir+string(filepath.Separator))
+}
@@ -0,0 +1,212 @@
+package overlay
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func setupOverlay(t *testing.T) (*Overlay, string) {
+	t.Helper()
+	root := t.TempDir()
+	ov, err := New(filepath.Join(t.TempDir(), "overlay"))
+	if err != nil {
+		t.Fatalf("failed to create overlay: %v", err)
+	}
+	return ov, root
+}
+
+func TestReadPathFallsThrough(t *testing.T) {
+	ov, root := setupOverlay(t)
+	realFile := filepath.Join(root, "file.txt")
+	if err := os.WriteFile(realFile, []byte("real"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ov.ReadPath(realFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != realFile {
+		t.Errorf("expected real path %q, got %q", realFile, got)
+	}
+
+	shadow, err := ov.PrepareWrite(realFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := os.WriteFile(shadow, []byte("shadow"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err = ov.ReadPath(realFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != shadow {
+		t.Errorf("expected shadow path %q, got %q", shadow, got)
+	}
+
+	data, err := os.ReadFile(realFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "real" {
+		t.Errorf("real file should be untouched, got %q", string(data))
+	}
+}
+
+func TestRemoveHidesRealPath(t *testing.T) {
+	ov, root := setupOverlay(t)
+	dir := filepath.Join(root, "dir")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	child := filepath.Join(dir, "child.txt")
+	if err := os.WriteFile(child, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ov.Remove(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := ov.ReadPath(child); !os.IsNotExist(err) {
+		t.Errorf("expected not-exist error for child of removed dir, got %v", err)
+	}
+	if _, err := os.Stat(child); err != nil {
+		t.Errorf("real child should still exist: %v", err)
+	}
+
+	changes, err := ov.Changes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Kind != KindDeleted || !changes[0].IsDirectory {
+		t.Errorf("expected single deleted directory change, got %+v", changes)
+	}
+}
+
+func TestRecreateInsideRemovedDirectory(t *testing.T) {
+	ov, root := setupOverlay(t)
+	dir := filepath.Join(root, "dir")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	old := filepath.Join(dir, "old.txt")
+	if err := os.WriteFile(old, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ov.Remove(dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ov.PrepareWrite(filepath.Join(dir, "new.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ov.ReadPath(old); !os.IsNotExist(err) {
+		t.Errorf("recreating a path should not resurrect deleted siblings, got %v", err)
+	}
+	if _, err := ov.ReadPath(dir); err != nil {
+		t.Errorf("parent directory should be visible again: %v", err)
+	}
+}
+
+func TestReadDirMerges(t *testing.T) {
+	ov, root := setupOverlay(t)
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := ov.Remove(filepath.Join(root, "a.txt")); err != nil {
+		t.Fatal(err)
+	}
+	shadow, err := ov.PrepareWrite(filepath.Join(root, "c.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(shadow, []byte("c"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := ov.ReadDir(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if len(names) != 2 || names[0] != "b.txt" || names[1] != "c.txt" {
+		t.Errorf("expected [b.txt c.txt], got %v", names)
+	}
+}
+
+func TestWhiteoutsPersist(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(t.TempDir(), "overlay")
+	realFile := filepath.Join(root, "file.txt")
+	if err := os.WriteFile(realFile, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ov, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ov.Remove(realFile); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reopened.ReadPath(realFile); !os.IsNotExist(err) {
+		t.Errorf("expected whiteout to survive reopen, got %v", err)
+	}
+}
+
+func TestForgetAndReset(t *testing.T) {
+	ov, root := setupOverlay(t)
+	realFile := filepath.Join(root, "file.txt")
+	shadow, err := ov.PrepareWrite(realFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(shadow, []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := ov.Changes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Kind != KindAdded || changes[0].Path != realFile {
+		t.Fatalf("expected added change for %s, got %+v", realFile, changes)
+	}
+
+	if err := ov.Forget(realFile); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(shadow); !os.IsNotExist(err) {
+		t.Errorf("expected shadow copy to be removed, got %v", err)
+	}
+
+	if err := ov.Remove(root); err != nil {
+		t.Fatal(err)
+	}
+	if err := ov.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	changes, err = ov.Changes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("expected no changes after reset, got %+v", changes)
+	}
+}
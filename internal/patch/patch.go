@@ -0,0 +1,407 @@
+// Package patch parses unified diffs, including multi-file git diffs, and
+// applies their hunks to text. Like patch(1), it finds hunks whose lines
+// have moved (an offset) and, with fuzz, hunks whose outermost context
+// lines no longer match.
+package patch
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DevNull is the name diffs give the missing side of a created or deleted
+// file.
+const DevNull = "/dev/null"
+
+// ErrBinary is returned for diffs of binary files, which carry no hunks.
+var ErrBinary = errors.New("binary diffs are not supported")
+
+// Line is one line of a hunk.
+type Line struct {
+	// Kind is ' ' for context, '-' for a removed line, or '+' for an added
+	// line.
+	Kind byte
+	// Text is the line without its line ending.
+	Text string
+	// NoNewline is set for a line marked "\ No newline at end of file".
+	NoNewline bool
+}
+
+// Hunk is one @@ section of a file diff.
+type Hunk struct {
+	OldStart, OldLines int
+	NewStart, NewLines int
+	Lines              []Line
+}
+
+// File is the change a diff makes to one file. Names are as they appear in
+// the diff, with any a/ and b/ prefixes; see Strip.
+type File struct {
+	// OldName is DevNull for a created file.
+	OldName string
+	// NewName is DevNull for a deleted file.
+	NewName string
+	Hunks   []Hunk
+}
+
+// Created reports whether the diff creates the file.
+func (f File) Created() bool { return f.OldName == DevNull }
+
+// Deleted reports whether the diff deletes the file.
+func (f File) Deleted() bool { return f.NewName == DevNull }
+
+// Parse reads the file diffs in a unified diff. Text around them, such as
+// a commit message or git's extended headers, is skipped.
+func Parse(diff string) ([]File, error) {
+	lines := strings.Split(diff, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSuffix(l, "\r")
+	}
+
+	var files []File
+	// gitHeader is the diff --git line of a section that has not had its
+	// --- and +++ lines yet.
+	gitHeader := ""
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			if gitHeader != "" {
+				return nil, noContentError(gitHeader)
+			}
+			gitHeader = line
+		case strings.HasPrefix(line, "Binary files ") || line == "GIT binary patch":
+			return nil, ErrBinary
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			f := File{OldName: headerName(line[4:]), NewName: headerName(lines[i+1][4:])}
+			i += 2
+			for i < len(lines) && strings.HasPrefix(lines[i], "@@ ") {
+				h, next, err := parseHunk(lines, i)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", f.displayName(), err)
+				}
+				f.Hunks = append(f.Hunks, h)
+				i = next
+			}
+			if len(f.Hunks) == 0 {
+				return nil, fmt.Errorf("%s: no hunks", f.displayName())
+			}
+			files = append(files, f)
+			gitHeader = ""
+			i-- // The loop moves past the last line read
+		}
+	}
+	if gitHeader != "" {
+		return nil, noContentError(gitHeader)
+	}
+	return files, nil
+}
+
+// noContentError reports a git diff section without hunks, such as a pure
+// rename or mode change.
+func noContentError(header string) error {
+	return fmt.Errorf("%q has no content changes; renames without edits, mode changes, and empty files are not supported", header)
+}
+
+func (f File) displayName() string {
+	if f.Deleted() {
+		return f.OldName
+	}
+	return f.NewName
+}
+
+// headerName extracts the file name from a --- or +++ line, dropping a
+// trailing timestamp and C-style quotes.
+func headerName(s string) string {
+	if i := strings.IndexByte(s, '\t'); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, `"`) {
+		if unquoted, err := strconv.Unquote(s); err == nil {
+			return unquoted
+		}
+	}
+	return s
+}
+
+// parseHunk parses the hunk whose header is lines[start], returning it and
+// the index of the line after it.
+func parseHunk(lines []string, start int) (Hunk, int, error) {
+	var h Hunk
+	header := lines[start]
+	end := strings.Index(header[3:], " @@")
+	if end < 0 {
+		return h, 0, fmt.Errorf("malformed hunk header %q", header)
+	}
+	ranges := strings.Fields(header[3 : 3+end])
+	if len(ranges) != 2 || !strings.HasPrefix(ranges[0], "-") || !strings.HasPrefix(ranges[1], "+") {
+		return h, 0, fmt.Errorf("malformed hunk header %q", header)
+	}
+	var err1, err2 error
+	h.OldStart, h.OldLines, err1 = parseRange(ranges[0][1:])
+	h.NewStart, h.NewLines, err2 = parseRange(ranges[1][1:])
+	if err1 != nil || err2 != nil {
+		return h, 0, fmt.Errorf("malformed hunk header %q", header)
+	}
+
+	oldLeft, newLeft := h.OldLines, h.NewLines
+	i := start + 1
+	for ; i < len(lines) && (oldLeft > 0 || newLeft > 0); i++ {
+		line := lines[i]
+		if strings.HasPrefix(line, `\`) {
+			if len(h.Lines) > 0 {
+				h.Lines[len(h.Lines)-1].NoNewline = true
+			}
+			continue
+		}
+		kind := byte(' ')
+		text := ""
+		// Some tools drop the space of empty context lines
+		if line != "" {
+			kind, text = line[0], line[1:]
+		}
+		switch kind {
+		case ' ':
+			oldLeft--
+			newLeft--
+		case '-':
+			oldLeft--
+		case '+':
+			newLeft--
+		default:
+			return h, 0, fmt.Errorf("hunk %q ends early at line %q", header, line)
+		}
+		if oldLeft < 0 || newLeft < 0 {
+			return h, 0, fmt.Errorf("hunk %q has more lines than its header says", header)
+		}
+		h.Lines = append(h.Lines, Line{Kind: kind, Text: text})
+	}
+	if oldLeft > 0 || newLeft > 0 {
+		return h, 0, fmt.Errorf("hunk %q is truncated", header)
+	}
+	// The marker can follow the last line
+	if i < len(lines) && strings.HasPrefix(lines[i], `\`) {
+		if len(h.Lines) > 0 {
+			h.Lines[len(h.Lines)-1].NoNewline = true
+		}
+		i++
+	}
+	return h, i, nil
+}
+
+// parseRange parses start[,count] from a hunk header. The count defaults
+// to 1.
+func parseRange(s string) (int, int, error) {
+	startText, countText, hasCount := strings.Cut(s, ",")
+	start, err := strconv.Atoi(startText)
+	if err != nil || start < 0 {
+		return 0, 0, errors.New("bad range")
+	}
+	count := 1
+	if hasCount {
+		if count, err = strconv.Atoi(countText); err != nil || count < 0 {
+			return 0, 0, errors.New("bad range")
+		}
+	}
+	return start, count, nil
+}
+
+// Strip removes n leading path components from name, as patch -p does.
+// DevNull is returned unchanged.
+func Strip(name string, n int) string {
+	if name == DevNull {
+		return name
+	}
+	for ; n > 0; n-- {
+		i := strings.IndexByte(name, '/')
+		if i < 0 {
+			return name
+		}
+		name = name[i+1:]
+	}
+	return name
+}
+
+// GitPrefixed reports whether every name in files carries git's a/ and b/
+// prefixes, which are then stripped with Strip(name, 1).
+func GitPrefixed(files []File) bool {
+	for _, f := range files {
+		if !f.Created() && !strings.HasPrefix(f.OldName, "a/") {
+			return false
+		}
+		if !f.Deleted() && !strings.HasPrefix(f.NewName, "b/") {
+			return false
+		}
+	}
+	return len(files) > 0
+}
+
+// Placement records where a hunk was applied.
+type Placement struct {
+	// Line is the 1-based line of the original text the hunk starts at.
+	Line int
+	// Offset is how many lines from the position in its header the hunk
+	// was found.
+	Offset int
+	// Fuzz is the number of context lines ignored at each end.
+	Fuzz int
+}
+
+// HunkError reports a hunk that could not be applied.
+type HunkError struct {
+	// Hunk is the 1-based number of the hunk.
+	Hunk   int
+	Header string
+}
+
+func (e *HunkError) Error() string {
+	return fmt.Sprintf("hunk %d (%s) does not match the file", e.Hunk, e.Header)
+}
+
+// Apply applies hunks to content in order. A hunk is placed where its
+// header says if its lines are there, and otherwise at the nearest line
+// they match. With maxFuzz above zero, up to that many context lines at
+// each end of a hunk may be ignored to place it. Lines are compared
+// without their line endings, and added lines take the dominant line
+// ending of content.
+func Apply(content string, hunks []Hunk, maxFuzz int) (string, []Placement, error) {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	eol := "\n"
+	crlf := 0
+	for _, l := range lines {
+		if strings.HasSuffix(l, "\r\n") {
+			crlf++
+		}
+	}
+	if crlf > len(lines)/2 {
+		eol = "\r\n"
+	}
+
+	var out []string
+	emit := func(l string) {
+		// An added line after a last line without a newline needs one
+		if n := len(out); n > 0 && !strings.HasSuffix(out[n-1], "\n") {
+			out[n-1] += eol
+		}
+		out = append(out, l)
+	}
+
+	placements := make([]Placement, 0, len(hunks))
+	pos, delta := 0, 0
+	for n, h := range hunks {
+		p, lead, trail, fuzz, ok := place(lines, pos, h, delta, maxFuzz)
+		if !ok {
+			return "", nil, &HunkError{Hunk: n + 1, Header: fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.OldStart, h.OldLines, h.NewStart, h.NewLines)}
+		}
+		offset := p - hunkStart(h, lead)
+		placements = append(placements, Placement{Line: p + 1, Offset: offset, Fuzz: fuzz})
+		// Later hunks are first looked for where this one moved them
+		delta = offset
+
+		for _, l := range lines[pos:p] {
+			emit(l)
+		}
+		k := p
+		for _, l := range h.Lines[lead : len(h.Lines)-trail] {
+			switch l.Kind {
+			case ' ':
+				emit(lines[k])
+				k++
+			case '-':
+				k++
+			case '+':
+				if l.NoNewline {
+					emit(l.Text)
+				} else {
+					emit(l.Text + eol)
+				}
+			}
+		}
+		pos = k
+	}
+	for _, l := range lines[pos:] {
+		emit(l)
+	}
+	return strings.Join(out, ""), placements, nil
+}
+
+// hunkStart is the 0-based line the header of h places its old lines at,
+// after skipping lead context lines.
+func hunkStart(h Hunk, lead int) int {
+	start := h.OldStart - 1
+	if h.OldLines == 0 {
+		// An empty old range names the line after which to insert
+		start = h.OldStart
+	}
+	return start + lead
+}
+
+// place finds where h applies in lines at or after pos, trying the
+// expected position and then ever further lines, with fuzz 0 and then
+// more. It returns the line the matched old lines start at, the context
+// lines ignored at the start and end, and the fuzz used.
+func place(lines []string, pos int, h Hunk, delta, maxFuzz int) (int, int, int, int, bool) {
+	leadContext, trailContext := 0, 0
+	for _, l := range h.Lines {
+		if l.Kind != ' ' {
+			break
+		}
+		leadContext++
+	}
+	for i := len(h.Lines) - 1; i >= 0 && h.Lines[i].Kind == ' '; i-- {
+		trailContext++
+	}
+
+	prevLead, prevTrail := -1, -1
+	for fuzz := 0; fuzz <= maxFuzz; fuzz++ {
+		lead, trail := min(fuzz, leadContext), min(fuzz, trailContext)
+		if lead+trail >= len(h.Lines) {
+			break
+		}
+		if lead == prevLead && trail == prevTrail {
+			continue
+		}
+		prevLead, prevTrail = lead, trail
+
+		var old []string
+		for _, l := range h.Lines[lead : len(h.Lines)-trail] {
+			if l.Kind != '+' {
+				old = append(old, l.Text)
+			}
+		}
+		expected := hunkStart(h, lead) + delta
+		last := len(lines) - len(old)
+		if len(old) == 0 {
+			// Without context, only the stated position will do
+			if expected >= pos && expected <= len(lines) {
+				return expected, lead, trail, fuzz, true
+			}
+			continue
+		}
+		for d := 0; expected-d >= pos || expected+d <= last; d++ {
+			if p := expected + d; p >= pos && p <= last && matches(lines[p:], old) {
+				return p, lead, trail, fuzz, true
+			}
+			if p := expected - d; d > 0 && p >= pos && p <= last && matches(lines[p:], old) {
+				return p, lead, trail, fuzz, true
+			}
+		}
+	}
+	return 0, 0, 0, 0, false
+}
+
+// matches reports whether lines starts with old, ignoring line endings.
+func matches(lines, old []string) bool {
+	for i, o := range old {
+		if strings.TrimRight(lines[i], "\r\n") != strings.TrimRight(o, "\r") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,178 @@
+package patch
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	diff := `Fix the greeting
+
+diff --git a/hello.go b/hello.go
+index 83db48f..bf269f4 100644
+--- a/hello.go
++++ b/hello.go
+@@ -1,3 +1,3 @@
+ package main
+-// hello
++// hello, world
+
+@@ -10 +10,2 @@ func main() {
+ 	run()
++	exit()
+--- /dev/null	2024-01-01 00:00:00.000000000 +0000
++++ "b/new file.txt"	2024-01-01 00:00:00.000000000 +0000
+@@ -0,0 +1 @@
++no newline
+\ No newline at end of file
+`
+	files, err := Parse(diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files, got %+v", files)
+	}
+	if f := files[0]; f.OldName != "a/hello.go" || f.NewName != "b/hello.go" || len(f.Hunks) != 2 {
+		t.Errorf("unexpected first file %+v", f)
+	}
+	// The empty line is context with its space dropped
+	if h := files[0].Hunks[0]; len(h.Lines) != 4 || h.Lines[3].Kind != ' ' || h.Lines[3].Text != "" {
+		t.Errorf("unexpected first hunk %+v", h)
+	}
+	if h := files[0].Hunks[1]; h.OldStart != 10 || h.OldLines != 1 || h.NewLines != 2 {
+		t.Errorf("unexpected second hunk %+v", h)
+	}
+	f := files[1]
+	if !f.Created() || f.NewName != "b/new file.txt" || !f.Hunks[0].Lines[0].NoNewline {
+		t.Errorf("unexpected created file %+v", f)
+	}
+	if !GitPrefixed(files) || Strip(f.NewName, 1) != "new file.txt" || Strip(DevNull, 1) != DevNull {
+		t.Error("expected git prefixes to be recognized and stripped")
+	}
+
+	for name, diff := range map[string]string{
+		"binary":    "diff --git a/x.png b/x.png\nBinary files a/x.png and b/x.png differ\n",
+		"rename":    "diff --git a/x b/y\nsimilarity index 100%\nrename from x\nrename to y\n",
+		"truncated": "--- a/x\n+++ b/x\n@@ -1,3 +1,3 @@\n a\n-b\n",
+		"no hunks":  "--- a/x\n+++ b/x\n",
+		"bad range": "--- a/x\n+++ b/x\n@@ -a +1 @@\n+x\n",
+	} {
+		if _, err := Parse(diff); err == nil {
+			t.Errorf("%s: expected an error", name)
+		} else if name == "binary" && !errors.Is(err, ErrBinary) {
+			t.Errorf("binary: got %v", err)
+		}
+	}
+}
+
+func mustParse(t *testing.T, diff string) []Hunk {
+	t.Helper()
+	files, err := Parse(diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files[0].Hunks
+}
+
+func TestApply(t *testing.T) {
+	original := "one\ntwo\nthree\nfour\nfive\nsix\nseven\n"
+	hunks := mustParse(t, `--- a/f
++++ b/f
+@@ -2,3 +2,3 @@
+ two
+-three
++THREE
+ four
+@@ -6,2 +6,3 @@
+ six
+ seven
++eight
+`)
+
+	got, placements, err := Apply(original, hunks, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "one\ntwo\nTHREE\nfour\nfive\nsix\nseven\neight\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if placements[0] != (Placement{Line: 2}) || placements[1] != (Placement{Line: 6}) {
+		t.Errorf("unexpected placements %+v", placements)
+	}
+
+	// Lines added above the hunks shift them
+	got, placements, err = Apply("zero\nzero\n"+original, hunks, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(got, "THREE\nfour\nfive\nsix\nseven\neight\n") || placements[0].Offset != 2 || placements[1].Offset != 2 {
+		t.Errorf("got %q with placements %+v", got, placements)
+	}
+
+	// Changed outer context needs fuzz
+	changed := strings.Replace(original, "two", "TWO", 1)
+	if _, _, err := Apply(changed, hunks, 0); err == nil {
+		t.Error("expected changed context to fail without fuzz")
+	} else {
+		var hunkErr *HunkError
+		if !errors.As(err, &hunkErr) || hunkErr.Hunk != 1 {
+			t.Errorf("unexpected error %v", err)
+		}
+	}
+	got, placements, err = Apply(changed, hunks, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "one\nTWO\nTHREE\nfour\nfive\nsix\nseven\neight\n"; got != want || placements[0].Fuzz != 1 || placements[0].Line != 3 {
+		t.Errorf("got %q with placements %+v", got, placements)
+	}
+
+	// Removed lines must still be there, fuzz or not
+	if _, _, err := Apply(strings.Replace(original, "three", "3", 1), hunks, 2); err == nil {
+		t.Error("expected a changed removed line to fail")
+	}
+}
+
+func TestApplyLineEndings(t *testing.T) {
+	hunks := mustParse(t, "--- a/f\n+++ b/f\n@@ -1,2 +1,3 @@\n a\n b\n+c\n")
+
+	// Added lines follow the file's CRLF endings
+	got, _, err := Apply("a\r\nb\r\n", hunks, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "a\r\nb\r\nc\r\n" {
+		t.Errorf("got %q", got)
+	}
+
+	// A last line without a newline gets one before an added line
+	got, _, err = Apply("a\nb", hunks, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "a\nb\nc\n" {
+		t.Errorf("got %q", got)
+	}
+
+	hunks = mustParse(t, "--- a/f\n+++ b/f\n@@ -1 +1 @@\n-a\n+b\n\\ No newline at end of file\n")
+	got, _, err = Apply("a\n", hunks, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "b" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestApplyCreate(t *testing.T) {
+	hunks := mustParse(t, "--- /dev/null\n+++ b/f\n@@ -0,0 +1,2 @@\n+x\n+y\n")
+	got, _, err := Apply("", hunks, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "x\ny\n" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,132 @@
+package pathutil
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxFilenameLength is the longest file name, in bytes, accepted by common
+// filesystems.
+const MaxFilenameLength = 255
+
+// invalidFilenameChars are characters not allowed in Windows file names.
+// Slashes are also path separators everywhere else.
+const invalidFilenameChars = `<>:"/\|?*`
+
+// reservedFilenames are device names Windows reserves regardless of
+// extension.
+var reservedFilenames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// ValidateFilename checks that name is usable as a single file or directory
+// name on Linux, macOS, and Windows.
+func ValidateFilename(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("file name is empty")
+	case name == "." || name == "..":
+		return fmt.Errorf("file name %q is reserved", name)
+	case len(name) > MaxFilenameLength:
+		return fmt.Errorf("file name is %d bytes, longer than %d", len(name), MaxFilenameLength)
+	case !utf8.ValidString(name):
+		return fmt.Errorf("file name is not valid UTF-8")
+	}
+	for _, r := range name {
+		if isInvalidFilenameRune(r) {
+			return fmt.Errorf("file name contains invalid character %q", r)
+		}
+	}
+	if strings.HasSuffix(name, ".") || strings.HasSuffix(name, " ") {
+		return fmt.Errorf("file name ends with a dot or space")
+	}
+	if isReservedFilename(name) {
+		return fmt.Errorf("file name %q is reserved on Windows", name)
+	}
+	return nil
+}
+
+// SanitizeFilename maps name to a portable file name: invalid characters are
+// replaced with replacement, leading and trailing spaces and trailing dots
+// are dropped, Windows device names get a trailing underscore, and the
+// result is truncated to maxLength bytes, keeping the extension. A
+// maxLength of zero or above MaxFilenameLength means MaxFilenameLength. An
+// empty result becomes "untitled".
+func SanitizeFilename(name, replacement string, maxLength int) string {
+	if maxLength <= 0 || maxLength > MaxFilenameLength {
+		maxLength = MaxFilenameLength
+	}
+
+	var b strings.Builder
+	for _, r := range strings.ToValidUTF8(name, replacement) {
+		if isInvalidFilenameRune(r) {
+			b.WriteString(replacement)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	result := trimFilename(b.String())
+
+	if len(result) > maxLength {
+		base, ext := splitExt(result)
+		if len(ext) > maxLength/2 {
+			base, ext = result, ""
+		}
+		result = trimFilename(truncateUTF8(base, maxLength-len(ext)) + ext)
+	}
+
+	if isReservedFilename(result) {
+		i := strings.Index(result, ".")
+		if i < 0 {
+			i = len(result)
+		}
+		result = result[:i] + "_" + result[i:]
+	}
+
+	if result == "" || result == "." || result == ".." {
+		return "untitled"
+	}
+	return result
+}
+
+func isInvalidFilenameRune(r rune) bool {
+	return r < 0x20 || r == 0x7f || strings.ContainsRune(invalidFilenameChars, r)
+}
+
+// isReservedFilename reports whether the part of name before the first dot
+// is a Windows device name, such as "con" or "LPT1.txt".
+func isReservedFilename(name string) bool {
+	stem, _, _ := strings.Cut(name, ".")
+	return reservedFilenames[strings.ToUpper(strings.TrimRight(stem, " "))]
+}
+
+// trimFilename drops leading and trailing spaces and trailing dots.
+func trimFilename(name string) string {
+	return strings.TrimLeft(strings.TrimRight(name, ". "), " ")
+}
+
+// splitExt splits name before its last dot. A leading dot, as in ".env",
+// does not start an extension.
+func splitExt(name string) (base, ext string) {
+	i := strings.LastIndex(name, ".")
+	if i <= 0 {
+		return name, ""
+	}
+	return name[:i], name[i:]
+}
+
+// truncateUTF8 shortens s to at most n bytes without splitting a rune.
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
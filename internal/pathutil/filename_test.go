@@ -0,0 +1,79 @@
+package pathutil
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateFilename(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		valid bool
+	}{
+		{"plain", "notes.txt", true},
+		{"dotfile", ".gitignore", true},
+		{"unicode", "résumé.md", true},
+		{"empty", "", false},
+		{"dot", ".", false},
+		{"dotdot", "..", false},
+		{"slash", "a/b", false},
+		{"colon", "a:b", false},
+		{"question mark", "why?.txt", false},
+		{"control character", "a\tb", false},
+		{"trailing dot", "name.", false},
+		{"trailing space", "name ", false},
+		{"reserved", "CON", false},
+		{"reserved lowercase with extension", "nul.txt", false},
+		{"reserved prefix only", "CONSOLE.txt", true},
+		{"too long", strings.Repeat("a", 256), false},
+		{"invalid utf-8", "bad\xff", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateFilename(tt.input)
+			if (err == nil) != tt.valid {
+				t.Errorf("ValidateFilename(%q) = %v, want valid=%v", tt.input, err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		replacement string
+		maxLength   int
+		expected    string
+	}{
+		{"already safe", "notes.txt", "_", 0, "notes.txt"},
+		{"invalid characters", `Q1/Q2: "Plan"?`, "_", 0, "Q1_Q2_ _Plan__"},
+		{"custom replacement", "a:b", "-", 0, "a-b"},
+		{"empty replacement", "a<b>c", "", 0, "abc"},
+		{"control characters", "a\nb", "_", 0, "a_b"},
+		{"trailing dots and spaces", "  draft. . ", "_", 0, "draft"},
+		{"reserved", "con", "_", 0, "con_"},
+		{"reserved with extension", "LPT1.log", "_", 0, "LPT1_.log"},
+		{"reserved with two extensions", "nul.tar.gz", "_", 0, "nul_.tar.gz"},
+		{"truncated keeping extension", "abcdefghij.txt", "_", 10, "abcdef.txt"},
+		{"truncated on rune boundary", "ééééé.md", "_", 8, "éé.md"},
+		{"empty", "", "_", 0, "untitled"},
+		{"only dots", "...", "_", 0, "untitled"},
+		{"invalid utf-8", "bad\xffname", "_", 0, "bad_name"},
+		{"long", strings.Repeat("a", 300), "_", 0, strings.Repeat("a", MaxFilenameLength)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := SanitizeFilename(tt.input, tt.replacement, tt.maxLength)
+			if result != tt.expected {
+				t.Errorf("SanitizeFilename(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+			if err := ValidateFilename(result); err != nil {
+				t.Errorf("sanitized name %q is not valid: %v", result, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package pathutil
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"syscall"
+)
+
+// ErrPathTooLong is returned when a path exceeds the platform's limits.
+var ErrPathTooLong = errors.New("path too long")
+
+// WindowsMaxPath is the classic Windows path limit (MAX_PATH). Go adds the
+// \\?\ prefix itself so the server can exceed it, but Explorer, many
+// Windows tools, and git without core.longpaths cannot.
+const WindowsMaxPath = 260
+
+// errorFilenameExcedRange is the Windows ERROR_FILENAME_EXCED_RANGE code.
+const errorFilenameExcedRange = syscall.Errno(206)
+
+// MaxPathLength returns the longest absolute path, in bytes, the platform
+// accepts.
+func MaxPathLength() int {
+	switch runtime.GOOS {
+	case "windows":
+		// With the \\?\ prefix, which the os package adds to long paths
+		return 32767
+	case "darwin":
+		return 1024
+	default:
+		return 4096
+	}
+}
+
+// CheckPathLength returns an error wrapping ErrPathTooLong if path, or any
+// component of it, is longer than the platform allows.
+func CheckPathLength(path string) error {
+	if max := MaxPathLength(); len(path) > max {
+		return fmt.Errorf("%w: path is %d bytes, over the %d-byte limit", ErrPathTooLong, len(path), max)
+	}
+	for _, part := range strings.FieldsFunc(path, isSeparator) {
+		if len(part) > MaxFilenameLength {
+			return fmt.Errorf("%w: component %q is %d bytes, over the %d-byte limit", ErrPathTooLong, truncateUTF8(part, 32)+"...", len(part), MaxFilenameLength)
+		}
+	}
+	return nil
+}
+
+// IsPathTooLong reports whether err was caused by a path or file name that
+// is too long for the operating system.
+func IsPathTooLong(err error) bool {
+	if errors.Is(err, ErrPathTooLong) || errors.Is(err, syscall.ENAMETOOLONG) {
+		return true
+	}
+	return runtime.GOOS == "windows" && errors.Is(err, errorFilenameExcedRange)
+}
+
+// StripLongPathPrefix removes a Windows \\?\ long-path prefix, turning
+// \\?\C:\dir into C:\dir and \\?\UNC\server\share into \\server\share.
+// Paths are kept unprefixed internally so they compare equal to the allowed
+// directories; the os package adds the prefix back when a path needs it.
+func StripLongPathPrefix(path string) string {
+	for _, prefix := range []string{`\\?\`, `//?/`} {
+		if !strings.HasPrefix(path, prefix) {
+			continue
+		}
+		rest := path[len(prefix):]
+		if len(rest) >= 4 && strings.EqualFold(rest[:3], "UNC") && (rest[3] == '\\' || rest[3] == '/') {
+			return `\\` + rest[4:]
+		}
+		return rest
+	}
+	return path
+}
+
+func isSeparator(r rune) bool {
+	return r == '/' || r == '\\' && runtime.GOOS == "windows"
+}
@@ -0,0 +1,68 @@
+package pathutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestCheckPathLength(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		tooLong bool
+	}{
+		{"short", "/tmp/project/main.go", false},
+		{"long name", "/tmp/" + strings.Repeat("a", MaxFilenameLength+1), true},
+		{"name at limit", "/tmp/" + strings.Repeat("a", MaxFilenameLength), false},
+		{"long path", "/tmp" + strings.Repeat("/abcdefgh", MaxPathLength()/9+1), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckPathLength(tt.path)
+			if (err != nil) != tt.tooLong {
+				t.Fatalf("CheckPathLength() = %v, want too long=%v", err, tt.tooLong)
+			}
+			if err != nil && !IsPathTooLong(err) {
+				t.Errorf("IsPathTooLong(%v) = false", err)
+			}
+		})
+	}
+}
+
+func TestIsPathTooLong(t *testing.T) {
+	osErr := &os.PathError{Op: "open", Path: "/x", Err: syscall.ENAMETOOLONG}
+	if !IsPathTooLong(fmt.Errorf("failed to write file: %w", osErr)) {
+		t.Error("expected wrapped ENAMETOOLONG to be detected")
+	}
+	if IsPathTooLong(errors.New("permission denied")) || IsPathTooLong(nil) {
+		t.Error("unexpected match")
+	}
+}
+
+func TestStripLongPathPrefix(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`\\?\C:\Users\dev\project`, `C:\Users\dev\project`},
+		{`\\?\UNC\server\share\dir`, `\\server\share\dir`},
+		{`\\?\unc\server\share`, `\\server\share`},
+		{`//?/C:/dir`, `C:/dir`},
+		{`C:\dir`, `C:\dir`},
+		{`\\server\share`, `\\server\share`},
+		{`/home/dev`, `/home/dev`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if result := StripLongPathPrefix(tt.input); result != tt.expected {
+				t.Errorf("StripLongPathPrefix(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
@@ -48,6 +48,8 @@ func ExpandHome(path string) string {
 
 // normalizeWindowsPath handles Windows-specific path formats.
 func normalizeWindowsPath(path string) string {
+	path = StripLongPathPrefix(path)
+
 	// Handle WSL /mnt/ paths
 	if strings.HasPrefix(path, "/mnt/") && len(path) > 5 {
 		driveLetter := path[5]
@@ -0,0 +1,115 @@
+// Package perms parses permission modes, sets the process umask, and
+// detects user namespace UID mappings, which decide who owns the files the
+// server creates as seen from outside a container.
+package perms
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ParseMode parses an octal permission mode such as 0644 or 755.
+func ParseMode(s string) (os.FileMode, error) {
+	n, err := strconv.ParseUint(strings.TrimPrefix(s, "0o"), 8, 32)
+	if err != nil || n > 0777 {
+		return 0, fmt.Errorf("invalid mode %q: must be octal permission bits between 0 and 0777", s)
+	}
+	return os.FileMode(n), nil
+}
+
+// Mapping describes how the user the server runs as maps to a user of the
+// host, who owns the files the server creates as the host sees them.
+type Mapping struct {
+	// UID is the effective user ID of the server.
+	UID int `json:"uid"`
+	// HostUID is the host user ID that UID maps to, or -1 if it is not
+	// mapped.
+	HostUID int `json:"hostUid"`
+	// OwnerUID is the host user ID of the user who started a rootless
+	// container, or -1 if it cannot be told: the one ID mapped on its own,
+	// which rootless runtimes do for that user, or else the ID root maps
+	// to.
+	OwnerUID int `json:"ownerUid"`
+	// Namespaced reports whether the server runs in a user namespace that
+	// maps only some of the host's user IDs.
+	Namespaced bool `json:"namespaced"`
+	// Container reports whether the server appears to run in a container.
+	Container bool `json:"container"`
+}
+
+// Warning describes how files the server creates will end up owned by a
+// host user other than the one who likely expects them, or returns "" if
+// they will not.
+func (m Mapping) Warning() string {
+	switch {
+	case m.Container && !m.Namespaced && m.UID == 0:
+		return "running as root in a container without a user namespace, so files created in mounted directories are owned by root on the host; run the container with --user or a rootless runtime, or set -file-mode 0666 and -dir-mode 0777 so host users can change them"
+	case m.Namespaced && m.HostUID >= 0 && m.OwnerUID >= 0 && m.HostUID != m.OwnerUID:
+		return fmt.Sprintf("uid %d maps to host uid %d rather than to the user running the container (host uid %d), so files created in mounted directories are not owned by that user; run as root in the container or with podman --userns=keep-id, or set -file-mode 0666 and -dir-mode 0777 so the host user can change them", m.UID, m.HostUID, m.OwnerUID)
+	}
+	return ""
+}
+
+// parseUIDMap builds the Mapping of uid from the contents of
+// /proc/self/uid_map, whose lines give the first ID inside the namespace,
+// the first ID outside it, and the number of IDs mapped.
+func parseUIDMap(r io.Reader, uid int) (Mapping, error) {
+	m := Mapping{UID: uid, HostUID: -1, OwnerUID: -1}
+	single, root := -1, -1
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			return Mapping{}, fmt.Errorf("invalid uid_map line %q", scanner.Text())
+		}
+		var nums [3]int64
+		for i, f := range fields {
+			n, err := strconv.ParseInt(f, 10, 64)
+			if err != nil {
+				return Mapping{}, fmt.Errorf("invalid uid_map line %q", scanner.Text())
+			}
+			nums[i] = n
+		}
+		inside, outside, count := nums[0], nums[1], nums[2]
+		// The initial namespace maps every ID to itself
+		if inside != 0 || outside != 0 || count != 4294967295 {
+			m.Namespaced = true
+		}
+		if id := int64(uid); id >= inside && id < inside+count {
+			m.HostUID = int(outside + id - inside)
+		}
+		if count == 1 {
+			single = int(outside)
+		}
+		if inside == 0 && count > 0 {
+			root = int(outside)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Mapping{}, err
+	}
+	m.OwnerUID = root
+	if single >= 0 {
+		m.OwnerUID = single
+	}
+	return m, nil
+}
+
+// inContainer reports whether the process appears to run in a container,
+// from the marker files Docker and Podman create and the container
+// variable systemd-nspawn and others set.
+func inContainer() bool {
+	if os.Getenv("container") != "" {
+		return true
+	}
+	for _, marker := range []string{"/.dockerenv", "/run/.containerenv"} {
+		if _, err := os.Stat(marker); err == nil {
+			return true
+		}
+	}
+	return false
+}
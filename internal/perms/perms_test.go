@@ -0,0 +1,92 @@
+package perms
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseMode(t *testing.T) {
+	for in, want := range map[string]os.FileMode{"0644": 0644, "755": 0755, "0o600": 0600, "0": 0, "0777": 0777} {
+		got, err := ParseMode(in)
+		if err != nil || got != want {
+			t.Errorf("ParseMode(%q) = %o, %v; want %o", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "0644x", "0888", "01777", "-1", "rw-r--r--"} {
+		if _, err := ParseMode(in); err == nil {
+			t.Errorf("ParseMode(%q): expected an error", in)
+		}
+	}
+}
+
+func TestParseUIDMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		uidMap  string
+		uid     int
+		want    Mapping
+		warning bool
+	}{
+		{
+			name:   "initial namespace",
+			uidMap: "         0          0 4294967295\n",
+			uid:    1000,
+			want:   Mapping{UID: 1000, HostUID: 1000, OwnerUID: 0},
+		},
+		{
+			name:   "rootless container as root",
+			uidMap: "0 1000 1\n1 100000 65536\n",
+			uid:    0,
+			want:   Mapping{UID: 0, HostUID: 1000, OwnerUID: 1000, Namespaced: true},
+		},
+		{
+			name:    "rootless container as another user",
+			uidMap:  "0 1000 1\n1 100000 65536\n",
+			uid:     33,
+			want:    Mapping{UID: 33, HostUID: 100032, OwnerUID: 1000, Namespaced: true},
+			warning: true,
+		},
+		{
+			name:   "keep-id",
+			uidMap: "0 1 1000\n1000 1000 1\n1001 1001 64536\n",
+			uid:    1000,
+			want:   Mapping{UID: 1000, HostUID: 1000, OwnerUID: 1000, Namespaced: true},
+		},
+		{
+			name:   "unmapped",
+			uidMap: "0 1000 1\n",
+			uid:    5,
+			want:   Mapping{UID: 5, HostUID: -1, OwnerUID: 1000, Namespaced: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseUIDMap(strings.NewReader(tt.uidMap), tt.uid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if w := got.Warning(); (w != "") != tt.warning {
+				t.Errorf("unexpected warning %q", w)
+			}
+		})
+	}
+
+	if _, err := parseUIDMap(strings.NewReader("0 x 1\n"), 0); err == nil {
+		t.Error("expected an error for an invalid line")
+	}
+}
+
+func TestRootContainerWarning(t *testing.T) {
+	m := Mapping{UID: 0, HostUID: 0, OwnerUID: 0, Container: true}
+	if !strings.Contains(m.Warning(), "owned by root on the host") {
+		t.Errorf("unexpected warning %q", m.Warning())
+	}
+	m.Container = false
+	if w := m.Warning(); w != "" {
+		t.Errorf("unexpected warning outside a container: %q", w)
+	}
+}
@@ -0,0 +1,20 @@
+//go:build linux
+
+package perms
+
+import "os"
+
+// DetectMapping reads the UID mapping of the server's user namespace.
+func DetectMapping() (Mapping, error) {
+	f, err := os.Open("/proc/self/uid_map")
+	if err != nil {
+		return Mapping{}, err
+	}
+	defer f.Close()
+	m, err := parseUIDMap(f, os.Geteuid())
+	if err != nil {
+		return Mapping{}, err
+	}
+	m.Container = inContainer()
+	return m, nil
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package perms
+
+import "os"
+
+// DetectMapping returns an identity mapping on platforms without user
+// namespaces.
+func DetectMapping() (Mapping, error) {
+	uid := os.Geteuid()
+	return Mapping{UID: uid, HostUID: uid, OwnerUID: 0, Container: inContainer()}, nil
+}
@@ -0,0 +1,18 @@
+//go:build !unix
+
+package perms
+
+import "os"
+
+// UmaskSupported reports whether SetUmask works on this platform.
+const UmaskSupported = false
+
+// SetUmask does nothing on platforms without a umask, and returns 0.
+func SetUmask(mask os.FileMode) os.FileMode {
+	return 0
+}
+
+// Umask returns 0 on platforms without a umask.
+func Umask() os.FileMode {
+	return 0
+}
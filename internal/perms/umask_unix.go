@@ -0,0 +1,24 @@
+//go:build unix
+
+package perms
+
+import (
+	"os"
+	"syscall"
+)
+
+// UmaskSupported reports whether SetUmask works on this platform.
+const UmaskSupported = true
+
+// SetUmask sets the process umask and returns the previous one.
+func SetUmask(mask os.FileMode) os.FileMode {
+	return os.FileMode(syscall.Umask(int(mask.Perm())))
+}
+
+// Umask returns the process umask.
+func Umask() os.FileMode {
+	// The only way to read the umask is to set it
+	mask := SetUmask(0)
+	SetUmask(mask)
+	return mask
+}
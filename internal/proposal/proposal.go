@@ -0,0 +1,137 @@
+// Package proposal stages file changes for review. A proposal holds the full
+// new content of each file together with a fingerprint of the content it
+// replaces, and is identified by an unguessable approval token. Nothing is
+// written to disk by this package.
+package proposal
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultTTL is how long a proposal stays approvable.
+const DefaultTTL = time.Hour
+
+// Sentinel errors returned by Store.
+var (
+	ErrNotFound = errors.New("proposal not found")
+	ErrExpired  = errors.New("proposal has expired")
+)
+
+// File is a single staged file change.
+type File struct {
+	// Path is the validated, resolved path the content will be written to.
+	Path string
+	// Content is the complete new file content.
+	Content []byte
+	// Mode is the permission to write the file with.
+	Mode os.FileMode
+	// BaseHash is the SHA-256 of the content being replaced, or empty if the
+	// file did not exist when the change was proposed.
+	BaseHash string
+	// Diff is a unified diff of the change for review.
+	Diff string
+}
+
+// Proposal is a set of staged file changes awaiting approval.
+type Proposal struct {
+	Token       string
+	Description string
+	Created     time.Time
+	Expires     time.Time
+	Files       []File
+}
+
+// Store holds pending proposals in memory.
+type Store struct {
+	mu        sync.Mutex
+	proposals map[string]*Proposal
+	ttl       time.Duration
+	now       func() time.Time
+}
+
+// NewStore creates a proposal store whose proposals expire after ttl.
+func NewStore(ttl time.Duration) *Store {
+	return &Store{
+		proposals: make(map[string]*Proposal),
+		ttl:       ttl,
+		now:       time.Now,
+	}
+}
+
+// Add stores a new proposal and returns it with its approval token.
+func (s *Store) Add(description string, files []File) (*Proposal, error) {
+	tokenBytes := make([]byte, 16)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pruneLocked()
+
+	now := s.now()
+	p := &Proposal{
+		Token:       hex.EncodeToString(tokenBytes),
+		Description: description,
+		Created:     now,
+		Expires:     now.Add(s.ttl),
+		Files:       files,
+	}
+	s.proposals[p.Token] = p
+	return p, nil
+}
+
+// Take removes the proposal with the given token and returns it.
+func (s *Store) Take(token string) (*Proposal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.proposals[token]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	delete(s.proposals, token)
+	if s.now().After(p.Expires) {
+		return nil, ErrExpired
+	}
+	return p, nil
+}
+
+// List returns the pending proposals, oldest first.
+func (s *Store) List() []*Proposal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pruneLocked()
+
+	list := make([]*Proposal, 0, len(s.proposals))
+	for _, p := range s.proposals {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Created.Before(list[j].Created)
+	})
+	return list
+}
+
+// pruneLocked drops expired proposals.
+func (s *Store) pruneLocked() {
+	now := s.now()
+	for token, p := range s.proposals {
+		if now.After(p.Expires) {
+			delete(s.proposals, token)
+		}
+	}
+}
+
+// HashContent returns the hex SHA-256 digest used for BaseHash.
+func HashContent(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
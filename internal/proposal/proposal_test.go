@@ -0,0 +1,67 @@
+package proposal
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStoreAddTake(t *testing.T) {
+	s := NewStore(DefaultTTL)
+
+	p, err := s.Add("rename helper", []File{{Path: "/tmp/a.go", Content: []byte("package a")}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(p.Token) != 32 {
+		t.Errorf("expected 32-char token, got %q", p.Token)
+	}
+	if got := len(s.List()); got != 1 {
+		t.Errorf("expected 1 pending proposal, got %d", got)
+	}
+
+	taken, err := s.Take(p.Token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if taken.Description != "rename helper" || len(taken.Files) != 1 {
+		t.Errorf("unexpected proposal: %+v", taken)
+	}
+
+	if _, err := s.Take(p.Token); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound on second take, got %v", err)
+	}
+}
+
+func TestStoreExpiry(t *testing.T) {
+	s := NewStore(time.Minute)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	p, err := s.Add("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := s.Take(p.Token); !errors.Is(err, ErrExpired) {
+		t.Errorf("expected ErrExpired, got %v", err)
+	}
+
+	if _, err := s.Add("", nil); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(2 * time.Minute)
+	if got := len(s.List()); got != 0 {
+		t.Errorf("expected expired proposals to be pruned, got %d", got)
+	}
+}
+
+func TestHashContent(t *testing.T) {
+	if HashContent([]byte("a")) == HashContent([]byte("b")) {
+		t.Error("expected different hashes for different content")
+	}
+	if HashContent([]byte("a")) != HashContent([]byte("a")) {
+		t.Error("expected stable hash")
+	}
+}
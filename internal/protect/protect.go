@@ -0,0 +1,112 @@
+// Package protect enforces a two-person rule for destructive operations in
+// protected subtrees. Deleting or moving anything in one takes two tokens
+// bound to the exact call: a confirmation token returned to the client for
+// its user, and an approval token delivered out-of-band to a second person
+// and never included in a tool result.
+package protect
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/portertech/filesystem-mcp-server/internal/confirm"
+	"github.com/portertech/filesystem-mcp-server/internal/pathutil"
+	"github.com/portertech/filesystem-mcp-server/internal/security"
+)
+
+// Request is an approval request sent out-of-band to the second person.
+type Request struct {
+	Tool    string
+	Action  string
+	Paths   []string
+	Token   string
+	Expires time.Time
+}
+
+// Guard tracks the protected subtrees and the tokens issued for them.
+type Guard struct {
+	dirs []string
+	// forms holds dirs and their symlink-resolved forms, for matching
+	// paths resolved either way.
+	forms         []string
+	confirmations *confirm.Gate
+	approvals     *confirm.Gate
+	ttl           time.Duration
+	notify        func(Request)
+}
+
+// New creates a guard for the given subtrees. Approval tokens are passed to
+// notify, which must deliver them somewhere the agent cannot read.
+func New(dirs []string, ttl time.Duration, notify func(Request)) (*Guard, error) {
+	g := &Guard{
+		confirmations: confirm.New(nil, ttl),
+		approvals:     confirm.New(nil, ttl),
+		ttl:           ttl,
+		notify:        notify,
+	}
+	for _, d := range dirs {
+		normalized, err := pathutil.NormalizePath(d)
+		if err != nil {
+			return nil, fmt.Errorf("protected path %s: %w", d, err)
+		}
+		g.dirs = append(g.dirs, normalized)
+		g.forms = append(g.forms, normalized)
+		if resolved, err := filepath.EvalSymlinks(normalized); err == nil && resolved != normalized {
+			g.forms = append(g.forms, resolved)
+		}
+	}
+	return g, nil
+}
+
+// Dirs returns the protected subtrees.
+func (g *Guard) Dirs() []string {
+	return append([]string(nil), g.dirs...)
+}
+
+// Covers reports whether an operation on path would affect a protected
+// subtree: path is inside one, or contains one.
+func (g *Guard) Covers(path string) bool {
+	if g == nil {
+		return false
+	}
+	for _, d := range g.forms {
+		if security.IsPathWithinAllowedDirectories(path, []string{d}) || security.IsPathWithinAllowedDirectories(d, []string{path}) {
+			return true
+		}
+	}
+	return false
+}
+
+// Approve consumes the confirmation and approval tokens in args and reports
+// whether both were issued for this exact call. Both tokens are spent even
+// when they do not match.
+func (g *Guard) Approve(tool string, args map[string]any) bool {
+	confirmation, _ := args[confirm.TokenParam].(string)
+	approval, _ := args[confirm.ApprovalParam].(string)
+	confirmed := g.confirmations.Confirm(confirmation, tool, args)
+	approved := g.approvals.Confirm(approval, tool, args)
+	return confirmed && approved
+}
+
+// Request issues a token pair for a call to tool with args. The approval
+// token is sent out-of-band; the confirmation token is returned for the
+// client.
+func (g *Guard) Request(tool, action string, paths []string, args map[string]any) (string, error) {
+	approval, err := g.approvals.Issue(tool, args)
+	if err != nil {
+		return "", err
+	}
+	confirmation, err := g.confirmations.Issue(tool, args)
+	if err != nil {
+		return "", err
+	}
+	g.notify(Request{
+		Tool:    tool,
+		Action:  action,
+		Paths:   paths,
+		Token:   approval,
+		Expires: time.Now().Add(g.ttl),
+	})
+	return confirmation, nil
+}
@@ -0,0 +1,101 @@
+package protect
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/portertech/filesystem-mcp-server/internal/confirm"
+)
+
+func TestGuardCovers(t *testing.T) {
+	tmpDir := t.TempDir()
+	protected := filepath.Join(tmpDir, "vault")
+	if err := os.Mkdir(protected, 0755); err != nil {
+		t.Fatal(err)
+	}
+	g, err := New([]string{protected}, confirm.DefaultTTL, func(Request) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resolvedTmp, _ := filepath.EvalSymlinks(tmpDir)
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{protected, true},
+		{filepath.Join(protected, "a", "b.txt"), true},
+		{filepath.Join(resolvedTmp, "vault", "c.txt"), true},
+		{tmpDir, true},
+		{filepath.Join(tmpDir, "other"), false},
+		{filepath.Join(tmpDir, "vaults"), false},
+	}
+	for _, tt := range tests {
+		if got := g.Covers(tt.path); got != tt.want {
+			t.Errorf("Covers(%s) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+
+	var nilGuard *Guard
+	if nilGuard.Covers(protected) {
+		t.Error("nil guard should cover nothing")
+	}
+}
+
+func TestGuardApprove(t *testing.T) {
+	var sent []Request
+	g, err := New([]string{t.TempDir()}, confirm.DefaultTTL, func(r Request) {
+		sent = append(sent, r)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := map[string]any{"path": "/vault/x"}
+
+	confirmation, err := g.Request("delete_file", "delete /vault/x", []string{"/vault/x"}, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 || sent[0].Token == "" || sent[0].Tool != "delete_file" {
+		t.Fatalf("expected one approval request, got %+v", sent)
+	}
+	approval := sent[0].Token
+	if approval == confirmation {
+		t.Fatal("approval and confirmation tokens must differ")
+	}
+
+	// The confirmation token alone is not enough, and the attempt spends it
+	if g.Approve("delete_file", map[string]any{"path": "/vault/x", confirm.TokenParam: confirmation}) {
+		t.Error("expected approval to need both tokens")
+	}
+
+	confirmation, err = g.Request("delete_file", "delete /vault/x", []string{"/vault/x"}, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	approval = sent[1].Token
+
+	// Swapped tokens do not approve
+	swapped := map[string]any{"path": "/vault/x", confirm.TokenParam: approval, confirm.ApprovalParam: confirmation}
+	if g.Approve("delete_file", swapped) {
+		t.Error("expected swapped tokens to be rejected")
+	}
+
+	confirmation, _ = g.Request("delete_file", "delete /vault/x", []string{"/vault/x"}, args)
+	approval = sent[2].Token
+	call := map[string]any{"path": "/vault/x", confirm.TokenParam: confirmation, confirm.ApprovalParam: approval}
+	if g.Approve("delete_file", map[string]any{"path": "/vault/y", confirm.TokenParam: confirmation, confirm.ApprovalParam: approval}) {
+		t.Error("expected tokens not to approve a different call")
+	}
+
+	confirmation, _ = g.Request("delete_file", "delete /vault/x", []string{"/vault/x"}, args)
+	approval = sent[3].Token
+	call[confirm.TokenParam], call[confirm.ApprovalParam] = confirmation, approval
+	if !g.Approve("delete_file", call) {
+		t.Error("expected both tokens to approve the matching call")
+	}
+	if g.Approve("delete_file", call) {
+		t.Error("expected tokens to be single-use")
+	}
+}
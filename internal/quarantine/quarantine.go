@@ -0,0 +1,159 @@
+// Package quarantine keeps copies of files ingested by agents along with a
+// manifest of where they came from, giving security teams provenance for
+// binaries read from the workspace. Copies are content-addressed by SHA-256
+// and stored read-only; the manifest is an append-only JSON Lines file.
+package quarantine
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	filesDirName = "files"
+	manifestName = "manifest.jsonl"
+)
+
+// Record describes one ingested file.
+type Record struct {
+	Time   time.Time `json:"time"`
+	Tool   string    `json:"tool"`
+	Source string    `json:"source"`
+	SHA256 string    `json:"sha256"`
+	Size   int64     `json:"size"`
+	// Copy is the quarantined copy the data was served from.
+	Copy string `json:"copy"`
+}
+
+// Quarantine copies files into a directory and records their provenance.
+type Quarantine struct {
+	mu       sync.Mutex
+	dir      string
+	filesDir string
+}
+
+// New creates a quarantine rooted at dir.
+func New(dir string) (*Quarantine, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	q := &Quarantine{dir: abs, filesDir: filepath.Join(abs, filesDirName)}
+	if err := os.MkdirAll(q.filesDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create quarantine directory: %w", err)
+	}
+	return q, nil
+}
+
+// Dir returns the quarantine root directory.
+func (q *Quarantine) Dir() string {
+	return q.dir
+}
+
+// Ingest copies the file at source into the quarantine, hashing it on the
+// way, and appends a record to the manifest. Callers should serve the data
+// from the returned record's Copy, so what the agent sees is exactly what
+// was hashed even if source changes afterwards.
+func (q *Quarantine) Ingest(tool, source string) (Record, error) {
+	in, err := os.Open(source)
+	if err != nil {
+		return Record{}, err
+	}
+	defer in.Close()
+
+	randBytes := make([]byte, 8)
+	if _, err := rand.Read(randBytes); err != nil {
+		return Record{}, fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	tmpName := filepath.Join(q.filesDir, ".tmp-"+hex.EncodeToString(randBytes))
+	out, err := os.OpenFile(tmpName, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0400)
+	if err != nil {
+		return Record{}, fmt.Errorf("failed to create quarantine copy: %w", err)
+	}
+	defer os.Remove(tmpName)
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(out, hash), in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return Record{}, fmt.Errorf("failed to copy into quarantine: %w", err)
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	rec := Record{
+		Time:   time.Now().UTC(),
+		Tool:   tool,
+		Source: source,
+		SHA256: sum,
+		Size:   size,
+		Copy:   filepath.Join(q.filesDir, sum+strings.ToLower(filepath.Ext(source))),
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	// Identical content is stored once
+	if _, err := os.Lstat(rec.Copy); os.IsNotExist(err) {
+		if err := os.Rename(tmpName, rec.Copy); err != nil {
+			return Record{}, fmt.Errorf("failed to store quarantine copy: %w", err)
+		}
+	}
+	if err := q.appendLocked(rec); err != nil {
+		return Record{}, fmt.Errorf("failed to record provenance: %w", err)
+	}
+	return rec, nil
+}
+
+// Records returns the manifest, oldest first.
+func (q *Quarantine) Records() ([]Record, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	data, err := os.ReadFile(filepath.Join(q.dir, manifestName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var records []Record
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest: %w", err)
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// appendLocked adds rec to the manifest.
+func (q *Quarantine) appendLocked(rec Record) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(q.dir, manifestName), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
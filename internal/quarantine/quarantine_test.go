@@ -0,0 +1,84 @@
+package quarantine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIngest(t *testing.T) {
+	srcDir := t.TempDir()
+	q, err := New(filepath.Join(t.TempDir(), "quarantine"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte("\x89PNG\r\n\x1a\nimage data")
+	src := filepath.Join(srcDir, "logo.PNG")
+	if err := os.WriteFile(src, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(data)
+	wantHash := hex.EncodeToString(sum[:])
+
+	rec, err := q.Ingest("read_media_file", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.SHA256 != wantHash || rec.Size != int64(len(data)) || rec.Source != src || rec.Tool != "read_media_file" {
+		t.Errorf("unexpected record: %+v", rec)
+	}
+	if rec.Copy != filepath.Join(q.Dir(), "files", wantHash+".png") {
+		t.Errorf("unexpected copy path %s", rec.Copy)
+	}
+	copied, err := os.ReadFile(rec.Copy)
+	if err != nil || string(copied) != string(data) {
+		t.Fatalf("copy = %q, %v", copied, err)
+	}
+	if info, err := os.Stat(rec.Copy); err != nil || info.Mode().Perm() != 0400 {
+		t.Errorf("copy should be read-only: %v %v", info.Mode(), err)
+	}
+
+	// Changing the source afterwards does not affect the copy, and a second
+	// read of the same content is recorded without another copy
+	if err := os.WriteFile(src, []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if copied, _ := os.ReadFile(rec.Copy); string(copied) != string(data) {
+		t.Error("copy changed with source")
+	}
+	other := filepath.Join(srcDir, "same.png")
+	if err := os.WriteFile(other, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Ingest("read_media_file", other); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, _ := os.ReadDir(filepath.Join(q.Dir(), "files"))
+	if len(entries) != 1 {
+		t.Errorf("expected one stored copy, got %d", len(entries))
+	}
+	records, err := q.Records()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].Source != src || records[1].Source != other || records[1].SHA256 != wantHash {
+		t.Errorf("unexpected manifest: %+v", records)
+	}
+}
+
+func TestIngestMissingSource(t *testing.T) {
+	q, err := New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Ingest("read_media_file", filepath.Join(t.TempDir(), "missing.png")); err == nil {
+		t.Error("expected error for missing source")
+	}
+	if records, _ := q.Records(); len(records) != 0 {
+		t.Errorf("expected empty manifest, got %+v", records)
+	}
+}
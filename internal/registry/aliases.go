@@ -0,0 +1,153 @@
+package registry
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/portertech/filesystem-mcp-server/internal/pathutil"
+)
+
+// aliasPattern matches alias names. At least two characters are required so
+// that aliases cannot be confused with Windows drive letters.
+var aliasPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]+$`)
+
+// Alias maps a short name to an allowed directory, so tool inputs can use
+// name:/rel/path instead of the directory's absolute host path.
+type Alias struct {
+	Name string
+	Dir  string
+}
+
+// SetAliases replaces the configured root aliases, given as name to
+// directory. Each directory must be one of the allowed directories.
+func (r *Registry) SetAliases(aliases map[string]string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	expanded := make(map[string]string, len(aliases))
+	for name, dir := range aliases {
+		if !aliasPattern.MatchString(name) {
+			return fmt.Errorf("invalid alias name %q: must start with a letter and contain at least two letters, digits, '-' or '_'", name)
+		}
+		normalized, err := pathutil.NormalizePath(dir)
+		if err != nil {
+			return fmt.Errorf("alias %s: %w", name, err)
+		}
+		allowed := false
+		for _, d := range r.dirs {
+			if d == normalized {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("alias %s: %s is not an allowed directory", name, normalized)
+		}
+		expanded[name] = normalized
+	}
+	r.aliases = expanded
+	return nil
+}
+
+// Aliases returns the configured root aliases sorted by name.
+func (r *Registry) Aliases() []Alias {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]Alias, 0, len(r.aliases))
+	for name, dir := range r.aliases {
+		result = append(result, Alias{Name: name, Dir: dir})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// Bookmarks looks up named directory shortcuts.
+type Bookmarks interface {
+	Lookup(name string) (string, bool)
+}
+
+// SetBookmarks configures the bookmarks expanded in paths. Passing nil
+// disables bookmark expansion.
+func (r *Registry) SetBookmarks(b Bookmarks) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bookmarks = b
+}
+
+// ExpandPath rewrites a path that starts with a root alias or a bookmark to
+// the directory it stands for joined with the rest of the path. Aliases are
+// written name:/rel/path, or just name:; bookmarks are written @name/rel/path,
+// or just @name. Other paths are returned unchanged. The result still has to
+// be validated; a .. in rel/path can climb out of the named directory.
+func (r *Registry) ExpandPath(path string) string {
+	r.mu.RLock()
+	aliases, bookmarks := r.aliases, r.bookmarks
+	r.mu.RUnlock()
+
+	if strings.HasPrefix(path, "@") && bookmarks != nil {
+		name, rest := path[1:], ""
+		if i := strings.IndexAny(name, `/\`); i >= 0 {
+			name, rest = name[:i], name[i:]
+		}
+		if dir, ok := bookmarks.Lookup(name); ok {
+			return filepath.Join(dir, filepath.FromSlash(rest))
+		}
+		return path
+	}
+
+	name, rest, ok := strings.Cut(path, ":")
+	if !ok || (rest != "" && rest[0] != '/' && rest[0] != '\\') {
+		return path
+	}
+	dir, ok := aliases[name]
+	if !ok {
+		return path
+	}
+	return filepath.Join(dir, filepath.FromSlash(rest))
+}
+
+// ResolveRoot returns the allowed directory named by root, which is either
+// its index in the allowed directory list, an alias, or the directory
+// itself.
+func (r *Registry) ResolveRoot(root string) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if i, err := strconv.Atoi(root); err == nil {
+		if i < 0 || i >= len(r.dirs) {
+			return "", fmt.Errorf("root index %d out of range: %d allowed directories", i, len(r.dirs))
+		}
+		return r.dirs[i], nil
+	}
+	if dir, ok := r.aliases[strings.TrimSuffix(root, ":")]; ok {
+		return dir, nil
+	}
+	if normalized, err := pathutil.NormalizePath(root); err == nil {
+		for _, d := range r.dirs {
+			if d == normalized {
+				return d, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("unknown root %q: expected an allowed directory index, alias, or path", root)
+}
+
+// ResolveRelative joins path, which must be relative, to the allowed
+// directory named by root. The result still has to be validated.
+func (r *Registry) ResolveRelative(root, path string) (string, error) {
+	dir, err := r.ResolveRoot(root)
+	if err != nil {
+		return "", err
+	}
+	if filepath.IsAbs(path) || strings.HasPrefix(path, "/") || strings.HasPrefix(path, `\`) {
+		return "", fmt.Errorf("path %q must be relative when root is given", path)
+	}
+	return filepath.Join(dir, filepath.FromSlash(path)), nil
+}
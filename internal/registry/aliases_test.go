@@ -0,0 +1,113 @@
+package registry
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAliases(t *testing.T) {
+	tmpDir := t.TempDir()
+	workspace := filepath.Join(tmpDir, "workspace")
+	other := filepath.Join(tmpDir, "other")
+	for _, d := range []string{workspace, other} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(workspace, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	r := New([]string{workspace}, logger)
+
+	t.Run("SetAliases rejects invalid aliases", func(t *testing.T) {
+		for name, aliases := range map[string]map[string]string{
+			"drive letter":    {"C": workspace},
+			"bad characters":  {"my space": workspace},
+			"not allowed dir": {"other": other},
+		} {
+			if err := r.SetAliases(aliases); err == nil {
+				t.Errorf("%s: expected error", name)
+			}
+		}
+	})
+
+	if err := r.SetAliases(map[string]string{"workspace": workspace}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"workspace:/main.go", filepath.Join(workspace, "main.go")},
+		{"workspace:/src/../main.go", filepath.Join(workspace, "main.go")},
+		{"workspace:", workspace},
+		{"workspace:/", workspace},
+		{"unknown:/main.go", "unknown:/main.go"},
+		{"workspace:main.go", "workspace:main.go"},
+		{"/abs/path", "/abs/path"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if result := r.ExpandPath(tt.input); result != tt.expected {
+				t.Errorf("ExpandPath(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+
+	t.Run("Validate expands aliases", func(t *testing.T) {
+		if _, err := r.Validate("workspace:/main.go"); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if _, err := r.ValidateForCreation("workspace:/new/file.txt"); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if _, err := r.Validate("workspace:/../other"); err == nil {
+			t.Error("expected error for alias path escaping its root")
+		}
+	})
+
+	if aliases := r.Aliases(); len(aliases) != 1 || aliases[0].Name != "workspace" || aliases[0].Dir != workspace {
+		t.Errorf("Aliases() = %v", aliases)
+	}
+}
+
+// bookmarkMap is a Bookmarks backed by a map.
+type bookmarkMap map[string]string
+
+func (m bookmarkMap) Lookup(name string) (string, bool) {
+	dir, ok := m[name]
+	return dir, ok
+}
+
+func TestExpandPathBookmarks(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	r := New(nil, logger)
+	if got := r.ExpandPath("@tests/a.go"); got != "@tests/a.go" {
+		t.Errorf("expanded without bookmarks: %q", got)
+	}
+
+	r.SetBookmarks(bookmarkMap{"tests": "/repo/internal/service/tests"})
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"@tests", "/repo/internal/service/tests"},
+		{"@tests/", "/repo/internal/service/tests"},
+		{"@tests/unit/a_test.go", "/repo/internal/service/tests/unit/a_test.go"},
+		{"@unknown/a.go", "@unknown/a.go"},
+		{"@testsuite", "@testsuite"},
+		{"/repo/@tests", "/repo/@tests"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if result := r.ExpandPath(tt.input); result != filepath.FromSlash(tt.expected) {
+				t.Errorf("ExpandPath(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
@@ -1,13 +1,29 @@
 package registry
 
 import (
+	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 
+	"github.com/portertech/filesystem-mcp-server/internal/confirm"
+	"github.com/portertech/filesystem-mcp-server/internal/ffmpeg"
+	"github.com/portertech/filesystem-mcp-server/internal/filelock"
+	"github.com/portertech/filesystem-mcp-server/internal/grant"
+	"github.com/portertech/filesystem-mcp-server/internal/journal"
+	"github.com/portertech/filesystem-mcp-server/internal/membudget"
+	"github.com/portertech/filesystem-mcp-server/internal/network"
+	"github.com/portertech/filesystem-mcp-server/internal/overlay"
 	"github.com/portertech/filesystem-mcp-server/internal/pathutil"
+	"github.com/portertech/filesystem-mcp-server/internal/protect"
+	"github.com/portertech/filesystem-mcp-server/internal/quarantine"
+	"github.com/portertech/filesystem-mcp-server/internal/reputation"
+	"github.com/portertech/filesystem-mcp-server/internal/scan"
 	"github.com/portertech/filesystem-mcp-server/internal/security"
+	"github.com/portertech/filesystem-mcp-server/internal/shadow"
+	"github.com/portertech/filesystem-mcp-server/internal/trash"
 )
 
 // Validator defines path validation methods used by the registry.
@@ -20,15 +36,40 @@ var _ Validator = (*Registry)(nil)
 
 // Registry manages the list of allowed directories.
 type Registry struct {
-	mu       sync.RWMutex
-	dirs     []string
-	resolved []string // symlink-resolved versions of dirs, computed once at init
-	logger   *slog.Logger
+	mu         sync.RWMutex
+	dirs       []string
+	resolved   []string // symlink-resolved versions of dirs, computed once at init
+	overlay    *overlay.Overlay
+	confirm    *confirm.Gate
+	protect    *protect.Guard
+	quarantine *quarantine.Quarantine
+	scanner    scan.Scanner
+	scanBlock  bool // refuse to return data the scanner flags
+	reputation *reputation.Lists
+	repBlock   bool // refuse to return data of denylisted files
+	shadow     *shadow.Store
+	memory     *membudget.Budget
+	ffmpeg     *ffmpeg.Runner
+	network    *network.Access
+	trash      *trash.Trash
+	journal    *journal.Journal
+	locks      *filelock.Manager
+	mimeTypes  map[string]string // extension to media type overrides
+	limits     Limits
+	modes      Modes
+	readOnly   map[string]string // read-only dir to its resolved form
+	grants     *grant.Manager
+	strict     bool // reject non-portable names for new files
+	allowChown bool // register change_owner
+	aliases    map[string]string
+	bookmarks  Bookmarks
+	logger     *slog.Logger
 }
 
 // New creates a new Registry with the given directories.
 func New(dirs []string, logger *slog.Logger) *Registry {
 	r := &Registry{
+		modes:  DefaultModes,
 		logger: logger,
 	}
 
@@ -132,6 +173,8 @@ func (r *Registry) GetResolved() []string {
 // Validate checks if a path is within allowed directories.
 // Returns the resolved path if valid, or an error if not.
 func (r *Registry) Validate(path string) (string, error) {
+	path = r.ExpandPath(path)
+
 	r.mu.RLock()
 	dirs := make([]string, len(r.dirs))
 	copy(dirs, r.dirs)
@@ -144,14 +187,37 @@ func (r *Registry) Validate(path string) (string, error) {
 
 // ValidateForCreation validates a path for file/directory creation.
 func (r *Registry) ValidateForCreation(path string) (string, error) {
+	path = r.ExpandPath(path)
+
 	r.mu.RLock()
 	dirs := make([]string, len(r.dirs))
 	copy(dirs, r.dirs)
 	resolved := make([]string, len(r.resolved))
 	copy(resolved, r.resolved)
+	strict := r.strict
 	r.mu.RUnlock()
 
-	return security.ValidatePathForCreationWithResolved(path, dirs, resolved)
+	resolvedPath, err := security.ValidatePathForCreationWithResolved(path, dirs, resolved)
+	if err != nil || !strict {
+		return resolvedPath, err
+	}
+	if err := validateNewNames(resolvedPath); err != nil {
+		return "", err
+	}
+	return resolvedPath, nil
+}
+
+// validateNewNames checks the names of the components of path that do not
+// exist yet, from the last one up to the first existing ancestor.
+func validateNewNames(path string) error {
+	for p := path; ; p = filepath.Dir(p) {
+		if _, err := os.Lstat(p); err == nil || filepath.Dir(p) == p {
+			return nil
+		}
+		if err := pathutil.ValidateFilename(filepath.Base(p)); err != nil {
+			return fmt.Errorf("invalid name %q: %w", filepath.Base(p), err)
+		}
+	}
 }
 
 // IsEmpty returns true if no directories are registered.
@@ -160,3 +226,255 @@ func (r *Registry) IsEmpty() bool {
 	defer r.mu.RUnlock()
 	return len(r.dirs) == 0
 }
+
+// SetOverlay enables overlay mode: mutations are redirected into the given
+// copy-on-write overlay instead of the real tree. Passing nil disables it.
+func (r *Registry) SetOverlay(ov *overlay.Overlay) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.overlay = ov
+}
+
+// Overlay returns the configured overlay, or nil when overlay mode is off.
+func (r *Registry) Overlay() *overlay.Overlay {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.overlay
+}
+
+// SetQuarantine enables copy-on-read quarantine of ingested media files.
+// Passing nil disables it.
+func (r *Registry) SetQuarantine(q *quarantine.Quarantine) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.quarantine = q
+}
+
+// Quarantine returns the configured quarantine, or nil when it is off.
+func (r *Registry) Quarantine() *quarantine.Quarantine {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.quarantine
+}
+
+// SetScanner configures the content scanner run on files before their data
+// is returned. When block is true, flagged files are refused; otherwise the
+// data is returned along with the detection. Passing nil disables scanning.
+func (r *Registry) SetScanner(s scan.Scanner, block bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.scanner = s
+	r.scanBlock = block
+}
+
+// Scanner returns the configured content scanner, or nil when scanning is
+// off, and whether flagged files are blocked.
+func (r *Registry) Scanner() (scan.Scanner, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.scanner, r.scanBlock
+}
+
+// SetReputation configures the hash lists read tools check files against.
+// When block is true, denylisted files are refused; otherwise their data is
+// returned with a warning. Passing nil disables the check.
+func (r *Registry) SetReputation(l *reputation.Lists, block bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reputation = l
+	r.repBlock = block
+}
+
+// Reputation returns the configured hash lists, or nil when none are, and
+// whether denylisted files are blocked.
+func (r *Registry) Reputation() (*reputation.Lists, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.reputation, r.repBlock
+}
+
+// SetShadow configures the store of last committed file content that whole
+// file reads fall back to when a file is being written. Passing nil disables
+// shadow reads.
+func (r *Registry) SetShadow(s *shadow.Store) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.shadow = s
+}
+
+// Shadow returns the shadow store, or nil when shadow reads are off.
+func (r *Registry) Shadow() *shadow.Store {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.shadow
+}
+
+// SetMemoryBudget configures the budget that whole file reads reserve their
+// size from. Passing nil removes the limit.
+func (r *Registry) SetMemoryBudget(b *membudget.Budget) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.memory = b
+}
+
+// MemoryBudget returns the memory budget, or nil when reads are unlimited.
+func (r *Registry) MemoryBudget() *membudget.Budget {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.memory
+}
+
+// SetFFmpeg configures the ffmpeg used to cut and resample audio that cannot
+// be trimmed natively. Passing nil limits clips to PCM WAV files.
+func (r *Registry) SetFFmpeg(f *ffmpeg.Runner) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ffmpeg = f
+}
+
+// FFmpeg returns the configured ffmpeg, or nil when there is none.
+func (r *Registry) FFmpeg() *ffmpeg.Runner {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.ffmpeg
+}
+
+// SetNetwork configures the hosts fetch_to_file may download from. Passing
+// nil disables network access.
+func (r *Registry) SetNetwork(a *network.Access) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.network = a
+}
+
+// Network returns the configured network access, or nil when there is none.
+func (r *Registry) Network() *network.Access {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.network
+}
+
+// SetTrash configures the trash that delete_file and delete_directory move
+// entries into. Passing nil makes deletes permanent.
+func (r *Registry) SetTrash(t *trash.Trash) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.trash = t
+}
+
+// Trash returns the trash, or nil when deletes are permanent.
+func (r *Registry) Trash() *trash.Trash {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.trash
+}
+
+// SetJournal configures the journal that records the prior state of files
+// changed by write_file, edit_file, move_file, and delete_file. Passing nil
+// disables undo.
+func (r *Registry) SetJournal(j *journal.Journal) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.journal = j
+}
+
+// Journal returns the undo journal, or nil when undo is off.
+func (r *Registry) Journal() *journal.Journal {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.journal
+}
+
+// SetLocks configures the manager of advisory file locks taken with
+// lock_file. Passing nil disables locking.
+func (r *Registry) SetLocks(m *filelock.Manager) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.locks = m
+}
+
+// Locks returns the lock manager, or nil when locking is off.
+func (r *Registry) Locks() *filelock.Manager {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.locks
+}
+
+// SetMIMETypes configures media types for file extensions, given with
+// their dot, that read_media_file uses instead of sniffing the content.
+func (r *Registry) SetMIMETypes(types map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mimeTypes = make(map[string]string, len(types))
+	for ext, t := range types {
+		r.mimeTypes[strings.ToLower(ext)] = t
+	}
+}
+
+// MIMEType returns the configured media type for a file extension.
+func (r *Registry) MIMEType(ext string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.mimeTypes[strings.ToLower(ext)]
+	return t, ok
+}
+
+// SetConfirmations configures which destructive operations must be confirmed
+// before they run. Passing nil disables confirmation.
+func (r *Registry) SetConfirmations(g *confirm.Gate) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.confirm = g
+}
+
+// Confirmations returns the configured confirmation gate, or nil.
+func (r *Registry) Confirmations() *confirm.Gate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.confirm
+}
+
+// SetProtection configures the subtrees where deletes and moves need a
+// second, out-of-band approval. Passing nil disables the two-person rule.
+func (r *Registry) SetProtection(g *protect.Guard) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.protect = g
+}
+
+// Protection returns the protected subtree guard, or nil when there is none.
+func (r *Registry) Protection() *protect.Guard {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.protect
+}
+
+// SetStrictFilenames configures whether new files and directories must have
+// portable names; see pathutil.ValidateFilename.
+func (r *Registry) SetStrictFilenames(strict bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.strict = strict
+}
+
+// StrictFilenames reports whether new files and directories must have
+// portable names.
+func (r *Registry) StrictFilenames() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.strict
+}
+
+// SetAllowChown configures whether clients may change file ownership.
+func (r *Registry) SetAllowChown(allow bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.allowChown = allow
+}
+
+// AllowChown reports whether clients may change file ownership.
+func (r *Registry) AllowChown() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.allowChown
+}
@@ -80,6 +80,25 @@ func TestRegistry(t *testing.T) {
 		}
 	})
 
+	t.Run("ValidateForCreation enforces strict filenames", func(t *testing.T) {
+		r := New([]string{dir1}, logger)
+		bad := filepath.Join(dir1, "new:dir", "notes.txt")
+		if _, err := r.ValidateForCreation(bad); err != nil {
+			t.Fatalf("unexpected error without strict filenames: %v", err)
+		}
+
+		r.SetStrictFilenames(true)
+		if _, err := r.ValidateForCreation(bad); err == nil || !strings.Contains(err.Error(), "new:dir") {
+			t.Errorf("expected error naming the invalid component, got %v", err)
+		}
+		if _, err := r.ValidateForCreation(filepath.Join(dir1, "sub", "CON.txt")); err == nil {
+			t.Error("expected error for reserved name")
+		}
+		if _, err := r.ValidateForCreation(filepath.Join(dir1, "sub", "notes.txt")); err != nil {
+			t.Errorf("unexpected error for portable name: %v", err)
+		}
+	})
+
 	t.Run("IsEmpty", func(t *testing.T) {
 		r := New([]string{}, logger)
 		if !r.IsEmpty() {
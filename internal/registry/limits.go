@@ -0,0 +1,24 @@
+package registry
+
+// Limits holds thresholds that guard against over-broad destructive
+// operations. A zero value disables the corresponding limit.
+type Limits struct {
+	// MaxDeleteFiles caps the number of files a single call may delete.
+	MaxDeleteFiles int
+	// MaxDeleteBytes caps the total size of the files a single call may delete.
+	MaxDeleteBytes int64
+}
+
+// SetLimits replaces the configured operation limits.
+func (r *Registry) SetLimits(l Limits) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.limits = l
+}
+
+// Limits returns the configured operation limits.
+func (r *Registry) Limits() Limits {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.limits
+}
@@ -0,0 +1,27 @@
+package registry
+
+import "os"
+
+// Modes are the permissions of the files and directories that tools create.
+// Files and directories that already exist keep their own.
+type Modes struct {
+	File os.FileMode
+	Dir  os.FileMode
+}
+
+// DefaultModes are the modes used unless SetModes replaces them.
+var DefaultModes = Modes{File: 0644, Dir: 0755}
+
+// SetModes replaces the modes of created files and directories.
+func (r *Registry) SetModes(m Modes) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.modes = m
+}
+
+// Modes returns the modes of created files and directories.
+func (r *Registry) Modes() Modes {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.modes
+}
@@ -0,0 +1,113 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/portertech/filesystem-mcp-server/internal/grant"
+	"github.com/portertech/filesystem-mcp-server/internal/pathutil"
+	"github.com/portertech/filesystem-mcp-server/internal/security"
+)
+
+// ErrReadOnly is returned for writes under a read-only directory that no
+// active grant covers.
+var ErrReadOnly = errors.New("directory is read-only")
+
+// SetReadOnly marks allowed directories as read-only. Each directory must be
+// one of the allowed directories.
+func (r *Registry) SetReadOnly(dirs []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	readOnly := make(map[string]string, len(dirs))
+	for _, dir := range dirs {
+		normalized, err := pathutil.NormalizePath(dir)
+		if err != nil {
+			return fmt.Errorf("read-only directory %s: %w", dir, err)
+		}
+		found := false
+		for i, d := range r.dirs {
+			if d == normalized {
+				readOnly[d] = r.resolved[i]
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("read-only directory %s is not an allowed directory", normalized)
+		}
+	}
+	r.readOnly = readOnly
+	return nil
+}
+
+// IsReadOnly reports whether dir is an allowed directory marked read-only.
+func (r *Registry) IsReadOnly(dir string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.readOnly[dir]
+	return ok
+}
+
+// SetGrants configures the manager of temporary write grants for read-only
+// directories. Passing nil disables grants.
+func (r *Registry) SetGrants(m *grant.Manager) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.grants = m
+}
+
+// Grants returns the grant manager, or nil when grants are disabled.
+func (r *Registry) Grants() *grant.Manager {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.grants
+}
+
+// ActivateGrant activates an operator-issued grant token for one of the
+// read-only directories and records it in the audit log.
+func (r *Registry) ActivateGrant(token string) (grant.Status, error) {
+	m := r.Grants()
+	if m == nil {
+		return grant.Status{}, errors.New("write grants are not enabled")
+	}
+	g, err := m.Verify(token)
+	if err != nil {
+		r.logger.Warn("audit: write grant rejected", "error", err)
+		return grant.Status{}, err
+	}
+	if !r.IsReadOnly(g.Root) {
+		r.logger.Warn("audit: write grant rejected", "grant", g.ID, "root", g.Root, "error", "not a read-only directory")
+		return grant.Status{}, fmt.Errorf("grant %s is for %s, which is not a read-only directory", g.ID, g.Root)
+	}
+	s, err := m.Activate(token)
+	if err != nil {
+		r.logger.Warn("audit: write grant rejected", "grant", g.ID, "root", g.Root, "error", err)
+		return grant.Status{}, err
+	}
+	r.logger.Info("audit: write grant activated", "grant", s.ID, "root", s.Root, "expires", s.Expires, "maxOps", s.MaxOps, "reason", s.Reason)
+	return s, nil
+}
+
+// CheckWrite returns an error if resolvedPath is under a read-only directory
+// and no active grant covers it. A covered write spends one operation of the
+// grant and is recorded in the audit log.
+func (r *Registry) CheckWrite(resolvedPath string) error {
+	r.mu.RLock()
+	readOnly, grants := r.readOnly, r.grants
+	r.mu.RUnlock()
+
+	for dir, resolved := range readOnly {
+		if !security.IsPathWithinAllowedDirectories(resolvedPath, []string{dir, resolved}) {
+			continue
+		}
+		if grants != nil {
+			if s, ok := grants.Use(dir); ok {
+				r.logger.Info("audit: write under grant", "grant", s.ID, "root", dir, "path", resolvedPath, "used", s.Used, "maxOps", s.MaxOps)
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: %s is under %s; an operator-issued write grant is required", ErrReadOnly, resolvedPath, dir)
+	}
+	return nil
+}
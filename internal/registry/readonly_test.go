@@ -0,0 +1,71 @@
+package registry
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/portertech/filesystem-mcp-server/internal/grant"
+)
+
+func TestReadOnlyAndGrants(t *testing.T) {
+	tmpDir := t.TempDir()
+	docs := filepath.Join(tmpDir, "docs")
+	work := filepath.Join(tmpDir, "work")
+	for _, d := range []string{docs, work} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	r := New([]string{docs, work}, logger)
+
+	if err := r.SetReadOnly([]string{filepath.Join(tmpDir, "elsewhere")}); err == nil {
+		t.Error("expected error for a directory that is not allowed")
+	}
+	if err := r.SetReadOnly([]string{docs}); err != nil {
+		t.Fatal(err)
+	}
+	docs = r.Get()[0]
+
+	if err := r.CheckWrite(filepath.Join(work, "a.txt")); err != nil {
+		t.Errorf("writable directory refused: %v", err)
+	}
+	target := filepath.Join(docs, "a.txt")
+	if err := r.CheckWrite(target); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("CheckWrite() = %v, want ErrReadOnly", err)
+	}
+
+	key := []byte(strings.Repeat("k", grant.MinKeyLength))
+	token, _, err := grant.Issue(key, docs, time.Hour, 1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ActivateGrant(token); err == nil {
+		t.Error("expected error when grants are disabled")
+	}
+	r.SetGrants(grant.NewManager(key))
+
+	workToken, _, err := grant.Issue(key, r.Get()[1], time.Hour, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ActivateGrant(workToken); err == nil {
+		t.Error("expected error for a grant on a writable directory")
+	}
+
+	if _, err := r.ActivateGrant(token); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.CheckWrite(target); err != nil {
+		t.Errorf("granted write refused: %v", err)
+	}
+	if err := r.CheckWrite(target); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("write after the grant was used up: got %v", err)
+	}
+}
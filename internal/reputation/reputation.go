@@ -0,0 +1,148 @@
+// Package reputation checks file digests against local lists of known-bad
+// and known-good hashes, such as indicator lists shared during incident
+// response. Lists are read once at startup and never leave the machine.
+package reputation
+
+import (
+	"bufio"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Verdict is what the lists say about a file.
+type Verdict string
+
+const (
+	Unknown   Verdict = "unknown"
+	KnownBad  Verdict = "known-bad"
+	KnownGood Verdict = "known-good"
+)
+
+// Digests holds the hashes of a file in every supported algorithm, so lists
+// may use whichever their source publishes.
+type Digests struct {
+	MD5    string `json:"md5"`
+	SHA1   string `json:"sha1"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// Match is the result of looking up a file's digests.
+type Match struct {
+	Verdict Verdict `json:"verdict"`
+	// Hash is the listed digest that matched.
+	Hash string `json:"hash,omitempty"`
+	// Label is the text following the hash on its list line, if any.
+	Label string `json:"label,omitempty"`
+}
+
+// Lists holds the denylist and allowlist, keyed by lowercase hex digest.
+type Lists struct {
+	deny  map[string]string
+	allow map[string]string
+}
+
+// Load reads the denylist and allowlist files. Either path may be empty.
+func Load(denyPath, allowPath string) (*Lists, error) {
+	l := &Lists{deny: map[string]string{}, allow: map[string]string{}}
+	for _, list := range []struct {
+		path    string
+		entries map[string]string
+	}{{denyPath, l.deny}, {allowPath, l.allow}} {
+		if list.path == "" {
+			continue
+		}
+		f, err := os.Open(list.path)
+		if err != nil {
+			return nil, err
+		}
+		err = parse(f, list.entries)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", list.path, err)
+		}
+	}
+	return l, nil
+}
+
+// parse reads one hash per line, optionally followed by whitespace and a
+// label, into entries. This accepts sha256sum-style output as well as plain
+// indicator lists. Blank lines and lines starting with # are skipped.
+func parse(r io.Reader, entries map[string]string) error {
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		hash, label := line, ""
+		if i := strings.IndexAny(line, " \t"); i >= 0 {
+			hash, label = line[:i], line[i+1:]
+		}
+		hash = strings.ToLower(hash)
+		if _, err := hex.DecodeString(hash); err != nil || (len(hash) != 32 && len(hash) != 40 && len(hash) != 64) {
+			return fmt.Errorf("line %d: %q is not an MD5, SHA-1, or SHA-256 hex digest", lineNum, hash)
+		}
+		entries[hash] = strings.TrimLeft(strings.TrimSpace(label), "*")
+	}
+	return scanner.Err()
+}
+
+// Len returns the number of denylisted and allowlisted hashes.
+func (l *Lists) Len() (deny, allow int) {
+	return len(l.deny), len(l.allow)
+}
+
+// Lookup reports whether any of the digests is listed. The denylist wins
+// when a file is on both lists.
+func (l *Lists) Lookup(d Digests) Match {
+	for _, h := range []string{d.SHA256, d.SHA1, d.MD5} {
+		if label, ok := l.deny[h]; ok {
+			return Match{Verdict: KnownBad, Hash: h, Label: label}
+		}
+	}
+	for _, h := range []string{d.SHA256, d.SHA1, d.MD5} {
+		if label, ok := l.allow[h]; ok {
+			return Match{Verdict: KnownGood, Hash: h, Label: label}
+		}
+	}
+	return Match{Verdict: Unknown}
+}
+
+// Check hashes the file at path and looks it up.
+func (l *Lists) Check(path string) (Match, Digests, error) {
+	d, err := HashFile(path)
+	if err != nil {
+		return Match{}, Digests{}, err
+	}
+	return l.Lookup(d), d, nil
+}
+
+// HashFile computes the digests of the file at path in a single pass.
+func HashFile(path string) (Digests, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Digests{}, err
+	}
+	defer f.Close()
+
+	md5Hash, sha1Hash, sha256Hash := md5.New(), sha1.New(), sha256.New()
+	n, err := io.Copy(io.MultiWriter(md5Hash, sha1Hash, sha256Hash), f)
+	if err != nil {
+		return Digests{}, err
+	}
+	return Digests{
+		MD5:    hex.EncodeToString(md5Hash.Sum(nil)),
+		SHA1:   hex.EncodeToString(sha1Hash.Sum(nil)),
+		SHA256: hex.EncodeToString(sha256Hash.Sum(nil)),
+		Size:   n,
+	}, nil
+}
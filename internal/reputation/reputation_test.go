@@ -0,0 +1,85 @@
+package reputation
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Digests of "hello\n".
+const (
+	helloMD5    = "b1946ac92492d2347c6235b4d2611184"
+	helloSHA1   = "f572d396fae9206628714fb2ce00f72e94f2258f"
+	helloSHA256 = "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"
+)
+
+func TestHashFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hello.txt")
+	if err := os.WriteFile(path, []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	d, err := HashFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Digests{MD5: helloMD5, SHA1: helloSHA1, SHA256: helloSHA256, Size: 6}
+	if d != want {
+		t.Errorf("HashFile() = %+v, want %+v", d, want)
+	}
+}
+
+func TestParse(t *testing.T) {
+	input := `# indicators
+` + strings.ToUpper(helloSHA256) + `  *dropper.exe
+
+` + helloMD5 + "\tEvil-Family\n"
+	entries := map[string]string{}
+	if err := parse(strings.NewReader(input), entries); err != nil {
+		t.Fatal(err)
+	}
+	if entries[helloSHA256] != "dropper.exe" || entries[helloMD5] != "Evil-Family" || len(entries) != 2 {
+		t.Errorf("unexpected entries: %v", entries)
+	}
+
+	for _, bad := range []string{"nothex\n", "abcd\n", helloSHA256 + "0\n"} {
+		if err := parse(strings.NewReader(bad), map[string]string{}); err == nil {
+			t.Errorf("parse(%q) expected error", bad)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	dir := t.TempDir()
+	deny := filepath.Join(dir, "deny.txt")
+	allow := filepath.Join(dir, "allow.txt")
+	if err := os.WriteFile(deny, []byte(helloSHA1+" known bad\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(allow, []byte(helloSHA256+"\n"+strings.Repeat("a", 64)+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := Load(deny, allow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, a := l.Len(); d != 1 || a != 2 {
+		t.Errorf("Len() = %d, %d", d, a)
+	}
+
+	hello := Digests{MD5: helloMD5, SHA1: helloSHA1, SHA256: helloSHA256}
+	if m := l.Lookup(hello); m.Verdict != KnownBad || m.Hash != helloSHA1 || m.Label != "known bad" {
+		t.Errorf("denylist should win, got %+v", m)
+	}
+	if m := l.Lookup(Digests{SHA256: strings.Repeat("a", 64)}); m.Verdict != KnownGood {
+		t.Errorf("expected known-good, got %+v", m)
+	}
+	if m := l.Lookup(Digests{SHA256: strings.Repeat("b", 64)}); m.Verdict != Unknown {
+		t.Errorf("expected unknown, got %+v", m)
+	}
+
+	if _, err := Load(filepath.Join(dir, "missing.txt"), ""); err == nil {
+		t.Error("expected error for missing list")
+	}
+}
@@ -0,0 +1,78 @@
+// Package retention decides which recovery data, such as trashed files and
+// journal snapshots, to drop so that it does not grow without bound, and
+// runs the pruning in the background.
+package retention
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Policy bounds how much recovery data is kept. A zero field is no limit.
+type Policy struct {
+	// MaxAge drops items older than this.
+	MaxAge time.Duration
+	// MaxBytes drops the oldest items until the rest fit.
+	MaxBytes int64
+}
+
+// Enabled reports whether the policy limits anything.
+func (p Policy) Enabled() bool {
+	return p.MaxAge > 0 || p.MaxBytes > 0
+}
+
+// Item is the time and size of one piece of recovery data.
+type Item struct {
+	Time time.Time
+	Size int64
+}
+
+// Expired reports, for each item, whether the policy drops it at now:
+// items older than MaxAge, then the oldest of the rest until their total
+// size is within MaxBytes.
+func (p Policy) Expired(now time.Time, items []Item) []bool {
+	expired := make([]bool, len(items))
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return items[order[a]].Time.Before(items[order[b]].Time)
+	})
+
+	var total int64
+	for _, i := range order {
+		if p.MaxAge > 0 && now.Sub(items[i].Time) > p.MaxAge {
+			expired[i] = true
+			continue
+		}
+		total += items[i].Size
+	}
+	for _, i := range order {
+		if p.MaxBytes <= 0 || total <= p.MaxBytes {
+			break
+		}
+		if !expired[i] {
+			expired[i] = true
+			total -= items[i].Size
+		}
+	}
+	return expired
+}
+
+// Run calls prune now and then every interval until ctx is done.
+func Run(ctx context.Context, interval time.Duration, prune func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	prune()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			prune()
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package retention
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpired(t *testing.T) {
+	now := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	items := []Item{
+		{Time: now.Add(-1 * day), Size: 40},
+		{Time: now.Add(-9 * day), Size: 10},
+		{Time: now.Add(-3 * day), Size: 30},
+		{Time: now.Add(-2 * day), Size: 50},
+	}
+
+	for _, tt := range []struct {
+		name   string
+		policy Policy
+		want   []bool
+	}{
+		{"none", Policy{}, []bool{false, false, false, false}},
+		{"age", Policy{MaxAge: 7 * day}, []bool{false, true, false, false}},
+		{"size", Policy{MaxBytes: 100}, []bool{false, true, true, false}},
+		{"both", Policy{MaxAge: 7 * day, MaxBytes: 90}, []bool{false, true, true, false}},
+		{"tiny", Policy{MaxBytes: 1}, []bool{true, true, true, true}},
+	} {
+		got := tt.policy.Expired(now, items)
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
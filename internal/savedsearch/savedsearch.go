@@ -0,0 +1,141 @@
+// Package savedsearch stores named search definitions so recurring searches
+// can be re-run with one short call. Searches are kept in a JSON state file
+// when a path is configured, and in memory otherwise.
+package savedsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxNameLength is the longest search name accepted, in bytes.
+const MaxNameLength = 100
+
+// ErrNotFound is returned for a name with no saved search.
+var ErrNotFound = errors.New("saved search not found")
+
+// Search is a saved search definition.
+type Search struct {
+	Name            string    `json:"name"`
+	Path            string    `json:"path"`
+	Patterns        []string  `json:"patterns,omitempty"`
+	ExcludePatterns []string  `json:"excludePatterns,omitempty"`
+	ContentPattern  string    `json:"contentPattern,omitempty"`
+	IgnoreCase      bool      `json:"ignoreCase,omitempty"`
+	Saved           time.Time `json:"saved"`
+}
+
+// Store holds saved searches keyed by name.
+type Store struct {
+	mu       sync.Mutex
+	path     string
+	searches map[string]Search
+}
+
+// NewStore creates a store persisting to path, loading any searches already
+// stored there. An empty path keeps searches in memory only.
+func NewStore(path string) (*Store, error) {
+	s := &Store{
+		path:     path,
+		searches: make(map[string]Search),
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.searches); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Save stores search under its name, replacing any search of that name.
+func (s *Store) Save(search Search) (Search, error) {
+	search.Name = strings.TrimSpace(search.Name)
+	if search.Name == "" {
+		return Search{}, errors.New("name is required")
+	}
+	if len(search.Name) > MaxNameLength {
+		return Search{}, fmt.Errorf("name exceeds %d bytes", MaxNameLength)
+	}
+	search.Saved = time.Now().UTC()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.searches[search.Name] = search
+	return search, s.saveLocked()
+}
+
+// Get returns the search saved under name.
+func (s *Store) Get(name string) (Search, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	search, ok := s.searches[strings.TrimSpace(name)]
+	if !ok {
+		return Search{}, ErrNotFound
+	}
+	return search, nil
+}
+
+// Delete removes the search saved under name.
+func (s *Store) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name = strings.TrimSpace(name)
+	if _, ok := s.searches[name]; !ok {
+		return ErrNotFound
+	}
+	delete(s.searches, name)
+	return s.saveLocked()
+}
+
+// List returns all saved searches sorted by name.
+func (s *Store) List() []Search {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]Search, 0, len(s.searches))
+	for _, search := range s.searches {
+		result = append(result, search)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// saveLocked writes the state file atomically.
+func (s *Store) saveLocked() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(s.searches)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,73 @@
+package savedsearch
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStorePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "searches.json")
+
+	s, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Save(Search{Name: " todos ", Path: "/src", ContentPattern: "TODO"}); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	search, err := reopened.Get("todos")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if search.Path != "/src" || search.ContentPattern != "TODO" || search.Saved.IsZero() {
+		t.Errorf("unexpected search: %+v", search)
+	}
+
+	if err := reopened.Delete("todos"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reopened.Get("todos"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+	if err := reopened.Delete("todos"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+	}
+}
+
+func TestStoreSaveValidatesName(t *testing.T) {
+	s, _ := NewStore("")
+	if _, err := s.Save(Search{Name: "  "}); err == nil {
+		t.Error("expected error for empty name")
+	}
+	if _, err := s.Save(Search{Name: strings.Repeat("n", MaxNameLength+1)}); err == nil {
+		t.Error("expected error for long name")
+	}
+}
+
+func TestStoreList(t *testing.T) {
+	s, _ := NewStore("")
+	for _, name := range []string{"b", "a", "c"} {
+		if _, err := s.Save(Search{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Saving an existing name replaces it
+	if _, err := s.Save(Search{Name: "a", Path: "/new"}); err != nil {
+		t.Fatal(err)
+	}
+
+	list := s.List()
+	if len(list) != 3 || list[0].Name != "a" || list[1].Name != "b" || list[2].Name != "c" {
+		t.Fatalf("unexpected list: %+v", list)
+	}
+	if list[0].Path != "/new" {
+		t.Errorf("expected replaced search, got %+v", list[0])
+	}
+}
@@ -0,0 +1,171 @@
+// Package scan runs files through an external content scanner, such as an
+// antivirus engine, before their data is handed to an agent. Scanners are
+// either a command run on the file or a ClamAV daemon reached over its
+// socket.
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// chunkSize is the size of the chunks streamed to clamd.
+const chunkSize = 64 * 1024
+
+// Verdict is the outcome of scanning a file.
+type Verdict struct {
+	Infected bool `json:"infected"`
+	// Signature names what the scanner detected, when it says.
+	Signature string `json:"signature,omitempty"`
+}
+
+// Scanner scans files for malicious content.
+type Scanner interface {
+	Scan(ctx context.Context, path string) (Verdict, error)
+}
+
+// Command scans files by running a program with the file's path as its last
+// argument. Following clamscan, exit status 0 means clean and 1 means
+// infected; any other status is an error. The last line of output on
+// detection is taken as the signature.
+type Command struct {
+	Name string
+	Args []string
+}
+
+// NewCommand parses a command line such as "clamscan --no-summary" into a
+// Command. Arguments are split on whitespace; quoting is not supported.
+func NewCommand(commandLine string) (*Command, error) {
+	fields := strings.Fields(commandLine)
+	if len(fields) == 0 {
+		return nil, errors.New("empty scanner command")
+	}
+	return &Command{Name: fields[0], Args: fields[1:]}, nil
+}
+
+// Scan runs the command on path.
+func (c *Command) Scan(ctx context.Context, path string) (Verdict, error) {
+	args := append(append([]string{}, c.Args...), path)
+	cmd := exec.CommandContext(ctx, c.Name, args...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	err := cmd.Run()
+	if err == nil {
+		return Verdict{}, nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return Verdict{Infected: true, Signature: signature(out.String(), path)}, nil
+	}
+	if msg := strings.TrimSpace(out.String()); msg != "" {
+		return Verdict{}, fmt.Errorf("scanner %s: %w: %s", c.Name, err, msg)
+	}
+	return Verdict{}, fmt.Errorf("scanner %s: %w", c.Name, err)
+}
+
+// signature extracts the detection name from scanner output of the form
+// "<path>: <signature> FOUND", falling back to the last line as is.
+func signature(output, path string) string {
+	var last string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			last = line
+		}
+	}
+	last = strings.TrimPrefix(last, path+": ")
+	return strings.TrimSuffix(last, " FOUND")
+}
+
+// Clamd scans files by streaming them to a ClamAV daemon with the INSTREAM
+// command, so the daemon does not need access to the file itself.
+type Clamd struct {
+	// Network is "unix" or "tcp".
+	Network string
+	Address string
+}
+
+// NewClamd parses a clamd address given as unix:/path/to/clamd.sock or
+// tcp:host:port.
+func NewClamd(address string) (*Clamd, error) {
+	network, addr, ok := strings.Cut(address, ":")
+	if !ok || addr == "" || (network != "unix" && network != "tcp") {
+		return nil, fmt.Errorf("invalid clamd address %q: expected unix:/path or tcp:host:port", address)
+	}
+	return &Clamd{Network: network, Address: addr}, nil
+}
+
+// Scan streams the file at path to clamd and parses its reply.
+func (c *Clamd) Scan(ctx context.Context, path string) (Verdict, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Verdict{}, err
+	}
+	defer f.Close()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, c.Network, c.Address)
+	if err != nil {
+		return Verdict{}, fmt.Errorf("failed to connect to clamd: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if _, err := io.WriteString(conn, "zINSTREAM\x00"); err != nil {
+		return Verdict{}, fmt.Errorf("failed to send to clamd: %w", err)
+	}
+	buf := make([]byte, 4+chunkSize)
+	for {
+		n, err := f.Read(buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf[:4], uint32(n))
+			if _, werr := conn.Write(buf[:4+n]); werr != nil {
+				return Verdict{}, fmt.Errorf("failed to send to clamd: %w", werr)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Verdict{}, err
+		}
+	}
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return Verdict{}, fmt.Errorf("failed to send to clamd: %w", err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && reply == "" {
+		return Verdict{}, fmt.Errorf("failed to read clamd reply: %w", err)
+	}
+	return parseClamdReply(strings.TrimRight(reply, "\x00\n"))
+}
+
+// parseClamdReply interprets a reply such as "stream: OK" or
+// "stream: Eicar-Signature FOUND".
+func parseClamdReply(reply string) (Verdict, error) {
+	result := reply
+	if _, after, ok := strings.Cut(reply, ": "); ok {
+		result = after
+	}
+	switch {
+	case result == "OK":
+		return Verdict{}, nil
+	case strings.HasSuffix(result, " FOUND"):
+		return Verdict{Infected: true, Signature: strings.TrimSuffix(result, " FOUND")}, nil
+	default:
+		return Verdict{}, fmt.Errorf("clamd: %s", reply)
+	}
+}
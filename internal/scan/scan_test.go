@@ -0,0 +1,152 @@
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func writeScript(t *testing.T, body string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not supported on Windows")
+	}
+	path := filepath.Join(t.TempDir(), "scanner.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCommandScan(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "image.png")
+	if err := os.WriteFile(file, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		script   string
+		infected bool
+		sig      string
+		wantErr  bool
+	}{
+		{name: "clean", script: "exit 0\n"},
+		{name: "infected", script: "echo \"$1: Eicar-Test-Signature FOUND\"\nexit 1\n", infected: true, sig: "Eicar-Test-Signature"},
+		{name: "error", script: "echo 'cannot open' >&2\nexit 2\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewCommand(writeScript(t, tt.script))
+			if err != nil {
+				t.Fatal(err)
+			}
+			v, err := c.Scan(context.Background(), file)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Scan() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if v.Infected != tt.infected || v.Signature != tt.sig {
+				t.Errorf("Scan() = %+v, want infected %v signature %q", v, tt.infected, tt.sig)
+			}
+		})
+	}
+}
+
+func TestNewCommandEmpty(t *testing.T) {
+	if _, err := NewCommand("  "); err == nil {
+		t.Error("expected error for empty command")
+	}
+}
+
+func TestNewClamd(t *testing.T) {
+	for _, addr := range []string{"unix:/run/clamd.sock", "tcp:127.0.0.1:3310"} {
+		if _, err := NewClamd(addr); err != nil {
+			t.Errorf("NewClamd(%q) error = %v", addr, err)
+		}
+	}
+	for _, addr := range []string{"", "udp:host:1", "unix:", "/run/clamd.sock"} {
+		if _, err := NewClamd(addr); err == nil {
+			t.Errorf("NewClamd(%q) expected error", addr)
+		}
+	}
+}
+
+// fakeClamd accepts one INSTREAM session and replies FOUND if the streamed
+// data contains marker.
+func fakeClamd(t *testing.T, marker string) *Clamd {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		if cmd, err := r.ReadString(0); err != nil || cmd != "zINSTREAM\x00" {
+			return
+		}
+		var data bytes.Buffer
+		for {
+			var size uint32
+			if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+				return
+			}
+			if size == 0 {
+				break
+			}
+			if _, err := io.CopyN(&data, r, int64(size)); err != nil {
+				return
+			}
+		}
+		if bytes.Contains(data.Bytes(), []byte(marker)) {
+			io.WriteString(conn, "stream: Eicar-Test-Signature FOUND\x00")
+		} else {
+			io.WriteString(conn, "stream: OK\x00")
+		}
+	}()
+
+	return &Clamd{Network: "tcp", Address: l.Addr().String()}
+}
+
+func TestClamdScan(t *testing.T) {
+	dir := t.TempDir()
+	clean := filepath.Join(dir, "clean.bin")
+	infected := filepath.Join(dir, "infected.bin")
+	if err := os.WriteFile(clean, bytes.Repeat([]byte("a"), chunkSize*2+10), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(infected, []byte("xxEICARxx"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := fakeClamd(t, "EICAR").Scan(context.Background(), clean)
+	if err != nil || v.Infected {
+		t.Errorf("clean file: got %+v, %v", v, err)
+	}
+	v, err = fakeClamd(t, "EICAR").Scan(context.Background(), infected)
+	if err != nil || !v.Infected || v.Signature != "Eicar-Test-Signature" {
+		t.Errorf("infected file: got %+v, %v", v, err)
+	}
+}
+
+func TestParseClamdReply(t *testing.T) {
+	if _, err := parseClamdReply("INSTREAM size limit exceeded. ERROR"); err == nil {
+		t.Error("expected error reply to fail")
+	}
+	if v, err := parseClamdReply("stream: OK"); err != nil || v.Infected {
+		t.Errorf("got %+v, %v", v, err)
+	}
+}
@@ -43,6 +43,9 @@ func ValidatePathWithResolved(path string, allowedDirs []string, resolvedAllowed
 	if err != nil {
 		return "", err
 	}
+	if err := pathutil.CheckPathLength(normalizedPath); err != nil {
+		return "", err
+	}
 
 	// For existing paths, resolve symlinks
 	resolvedPath := normalizedPath
@@ -100,6 +103,9 @@ func ValidateFinalPath(path string, allowedDirs []string) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if err := pathutil.CheckPathLength(normalizedPath); err != nil {
+		return "", err
+	}
 
 	info, err := os.Lstat(normalizedPath)
 	if err != nil {
@@ -137,6 +143,9 @@ func ValidateFinalPathForCreation(path string, allowedDirs []string) (string, er
 	if err != nil {
 		return "", err
 	}
+	if err := pathutil.CheckPathLength(normalizedPath); err != nil {
+		return "", err
+	}
 
 	if info, err := os.Lstat(normalizedPath); err == nil {
 		if info.Mode()&os.ModeSymlink != 0 {
@@ -311,6 +320,9 @@ func ValidatePathForCreationWithResolved(path string, allowedDirs []string, reso
 	if err != nil {
 		return "", err
 	}
+	if err := pathutil.CheckPathLength(normalizedPath); err != nil {
+		return "", err
+	}
 
 	// For new files, we need to validate based on where they would be created
 	// Walk up the path to find the first existing parent
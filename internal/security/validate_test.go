@@ -1,9 +1,13 @@
 package security
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/portertech/filesystem-mcp-server/internal/pathutil"
 )
 
 func TestValidatePath(t *testing.T) {
@@ -39,6 +43,8 @@ func TestValidatePath(t *testing.T) {
 		{"parent traversal", filepath.Join(allowedDir, "..", "disallowed", "test.txt"), ErrPathOutsideAllowed},
 		{"empty path", "", ErrEmptyPath},
 		{"null byte", "test\x00.txt", ErrNullByte},
+		{"long component", filepath.Join(allowedDir, strings.Repeat("a", 300)), pathutil.ErrPathTooLong},
+		{"long path", filepath.Join(allowedDir, strings.Repeat("a/", 3000)), pathutil.ErrPathTooLong},
 	}
 
 	for _, tt := range tests {
@@ -47,7 +53,7 @@ func TestValidatePath(t *testing.T) {
 			if tt.expectError != nil {
 				if err == nil {
 					t.Errorf("expected error %v, got nil", tt.expectError)
-				} else if err != tt.expectError && err.Error() != tt.expectError.Error() {
+				} else if !errors.Is(err, tt.expectError) {
 					t.Errorf("expected error %v, got %v", tt.expectError, err)
 				}
 			} else if err != nil {
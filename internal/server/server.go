@@ -2,31 +2,144 @@ package server
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"path/filepath"
+	"sort"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/portertech/filesystem-mcp-server/internal/annotation"
+	"github.com/portertech/filesystem-mcp-server/internal/bookmark"
+	"github.com/portertech/filesystem-mcp-server/internal/buffer"
+	"github.com/portertech/filesystem-mcp-server/internal/confirm"
+	"github.com/portertech/filesystem-mcp-server/internal/filelock"
+	"github.com/portertech/filesystem-mcp-server/internal/idempotency"
+	"github.com/portertech/filesystem-mcp-server/internal/oplog"
+	"github.com/portertech/filesystem-mcp-server/internal/proposal"
 	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/retention"
+	"github.com/portertech/filesystem-mcp-server/internal/savedsearch"
 	"github.com/portertech/filesystem-mcp-server/internal/tools"
+	"github.com/portertech/filesystem-mcp-server/internal/usage"
+	"github.com/spf13/cast"
 )
 
 // Server wraps the MCP server with filesystem tools.
 type Server struct {
-	mcpServer *server.MCPServer
-	registry  *registry.Registry
-	logger    *slog.Logger
+	mcpServer   *server.MCPServer
+	registry    *registry.Registry
+	proposals   *proposal.Store
+	buffers     *buffer.Store
+	usage       *usage.Tracker
+	annotations *annotation.Store
+	searches    *savedsearch.Store
+	bookmarks   *bookmark.Store
+	idempotency *idempotency.Store
+	operations  *oplog.Log
+	tools       map[string]registeredTool
+	logger      *slog.Logger
+	toolCount   int
+
+	stateDir      string
+	usageInterval time.Duration
+	pruneInterval time.Duration
+	version       string
+	logLevel      *slog.LevelVar
+	// enabled limits the tools registered, if set.
+	enabled map[string]bool
+}
+
+// registeredTool is a tool as registered, with its wrapped handler.
+type registeredTool struct {
+	tool    mcp.Tool
+	handler server.ToolHandlerFunc
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithStateDir sets the directory where the server persists state between
+// runs, which statedir.Open has migrated to the current layout. Without
+// it, state is kept in memory only.
+func WithStateDir(dir string) Option {
+	return func(s *Server) {
+		s.stateDir = dir
+	}
+}
+
+// WithUsageInterval sets how often disk usage of the allowed directories is
+// sampled. Zero disables background sampling.
+func WithUsageInterval(d time.Duration) Option {
+	return func(s *Server) {
+		s.usageInterval = d
+	}
+}
+
+// WithPruneInterval sets how often recovery data outside its retention
+// policy is pruned. Zero disables background pruning.
+func WithPruneInterval(d time.Duration) Option {
+	return func(s *Server) {
+		s.pruneInterval = d
+	}
+}
+
+// WithVersion sets the version reported to clients.
+func WithVersion(version string) Option {
+	return func(s *Server) {
+		s.version = version
+	}
+}
+
+// WithLogLevel lets clients change level, the level of the server's
+// logger, by registering the set_log_level tool.
+func WithLogLevel(level *slog.LevelVar) Option {
+	return func(s *Server) {
+		s.logLevel = level
+	}
+}
+
+// WithTools registers only the named tools. Names of tools that do not
+// exist or are not available in this configuration are ignored; compare
+// with ToolNames to find them.
+func WithTools(names ...string) Option {
+	return func(s *Server) {
+		s.enabled = make(map[string]bool, len(names))
+		for _, name := range names {
+			s.enabled[name] = true
+		}
+	}
 }
 
 // New creates a new filesystem MCP server.
-func New(reg *registry.Registry, logger *slog.Logger) *Server {
+func New(reg *registry.Registry, logger *slog.Logger, opts ...Option) *Server {
 	s := &Server{
-		registry: reg,
-		logger:   logger,
+		registry:    reg,
+		proposals:   proposal.NewStore(proposal.DefaultTTL),
+		buffers:     buffer.NewStore(buffer.DefaultTTL, buffer.DefaultMaxBytes, buffer.DefaultMaxTotalBytes),
+		idempotency: idempotency.New(idempotency.DefaultTTL),
+		operations:  oplog.New(oplog.DefaultMaxOperations),
+		tools:       make(map[string]registeredTool),
+		logger:      logger,
+		version:     "1.0.0",
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.usage = s.newUsageTracker()
+	s.annotations = s.newAnnotationStore()
+	s.searches = s.newSavedSearchStore()
+	s.bookmarks = s.newBookmarkStore()
+	reg.SetBookmarks(s.bookmarks)
+	if filelock.Supported && reg.Locks() == nil {
+		reg.SetLocks(filelock.NewManager())
 	}
 
 	mcpServer := server.NewMCPServer(
-		"filesystem-mcp-server",
-		"1.0.0",
+		tools.ServerName,
+		s.version,
 		server.WithLogging(),
 	)
 
@@ -39,95 +152,263 @@ func New(reg *registry.Registry, logger *slog.Logger) *Server {
 // registerTools registers all filesystem tools with the MCP server.
 func (s *Server) registerTools() {
 	// Read tools
-	s.mcpServer.AddTool(
+	s.addTool(
 		tools.NewReadTextFileTool(s.registry),
 		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			return tools.HandleReadTextFile(ctx, s.registry, req)
 		},
 	)
 
-	s.mcpServer.AddTool(
+	s.addTool(
 		tools.NewReadFileTool(s.registry),
 		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			return tools.HandleReadFile(ctx, s.registry, req)
 		},
 	)
 
-	s.mcpServer.AddTool(
+	s.addTool(
 		tools.NewReadMultipleFilesTool(s.registry),
 		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			return tools.HandleReadMultipleFiles(ctx, s.registry, req)
 		},
 	)
 
-	s.mcpServer.AddTool(
+	s.addTool(
 		tools.NewReadMediaFileTool(s.registry),
 		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			return tools.HandleReadMediaFile(ctx, s.registry, req)
 		},
 	)
 
+	s.addTool(
+		tools.NewReadFileBytesTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleReadFileBytes(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewJSONQueryTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleJSONQuery(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewCSVPreviewTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleCSVPreview(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewValidateFileTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleValidateFile(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewTailFollowTool(),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleTailFollow(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewDiffFilesTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleDiffFiles(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewListArchiveTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleListArchive(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewGetImageInfoTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleGetImageInfo(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewFontInfoTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleFontInfo(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewSanitizeSVGTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleSanitizeSVG(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewRasterizeSVGTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleRasterizeSVG(ctx, s.registry, req)
+		},
+	)
+
 	// Write tools
-	s.mcpServer.AddTool(
+	s.addTool(
 		tools.NewWriteFileTool(s.registry),
 		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			return tools.HandleWriteFile(ctx, s.registry, req)
 		},
 	)
 
-	s.mcpServer.AddTool(
+	s.addTool(
+		tools.NewWriteFileRangeTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleWriteFileRange(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewWriteMediaFileTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleWriteMediaFile(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
 		tools.NewEditFileTool(s.registry),
 		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			return tools.HandleEditFile(ctx, s.registry, req)
 		},
 	)
 
+	s.addTool(
+		tools.NewEditLinesTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleEditLines(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewFilterFileTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleFilterFile(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewApplyPatchTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleApplyPatch(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewTouchFileTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleTouchFile(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewCreateFromTemplateTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleCreateFromTemplate(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewCreateSymlinkTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleCreateSymlink(ctx, s.registry, req)
+		},
+	)
+
 	// Copy tool
-	s.mcpServer.AddTool(
+	s.addTool(
 		tools.NewCopyFileTool(s.registry),
 		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			return tools.HandleCopyFile(ctx, s.registry, req)
 		},
 	)
 
+	s.addTool(
+		tools.NewCopyDirectoryTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleCopyDirectory(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewConvertFileTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleConvertFile(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewMergeListsTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleMergeLists(ctx, s.registry, req)
+		},
+	)
+
 	// Delete tools
-	s.mcpServer.AddTool(
+	s.addTool(
 		tools.NewDeleteFileTool(s.registry),
 		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			return tools.HandleDeleteFile(ctx, s.registry, req)
 		},
 	)
 
-	s.mcpServer.AddTool(
+	s.addTool(
 		tools.NewDeleteDirectoryTool(s.registry),
 		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			return tools.HandleDeleteDirectory(ctx, s.registry, req)
 		},
 	)
 
+	s.addTool(
+		tools.NewCleanupOldFilesTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleCleanupOldFiles(ctx, s.registry, req)
+		},
+	)
+
 	// Directory tools
-	s.mcpServer.AddTool(
+	s.addTool(
 		tools.NewCreateDirectoryTool(s.registry),
 		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			return tools.HandleCreateDirectory(ctx, s.registry, req)
 		},
 	)
 
-	s.mcpServer.AddTool(
+	s.addTool(
 		tools.NewListDirectoryTool(s.registry),
 		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			return tools.HandleListDirectory(ctx, s.registry, req)
 		},
 	)
 
-	s.mcpServer.AddTool(
+	s.addTool(
 		tools.NewListDirectoryWithSizesTool(s.registry),
 		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			return tools.HandleListDirectoryWithSizes(ctx, s.registry, req)
 		},
 	)
 
-	s.mcpServer.AddTool(
+	s.addTool(
+		tools.NewDiskUsageTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleDiskUsage(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
 		tools.NewDirectoryTreeTool(s.registry),
 		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			return tools.HandleDirectoryTree(ctx, s.registry, req)
@@ -135,45 +416,675 @@ func (s *Server) registerTools() {
 	)
 
 	// Move tool
-	s.mcpServer.AddTool(
+	s.addTool(
 		tools.NewMoveFileTool(s.registry),
 		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			return tools.HandleMoveFile(ctx, s.registry, req)
 		},
 	)
 
-	// Search tool
-	s.mcpServer.AddTool(
+	s.addTool(
+		tools.NewReplaceDirectoryTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleReplaceDirectory(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewBulkRenameTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleBulkRename(ctx, s.registry, req)
+		},
+	)
+
+	// Search tools
+	s.addTool(
 		tools.NewSearchFilesTool(s.registry),
 		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			return tools.HandleSearchFiles(ctx, s.registry, req)
 		},
 	)
 
+	s.addTool(
+		tools.NewSearchContentTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleSearchContent(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewFindLargestFilesTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleFindLargestFiles(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewCountLinesTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleCountLines(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewFileStatsTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleFileStats(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewFindDuplicatesTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleFindDuplicates(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewBlameSummaryTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleBlameSummary(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewPackContextTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandlePackContext(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewScanLicensesTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleScanLicenses(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewSummarizeDependenciesTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleSummarizeDependencies(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewAnalyzeIgnoresTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleAnalyzeIgnores(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewLintTextTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleLintText(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewDetectEncodingTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleDetectEncoding(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewFindLongPathsTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleFindLongPaths(ctx, s.registry, req)
+		},
+	)
+
+	// Saved search tools
+	s.addTool(
+		tools.NewSaveSearchTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleSaveSearch(ctx, s.registry, s.searches, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewRunSavedSearchTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleRunSavedSearch(ctx, s.registry, s.searches, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewListSavedSearchesTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleListSavedSearches(ctx, s.registry, s.searches, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewDeleteSavedSearchTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleDeleteSavedSearch(ctx, s.registry, s.searches, req)
+		},
+	)
+
+	// Bookmark tools
+	s.addTool(
+		tools.NewAddBookmarkTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleAddBookmark(ctx, s.registry, s.bookmarks, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewListBookmarksTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleListBookmarks(ctx, s.registry, s.bookmarks, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewRemoveBookmarkTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleRemoveBookmark(ctx, s.registry, s.bookmarks, req)
+		},
+	)
+
 	// Info tools
-	s.mcpServer.AddTool(
+	s.addTool(
 		tools.NewGetFileInfoTool(s.registry),
 		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			return tools.HandleGetFileInfo(ctx, s.registry, req)
 		},
 	)
 
-	s.mcpServer.AddTool(
+	s.addTool(
+		tools.NewReadLinkTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleReadLink(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewHashFileTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleHashFile(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewFileChecksumTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleFileChecksum(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewVerifyChecksumTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleVerifyChecksum(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
 		tools.NewListAllowedDirectoriesTool(s.registry),
 		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			return tools.HandleListAllowedDirectories(ctx, s.registry, req)
 		},
 	)
 
-	s.logger.Info("registered tools", "count", 17)
+	s.addTool(
+		tools.NewSanitizeFilenameTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleSanitizeFilename(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewGetUsageTrendTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleGetUsageTrend(ctx, s.registry, s.usage, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewHealthTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleHealth(ctx, s.registry, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewGetServerInfoTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleGetServerInfo(ctx, s.registry, s.version, s.toolNames(), req)
+		},
+	)
+
+	// Proposal tools
+	s.addTool(
+		tools.NewProposeChangesTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleProposeChanges(ctx, s.registry, s.proposals, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewApproveChangesTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleApproveChanges(ctx, s.registry, s.proposals, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewRejectChangesTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleRejectChanges(ctx, s.registry, s.proposals, req)
+		},
+	)
+
+	// Buffer tools
+	s.addTool(
+		tools.NewCopyToBufferTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleCopyToBuffer(ctx, s.registry, s.buffers, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewPasteFromBufferTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandlePasteFromBuffer(ctx, s.registry, s.buffers, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewExtractRegionTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleExtractRegion(ctx, s.registry, s.buffers, req)
+		},
+	)
+
+	// Annotation tools
+	s.addTool(
+		tools.NewSetAnnotationTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleSetAnnotation(ctx, s.registry, s.annotations, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewGetAnnotationsTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleGetAnnotations(ctx, s.registry, s.annotations, req)
+		},
+	)
+
+	// Operation log tools
+	s.addTool(
+		tools.NewExportOperationsTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleExportOperations(ctx, s.registry, s.operations, req)
+		},
+	)
+
+	// Plan tools
+	s.addTool(
+		tools.NewExecutePlanTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleExecutePlan(ctx, s.registry, s.lookupTool, req)
+		},
+	)
+
+	s.addTool(
+		tools.NewBatchOperationsTool(s.registry),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return tools.HandleBatchOperations(ctx, s.registry, req)
+		},
+	)
+
+	// Overlay tools
+	if s.registry.Overlay() != nil {
+		s.addTool(
+			tools.NewOverlayStatusTool(s.registry),
+			func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return tools.HandleOverlayStatus(ctx, s.registry, req)
+			},
+		)
+
+		s.addTool(
+			tools.NewOverlayDiffTool(s.registry),
+			func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return tools.HandleOverlayDiff(ctx, s.registry, req)
+			},
+		)
+
+		s.addTool(
+			tools.NewOverlayCommitTool(s.registry),
+			func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return tools.HandleOverlayCommit(ctx, s.registry, req)
+			},
+		)
+
+		s.addTool(
+			tools.NewOverlayDiscardTool(s.registry),
+			func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return tools.HandleOverlayDiscard(ctx, s.registry, req)
+			},
+		)
+	}
+
+	// Grant tools
+	if s.registry.Grants() != nil {
+		s.addTool(
+			tools.NewActivateWriteGrantTool(s.registry),
+			func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return tools.HandleActivateWriteGrant(ctx, s.registry, req)
+			},
+		)
+	}
+
+	// Ownership tools
+	if s.registry.AllowChown() {
+		s.addTool(
+			tools.NewChangeOwnerTool(s.registry),
+			func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return tools.HandleChangeOwner(ctx, s.registry, req)
+			},
+		)
+	}
+
+	// Trash tools
+	if s.registry.Trash() != nil {
+		s.addTool(
+			tools.NewListTrashTool(s.registry),
+			func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return tools.HandleListTrash(ctx, s.registry, req)
+			},
+		)
+
+		s.addTool(
+			tools.NewRestoreFromTrashTool(s.registry),
+			func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return tools.HandleRestoreFromTrash(ctx, s.registry, req)
+			},
+		)
+
+		s.addTool(
+			tools.NewEmptyTrashTool(s.registry),
+			func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return tools.HandleEmptyTrash(ctx, s.registry, req)
+			},
+		)
+	}
+
+	// Lock tools
+	if s.registry.Locks() != nil {
+		s.addTool(
+			tools.NewLockFileTool(s.registry),
+			func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return tools.HandleLockFile(ctx, s.registry, req)
+			},
+		)
+
+		s.addTool(
+			tools.NewUnlockFileTool(s.registry),
+			func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return tools.HandleUnlockFile(ctx, s.registry, req)
+			},
+		)
+	}
+
+	// Journal tools
+	if s.registry.Journal() != nil {
+		s.addTool(
+			tools.NewListOperationsTool(s.registry),
+			func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return tools.HandleListOperations(ctx, s.registry, req)
+			},
+		)
+
+		s.addTool(
+			tools.NewUndoOperationTool(s.registry),
+			func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return tools.HandleUndoOperation(ctx, s.registry, req)
+			},
+		)
+	}
+
+	// Network tools
+	if s.registry.Network() != nil {
+		s.addTool(
+			tools.NewFetchToFileTool(s.registry),
+			func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return tools.HandleFetchToFile(ctx, s.registry, req)
+			},
+		)
+
+		s.addTool(
+			tools.NewUploadFileTool(s.registry),
+			func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return tools.HandleUploadFile(ctx, s.registry, req)
+			},
+		)
+	}
+
+	// Logging tools
+	if s.logLevel != nil {
+		s.addTool(
+			tools.NewSetLogLevelTool(),
+			func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return tools.HandleSetLogLevel(ctx, s.logLevel, s.logger, req)
+			},
+		)
+	}
+
+	s.logger.Info("registered tools", "count", s.toolCount)
+}
+
+// newUsageTracker creates the disk usage tracker, falling back to an
+// in-memory tracker if the state file cannot be loaded.
+func (s *Server) newUsageTracker() *usage.Tracker {
+	var path string
+	if s.stateDir != "" {
+		path = filepath.Join(s.stateDir, "usage.json")
+	}
+	tracker, err := usage.NewTracker(path, s.logger)
+	if err != nil {
+		s.logger.Warn("failed to load usage samples, keeping them in memory", "path", path, "error", err)
+		tracker, _ = usage.NewTracker("", s.logger)
+	}
+	return tracker
+}
+
+// newAnnotationStore creates the annotation store, falling back to an
+// in-memory store if the state file cannot be loaded.
+func (s *Server) newAnnotationStore() *annotation.Store {
+	var path string
+	if s.stateDir != "" {
+		path = filepath.Join(s.stateDir, "annotations.json")
+	}
+	store, err := annotation.NewStore(path)
+	if err != nil {
+		s.logger.Warn("failed to load annotations, keeping them in memory", "path", path, "error", err)
+		store, _ = annotation.NewStore("")
+	}
+	return store
+}
+
+// newSavedSearchStore creates the saved search store, falling back to an
+// in-memory store if the state file cannot be loaded.
+func (s *Server) newSavedSearchStore() *savedsearch.Store {
+	var path string
+	if s.stateDir != "" {
+		path = filepath.Join(s.stateDir, "searches.json")
+	}
+	store, err := savedsearch.NewStore(path)
+	if err != nil {
+		s.logger.Warn("failed to load saved searches, keeping them in memory", "path", path, "error", err)
+		store, _ = savedsearch.NewStore("")
+	}
+	return store
+}
+
+// newBookmarkStore creates the bookmark store, falling back to an in-memory
+// store if the state file cannot be loaded.
+func (s *Server) newBookmarkStore() *bookmark.Store {
+	var path string
+	if s.stateDir != "" {
+		path = filepath.Join(s.stateDir, "bookmarks.json")
+	}
+	store, err := bookmark.NewStore(path)
+	if err != nil {
+		s.logger.Warn("failed to load bookmarks, keeping them in memory", "path", path, "error", err)
+		store, _ = bookmark.NewStore("")
+	}
+	return store
+}
+
+// addTool registers a tool with the MCP server and keeps count of them. Tools
+// that take paths get the shared root parameter.
+func (s *Server) addTool(tool mcp.Tool, handler server.ToolHandlerFunc) {
+	if s.enabled != nil && !s.enabled[tool.Name] {
+		return
+	}
+	// Recorded inside root resolution, so the log holds resolved paths
+	if !tools.IsReadOnly(tool) && tool.Name != tools.ExecutePlanTool {
+		handler = s.recorded(tool.Name, handler)
+	}
+	if withRoot, ok := tools.WithRootParameter(tool); ok {
+		tool = withRoot
+		next := handler
+		handler = func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if err := tools.ApplyRoot(s.registry, &req); err != nil {
+				return mcp.NewToolResultError(fmt.Errorf("root resolution failed: %w", err).Error()), nil
+			}
+			return next(ctx, req)
+		}
+	}
+	if withKey, ok := tools.WithIdempotencyKey(tool); ok {
+		tool = withKey
+		handler = s.idempotent(tool.Name, handler)
+	}
+	s.mcpServer.AddTool(tool, handler)
+	s.tools[tool.Name] = registeredTool{tool: tool, handler: handler}
+	s.toolCount++
+}
+
+// lookupTool finds a registered tool by name, for execute_plan.
+func (s *Server) lookupTool(name string) (mcp.Tool, tools.ToolHandler, bool) {
+	t, ok := s.tools[name]
+	if !ok {
+		return mcp.Tool{}, nil, false
+	}
+	return t.tool, tools.ToolHandler(t.handler), true
+}
+
+// toolNames lists the registered tools, for get_server_info.
+func (s *Server) toolNames() []string {
+	names := make([]string, 0, len(s.tools))
+	for name := range s.tools {
+		names = append(names, name)
+	}
+	return names
+}
+
+// ToolNames lists the registered tools, sorted.
+func (s *Server) ToolNames() []string {
+	names := s.toolNames()
+	sort.Strings(names)
+	return names
+}
+
+// Tools returns the registered tools with their handlers, sorted by name,
+// for adding to another MCP server.
+func (s *Server) Tools() []server.ServerTool {
+	var serverTools []server.ServerTool
+	for _, name := range s.ToolNames() {
+		t := s.tools[name]
+		serverTools = append(serverTools, server.ServerTool{Tool: t.tool, Handler: t.handler})
+	}
+	return serverTools
+}
+
+// recorded wraps the handler of a mutating tool so that each call is added
+// to the operations log.
+func (s *Server) recorded(name string, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := next(ctx, req)
+		var failure string
+		switch {
+		case err != nil:
+			failure = err.Error()
+		case result != nil && result.IsError:
+			failure = tools.ContentText(result)
+		}
+		s.operations.Record(name, req.Params.Arguments, failure)
+		return result, err
+	}
+}
+
+// idempotent wraps the handler of a mutating tool so that calls carrying an
+// idempotency key are applied once. Only successful results are remembered,
+// so a failed call can be retried with the same key.
+func (s *Server) idempotent(name string, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		key := cast.ToString(req.Params.Arguments[idempotency.KeyParam])
+		if key == "" {
+			return next(ctx, req)
+		}
+
+		var callErr error
+		result, replayed, err := s.idempotency.Do(key, confirm.Fingerprint(name, req.Params.Arguments), func() (any, bool) {
+			result, err := next(ctx, req)
+			callErr = err
+			return result, err == nil && result != nil && !result.IsError
+		})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("%w: %s", err, key).Error()), nil
+		}
+		if replayed {
+			s.logger.Info("replayed idempotent call", "tool", name, "key", key)
+		}
+		return result.(*mcp.CallToolResult), callErr
+	}
 }
 
 // Run starts the server with stdio transport.
 func (s *Server) Run(ctx context.Context) error {
 	s.logger.Info("starting filesystem MCP server")
+	if s.usageInterval > 0 {
+		go s.usage.Run(ctx, s.registry.Get, s.usageInterval)
+	}
+	if s.pruneInterval > 0 {
+		go retention.Run(ctx, s.pruneInterval, s.prune)
+	}
 	return server.ServeStdio(s.mcpServer)
 }
 
+// prune drops recovery data outside its retention policy.
+func (s *Server) prune() {
+	if t := s.registry.Trash(); t != nil {
+		removed, err := t.Prune(s.registry.GetResolved())
+		if err != nil {
+			s.logger.Warn("failed to prune trash", "error", err)
+		}
+		if len(removed) > 0 {
+			var size int64
+			for _, e := range removed {
+				size += e.Size
+			}
+			s.logger.Info("pruned trash", "entries", len(removed), "bytes", size)
+		}
+	}
+	if j := s.registry.Journal(); j != nil {
+		count, size, err := j.Prune(s.registry.GetResolved())
+		if err != nil {
+			s.logger.Warn("failed to prune undo journal", "error", err)
+		}
+		if count > 0 {
+			s.logger.Info("pruned undo journal", "operations", count, "bytes", size)
+		}
+	}
+}
+
 // GetMCPServer returns the underlying MCP server for testing.
 func (s *Server) GetMCPServer() *server.MCPServer {
 	return s.mcpServer
@@ -1,11 +1,17 @@
 package server
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/idempotency"
 	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/spf13/cast"
 )
 
 func setupTestServer(t *testing.T) (*Server, string) {
@@ -40,3 +46,120 @@ func TestGetMCPServer(t *testing.T) {
 		t.Error("GetMCPServer should return non-nil server")
 	}
 }
+
+func TestIdempotentHandler(t *testing.T) {
+	srv, _ := setupTestServer(t)
+
+	calls := 0
+	handler := srv.idempotent("append", func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls++
+		if cast.ToBool(req.Params.Arguments["fail"]) {
+			return mcp.NewToolResultError("failed"), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("call %d", calls)), nil
+	})
+	call := func(args map[string]any) string {
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = args
+		result, err := handler(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	if got := call(map[string]any{"path": "a"}); got != "call 1" {
+		t.Fatalf("got %q", got)
+	}
+	if got := call(map[string]any{"path": "a"}); got != "call 2" {
+		t.Errorf("calls without a key should always run, got %q", got)
+	}
+
+	args := map[string]any{"path": "a", idempotency.KeyParam: "k1"}
+	if got := call(args); got != "call 3" {
+		t.Fatalf("got %q", got)
+	}
+	if got := call(args); got != "call 3" {
+		t.Errorf("expected retry to return the original result, got %q", got)
+	}
+	if got := call(map[string]any{"path": "b", idempotency.KeyParam: "k1"}); !strings.Contains(got, "already used") {
+		t.Errorf("expected key reuse to be refused, got %q", got)
+	}
+
+	failing := map[string]any{"fail": true, idempotency.KeyParam: "k2"}
+	call(failing)
+	call(failing)
+	if calls != 5 {
+		t.Errorf("expected failed calls not to be remembered, got %d calls", calls)
+	}
+}
+
+func TestRecordedHandler(t *testing.T) {
+	srv, _ := setupTestServer(t)
+
+	handler := srv.recorded("delete_file", func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if cast.ToBool(req.Params.Arguments["fail"]) {
+			return mcp.NewToolResultError("failed"), nil
+		}
+		return mcp.NewToolResultText("ok"), nil
+	})
+	for _, args := range []map[string]any{{"path": "/a"}, {"path": "/b", "fail": true}} {
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = args
+		if _, err := handler(context.Background(), req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ops, _ := srv.operations.Operations()
+	if len(ops) != 2 || ops[0].Tool != "delete_file" || ops[0].Error != "" || ops[1].Error != "failed" {
+		t.Errorf("unexpected operations: %+v", ops)
+	}
+}
+
+func TestGetServerInfo(t *testing.T) {
+	tmpDir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	reg := registry.New([]string{tmpDir}, logger)
+	srv := New(reg, logger, WithVersion("1.2.3"))
+
+	var req mcp.CallToolRequest
+	req.Params.Name = "get_server_info"
+	result, err := srv.tools["get_server_info"].handler(context.Background(), req)
+	if err != nil || result.IsError {
+		t.Fatalf("unexpected error: %v %v", err, result)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{`"version": "1.2.3"`, `"get_server_info"`, `"read_text_file"`, tmpDir} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %s in:\n%s", want, text)
+		}
+	}
+}
+
+func TestSetLogLevelRequiresOption(t *testing.T) {
+	srv, _ := setupTestServer(t)
+	if _, ok := srv.tools["set_log_level"]; ok {
+		t.Error("set_log_level should not be registered by default")
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	srv = New(registry.New([]string{t.TempDir()}, logger), logger, WithLogLevel(new(slog.LevelVar)))
+	if _, ok := srv.tools["set_log_level"]; !ok {
+		t.Error("expected set_log_level with WithLogLevel")
+	}
+}
+
+func TestWithTools(t *testing.T) {
+	tmpDir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	reg := registry.New([]string{tmpDir}, logger)
+	srv := New(reg, logger, WithTools("write_file", "read_text_file", "no_such_tool"))
+
+	if names := srv.ToolNames(); strings.Join(names, ",") != "read_text_file,write_file" {
+		t.Errorf("unexpected tools %v", names)
+	}
+	if tools := srv.Tools(); len(tools) != 2 || tools[0].Tool.Name != "read_text_file" {
+		t.Errorf("unexpected server tools %v", tools)
+	}
+}
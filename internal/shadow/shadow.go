@@ -0,0 +1,164 @@
+// Package shadow keeps the last committed content of recently read or
+// written files, so a read that races with another writer can be served a
+// consistent earlier version instead of a torn one. A read counts as torn
+// when the file's size or modification time changes while it is read.
+package shadow
+
+import (
+	"container/list"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultMaxBytes is the default total size of the shadow copies kept.
+const DefaultMaxBytes = 64 << 20
+
+const (
+	// readAttempts is how many times a read is retried while the file keeps
+	// changing before falling back to the shadow copy.
+	readAttempts = 3
+	// retryDelay gives a writer time to finish between attempts.
+	retryDelay = 50 * time.Millisecond
+)
+
+// Source says which version of a file a read returned.
+type Source string
+
+const (
+	// Current is the file as it is on disk.
+	Current Source = "current"
+	// Shadow is the last committed content, served because the file was
+	// being written.
+	Shadow Source = "shadow"
+	// Unstable is what was read from a file that kept changing and had no
+	// shadow copy; it may be torn.
+	Unstable Source = "unstable"
+)
+
+// Version describes the content a read returned.
+type Version struct {
+	Source  Source    `json:"source"`
+	ModTime time.Time `json:"modTime"`
+	Size    int64     `json:"size"`
+}
+
+type entry struct {
+	path    string
+	data    []byte
+	modTime time.Time
+}
+
+// Store holds shadow copies, evicting the least recently used once their
+// total size exceeds the limit.
+type Store struct {
+	mu       sync.Mutex
+	maxBytes int64
+	size     int64
+	order    *list.List
+	entries  map[string]*list.Element
+	readFile func(string) ([]byte, error)
+}
+
+// New creates a store keeping at most maxBytes of file content.
+func New(maxBytes int64) *Store {
+	return &Store{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+		readFile: os.ReadFile,
+	}
+}
+
+// Read reads the whole file at path. If the file changes during every
+// attempt, it returns the shadow copy when there is one, and otherwise the
+// last attempt's data marked Unstable.
+func (s *Store) Read(path string) ([]byte, Version, error) {
+	var data []byte
+	var after os.FileInfo
+	for attempt := 0; attempt < readAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(retryDelay)
+		}
+		before, err := os.Stat(path)
+		if err != nil {
+			return nil, Version{}, err
+		}
+		data, err = s.readFile(path)
+		if err != nil {
+			return nil, Version{}, err
+		}
+		after, err = os.Stat(path)
+		if err != nil {
+			return nil, Version{}, err
+		}
+		if unchanged(before, after) && int64(len(data)) == after.Size() {
+			s.put(path, data, after.ModTime())
+			return data, Version{Source: Current, ModTime: after.ModTime(), Size: after.Size()}, nil
+		}
+	}
+
+	if e, ok := s.get(path); ok {
+		return e.data, Version{Source: Shadow, ModTime: e.modTime, Size: int64(len(e.data))}, nil
+	}
+	return data, Version{Source: Unstable, ModTime: after.ModTime(), Size: int64(len(data))}, nil
+}
+
+// Record stores data as the committed content of path, for use after a
+// completed write. Nothing is recorded if the file no longer matches data.
+func (s *Store) Record(path string, data []byte) {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() != int64(len(data)) {
+		return
+	}
+	s.put(path, data, info.ModTime())
+}
+
+// Forget drops the shadow copy of path, for a write that changed the file
+// in place, after which the copy is no longer its committed content.
+func (s *Store) Forget(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.entries[path]; ok {
+		s.removeLocked(el)
+	}
+}
+
+func (s *Store) get(path string) (*entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.entries[path]
+	if !ok {
+		return nil, false
+	}
+	s.order.MoveToFront(el)
+	return el.Value.(*entry), true
+}
+
+func (s *Store) put(path string, data []byte, modTime time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.entries[path]; ok {
+		s.removeLocked(el)
+	}
+	if int64(len(data)) > s.maxBytes {
+		return
+	}
+	// Copy so callers cannot modify the shadow through data
+	e := &entry{path: path, data: append([]byte(nil), data...), modTime: modTime}
+	s.entries[path] = s.order.PushFront(e)
+	s.size += int64(len(e.data))
+	for s.size > s.maxBytes {
+		s.removeLocked(s.order.Back())
+	}
+}
+
+func (s *Store) removeLocked(el *list.Element) {
+	e := s.order.Remove(el).(*entry)
+	delete(s.entries, e.path)
+	s.size -= int64(len(e.data))
+}
+
+func unchanged(a, b os.FileInfo) bool {
+	return a.Size() == b.Size() && a.ModTime().Equal(b.ModTime()) && os.SameFile(a, b)
+}
@@ -0,0 +1,125 @@
+package shadow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadCurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(path, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := New(DefaultMaxBytes)
+
+	data, v, err := s.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hello" || v.Source != Current || v.Size != 5 {
+		t.Errorf("got %q %+v, want current hello", data, v)
+	}
+	if e, ok := s.get(path); !ok || string(e.data) != "hello" {
+		t.Error("expected a stable read to be kept as the shadow copy")
+	}
+}
+
+func TestReadFallsBackToShadow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(path, []byte("committed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := New(DefaultMaxBytes)
+	s.Record(path, []byte("committed"))
+
+	// Simulate a writer appending to the file during every read
+	writes := 0
+	s.readFile = func(p string) ([]byte, error) {
+		data, err := os.ReadFile(p)
+		f, ferr := os.OpenFile(p, os.O_APPEND|os.O_WRONLY, 0644)
+		if ferr != nil {
+			t.Fatal(ferr)
+		}
+		defer f.Close()
+		f.Write([]byte("x"))
+		writes++
+		return data, err
+	}
+
+	data, v, err := s.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Source != Shadow || string(data) != "committed" {
+		t.Errorf("got %q %+v, want shadow copy", data, v)
+	}
+	if writes != readAttempts {
+		t.Errorf("expected %d attempts, got %d", readAttempts, writes)
+	}
+
+	// Without a shadow copy the torn data is returned and marked unstable
+	s = New(DefaultMaxBytes)
+	s.readFile = func(p string) ([]byte, error) {
+		data, err := os.ReadFile(p)
+		os.WriteFile(p, append(data, 'y'), 0644)
+		return data, err
+	}
+	if _, v, err = s.Read(path); err != nil || v.Source != Unstable {
+		t.Errorf("got %+v, %v, want unstable", v, err)
+	}
+}
+
+func TestRecordSkipsMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(path, []byte("on disk"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := New(DefaultMaxBytes)
+	s.Record(path, []byte("something else"))
+	if _, ok := s.get(path); ok {
+		t.Error("expected a copy that does not match the file to be skipped")
+	}
+}
+
+func TestForget(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(path, []byte("on disk"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := New(DefaultMaxBytes)
+	s.Record(path, []byte("on disk"))
+	s.Forget(path)
+	if _, ok := s.get(path); ok || s.size != 0 {
+		t.Error("expected the copy to be dropped")
+	}
+}
+
+func TestEviction(t *testing.T) {
+	dir := t.TempDir()
+	s := New(10)
+	for _, name := range []string{"a", "b", "c"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("1234"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		s.Record(path, []byte("1234"))
+	}
+	if _, ok := s.get(filepath.Join(dir, "a")); ok {
+		t.Error("expected least recently used copy to be evicted")
+	}
+	for _, name := range []string{"b", "c"} {
+		if _, ok := s.get(filepath.Join(dir, name)); !ok {
+			t.Errorf("expected %s to be kept", name)
+		}
+	}
+
+	big := filepath.Join(dir, "big")
+	if err := os.WriteFile(big, make([]byte, 11), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s.Record(big, make([]byte, 11))
+	if _, ok := s.get(big); ok {
+		t.Error("expected a file larger than the limit not to be kept")
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !unix && !windows
+
+package statedir
+
+// sameDevice reports false, keeping per-root state in the allowed
+// directories, where this platform cannot tell filesystems apart.
+func sameDevice(a, b string) bool {
+	return false
+}
@@ -0,0 +1,26 @@
+//go:build unix
+
+package statedir
+
+import (
+	"os"
+	"syscall"
+)
+
+// sameDevice reports whether a and b are on the same filesystem.
+func sameDevice(a, b string) bool {
+	ia, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	ib, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	sa, ok := ia.Sys().(*syscall.Stat_t)
+	if !ok {
+		return false
+	}
+	sb, ok := ib.Sys().(*syscall.Stat_t)
+	return ok && sa.Dev == sb.Dev
+}
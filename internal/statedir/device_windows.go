@@ -0,0 +1,12 @@
+package statedir
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// sameDevice reports whether a and b are on the same volume.
+func sameDevice(a, b string) bool {
+	va, vb := filepath.VolumeName(a), filepath.VolumeName(b)
+	return va != "" && strings.EqualFold(va, vb)
+}
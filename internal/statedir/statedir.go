@@ -0,0 +1,301 @@
+// Package statedir manages the directory where the server keeps state
+// between runs: bookmarks, annotations, saved searches, and usage samples
+// as JSON files at the top, and a subdirectory per kind of per-root state,
+// such as the undo journal and the trash, with one directory in it for
+// each allowed directory. The layout is versioned by a version file; Open
+// migrates a directory written by an earlier release to the current
+// layout.
+//
+// Several server instances can share a state directory. Each registers the
+// allowed directories it serves with a lock file it holds while running,
+// so an instance can tell when another serves the same directory.
+package statedir
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/portertech/filesystem-mcp-server/internal/filelock"
+)
+
+// Version is the layout version this release writes.
+const Version = 1
+
+// name is the directory created under the platform's state directory.
+const name = "filesystem-mcp"
+
+// versionFile holds the layout version of a state directory.
+const versionFile = "version"
+
+// lockName is the lock file instances hold while opening a state
+// directory, so only one migrates it.
+const lockName = "lock"
+
+// instancesDir holds a directory per allowed directory, with a lock file
+// for each instance serving it.
+const instancesDir = "instances"
+
+// lockWait is how long Open waits for another instance to finish opening
+// the state directory.
+const lockWait = 30 * time.Second
+
+// migrations upgrade a state directory from layout version i to i+1.
+var migrations = []func(dir string) error{
+	// Version 0, written before the layout was versioned, has the same
+	// top-level files; per-root state moves in lazily, see Locator
+	func(string) error { return nil },
+}
+
+// ErrTooNew is returned for a state directory written by a newer release.
+var ErrTooNew = errors.New("state directory layout is newer than this release supports")
+
+// Default returns the default state directory: filesystem-mcp under
+// $XDG_STATE_HOME, ~/.local/state, or on Windows %LocalAppData%. It
+// returns "" if none of them can be determined.
+func Default() string {
+	if dir := os.Getenv("XDG_STATE_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, name)
+	}
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("LocalAppData"); dir != "" {
+			return filepath.Join(dir, name)
+		}
+		return ""
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".local", "state", name)
+}
+
+// Dir is an open state directory.
+type Dir struct {
+	path   string
+	logger *slog.Logger
+
+	mu sync.Mutex
+	// located caches Locator results by kind and root.
+	located map[string]string
+	// registered holds the instance lock files of Register, by the
+	// directory they are in.
+	registered map[string]instance
+}
+
+// instance is a held instance lock file.
+type instance struct {
+	path    string
+	release func()
+}
+
+// Open creates the state directory at path if needed and migrates it to
+// the current layout.
+func Open(path string, logger *slog.Logger) (*Dir, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(abs, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	release, err := filelock.Acquire(filepath.Join(abs, lockName), lockWait)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock state directory: %w", err)
+	}
+	defer release()
+	version, err := readVersion(abs)
+	if err != nil {
+		return nil, err
+	}
+	if version > Version {
+		return nil, fmt.Errorf("%w: %s has version %d, this release supports up to %d", ErrTooNew, abs, version, Version)
+	}
+	if version < Version {
+		for v := version; v < Version; v++ {
+			if err := migrations[v](abs); err != nil {
+				return nil, fmt.Errorf("failed to migrate state directory %s from version %d: %w", abs, v, err)
+			}
+		}
+		if err := writeVersion(abs); err != nil {
+			return nil, err
+		}
+		if version > 0 {
+			logger.Info("migrated state directory", "path", abs, "from", version, "to", Version)
+		}
+	}
+	return &Dir{path: abs, logger: logger, located: make(map[string]string), registered: make(map[string]instance)}, nil
+}
+
+// Path returns the absolute path of the state directory.
+func (d *Dir) Path() string {
+	return d.path
+}
+
+// Locator returns a function that gives the directory holding kind's state
+// for an allowed directory. That is a directory under kind in the state
+// directory when root is on the same filesystem, so files can be moved in
+// with a rename, and legacyName at the top of root otherwise, or when d is
+// nil. A legacyName directory left in root by an earlier release, or by a
+// run without a state directory, is moved into the state directory the
+// first time root is located.
+func (d *Dir) Locator(kind, legacyName string) func(root string) string {
+	return func(root string) string {
+		legacy := filepath.Join(root, legacyName)
+		if d == nil {
+			return legacy
+		}
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		key := kind + "\x00" + root
+		if dir, ok := d.located[key]; ok {
+			return dir
+		}
+		dir := d.locate(kind, legacy, root)
+		d.located[key] = dir
+		return dir
+	}
+}
+
+// locate places kind's state for root, moving legacy into the state
+// directory if it exists.
+func (d *Dir) locate(kind, legacy, root string) string {
+	if !sameDevice(d.path, root) {
+		return legacy
+	}
+	dir := filepath.Join(d.path, kind, rootKey(root))
+	if _, err := os.Lstat(legacy); err != nil {
+		return dir
+	}
+	if _, err := os.Lstat(dir); err == nil {
+		d.logger.Warn("leaving state in the allowed directory, since the state directory already has some for it", "kind", kind, "path", legacy, "stateDir", dir)
+		return dir
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0700); err != nil {
+		d.logger.Warn("failed to move state into the state directory, keeping it in the allowed directory", "kind", kind, "path", legacy, "error", err)
+		return legacy
+	}
+	if err := os.Rename(legacy, dir); err != nil {
+		d.logger.Warn("failed to move state into the state directory, keeping it in the allowed directory", "kind", kind, "path", legacy, "error", err)
+		return legacy
+	}
+	d.logger.Info("moved state into the state directory", "kind", kind, "from", legacy, "to", dir)
+	return dir
+}
+
+// Register records that this instance serves root, holding a lock file
+// until Close, and returns how many other running instances serve it.
+// Lock files left by instances that have exited are removed. Where files
+// cannot be locked, other instances cannot be detected, and it returns 0.
+func (d *Dir) Register(root string) (int, error) {
+	if !filelock.Supported {
+		return 0, nil
+	}
+	dir := filepath.Join(d.path, instancesDir, rootKey(root))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return 0, fmt.Errorf("failed to register instance: %w", err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	own, ok := d.registered[dir]
+	if !ok {
+		b := make([]byte, 4)
+		rand.Read(b)
+		path := filepath.Join(dir, fmt.Sprintf("%d-%s", os.Getpid(), hex.EncodeToString(b)))
+		release, err := filelock.Acquire(path, 0)
+		if err != nil {
+			return 0, fmt.Errorf("failed to register instance: %w", err)
+		}
+		own = instance{path: path, release: release}
+		d.registered[dir] = own
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list instances: %w", err)
+	}
+	others := 0
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		if path == own.path {
+			continue
+		}
+		release, err := filelock.Acquire(path, 0)
+		if errors.Is(err, filelock.ErrLocked) {
+			others++
+			continue
+		}
+		if err == nil {
+			release()
+			os.Remove(path)
+		}
+	}
+	return others, nil
+}
+
+// Close releases and removes the lock files of Register.
+func (d *Dir) Close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for dir, own := range d.registered {
+		own.release()
+		os.Remove(own.path)
+		delete(d.registered, dir)
+	}
+}
+
+// rootKey names the directory of root's state: its base name, for people
+// looking through the state directory, and a hash of its path.
+func rootKey(root string) string {
+	sum := sha256.Sum256([]byte(root))
+	base := strings.Trim(filepath.Base(root), `.\/:`)
+	if base == "" {
+		base = "root"
+	}
+	return base + "-" + hex.EncodeToString(sum[:6])
+}
+
+// readVersion returns the layout version of dir: 0 for a directory written
+// before layouts were versioned, and the current version for a new one.
+func readVersion(dir string) (int, error) {
+	data, err := os.ReadFile(filepath.Join(dir, versionFile))
+	if err == nil {
+		v, err := strconv.Atoi(strings.TrimSpace(string(data)))
+		if err != nil || v < 0 {
+			return 0, fmt.Errorf("state directory %s has an invalid version file", dir)
+		}
+		return v, nil
+	}
+	if !os.IsNotExist(err) {
+		return 0, fmt.Errorf("failed to read state directory version: %w", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read state directory: %w", err)
+	}
+	for _, e := range entries {
+		if e.Name() != lockName {
+			return 0, nil
+		}
+	}
+	return Version, writeVersion(dir)
+}
+
+// writeVersion records the current layout version in dir.
+func writeVersion(dir string) error {
+	if err := os.WriteFile(filepath.Join(dir, versionFile), []byte(strconv.Itoa(Version)+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write state directory version: %w", err)
+	}
+	return nil
+}
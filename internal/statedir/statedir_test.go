@@ -0,0 +1,133 @@
+package statedir
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/portertech/filesystem-mcp-server/internal/filelock"
+)
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func TestOpen(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "state")
+	if _, err := Open(dir, discard); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, versionFile)); string(data) != "1\n" {
+		t.Errorf("expected a new directory to get the current version, got %q", data)
+	}
+
+	// An unversioned directory from an earlier release keeps its files
+	old := t.TempDir()
+	os.WriteFile(filepath.Join(old, "bookmarks.json"), []byte("[]"), 0600)
+	if _, err := Open(old, discard); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(old, "bookmarks.json")); err != nil {
+		t.Error("expected the migration to keep bookmarks.json")
+	}
+	if data, _ := os.ReadFile(filepath.Join(old, versionFile)); string(data) != "1\n" {
+		t.Errorf("expected the migration to record the version, got %q", data)
+	}
+
+	os.WriteFile(filepath.Join(old, versionFile), []byte("99\n"), 0600)
+	if _, err := Open(old, discard); !errors.Is(err, ErrTooNew) {
+		t.Errorf("expected ErrTooNew, got %v", err)
+	}
+	os.WriteFile(filepath.Join(old, versionFile), []byte("x"), 0600)
+	if _, err := Open(old, discard); err == nil {
+		t.Error("expected an invalid version to be refused")
+	}
+}
+
+func TestDefault(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", filepath.Join(t.TempDir(), "xdg"))
+	if got, want := Default(), filepath.Join(os.Getenv("XDG_STATE_HOME"), name); got != want {
+		t.Errorf("Default() = %q, want %q", got, want)
+	}
+	t.Setenv("XDG_STATE_HOME", "relative")
+	if got := Default(); got == filepath.Join("relative", name) {
+		t.Error("expected a relative XDG_STATE_HOME to be ignored")
+	}
+}
+
+func TestLocator(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "plan9" {
+		t.Skip("temporary directories may not share a volume")
+	}
+	d, err := Open(t.TempDir(), discard)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	root := t.TempDir()
+	if !sameDevice(d.Path(), root) {
+		t.Skip("temporary directories are on different filesystems")
+	}
+	legacy := filepath.Join(root, ".mcp-journal")
+	os.MkdirAll(legacy, 0700)
+	os.WriteFile(filepath.Join(legacy, "journal.json"), []byte("[]"), 0600)
+
+	locate := d.Locator("journal", ".mcp-journal")
+	dir := locate(root)
+	if filepath.Dir(dir) != filepath.Join(d.Path(), "journal") {
+		t.Fatalf("expected the journal in the state directory, got %s", dir)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "journal.json")); err != nil {
+		t.Error("expected the legacy journal to be moved into the state directory")
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Error("expected the legacy journal to be gone")
+	}
+	if locate(root) != dir {
+		t.Error("expected the same directory on every call")
+	}
+	if other := locate(t.TempDir()); other == dir {
+		t.Error("expected each allowed directory to get its own directory")
+	}
+
+	var none *Dir
+	if got := none.Locator("trash", ".mcp-trash")(root); got != filepath.Join(root, ".mcp-trash") {
+		t.Errorf("expected a nil Dir to keep state in the allowed directory, got %s", got)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	if !filelock.Supported {
+		t.Skip(filelock.ErrUnsupported)
+	}
+	state := t.TempDir()
+	a, err := Open(state, discard)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	b, err := Open(state, discard)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	root := t.TempDir()
+
+	if n, err := a.Register(root); err != nil || n != 0 {
+		t.Fatalf("expected no other instances, got %d, %v", n, err)
+	}
+	if n, err := b.Register(root); err != nil || n != 1 {
+		t.Fatalf("expected one other instance, got %d, %v", n, err)
+	}
+	if n, _ := b.Register(t.TempDir()); n != 0 {
+		t.Errorf("expected no other instances of another directory, got %d", n)
+	}
+	a.Close()
+	if n, _ := b.Register(root); n != 0 {
+		t.Errorf("expected the closed instance to be gone, got %d", n)
+	}
+	b.Close()
+	entries, _ := os.ReadDir(filepath.Join(state, instancesDir, rootKey(root)))
+	if len(entries) != 0 {
+		t.Errorf("expected Close to remove the lock files, got %d", len(entries))
+	}
+}
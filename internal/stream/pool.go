@@ -0,0 +1,39 @@
+package stream
+
+import (
+	"io"
+	"sync"
+)
+
+// Buffers are pooled as pointers to slices so that returning them to the
+// pool does not allocate.
+var (
+	chunkPool = sync.Pool{New: func() any {
+		buf := make([]byte, DefaultChunkSize)
+		return &buf
+	}}
+	tailChunkPool = sync.Pool{New: func() any {
+		buf := make([]byte, TailChunkSize)
+		return &buf
+	}}
+)
+
+// getChunk returns a DefaultChunkSize buffer from the pool. Return it with
+// putChunk once nothing refers to its contents.
+func getChunk() *[]byte {
+	return chunkPool.Get().(*[]byte)
+}
+
+func putChunk(buf *[]byte) {
+	chunkPool.Put(buf)
+}
+
+// copyChunks copies src to dst through a pooled buffer. Unlike io.CopyBuffer
+// it always uses that buffer: given an *os.File, io.CopyBuffer defers to its
+// WriteTo or ReadFrom method, which allocates a buffer of its own unless the
+// kernel can copy the data directly.
+func copyChunks(dst io.Writer, src io.Reader) (int64, error) {
+	buf := getChunk()
+	defer putChunk(buf)
+	return io.CopyBuffer(struct{ io.Writer }{dst}, struct{ io.Reader }{src}, *buf)
+}
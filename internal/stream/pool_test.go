@@ -0,0 +1,107 @@
+package stream
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStreamToBase64Sizes(t *testing.T) {
+	tmpDir := t.TempDir()
+	// Sizes around the chunk size exercise the partial final chunk
+	for _, size := range []int{0, 1, 2, 3, DefaultChunkSize - 1, DefaultChunkSize, DefaultChunkSize + 1, 3*DefaultChunkSize + 2} {
+		data := []byte(strings.Repeat("abcdefg", size/7+1)[:size])
+		path := filepath.Join(tmpDir, "data.bin")
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := StreamToBase64(path)
+		if err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+		if want := base64.StdEncoding.EncodeToString(data); got != want {
+			t.Errorf("size %d: encoding does not match", size)
+		}
+	}
+}
+
+// writeBenchFile writes a file of size bytes of text lines for benchmarks.
+func writeBenchFile(b *testing.B, size int) string {
+	b.Helper()
+	path := filepath.Join(b.TempDir(), "bench.txt")
+	line := strings.Repeat("x", 79) + "\n"
+	if err := os.WriteFile(path, []byte(strings.Repeat(line, size/len(line))), 0644); err != nil {
+		b.Fatal(err)
+	}
+	return path
+}
+
+func BenchmarkCopyFileStreaming(b *testing.B) {
+	src := writeBenchFile(b, 256*1024)
+	dst := filepath.Join(filepath.Dir(src), "copy.txt")
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := CopyFileStreaming(src, dst); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTailFile(b *testing.B) {
+	path := writeBenchFile(b, 256*1024)
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := TailFile(path, 50); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStreamToBase64(b *testing.B) {
+	path := writeBenchFile(b, 1024*1024)
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := StreamToBase64(path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkHashFile(b *testing.B) {
+	path := writeBenchFile(b, 1024*1024)
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := HashFile(path, sha256.New()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestBase64Range(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.bin")
+	if err := os.WriteFile(path, []byte("abcdefgh"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		offset, length int64
+		want           string
+		n              int64
+	}{
+		{0, 3, "YWJj", 3},
+		{3, 3, "ZGVm", 3},
+		{6, 3, "Z2g=", 2},
+		{8, 3, "", 0},
+	}
+	for _, tt := range tests {
+		got, n, err := Base64Range(path, tt.offset, tt.length)
+		if err != nil || got != tt.want || n != tt.n {
+			t.Errorf("Base64Range(%d, %d) = %q, %d, %v; want %q, %d", tt.offset, tt.length, got, n, err, tt.want, tt.n)
+		}
+	}
+	if _, _, err := Base64Range(path, 9, 3); err == nil {
+		t.Error("expected an error for an offset past the end")
+	}
+}
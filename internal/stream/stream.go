@@ -2,15 +2,17 @@ package stream
 
 import (
 	"bufio"
-	"bytes"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
+	"math"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
@@ -44,9 +46,11 @@ func TailFile(path string, n int) (string, error) {
 		return "", nil
 	}
 
-	// Read from the end in chunks
+	// Read from the end in chunks, collecting lines last first
 	var lines []string
-	chunk := make([]byte, TailChunkSize)
+	chunkBuf := tailChunkPool.Get().(*[]byte)
+	defer tailChunkPool.Put(chunkBuf)
+	chunk := *chunkBuf
 	var leftover []byte
 	offset := fileSize
 
@@ -71,12 +75,11 @@ func TailFile(path string, n int) (string, error) {
 		data := append(chunk[:bytesRead], leftover...)
 
 		// Split into lines
-		var newLines []string
 		start := len(data)
 		for i := len(data) - 1; i >= 0; i-- {
 			if data[i] == '\n' {
 				if i < start-1 {
-					newLines = append([]string{string(data[i+1 : start])}, newLines...)
+					lines = append(lines, string(data[i+1:start]))
 				}
 				start = i
 			}
@@ -89,19 +92,18 @@ func TailFile(path string, n int) (string, error) {
 		} else {
 			leftover = nil
 		}
-
-		lines = append(newLines, lines...)
 	}
 
 	// Handle remaining leftover (first line of file)
 	if len(leftover) > 0 && len(lines) < n {
-		lines = append([]string{string(leftover)}, lines...)
+		lines = append(lines, string(leftover))
 	}
 
-	// Take only the last n lines
+	// Take only the last n lines, in file order
 	if len(lines) > n {
-		lines = lines[len(lines)-n:]
+		lines = lines[:n]
 	}
+	slices.Reverse(lines)
 
 	// Join lines with newlines using strings.Builder
 	var result strings.Builder
@@ -181,9 +183,11 @@ func CopyFileStreaming(src, dst string) error {
 		}
 	}()
 
-	// Copy with buffered writes
-	buf := make([]byte, DefaultChunkSize)
-	_, err = io.CopyBuffer(tmpFile, srcFile, buf)
+	// Copy with buffered writes. Where the kernel can copy between the files
+	// directly, the pooled buffer goes unused.
+	buf := getChunk()
+	_, err = io.CopyBuffer(tmpFile, srcFile, *buf)
+	putChunk(buf)
 	if err != nil {
 		tmpFile.Close()
 		return fmt.Errorf("failed to copy: %w", err)
@@ -207,53 +211,99 @@ func CopyFileStreaming(src, dst string) error {
 	return nil
 }
 
+// WriteFileStreaming writes a file with the output of write, through a
+// temporary file in the same directory that is renamed into place only if
+// write succeeds.
+func WriteFileStreaming(dst string, perm os.FileMode, write func(io.Writer) error) error {
+	tmpFile, err := createTempFile(filepath.Dir(dst), ".tmp-")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+
+	success := false
+	defer func() {
+		if !success {
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if err := write(tmpFile); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to sync file: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return fmt.Errorf("failed to set permissions: %w", err)
+	}
+	if err := os.Rename(tmpPath, dst); err != nil {
+		return fmt.Errorf("failed to rename: %w", err)
+	}
+
+	success = true
+	return nil
+}
+
+// HashFile streams a file through h in DefaultChunkSize chunks and returns
+// the number of bytes hashed.
+func HashFile(path string, h hash.Hash) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	return copyChunks(h, f)
+}
+
 // StreamToBase64 encodes a file to base64 using streaming to handle large files.
 func StreamToBase64(path string) (string, error) {
+	data, _, err := Base64Range(path, 0, math.MaxInt64)
+	return data, err
+}
+
+// Base64Range encodes up to length bytes of the file at path, starting at
+// offset, and returns the encoded data and the number of bytes encoded.
+// Encodings of consecutive ranges whose lengths are multiples of three
+// concatenate to the encoding of the whole file.
+func Base64Range(path string, offset, length int64) (string, int64, error) {
 	f, err := os.Open(path)
 	if err != nil {
-		return "", err
+		return "", 0, err
 	}
 	defer f.Close()
 
-	// For files under 10MB, read all at once for simplicity
 	stat, err := f.Stat()
 	if err != nil {
-		return "", err
+		return "", 0, err
 	}
-
-	if stat.Size() < 10*1024*1024 {
-		data, err := io.ReadAll(f)
-		if err != nil {
-			return "", err
-		}
-		return base64.StdEncoding.EncodeToString(data), nil
+	if offset < 0 || offset > stat.Size() {
+		return "", 0, fmt.Errorf("offset %d is outside the file (%d bytes)", offset, stat.Size())
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return "", 0, err
 	}
 
-	// For larger files, stream the encoding
-	var result bytes.Buffer
-	buf := make([]byte, DefaultChunkSize)
+	// Encode straight into a result sized for the range, so the only large
+	// allocation is the encoded string itself
+	var result strings.Builder
+	result.Grow(base64.StdEncoding.EncodedLen(int(min(length, stat.Size()-offset))))
 	encoder := base64.NewEncoder(base64.StdEncoding, &result)
-
-	for {
-		n, err := f.Read(buf)
-		if n > 0 {
-			if _, err := encoder.Write(buf[:n]); err != nil {
-				return "", err
-			}
-		}
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return "", err
-		}
+	n, err := copyChunks(encoder, io.LimitReader(f, length))
+	if err != nil {
+		return "", 0, err
 	}
-
 	if err := encoder.Close(); err != nil {
-		return "", err
+		return "", 0, err
 	}
 
-	return result.String(), nil
+	return result.String(), n, nil
 }
 
 // createTempFile creates a temporary file with a cryptographically random suffix.
@@ -1,6 +1,8 @@
 package stream
 
 import (
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -353,3 +355,34 @@ func TestLineNumberWidth(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteFileStreaming(t *testing.T) {
+	tmpDir := t.TempDir()
+	dst := filepath.Join(tmpDir, "out.txt")
+
+	err := WriteFileStreaming(dst, 0640, func(w io.Writer) error {
+		_, err := io.WriteString(w, "streamed")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WriteFileStreaming error: %v", err)
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "streamed" {
+		t.Errorf("expected %q, got %q", "streamed", string(data))
+	}
+
+	// A failing writer leaves the destination and directory untouched
+	err = WriteFileStreaming(dst, 0640, func(w io.Writer) error {
+		io.WriteString(w, "partial")
+		return errors.New("boom")
+	})
+	if err == nil {
+		t.Fatal("expected the writer's error")
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "streamed" {
+		t.Errorf("destination changed to %q", string(data))
+	}
+	if entries, _ := os.ReadDir(tmpDir); len(entries) != 1 {
+		t.Errorf("expected no leftover temp files, got %d entries", len(entries))
+	}
+}
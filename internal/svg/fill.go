@@ -0,0 +1,157 @@
+package svg
+
+import (
+	"image"
+	"math"
+	"sort"
+)
+
+// subsamples is the number of scanlines sampled per pixel row. Coverage
+// along each scanline is exact, so this only limits vertical antialiasing.
+const subsamples = 4
+
+// edge is a polygon edge with y0 < y1. dir is +1 for edges that went down
+// and -1 for edges that went up, for the nonzero rule.
+type edge struct {
+	x0, y0, x1, y1 float64
+	dir            int
+}
+
+type crossing struct {
+	x   float64
+	dir int
+}
+
+// fillPolygons composites the polygons onto dst in color c, using the
+// even-odd fill rule or else nonzero.
+func fillPolygons(dst *image.RGBA, polys [][]point, evenOdd bool, c rgba) {
+	if c.a <= 0 {
+		return
+	}
+	bounds := dst.Bounds()
+	var edges []edge
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, poly := range polys {
+		for i, p := range poly {
+			q := poly[(i+1)%len(poly)]
+			minX, maxX = math.Min(minX, p.x), math.Max(maxX, p.x)
+			minY, maxY = math.Min(minY, p.y), math.Max(maxY, p.y)
+			if p.y == q.y || math.IsNaN(p.y) || math.IsNaN(q.y) {
+				continue
+			}
+			if p.y < q.y {
+				edges = append(edges, edge{p.x, p.y, q.x, q.y, 1})
+			} else {
+				edges = append(edges, edge{q.x, q.y, p.x, p.y, -1})
+			}
+		}
+	}
+	if len(edges) == 0 {
+		return
+	}
+	x0 := max(bounds.Min.X, int(math.Floor(minX)))
+	x1 := min(bounds.Max.X, int(math.Ceil(maxX))+1)
+	y0 := max(bounds.Min.Y, int(math.Floor(minY)))
+	y1 := min(bounds.Max.Y, int(math.Ceil(maxY))+1)
+	if x0 >= x1 || y0 >= y1 {
+		return
+	}
+
+	sort.Slice(edges, func(i, j int) bool { return edges[i].y0 < edges[j].y0 })
+	cover := make([]float64, x1-x0)
+	var active []edge
+	var xs []crossing
+	next := 0
+	for y := y0; y < y1; y++ {
+		// Keep the edges that span this row
+		kept := active[:0]
+		for _, e := range active {
+			if e.y1 > float64(y) {
+				kept = append(kept, e)
+			}
+		}
+		active = kept
+		for next < len(edges) && edges[next].y0 < float64(y+1) {
+			if edges[next].y1 > float64(y) {
+				active = append(active, edges[next])
+			}
+			next++
+		}
+		if len(active) == 0 {
+			continue
+		}
+
+		clear(cover)
+		for s := range subsamples {
+			sy := float64(y) + (float64(s)+0.5)/subsamples
+			xs = xs[:0]
+			for _, e := range active {
+				if e.y0 <= sy && sy < e.y1 {
+					t := (sy - e.y0) / (e.y1 - e.y0)
+					xs = append(xs, crossing{e.x0 + t*(e.x1-e.x0), e.dir})
+				}
+			}
+			sort.Slice(xs, func(i, j int) bool { return xs[i].x < xs[j].x })
+			wind := 0
+			var start float64
+			for _, cr := range xs {
+				was := inside(wind, evenOdd)
+				wind += cr.dir
+				now := inside(wind, evenOdd)
+				if now && !was {
+					start = cr.x
+				} else if was && !now {
+					addSpan(cover, start-float64(x0), cr.x-float64(x0), 1.0/subsamples)
+				}
+			}
+		}
+
+		for i, cv := range cover {
+			if cv > 0 {
+				blend(dst, x0+i, y, c, math.Min(cv, 1))
+			}
+		}
+	}
+}
+
+func inside(wind int, evenOdd bool) bool {
+	if evenOdd {
+		return wind%2 != 0
+	}
+	return wind != 0
+}
+
+// addSpan adds weight to the cells of cover between a and b, splitting it
+// across the partly covered cells at either end.
+func addSpan(cover []float64, a, b, weight float64) {
+	a = math.Max(a, 0)
+	b = math.Min(b, float64(len(cover)))
+	if a >= b {
+		return
+	}
+	ia, ib := int(a), int(b)
+	if ia == ib {
+		cover[ia] += (b - a) * weight
+		return
+	}
+	cover[ia] += (float64(ia+1) - a) * weight
+	for i := ia + 1; i < ib; i++ {
+		cover[i] += weight
+	}
+	if ib < len(cover) {
+		cover[ib] += (b - float64(ib)) * weight
+	}
+}
+
+// blend composites c at the given coverage over the premultiplied pixel at
+// x, y.
+func blend(dst *image.RGBA, x, y int, c rgba, coverage float64) {
+	a := c.a * coverage
+	i := dst.PixOffset(x, y)
+	px := dst.Pix[i : i+4 : i+4]
+	for ch, v := range [3]float64{c.r, c.g, c.b} {
+		px[ch] = uint8(math.Round(v*a*255 + float64(px[ch])*(1-a)))
+	}
+	px[3] = uint8(math.Round(a*255 + float64(px[3])*(1-a)))
+}
@@ -0,0 +1,367 @@
+package svg
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// rgba is a non-premultiplied color with components from 0 to 1.
+type rgba struct {
+	r, g, b, a float64
+}
+
+// namedColors holds the CSS color keywords most often found in SVGs.
+var namedColors = map[string]uint32{
+	"black": 0x000000, "silver": 0xc0c0c0, "gray": 0x808080, "grey": 0x808080,
+	"white": 0xffffff, "maroon": 0x800000, "red": 0xff0000, "purple": 0x800080,
+	"fuchsia": 0xff00ff, "magenta": 0xff00ff, "green": 0x008000, "lime": 0x00ff00,
+	"olive": 0x808000, "yellow": 0xffff00, "navy": 0x000080, "blue": 0x0000ff,
+	"teal": 0x008080, "aqua": 0x00ffff, "cyan": 0x00ffff, "orange": 0xffa500,
+	"pink": 0xffc0cb, "brown": 0xa52a2a, "gold": 0xffd700, "indigo": 0x4b0082,
+	"violet": 0xee82ee, "darkgray": 0xa9a9a9, "darkgrey": 0xa9a9a9,
+	"lightgray": 0xd3d3d3, "lightgrey": 0xd3d3d3, "dimgray": 0x696969,
+	"dimgrey": 0x696969, "darkred": 0x8b0000, "darkgreen": 0x006400,
+	"darkblue": 0x00008b, "lightblue": 0xadd8e6, "skyblue": 0x87ceeb,
+	"steelblue": 0x4682b4, "royalblue": 0x4169e1, "tomato": 0xff6347,
+	"coral": 0xff7f50, "salmon": 0xfa8072, "crimson": 0xdc143c,
+	"firebrick": 0xb22222, "orangered": 0xff4500, "tan": 0xd2b48c,
+	"beige": 0xf5f5dc, "ivory": 0xfffff0, "khaki": 0xf0e68c,
+	"lavender": 0xe6e6fa, "turquoise": 0x40e0d0, "slategray": 0x708090,
+	"slategrey": 0x708090, "whitesmoke": 0xf5f5f5, "gainsboro": 0xdcdcdc,
+	"chocolate": 0xd2691e, "seagreen": 0x2e8b57, "forestgreen": 0x228b22,
+	"limegreen": 0x32cd32, "darkorange": 0xff8c00, "midnightblue": 0x191970,
+	"dodgerblue": 0x1e90ff, "deepskyblue": 0x00bfff, "hotpink": 0xff69b4,
+	"plum": 0xdda0dd, "orchid": 0xda70d6, "sienna": 0xa0522d,
+}
+
+// parseColor parses a CSS color. It reports false for "none", unknown
+// values, and references such as url(#id), which callers resolve.
+func parseColor(s string) (rgba, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if v, ok := namedColors[s]; ok {
+		return hexColor(v), true
+	}
+	if s == "transparent" {
+		return rgba{}, true
+	}
+	if strings.HasPrefix(s, "#") {
+		hex := s[1:]
+		switch len(hex) {
+		case 3, 4:
+			var expanded strings.Builder
+			for _, c := range hex {
+				expanded.WriteRune(c)
+				expanded.WriteRune(c)
+			}
+			hex = expanded.String()
+		case 6, 8:
+		default:
+			return rgba{}, false
+		}
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return rgba{}, false
+		}
+		if len(hex) == 8 {
+			c := hexColor(uint32(v >> 8))
+			c.a = float64(v&0xff) / 255
+			return c, true
+		}
+		return hexColor(uint32(v)), true
+	}
+	if args, ok := cssFunction(s, "rgb", "rgba"); ok && len(args) >= 3 {
+		var c rgba
+		for i, p := range []*float64{&c.r, &c.g, &c.b} {
+			*p = clamp01(parseChannel(args[i], 255))
+		}
+		c.a = 1
+		if len(args) >= 4 {
+			c.a = clamp01(parseChannel(args[3], 1))
+		}
+		return c, true
+	}
+	return rgba{}, false
+}
+
+func hexColor(v uint32) rgba {
+	return rgba{float64(v>>16&0xff) / 255, float64(v>>8&0xff) / 255, float64(v&0xff) / 255, 1}
+}
+
+// parseChannel parses a color channel, either a percentage or a number out
+// of scale.
+func parseChannel(s string, scale float64) float64 {
+	s = strings.TrimSpace(s)
+	if p, ok := strings.CutSuffix(s, "%"); ok {
+		v, _ := strconv.ParseFloat(p, 64)
+		return v / 100
+	}
+	v, _ := strconv.ParseFloat(s, 64)
+	return v / scale
+}
+
+// cssFunction splits the arguments of a call to one of names, such as
+// rgb(1, 2, 3) or rgb(1 2 3 / 50%).
+func cssFunction(s string, names ...string) ([]string, bool) {
+	for _, name := range names {
+		if rest, ok := strings.CutPrefix(s, name+"("); ok {
+			rest, ok = strings.CutSuffix(rest, ")")
+			if !ok {
+				return nil, false
+			}
+			return strings.FieldsFunc(rest, func(r rune) bool {
+				return r == ',' || r == ' ' || r == '/' || r == '\t'
+			}), true
+		}
+	}
+	return nil, false
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+// parseOpacity parses an opacity, a number or percentage, defaulting to 1.
+func parseOpacity(s string) float64 {
+	if s == "" {
+		return 1
+	}
+	return clamp01(parseChannel(s, 1))
+}
+
+// Units of absolute lengths, in user units (CSS pixels).
+var lengthUnits = map[string]float64{
+	"px": 1, "pt": 96.0 / 72, "pc": 16, "mm": 96 / 25.4, "cm": 96 / 2.54,
+	"in": 96, "em": 16, "ex": 8,
+}
+
+// parseLength parses a length, resolving percentages against ref.
+func parseLength(s string, ref float64) (float64, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, false
+	}
+	if p, ok := strings.CutSuffix(s, "%"); ok {
+		v, err := strconv.ParseFloat(p, 64)
+		return v / 100 * ref, err == nil
+	}
+	for unit, scale := range lengthUnits {
+		if n, ok := strings.CutSuffix(s, unit); ok {
+			v, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+			return v * scale, err == nil
+		}
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	return v, err == nil
+}
+
+// matrix is an affine transform [a c e; b d f].
+type matrix struct {
+	a, b, c, d, e, f float64
+}
+
+var identity = matrix{a: 1, d: 1}
+
+// mul returns the transform that applies n, then m.
+func (m matrix) mul(n matrix) matrix {
+	return matrix{
+		a: m.a*n.a + m.c*n.b,
+		b: m.b*n.a + m.d*n.b,
+		c: m.a*n.c + m.c*n.d,
+		d: m.b*n.c + m.d*n.d,
+		e: m.a*n.e + m.c*n.f + m.e,
+		f: m.b*n.e + m.d*n.f + m.f,
+	}
+}
+
+func (m matrix) apply(p point) point {
+	return point{m.a*p.x + m.c*p.y + m.e, m.b*p.x + m.d*p.y + m.f}
+}
+
+// scale returns how much m scales lengths on average.
+func (m matrix) scale() float64 {
+	return math.Sqrt(math.Abs(m.a*m.d - m.b*m.c))
+}
+
+// parseTransform parses a transform attribute.
+func parseTransform(s string) matrix {
+	m := identity
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimLeft(s, " ,\t\n") {
+		open := strings.IndexByte(s, '(')
+		end := strings.IndexByte(s, ')')
+		if open < 0 || end < open {
+			break
+		}
+		name := strings.TrimSpace(s[:open])
+		args := parseNumbers(s[open+1 : end])
+		s = s[end+1:]
+		arg := func(i int, def float64) float64 {
+			if i < len(args) {
+				return args[i]
+			}
+			return def
+		}
+
+		var t matrix
+		switch name {
+		case "matrix":
+			if len(args) != 6 {
+				continue
+			}
+			t = matrix{args[0], args[1], args[2], args[3], args[4], args[5]}
+		case "translate":
+			t = matrix{a: 1, d: 1, e: arg(0, 0), f: arg(1, 0)}
+		case "scale":
+			sx := arg(0, 1)
+			t = matrix{a: sx, d: arg(1, sx)}
+		case "rotate":
+			rad := arg(0, 0) * math.Pi / 180
+			cos, sin := math.Cos(rad), math.Sin(rad)
+			cx, cy := arg(1, 0), arg(2, 0)
+			t = matrix{a: 1, d: 1, e: cx, f: cy}.
+				mul(matrix{a: cos, b: sin, c: -sin, d: cos}).
+				mul(matrix{a: 1, d: 1, e: -cx, f: -cy})
+		case "skewX":
+			t = matrix{a: 1, c: math.Tan(arg(0, 0) * math.Pi / 180), d: 1}
+		case "skewY":
+			t = matrix{a: 1, b: math.Tan(arg(0, 0) * math.Pi / 180), d: 1}
+		default:
+			continue
+		}
+		m = m.mul(t)
+	}
+	return m
+}
+
+// parseNumbers parses a list of numbers separated by commas or whitespace.
+// Path data can also run numbers together, as in "1.5.5" or "1-2".
+func parseNumbers(s string) []float64 {
+	var nums []float64
+	sc := numberScanner{s: s}
+	for {
+		v, ok := sc.next()
+		if !ok {
+			return nums
+		}
+		nums = append(nums, v)
+	}
+}
+
+// numberScanner reads numbers from SVG number lists and path data.
+type numberScanner struct {
+	s string
+	i int
+}
+
+func (sc *numberScanner) skipSeparators() {
+	for sc.i < len(sc.s) && strings.IndexByte(" \t\r\n,", sc.s[sc.i]) >= 0 {
+		sc.i++
+	}
+}
+
+// next returns the next number, or false at the end of the input or before
+// anything that is not a number.
+func (sc *numberScanner) next() (float64, bool) {
+	sc.skipSeparators()
+	start := sc.i
+	i := sc.i
+	if i < len(sc.s) && (sc.s[i] == '+' || sc.s[i] == '-') {
+		i++
+	}
+	digits, dot := false, false
+	for ; i < len(sc.s); i++ {
+		c := sc.s[i]
+		if c >= '0' && c <= '9' {
+			digits = true
+		} else if c == '.' && !dot {
+			dot = true
+		} else {
+			break
+		}
+	}
+	if !digits {
+		return 0, false
+	}
+	if i < len(sc.s) && (sc.s[i] == 'e' || sc.s[i] == 'E') {
+		j := i + 1
+		if j < len(sc.s) && (sc.s[j] == '+' || sc.s[j] == '-') {
+			j++
+		}
+		if j < len(sc.s) && sc.s[j] >= '0' && sc.s[j] <= '9' {
+			for j < len(sc.s) && sc.s[j] >= '0' && sc.s[j] <= '9' {
+				j++
+			}
+			i = j
+		}
+	}
+	v, err := strconv.ParseFloat(sc.s[start:i], 64)
+	if err != nil {
+		return 0, false
+	}
+	sc.i = i
+	return v, true
+}
+
+// flag reads an arc flag, a single 0 or 1 that may not be followed by a
+// separator.
+func (sc *numberScanner) flag() (bool, bool) {
+	sc.skipSeparators()
+	if sc.i < len(sc.s) && (sc.s[sc.i] == '0' || sc.s[sc.i] == '1') {
+		sc.i++
+		return sc.s[sc.i-1] == '1', true
+	}
+	return false, false
+}
+
+// presentation lists the properties the rasterizer reads from attributes
+// and style declarations, and whether children inherit them.
+var presentation = map[string]bool{
+	"fill": true, "fill-opacity": true, "fill-rule": true, "stroke": true,
+	"stroke-width": true, "stroke-opacity": true, "stroke-linecap": true,
+	"color": true, "visibility": true, "display": false, "opacity": false,
+	"stop-color": false, "stop-opacity": false, "filter": false,
+	"clip-path": false, "mask": false,
+}
+
+// style is the computed style of an element.
+type style struct {
+	props map[string]string
+	// opacity is the product of the opacities of the element and its
+	// ancestors, which approximates group opacity
+	opacity    float64
+	display    string
+	visibility string
+}
+
+func defaultStyle() style {
+	return style{props: map[string]string{"fill": "black", "color": "black"}, opacity: 1}
+}
+
+// apply computes the style of n from st, the style of its parent.
+// Declarations in the style attribute override presentation attributes.
+func (st *style) apply(n *node) {
+	props := make(map[string]string, len(st.props))
+	for k, v := range st.props {
+		if presentation[k] {
+			props[k] = v
+		}
+	}
+	set := func(k, v string) {
+		v = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(v), "!important"))
+		if _, ok := presentation[k]; ok && v != "" && v != "inherit" {
+			props[k] = v
+		}
+	}
+	for k, v := range n.attrs {
+		set(k, v)
+	}
+	for _, decl := range strings.Split(n.attrs["style"], ";") {
+		if k, v, ok := strings.Cut(decl, ":"); ok {
+			set(strings.ToLower(strings.TrimSpace(k)), v)
+		}
+	}
+	st.props = props
+	st.opacity *= parseOpacity(props["opacity"])
+	st.display = props["display"]
+	st.visibility = props["visibility"]
+}
@@ -0,0 +1,282 @@
+package svg
+
+import (
+	"math"
+	"strings"
+)
+
+type point struct {
+	x, y float64
+}
+
+func (p point) sub(q point) point      { return point{p.x - q.x, p.y - q.y} }
+func (p point) add(q point) point      { return point{p.x + q.x, p.y + q.y} }
+func (p point) mul(s float64) point    { return point{p.x * s, p.y * s} }
+func (p point) dist(q point) float64   { return math.Hypot(p.x-q.x, p.y-q.y) }
+func lerp(p, q point, t float64) point { return p.add(q.sub(p).mul(t)) }
+
+// subpath is a polyline, closed or not, that curves have been flattened
+// into.
+type subpath struct {
+	pts    []point
+	closed bool
+}
+
+// maxSegments caps how finely one curve is flattened.
+const maxSegments = 1024
+
+// pathBuilder flattens path commands into subpaths. scale is the size of a
+// user unit in pixels, which sets how many segments curves need.
+type pathBuilder struct {
+	scale float64
+	paths []subpath
+	cur   point
+	start point
+}
+
+func (b *pathBuilder) moveTo(p point) {
+	b.paths = append(b.paths, subpath{pts: []point{p}})
+	b.cur, b.start = p, p
+}
+
+func (b *pathBuilder) lineTo(p point) {
+	if len(b.paths) == 0 || b.paths[len(b.paths)-1].closed {
+		b.moveTo(b.cur)
+	}
+	sp := &b.paths[len(b.paths)-1]
+	sp.pts = append(sp.pts, p)
+	b.cur = p
+}
+
+func (b *pathBuilder) close() {
+	if len(b.paths) > 0 && !b.paths[len(b.paths)-1].closed {
+		b.paths[len(b.paths)-1].closed = true
+	}
+	b.cur = b.start
+}
+
+// segments returns how many chords a curve of the given length in user
+// units needs to stay within a fraction of a pixel of it.
+func (b *pathBuilder) segments(length float64) int {
+	n := int(math.Ceil(2 * math.Sqrt(length*b.scale)))
+	return max(1, min(n, maxSegments))
+}
+
+func (b *pathBuilder) cubicTo(c1, c2, p point) {
+	p0 := b.cur
+	n := b.segments(p0.dist(c1) + c1.dist(c2) + c2.dist(p))
+	for i := 1; i <= n; i++ {
+		t := float64(i) / float64(n)
+		a, bb, c := lerp(p0, c1, t), lerp(c1, c2, t), lerp(c2, p, t)
+		b.lineTo(lerp(lerp(a, bb, t), lerp(bb, c, t), t))
+	}
+}
+
+func (b *pathBuilder) quadTo(c, p point) {
+	p0 := b.cur
+	n := b.segments(p0.dist(c) + c.dist(p))
+	for i := 1; i <= n; i++ {
+		t := float64(i) / float64(n)
+		b.lineTo(lerp(lerp(p0, c, t), lerp(c, p, t), t))
+	}
+}
+
+// arcTo draws an elliptical arc to p, converting the SVG endpoint
+// parameters to a center and angles as the SVG specification describes.
+func (b *pathBuilder) arcTo(rx, ry, rotation float64, large, sweep bool, p point) {
+	p0 := b.cur
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 || p0 == p {
+		b.lineTo(p)
+		return
+	}
+	phi := rotation * math.Pi / 180
+	cos, sin := math.Cos(phi), math.Sin(phi)
+	dx, dy := (p0.x-p.x)/2, (p0.y-p.y)/2
+	x1 := cos*dx + sin*dy
+	y1 := -sin*dx + cos*dy
+
+	// Scale up radii too small to reach p
+	if l := x1*x1/(rx*rx) + y1*y1/(ry*ry); l > 1 {
+		rx *= math.Sqrt(l)
+		ry *= math.Sqrt(l)
+	}
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	coef := math.Sqrt(math.Max(0, num/den))
+	if large == sweep {
+		coef = -coef
+	}
+	cx1 := coef * rx * y1 / ry
+	cy1 := -coef * ry * x1 / rx
+	cx := cos*cx1 - sin*cy1 + (p0.x+p.x)/2
+	cy := sin*cx1 + cos*cy1 + (p0.y+p.y)/2
+
+	angle := func(ux, uy, vx, vy float64) float64 {
+		return math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)
+	}
+	theta := angle(1, 0, (x1-cx1)/rx, (y1-cy1)/ry)
+	delta := angle((x1-cx1)/rx, (y1-cy1)/ry, (-x1-cx1)/rx, (-y1-cy1)/ry)
+	if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	} else if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	}
+
+	n := b.segments(math.Abs(delta) * max(rx, ry))
+	for i := 1; i < n; i++ {
+		t := theta + delta*float64(i)/float64(n)
+		ex, ey := rx*math.Cos(t), ry*math.Sin(t)
+		b.lineTo(point{cos*ex - sin*ey + cx, sin*ex + cos*ey + cy})
+	}
+	// Land exactly on p so rounding does not open closed shapes
+	b.lineTo(p)
+}
+
+// ellipse adds a closed ellipse.
+func (b *pathBuilder) ellipse(cx, cy, rx, ry float64) {
+	b.moveTo(point{cx + rx, cy})
+	b.arcTo(rx, ry, 0, false, true, point{cx - rx, cy})
+	b.arcTo(rx, ry, 0, false, true, point{cx + rx, cy})
+	b.close()
+}
+
+// parsePath adds the path data d, stopping at the first error as the SVG
+// specification asks.
+func (b *pathBuilder) parsePath(d string) {
+	sc := numberScanner{s: d}
+	var cmd byte
+	// ctrl is the last control point, for the smooth curve commands
+	var ctrl point
+	var lastCmd byte
+	for {
+		sc.skipSeparators()
+		if sc.i >= len(sc.s) {
+			return
+		}
+		if c := sc.s[sc.i]; strings.IndexByte("MmLlHhVvCcSsQqTtAaZz", c) >= 0 {
+			cmd = c
+			sc.i++
+		} else if cmd == 0 {
+			return
+		}
+
+		rel := cmd >= 'a'
+		at := func(x, y float64) point {
+			if rel {
+				return point{b.cur.x + x, b.cur.y + y}
+			}
+			return point{x, y}
+		}
+		nums := func(n int) ([]float64, bool) {
+			v := make([]float64, n)
+			for i := range v {
+				var ok bool
+				if v[i], ok = sc.next(); !ok {
+					return nil, false
+				}
+			}
+			return v, true
+		}
+
+		upper := cmd &^ 0x20
+		switch upper {
+		case 'Z':
+			b.close()
+			ctrl = b.cur
+			lastCmd = 'Z'
+			// Z takes no arguments, so a number after it is an error
+			// unless another command follows
+			cmd = 0
+			continue
+		case 'M', 'L', 'T':
+			v, ok := nums(2)
+			if !ok {
+				return
+			}
+			p := at(v[0], v[1])
+			switch upper {
+			case 'M':
+				b.moveTo(p)
+				// Further pairs after a moveto are linetos
+				if rel {
+					cmd = 'l'
+				} else {
+					cmd = 'L'
+				}
+			case 'L':
+				b.lineTo(p)
+			case 'T':
+				c := b.cur
+				if lastCmd == 'Q' || lastCmd == 'T' {
+					c = b.cur.mul(2).sub(ctrl)
+				}
+				b.quadTo(c, p)
+				ctrl = c
+			}
+			if upper != 'T' {
+				ctrl = p
+			}
+		case 'H', 'V':
+			v, ok := sc.next()
+			if !ok {
+				return
+			}
+			p := b.cur
+			switch {
+			case upper == 'H' && rel:
+				p.x += v
+			case upper == 'H':
+				p.x = v
+			case rel:
+				p.y += v
+			default:
+				p.y = v
+			}
+			b.lineTo(p)
+			ctrl = p
+		case 'C', 'S':
+			n := 6
+			if upper == 'S' {
+				n = 4
+			}
+			v, ok := nums(n)
+			if !ok {
+				return
+			}
+			c1 := b.cur
+			if upper == 'C' {
+				c1 = at(v[0], v[1])
+				v = v[2:]
+			} else if lastCmd == 'C' || lastCmd == 'S' {
+				c1 = b.cur.mul(2).sub(ctrl)
+			}
+			c2, p := at(v[0], v[1]), at(v[2], v[3])
+			b.cubicTo(c1, c2, p)
+			ctrl = c2
+		case 'Q':
+			v, ok := nums(4)
+			if !ok {
+				return
+			}
+			c, p := at(v[0], v[1]), at(v[2], v[3])
+			b.quadTo(c, p)
+			ctrl = c
+		case 'A':
+			r, ok := nums(3)
+			if !ok {
+				return
+			}
+			large, ok1 := sc.flag()
+			sweep, ok2 := sc.flag()
+			v, ok3 := nums(2)
+			if !ok1 || !ok2 || !ok3 {
+				return
+			}
+			p := at(v[0], v[1])
+			b.arcTo(r[0], r[1], r[2], large, sweep, p)
+			ctrl = p
+		}
+		lastCmd = upper
+	}
+}
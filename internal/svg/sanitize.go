@@ -0,0 +1,226 @@
+package svg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// unsafeElements are removed from sanitized SVGs along with everything
+// inside them. They run scripts, embed other documents, or, for set and
+// animate, can rewrite links into script URLs after sanitizing.
+var unsafeElements = map[string]bool{
+	"script": true, "foreignobject": true, "iframe": true, "embed": true,
+	"object": true, "handler": true, "listener": true, "set": true,
+	"animate": true, "animatemotion": true, "animatetransform": true,
+}
+
+// linkAttributes hold URLs.
+var linkAttributes = map[string]bool{"href": true, "src": true, "action": true, "formaction": true}
+
+// safeDataURL matches data URLs of raster images, which cannot run scripts.
+var safeDataURL = regexp.MustCompile(`^data:image/(png|jpeg|jpg|gif|webp|bmp)[;,]`)
+
+// cssURL matches url() references in style sheets and presentation
+// attributes.
+var cssURL = regexp.MustCompile(`(?i)url\(\s*(['"]?)([^'")]*)(['"]?)\s*\)`)
+
+// cssImport matches @import rules, which load other style sheets.
+var cssImport = regexp.MustCompile(`(?i)@import[^;]*;?`)
+
+// cssScript matches CSS that can run scripts in old browsers.
+var cssScript = regexp.MustCompile(`(?i)(expression\s*\(|javascript:|-moz-binding|behavior\s*:)`)
+
+// Sanitize removes everything from an SVG document that can run scripts or
+// load external resources: script and foreignObject elements, event handler
+// attributes, links that are not local fragments or raster data URLs,
+// external url() references and @import rules in CSS, DOCTYPE declarations,
+// and processing instructions. It returns the cleaned document and a
+// description of each kind of content removed.
+func Sanitize(data []byte) ([]byte, []string, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.Entity = xml.HTMLEntity
+
+	var out bytes.Buffer
+	var removed []string
+	seen := make(map[string]bool)
+	remove := func(format string, args ...any) {
+		what := fmt.Sprintf(format, args...)
+		if !seen[what] {
+			seen[what] = true
+			removed = append(removed, what)
+		}
+	}
+
+	var stack []xml.Name
+	// skip counts the open elements inside an unsafe element
+	skip := 0
+	// open is true while the last start tag is unfinished, so that empty
+	// elements can be written as <x/>
+	open := false
+	inStyle := false
+	root := false
+	for {
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid SVG: %w", err)
+		}
+
+		if end, ok := tok.(xml.EndElement); ok {
+			if len(stack) == 0 || stack[len(stack)-1] != end.Name {
+				return nil, nil, fmt.Errorf("invalid SVG: unexpected end element </%s>", qualified(end.Name))
+			}
+			stack = stack[:len(stack)-1]
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			stack = append(stack, start.Name)
+		}
+
+		if skip > 0 {
+			switch tok.(type) {
+			case xml.StartElement:
+				skip++
+			case xml.EndElement:
+				skip--
+			}
+			continue
+		}
+
+		if _, ok := tok.(xml.EndElement); !ok && open {
+			out.WriteString(">")
+			open = false
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			local := strings.ToLower(t.Name.Local)
+			if unsafeElements[local] {
+				remove("<%s> element", t.Name.Local)
+				skip = 1
+				continue
+			}
+			if !root {
+				if local != "svg" {
+					return nil, nil, fmt.Errorf("root element is <%s>, not <svg>", t.Name.Local)
+				}
+				root = true
+			}
+			inStyle = local == "style"
+			out.WriteString("<" + qualified(t.Name))
+			for _, attr := range t.Attr {
+				value, ok := sanitizeAttr(attr, remove)
+				if !ok {
+					continue
+				}
+				out.WriteString(" " + qualified(attr.Name) + `="`)
+				xml.EscapeText(&out, []byte(value))
+				out.WriteString(`"`)
+			}
+			open = true
+
+		case xml.EndElement:
+			if open {
+				out.WriteString("/>")
+				open = false
+			} else {
+				out.WriteString("</" + qualified(t.Name) + ">")
+			}
+			inStyle = false
+
+		case xml.CharData:
+			text := string(t)
+			if inStyle {
+				text = sanitizeCSS(text, remove)
+			}
+			xml.EscapeText(&out, []byte(text))
+
+		case xml.ProcInst:
+			if t.Target == "xml" {
+				out.WriteString("<?xml " + string(t.Inst) + "?>")
+			} else {
+				remove("<?%s?> processing instruction", t.Target)
+			}
+
+		case xml.Directive:
+			remove("DOCTYPE declaration")
+
+		case xml.Comment:
+			// Comments are dropped; some browsers parse conditional ones
+		}
+	}
+	if !root {
+		return nil, nil, errors.New("no <svg> element found")
+	}
+	if len(stack) > 0 {
+		return nil, nil, fmt.Errorf("invalid SVG: <%s> is not closed", qualified(stack[len(stack)-1]))
+	}
+	return out.Bytes(), removed, nil
+}
+
+// sanitizeAttr returns the cleaned value of attr, or false to drop it.
+func sanitizeAttr(attr xml.Attr, remove func(string, ...any)) (string, bool) {
+	local := strings.ToLower(attr.Name.Local)
+	value := attr.Value
+	if strings.HasPrefix(local, "on") {
+		remove("%s event handler", local)
+		return "", false
+	}
+	if linkAttributes[local] {
+		link := strings.TrimSpace(value)
+		if strings.HasPrefix(link, "#") || safeDataURL.MatchString(strings.ToLower(link)) {
+			return value, true
+		}
+		remove("external or script link in %s", local)
+		return "", false
+	}
+	if local == "attributename" && linkAttributes[strings.ToLower(value)] {
+		remove("animation of %s", value)
+		return "", false
+	}
+	if strings.Contains(strings.ToLower(value), "url(") || local == "style" {
+		return sanitizeCSS(value, remove), true
+	}
+	return value, true
+}
+
+// sanitizeCSS removes @import rules, script expressions, and url()
+// references to anything but local fragments and raster data URLs.
+func sanitizeCSS(css string, remove func(string, ...any)) string {
+	if cssImport.MatchString(css) {
+		remove("CSS @import")
+		css = cssImport.ReplaceAllString(css, "")
+	}
+	if cssScript.MatchString(css) {
+		remove("CSS script expression")
+		css = cssScript.ReplaceAllStringFunc(css, func(m string) string {
+			// Keep parentheses balanced so the rest of the rule parses
+			if strings.HasSuffix(m, "(") {
+				return "invalid("
+			}
+			return ""
+		})
+	}
+	return cssURL.ReplaceAllStringFunc(css, func(m string) string {
+		target := strings.TrimSpace(cssURL.FindStringSubmatch(m)[2])
+		if strings.HasPrefix(target, "#") || safeDataURL.MatchString(strings.ToLower(target)) {
+			return m
+		}
+		remove("external url() reference")
+		return "none"
+	})
+}
+
+// qualified returns the name as written, with its prefix.
+func qualified(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+	return n.Space + ":" + n.Local
+}
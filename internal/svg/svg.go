@@ -0,0 +1,583 @@
+// Package svg sanitizes SVG documents and rasterizes them to images for
+// clients that cannot render SVG themselves. The rasterizer covers the
+// static shape subset of SVG — paths, basic shapes, groups, use,
+// transforms, viewBox, solid fills and strokes — and reports what it
+// skipped. It never loads external resources.
+package svg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"strings"
+)
+
+// MaxSize caps each dimension of a rasterized image.
+const MaxSize = 4096
+
+// Limits that keep hostile documents from exhausting memory or time.
+const (
+	maxElements = 100000
+	maxDepth    = 256
+	maxUseDepth = 16
+	// maxDraws caps the elements drawn, counting each time a <use>
+	// draws its target, since nested uses multiply
+	maxDraws = 100000
+)
+
+// Namespaces the rasterizer reads. Elements in other namespaces are
+// ignored.
+const (
+	nsSVG   = "http://www.w3.org/2000/svg"
+	nsXLink = "http://www.w3.org/1999/xlink"
+)
+
+// node is an element of a parsed SVG document.
+type node struct {
+	name     string
+	attrs    map[string]string
+	children []*node
+}
+
+// document is a parsed SVG document.
+type document struct {
+	root *node
+	ids  map[string]*node
+}
+
+// parse reads an SVG document into a tree of the elements the rasterizer
+// understands.
+func parse(data []byte) (*document, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.Entity = xml.HTMLEntity
+	doc := &document{ids: make(map[string]*node)}
+	var stack []*node
+	count := 0
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid SVG: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if count++; count > maxElements {
+				return nil, fmt.Errorf("SVG has more than %d elements", maxElements)
+			}
+			if len(stack) >= maxDepth {
+				return nil, fmt.Errorf("SVG is nested more than %d deep", maxDepth)
+			}
+			n := &node{name: t.Name.Local, attrs: make(map[string]string, len(t.Attr))}
+			if t.Name.Space != nsSVG && t.Name.Space != "" {
+				// Keep foreign elements in the tree so nesting stays
+				// right, but never draw them
+				n.name = ""
+			}
+			for _, a := range t.Attr {
+				switch {
+				case a.Name.Space == "":
+					n.attrs[a.Name.Local] = a.Value
+				case a.Name.Space == nsXLink && a.Name.Local == "href":
+					if _, ok := n.attrs["href"]; !ok {
+						n.attrs["href"] = a.Value
+					}
+				}
+			}
+			if id := n.attrs["id"]; id != "" {
+				if _, dup := doc.ids[id]; !dup {
+					doc.ids[id] = n
+				}
+			}
+			if len(stack) == 0 {
+				if doc.root != nil {
+					return nil, errors.New("invalid SVG: more than one root element")
+				}
+				doc.root = n
+			} else {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if doc.root == nil || doc.root.name != "svg" {
+		return nil, errors.New("no <svg> root element found")
+	}
+	return doc, nil
+}
+
+// Size returns the intrinsic size of an SVG document in pixels, from its
+// width and height or else its viewBox, defaulting to 300x150 as browsers
+// do.
+func Size(data []byte) (float64, float64, error) {
+	doc, err := parse(data)
+	if err != nil {
+		return 0, 0, err
+	}
+	w, h := doc.size()
+	return w, h, nil
+}
+
+func (doc *document) size() (float64, float64) {
+	vb, hasViewBox := parseViewBox(doc.root.attrs["viewBox"])
+	w, okW := parseLength(doc.root.attrs["width"], 0)
+	h, okH := parseLength(doc.root.attrs["height"], 0)
+	if strings.HasSuffix(doc.root.attrs["width"], "%") {
+		okW = false
+	}
+	if strings.HasSuffix(doc.root.attrs["height"], "%") {
+		okH = false
+	}
+	switch {
+	case okW && okH:
+	case hasViewBox && okW:
+		h = w * vb[3] / vb[2]
+	case hasViewBox && okH:
+		w = h * vb[2] / vb[3]
+	case hasViewBox:
+		w, h = vb[2], vb[3]
+	default:
+		if !okW {
+			w = 300
+		}
+		if !okH {
+			h = 150
+		}
+	}
+	return w, h
+}
+
+// parseViewBox parses a viewBox into x, y, width, and height.
+func parseViewBox(s string) ([4]float64, bool) {
+	v := parseNumbers(s)
+	if len(v) != 4 || v[2] <= 0 || v[3] <= 0 {
+		return [4]float64{}, false
+	}
+	return [4]float64{v[0], v[1], v[2], v[3]}, true
+}
+
+// viewBoxTransform maps a viewBox onto a viewport of width w and height h
+// following preserveAspectRatio.
+func viewBoxTransform(vb [4]float64, w, h float64, preserve string) matrix {
+	sx, sy := w/vb[2], h/vb[3]
+	fields := strings.Fields(preserve)
+	align, slice := "xMidYMid", false
+	if len(fields) > 0 {
+		align = fields[0]
+	}
+	if len(fields) > 1 {
+		slice = fields[1] == "slice"
+	}
+	if align == "none" {
+		return matrix{a: sx, d: sy, e: -vb[0] * sx, f: -vb[1] * sy}
+	}
+	s := math.Min(sx, sy)
+	if slice {
+		s = math.Max(sx, sy)
+	}
+	ax, ay := 0.5, 0.5
+	if strings.Contains(align, "xMin") {
+		ax = 0
+	} else if strings.Contains(align, "xMax") {
+		ax = 1
+	}
+	if strings.Contains(align, "YMin") {
+		ay = 0
+	} else if strings.Contains(align, "YMax") {
+		ay = 1
+	}
+	return matrix{
+		a: s, d: s,
+		e: -vb[0]*s + (w-vb[2]*s)*ax,
+		f: -vb[1]*s + (h-vb[3]*s)*ay,
+	}
+}
+
+// Rasterize renders an SVG document to an image of the given size. If only
+// one dimension is given, the other follows the document's aspect ratio;
+// if neither is, the document's own size is used. Text, embedded images,
+// filters, clipping, masks, and style sheets are skipped, and gradients
+// are drawn in their average color; the returned notes say which of these
+// the document used.
+func Rasterize(data []byte, width, height int) (*image.RGBA, []string, error) {
+	doc, err := parse(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	iw, ih := doc.size()
+	if iw <= 0 || ih <= 0 {
+		return nil, nil, errors.New("SVG has no area")
+	}
+	switch {
+	case width > 0 && height > 0:
+	case width > 0:
+		height = int(math.Round(float64(width) * ih / iw))
+	case height > 0:
+		width = int(math.Round(float64(height) * iw / ih))
+	default:
+		width, height = int(math.Ceil(iw)), int(math.Ceil(ih))
+	}
+	width, height = max(width, 1), max(height, 1)
+	if width > MaxSize || height > MaxSize {
+		return nil, nil, fmt.Errorf("image would be %dx%d; the maximum is %dx%d", width, height, MaxSize, MaxSize)
+	}
+
+	// The root's width and height only set the intrinsic size; the
+	// requested size is its viewport
+	m := matrix{a: float64(width) / iw, d: float64(height) / ih}
+	if vb, ok := parseViewBox(doc.root.attrs["viewBox"]); ok {
+		m = viewBoxTransform(vb, float64(width), float64(height), doc.root.attrs["preserveAspectRatio"])
+	}
+
+	r := &renderer{
+		doc:  doc,
+		dst:  image.NewRGBA(image.Rect(0, 0, width, height)),
+		seen: make(map[string]bool),
+	}
+	st := defaultStyle()
+	st.apply(doc.root)
+	r.children(doc.root, m, st, 0)
+	return r.dst, r.notes, nil
+}
+
+// renderer draws a document.
+type renderer struct {
+	doc   *document
+	dst   *image.RGBA
+	notes []string
+	seen  map[string]bool
+	draws int
+}
+
+func (r *renderer) note(s string) {
+	if !r.seen[s] {
+		r.seen[s] = true
+		r.notes = append(r.notes, s)
+	}
+}
+
+func (r *renderer) children(n *node, m matrix, st style, useDepth int) {
+	for _, c := range n.children {
+		r.draw(c, m, st, useDepth)
+	}
+}
+
+// draw renders n and its children with the transform m and the style st
+// inherited from n's parent.
+func (r *renderer) draw(n *node, m matrix, st style, useDepth int) {
+	if n.name == "" {
+		return
+	}
+	if r.draws++; r.draws > maxDraws {
+		r.note(fmt.Sprintf("drawing stopped after %d elements", maxDraws))
+		return
+	}
+	st.apply(n)
+	if st.display == "none" {
+		return
+	}
+	if t, ok := n.attrs["transform"]; ok {
+		m = m.mul(parseTransform(t))
+	}
+	for _, attr := range []string{"filter", "clip-path", "mask"} {
+		if v := st.props[attr]; v != "" && v != "none" {
+			r.note(attr + " is ignored")
+		}
+	}
+
+	switch n.name {
+	case "g", "a":
+		r.children(n, m, st, useDepth)
+	case "switch":
+		// Draw the first child, as a browser meeting no conditions would
+		for _, c := range n.children {
+			if c.name != "" {
+				r.draw(c, m, st, useDepth)
+				break
+			}
+		}
+	case "svg":
+		x, _ := parseLength(n.attrs["x"], 0)
+		y, _ := parseLength(n.attrs["y"], 0)
+		m = m.mul(matrix{a: 1, d: 1, e: x, f: y})
+		if vb, ok := parseViewBox(n.attrs["viewBox"]); ok {
+			w, okW := parseLength(n.attrs["width"], vb[2])
+			h, okH := parseLength(n.attrs["height"], vb[3])
+			if okW && okH {
+				m = m.mul(viewBoxTransform(vb, w, h, n.attrs["preserveAspectRatio"]))
+			}
+		}
+		r.children(n, m, st, useDepth)
+	case "use":
+		if useDepth >= maxUseDepth {
+			r.note("deeply nested <use> is ignored")
+			return
+		}
+		target := r.doc.ids[strings.TrimPrefix(n.attrs["href"], "#")]
+		if target == nil || !strings.HasPrefix(n.attrs["href"], "#") {
+			return
+		}
+		x, _ := parseLength(n.attrs["x"], 0)
+		y, _ := parseLength(n.attrs["y"], 0)
+		m = m.mul(matrix{a: 1, d: 1, e: x, f: y})
+		if target.name == "symbol" {
+			st.apply(target)
+			if vb, ok := parseViewBox(target.attrs["viewBox"]); ok {
+				w, okW := parseLength(n.attrs["width"], 0)
+				h, okH := parseLength(n.attrs["height"], 0)
+				if !okW || !okH {
+					w, h = vb[2], vb[3]
+				}
+				m = m.mul(viewBoxTransform(vb, w, h, target.attrs["preserveAspectRatio"]))
+			}
+			r.children(target, m, st, useDepth+1)
+			return
+		}
+		r.draw(target, m, st, useDepth+1)
+	case "rect", "circle", "ellipse", "line", "polyline", "polygon", "path":
+		r.shape(n, m, st)
+	case "text":
+		r.note("text is not rendered")
+	case "image":
+		r.note("embedded images are not rendered")
+	case "script":
+		// Scripts are never run, and draw nothing themselves
+	case "style":
+		r.note("style sheets are ignored; only style attributes apply")
+	case "defs", "symbol", "clipPath", "mask", "pattern", "marker",
+		"linearGradient", "radialGradient", "filter", "title", "desc", "metadata":
+		// Only drawn through references
+	default:
+		r.note(fmt.Sprintf("<%s> is not supported", n.name))
+	}
+}
+
+// shape draws a basic shape or path.
+func (r *renderer) shape(n *node, m matrix, st style) {
+	b := &pathBuilder{scale: m.scale()}
+	num := func(name string) float64 {
+		v, _ := parseLength(n.attrs[name], 0)
+		return v
+	}
+	switch n.name {
+	case "rect":
+		x, y, w, h := num("x"), num("y"), num("width"), num("height")
+		if w <= 0 || h <= 0 {
+			return
+		}
+		rx, okX := parseLength(n.attrs["rx"], 0)
+		ry, okY := parseLength(n.attrs["ry"], 0)
+		if !okX {
+			rx = ry
+		}
+		if !okY {
+			ry = rx
+		}
+		rx, ry = math.Min(math.Max(rx, 0), w/2), math.Min(math.Max(ry, 0), h/2)
+		if rx == 0 || ry == 0 {
+			b.moveTo(point{x, y})
+			b.lineTo(point{x + w, y})
+			b.lineTo(point{x + w, y + h})
+			b.lineTo(point{x, y + h})
+			b.close()
+			break
+		}
+		b.moveTo(point{x + rx, y})
+		b.lineTo(point{x + w - rx, y})
+		b.arcTo(rx, ry, 0, false, true, point{x + w, y + ry})
+		b.lineTo(point{x + w, y + h - ry})
+		b.arcTo(rx, ry, 0, false, true, point{x + w - rx, y + h})
+		b.lineTo(point{x + rx, y + h})
+		b.arcTo(rx, ry, 0, false, true, point{x, y + h - ry})
+		b.lineTo(point{x, y + ry})
+		b.arcTo(rx, ry, 0, false, true, point{x + rx, y})
+		b.close()
+	case "circle":
+		if rad := num("r"); rad > 0 {
+			b.ellipse(num("cx"), num("cy"), rad, rad)
+		}
+	case "ellipse":
+		if rx, ry := num("rx"), num("ry"); rx > 0 && ry > 0 {
+			b.ellipse(num("cx"), num("cy"), rx, ry)
+		}
+	case "line":
+		b.moveTo(point{num("x1"), num("y1")})
+		b.lineTo(point{num("x2"), num("y2")})
+	case "polyline", "polygon":
+		v := parseNumbers(n.attrs["points"])
+		for i := 0; i+1 < len(v); i += 2 {
+			if i == 0 {
+				b.moveTo(point{v[0], v[1]})
+			} else {
+				b.lineTo(point{v[i], v[i+1]})
+			}
+		}
+		if n.name == "polygon" {
+			b.close()
+		}
+	case "path":
+		b.parsePath(n.attrs["d"])
+	}
+	if len(b.paths) == 0 || st.visibility == "hidden" || st.visibility == "collapse" {
+		return
+	}
+
+	device := make([]subpath, len(b.paths))
+	for i, sp := range b.paths {
+		pts := make([]point, len(sp.pts))
+		for j, p := range sp.pts {
+			pts[j] = m.apply(p)
+		}
+		device[i] = subpath{pts: pts, closed: sp.closed}
+	}
+
+	if c, ok := r.paint(st.props["fill"], st); ok && n.name != "line" {
+		c.a *= parseOpacity(st.props["fill-opacity"]) * st.opacity
+		polys := make([][]point, 0, len(device))
+		for _, sp := range device {
+			if len(sp.pts) > 2 {
+				polys = append(polys, sp.pts)
+			}
+		}
+		fillPolygons(r.dst, polys, st.props["fill-rule"] == "evenodd", c)
+	}
+	if c, ok := r.paint(st.props["stroke"], st); ok {
+		width := 1.0
+		if v, ok := parseLength(st.props["stroke-width"], 0); ok {
+			width = v
+		}
+		width *= m.scale()
+		if width <= 0 {
+			return
+		}
+		c.a *= parseOpacity(st.props["stroke-opacity"]) * st.opacity
+		fillPolygons(r.dst, strokePolygons(device, width, st.props["stroke-linecap"]), false, c)
+	}
+}
+
+// paint resolves a fill or stroke value to a color.
+func (r *renderer) paint(value string, st style) (rgba, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "none" {
+		return rgba{}, false
+	}
+	if value == "currentColor" {
+		return parseColor(st.props["color"])
+	}
+	if rest, ok := strings.CutPrefix(value, "url("); ok {
+		ref, fallback, _ := strings.Cut(rest, ")")
+		ref = strings.Trim(strings.TrimSpace(ref), `'"`)
+		if target := r.doc.ids[strings.TrimPrefix(ref, "#")]; target != nil && strings.HasPrefix(ref, "#") {
+			if c, ok := r.gradient(target, 0); ok {
+				r.note("gradients are drawn in their average color")
+				return c, true
+			}
+			if target.name == "pattern" {
+				r.note("patterns are not rendered")
+			}
+		}
+		return r.paint(fallback, st)
+	}
+	return parseColor(value)
+}
+
+// gradient returns the average color of a gradient's stops, following
+// href to the gradient they are inherited from.
+func (r *renderer) gradient(n *node, depth int) (rgba, bool) {
+	if n.name != "linearGradient" && n.name != "radialGradient" {
+		return rgba{}, false
+	}
+	var sum rgba
+	count := 0
+	for _, stop := range n.children {
+		if stop.name != "stop" {
+			continue
+		}
+		st := defaultStyle()
+		st.apply(stop)
+		c, ok := parseColor(st.props["stop-color"])
+		if !ok {
+			c = rgba{a: 1}
+		}
+		a := c.a * parseOpacity(st.props["stop-opacity"])
+		sum.r += c.r * a
+		sum.g += c.g * a
+		sum.b += c.b * a
+		sum.a += a
+		count++
+	}
+	if count == 0 {
+		if href := n.attrs["href"]; strings.HasPrefix(href, "#") && depth < maxUseDepth {
+			if target := r.doc.ids[href[1:]]; target != nil {
+				return r.gradient(target, depth+1)
+			}
+		}
+		return rgba{}, false
+	}
+	if sum.a == 0 {
+		return rgba{}, true
+	}
+	return rgba{sum.r / sum.a, sum.g / sum.a, sum.b / sum.a, sum.a / float64(count)}, true
+}
+
+// strokePolygons outlines the subpaths with a pen of the given width.
+// Every polygon winds the same way, so filling them with the nonzero rule
+// gives their union. Joins are round; caps are butt, round, or square.
+func strokePolygons(paths []subpath, width float64, lineCap string) [][]point {
+	hw := width / 2
+	var polys [][]point
+	for _, sp := range paths {
+		pts := sp.pts
+		if len(pts) < 2 {
+			continue
+		}
+		n := len(pts) - 1
+		if sp.closed {
+			n = len(pts)
+		}
+		for i := range n {
+			p, q := pts[i], pts[(i+1)%len(pts)]
+			length := p.dist(q)
+			if length == 0 {
+				continue
+			}
+			d := q.sub(p).mul(1 / length)
+			if !sp.closed && lineCap == "square" {
+				if i == 0 {
+					p = p.sub(d.mul(hw))
+				}
+				if i == n-1 {
+					q = q.add(d.mul(hw))
+				}
+			}
+			nrm := point{-d.y * hw, d.x * hw}
+			polys = append(polys, []point{p.add(nrm), q.add(nrm), q.sub(nrm), p.sub(nrm)})
+		}
+		for i, p := range pts {
+			end := !sp.closed && (i == 0 || i == len(pts)-1)
+			if !end || lineCap == "round" {
+				polys = append(polys, disk(p, hw))
+			}
+		}
+	}
+	return polys
+}
+
+// disk approximates a circle, winding the same way as stroke segments.
+func disk(c point, r float64) []point {
+	n := max(8, min(256, int(math.Ceil(4*math.Sqrt(r)))+4))
+	pts := make([]point, n)
+	for i := range pts {
+		t := -2 * math.Pi * float64(i) / float64(n)
+		pts[i] = point{c.x + r*math.Cos(t), c.y + r*math.Sin(t)}
+	}
+	return pts
+}
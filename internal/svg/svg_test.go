@@ -0,0 +1,224 @@
+package svg
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	input := `<?xml version="1.0"?>
+<!DOCTYPE svg>
+<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" onload="alert(1)" viewBox="0 0 10 10">
+  <script>alert(2)</script>
+  <style>@import url(http://evil.example/x.css); rect { fill: url(http://evil.example/p.svg#a); stroke: url(#g) }</style>
+  <a xlink:href="javascript:alert(3)"><rect width="5" height="5" onclick="x()"/></a>
+  <use href="#shape"/>
+  <image href="data:image/png;base64,AAAA"/>
+  <image href="https://tracker.example/pixel.png"/>
+  <foreignObject><div xmlns="http://www.w3.org/1999/xhtml">hi</div></foreignObject>
+  <set attributeName="href" to="javascript:alert(4)"/>
+  <circle r="1" style="fill: red; background: url(http://evil.example/bg.png)"/>
+</svg>`
+	out, removed, err := Sanitize([]byte(input))
+	if err != nil {
+		t.Fatalf("Sanitize: %v", err)
+	}
+	got := string(out)
+	for _, bad := range []string{"alert", "evil.example", "tracker.example", "foreignObject", "<script", "DOCTYPE", "onclick", "@import"} {
+		if strings.Contains(got, bad) {
+			t.Errorf("sanitized SVG still contains %q:\n%s", bad, got)
+		}
+	}
+	for _, good := range []string{`<use href="#shape"/>`, `data:image/png;base64,AAAA`, `stroke: url(#g)`, `fill: red`, `<rect width="5" height="5"/>`, `xmlns:xlink=`, `<?xml version="1.0"?>`} {
+		if !strings.Contains(got, good) {
+			t.Errorf("sanitized SVG lost %q:\n%s", good, got)
+		}
+	}
+	for _, want := range []string{"<script> element", "onload event handler", "external or script link in href", "CSS @import", "external url() reference", "DOCTYPE declaration", "<foreignObject> element", "<set> element"} {
+		if !slices.Contains(removed, want) {
+			t.Errorf("removed = %q, want it to include %q", removed, want)
+		}
+	}
+}
+
+func TestSanitizeRejects(t *testing.T) {
+	for name, input := range map[string]string{
+		"not svg":    `<html><body/></html>`,
+		"unclosed":   `<svg><g></svg>`,
+		"not xml":    `hello`,
+		"bad entity": `<svg>&xxe;</svg>`,
+	} {
+		if _, _, err := Sanitize([]byte(input)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// pixel returns the color at x, y as 8-bit RGBA.
+func pixel(img *image.RGBA, x, y int) [4]uint8 {
+	i := img.PixOffset(x, y)
+	return [4]uint8(img.Pix[i : i+4])
+}
+
+func TestRasterizeShapes(t *testing.T) {
+	input := `<svg xmlns="http://www.w3.org/2000/svg" width="20" height="10" viewBox="0 0 200 100">
+  <rect width="100" height="100" fill="#ff0000"/>
+  <circle cx="150" cy="50" r="40" style="fill: rgb(0, 0, 255)"/>
+  <path d="M0 0 L10 0" stroke="lime" stroke-width="0"/>
+  <text x="10" y="10">ignored</text>
+</svg>`
+	img, notes, err := Rasterize([]byte(input), 40, 0)
+	if err != nil {
+		t.Fatalf("Rasterize: %v", err)
+	}
+	if got := img.Bounds().Size(); got != (image.Point{40, 20}) {
+		t.Fatalf("size = %v, want 40x20 from the aspect ratio", got)
+	}
+	if got := pixel(img, 5, 10); got != [4]uint8{255, 0, 0, 255} {
+		t.Errorf("rect pixel = %v, want opaque red", got)
+	}
+	if got := pixel(img, 30, 10); got != [4]uint8{0, 0, 255, 255} {
+		t.Errorf("circle center = %v, want opaque blue", got)
+	}
+	if got := pixel(img, 39, 0); got[3] != 0 {
+		t.Errorf("corner outside the circle = %v, want transparent", got)
+	}
+	if !slices.Contains(notes, "text is not rendered") {
+		t.Errorf("notes = %q, want a note about text", notes)
+	}
+}
+
+func TestRasterizeCoverage(t *testing.T) {
+	// A rectangle covering the left half of the middle pixel column
+	input := `<svg xmlns="http://www.w3.org/2000/svg" width="3" height="1"><rect width="1.5" height="1"/></svg>`
+	img, _, err := Rasterize([]byte(input), 0, 0)
+	if err != nil {
+		t.Fatalf("Rasterize: %v", err)
+	}
+	if a := pixel(img, 0, 0)[3]; a != 255 {
+		t.Errorf("covered pixel alpha = %d, want 255", a)
+	}
+	if a := pixel(img, 1, 0)[3]; a < 120 || a > 135 {
+		t.Errorf("half-covered pixel alpha = %d, want about 128", a)
+	}
+	if a := pixel(img, 2, 0)[3]; a != 0 {
+		t.Errorf("uncovered pixel alpha = %d, want 0", a)
+	}
+}
+
+func TestRasterizeFillRule(t *testing.T) {
+	// Two nested squares wound the same way: nonzero fills the hole,
+	// evenodd leaves it empty
+	d := `M0 0 H10 V10 H0 Z M3 3 H7 V7 H3 Z`
+	for rule, wantAlpha := range map[string]uint8{"nonzero": 255, "evenodd": 0} {
+		input := `<svg xmlns="http://www.w3.org/2000/svg" width="10" height="10"><path fill-rule="` + rule + `" d="` + d + `"/></svg>`
+		img, _, err := Rasterize([]byte(input), 0, 0)
+		if err != nil {
+			t.Fatalf("Rasterize: %v", err)
+		}
+		if a := pixel(img, 5, 5)[3]; a != wantAlpha {
+			t.Errorf("%s: center alpha = %d, want %d", rule, a, wantAlpha)
+		}
+		if a := pixel(img, 1, 1)[3]; a != 255 {
+			t.Errorf("%s: ring alpha = %d, want 255", rule, a)
+		}
+	}
+}
+
+func TestRasterizeStrokeAndTransforms(t *testing.T) {
+	input := `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="20" height="20">
+  <defs><rect id="box" width="4" height="4" fill="blue"/></defs>
+  <g transform="translate(10 0)"><use xlink:href="#box" x="2" y="2"/></g>
+  <polyline points="0 10 20 10" fill="none" stroke="black" stroke-width="2"/>
+  <g opacity="0.5"><rect x="0" y="15" width="5" height="5" fill="white"/></g>
+</svg>`
+	img, _, err := Rasterize([]byte(input), 0, 0)
+	if err != nil {
+		t.Fatalf("Rasterize: %v", err)
+	}
+	if got := pixel(img, 13, 3); got != [4]uint8{0, 0, 255, 255} {
+		t.Errorf("used rect = %v, want opaque blue at (13, 3)", got)
+	}
+	if got := pixel(img, 3, 3); got[3] != 0 {
+		t.Errorf("defs content was drawn in place: %v", got)
+	}
+	if got := pixel(img, 10, 9); got != [4]uint8{0, 0, 0, 255} {
+		t.Errorf("stroke pixel = %v, want opaque black", got)
+	}
+	if got := pixel(img, 10, 12); got[3] != 0 {
+		t.Errorf("pixel beside stroke = %v, want transparent", got)
+	}
+	if got := pixel(img, 2, 17); got[3] < 120 || got[3] > 135 {
+		t.Errorf("half-opaque rect = %v, want alpha about 128", got)
+	}
+}
+
+func TestRasterizeLimits(t *testing.T) {
+	input := `<svg xmlns="http://www.w3.org/2000/svg" width="10" height="10"/>`
+	if _, _, err := Rasterize([]byte(input), MaxSize+1, 10); err == nil {
+		t.Error("expected an error for an oversized image")
+	}
+	if _, _, err := Rasterize([]byte(`<html/>`), 10, 10); err == nil {
+		t.Error("expected an error for a non-SVG document")
+	}
+
+	// A use that refers to itself must not recurse forever
+	loop := `<svg xmlns="http://www.w3.org/2000/svg" width="10" height="10"><g id="a"><use href="#a"/></g></svg>`
+	if _, _, err := Rasterize([]byte(loop), 0, 0); err != nil {
+		t.Errorf("Rasterize: %v", err)
+	}
+
+	// Nested uses that each draw the level below twice must stop
+	var fanout strings.Builder
+	fanout.WriteString(`<svg xmlns="http://www.w3.org/2000/svg" width="10" height="10"><defs><rect id="l0" width="1" height="1"/>`)
+	for i := 1; i <= 20; i++ {
+		fmt.Fprintf(&fanout, `<g id="l%d"><use href="#l%d"/><use href="#l%d"/></g>`, i, i-1, i-1)
+	}
+	fanout.WriteString(`</defs><use href="#l20"/></svg>`)
+	_, notes, err := Rasterize([]byte(fanout.String()), 0, 0)
+	if err != nil {
+		t.Fatalf("Rasterize: %v", err)
+	}
+	if !slices.Contains(notes, fmt.Sprintf("drawing stopped after %d elements", maxDraws)) {
+		t.Errorf("notes = %q, want drawing to stop", notes)
+	}
+}
+
+func TestPathArc(t *testing.T) {
+	b := &pathBuilder{scale: 10}
+	b.parsePath("M0 0 a5 5 0 1 0 10 0 z")
+	pts := b.paths[0].pts
+	if got := pts[len(pts)-1]; got != (point{10, 0}) {
+		t.Errorf("arc ends at %v, want (10, 0)", got)
+	}
+	// A half circle of radius 5 from the sweep-flag side stays on it
+	for _, p := range pts[1:] {
+		if r := p.dist(point{5, 0}); math.Abs(r-5) > 1e-9 {
+			t.Errorf("arc point %v is %v from the center, want 5", p, r)
+		}
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	tests := map[string]rgba{
+		"#f00":                 {1, 0, 0, 1},
+		"#00ff0080":            {0, 1, 0, 128.0 / 255},
+		"rgb(0, 0, 255)":       {0, 0, 1, 1},
+		"rgba(255,255,255,.5)": {1, 1, 1, 0.5},
+		"rgb(100% 0% 0%)":      {1, 0, 0, 1},
+		" Navy ":               {0, 0, 128.0 / 255, 1},
+	}
+	for in, want := range tests {
+		got, ok := parseColor(in)
+		if !ok || got != want {
+			t.Errorf("parseColor(%q) = %v, %v; want %v", in, got, ok, want)
+		}
+	}
+	if _, ok := parseColor("url(#g)"); ok {
+		t.Error("parseColor accepted a paint server reference")
+	}
+}
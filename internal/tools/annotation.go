@@ -0,0 +1,144 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/annotation"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/security"
+	"github.com/spf13/cast"
+)
+
+// NewSetAnnotationTool creates the set_annotation tool.
+func NewSetAnnotationTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"set_annotation",
+		mcp.WithDescription("Attach a short note and tags (e.g. 'reviewed', 'needs-refactor') to a file or directory. Annotations persist across calls and, with a state directory, across sessions. Replaces any existing annotation; an empty note with no tags removes it."),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Set Annotation",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  boolPtr(true),
+		}),
+		mcp.WithString("path", mcp.Description("File or directory to annotate"), mcp.Required()),
+		mcp.WithString("note", mcp.Description(fmt.Sprintf("Free-form note (max %d bytes)", annotation.MaxNoteLength))),
+		mcp.WithArray("tags", mcp.Description(fmt.Sprintf("Tags to attach (max %d)", annotation.MaxTags)), mcp.Items(map[string]any{"type": "string"})),
+	)
+}
+
+// HandleSetAnnotation handles the set_annotation tool.
+func HandleSetAnnotation(ctx context.Context, reg *registry.Registry, store *annotation.Store, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	note := cast.ToString(request.Params.Arguments["note"])
+
+	var tags []string
+	if tagsArg, ok := request.Params.Arguments["tags"].([]interface{}); ok {
+		for _, t := range tagsArg {
+			tags = append(tags, cast.ToString(t))
+		}
+	}
+
+	resolvedPath, err := reg.Validate(path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	// A path deleted in the overlay is gone as far as the agent can tell,
+	// though its annotation can still be removed
+	if _, err := readTarget(reg, resolvedPath); err != nil && (note != "" || len(tags) > 0) {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+
+	a, err := store.Set(resolvedPath, note, tags)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to set annotation: %w", err).Error()), nil
+	}
+
+	if a.Note == "" && len(a.Tags) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("Removed annotation from %s", resolvedPath)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Annotated %s\n%s", resolvedPath, formatAnnotation(a))), nil
+}
+
+// NewGetAnnotationsTool creates the get_annotations tool.
+func NewGetAnnotationsTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"get_annotations",
+		mcp.WithDescription("List annotations left with set_annotation, for a path and everything beneath it, optionally filtered by tag."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path", mcp.Description("File or directory to list annotations for (default: all allowed directories)")),
+		mcp.WithString("tag", mcp.Description("Only list annotations carrying this tag")),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleGetAnnotations handles the get_annotations tool.
+func HandleGetAnnotations(ctx context.Context, reg *registry.Registry, store *annotation.Store, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	tag := strings.TrimSpace(cast.ToString(request.Params.Arguments["tag"]))
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	var resolvedPath string
+	if path != "" {
+		var err error
+		resolvedPath, err = reg.Validate(path)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+		}
+	}
+
+	// Annotations left under directories that are no longer allowed stay hidden
+	allowedDirs := reg.GetResolved()
+	annotations := []annotation.Annotation{}
+	for _, a := range store.List(resolvedPath) {
+		if !security.IsPathWithinAllowedDirectories(a.Path, allowedDirs) {
+			continue
+		}
+		// So do those of paths deleted in the overlay
+		if _, err := readTarget(reg, a.Path); err != nil {
+			continue
+		}
+		if tag != "" && !a.HasTag(tag) {
+			continue
+		}
+		annotations = append(annotations, a)
+	}
+
+	if format == "json" {
+		jsonResult, err := json.MarshalIndent(annotations, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(jsonResult)), nil
+	}
+
+	if len(annotations) == 0 {
+		return mcp.NewToolResultText("No annotations found"), nil
+	}
+
+	var result strings.Builder
+	for i, a := range annotations {
+		if i > 0 {
+			result.WriteString("\n")
+		}
+		fmt.Fprintf(&result, "%s\n%s", a.Path, formatAnnotation(a))
+	}
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// formatAnnotation renders the tags and note of a, indented.
+func formatAnnotation(a annotation.Annotation) string {
+	var b strings.Builder
+	if len(a.Tags) > 0 {
+		fmt.Fprintf(&b, "  tags: %s\n", strings.Join(a.Tags, ", "))
+	}
+	for _, line := range strings.Split(a.Note, "\n") {
+		if line != "" {
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/annotation"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+)
+
+func TestAnnotations(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	store, err := annotation.NewStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := func(ctx context.Context, reg *registry.Registry, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return HandleSetAnnotation(ctx, reg, store, req)
+	}
+	get := func(ctx context.Context, reg *registry.Registry, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return HandleGetAnnotations(ctx, reg, store, req)
+	}
+
+	writePackTree(t, tmpDir, map[string]string{"src/a.go": "a", "src/b.go": "b", "docs/c.md": "c"})
+	src := filepath.Join(tmpDir, "src")
+
+	for _, args := range []map[string]any{
+		{"path": filepath.Join(src, "a.go"), "note": "checked error paths", "tags": []interface{}{"reviewed"}},
+		{"path": filepath.Join(src, "b.go"), "tags": []interface{}{"needs-refactor", "reviewed"}},
+		{"path": filepath.Join(tmpDir, "docs", "c.md"), "note": "outdated"},
+	} {
+		if result := callTool(t, set, reg, args); result.IsError {
+			t.Fatalf("set_annotation failed: %s", resultText(result))
+		}
+	}
+
+	tests := []struct {
+		name        string
+		args        map[string]any
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:     "all",
+			args:     map[string]any{},
+			contains: []string{"a.go", "b.go", "c.md", "checked error paths", "tags: needs-refactor, reviewed"},
+		},
+		{
+			name:        "under directory",
+			args:        map[string]any{"path": src},
+			contains:    []string{"a.go", "b.go"},
+			notContains: []string{"c.md"},
+		},
+		{
+			name:        "by tag",
+			args:        map[string]any{"tag": "needs-refactor"},
+			contains:    []string{"b.go"},
+			notContains: []string{"a.go", "c.md"},
+		},
+		{
+			name:     "no match",
+			args:     map[string]any{"tag": "missing"},
+			contains: []string{"No annotations found"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := resultText(callTool(t, get, reg, tt.args))
+			for _, want := range tt.contains {
+				if !strings.Contains(text, want) {
+					t.Errorf("expected %q in output:\n%s", want, text)
+				}
+			}
+			for _, unwanted := range tt.notContains {
+				if strings.Contains(text, unwanted) {
+					t.Errorf("did not expect %q in output:\n%s", unwanted, text)
+				}
+			}
+		})
+	}
+
+	// Clearing the note and tags removes the annotation
+	result := callTool(t, set, reg, map[string]any{"path": filepath.Join(tmpDir, "docs", "c.md")})
+	if !strings.Contains(resultText(result), "Removed annotation") {
+		t.Errorf("expected removal, got %s", resultText(result))
+	}
+	if text := resultText(callTool(t, get, reg, map[string]any{})); strings.Contains(text, "c.md") {
+		t.Errorf("expected c.md annotation to be gone:\n%s", text)
+	}
+}
+
+func TestSetAnnotationOutsideAllowed(t *testing.T) {
+	reg, _ := setupTestRegistry(t)
+	store, _ := annotation.NewStore("")
+	set := func(ctx context.Context, reg *registry.Registry, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return HandleSetAnnotation(ctx, reg, store, req)
+	}
+
+	outside := filepath.Join(t.TempDir(), "x.txt")
+	if err := os.WriteFile(outside, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if result := callTool(t, set, reg, map[string]any{"path": outside, "note": "n"}); !result.IsError {
+		t.Error("expected error for path outside allowed directories")
+	}
+}
@@ -0,0 +1,252 @@
+package tools
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/spf13/cast"
+)
+
+const (
+	defaultArchiveEntries = 1000
+	maxArchiveEntries     = 10000
+)
+
+// archiveEntry is one member of an archive listed by list_archive.
+type archiveEntry struct {
+	Name string `json:"name"`
+	// Type is "file", "dir", "symlink", or "other".
+	Type string `json:"type"`
+	Size int64  `json:"size"`
+	// CompressedSize is only known for zip archives, where each entry is
+	// compressed on its own.
+	CompressedSize *int64    `json:"compressedSize,omitempty"`
+	Modified       time.Time `json:"modified"`
+	LinkTarget     string    `json:"linkTarget,omitempty"`
+}
+
+// archiveListing is the result of list_archive.
+type archiveListing struct {
+	Path string `json:"path"`
+	// Format is "zip", "tar", "tar.gz", or "tar.bz2".
+	Format  string         `json:"format"`
+	Entries []archiveEntry `json:"entries"`
+	// Total, TotalSize, and TotalCompressed cover every entry, including
+	// any left out by the limit, but only up to maxFiles entries.
+	Total           int    `json:"total"`
+	TotalSize       int64  `json:"totalSize"`
+	TotalCompressed *int64 `json:"totalCompressed,omitempty"`
+	// FileLimitReached is set when reading stopped at maxFiles entries.
+	FileLimitReached bool `json:"fileLimitReached,omitempty"`
+}
+
+// NewListArchiveTool creates the list_archive tool.
+func NewListArchiveTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"list_archive",
+		mcp.WithDescription("List the entries of a zip or tar archive (plain, gzip, or bzip2 compressed) without extracting it: name, type, uncompressed size, compressed size for zip entries, and modification time. The format is detected from the content, not the extension."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path", mcp.Description("Path to the archive"), mcp.Required()),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Number of entries to return (default: %d, max: %d)", defaultArchiveEntries, maxArchiveEntries))),
+		withMaxFilesParam(),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleListArchive handles the list_archive tool.
+func HandleListArchive(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	limit := cast.ToInt(request.Params.Arguments["limit"])
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	if limit <= 0 {
+		limit = defaultArchiveEntries
+	}
+	if limit > maxArchiveEntries {
+		limit = maxArchiveEntries
+	}
+	budget, err := parseMaxFiles(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	readPath, err := validateRead(reg, path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	listing, err := listArchive(ctx, readPath, limit, budget)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to list archive: %w", err).Error()), nil
+	}
+	listing.Path = path
+	listing.FileLimitReached = budget.exhausted
+
+	if format == "json" {
+		jsonResult, err := json.MarshalIndent(listing, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(jsonResult)), nil
+	}
+
+	var text strings.Builder
+	for _, e := range listing.Entries {
+		size := stream.FormatSize(e.Size)
+		if e.CompressedSize != nil {
+			size += " (" + stream.FormatSize(*e.CompressedSize) + ")"
+		}
+		name := e.Name
+		switch e.Type {
+		case "dir":
+			size = "-"
+		case "symlink":
+			if e.LinkTarget != "" {
+				name += " -> " + e.LinkTarget
+			}
+		}
+		fmt.Fprintf(&text, "%-20s  %s  %s\n", size, e.Modified.Format("2006-01-02 15:04"), name)
+	}
+	if len(listing.Entries) < listing.Total {
+		fmt.Fprintf(&text, "... %d more entries not shown\n", listing.Total-len(listing.Entries))
+	}
+	fmt.Fprintf(&text, "%s archive: %d entries, %s uncompressed", listing.Format, listing.Total, stream.FormatSize(listing.TotalSize))
+	if listing.TotalCompressed != nil {
+		fmt.Fprintf(&text, ", %s compressed", stream.FormatSize(*listing.TotalCompressed))
+	}
+	text.WriteString("\n")
+	return withBudgetNote(mcp.NewToolResultText(text.String()), budget), nil
+}
+
+// listArchive lists the archive at path, keeping the first limit entries
+// and reading no more than the budget allows. Zip archives are read through
+// their central directory; tar archives are streamed, decompressing them on
+// the way.
+func listArchive(ctx context.Context, path string, limit int, budget *fileBudget) (archiveListing, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return archiveListing{}, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return archiveListing{}, err
+	}
+	if info.IsDir() {
+		return archiveListing{}, errors.New("path is a directory")
+	}
+
+	br := bufio.NewReader(f)
+	magic, _ := br.Peek(4)
+	listing := archiveListing{Entries: []archiveEntry{}}
+	add := func(e archiveEntry) {
+		listing.Total++
+		listing.TotalSize += e.Size
+		if len(listing.Entries) < limit {
+			listing.Entries = append(listing.Entries, e)
+		}
+	}
+
+	switch {
+	case bytes.HasPrefix(magic, []byte("PK\x03\x04")) || bytes.HasPrefix(magic, []byte("PK\x05\x06")):
+		listing.Format = "zip"
+		zr, err := zip.NewReader(f, info.Size())
+		if err != nil {
+			return archiveListing{}, err
+		}
+		var compressed int64
+		for _, zf := range zr.File {
+			if err := ctx.Err(); err != nil {
+				return archiveListing{}, err
+			}
+			if !budget.take() {
+				break
+			}
+			csize := int64(zf.CompressedSize64)
+			compressed += csize
+			add(archiveEntry{Name: zf.Name, Type: entryType(zf.Mode()), Size: int64(zf.UncompressedSize64), CompressedSize: &csize, Modified: zf.Modified})
+		}
+		listing.TotalCompressed = &compressed
+		return listing, nil
+
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		listing.Format = "tar.gz"
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return archiveListing{}, err
+		}
+		defer gz.Close()
+		err = listTar(ctx, gz, budget, add)
+		return listing, err
+
+	case bytes.HasPrefix(magic, []byte("BZh")):
+		listing.Format = "tar.bz2"
+		err = listTar(ctx, bzip2.NewReader(br), budget, add)
+		return listing, err
+
+	default:
+		listing.Format = "tar"
+		err = listTar(ctx, br, budget, add)
+		return listing, err
+	}
+}
+
+// listTar calls add for each entry of the tar stream r, stopping when the
+// budget runs out.
+func listTar(ctx context.Context, r io.Reader, budget *fileBudget, add func(archiveEntry)) error {
+	tr := tar.NewReader(r)
+	for n := 0; ; n++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if n == 0 && (errors.Is(err, tar.ErrHeader) || errors.Is(err, io.ErrUnexpectedEOF)) {
+				return errors.New("not a zip or tar archive")
+			}
+			return err
+		}
+		if !budget.take() {
+			return nil
+		}
+		e := archiveEntry{Name: hdr.Name, Type: entryType(hdr.FileInfo().Mode()), Size: hdr.Size, Modified: hdr.ModTime}
+		if e.Type == "symlink" {
+			e.LinkTarget = hdr.Linkname
+		}
+		if e.Type == "dir" {
+			e.Size = 0
+		}
+		add(e)
+	}
+}
+
+// entryType names the kind of archive entry with the given mode.
+func entryType(mode os.FileMode) string {
+	switch {
+	case mode.IsDir():
+		return "dir"
+	case mode&os.ModeSymlink != 0:
+		return "symlink"
+	case mode.IsRegular():
+		return "file"
+	default:
+		return "other"
+	}
+}
@@ -0,0 +1,128 @@
+package tools
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleListArchive(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	var zipBuf bytes.Buffer
+	zw := zip.NewWriter(&zipBuf)
+	for name, content := range map[string]string{"docs/": "", "docs/readme.txt": strings.Repeat("a", 1000), "main.go": "package main\n"} {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zipPath := filepath.Join(tmpDir, "bundle.jar")
+	if err := os.WriteFile(zipPath, zipBuf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var tgzBuf bytes.Buffer
+	gz := gzip.NewWriter(&tgzBuf)
+	tw := tar.NewWriter(gz)
+	headers := []*tar.Header{
+		{Name: "app/", Typeflag: tar.TypeDir, Mode: 0755, ModTime: modified},
+		{Name: "app/run.sh", Typeflag: tar.TypeReg, Mode: 0755, Size: 5, ModTime: modified},
+		{Name: "app/latest", Typeflag: tar.TypeSymlink, Linkname: "run.sh", ModTime: modified},
+	}
+	for _, hdr := range headers {
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Size > 0 {
+			tw.Write([]byte("echo\n"))
+		}
+	}
+	tw.Close()
+	gz.Close()
+	tgzPath := filepath.Join(tmpDir, "release.tgz")
+	if err := os.WriteFile(tgzPath, tgzBuf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("zip json", func(t *testing.T) {
+		result := callTool(t, HandleListArchive, reg, map[string]any{"path": zipPath, "format": "json"})
+		var listing archiveListing
+		if err := json.Unmarshal([]byte(resultText(result)), &listing); err != nil {
+			t.Fatalf("bad result %s: %v", resultText(result), err)
+		}
+		if listing.Format != "zip" || listing.Total != 3 || listing.TotalSize != 1013 {
+			t.Fatalf("unexpected listing %+v", listing)
+		}
+		if listing.TotalCompressed == nil || *listing.TotalCompressed >= 1013 {
+			t.Errorf("expected a compressed total below the uncompressed size")
+		}
+		for _, e := range listing.Entries {
+			if e.Name == "docs/" && e.Type != "dir" || e.Name == "main.go" && e.Type != "file" {
+				t.Errorf("unexpected entry %+v", e)
+			}
+			if !e.Modified.Equal(modified) {
+				t.Errorf("%s modified %v, want %v", e.Name, e.Modified, modified)
+			}
+		}
+	})
+
+	t.Run("tar.gz text", func(t *testing.T) {
+		result := callTool(t, HandleListArchive, reg, map[string]any{"path": tgzPath})
+		text := resultText(result)
+		for _, want := range []string{"app/run.sh", "app/latest -> run.sh", "tar.gz archive: 3 entries"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("missing %q in:\n%s", want, text)
+			}
+		}
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		result := callTool(t, HandleListArchive, reg, map[string]any{"path": tgzPath, "limit": 1})
+		if text := resultText(result); !strings.Contains(text, "2 more entries not shown") {
+			t.Errorf("expected truncation note, got:\n%s", text)
+		}
+	})
+
+	t.Run("max files", func(t *testing.T) {
+		for _, path := range []string{zipPath, tgzPath} {
+			result := callTool(t, HandleListArchive, reg, map[string]any{"path": path, "maxFiles": 2, "format": "json"})
+			var listing archiveListing
+			if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &listing); err != nil {
+				t.Fatal(err)
+			}
+			if listing.Total != 2 || !listing.FileLimitReached {
+				t.Errorf("%s: expected reading to stop at 2 entries, got %+v", path, listing)
+			}
+		}
+		result := callTool(t, HandleListArchive, reg, map[string]any{"path": tgzPath, "maxFiles": 3})
+		if strings.Contains(resultText(result), "maxFiles") {
+			t.Errorf("expected no note when every entry fits, got:\n%s", resultText(result))
+		}
+	})
+
+	t.Run("not an archive", func(t *testing.T) {
+		path := filepath.Join(tmpDir, "notes.txt")
+		if err := os.WriteFile(path, []byte("just text"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		result := callTool(t, HandleListArchive, reg, map[string]any{"path": path})
+		if !result.IsError || !strings.Contains(resultText(result), "not a zip or tar archive") {
+			t.Errorf("expected an error, got %q", resultText(result))
+		}
+	})
+}
@@ -0,0 +1,571 @@
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/journal"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/security"
+	"github.com/spf13/cast"
+)
+
+// batchOp is one operation of a batch_operations call, with its paths
+// resolved and, for write and edit, the content it leaves behind.
+type batchOp struct {
+	Op          string
+	Path        string
+	Destination string
+	Content     []byte
+	Perm        os.FileMode
+	Diff        string
+	staged      string
+	// backup is where a deleted file was moved aside while the batch ran.
+	backup string
+}
+
+// String describes the operation for the result.
+func (op *batchOp) String() string {
+	if op.Op == "move" {
+		return fmt.Sprintf("move %s to %s", op.Path, op.Destination)
+	}
+	return fmt.Sprintf("%s %s", op.Op, op.Path)
+}
+
+// batchFile is a file as the batch leaves it so far. Its content is held
+// in memory once written or edited, and otherwise is that of the file at
+// origin before the batch.
+type batchFile struct {
+	content []byte
+	origin  string
+	perm    os.FileMode
+}
+
+// read returns the file's content.
+func (f *batchFile) read() ([]byte, error) {
+	if f.origin == "" {
+		return f.content, nil
+	}
+	return os.ReadFile(f.origin)
+}
+
+// size returns the file's size in bytes.
+func (f *batchFile) size() (int64, error) {
+	if f.origin == "" {
+		return int64(len(f.content)), nil
+	}
+	info, err := os.Stat(f.origin)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// batchView is the tree as the operations checked so far would leave it,
+// on top of the tree on disk. A nil file is one the batch removed.
+type batchView struct {
+	files map[string]*batchFile
+	dirs  map[string]bool
+	// fileMode is the mode of files the batch creates
+	fileMode os.FileMode
+}
+
+// lookup returns the file at path, or whether path is a directory, in the
+// view. Both are zero if nothing is there.
+func (v *batchView) lookup(path string) (*batchFile, bool, error) {
+	if f, ok := v.files[path]; ok {
+		return f, false, nil
+	}
+	if v.dirs[path] {
+		return nil, true, nil
+	}
+	info, err := os.Lstat(path)
+	switch {
+	case os.IsNotExist(err):
+		return nil, false, nil
+	case err != nil:
+		return nil, false, err
+	case info.IsDir():
+		return nil, true, nil
+	case !info.Mode().IsRegular():
+		return nil, false, fmt.Errorf("not a regular file: %s", path)
+	}
+	return &batchFile{origin: path, perm: info.Mode().Perm()}, false, nil
+}
+
+// file returns the file at path, which must exist in the view.
+func (v *batchView) file(path string) (*batchFile, error) {
+	f, isDir, err := v.lookup(path)
+	switch {
+	case err != nil:
+		return nil, err
+	case isDir:
+		return nil, fmt.Errorf("path is a directory: %s", path)
+	case f == nil:
+		return nil, fmt.Errorf("file does not exist: %s", path)
+	}
+	return f, nil
+}
+
+// mkdirAll records dir and any missing parents as directories.
+func (v *batchView) mkdirAll(dir string) error {
+	var missing []string
+	for d := dir; ; d = filepath.Dir(d) {
+		f, isDir, err := v.lookup(d)
+		if err != nil {
+			return err
+		}
+		if isDir {
+			break
+		}
+		if f != nil {
+			return fmt.Errorf("path segment is not a directory: %s", d)
+		}
+		missing = append(missing, d)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	for _, d := range missing {
+		v.dirs[d] = true
+	}
+	return nil
+}
+
+// batchJournal records how to undo each change a batch has made.
+type batchJournal struct {
+	undo    []func() error
+	backups []string
+	staged  []string
+	// dirMode is the mode of directories the batch creates
+	dirMode os.FileMode
+	// record is the batch's operation in the undo journal, which notes each
+	// file just before the batch changes it.
+	record *journal.Pending
+}
+
+// mkdirAll creates dir and any missing parents.
+func (j *batchJournal) mkdirAll(dir string) error {
+	var missing []string
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Lstat(d); err == nil {
+			break
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+		missing = append(missing, d)
+	}
+	for i := len(missing) - 1; i >= 0; i-- {
+		d := missing[i]
+		if err := os.Mkdir(d, j.dirMode); err != nil {
+			return err
+		}
+		j.undo = append(j.undo, func() error { return os.Remove(d) })
+	}
+	return nil
+}
+
+// rename moves oldPath to newPath, which must not exist.
+func (j *batchJournal) rename(oldPath, newPath string) error {
+	if _, err := os.Lstat(newPath); err == nil {
+		return fmt.Errorf("destination already exists: %s", newPath)
+	}
+	if err := os.Rename(oldPath, newPath); err != nil {
+		return err
+	}
+	j.undo = append(j.undo, func() error { return os.Rename(newPath, oldPath) })
+	return nil
+}
+
+// backUp moves the file at path aside, next to it, until the batch is
+// done, and returns where it went. It does nothing if path does not exist.
+func (j *batchJournal) backUp(path string) (string, error) {
+	if _, err := os.Lstat(path); os.IsNotExist(err) {
+		return "", nil
+	}
+	backup, err := batchTempName(filepath.Dir(path), ".batch-backup-")
+	if err != nil {
+		return "", err
+	}
+	if err := j.rename(path, backup); err != nil {
+		return "", err
+	}
+	j.backups = append(j.backups, backup)
+	return backup, nil
+}
+
+// rollBack undoes the recorded changes in reverse order and removes the
+// staged files. It returns the changes that could not be undone.
+func (j *batchJournal) rollBack() []error {
+	var errs []error
+	for i := len(j.undo) - 1; i >= 0; i-- {
+		if err := j.undo[i](); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, staged := range j.staged {
+		os.Remove(staged)
+	}
+	return errs
+}
+
+// batchTempName returns an unused name in dir starting with prefix.
+func batchTempName(dir, prefix string) (string, error) {
+	randBytes := make([]byte, 8)
+	if _, err := rand.Read(randBytes); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	return filepath.Join(dir, prefix+hex.EncodeToString(randBytes)), nil
+}
+
+// NewBatchOperationsTool creates the batch_operations tool.
+func NewBatchOperationsTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"batch_operations",
+		mcp.WithDescription("Apply an ordered list of file operations all or nothing. Each operation is an object with an op of 'write' (path, content), 'edit' (path, edits as for edit_file), 'move' (source, destination, files only), 'delete' (path, files only), or 'mkdir' (path). Every operation is checked against the result of the ones before it and new content is staged in temporary files before anything changes; if any operation then fails, the ones already applied are rolled back. Not available in overlay mode, which already stages every change."),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Batch Operations",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(true),
+			IdempotentHint:  boolPtr(false),
+		}),
+		mcp.WithArray("operations", mcp.Description("Operations to apply, in order"), mcp.Required(), mcp.Items(map[string]any{"type": "object"})),
+		mcp.WithBoolean("dryRun", mcp.Description("If true, check the operations and show what they would do without changing anything")),
+		withForce(),
+		withConfirmationToken(),
+		withApprovalToken(),
+	)
+}
+
+// HandleBatchOperations handles the batch_operations tool.
+func HandleBatchOperations(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	dryRun := cast.ToBool(request.Params.Arguments["dryRun"])
+	force := cast.ToBool(request.Params.Arguments["force"])
+
+	if reg.Overlay() != nil {
+		return mcp.NewToolResultError("batch_operations is not supported in overlay mode; make the changes with the individual tools and commit the overlay instead"), nil
+	}
+	rawOps, ok := request.Params.Arguments["operations"].([]any)
+	if !ok || len(rawOps) == 0 {
+		return mcp.NewToolResultError("operations must be a non-empty array"), nil
+	}
+
+	ops, deleted, deletedBytes, err := prepareBatch(reg, rawOps)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("nothing was changed: %w", err).Error()), nil
+	}
+
+	var summary strings.Builder
+	var paths, removed []string
+	for i, op := range ops {
+		fmt.Fprintf(&summary, "%d. %s\n", i+1, op)
+		paths = append(paths, op.Path)
+		switch op.Op {
+		case "move":
+			paths = append(paths, op.Destination)
+			removed = append(removed, op.Path, op.Destination)
+		case "delete":
+			removed = append(removed, op.Path)
+		}
+	}
+	if dryRun {
+		for _, op := range ops {
+			if op.Diff != "" {
+				fmt.Fprintf(&summary, "\n%s", op.Diff)
+			}
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Dry run - %d operations checked, nothing changed:\n%s", len(ops), summary.String())), nil
+	}
+
+	if err := checkDeleteLimits(reg, deleted, deletedBytes, force); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if result := requireApproval(reg, "batch_operations", request, fmt.Sprintf("apply %d operations", len(ops)), removed...); result != nil {
+		return result, nil
+	}
+	for _, p := range paths {
+		if err := reg.CheckWrite(p); err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("nothing was changed: %w", err).Error()), nil
+		}
+	}
+
+	journal := &batchJournal{dirMode: reg.Modes().Dir, record: beginJournal(reg, "batch_operations", ops[0].Path)}
+	if err := stageBatch(journal, ops); err != nil {
+		journal.rollBack()
+		journal.record.Discard()
+		return mcp.NewToolResultError(fmt.Errorf("failed to stage changes, nothing was changed: %w", err).Error()), nil
+	}
+	if err := commitBatch(journal, ops); err != nil {
+		journal.record.Discard()
+		if errs := journal.rollBack(); len(errs) > 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("%v\nRolling back failed, the tree may be partly changed: %v\nBackups of replaced and deleted files were kept: %s",
+				err, errors.Join(errs...), strings.Join(journal.backups, ", "))), nil
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("%v\nRolled back; nothing was changed.", err)), nil
+	}
+
+	trashNotes, trashed := trashBatchDeletes(reg, ops)
+	for _, backup := range journal.backups {
+		if !trashed[backup] {
+			os.Remove(backup)
+		}
+	}
+	if store := reg.Shadow(); store != nil {
+		for _, op := range ops {
+			if op.staged != "" {
+				store.Record(op.Path, op.Content)
+			}
+		}
+	}
+	note := journalNote(journal.record)
+	return mcp.NewToolResultText(fmt.Sprintf("Successfully applied %d operations%s:\n%s%s", len(ops), note, summary.String(), trashNotes)), nil
+}
+
+// trashBatchDeletes moves the backups of the files a committed batch
+// deleted into the trash, if it is enabled, and describes where they went.
+// It also returns the backups it handled, which must not be removed.
+func trashBatchDeletes(reg *registry.Registry, ops []*batchOp) (string, map[string]bool) {
+	t := reg.Trash()
+	if t == nil {
+		return "", nil
+	}
+	var notes strings.Builder
+	handled := make(map[string]bool)
+	for _, op := range ops {
+		if op.Op != "delete" || op.backup == "" {
+			continue
+		}
+		handled[op.backup] = true
+		var size int64
+		if info, err := os.Lstat(op.backup); err == nil {
+			size = info.Size()
+		}
+		e, err := t.PutAs(containingRoot(reg, op.Path), op.backup, op.Path, false, size)
+		if err != nil {
+			fmt.Fprintf(&notes, "Failed to move deleted %s to the trash; it was kept at %s: %v\n", op.Path, op.backup, err)
+			continue
+		}
+		fmt.Fprintf(&notes, "Moved %s to the trash as %s; use restore_from_trash to recover it\n", op.Path, e.ID)
+	}
+	return notes.String(), handled
+}
+
+// prepareBatch validates the operations in order against the tree they
+// would leave, without changing anything. It also returns the number and
+// total size of the files they delete.
+func prepareBatch(reg *registry.Registry, rawOps []any) ([]*batchOp, int, int64, error) {
+	view := &batchView{files: make(map[string]*batchFile), dirs: make(map[string]bool), fileMode: reg.Modes().File}
+	resolve := func(path string) (string, error) {
+		if path == "" {
+			return "", errors.New("path is required")
+		}
+		resolved, err := reg.ValidateForCreation(path)
+		if err != nil {
+			return "", fmt.Errorf("path validation failed: %w", err)
+		}
+		if err := security.ValidateNoSymlinksInPath(reg.ExpandPath(path), reg.Get()); err != nil {
+			return "", fmt.Errorf("path validation failed: %w", err)
+		}
+		return resolved, nil
+	}
+
+	var ops []*batchOp
+	var deleted int
+	var deletedBytes int64
+	for i, raw := range rawOps {
+		args, ok := raw.(map[string]any)
+		if !ok {
+			return nil, 0, 0, fmt.Errorf("operation %d: must be an object", i+1)
+		}
+		op := &batchOp{Op: cast.ToString(args["op"])}
+		var err error
+		if op.Op == "move" {
+			op.Path, err = resolve(cast.ToString(args["source"]))
+			if err == nil {
+				op.Destination, err = resolve(cast.ToString(args["destination"]))
+			}
+		} else {
+			op.Path, err = resolve(cast.ToString(args["path"]))
+		}
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("operation %d (%s): %w", i+1, op.Op, err)
+		}
+
+		switch op.Op {
+		case "write":
+			err = prepareBatchWrite(view, op, []byte(cast.ToString(args["content"])))
+		case "edit":
+			err = prepareBatchEdit(view, op, args["edits"])
+		case "move":
+			err = prepareBatchMove(view, op)
+		case "delete":
+			var f *batchFile
+			if f, err = view.file(op.Path); err == nil {
+				var size int64
+				if size, err = f.size(); err == nil {
+					deleted++
+					deletedBytes += size
+					view.files[op.Path] = nil
+				}
+			}
+		case "mkdir":
+			err = view.mkdirAll(op.Path)
+		default:
+			err = fmt.Errorf("unknown op %q: must be write, edit, move, delete, or mkdir", op.Op)
+		}
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("operation %d (%s): %w", i+1, op, err)
+		}
+		ops = append(ops, op)
+	}
+	return ops, deleted, deletedBytes, nil
+}
+
+func prepareBatchWrite(view *batchView, op *batchOp, content []byte) error {
+	existing, isDir, err := view.lookup(op.Path)
+	if err != nil {
+		return err
+	} else if isDir {
+		return errors.New("path is a directory")
+	}
+	if err := view.mkdirAll(filepath.Dir(op.Path)); err != nil {
+		return err
+	}
+	// A replaced file keeps its mode
+	op.Content, op.Perm = content, view.fileMode
+	if existing != nil {
+		op.Perm = existing.perm
+	}
+	view.files[op.Path] = &batchFile{content: content, perm: op.Perm}
+	return nil
+}
+
+func prepareBatchEdit(view *batchView, op *batchOp, editsArg any) error {
+	edits := parseEdits(editsArg)
+	if len(edits) == 0 {
+		return errors.New("edits must be a non-empty array")
+	}
+	f, err := view.file(op.Path)
+	if err != nil {
+		return err
+	}
+	data, err := f.read()
+	if err != nil {
+		return err
+	}
+	newContent, err := applyEdits(string(data), edits)
+	if err != nil {
+		return err
+	}
+	op.Content, op.Perm = []byte(newContent), f.perm
+	op.Diff = generateUnifiedDiff(op.Path, string(data), newContent)
+	view.files[op.Path] = &batchFile{content: op.Content, perm: op.Perm}
+	return nil
+}
+
+func prepareBatchMove(view *batchView, op *batchOp) error {
+	if op.Path == op.Destination {
+		return errors.New("source and destination are the same")
+	}
+	f, err := view.file(op.Path)
+	if err != nil {
+		return err
+	}
+	if existing, isDir, err := view.lookup(op.Destination); err != nil {
+		return err
+	} else if existing != nil || isDir {
+		return errors.New("destination already exists")
+	}
+	if err := view.mkdirAll(filepath.Dir(op.Destination)); err != nil {
+		return err
+	}
+	view.files[op.Destination] = f
+	view.files[op.Path] = nil
+	return nil
+}
+
+// stageBatch writes the content of every write and edit to a temporary
+// file in the nearest existing directory above its path, so that
+// committing it is a rename.
+func stageBatch(journal *batchJournal, ops []*batchOp) error {
+	for _, op := range ops {
+		if op.Op != "write" && op.Op != "edit" {
+			continue
+		}
+		dir := filepath.Dir(op.Path)
+		for {
+			if info, err := os.Stat(dir); err == nil && info.IsDir() {
+				break
+			}
+			dir = filepath.Dir(dir)
+		}
+		name, err := batchTempName(dir, ".tmp-")
+		if err != nil {
+			return err
+		}
+		f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, op.Perm)
+		if err != nil {
+			return fmt.Errorf("failed to create temp file: %w", err)
+		}
+		journal.staged = append(journal.staged, name)
+		_, err = f.Write(op.Content)
+		if err == nil {
+			err = f.Sync()
+		}
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write temp file: %w", err)
+		}
+		op.staged = name
+	}
+	return nil
+}
+
+// commitBatch applies the staged operations in order, stopping at the
+// first that fails. The journal can then roll back the ones before it.
+func commitBatch(journal *batchJournal, ops []*batchOp) error {
+	for i, op := range ops {
+		if err := commitBatchOp(journal, op); err != nil {
+			return fmt.Errorf("operation %d (%s) failed: %w", i+1, op, err)
+		}
+	}
+	return nil
+}
+
+// commitBatchOp applies one staged operation, recording how to undo it.
+func commitBatchOp(journal *batchJournal, op *batchOp) error {
+	switch op.Op {
+	case "write", "edit":
+		if err := journal.mkdirAll(filepath.Dir(op.Path)); err != nil {
+			return err
+		}
+		journal.record.Save(op.Path)
+		if _, err := journal.backUp(op.Path); err != nil {
+			return err
+		}
+		return journal.rename(op.staged, op.Path)
+	case "move":
+		if err := journal.mkdirAll(filepath.Dir(op.Destination)); err != nil {
+			return err
+		}
+		journal.record.Move(op.Path, op.Destination)
+		return journal.rename(op.Path, op.Destination)
+	case "delete":
+		journal.record.Save(op.Path)
+		var err error
+		op.backup, err = journal.backUp(op.Path)
+		return err
+	case "mkdir":
+		return journal.mkdirAll(op.Path)
+	}
+	return fmt.Errorf("unknown op %q", op.Op)
+}
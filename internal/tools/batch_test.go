@@ -0,0 +1,159 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/portertech/filesystem-mcp-server/internal/retention"
+	"github.com/portertech/filesystem-mcp-server/internal/trash"
+)
+
+func TestHandleBatchOperations(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	os.WriteFile(filepath.Join(tmpDir, "config.txt"), []byte("name = old\n"), 0600)
+	os.WriteFile(filepath.Join(tmpDir, "stale.txt"), []byte("stale"), 0644)
+
+	ops := []any{
+		map[string]any{"op": "mkdir", "path": filepath.Join(tmpDir, "src")},
+		map[string]any{"op": "write", "path": filepath.Join(tmpDir, "src", "main.txt"), "content": "main"},
+		map[string]any{"op": "edit", "path": filepath.Join(tmpDir, "config.txt"), "edits": []any{map[string]any{"oldText": "old", "newText": "new"}}},
+		map[string]any{"op": "move", "source": filepath.Join(tmpDir, "config.txt"), "destination": filepath.Join(tmpDir, "conf", "app.txt")},
+		map[string]any{"op": "edit", "path": filepath.Join(tmpDir, "conf", "app.txt"), "edits": []any{map[string]any{"oldText": "name", "newText": "title"}}},
+		map[string]any{"op": "delete", "path": filepath.Join(tmpDir, "stale.txt")},
+	}
+
+	result := callTool(t, HandleBatchOperations, reg, map[string]any{"operations": ops, "dryRun": true})
+	if result.IsError || !strings.Contains(resultText(result), "6 operations checked") {
+		t.Fatalf("unexpected dry run result: %s", resultText(result))
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "src")); !os.IsNotExist(err) {
+		t.Fatal("expected a dry run to change nothing")
+	}
+
+	result = callTool(t, HandleBatchOperations, reg, map[string]any{"operations": ops})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if data, _ := os.ReadFile(filepath.Join(tmpDir, "src", "main.txt")); string(data) != "main" {
+		t.Errorf("unexpected written content %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(tmpDir, "conf", "app.txt")); string(data) != "title = new\n" {
+		t.Errorf("unexpected moved content %q", data)
+	}
+	if info, err := os.Stat(filepath.Join(tmpDir, "conf", "app.txt")); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("expected the edited file to keep its mode, got %v", info.Mode())
+	}
+	for _, name := range []string{"config.txt", "stale.txt"} {
+		if _, err := os.Stat(filepath.Join(tmpDir, name)); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be gone", name)
+		}
+	}
+	entries, _ := os.ReadDir(tmpDir)
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			t.Errorf("unexpected leftover %s", e.Name())
+		}
+	}
+}
+
+func TestHandleBatchOperationsKeepsMode(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	secret := filepath.Join(tmpDir, "secret.env")
+	os.WriteFile(secret, []byte("TOKEN=old\n"), 0600)
+
+	ops := []any{map[string]any{"op": "write", "path": secret, "content": "TOKEN=new\n"}}
+	if result := callTool(t, HandleBatchOperations, reg, map[string]any{"operations": ops}); result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if info, err := os.Stat(secret); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("expected the replaced file to keep its mode, got %v", info.Mode())
+	}
+}
+
+func TestHandleBatchOperationsInvalid(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	os.WriteFile(filepath.Join(tmpDir, "a.txt"), []byte("a"), 0644)
+
+	for name, ops := range map[string][]any{
+		"unknown op":          {map[string]any{"op": "chmod", "path": filepath.Join(tmpDir, "a.txt")}},
+		"missing file":        {map[string]any{"op": "delete", "path": filepath.Join(tmpDir, "missing.txt")}},
+		"deleted then edited": {map[string]any{"op": "delete", "path": filepath.Join(tmpDir, "a.txt")}, map[string]any{"op": "edit", "path": filepath.Join(tmpDir, "a.txt"), "edits": []any{map[string]any{"oldText": "a", "newText": "b"}}}},
+		"existing destination": {
+			map[string]any{"op": "write", "path": filepath.Join(tmpDir, "b.txt"), "content": "b"},
+			map[string]any{"op": "move", "source": filepath.Join(tmpDir, "a.txt"), "destination": filepath.Join(tmpDir, "b.txt")},
+		},
+		"outside": {map[string]any{"op": "write", "path": "/etc/batch.txt", "content": "x"}},
+	} {
+		result := callTool(t, HandleBatchOperations, reg, map[string]any{"operations": ops})
+		if !result.IsError || !strings.Contains(resultText(result), "nothing was changed") {
+			t.Errorf("%s: expected an error, got %s", name, resultText(result))
+		}
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "b.txt")); !os.IsNotExist(err) {
+		t.Error("expected a refused batch to write nothing")
+	}
+	if data, _ := os.ReadFile(filepath.Join(tmpDir, "a.txt")); string(data) != "a" {
+		t.Errorf("expected a.txt to be untouched, got %q", data)
+	}
+}
+
+func TestBatchRollBack(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	os.WriteFile(filepath.Join(tmpDir, "a.txt"), []byte("a"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "b.txt"), []byte("b"), 0644)
+
+	ops, _, _, err := prepareBatch(reg, []any{
+		map[string]any{"op": "write", "path": filepath.Join(tmpDir, "a.txt"), "content": "new a"},
+		map[string]any{"op": "write", "path": filepath.Join(tmpDir, "new", "c.txt"), "content": "c"},
+		map[string]any{"op": "delete", "path": filepath.Join(tmpDir, "b.txt")},
+		map[string]any{"op": "move", "source": filepath.Join(tmpDir, "new", "c.txt"), "destination": filepath.Join(tmpDir, "d.txt")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	journal := &batchJournal{}
+	if err := stageBatch(journal, ops); err != nil {
+		t.Fatal(err)
+	}
+
+	// Something else takes the move's destination after the batch was checked
+	os.WriteFile(filepath.Join(tmpDir, "d.txt"), []byte("d"), 0644)
+	if err := commitBatch(journal, ops); err == nil || !strings.Contains(err.Error(), "operation 4") {
+		t.Fatalf("expected operation 4 to fail, got %v", err)
+	}
+	if errs := journal.rollBack(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	for name, want := range map[string]string{"a.txt": "a", "b.txt": "b", "d.txt": "d"} {
+		if data, _ := os.ReadFile(filepath.Join(tmpDir, name)); string(data) != want {
+			t.Errorf("%s: expected %q after rolling back, got %q", name, want, data)
+		}
+	}
+	entries, _ := os.ReadDir(tmpDir)
+	if len(entries) != 3 {
+		t.Errorf("expected only the original files to remain, got %v", entries)
+	}
+}
+
+func TestHandleBatchOperationsToTrash(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	reg.SetTrash(trash.New(retention.Policy{}, nil))
+	stale := filepath.Join(tmpDir, "stale.txt")
+	os.WriteFile(stale, []byte("stale"), 0644)
+
+	ops := []any{map[string]any{"op": "delete", "path": stale}}
+	result := callTool(t, HandleBatchOperations, reg, map[string]any{"operations": ops})
+	if result.IsError || !strings.Contains(resultText(result), "to the trash") {
+		t.Fatalf("unexpected result: %s", resultText(result))
+	}
+	entries, err := reg.Trash().List(reg.GetResolved())
+	if err != nil || len(entries) != 1 || entries[0].Path != stale {
+		t.Fatalf("expected %s in the trash, got %v, %v", stale, entries, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(tmpDir, trash.DirName, entries[0].ID)); string(data) != "stale" {
+		t.Errorf("unexpected trashed content %q", data)
+	}
+}
@@ -0,0 +1,205 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/spf13/cast"
+)
+
+const (
+	defaultBlameFiles = 100
+	maxBlameFiles     = 1000
+)
+
+// authorStats is the number of lines attributed to one author.
+type authorStats struct {
+	Name    string  `json:"name"`
+	Email   string  `json:"email"`
+	Lines   int     `json:"lines"`
+	Percent float64 `json:"percent"`
+	Files   int     `json:"files"`
+}
+
+// blameSummary is the result of blame_summary. Pending counts the overlay
+// changes under Path, which are not committed and so not blamed.
+type blameSummary struct {
+	Repository string        `json:"repository"`
+	Path       string        `json:"path"`
+	Commit     string        `json:"commit"`
+	Files      int           `json:"files"`
+	Lines      int           `json:"lines"`
+	Truncated  bool          `json:"truncated"`
+	Pending    int           `json:"pendingChanges,omitempty"`
+	Authors    []authorStats `json:"authors"`
+}
+
+// NewBlameSummaryTool creates the blame_summary tool.
+func NewBlameSummaryTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"blame_summary",
+		mcp.WithDescription("Summarize git blame for a file or directory in a git repository: lines attributed to each author at HEAD. Binary files are skipped."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path", mcp.Description("File or directory inside a git repository"), mcp.Required()),
+		mcp.WithNumber("maxFiles", mcp.Description(fmt.Sprintf("Maximum number of files to blame (default: %d, max: %d)", defaultBlameFiles, maxBlameFiles))),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleBlameSummary handles the blame_summary tool.
+func HandleBlameSummary(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	maxFiles := cast.ToInt(request.Params.Arguments["maxFiles"])
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	if maxFiles <= 0 {
+		maxFiles = defaultBlameFiles
+	}
+	if maxFiles > maxBlameFiles {
+		maxFiles = maxBlameFiles
+	}
+
+	resolvedPath, err := reg.Validate(path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+
+	repo, err := git.PlainOpenWithOptions(resolvedPath, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		if errors.Is(err, git.ErrRepositoryNotExists) {
+			return mcp.NewToolResultError("path is not inside a git repository"), nil
+		}
+		return mcp.NewToolResultError(fmt.Errorf("failed to open repository: %w", err).Error()), nil
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to open repository: %w", err).Error()), nil
+	}
+
+	// The repository, including its .git directory, must itself be allowed
+	repoRoot, err := reg.Validate(worktree.Filesystem.Root())
+	if err != nil {
+		return mcp.NewToolResultError("git repository root is outside the allowed directories"), nil
+	}
+
+	rel, err := filepath.Rel(repoRoot, resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to resolve path in repository: %w", err).Error()), nil
+	}
+	rel = filepath.ToSlash(rel)
+
+	head, err := repo.Head()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to resolve HEAD: %w", err).Error()), nil
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to read HEAD commit: %w", err).Error()), nil
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to read HEAD tree: %w", err).Error()), nil
+	}
+
+	summary := blameSummary{
+		Repository: repoRoot,
+		Path:       resolvedPath,
+		Commit:     head.Hash().String(),
+		Pending:    pendingChanges(reg, resolvedPath),
+		Authors:    []authorStats{},
+	}
+
+	var files []string
+	err = tree.Files().ForEach(func(f *object.File) error {
+		if rel != "." && f.Name != rel && !strings.HasPrefix(f.Name, rel+"/") {
+			return nil
+		}
+		if binary, err := f.IsBinary(); err != nil || binary {
+			return nil
+		}
+		if len(files) == maxFiles {
+			summary.Truncated = true
+			return io.EOF
+		}
+		files = append(files, f.Name)
+		return nil
+	})
+	if err != nil && !errors.Is(err, io.EOF) {
+		return mcp.NewToolResultError(fmt.Errorf("failed to list files: %w", err).Error()), nil
+	}
+	if len(files) == 0 {
+		return mcp.NewToolResultError("no tracked text files found at HEAD for this path"), nil
+	}
+
+	byAuthor := make(map[string]*authorStats)
+	for _, name := range files {
+		if ctx.Err() != nil {
+			return mcp.NewToolResultError(ctx.Err().Error()), nil
+		}
+		blame, err := git.Blame(commit, name)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to blame %s: %w", name, err).Error()), nil
+		}
+
+		seen := make(map[string]bool)
+		for _, line := range blame.Lines {
+			stats, ok := byAuthor[line.Author]
+			if !ok {
+				stats = &authorStats{Name: line.AuthorName, Email: line.Author}
+				byAuthor[line.Author] = stats
+			}
+			stats.Lines++
+			if !seen[line.Author] {
+				seen[line.Author] = true
+				stats.Files++
+			}
+			summary.Lines++
+		}
+		summary.Files++
+	}
+
+	for _, stats := range byAuthor {
+		if summary.Lines > 0 {
+			stats.Percent = float64(stats.Lines) * 100 / float64(summary.Lines)
+		}
+		summary.Authors = append(summary.Authors, *stats)
+	}
+	sort.Slice(summary.Authors, func(i, j int) bool {
+		if summary.Authors[i].Lines != summary.Authors[j].Lines {
+			return summary.Authors[i].Lines > summary.Authors[j].Lines
+		}
+		return summary.Authors[i].Email < summary.Authors[j].Email
+	})
+
+	if format == "json" {
+		jsonResult, err := json.MarshalIndent(summary, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(jsonResult)), nil
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "Blame summary for %s at %s (%d files, %d lines)\n", resolvedPath, summary.Commit[:12], summary.Files, summary.Lines)
+	if summary.Truncated {
+		fmt.Fprintf(&result, "Only the first %d files were blamed; raise maxFiles to include more.\n", maxFiles)
+	}
+	if summary.Pending > 0 {
+		fmt.Fprintf(&result, "%d pending overlay changes under this path are not committed and were not blamed.\n", summary.Pending)
+	}
+	for _, a := range summary.Authors {
+		fmt.Fprintf(&result, "%8d %5.1f%%  %s <%s> in %d file(s)\n", a.Lines, a.Percent, a.Name, a.Email, a.Files)
+	}
+
+	return mcp.NewToolResultText(result.String()), nil
+}
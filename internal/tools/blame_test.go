@@ -0,0 +1,71 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// commitFile writes content to name in the worktree and commits it as author.
+func commitFile(t *testing.T, repo *git.Repository, dir, name, content, author string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.Add(name); err != nil {
+		t.Fatal(err)
+	}
+	sig := &object.Signature{Name: author, Email: strings.ToLower(author) + "@example.com", When: time.Now()}
+	if _, err := wt.Commit("update "+name, &git.CommitOptions{Author: sig, Committer: sig}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBlameSummary(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	repo, err := git.PlainInit(tmpDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, repo, tmpDir, "main.go", "a\nb\nc\n", "Alice")
+	commitFile(t, repo, tmpDir, "main.go", "a\nb\nc\nd\n", "Bob")
+	commitFile(t, repo, tmpDir, filepath.Join("pkg", "util.go"), "x\ny\nz\n", "Bob")
+
+	result := callTool(t, HandleBlameSummary, reg, map[string]any{"path": tmpDir})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	text := resultText(result)
+	lines := strings.Split(text, "\n")
+	if !strings.Contains(text, "(2 files, 7 lines)") {
+		t.Errorf("unexpected totals: %s", text)
+	}
+	if len(lines) < 3 || !strings.Contains(lines[1], "Bob <bob@example.com> in 2 file(s)") || !strings.Contains(lines[2], "Alice") {
+		t.Errorf("expected Bob then Alice, got %s", text)
+	}
+
+	result = callTool(t, HandleBlameSummary, reg, map[string]any{"path": filepath.Join(tmpDir, "pkg")})
+	if text := resultText(result); strings.Contains(text, "Alice") || !strings.Contains(text, "3 lines") {
+		t.Errorf("expected only pkg/ to be blamed, got %s", text)
+	}
+}
+
+func TestBlameSummaryNotRepository(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	result := callTool(t, HandleBlameSummary, reg, map[string]any{"path": tmpDir})
+	if !result.IsError {
+		t.Errorf("expected error outside a git repository, got %s", resultText(result))
+	}
+}
@@ -0,0 +1,118 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/bookmark"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/security"
+	"github.com/spf13/cast"
+)
+
+// NewAddBookmarkTool creates the add_bookmark tool.
+func NewAddBookmarkTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"add_bookmark",
+		mcp.WithDescription("Bookmark a directory under a short name. Any tool then accepts @name, or @name/relative/path, in place of the directory's path. Adding an existing name replaces it."),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Add Bookmark",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  boolPtr(true),
+		}),
+		mcp.WithString("name", mcp.Description("Bookmark name: letters, digits, '.', '-' and '_'"), mcp.Required()),
+		mcp.WithString("path", mcp.Description("Directory to bookmark"), mcp.Required()),
+	)
+}
+
+// HandleAddBookmark handles the add_bookmark tool.
+func HandleAddBookmark(ctx context.Context, reg *registry.Registry, store *bookmark.Store, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name := cast.ToString(request.Params.Arguments["name"])
+	path := cast.ToString(request.Params.Arguments["path"])
+
+	resolvedPath, errResult := resolveDirectory(reg, path)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	b, err := store.Add(name, resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to add bookmark: %w", err).Error()), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Bookmarked @%s -> %s", b.Name, b.Path)), nil
+}
+
+// NewListBookmarksTool creates the list_bookmarks tool.
+func NewListBookmarksTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"list_bookmarks",
+		mcp.WithDescription("List the directories bookmarked with add_bookmark."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleListBookmarks handles the list_bookmarks tool. Bookmarks to
+// directories no longer allowed are left out.
+func HandleListBookmarks(ctx context.Context, reg *registry.Registry, store *bookmark.Store, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	allowedDirs := reg.GetResolved()
+	bookmarks := []bookmark.Bookmark{}
+	for _, b := range store.List() {
+		if security.IsPathWithinAllowedDirectories(b.Path, allowedDirs) {
+			bookmarks = append(bookmarks, b)
+		}
+	}
+
+	if format == "json" {
+		jsonResult, err := json.MarshalIndent(bookmarks, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(jsonResult)), nil
+	}
+
+	if len(bookmarks) == 0 {
+		return mcp.NewToolResultText("No bookmarks"), nil
+	}
+
+	var result strings.Builder
+	for _, b := range bookmarks {
+		fmt.Fprintf(&result, "@%s -> %s\n", b.Name, b.Path)
+	}
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// NewRemoveBookmarkTool creates the remove_bookmark tool.
+func NewRemoveBookmarkTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"remove_bookmark",
+		mcp.WithDescription("Remove a bookmark added with add_bookmark. The directory itself is not touched."),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Remove Bookmark",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(true),
+			IdempotentHint:  boolPtr(true),
+		}),
+		mcp.WithString("name", mcp.Description("Name of the bookmark"), mcp.Required()),
+	)
+}
+
+// HandleRemoveBookmark handles the remove_bookmark tool.
+func HandleRemoveBookmark(ctx context.Context, reg *registry.Registry, store *bookmark.Store, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name := cast.ToString(request.Params.Arguments["name"])
+
+	if err := store.Delete(name); err != nil {
+		if errors.Is(err, bookmark.ErrNotFound) {
+			return mcp.NewToolResultError(fmt.Sprintf("no bookmark named %q", name)), nil
+		}
+		return mcp.NewToolResultError(fmt.Errorf("failed to remove bookmark: %w", err).Error()), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Removed bookmark %q", name)), nil
+}
@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/bookmark"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+)
+
+func TestBookmarkTools(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	store, _ := bookmark.NewStore("")
+	reg.SetBookmarks(store)
+	with := func(handler func(context.Context, *registry.Registry, *bookmark.Store, mcp.CallToolRequest) (*mcp.CallToolResult, error)) func(context.Context, *registry.Registry, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, reg *registry.Registry, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return handler(ctx, reg, store, req)
+		}
+	}
+
+	testsDir := filepath.Join(tmpDir, "internal", "service", "tests")
+	if err := os.MkdirAll(testsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(testsDir, "a_test.go"), []byte("package tests\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := callTool(t, with(HandleAddBookmark), reg, map[string]any{"name": "tests", "path": testsDir})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	result = callTool(t, with(HandleAddBookmark), reg, map[string]any{"name": "file", "path": filepath.Join(testsDir, "a_test.go")})
+	if !result.IsError {
+		t.Error("expected error bookmarking a file")
+	}
+
+	result = callTool(t, with(HandleListBookmarks), reg, map[string]any{})
+	if !strings.Contains(resultText(result), "@tests -> "+testsDir) {
+		t.Errorf("unexpected list:\n%s", resultText(result))
+	}
+
+	// Bookmarks expand in the path arguments of any tool
+	result = callTool(t, HandleReadTextFile, reg, map[string]any{"path": "@tests/a_test.go"})
+	if result.IsError || resultText(result) != "package tests\n" {
+		t.Errorf("unexpected read through bookmark: %s", resultText(result))
+	}
+	result = callTool(t, HandleWriteFile, reg, map[string]any{"path": "@tests/b_test.go", "content": "package tests\n"})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if _, err := os.Stat(filepath.Join(testsDir, "b_test.go")); err != nil {
+		t.Errorf("write through bookmark: %v", err)
+	}
+
+	result = callTool(t, with(HandleRemoveBookmark), reg, map[string]any{"name": "tests"})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	result = callTool(t, with(HandleRemoveBookmark), reg, map[string]any{"name": "tests"})
+	if !result.IsError {
+		t.Error("expected error removing a missing bookmark")
+	}
+	result = callTool(t, HandleReadTextFile, reg, map[string]any{"path": "@tests/a_test.go"})
+	if !result.IsError {
+		t.Error("expected error reading through a removed bookmark")
+	}
+}
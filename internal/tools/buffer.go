@@ -0,0 +1,339 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/buffer"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/spf13/cast"
+)
+
+// fileRegion is the part of a file a tool works on: a line range, a byte
+// range, or the whole file.
+type fileRegion struct {
+	// startLine and endLine are 1-based and inclusive; an endLine of 0
+	// means the last line
+	startLine, endLine int
+	// offset and length are used when byteRange is set; a length of 0
+	// means the rest of the file
+	offset, length int64
+	byteRange      bool
+}
+
+// withRegionParams adds the parameters parseRegion reads.
+func withRegionParams() []mcp.ToolOption {
+	return []mcp.ToolOption{
+		mcp.WithNumber("startLine", mcp.Description("First line of the region, 1-based (default: 1)")),
+		mcp.WithNumber("endLine", mcp.Description("Last line of the region, inclusive (default: the last line)")),
+		mcp.WithNumber("offset", mcp.Description("Byte offset the region starts at, instead of lines")),
+		mcp.WithNumber("length", mcp.Description("Number of bytes in the region, with offset (default: the rest of the file)")),
+	}
+}
+
+// parseRegion reads a region from startLine/endLine or offset/length.
+// Without either, the region is the whole file.
+func parseRegion(args map[string]any) (fileRegion, error) {
+	_, hasStart := args["startLine"]
+	_, hasEnd := args["endLine"]
+	_, hasOffset := args["offset"]
+	_, hasLength := args["length"]
+	if (hasStart || hasEnd) && (hasOffset || hasLength) {
+		return fileRegion{}, errors.New("cannot use startLine/endLine with offset/length")
+	}
+	if hasOffset || hasLength {
+		r := fileRegion{offset: cast.ToInt64(args["offset"]), length: cast.ToInt64(args["length"]), byteRange: true}
+		if r.offset < 0 {
+			return fileRegion{}, errors.New("offset must not be negative")
+		}
+		if hasLength && r.length <= 0 {
+			return fileRegion{}, errors.New("length must be positive")
+		}
+		return r, nil
+	}
+	r := fileRegion{startLine: 1}
+	if hasStart {
+		r.startLine = cast.ToInt(args["startLine"])
+	}
+	if hasEnd {
+		r.endLine = cast.ToInt(args["endLine"])
+		if r.endLine < 1 {
+			return fileRegion{}, errors.New("endLine must be at least 1")
+		}
+	}
+	if r.startLine < 1 {
+		return fileRegion{}, errors.New("startLine must be at least 1")
+	}
+	if r.endLine != 0 && r.endLine < r.startLine {
+		return fileRegion{}, fmt.Errorf("endLine %d is before startLine %d", r.endLine, r.startLine)
+	}
+	return r, nil
+}
+
+// readRegion reads region r of the file at path, refusing regions larger
+// than maxBytes. It also returns a description of the region read, such as
+// "lines 3-10".
+func readRegion(path string, r fileRegion, maxBytes int64) ([]byte, string, error) {
+	var buf bytes.Buffer
+	_, described, err := copyRegion(path, r, &buf, maxBytes)
+	if err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), described, nil
+}
+
+// copyRegion writes region r of the file at path to w, refusing regions
+// larger than maxBytes if it is positive. It returns the number of bytes
+// written and a description of the region, such as "lines 3-10". An
+// endLine past the end of the file stops at the last line.
+func copyRegion(path string, r fileRegion, w io.Writer, maxBytes int64) (int64, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return 0, "", err
+	}
+	size := info.Size()
+	tooLarge := func(n int64) error {
+		return fmt.Errorf("region is %s, over the limit of %s", stream.FormatSize(n), stream.FormatSize(maxBytes))
+	}
+
+	if r.byteRange {
+		if r.offset > size {
+			return 0, "", fmt.Errorf("offset %d is past the end of the file (%d bytes)", r.offset, size)
+		}
+		length := size - r.offset
+		if r.length > 0 && r.length < length {
+			length = r.length
+		}
+		if maxBytes > 0 && length > maxBytes {
+			return 0, "", tooLarge(length)
+		}
+		n, err := io.Copy(w, io.NewSectionReader(f, r.offset, length))
+		if err != nil {
+			return n, "", err
+		}
+		return n, fmt.Sprintf("bytes %d-%d", r.offset, r.offset+n), nil
+	}
+
+	var written int64
+	line := 0
+	reader := bufio.NewReader(f)
+	for r.endLine == 0 || line < r.endLine {
+		b, err := reader.ReadBytes('\n')
+		if len(b) > 0 {
+			line++
+			if line >= r.startLine {
+				if maxBytes > 0 && written+int64(len(b)) > maxBytes {
+					return written, "", tooLarge(written + int64(len(b)))
+				}
+				if _, err := w.Write(b); err != nil {
+					return written, "", err
+				}
+				written += int64(len(b))
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return written, "", err
+		}
+	}
+	if line == 0 && r.startLine == 1 {
+		return 0, "no lines", nil
+	}
+	if line < r.startLine {
+		return 0, "", fmt.Errorf("startLine %d is past the end of the file (%d lines)", r.startLine, line)
+	}
+	if line == r.startLine {
+		return written, fmt.Sprintf("line %d", line), nil
+	}
+	return written, fmt.Sprintf("lines %d-%d", r.startLine, line), nil
+}
+
+// NewCopyToBufferTool creates the copy_to_buffer tool.
+func NewCopyToBufferTool(reg *registry.Registry) mcp.Tool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription(fmt.Sprintf("Copy a line range, byte range, or whole file into a named in-memory buffer, to paste elsewhere with paste_from_buffer without passing the content through the conversation. Copying to a name in use replaces that buffer. Buffers are kept for %s after they are filled and hold at most %s each; all buffers together hold at most %s.", buffer.DefaultTTL, stream.FormatSize(buffer.DefaultMaxBytes), stream.FormatSize(buffer.DefaultMaxTotalBytes))),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Copy to Buffer",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  boolPtr(true),
+		}),
+		mcp.WithString("name", mcp.Description("Name of the buffer"), mcp.Required()),
+		mcp.WithString("path", mcp.Description("Path to the file to copy from"), mcp.Required()),
+	}
+	return mcp.NewTool("copy_to_buffer", append(opts, withRegionParams()...)...)
+}
+
+// HandleCopyToBuffer handles the copy_to_buffer tool.
+func HandleCopyToBuffer(ctx context.Context, reg *registry.Registry, store *buffer.Store, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name := cast.ToString(request.Params.Arguments["name"])
+	path := cast.ToString(request.Params.Arguments["path"])
+
+	region, err := parseRegion(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	resolvedPath, err := reg.Validate(path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	source, err := readTarget(reg, resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to read file: %w", err).Error()), nil
+	}
+	if info, err := os.Stat(source); err == nil && info.IsDir() {
+		return mcp.NewToolResultError("path is a directory, not a file"), nil
+	}
+
+	content, described, err := readRegion(source, region, store.MaxBytes())
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to read file: %w", err).Error()), nil
+	}
+	b, err := store.Put(name, content, fmt.Sprintf("%s of %s", described, resolvedPath))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Copied %s (%d bytes) to buffer %q; it expires at %s", b.Source, len(content), name, b.Expires.Format(time.RFC3339))), nil
+}
+
+// NewPasteFromBufferTool creates the paste_from_buffer tool.
+func NewPasteFromBufferTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"paste_from_buffer",
+		mcp.WithDescription("Paste a buffer filled by copy_to_buffer into a file: before a line, over a range of lines, at the end, or as the whole file. A file that does not exist is created. Pasted lines take the file's line endings. Returns a unified diff of text files. The buffer is kept, so it can be pasted again."),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithString("name", mcp.Description("Name of the buffer"), mcp.Required()),
+		mcp.WithString("path", mcp.Description("Path to the file to paste into"), mcp.Required()),
+		mcp.WithNumber("line", mcp.Description("Insert before this line, 1-based; one past the last line appends (default: append to the end)")),
+		mcp.WithNumber("endLine", mcp.Description("With line, replace lines line through endLine instead of inserting")),
+		mcp.WithBoolean("overwrite", mcp.Description("Replace the whole file with the buffer (default: false)")),
+		mcp.WithBoolean("dryRun", mcp.Description("If true, preview changes without writing")),
+	)
+}
+
+// HandlePasteFromBuffer handles the paste_from_buffer tool.
+func HandlePasteFromBuffer(ctx context.Context, reg *registry.Registry, store *buffer.Store, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name := cast.ToString(request.Params.Arguments["name"])
+	path := cast.ToString(request.Params.Arguments["path"])
+	overwrite := cast.ToBool(request.Params.Arguments["overwrite"])
+	dryRun := cast.ToBool(request.Params.Arguments["dryRun"])
+	_, hasLine := request.Params.Arguments["line"]
+	_, hasEndLine := request.Params.Arguments["endLine"]
+	line := cast.ToInt(request.Params.Arguments["line"])
+	endLine := cast.ToInt(request.Params.Arguments["endLine"])
+
+	if hasEndLine && !hasLine {
+		return mcp.NewToolResultError("endLine needs line"), nil
+	}
+	if overwrite && hasLine {
+		return mcp.NewToolResultError("cannot use line with overwrite"), nil
+	}
+	b, err := store.Get(name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("buffer %q: %w", name, err).Error()), nil
+	}
+
+	resolvedPath, err := reg.ValidateForCreation(path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	var original []byte
+	perm := reg.Modes().File
+	exists := false
+	if info, err := statTarget(reg, resolvedPath); err == nil {
+		if info.IsDir() {
+			return mcp.NewToolResultError("path is a directory, not a file"), nil
+		}
+		source, _ := readTarget(reg, resolvedPath)
+		if original, err = os.ReadFile(source); err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to read file: %w", err).Error()), nil
+		}
+		perm = info.Mode().Perm()
+		exists = true
+	}
+
+	var updated []byte
+	switch {
+	case overwrite || !exists:
+		if hasLine && line != 1 {
+			return mcp.NewToolResultError("file does not exist; only line 1 can be pasted at"), nil
+		}
+		updated = b.Content
+	case looksBinary(original) || looksBinary(b.Content):
+		if hasLine {
+			return mcp.NewToolResultError("cannot paste by line into or from binary content; omit line to append"), nil
+		}
+		updated = append(append([]byte(nil), original...), b.Content...)
+	default:
+		op := lineOperation{Type: "insert_before", Line: line, Text: string(b.Content)}
+		if !hasLine {
+			op.Line = lineCount(string(original)) + 1
+		}
+		if hasEndLine {
+			op.Type, op.EndLine = "replace_range", endLine
+			if endLine < line {
+				return mcp.NewToolResultError(fmt.Sprintf("endLine %d is before line %d", endLine, line)), nil
+			}
+		}
+		content, err := applyLineOperations(string(original), []lineOperation{op})
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		updated = []byte(content)
+	}
+
+	diff := ""
+	if !looksBinary(original) && !looksBinary(updated) {
+		diff = "\n\n" + generateUnifiedDiff(resolvedPath, string(original), string(updated))
+	}
+	if dryRun {
+		return mcp.NewToolResultText(fmt.Sprintf("Dry run - changes not applied:%s", diff)), nil
+	}
+
+	target, allowedDirs, err := writeTarget(reg, resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to write file: %w", err).Error()), nil
+	}
+	// Create parent directories if needed (the overlay creates its own)
+	if !exists && reg.Overlay() == nil {
+		if err := safeMkdirAll(filepath.Dir(reg.ExpandPath(path)), reg.Modes().Dir, reg.Get()); err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to create directories: %w", err).Error()), nil
+		}
+	}
+	if err := atomicWriteFile(target, updated, perm, allowedDirs); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to write file: %w", err).Error()), nil
+	}
+	if store := reg.Shadow(); store != nil {
+		store.Record(target, updated)
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Pasted buffer %q (%s) into %s%s", name, b.Source, resolvedPath, diff)), nil
+}
+
+// lineCount returns the number of lines in content, counting a last line
+// without a newline.
+func lineCount(content string) int {
+	n := strings.Count(content, "\n")
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		n++
+	}
+	return n
+}
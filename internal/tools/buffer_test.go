@@ -0,0 +1,96 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/buffer"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+)
+
+func callBufferTool(t *testing.T, handler func(context.Context, *registry.Registry, *buffer.Store, mcp.CallToolRequest) (*mcp.CallToolResult, error), reg *registry.Registry, store *buffer.Store, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = args
+	result, err := handler(context.Background(), reg, store, request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result
+}
+
+func TestCopyPasteBuffer(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	store := buffer.NewStore(buffer.DefaultTTL, buffer.DefaultMaxBytes, buffer.DefaultMaxTotalBytes)
+	src := filepath.Join(tmpDir, "big.go")
+	os.WriteFile(src, []byte("package big\n\nfunc A() {}\n\nfunc B() {}\n"), 0644)
+	dst := filepath.Join(tmpDir, "b.go")
+	os.WriteFile(dst, []byte("package big\r\n\r\n// end\r\n"), 0644)
+
+	result := callBufferTool(t, HandleCopyToBuffer, reg, store, map[string]any{"name": "funcB", "path": src, "startLine": 5, "endLine": 9})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if !strings.Contains(resultText(result), "line 5 of") {
+		t.Errorf("expected the range to stop at the last line, got %s", resultText(result))
+	}
+
+	result = callBufferTool(t, HandlePasteFromBuffer, reg, store, map[string]any{"name": "funcB", "path": dst, "line": 3})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "package big\r\n\r\nfunc B() {}\r\n// end\r\n" {
+		t.Errorf("unexpected content: %q", data)
+	}
+
+	// Appending to a new file creates it, and the buffer can be pasted again
+	created := filepath.Join(tmpDir, "sub", "c.go")
+	result = callBufferTool(t, HandlePasteFromBuffer, reg, store, map[string]any{"name": "funcB", "path": created})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if data, _ := os.ReadFile(created); string(data) != "func B() {}\n" {
+		t.Errorf("unexpected content: %q", data)
+	}
+
+	result = callBufferTool(t, HandlePasteFromBuffer, reg, store, map[string]any{"name": "funcB", "path": src, "line": 3, "endLine": 3, "dryRun": true})
+	if result.IsError || !strings.Contains(resultText(result), "-func A() {}") {
+		t.Errorf("expected a diff replacing line 3, got %s", resultText(result))
+	}
+	if data, _ := os.ReadFile(src); !strings.Contains(string(data), "func A") {
+		t.Error("expected a dry run to leave the file alone")
+	}
+}
+
+func TestCopyToBufferBytes(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	store := buffer.NewStore(buffer.DefaultTTL, 8, buffer.DefaultMaxTotalBytes)
+	src := filepath.Join(tmpDir, "data.bin")
+	os.WriteFile(src, []byte("0123456789"), 0644)
+
+	result := callBufferTool(t, HandleCopyToBuffer, reg, store, map[string]any{"name": "mid", "path": src, "offset": 2, "length": 4})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if b, _ := store.Get("mid"); string(b.Content) != "2345" {
+		t.Errorf("unexpected buffer: %q", b.Content)
+	}
+
+	for name, args := range map[string]map[string]any{
+		"too large": {"name": "all", "path": src},
+		"mixed":     {"name": "x", "path": src, "startLine": 1, "offset": 0},
+		"past end":  {"name": "x", "path": src, "offset": 11},
+		"outside":   {"name": "x", "path": "/etc/passwd"},
+	} {
+		if result := callBufferTool(t, HandleCopyToBuffer, reg, store, args); !result.IsError {
+			t.Errorf("%s: expected an error, got %s", name, resultText(result))
+		}
+	}
+	if result := callBufferTool(t, HandlePasteFromBuffer, reg, store, map[string]any{"name": "missing", "path": src}); !result.IsError {
+		t.Error("expected pasting a missing buffer to fail")
+	}
+}
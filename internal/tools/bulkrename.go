@@ -0,0 +1,313 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/spf13/cast"
+)
+
+// renamePlaceholder matches the placeholders of a bulk_rename template.
+var renamePlaceholder = regexp.MustCompile(`\{(name|ext|n)(?::(\d+))?\}`)
+
+// renamePair is one rename of bulk_rename, relative to its directory.
+type renamePair struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// bulkRenameResult is the result of bulk_rename.
+type bulkRenameResult struct {
+	Path    string       `json:"path"`
+	DryRun  bool         `json:"dryRun"`
+	Renamed []renamePair `json:"renamed"`
+	// Unchanged counts matched files whose new name is the same.
+	Unchanged int `json:"unchanged,omitempty"`
+}
+
+// NewBulkRenameTool creates the bulk_rename tool.
+func NewBulkRenameTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"bulk_rename",
+		mcp.WithDescription("Rename many files under a directory in one call. Files whose names match a glob pattern get a new name from a template, such as pattern '*.jpeg' with to '{name}.jpg', or from a regular expression replacement. Files stay in their own directories. All new names are checked before anything is renamed: if any two files would get the same name, or a new name is taken by a file that is not being renamed, nothing is renamed. Use dryRun to preview the old and new names."),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Bulk Rename",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(true),
+			IdempotentHint:  boolPtr(false),
+		}),
+		mcp.WithString("path", mcp.Description("Directory containing the files to rename"), mcp.Required()),
+		mcp.WithString("pattern", mcp.Description("Glob pattern matched against file names, such as '*.jpeg' (default: '*')")),
+		mcp.WithString("to", mcp.Description("New name. Without regex, a template where {name} is the old name without its extension, {ext} the extension without the dot, and {n} a counter in path order starting at 1, zero-padded with {n:3}. With regex, a replacement where $1 or ${1} is a capture group"), mcp.Required()),
+		mcp.WithString("regex", mcp.Description("Regular expression matched against file names. Only matching files are renamed, and the match is replaced with to")),
+		mcp.WithBoolean("recursive", mcp.Description("Also rename matching files in subdirectories (default: false)")),
+		mcp.WithBoolean("dryRun", mcp.Description("If true, list the old and new names without renaming")),
+		mcp.WithNumber("maxFiles", mcp.Description(fmt.Sprintf("Refuse directories with more than this many files and directories (default: %d)", defaultMaxFiles))),
+		withConfirmationToken(),
+		withApprovalToken(),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleBulkRename handles the bulk_rename tool.
+func HandleBulkRename(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	pattern := cast.ToString(request.Params.Arguments["pattern"])
+	to := cast.ToString(request.Params.Arguments["to"])
+	regex := cast.ToString(request.Params.Arguments["regex"])
+	recursive := cast.ToBool(request.Params.Arguments["recursive"])
+	dryRun := cast.ToBool(request.Params.Arguments["dryRun"])
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	if reg.Overlay() != nil {
+		return mcp.NewToolResultError("bulk_rename is not supported in overlay mode; use move_file for each file instead"), nil
+	}
+	if to == "" {
+		return mcp.NewToolResultError("to parameter is required"), nil
+	}
+	if pattern == "" {
+		pattern = "*"
+	}
+	matcher, err := glob.Compile(pattern)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid pattern %q: %v", pattern, err)), nil
+	}
+	var re *regexp.Regexp
+	if regex != "" {
+		if re, err = regexp.Compile(regex); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid regex %q: %v", regex, err)), nil
+		}
+	}
+	budget, err := parseMaxFiles(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	resolvedPath, err := reg.Validate(path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	if info, err := os.Stat(resolvedPath); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to stat path: %w", err).Error()), nil
+	} else if !info.IsDir() {
+		return mcp.NewToolResultError("path is not a directory"), nil
+	}
+
+	// Collect the matching files in path order
+	var sources []string
+	filter := treeFilter{onDir: func(dirPath, relPath string) error {
+		if !budget.take() {
+			return errMaxFiles
+		}
+		if !recursive {
+			return filepath.SkipDir
+		}
+		return nil
+	}}
+	err = walkTree(ctx, reg, resolvedPath, filter, func(walkPath, relPath string, entry fs.DirEntry) error {
+		if !budget.take() {
+			return errMaxFiles
+		}
+		if !matcher.Match(entry.Name()) || (re != nil && !re.MatchString(entry.Name())) {
+			return nil
+		}
+		sources = append(sources, walkPath)
+		return nil
+	})
+	if errors.Is(err, errMaxFiles) {
+		// Renaming part of the files would number them wrongly
+		return mcp.NewToolResultError(fmt.Sprintf("%s has more than %d files and directories; raise maxFiles to rename in it", resolvedPath, budget.max)), nil
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to walk directory: %w", err).Error()), nil
+	}
+
+	result := bulkRenameResult{Path: resolvedPath, DryRun: dryRun, Renamed: []renamePair{}}
+	var from, dest []string
+	for i, src := range sources {
+		name := filepath.Base(src)
+		var newName string
+		if re != nil {
+			newName = re.ReplaceAllString(name, to)
+		} else {
+			newName = expandRenameTemplate(to, name, i+1)
+		}
+		if newName == name {
+			result.Unchanged++
+			continue
+		}
+		if newName == "" || newName == "." || newName == ".." || strings.ContainsAny(newName, `/\`) {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid new name %q for %s: must be a file name", newName, src)), nil
+		}
+		target := filepath.Join(filepath.Dir(src), newName)
+		if _, err := reg.ValidateForCreation(target); err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("new name validation failed for %s: %w", src, err).Error()), nil
+		}
+		from = append(from, src)
+		dest = append(dest, target)
+		rel, _ := filepath.Rel(resolvedPath, src)
+		relTarget, _ := filepath.Rel(resolvedPath, target)
+		result.Renamed = append(result.Renamed, renamePair{From: filepath.ToSlash(rel), To: filepath.ToSlash(relTarget)})
+	}
+	if err := checkRenameConflicts(from, dest); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("nothing was renamed: %w", err).Error()), nil
+	}
+
+	if !dryRun && len(from) > 0 {
+		var protected []string
+		for i := range from {
+			if isProtected(reg, from[i], dest[i]) {
+				protected = append(protected, from[i])
+			}
+		}
+		if len(protected) > 0 {
+			action := fmt.Sprintf("rename %d files under %s, %d of them in protected paths", len(from), resolvedPath, len(protected))
+			if result := requireApproval(reg, "bulk_rename", request, action, protected...); result != nil {
+				return result, nil
+			}
+		}
+		for i := range from {
+			for _, p := range []string{from[i], dest[i]} {
+				if err := reg.CheckWrite(p); err != nil {
+					return mcp.NewToolResultError(fmt.Errorf("nothing was renamed: %w", err).Error()), nil
+				}
+			}
+		}
+		if err := renameAll(from, dest); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	if format == "json" {
+		jsonResult, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(jsonResult)), nil
+	}
+
+	var text strings.Builder
+	switch {
+	case len(result.Renamed) == 0:
+		fmt.Fprintf(&text, "No files to rename in %s\n", resolvedPath)
+	case dryRun:
+		fmt.Fprintf(&text, "Dry run - would rename %d files in %s:\n", len(result.Renamed), resolvedPath)
+	default:
+		fmt.Fprintf(&text, "Renamed %d files in %s:\n", len(result.Renamed), resolvedPath)
+	}
+	for _, pair := range result.Renamed {
+		fmt.Fprintf(&text, "%s -> %s\n", pair.From, pair.To)
+	}
+	if result.Unchanged > 0 {
+		fmt.Fprintf(&text, "%d matching files already have their new name\n", result.Unchanged)
+	}
+	return mcp.NewToolResultText(text.String()), nil
+}
+
+// expandRenameTemplate fills in the placeholders of tmpl for the file
+// name, the n-th file renamed.
+func expandRenameTemplate(tmpl, name string, n int) string {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	if base == "" {
+		// A dotfile such as .env has no extension
+		base, ext = name, ""
+	}
+	return renamePlaceholder.ReplaceAllStringFunc(tmpl, func(m string) string {
+		parts := renamePlaceholder.FindStringSubmatch(m)
+		switch parts[1] {
+		case "name":
+			return base
+		case "ext":
+			return strings.TrimPrefix(ext, ".")
+		}
+		width, _ := strconv.Atoi(parts[2])
+		return fmt.Sprintf("%0*d", width, n)
+	})
+}
+
+// checkRenameConflicts reports new names used twice, and new names taken
+// by files that are not themselves being renamed.
+func checkRenameConflicts(from, dest []string) error {
+	renamed := make(map[string]bool, len(from))
+	for _, src := range from {
+		renamed[src] = true
+	}
+	seen := make(map[string]string, len(dest))
+	for i, target := range dest {
+		if other, ok := seen[target]; ok {
+			return fmt.Errorf("%s and %s would both be renamed to %s", other, from[i], target)
+		}
+		seen[target] = from[i]
+		if _, err := os.Lstat(target); err == nil && !renamed[target] {
+			return fmt.Errorf("cannot rename %s: %s already exists", from[i], target)
+		}
+	}
+	return nil
+}
+
+// renameAll renames each of from to the matching dest. When a new name is
+// another file's old name, as in a swap, every file first moves to a
+// temporary name. If a rename fails, the ones done are undone.
+func renameAll(from, dest []string) error {
+	sources := make(map[string]bool, len(from))
+	for _, src := range from {
+		sources[src] = true
+	}
+	chained := false
+	for _, target := range dest {
+		if sources[target] {
+			chained = true
+			break
+		}
+	}
+
+	type step struct{ from, to string }
+	var steps []step
+	if chained {
+		for i := range from {
+			tmp, err := batchTempName(filepath.Dir(from[i]), ".rename-")
+			if err != nil {
+				return err
+			}
+			steps = append(steps, step{from[i], tmp})
+		}
+		for i := range from {
+			steps = append(steps, step{steps[i].to, dest[i]})
+		}
+	} else {
+		for i := range from {
+			steps = append(steps, step{from[i], dest[i]})
+		}
+	}
+
+	for i, s := range steps {
+		var err error
+		if _, statErr := os.Lstat(s.to); statErr == nil {
+			err = fmt.Errorf("%s already exists", s.to)
+		} else {
+			err = os.Rename(s.from, s.to)
+		}
+		if err == nil {
+			continue
+		}
+		for j := i - 1; j >= 0; j-- {
+			if undoErr := os.Rename(steps[j].to, steps[j].from); undoErr != nil {
+				return fmt.Errorf("failed to rename %s: %w; undoing the earlier renames also failed at %s: %v", s.from, err, steps[j].to, undoErr)
+			}
+		}
+		return fmt.Errorf("failed to rename %s: %w; nothing was renamed", s.from, err)
+	}
+	return nil
+}
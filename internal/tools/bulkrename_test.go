@@ -0,0 +1,121 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/portertech/filesystem-mcp-server/internal/trash"
+)
+
+func TestExpandRenameTemplate(t *testing.T) {
+	tests := []struct {
+		tmpl, name string
+		n          int
+		want       string
+	}{
+		{"{name}.jpg", "photo.jpeg", 1, "photo.jpg"},
+		{"img-{n:3}.{ext}", "photo.jpeg", 7, "img-007.jpeg"},
+		{"{name}-{n}", ".env", 2, ".env-2"},
+		{"{name}.bak", "archive.tar.gz", 1, "archive.tar.bak"},
+	}
+	for _, tt := range tests {
+		if got := expandRenameTemplate(tt.tmpl, tt.name, tt.n); got != tt.want {
+			t.Errorf("expandRenameTemplate(%q, %q, %d) = %q, want %q", tt.tmpl, tt.name, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestHandleBulkRename(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	os.MkdirAll(filepath.Join(tmpDir, "sub"), 0755)
+	for _, name := range []string{"a.jpeg", "b.jpeg", "notes.txt", "sub/c.jpeg"} {
+		os.WriteFile(filepath.Join(tmpDir, name), []byte(name), 0644)
+	}
+
+	result := callTool(t, HandleBulkRename, reg, map[string]any{"path": tmpDir, "pattern": "*.jpeg", "to": "{name}.jpg", "dryRun": true})
+	text := resultText(result)
+	if result.IsError || !strings.Contains(text, "a.jpeg -> a.jpg") || strings.Contains(text, "c.jpeg") {
+		t.Fatalf("unexpected dry run: %s", text)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "a.jpeg")); err != nil {
+		t.Fatal("expected a dry run to rename nothing")
+	}
+
+	result = callTool(t, HandleBulkRename, reg, map[string]any{"path": tmpDir, "pattern": "*.jpeg", "to": "{name}.jpg", "recursive": true})
+	if result.IsError || !strings.Contains(resultText(result), "Renamed 3 files") {
+		t.Fatalf("unexpected result: %s", resultText(result))
+	}
+	for _, name := range []string{"a.jpg", "b.jpg", "sub/c.jpg"} {
+		if data, err := os.ReadFile(filepath.Join(tmpDir, name)); err != nil || !strings.HasSuffix(string(data), ".jpeg") {
+			t.Errorf("expected %s to hold the renamed file, got %q, %v", name, data, err)
+		}
+	}
+}
+
+func TestHandleBulkRenameConflicts(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	for _, name := range []string{"a.txt", "b.txt", "c.md", "taken.md"} {
+		os.WriteFile(filepath.Join(tmpDir, name), []byte(name), 0644)
+	}
+
+	for name, args := range map[string]map[string]any{
+		"same new name":  {"path": tmpDir, "pattern": "*.txt", "to": "same.txt"},
+		"existing file":  {"path": tmpDir, "pattern": "c.md", "to": "taken.md"},
+		"path separator": {"path": tmpDir, "pattern": "a.txt", "to": "../a.txt"},
+	} {
+		if result := callTool(t, HandleBulkRename, reg, args); !result.IsError {
+			t.Errorf("%s: expected an error, got %s", name, resultText(result))
+		}
+	}
+	for _, name := range []string{"a.txt", "b.txt", "c.md", "taken.md"} {
+		if data, _ := os.ReadFile(filepath.Join(tmpDir, name)); string(data) != name {
+			t.Errorf("expected %s to be untouched", name)
+		}
+	}
+
+	// A new name that is another file's old name is not a conflict
+	os.WriteFile(filepath.Join(tmpDir, "x.log"), []byte("x"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "xx.log"), []byte("xx"), 0644)
+	result := callTool(t, HandleBulkRename, reg, map[string]any{"path": tmpDir, "pattern": "*.log", "regex": "^x", "to": "xx"})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	for name, want := range map[string]string{"xx.log": "x", "xxx.log": "xx"} {
+		if data, _ := os.ReadFile(filepath.Join(tmpDir, name)); string(data) != want {
+			t.Errorf("%s: expected %q, got %q", name, want, data)
+		}
+	}
+}
+
+func TestHandleBulkRenameSkipsTrash(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	os.MkdirAll(filepath.Join(tmpDir, trash.DirName), 0755)
+	trashed := filepath.Join(tmpDir, trash.DirName, "old.jpeg")
+	os.WriteFile(trashed, []byte("old"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "a.jpeg"), []byte("a"), 0644)
+
+	result := callTool(t, HandleBulkRename, reg, map[string]any{"path": tmpDir, "pattern": "*.jpeg", "to": "{name}.jpg", "recursive": true})
+	if result.IsError || !strings.Contains(resultText(result), "Renamed 1 files") {
+		t.Fatalf("unexpected result: %s", resultText(result))
+	}
+	if _, err := os.Stat(trashed); err != nil {
+		t.Errorf("expected the trash to be left alone: %v", err)
+	}
+}
+
+func TestHandleBulkRenameMaxFiles(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	for _, name := range []string{"a.jpeg", "b.jpeg"} {
+		os.WriteFile(filepath.Join(tmpDir, name), []byte(name), 0644)
+	}
+
+	result := callTool(t, HandleBulkRename, reg, map[string]any{"path": tmpDir, "pattern": "*.jpeg", "to": "{n}.jpg", "maxFiles": 1})
+	if !result.IsError || !strings.Contains(resultText(result), "raise maxFiles") {
+		t.Fatalf("expected the rename to be refused, got %s", resultText(result))
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "a.jpeg")); err != nil {
+		t.Error("expected nothing to be renamed")
+	}
+}
@@ -0,0 +1,167 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/spf13/cast"
+)
+
+const (
+	defaultBytesLength = 1024
+	maxBytesLength     = 1024 * 1024
+)
+
+// byteRange is the base64 result of read_file_bytes.
+type byteRange struct {
+	Path   string `json:"path"`
+	Offset int64  `json:"offset"`
+	Length int    `json:"length"`
+	Size   int64  `json:"size"`
+	Data   string `json:"data"`
+	// NextOffset is the offset of the following range, absent at the end
+	// of the file.
+	NextOffset *int64 `json:"nextOffset,omitempty"`
+}
+
+// NewReadFileBytesTool creates the read_file_bytes tool.
+func NewReadFileBytesTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"read_file_bytes",
+		mcp.WithDescription("Read a range of bytes from any file, text or binary, and return it as a hex dump with offsets and ASCII, or as base64. Use it to inspect file headers and magic numbers, or to page through large and binary files that read_text_file cannot show."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path", mcp.Description("Path to the file to read"), mcp.Required()),
+		mcp.WithNumber("offset", mcp.Description("Byte offset to start at; negative offsets count back from the end of the file (default: 0)")),
+		mcp.WithNumber("length", mcp.Description(fmt.Sprintf("Number of bytes to read (default: %d, max: %d)", defaultBytesLength, maxBytesLength))),
+		mcp.WithString("encoding", mcp.Description("Output encoding: 'hex' for a hex dump or 'base64' for JSON with base64 data (default: hex)")),
+	)
+}
+
+// HandleReadFileBytes handles the read_file_bytes tool.
+func HandleReadFileBytes(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	offset := cast.ToInt64(request.Params.Arguments["offset"])
+	length := defaultBytesLength
+	if _, ok := request.Params.Arguments["length"]; ok {
+		length = cast.ToInt(request.Params.Arguments["length"])
+	}
+	encoding := cast.ToString(request.Params.Arguments["encoding"])
+
+	if length <= 0 || length > maxBytesLength {
+		return mcp.NewToolResultError(fmt.Sprintf("length must be between 1 and %d", maxBytesLength)), nil
+	}
+	if encoding == "" {
+		encoding = "hex"
+	}
+	if encoding != "hex" && encoding != "base64" {
+		return mcp.NewToolResultError("encoding must be 'hex' or 'base64'"), nil
+	}
+
+	resolvedPath, err := validateRead(reg, path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	f, err := os.Open(resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to open file: %w", err).Error()), nil
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to stat file: %w", err).Error()), nil
+	}
+	if info.IsDir() {
+		return mcp.NewToolResultError("path is a directory, not a file"), nil
+	}
+	match, err := checkReputation(reg, resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	size := info.Size()
+	if offset < 0 {
+		offset = max(0, size+offset)
+	}
+	if offset > size {
+		return mcp.NewToolResultError(fmt.Sprintf("offset %d is past the end of the file (%d bytes)", offset, size)), nil
+	}
+
+	// The hex dump is about four times the size of the bytes it shows
+	release, err := reserveMemory(ctx, reg, resolvedPath, 5*int64(length))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	defer release()
+
+	buf := make([]byte, length)
+	n, err := f.ReadAt(buf, offset)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return mcp.NewToolResultError(fmt.Errorf("failed to read file: %w", err).Error()), nil
+	}
+	buf = buf[:n]
+	next := offset + int64(n)
+
+	if encoding == "base64" {
+		result := byteRange{Path: path, Offset: offset, Length: n, Size: size, Data: base64.StdEncoding.EncodeToString(buf)}
+		if next < size {
+			result.NextOffset = &next
+		}
+		jsonResult, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return withReputationWarning(mcp.NewToolResultText(string(jsonResult)), match), nil
+	}
+
+	var text strings.Builder
+	if n == 0 {
+		fmt.Fprintf(&text, "%s: no bytes at offset %d of %s\n", path, offset, stream.FormatSize(size))
+	} else {
+		fmt.Fprintf(&text, "%s: bytes %d-%d of %s (%d bytes)\n", path, offset, next-1, stream.FormatSize(size), size)
+		hexDump(&text, buf, offset)
+	}
+	if next < size {
+		fmt.Fprintf(&text, "Next offset: %d\n", next)
+	}
+	return withReputationWarning(mcp.NewToolResultText(text.String()), match), nil
+}
+
+// hexDump writes data in the layout of hexdump -C, labelling lines with
+// offsets starting at base.
+func hexDump(w *strings.Builder, data []byte, base int64) {
+	const hexDigits = "0123456789abcdef"
+	for i := 0; i < len(data); i += 16 {
+		line := data[i:min(i+16, len(data))]
+		fmt.Fprintf(w, "%08x  ", base+int64(i))
+		for j := range 16 {
+			if j < len(line) {
+				w.WriteByte(hexDigits[line[j]>>4])
+				w.WriteByte(hexDigits[line[j]&0xf])
+				w.WriteByte(' ')
+			} else {
+				w.WriteString("   ")
+			}
+			if j == 7 {
+				w.WriteByte(' ')
+			}
+		}
+		w.WriteString(" |")
+		for _, b := range line {
+			if b >= 0x20 && b < 0x7f {
+				w.WriteByte(b)
+			} else {
+				w.WriteByte('.')
+			}
+		}
+		w.WriteString("|\n")
+	}
+}
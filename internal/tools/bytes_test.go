@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHandleReadFileBytes(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	data := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR" + strings.Repeat("x", 40) + "tail")
+	path := filepath.Join(tmpDir, "image.bin")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := callTool(t, HandleReadFileBytes, reg, map[string]any{"path": path, "length": 20})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	text := resultText(result)
+	for _, want := range []string{
+		"bytes 0-19 of 60 B (60 bytes)",
+		"00000000  89 50 4e 47 0d 0a 1a 0a  00 00 00 0d 49 48 44 52  |.PNG........IHDR|",
+		"00000010  78 78 78 78                                       |xxxx|",
+		"Next offset: 20",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+
+	// Negative offsets count from the end, and the last range has no next
+	// offset
+	result = callTool(t, HandleReadFileBytes, reg, map[string]any{"path": path, "offset": -4, "encoding": "base64"})
+	var got byteRange
+	if err := json.Unmarshal([]byte(resultText(result)), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, resultText(result))
+	}
+	if got.Offset != 56 || got.Length != 4 || got.Size != 60 || got.NextOffset != nil || got.Data != base64.StdEncoding.EncodeToString([]byte("tail")) {
+		t.Errorf("unexpected result: %+v", got)
+	}
+
+	for name, args := range map[string]map[string]any{
+		"past end":     {"path": path, "offset": 61},
+		"zero length":  {"path": path, "length": 0},
+		"too long":     {"path": path, "length": maxBytesLength + 1},
+		"bad encoding": {"path": path, "encoding": "octal"},
+		"directory":    {"path": tmpDir},
+	} {
+		if result := callTool(t, HandleReadFileBytes, reg, args); !result.IsError {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package tools
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/spf13/cast"
+)
+
+// checksumAlgorithms maps the algorithms file_checksum accepts to their
+// constructors.
+var checksumAlgorithms = map[string]func() hash.Hash{
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+	"sha1":   sha1.New,
+	"md5":    md5.New,
+	"crc32":  func() hash.Hash { return crc32.NewIEEE() },
+}
+
+// fileChecksum is the result of file_checksum.
+type fileChecksum struct {
+	Path      string `json:"path"`
+	Algorithm string `json:"algorithm"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+	// Match is set when an expected digest was given.
+	Match *bool `json:"match,omitempty"`
+}
+
+// NewFileChecksumTool creates the file_checksum tool.
+func NewFileChecksumTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"file_checksum",
+		mcp.WithDescription("Compute the checksum of a file with one algorithm (sha256, sha512, sha1, md5, or crc32), streaming it so large files are not loaded into memory. Pass expected to verify a file after copying or writing it."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path", mcp.Description("Path to the file"), mcp.Required()),
+		mcp.WithString("algorithm", mcp.Description("Hash algorithm: 'sha256' (default), 'sha512', 'sha1', 'md5', or 'crc32'")),
+		mcp.WithString("expected", mcp.Description("Hex digest to compare against, case-insensitive")),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleFileChecksum handles the file_checksum tool.
+func HandleFileChecksum(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	algorithm := strings.ToLower(cast.ToString(request.Params.Arguments["algorithm"]))
+	expected := strings.ToLower(strings.TrimSpace(cast.ToString(request.Params.Arguments["expected"])))
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	result, errResult := computeChecksum(reg, path, algorithm)
+	if errResult != nil {
+		return errResult, nil
+	}
+	if expected != "" {
+		match := expected == result.Digest
+		result.Match = &match
+	}
+
+	if format == "json" {
+		jsonResult, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(jsonResult)), nil
+	}
+
+	// Same layout as sha256sum and friends
+	text := fmt.Sprintf("%s  %s\n", result.Digest, result.Path)
+	if result.Match != nil {
+		if *result.Match {
+			text += "OK: matches expected digest\n"
+		} else {
+			text += fmt.Sprintf("MISMATCH: expected %s\n", expected)
+		}
+	}
+	return mcp.NewToolResultText(text), nil
+}
+
+// checksumResult is the result of verify_checksum.
+type checksumResult struct {
+	Path      string `json:"path"`
+	Algorithm string `json:"algorithm"`
+	Expected  string `json:"expected"`
+	Actual    string `json:"actual"`
+	Size      int64  `json:"size"`
+	Match     bool   `json:"match"`
+}
+
+// NewVerifyChecksumTool creates the verify_checksum tool.
+func NewVerifyChecksumTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"verify_checksum",
+		mcp.WithDescription("Check a file against an expected digest and report whether it matches, along with the actual digest. The file is streamed, so large files are not loaded into memory. A mismatch is reported in the result, not as an error."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path", mcp.Description("Path to the file"), mcp.Required()),
+		mcp.WithString("expected", mcp.Description("Expected hex digest, case-insensitive"), mcp.Required()),
+		mcp.WithString("algorithm", mcp.Description("Hash algorithm: 'sha256' (default), 'sha512', 'sha1', 'md5', or 'crc32'")),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleVerifyChecksum handles the verify_checksum tool.
+func HandleVerifyChecksum(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	algorithm := strings.ToLower(cast.ToString(request.Params.Arguments["algorithm"]))
+	expected := strings.ToLower(strings.TrimSpace(cast.ToString(request.Params.Arguments["expected"])))
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	if expected == "" {
+		return mcp.NewToolResultError("expected digest is required"), nil
+	}
+	if _, err := hex.DecodeString(expected); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("expected digest %q is not hexadecimal", expected)), nil
+	}
+
+	sum, errResult := computeChecksum(reg, path, algorithm)
+	if errResult != nil {
+		return errResult, nil
+	}
+	// A digest of the wrong length almost always means the wrong algorithm
+	if len(expected) != len(sum.Digest) {
+		return mcp.NewToolResultError(fmt.Sprintf("expected digest has %d hex digits, but %s digests have %d", len(expected), sum.Algorithm, len(sum.Digest))), nil
+	}
+	result := checksumResult{
+		Path:      sum.Path,
+		Algorithm: sum.Algorithm,
+		Expected:  expected,
+		Actual:    sum.Digest,
+		Size:      sum.Size,
+		Match:     expected == sum.Digest,
+	}
+
+	if format == "json" {
+		jsonResult, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(jsonResult)), nil
+	}
+
+	if result.Match {
+		return mcp.NewToolResultText(fmt.Sprintf("OK: %s %s matches %s\n", result.Path, result.Algorithm, result.Actual)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("MISMATCH: %s %s is %s, expected %s\n", result.Path, result.Algorithm, result.Actual, result.Expected)), nil
+}
+
+// computeChecksum validates path and streams it through algorithm, which
+// defaults to sha256.
+func computeChecksum(reg *registry.Registry, path, algorithm string) (fileChecksum, *mcp.CallToolResult) {
+	if algorithm == "" {
+		algorithm = "sha256"
+	}
+	newHash, ok := checksumAlgorithms[algorithm]
+	if !ok {
+		return fileChecksum{}, mcp.NewToolResultError(fmt.Sprintf("unsupported algorithm %q: expected sha256, sha512, sha1, md5, or crc32", algorithm))
+	}
+
+	resolvedPath, err := validateRead(reg, path)
+	if err != nil {
+		return fileChecksum{}, mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error())
+	}
+	info, err := os.Stat(resolvedPath)
+	if err != nil {
+		return fileChecksum{}, mcp.NewToolResultError(fmt.Errorf("failed to stat file: %w", err).Error())
+	}
+	if info.IsDir() {
+		return fileChecksum{}, mcp.NewToolResultError("path is a directory, not a file")
+	}
+
+	h := newHash()
+	size, err := stream.HashFile(resolvedPath, h)
+	if err != nil {
+		return fileChecksum{}, mcp.NewToolResultError(fmt.Errorf("failed to hash file: %w", err).Error())
+	}
+	return fileChecksum{Path: resolvedPath, Algorithm: algorithm, Digest: hex.EncodeToString(h.Sum(nil)), Size: size}, nil
+}
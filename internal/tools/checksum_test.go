@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHandleFileChecksum(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	path := filepath.Join(tmpDir, "data.txt")
+	if err := os.WriteFile(path, []byte("hello world"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		algorithm string
+		digest    string
+	}{
+		{"", "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"},
+		{"sha1", "2aae6c35c94fcfb415dbe95f408b9ce91ee846ed"},
+		{"MD5", "5eb63bbbe01eeed093cb22bb8f5acdc3"},
+		{"crc32", "0d4a1185"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			result := callTool(t, HandleFileChecksum, reg, map[string]any{"path": path, "algorithm": tt.algorithm})
+			if result.IsError {
+				t.Fatalf("file_checksum failed: %s", resultText(result))
+			}
+			if want := tt.digest + "  " + path + "\n"; resultText(result) != want {
+				t.Errorf("got %q, want %q", resultText(result), want)
+			}
+		})
+	}
+
+	result := callTool(t, HandleFileChecksum, reg, map[string]any{"path": path, "algorithm": "crc32", "expected": "0D4A1185", "format": "json"})
+	var sum fileChecksum
+	if err := json.Unmarshal([]byte(resultText(result)), &sum); err != nil {
+		t.Fatal(err)
+	}
+	if sum.Match == nil || !*sum.Match || sum.Size != 11 {
+		t.Errorf("expected a matching 11-byte checksum, got %+v", sum)
+	}
+
+	result = callTool(t, HandleFileChecksum, reg, map[string]any{"path": path, "expected": "deadbeef"})
+	if result.IsError || !strings.Contains(resultText(result), "MISMATCH") {
+		t.Errorf("expected a mismatch report, got %q", resultText(result))
+	}
+
+	result = callTool(t, HandleFileChecksum, reg, map[string]any{"path": path, "algorithm": "sha3"})
+	if !result.IsError {
+		t.Error("expected an unsupported algorithm to be refused")
+	}
+	result = callTool(t, HandleFileChecksum, reg, map[string]any{"path": tmpDir})
+	if !result.IsError {
+		t.Error("expected a directory to be refused")
+	}
+}
+
+func TestHandleVerifyChecksum(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	path := filepath.Join(tmpDir, "data.txt")
+	if err := os.WriteFile(path, []byte("hello world"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := callTool(t, HandleVerifyChecksum, reg, map[string]any{"path": path, "expected": "B94D27B9934D3E08A52E52D7DA7DABFAC484EFE37A5380EE9088F7ACE2EFCDE9"})
+	if result.IsError || !strings.HasPrefix(resultText(result), "OK: ") {
+		t.Errorf("expected a match, got %q", resultText(result))
+	}
+
+	result = callTool(t, HandleVerifyChecksum, reg, map[string]any{"path": path, "algorithm": "md5", "expected": "00000000000000000000000000000000", "format": "json"})
+	if result.IsError {
+		t.Fatalf("verify_checksum failed: %s", resultText(result))
+	}
+	var check checksumResult
+	if err := json.Unmarshal([]byte(resultText(result)), &check); err != nil {
+		t.Fatal(err)
+	}
+	if check.Match || check.Actual != "5eb63bbbe01eeed093cb22bb8f5acdc3" {
+		t.Errorf("expected a mismatch with the actual digest, got %+v", check)
+	}
+
+	for name, args := range map[string]map[string]any{
+		"missing expected": {"path": path},
+		"not hex":          {"path": path, "expected": "xyz"},
+		"wrong length":     {"path": path, "algorithm": "sha1", "expected": "0d4a1185"},
+		"directory":        {"path": tmpDir, "expected": "0d4a1185", "algorithm": "crc32"},
+	} {
+		if result := callTool(t, HandleVerifyChecksum, reg, args); !result.IsError {
+			t.Errorf("%s: expected an error, got %q", name, resultText(result))
+		}
+	}
+}
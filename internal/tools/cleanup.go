@@ -0,0 +1,296 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/security"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/spf13/cast"
+)
+
+// cleanupEntry describes a file selected by cleanup_old_files.
+type cleanupEntry struct {
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	MovedTo  string    `json:"movedTo,omitempty"`
+}
+
+// cleanupReport is the JSON output of cleanup_old_files.
+type cleanupReport struct {
+	DryRun     bool           `json:"dryRun"`
+	Action     string         `json:"action"`
+	Cutoff     time.Time      `json:"cutoff"`
+	TotalFiles int            `json:"totalFiles"`
+	TotalBytes int64          `json:"totalBytes"`
+	Files      []cleanupEntry `json:"files"`
+}
+
+// NewCleanupOldFilesTool creates the cleanup_old_files tool.
+func NewCleanupOldFilesTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"cleanup_old_files",
+		mcp.WithDescription("Delete, or move to a trash directory, files under a directory that were last modified longer ago than a given age. Use dryRun to preview the report first."),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Clean Up Old Files",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(true),
+			IdempotentHint:  boolPtr(true),
+		}),
+		mcp.WithString("path", mcp.Description("Directory to clean up"), mcp.Required()),
+		mcp.WithString("olderThan", mcp.Description("Minimum age of files to remove, e.g. '36h', '7d', or '2w'"), mcp.Required()),
+		mcp.WithArray("patterns", mcp.Description("Glob patterns selecting files, relative to path (default: all files)"), mcp.Items(map[string]any{"type": "string"})),
+		mcp.WithArray("excludePatterns", mcp.Description("Glob patterns to exclude"), mcp.Items(map[string]any{"type": "string"})),
+		mcp.WithString("trashDir", mcp.Description("If set, move files into this directory (keeping their relative paths) instead of deleting them")),
+		mcp.WithBoolean("dryRun", mcp.Description("If true, report what would be removed without changing anything")),
+		withMaxFilesParam(),
+		withForce(),
+		withConfirmationToken(),
+		withApprovalToken(),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleCleanupOldFiles handles the cleanup_old_files tool.
+func HandleCleanupOldFiles(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	olderThan := cast.ToString(request.Params.Arguments["olderThan"])
+	trashDir := cast.ToString(request.Params.Arguments["trashDir"])
+	dryRun := cast.ToBool(request.Params.Arguments["dryRun"])
+	force := cast.ToBool(request.Params.Arguments["force"])
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	age, err := parseAge(olderThan)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("invalid olderThan: %w", err).Error()), nil
+	}
+
+	resolvedPath, err := reg.Validate(path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	info, err := os.Stat(resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to stat path: %w", err).Error()), nil
+	}
+	if !info.IsDir() {
+		return mcp.NewToolResultError("path is not a directory"), nil
+	}
+
+	var resolvedTrash string
+	if trashDir != "" {
+		if reg.Overlay() != nil {
+			return mcp.NewToolResultError("trashDir is not supported in overlay mode"), nil
+		}
+		resolvedTrash, err = reg.ValidateForCreation(trashDir)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("trash directory validation failed: %w", err).Error()), nil
+		}
+		if resolvedTrash == resolvedPath || strings.HasPrefix(resolvedTrash, resolvedPath+string(filepath.Separator)) {
+			return mcp.NewToolResultError("trashDir must not be inside the directory being cleaned"), nil
+		}
+	}
+
+	filter, err := parseTreeFilter(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	// Build output and caches are usually ignored, and are what gets cleaned
+	filter.gitignore = false
+	budget, err := parseMaxFiles(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	report := cleanupReport{
+		DryRun: dryRun,
+		Action: "delete",
+		Cutoff: time.Now().Add(-age),
+		Files:  []cleanupEntry{},
+	}
+	if resolvedTrash != "" {
+		report.Action = "trash"
+	}
+
+	var walkErrs walkErrors
+	filter.onError = walkErrs.add
+	filter.onDir = func(dirPath, relPath string) error {
+		if !budget.take() {
+			return errMaxFiles
+		}
+		return nil
+	}
+	err = walkTree(ctx, reg, resolvedPath, filter, func(walkPath, relPath string, entry fs.DirEntry) error {
+		if !budget.take() {
+			return errMaxFiles
+		}
+		fileInfo, err := entry.Info()
+		if err != nil {
+			walkErrs.add(walkPath, err)
+			return nil
+		}
+		if !fileInfo.ModTime().Before(report.Cutoff) {
+			return nil
+		}
+
+		report.Files = append(report.Files, cleanupEntry{
+			Path:     walkPath,
+			Size:     fileInfo.Size(),
+			Modified: fileInfo.ModTime(),
+		})
+		report.TotalFiles++
+		report.TotalBytes += fileInfo.Size()
+		return nil
+	})
+	if err != nil && !errors.Is(err, errMaxFiles) {
+		return mcp.NewToolResultError(fmt.Errorf("cleanup failed: %w", err).Error()), nil
+	}
+
+	if !dryRun {
+		if report.Action == "delete" {
+			if err := checkDeleteLimits(reg, report.TotalFiles, report.TotalBytes, force); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+		var protected []string
+		for _, entry := range report.Files {
+			if isProtected(reg, entry.Path) {
+				protected = append(protected, entry.Path)
+			}
+		}
+		if len(protected) > 0 {
+			action := fmt.Sprintf("%s %d files under %s, %d of them in protected paths", report.Action, report.TotalFiles, resolvedPath, len(protected))
+			if result := requireApproval(reg, "cleanup_old_files", request, action, protected...); result != nil {
+				return result, nil
+			}
+		}
+		for i := range report.Files {
+			entry := &report.Files[i]
+			if resolvedTrash != "" {
+				entry.MovedTo, err = moveToTrash(reg, resolvedPath, resolvedTrash, entry.Path)
+			} else {
+				err = removeFile(reg, entry.Path, entry.Size)
+			}
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Errorf("failed to clean up %s after processing %d of %d files: %w", entry.Path, i, report.TotalFiles, err).Error()), nil
+			}
+		}
+	}
+
+	if format == "json" {
+		jsonResult, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return withWalkErrors(withBudgetNote(mcp.NewToolResultText(string(jsonResult)), budget), &walkErrs), nil
+	}
+
+	if report.TotalFiles == 0 {
+		return withWalkErrors(withBudgetNote(mcp.NewToolResultText(fmt.Sprintf("No files older than %s found", olderThan)), budget), &walkErrs), nil
+	}
+
+	verb := "Deleted"
+	if resolvedTrash != "" {
+		verb = "Moved to trash"
+	}
+	if dryRun {
+		verb = "Dry run - would delete"
+		if resolvedTrash != "" {
+			verb = "Dry run - would move to trash"
+		}
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "%s %d files (%s) older than %s:\n", verb, report.TotalFiles, stream.FormatSize(report.TotalBytes), olderThan)
+	for _, f := range report.Files {
+		fmt.Fprintf(&result, "%s  %s  %s\n", f.Modified.Format("2006-01-02 15:04"), stream.FormatSize(f.Size), f.Path)
+	}
+
+	return withWalkErrors(withBudgetNote(mcp.NewToolResultText(result.String()), budget), &walkErrs), nil
+}
+
+// parseAge parses a Go duration, additionally accepting whole days ("7d")
+// and weeks ("2w").
+func parseAge(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, fmt.Errorf("age is required")
+	}
+	var d time.Duration
+	var err error
+	switch unit := s[len(s)-1]; unit {
+	case 'd', 'w':
+		var n float64
+		n, err = strconv.ParseFloat(s[:len(s)-1], 64)
+		d = time.Duration(n * float64(24*time.Hour))
+		if unit == 'w' {
+			d *= 7
+		}
+	default:
+		d, err = time.ParseDuration(s)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("cannot parse %q as a duration", s)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("age must be positive")
+	}
+	return d, nil
+}
+
+// removeFile deletes a single file of size bytes, into the trash if it is
+// enabled, or through the overlay if one is active.
+func removeFile(reg *registry.Registry, resolvedPath string, size int64) error {
+	if err := reg.CheckWrite(resolvedPath); err != nil {
+		return err
+	}
+	if t := reg.Trash(); t != nil {
+		_, err := t.Put(containingRoot(reg, resolvedPath), resolvedPath, false, size)
+		return err
+	}
+	if ov := reg.Overlay(); ov != nil {
+		return ov.Remove(resolvedPath)
+	}
+	if err := ensureNoSymlink(resolvedPath); err != nil {
+		return err
+	}
+	return os.Remove(resolvedPath)
+}
+
+// moveToTrash moves file from under root into trashDir, keeping its path
+// relative to root, and returns the new location.
+func moveToTrash(reg *registry.Registry, root, trashDir, file string) (string, error) {
+	rel, err := filepath.Rel(root, file)
+	if err != nil {
+		return "", err
+	}
+	dest := filepath.Join(trashDir, rel)
+	if _, err := security.ValidateFinalPathForCreation(dest, reg.Get()); err != nil {
+		return "", err
+	}
+	for _, p := range []string{file, dest} {
+		if err := reg.CheckWrite(p); err != nil {
+			return "", err
+		}
+	}
+	if err := safeMkdirAll(filepath.Dir(dest), reg.Modes().Dir, reg.Get()); err != nil {
+		return "", err
+	}
+	if _, err := os.Lstat(dest); err == nil {
+		return "", fmt.Errorf("trash destination already exists: %s", dest)
+	}
+	if err := os.Rename(file, dest); err != nil {
+		return "", err
+	}
+	return dest, nil
+}
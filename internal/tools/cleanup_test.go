@@ -0,0 +1,160 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"36h", 36 * time.Hour, false},
+		{"7d", 7 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"1.5d", 36 * time.Hour, false},
+		{"", 0, true},
+		{"-1h", 0, true},
+		{"soon", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseAge(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAge(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseAge(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// setupCleanupTree creates old and fresh files under dir.
+func setupCleanupTree(t *testing.T, dir string) (old, fresh, oldLog string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(dir, "cache"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	old = filepath.Join(dir, "cache", "old.tmp")
+	fresh = filepath.Join(dir, "cache", "fresh.tmp")
+	oldLog = filepath.Join(dir, "old.log")
+	for _, p := range []string{old, fresh, oldLog} {
+		if err := os.WriteFile(p, []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	past := time.Now().Add(-10 * 24 * time.Hour)
+	for _, p := range []string{old, oldLog} {
+		if err := os.Chtimes(p, past, past); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return old, fresh, oldLog
+}
+
+func TestCleanupOldFiles(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	old, fresh, oldLog := setupCleanupTree(t, tmpDir)
+
+	args := map[string]any{"path": tmpDir, "olderThan": "7d", "patterns": []interface{}{"**/*.tmp"}, "dryRun": true}
+	result := callTool(t, HandleCleanupOldFiles, reg, args)
+	if result.IsError {
+		t.Fatalf("dry run failed: %s", resultText(result))
+	}
+	text := resultText(result)
+	if !strings.Contains(text, "would delete 1 files") || !strings.Contains(text, old) {
+		t.Errorf("unexpected dry run report: %s", text)
+	}
+	if _, err := os.Stat(old); err != nil {
+		t.Fatalf("dry run should not delete files: %v", err)
+	}
+
+	args["dryRun"] = false
+	result = callTool(t, HandleCleanupOldFiles, reg, args)
+	if result.IsError {
+		t.Fatalf("cleanup failed: %s", resultText(result))
+	}
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Errorf("old file should be deleted, got %v", err)
+	}
+	for _, p := range []string{fresh, oldLog} {
+		if _, err := os.Stat(p); err != nil {
+			t.Errorf("%s should be kept: %v", p, err)
+		}
+	}
+}
+
+func TestCleanupOldFilesTrash(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	work := filepath.Join(tmpDir, "work")
+	old, _, oldLog := setupCleanupTree(t, work)
+	trash := filepath.Join(tmpDir, "trash")
+
+	result := callTool(t, HandleCleanupOldFiles, reg, map[string]any{
+		"path":            work,
+		"olderThan":       "48h",
+		"excludePatterns": []interface{}{"*.log"},
+		"trashDir":        trash,
+	})
+	if result.IsError {
+		t.Fatalf("cleanup failed: %s", resultText(result))
+	}
+
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Errorf("old file should be moved, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(trash, "cache", "old.tmp")); err != nil {
+		t.Errorf("old file should be in trash: %v", err)
+	}
+	if _, err := os.Stat(oldLog); err != nil {
+		t.Errorf("excluded file should be kept: %v", err)
+	}
+
+	result = callTool(t, HandleCleanupOldFiles, reg, map[string]any{
+		"path":      work,
+		"olderThan": "1d",
+		"trashDir":  filepath.Join(work, "trash"),
+	})
+	if !result.IsError {
+		t.Error("expected trashDir inside the cleaned directory to be rejected")
+	}
+}
+
+func TestCleanupOldFilesSkipsGitAndStopsAtMaxFiles(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	old, _, _ := setupCleanupTree(t, tmpDir)
+	gitObject := filepath.Join(tmpDir, ".git", "objects", "ab")
+	if err := os.MkdirAll(filepath.Dir(gitObject), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(gitObject, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-10 * 24 * time.Hour)
+	if err := os.Chtimes(gitObject, past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	result := callTool(t, HandleCleanupOldFiles, reg, map[string]any{"path": tmpDir, "olderThan": "7d", "dryRun": true})
+	if result.IsError {
+		t.Fatalf("dry run failed: %s", resultText(result))
+	}
+	if text := resultText(result); strings.Contains(text, gitObject) || !strings.Contains(text, old) {
+		t.Errorf("expected .git to be skipped: %s", text)
+	}
+
+	result = callTool(t, HandleCleanupOldFiles, reg, map[string]any{"path": tmpDir, "olderThan": "7d", "dryRun": true, "maxFiles": 1})
+	if result.IsError {
+		t.Fatalf("dry run failed: %s", resultText(result))
+	}
+	if text := resultText(result); !strings.Contains(text, "raise maxFiles") {
+		t.Errorf("expected a file limit note: %s", text)
+	}
+}
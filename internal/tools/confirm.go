@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/confirm"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/spf13/cast"
+)
+
+// ConfirmableTools lists the tools that can be configured to require
+// confirmation, and the operation that triggers it.
+var ConfirmableTools = map[string]string{
+	"approve_changes":   "applying a proposal",
+	"copy_directory":    "overwriting existing files in the destination",
+	"copy_file":         "overwriting an existing destination",
+	"delete_directory":  "recursive deletion",
+	"empty_trash":       "permanently deleting trashed entries",
+	"replace_directory": "replacing an existing destination",
+	"undo_operation":    "reverting a recorded operation",
+}
+
+// withConfirmationToken declares the optional confirmation token argument
+// accepted by tools that can be gated.
+func withConfirmationToken() mcp.ToolOption {
+	return mcp.WithString(confirm.TokenParam, mcp.Description("Token returned when the server asked for confirmation of this exact call. Pass it only after the user has agreed to the operation. Only needed if the server is configured to confirm this operation."))
+}
+
+// requireConfirmation returns a result asking for confirmation when tool is
+// gated and the request does not carry a valid token for it, or nil if the
+// operation may proceed. action describes the operation to the user.
+func requireConfirmation(reg *registry.Registry, tool string, request mcp.CallToolRequest, action string) *mcp.CallToolResult {
+	gate := reg.Confirmations()
+	if !gate.Requires(tool) {
+		return nil
+	}
+
+	args := request.Params.Arguments
+	if gate.Confirm(cast.ToString(args[confirm.TokenParam]), tool, args) {
+		return nil
+	}
+
+	token, err := gate.Issue(tool, args)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to issue confirmation token: %w", err).Error())
+	}
+	return mcp.NewToolResultError(fmt.Sprintf(
+		"Confirmation required: %s. Nothing has been changed.\nShow this operation to the user and, only if they approve, repeat the identical call with %s=%q.",
+		action, confirm.TokenParam, token,
+	))
+}
+
+// withApprovalToken declares the optional out-of-band approval token
+// argument accepted by tools that can act on protected paths.
+func withApprovalToken() mcp.ToolOption {
+	return mcp.WithString(confirm.ApprovalParam, mcp.Description("Approval token a second person received out-of-band for this exact call. Only needed for operations in protected paths."))
+}
+
+// isProtected reports whether an operation on any of paths would affect a
+// protected subtree.
+func isProtected(reg *registry.Registry, paths ...string) bool {
+	guard := reg.Protection()
+	for _, p := range paths {
+		if guard.Covers(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// requireApproval enforces the two-person rule: it returns a result asking
+// for approval when an operation on paths affects a protected subtree and
+// the request does not carry valid confirmation and approval tokens for it,
+// or nil if the operation may proceed. The approval token goes to the second
+// person out-of-band and is never part of the result. action describes the
+// operation to both people.
+func requireApproval(reg *registry.Registry, tool string, request mcp.CallToolRequest, action string, paths ...string) *mcp.CallToolResult {
+	if !isProtected(reg, paths...) {
+		return nil
+	}
+	guard := reg.Protection()
+
+	args := request.Params.Arguments
+	if guard.Approve(tool, args) {
+		return nil
+	}
+
+	token, err := guard.Request(tool, action, paths, args)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to issue approval tokens: %w", err).Error())
+	}
+	return mcp.NewToolResultError(fmt.Sprintf(
+		"Two-person approval required: %s affects a protected path. Nothing has been changed.\nShow this operation to the user. An approval token has been sent to a second approver out-of-band. Only if the user approves and the approver provides that token, repeat the identical call with %s=%q and %s set to the approver's token.",
+		action, confirm.TokenParam, token, confirm.ApprovalParam,
+	))
+}
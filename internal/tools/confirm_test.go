@@ -0,0 +1,182 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/portertech/filesystem-mcp-server/internal/confirm"
+	"github.com/portertech/filesystem-mcp-server/internal/protect"
+)
+
+var confirmationTokenPattern = regexp.MustCompile(confirm.TokenParam + `="([0-9a-f]+)"`)
+
+func TestDeleteDirectoryRequiresConfirmation(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	reg.SetConfirmations(confirm.New([]string{"delete_directory"}, confirm.DefaultTTL))
+
+	dir := filepath.Join(tmpDir, "dir")
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	empty := filepath.Join(tmpDir, "empty")
+	if err := os.Mkdir(empty, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Non-recursive deletes are not gated
+	result := callTool(t, HandleDeleteDirectory, reg, map[string]any{"path": empty})
+	if result.IsError {
+		t.Fatalf("non-recursive delete should not need confirmation: %s", resultText(result))
+	}
+
+	args := map[string]any{"path": dir, "recursive": true}
+	result = callTool(t, HandleDeleteDirectory, reg, args)
+	if !result.IsError {
+		t.Fatal("expected recursive delete to require confirmation")
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Fatalf("directory should survive unconfirmed delete: %v", err)
+	}
+	match := confirmationTokenPattern.FindStringSubmatch(resultText(result))
+	if match == nil {
+		t.Fatalf("expected a confirmation token, got %s", resultText(result))
+	}
+
+	// A token does not carry over to a different call
+	other := filepath.Join(tmpDir, "other")
+	if err := os.Mkdir(other, 0755); err != nil {
+		t.Fatal(err)
+	}
+	result = callTool(t, HandleDeleteDirectory, reg, map[string]any{"path": other, "recursive": true, confirm.TokenParam: match[1]})
+	if !result.IsError {
+		t.Fatal("expected token for a different path to be rejected")
+	}
+
+	result = callTool(t, HandleDeleteDirectory, reg, args)
+	match = confirmationTokenPattern.FindStringSubmatch(resultText(result))
+	if match == nil {
+		t.Fatalf("expected a confirmation token, got %s", resultText(result))
+	}
+	result = callTool(t, HandleDeleteDirectory, reg, map[string]any{"path": dir, "recursive": true, confirm.TokenParam: match[1]})
+	if result.IsError {
+		t.Fatalf("confirmed delete failed: %s", resultText(result))
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("directory should be deleted after confirmation, got %v", err)
+	}
+}
+
+func TestCopyFileOverwriteRequiresConfirmation(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	reg.SetConfirmations(confirm.New([]string{"copy_file"}, confirm.DefaultTTL))
+
+	src := filepath.Join(tmpDir, "src.txt")
+	dst := filepath.Join(tmpDir, "dst.txt")
+	if err := os.WriteFile(src, []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := callTool(t, HandleCopyFile, reg, map[string]any{"source": src, "destination": dst})
+	if result.IsError {
+		t.Fatalf("copy to a new destination should not need confirmation: %s", resultText(result))
+	}
+	if err := os.WriteFile(dst, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	args := map[string]any{"source": src, "destination": dst, "overwrite": true}
+	result = callTool(t, HandleCopyFile, reg, args)
+	match := confirmationTokenPattern.FindStringSubmatch(resultText(result))
+	if !result.IsError || match == nil {
+		t.Fatalf("expected overwrite to require confirmation, got %s", resultText(result))
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "old" {
+		t.Errorf("destination should be untouched before confirmation, got %q", string(data))
+	}
+
+	args[confirm.TokenParam] = match[1]
+	result = callTool(t, HandleCopyFile, reg, args)
+	if result.IsError {
+		t.Fatalf("confirmed copy failed: %s", resultText(result))
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "new" {
+		t.Errorf("expected destination to be overwritten, got %q", string(data))
+	}
+}
+
+func TestProtectedDeleteRequiresApproval(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	vault := filepath.Join(tmpDir, "vault")
+	if err := os.Mkdir(vault, 0755); err != nil {
+		t.Fatal(err)
+	}
+	var approvals []string
+	guard, err := protect.New([]string{vault}, confirm.DefaultTTL, func(r protect.Request) {
+		approvals = append(approvals, r.Token)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg.SetProtection(guard)
+
+	outside := filepath.Join(tmpDir, "outside.txt")
+	file := filepath.Join(vault, "ledger.txt")
+	for _, p := range []string{outside, file} {
+		if err := os.WriteFile(p, []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result := callTool(t, HandleDeleteFile, reg, map[string]any{"path": outside})
+	if result.IsError {
+		t.Fatalf("delete outside protected paths should not need approval: %s", resultText(result))
+	}
+
+	result = callTool(t, HandleDeleteFile, reg, map[string]any{"path": file})
+	match := confirmationTokenPattern.FindStringSubmatch(resultText(result))
+	if !result.IsError || match == nil || len(approvals) != 1 {
+		t.Fatalf("expected delete to require approval, got %s", resultText(result))
+	}
+	if strings.Contains(resultText(result), approvals[0]) {
+		t.Fatal("approval token must not appear in the tool result")
+	}
+
+	// The confirmation token alone does not delete
+	result = callTool(t, HandleDeleteFile, reg, map[string]any{"path": file, confirm.TokenParam: match[1]})
+	if !result.IsError {
+		t.Fatal("expected delete with only the confirmation token to be refused")
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Fatalf("file should survive unapproved delete: %v", err)
+	}
+
+	match = confirmationTokenPattern.FindStringSubmatch(resultText(result))
+	if match == nil || len(approvals) != 2 {
+		t.Fatalf("expected a new token pair, got %s", resultText(result))
+	}
+	result = callTool(t, HandleDeleteFile, reg, map[string]any{"path": file, confirm.TokenParam: match[1], confirm.ApprovalParam: approvals[1]})
+	if result.IsError {
+		t.Fatalf("approved delete failed: %s", resultText(result))
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("file should be deleted after approval, got %v", err)
+	}
+
+	// Deleting a directory containing a protected subtree is covered too
+	parent := filepath.Join(tmpDir, "parent")
+	if err := os.MkdirAll(filepath.Join(parent, "vault"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	nested, err := protect.New([]string{filepath.Join(parent, "vault")}, confirm.DefaultTTL, func(protect.Request) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg.SetProtection(nested)
+	result = callTool(t, HandleDeleteDirectory, reg, map[string]any{"path": parent, "recursive": true})
+	if !result.IsError || !strings.Contains(resultText(result), "Two-person approval required") {
+		t.Fatal("expected delete of a protected subtree's parent to require approval")
+	}
+}
@@ -0,0 +1,118 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/convert"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/security"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/spf13/cast"
+)
+
+// NewConvertFileTool creates the convert_file tool.
+func NewConvertFileTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"convert_file",
+		mcp.WithDescription("Convert a structured data file between JSON, YAML, CSV, and TSV, writing the result atomically to a new path. Formats are inferred from the file extensions unless given. Tables convert to and from arrays of objects, with the first row as keys. CSV, TSV, and JSON input is streamed, so large files are not loaded into memory."),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Convert File",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(true),
+			IdempotentHint:  boolPtr(true),
+		}),
+		mcp.WithString("source", mcp.Description("Path to the file to convert"), mcp.Required()),
+		mcp.WithString("destination", mcp.Description("Path to write the converted file"), mcp.Required()),
+		mcp.WithString("from", mcp.Description("Source format: 'json', 'yaml', 'csv', or 'tsv' (default: from the source extension)")),
+		mcp.WithString("to", mcp.Description("Destination format: 'json', 'yaml', 'csv', or 'tsv' (default: from the destination extension)")),
+		mcp.WithBoolean("overwrite", mcp.Description("Replace the destination if it already exists (default: false)")),
+	)
+}
+
+// HandleConvertFile handles the convert_file tool.
+func HandleConvertFile(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	source := cast.ToString(request.Params.Arguments["source"])
+	destination := cast.ToString(request.Params.Arguments["destination"])
+	fromName := cast.ToString(request.Params.Arguments["from"])
+	toName := cast.ToString(request.Params.Arguments["to"])
+	overwrite := cast.ToBool(request.Params.Arguments["overwrite"])
+
+	from, err := formatArgument(fromName, source)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	to, err := formatArgument(toName, destination)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if from == to {
+		return mcp.NewToolResultError(fmt.Sprintf("source and destination are both %s; use copy_file instead", from)), nil
+	}
+
+	resolvedSrc, err := validateRead(reg, source)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("source path validation failed: %w", err).Error()), nil
+	}
+	if info, err := os.Stat(resolvedSrc); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to stat source: %w", err).Error()), nil
+	} else if info.IsDir() {
+		return mcp.NewToolResultError("source is a directory, not a file"), nil
+	}
+
+	resolvedDst, err := reg.ValidateForCreation(destination)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("destination path validation failed: %w", err).Error()), nil
+	}
+	if err := security.ValidateNoSymlinksInPath(reg.ExpandPath(destination), reg.Get()); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("destination path validation failed: %w", err).Error()), nil
+	}
+	if existing, err := readTarget(reg, resolvedDst); err == nil {
+		if _, err := os.Lstat(existing); err == nil {
+			if !overwrite {
+				return mcp.NewToolResultError("destination already exists, set overwrite=true to replace"), nil
+			}
+			if err := ensureNoSymlink(existing); err != nil {
+				return mcp.NewToolResultError(fmt.Errorf("destination path validation failed: %w", err).Error()), nil
+			}
+		}
+	}
+
+	target, allowedDirs, err := writeTarget(reg, resolvedDst)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to convert file: %w", err).Error()), nil
+	}
+	if _, err := security.ValidateFinalPathForCreation(target, allowedDirs); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("destination path validation failed: %w", err).Error()), nil
+	}
+
+	src, err := os.Open(resolvedSrc)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to open source: %w", err).Error()), nil
+	}
+	defer src.Close()
+
+	var records int
+	err = stream.WriteFileStreaming(target, reg.Modes().File, func(w io.Writer) error {
+		var err error
+		records, err = convert.Convert(src, w, from, to)
+		return err
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to convert %s to %s: %w", from, to, err).Error()), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Converted %s (%s) to %s (%s): %d records", resolvedSrc, from, resolvedDst, to, records)), nil
+}
+
+// formatArgument returns the format named by name, or inferred from path if
+// name is empty.
+func formatArgument(name, path string) (convert.Format, error) {
+	if name != "" {
+		return convert.ParseFormat(name)
+	}
+	return convert.FormatForPath(path)
+}
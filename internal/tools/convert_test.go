@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHandleConvertFile(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	src := filepath.Join(tmpDir, "hosts.csv")
+	if err := os.WriteFile(src, []byte("name,ip\ndb,10.0.0.2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(tmpDir, "hosts.json")
+
+	result := callTool(t, HandleConvertFile, reg, map[string]any{"source": src, "destination": dst})
+	if result.IsError {
+		t.Fatalf("convert_file failed: %s", resultText(result))
+	}
+	if !strings.Contains(resultText(result), "1 records") {
+		t.Errorf("expected record count in result, got %q", resultText(result))
+	}
+	data, _ := os.ReadFile(dst)
+	if want := "[\n  {\"name\": \"db\", \"ip\": \"10.0.0.2\"}\n]\n"; string(data) != want {
+		t.Errorf("got %q, want %q", string(data), want)
+	}
+
+	result = callTool(t, HandleConvertFile, reg, map[string]any{"source": src, "destination": dst})
+	if !result.IsError || !strings.Contains(resultText(result), "already exists") {
+		t.Errorf("expected existing destination to be refused, got %q", resultText(result))
+	}
+
+	// Explicit formats override extensions
+	out := filepath.Join(tmpDir, "hosts.out")
+	result = callTool(t, HandleConvertFile, reg, map[string]any{"source": dst, "destination": out, "to": "yaml"})
+	if result.IsError {
+		t.Fatalf("convert_file failed: %s", resultText(result))
+	}
+	data, _ = os.ReadFile(out)
+	if want := "- name: db\n  ip: 10.0.0.2\n"; string(data) != want {
+		t.Errorf("got %q, want %q", string(data), want)
+	}
+
+	// A failed conversion leaves nothing behind
+	bad := filepath.Join(tmpDir, "bad.json")
+	if err := os.WriteFile(bad, []byte(`{"not": "an array"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	failed := filepath.Join(tmpDir, "bad.csv")
+	result = callTool(t, HandleConvertFile, reg, map[string]any{"source": bad, "destination": failed})
+	if !result.IsError {
+		t.Error("expected conversion of a JSON object to CSV to fail")
+	}
+	entries, _ := os.ReadDir(tmpDir)
+	for _, e := range entries {
+		if e.Name() == "bad.csv" || strings.HasPrefix(e.Name(), ".tmp-") {
+			t.Errorf("unexpected leftover file %s", e.Name())
+		}
+	}
+
+	result = callTool(t, HandleConvertFile, reg, map[string]any{"source": src, "destination": filepath.Join(tmpDir, "notes.txt")})
+	if !result.IsError || !strings.Contains(resultText(result), "unsupported format") {
+		t.Errorf("expected unsupported format error, got %q", resultText(result))
+	}
+}
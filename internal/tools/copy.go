@@ -6,12 +6,17 @@ import (
 	"os"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/imaging"
 	"github.com/portertech/filesystem-mcp-server/internal/registry"
 	"github.com/portertech/filesystem-mcp-server/internal/security"
 	"github.com/portertech/filesystem-mcp-server/internal/stream"
 	"github.com/spf13/cast"
 )
 
+// maxStripSize caps the images copy_file strips metadata from, since they
+// are held in memory.
+const maxStripSize = 100 * 1024 * 1024
+
 // NewCopyFileTool creates the copy_file tool.
 func NewCopyFileTool(reg *registry.Registry) mcp.Tool {
 	return mcp.NewTool(
@@ -20,6 +25,8 @@ func NewCopyFileTool(reg *registry.Registry) mcp.Tool {
 		mcp.WithString("source", mcp.Description("Path to the source file"), mcp.Required()),
 		mcp.WithString("destination", mcp.Description("Path to the destination file"), mcp.Required()),
 		mcp.WithBoolean("overwrite", mcp.Description("If true, overwrite existing destination file")),
+		mcp.WithBoolean("stripMetadata", mcp.Description("If true, the source must be a JPEG, PNG, or WebP image, and the copy has its EXIF (including GPS location), XMP, IPTC, and comments removed. The pixels are not re-encoded")),
+		withConfirmationToken(),
 		mcp.WithToolAnnotation(mcp.ToolAnnotation{
 			Title:           "Copy File",
 			ReadOnlyHint:    boolPtr(false),
@@ -34,9 +41,10 @@ func HandleCopyFile(ctx context.Context, reg *registry.Registry, request mcp.Cal
 	source := cast.ToString(request.Params.Arguments["source"])
 	destination := cast.ToString(request.Params.Arguments["destination"])
 	overwrite := cast.ToBool(request.Params.Arguments["overwrite"])
+	stripMetadata := cast.ToBool(request.Params.Arguments["stripMetadata"])
 
 	// Validate source path
-	resolvedSrc, err := reg.Validate(source)
+	resolvedSrc, err := validateRead(reg, source)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Errorf("source path validation failed: %w", err).Error()), nil
 	}
@@ -49,6 +57,9 @@ func HandleCopyFile(ctx context.Context, reg *registry.Registry, request mcp.Cal
 	if srcInfo.IsDir() {
 		return mcp.NewToolResultError("source is a directory, not a file"), nil
 	}
+	if stripMetadata && srcInfo.Size() > maxStripSize {
+		return mcp.NewToolResultError(fmt.Sprintf("source is %s; metadata can only be stripped from images up to %s", stream.FormatSize(srcInfo.Size()), stream.FormatSize(maxStripSize))), nil
+	}
 
 	// Validate destination path
 	resolvedDst, err := reg.ValidateForCreation(destination)
@@ -56,24 +67,65 @@ func HandleCopyFile(ctx context.Context, reg *registry.Registry, request mcp.Cal
 		return mcp.NewToolResultError(fmt.Errorf("destination path validation failed: %w", err).Error()), nil
 	}
 
-	if err := security.ValidateNoSymlinksInPath(destination, reg.Get()); err != nil {
+	if err := security.ValidateNoSymlinksInPath(reg.ExpandPath(destination), reg.Get()); err != nil {
 		return mcp.NewToolResultError(fmt.Errorf("destination path validation failed: %w", err).Error()), nil
 	}
 
 	// Check if destination exists
-	if _, err := os.Lstat(resolvedDst); err == nil {
-		if !overwrite {
-			return mcp.NewToolResultError("destination already exists, set overwrite=true to replace"), nil
-		}
-		if err := ensureNoSymlink(resolvedDst); err != nil {
-			return mcp.NewToolResultError(fmt.Errorf("destination path validation failed: %w", err).Error()), nil
+	if existing, err := readTarget(reg, resolvedDst); err == nil {
+		if _, err := os.Lstat(existing); err == nil {
+			if !overwrite {
+				return mcp.NewToolResultError("destination already exists, set overwrite=true to replace"), nil
+			}
+			if err := ensureNoSymlink(existing); err != nil {
+				return mcp.NewToolResultError(fmt.Errorf("destination path validation failed: %w", err).Error()), nil
+			}
+			if result := requireConfirmation(reg, "copy_file", request, fmt.Sprintf("overwrite %s with a copy of %s", resolvedDst, resolvedSrc)); result != nil {
+				return result, nil
+			}
 		}
 	}
 
+	target, allowedDirs, err := writeTarget(reg, resolvedDst)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to copy file: %w", err).Error()), nil
+	}
+
+	if stripMetadata {
+		return copyStripped(ctx, reg, resolvedSrc, resolvedDst, target, srcInfo, allowedDirs)
+	}
+
 	// Copy the file
-	if err := stream.CopyFileStreaming(resolvedSrc, resolvedDst); err != nil {
+	if err := stream.CopyFileStreaming(resolvedSrc, target); err != nil {
 		return mcp.NewToolResultError(fmt.Errorf("failed to copy file: %w", err).Error()), nil
 	}
 
 	return mcp.NewToolResultText(fmt.Sprintf("Successfully copied %s to %s", resolvedSrc, resolvedDst)), nil
 }
+
+// copyStripped copies the image at src to target with its metadata removed.
+func copyStripped(ctx context.Context, reg *registry.Registry, src, dst, target string, srcInfo os.FileInfo, allowedDirs []string) (*mcp.CallToolResult, error) {
+	release, err := reserveMemory(ctx, reg, src, 2*srcInfo.Size())
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	defer release()
+
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to copy file: %w", err).Error()), nil
+	}
+	stripped, removed, err := imaging.StripMetadata(data)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to strip metadata: %w", err).Error()), nil
+	}
+	if err := atomicWriteFile(target, stripped, srcInfo.Mode().Perm(), allowedDirs); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to copy file: %w", err).Error()), nil
+	}
+
+	note := "no metadata found"
+	if removed {
+		note = fmt.Sprintf("removed %s of metadata", stream.FormatSize(int64(len(data)-len(stripped))))
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Successfully copied %s to %s (%s)", src, dst, note)), nil
+}
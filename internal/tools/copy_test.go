@@ -1,9 +1,13 @@
 package tools
 
 import (
+	"bytes"
 	"context"
+	"image"
+	"image/jpeg"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -143,3 +147,41 @@ func TestHandleCopyFile(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleCopyFileStripMetadata(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+
+	var plain bytes.Buffer
+	if err := jpeg.Encode(&plain, image.NewGray(image.Rect(0, 0, 8, 8)), nil); err != nil {
+		t.Fatal(err)
+	}
+	exif := "Exif\x00\x00GPS 51.5N"
+	tagged := append([]byte{0xFF, 0xD8, 0xFF, 0xE1, 0, byte(len(exif) + 2)}, exif...)
+	tagged = append(tagged, plain.Bytes()[2:]...)
+	src := filepath.Join(tmpDir, "photo.jpg")
+	if err := os.WriteFile(src, tagged, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(tmpDir, "shared.jpg")
+	result := callTool(t, HandleCopyFile, reg, map[string]any{"source": src, "destination": dst, "stripMetadata": true})
+	if result.IsError || !strings.Contains(resultText(result), "removed") {
+		t.Fatalf("unexpected result: %s", resultText(result))
+	}
+	got, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, plain.Bytes()) {
+		t.Errorf("copy still holds metadata: %d bytes, want %d", len(got), plain.Len())
+	}
+
+	text := filepath.Join(tmpDir, "notes.txt")
+	if err := os.WriteFile(text, []byte("notes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result = callTool(t, HandleCopyFile, reg, map[string]any{"source": text, "destination": filepath.Join(tmpDir, "notes2.txt"), "stripMetadata": true})
+	if !result.IsError {
+		t.Error("expected stripping a non-image to fail")
+	}
+}
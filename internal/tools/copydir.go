@@ -0,0 +1,271 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/security"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/spf13/cast"
+)
+
+// copyEntry is a directory or file copy_directory will create.
+type copyEntry struct {
+	src  string
+	dst  string
+	mode fs.FileMode
+	// exists is set for a destination that is already there.
+	exists bool
+}
+
+// copyPlan is everything copy_directory will do, worked out before
+// anything is written.
+type copyPlan struct {
+	// dirs are in walk order, so parents come before their children.
+	dirs    []copyEntry
+	files   []copyEntry
+	bytes   int64
+	skipped []string
+}
+
+// NewCopyDirectoryTool creates the copy_directory tool.
+func NewCopyDirectoryTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"copy_directory",
+		mcp.WithDescription("Recursively copy a directory to a new location, preserving permissions. Files are streamed, so large files are not loaded into memory. Symlinks are skipped or refused, never followed."),
+		mcp.WithString("source", mcp.Description("Path to the directory to copy"), mcp.Required()),
+		mcp.WithString("destination", mcp.Description("Path of the copy. Missing parent directories are created"), mcp.Required()),
+		mcp.WithBoolean("overwrite", mcp.Description("If true, copy into an existing destination directory, replacing files that exist in both")),
+		mcp.WithArray("excludePatterns", mcp.Description("Glob patterns, relative to source, of files and directories not to copy"), mcp.Items(map[string]any{"type": "string"})),
+		mcp.WithString("symlinks", mcp.Description("What to do with symlinks in the source: 'skip' (default) leaves them out and lists them, 'fail' refuses the copy before anything is written")),
+		withMaxFilesParam(),
+		withConfirmationToken(),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Copy Directory",
+			ReadOnlyHint:    boolPtr(false),
+			IdempotentHint:  boolPtr(false),
+			DestructiveHint: boolPtr(true),
+		}),
+	)
+}
+
+// HandleCopyDirectory handles the copy_directory tool.
+func HandleCopyDirectory(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	source := cast.ToString(request.Params.Arguments["source"])
+	destination := cast.ToString(request.Params.Arguments["destination"])
+	overwrite := cast.ToBool(request.Params.Arguments["overwrite"])
+	symlinks := cast.ToString(request.Params.Arguments["symlinks"])
+	if symlinks == "" {
+		symlinks = "skip"
+	}
+	if symlinks != "skip" && symlinks != "fail" {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid symlinks %q: expected skip or fail", symlinks)), nil
+	}
+	var excludeGlobs []glob.Glob
+	for _, p := range cast.ToStringSlice(request.Params.Arguments["excludePatterns"]) {
+		globs, err := compileGlobs(p)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid exclude pattern %q: %v", p, err)), nil
+		}
+		excludeGlobs = append(excludeGlobs, globs...)
+	}
+	budget, err := parseMaxFiles(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// Like directory_tree, this walks the real tree even in overlay mode
+	resolvedSrc, err := reg.Validate(source)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("source path validation failed: %w", err).Error()), nil
+	}
+	srcInfo, err := os.Stat(resolvedSrc)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to stat source: %w", err).Error()), nil
+	}
+	if !srcInfo.IsDir() {
+		return mcp.NewToolResultError("source is not a directory, use copy_file instead"), nil
+	}
+
+	resolvedDst, err := reg.ValidateForCreation(destination)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("destination path validation failed: %w", err).Error()), nil
+	}
+	if err := security.ValidateNoSymlinksInPath(reg.ExpandPath(destination), reg.Get()); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("destination path validation failed: %w", err).Error()), nil
+	}
+	if resolvedDst == resolvedSrc || strings.HasPrefix(resolvedDst, resolvedSrc+string(filepath.Separator)) {
+		return mcp.NewToolResultError("destination is inside the source directory"), nil
+	}
+
+	dstInfo, err := statTarget(reg, resolvedDst)
+	dstExists := err == nil
+	if dstExists {
+		if !dstInfo.IsDir() {
+			return mcp.NewToolResultError("destination exists and is not a directory"), nil
+		}
+		if !overwrite {
+			return mcp.NewToolResultError("destination already exists, set overwrite=true to copy into it"), nil
+		}
+	}
+
+	plan, err := planDirectoryCopy(ctx, reg, resolvedSrc, resolvedDst, excludeGlobs, symlinks == "fail", budget)
+	if err != nil {
+		if errors.Is(err, errMaxFiles) {
+			return mcp.NewToolResultError(fmt.Sprintf("source has more than %d files and directories; raise maxFiles to copy it", budget.max)), nil
+		}
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	replaced := 0
+	for _, f := range plan.files {
+		if f.exists {
+			replaced++
+		}
+	}
+	if replaced > 0 {
+		action := fmt.Sprintf("overwrite %d files in %s with copies from %s", replaced, resolvedDst, resolvedSrc)
+		if result := requireConfirmation(reg, "copy_directory", request, action); result != nil {
+			return result, nil
+		}
+	}
+
+	root := copyEntry{src: resolvedSrc, dst: resolvedDst, mode: srcInfo.Mode(), exists: dstExists}
+	if err := copyDirectoryTree(reg, root, plan); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to copy directory: %w", err).Error()), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Successfully copied %s to %s: %d files, %d directories, %s", resolvedSrc, resolvedDst, len(plan.files), len(plan.dirs)+1, stream.FormatSize(plan.bytes))
+	if replaced > 0 {
+		fmt.Fprintf(&b, "; %d files replaced", replaced)
+	}
+	if len(plan.skipped) > 0 {
+		fmt.Fprintf(&b, "\n\nSkipped %d symlinks and special files:\n%s\n", len(plan.skipped), strings.Join(plan.skipped, "\n"))
+	}
+	return mcp.NewToolResultText(b.String()), nil
+}
+
+// planDirectoryCopy walks src and pairs each directory and regular file
+// with its place under dst, checking what is already there. Symlinks and
+// special files are skipped, or refused if failOnSymlink is set and the
+// entry is a symlink.
+func planDirectoryCopy(ctx context.Context, reg *registry.Registry, src, dst string, excludeGlobs []glob.Glob, failOnSymlink bool, budget *fileBudget) (*copyPlan, error) {
+	plan := &copyPlan{}
+	err := filepath.WalkDir(src, func(walkPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to read source: %w", err)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if walkPath == src {
+			return nil
+		}
+		if !budget.take() {
+			return errMaxFiles
+		}
+		relPath, err := filepath.Rel(src, walkPath)
+		if err != nil {
+			return err
+		}
+		if matchesAny(excludeGlobs, filepath.ToSlash(relPath)) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if entry.Type()&os.ModeSymlink != 0 {
+			if failOnSymlink {
+				return fmt.Errorf("source contains a symlink: %s", walkPath)
+			}
+			plan.skipped = append(plan.skipped, walkPath)
+			return nil
+		}
+		if !entry.IsDir() && !entry.Type().IsRegular() {
+			plan.skipped = append(plan.skipped, walkPath)
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return fmt.Errorf("failed to read source: %w", err)
+		}
+
+		c := copyEntry{src: walkPath, dst: filepath.Join(dst, relPath), mode: info.Mode()}
+		existing, err := readTarget(reg, c.dst)
+		if err == nil {
+			if dstInfo, err := os.Lstat(existing); err == nil {
+				switch {
+				case dstInfo.Mode()&os.ModeSymlink != 0:
+					return fmt.Errorf("destination contains a symlink: %s", c.dst)
+				case dstInfo.IsDir() != entry.IsDir():
+					return fmt.Errorf("destination has a different kind of entry at %s", c.dst)
+				}
+				c.exists = true
+			}
+		}
+		if entry.IsDir() {
+			plan.dirs = append(plan.dirs, c)
+		} else {
+			plan.files = append(plan.files, c)
+			plan.bytes += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// copyDirectoryTree creates root and the directories of plan, copies its
+// files, and then gives each new directory its source's permissions. The
+// permissions are set last so that read-only directories can be filled.
+func copyDirectoryTree(reg *registry.Registry, root copyEntry, plan *copyPlan) error {
+	dirs := append([]copyEntry{root}, plan.dirs...)
+	var created []copyEntry
+	for _, d := range dirs {
+		if err := reg.CheckWrite(d.dst); err != nil {
+			return err
+		}
+		if d.exists {
+			continue
+		}
+		target := d.dst
+		if ov := reg.Overlay(); ov != nil {
+			var err error
+			if target, err = ov.MkdirAll(d.dst); err != nil {
+				return err
+			}
+		} else if err := safeMkdirAll(d.dst, reg.Modes().Dir, reg.Get()); err != nil {
+			return err
+		}
+		created = append(created, copyEntry{dst: target, mode: d.mode})
+	}
+
+	for i, f := range plan.files {
+		target, _, err := writeTarget(reg, f.dst)
+		if err != nil {
+			return fmt.Errorf("%s (after copying %d of %d files): %w", f.dst, i, len(plan.files), err)
+		}
+		if err := stream.CopyFileStreaming(f.src, target); err != nil {
+			return fmt.Errorf("%s (after copying %d of %d files): %w", f.dst, i, len(plan.files), err)
+		}
+	}
+
+	for i := len(created) - 1; i >= 0; i-- {
+		if err := os.Chmod(created[i].dst, created[i].mode.Perm()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/portertech/filesystem-mcp-server/internal/confirm"
+)
+
+func TestHandleCopyDirectory(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	src := filepath.Join(tmpDir, "src")
+	os.MkdirAll(filepath.Join(src, "sub", "node_modules"), 0755)
+	os.WriteFile(filepath.Join(src, "a.txt"), []byte("a"), 0644)
+	os.WriteFile(filepath.Join(src, "run.sh"), []byte("#!/bin/sh\n"), 0755)
+	os.WriteFile(filepath.Join(src, "sub", "b.txt"), []byte("b"), 0600)
+	os.WriteFile(filepath.Join(src, "sub", "node_modules", "dep.js"), []byte("x"), 0644)
+	os.Chmod(filepath.Join(src, "sub"), 0700)
+	os.Symlink("a.txt", filepath.Join(src, "link"))
+
+	dst := filepath.Join(tmpDir, "out", "copy")
+	result := callTool(t, HandleCopyDirectory, reg, map[string]any{"source": src, "destination": dst, "excludePatterns": []any{"**/node_modules"}})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	text := resultText(result)
+	if !strings.Contains(text, "3 files, 2 directories") || !strings.Contains(text, filepath.Join(src, "link")) {
+		t.Errorf("unexpected result:\n%s", text)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dst, "sub", "b.txt")); string(data) != "b" {
+		t.Errorf("sub/b.txt = %q", data)
+	}
+	for name, want := range map[string]os.FileMode{"run.sh": 0755, "sub/b.txt": 0600, "sub": 0700} {
+		if info, err := os.Stat(filepath.Join(dst, name)); err != nil || info.Mode().Perm() != want {
+			t.Errorf("%s: expected mode %o, got %v (%v)", name, want, info, err)
+		}
+	}
+	if _, err := os.Lstat(filepath.Join(dst, "link")); !os.IsNotExist(err) {
+		t.Error("expected the symlink to be skipped")
+	}
+	if _, err := os.Stat(filepath.Join(dst, "sub", "node_modules")); !os.IsNotExist(err) {
+		t.Error("expected node_modules to be excluded")
+	}
+
+	for name, args := range map[string]map[string]any{
+		"existing destination": {"source": src, "destination": dst},
+		"symlinks fail":        {"source": src, "destination": filepath.Join(tmpDir, "other"), "symlinks": "fail"},
+		"into itself":          {"source": src, "destination": filepath.Join(src, "sub", "copy")},
+		"file source":          {"source": filepath.Join(src, "a.txt"), "destination": filepath.Join(tmpDir, "x")},
+		"too many files":       {"source": src, "destination": filepath.Join(tmpDir, "other"), "maxFiles": 2},
+	} {
+		if result := callTool(t, HandleCopyDirectory, reg, args); !result.IsError {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "other")); !os.IsNotExist(err) {
+		t.Error("expected a refused copy to write nothing")
+	}
+}
+
+func TestCopyDirectoryOverwriteRequiresConfirmation(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	reg.SetConfirmations(confirm.New([]string{"copy_directory"}, confirm.DefaultTTL))
+	src := filepath.Join(tmpDir, "src")
+	dst := filepath.Join(tmpDir, "dst")
+	os.MkdirAll(src, 0755)
+	os.MkdirAll(dst, 0755)
+	os.WriteFile(filepath.Join(src, "a.txt"), []byte("new"), 0644)
+	os.WriteFile(filepath.Join(src, "b.txt"), []byte("b"), 0644)
+	os.WriteFile(filepath.Join(dst, "a.txt"), []byte("old"), 0644)
+	os.WriteFile(filepath.Join(dst, "keep.txt"), []byte("keep"), 0644)
+
+	args := map[string]any{"source": src, "destination": dst, "overwrite": true}
+	result := callTool(t, HandleCopyDirectory, reg, args)
+	match := confirmationTokenPattern.FindStringSubmatch(resultText(result))
+	if !result.IsError || match == nil {
+		t.Fatalf("expected overwrite to require confirmation, got %s", resultText(result))
+	}
+
+	args[confirm.TokenParam] = match[1]
+	result = callTool(t, HandleCopyDirectory, reg, args)
+	if result.IsError {
+		t.Fatalf("confirmed copy failed: %s", resultText(result))
+	}
+	for name, want := range map[string]string{"a.txt": "new", "b.txt": "b", "keep.txt": "keep"} {
+		if data, _ := os.ReadFile(filepath.Join(dst, name)); string(data) != want {
+			t.Errorf("%s = %q, want %q", name, data, want)
+		}
+	}
+}
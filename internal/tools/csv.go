@@ -0,0 +1,26 @@
+package tools
+
+import (
+	"encoding/csv"
+	"strings"
+)
+
+// formatCSV renders rows as CSV with a header row. Cells holding names or
+// paths should go through csvText first.
+func formatCSV(header []string, rows [][]string) string {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	w.Write(header)
+	w.WriteAll(rows) // writes to a strings.Builder cannot fail
+	return b.String()
+}
+
+// csvText guards a text cell against formula injection: spreadsheets run
+// cells starting with =, +, -, or @ as formulas, so such cells get a leading
+// apostrophe, which spreadsheets hide.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/portertech/filesystem-mcp-server/internal/usage"
+)
+
+func TestCSVText(t *testing.T) {
+	for in, want := range map[string]string{
+		"report.txt":        "report.txt",
+		"=HYPERLINK(\"x\")": "'=HYPERLINK(\"x\")",
+		"+1":                "'+1",
+		"-rf":               "'-rf",
+		"@SUM(A1)":          "'@SUM(A1)",
+		"":                  "",
+	} {
+		if got := csvText(in); got != want {
+			t.Errorf("csvText(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCSVFormats(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	if err := os.WriteFile(filepath.Join(tmpDir, "a, b.txt"), []byte("12345"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "=cmd.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(tmpDir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	parse := func(t *testing.T, text string) [][]string {
+		t.Helper()
+		records, err := csv.NewReader(strings.NewReader(text)).ReadAll()
+		if err != nil {
+			t.Fatalf("invalid CSV %q: %v", text, err)
+		}
+		return records
+	}
+
+	records := parse(t, resultText(callTool(t, HandleListDirectoryWithSizes, reg, map[string]any{"path": tmpDir, "format": "csv"})))
+	if !reflect.DeepEqual(records[0], []string{"name", "type", "size", "modified"}) || len(records) != 4 {
+		t.Fatalf("unexpected listing: %v", records)
+	}
+	if got := records[1][:3]; !reflect.DeepEqual(got, []string{"'=cmd.txt", "file", "1"}) {
+		t.Errorf("expected formula cell to be guarded, got %v", got)
+	}
+	if got := records[2][:3]; !reflect.DeepEqual(got, []string{"a, b.txt", "file", "5"}) {
+		t.Errorf("unexpected row %v", got)
+	}
+	if got := records[3][:2]; !reflect.DeepEqual(got, []string{"sub", "directory"}) {
+		t.Errorf("unexpected row %v", got)
+	}
+
+	records = parse(t, resultText(callTool(t, HandleListDirectory, reg, map[string]any{"path": tmpDir, "format": "csv"})))
+	if len(records) != 4 || records[3][1] != "directory" {
+		t.Errorf("unexpected listing: %v", records)
+	}
+
+	records = parse(t, resultText(callTool(t, HandleHashFile, reg, map[string]any{"path": filepath.Join(tmpDir, "a, b.txt"), "format": "csv"})))
+	if len(records) != 2 || records[1][1] != "5" || records[1][4] != "5994471abb01112afcc18159f6cc74b4f511b99806da59b3caf5a9c173cacfc5" {
+		t.Errorf("unexpected hash rows: %v", records)
+	}
+
+	records = parse(t, resultText(callTool(t, HandleFindLargestFiles, reg, map[string]any{"path": tmpDir, "limit": 1, "format": "csv"})))
+	if len(records) != 2 || records[1][0] != filepath.Join(tmpDir, "a, b.txt") || records[1][1] != "5" {
+		t.Errorf("unexpected largest rows: %v", records)
+	}
+}
+
+func TestUsageCSV(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	trend := summarizeUsage("/root", []usage.Sample{
+		{Time: start, Bytes: 1000, Children: map[string]int64{"logs": 400}},
+		{Time: start.Add(24 * time.Hour), Bytes: 1500, Children: map[string]int64{"logs": 900}},
+	})
+	want := "root,entry,samples,from,to,startBytes,endBytes,change,changePerDay\n" +
+		"/root,,2,2024-01-01T00:00:00Z,2024-01-02T00:00:00Z,1000,1500,500,500\n" +
+		"/root,logs,,,,,900,500,\n" +
+		"/empty,,0,,,,,,\n"
+	if got := usageCSV([]usageTrend{trend, {Root: "/empty"}}); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,186 @@
+package tools
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/convert"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/spf13/cast"
+)
+
+const (
+	defaultPreviewRows = 5
+	maxPreviewRows     = 100
+	// maxPreviewCell caps the characters of a cell csv_preview returns.
+	maxPreviewCell = 200
+	// previewMemory is about what csv_preview buffers: the sampled rows
+	// and the window read from the end of the file.
+	previewMemory = 2 * 1024 * 1024
+)
+
+// NewCSVPreviewTool creates the csv_preview tool.
+func NewCSVPreviewTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"csv_preview",
+		mcp.WithDescription(fmt.Sprintf("Preview a CSV or TSV file without reading all of it: the first and last rows, the column names and inferred types (integer, number, boolean, date, datetime, or string), and the row count, estimated from the first %d rows unless exact is set. Use it instead of read_text_file to get to know a data file.", convert.PreviewSampleRows)),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path", mcp.Description("Path to the CSV or TSV file"), mcp.Required()),
+		mcp.WithNumber("head", mcp.Description(fmt.Sprintf("Number of rows to return from the start (default: %d, max: %d)", defaultPreviewRows, maxPreviewRows))),
+		mcp.WithNumber("tail", mcp.Description(fmt.Sprintf("Number of rows to return from the end (default: %d, max: %d)", defaultPreviewRows, maxPreviewRows))),
+		mcp.WithString("delimiter", mcp.Description("Field delimiter: a single character, or 'tab' (default: tab for .tsv files, otherwise detected from the first line)")),
+		mcp.WithBoolean("header", mcp.Description("Whether the first row holds column names (default: inferred; a first row of only text is taken as a header)")),
+		mcp.WithBoolean("exact", mcp.Description("Count every row instead of estimating, reading through the whole file (default: false)")),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleCSVPreview handles the csv_preview tool.
+func HandleCSVPreview(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	opts := convert.PreviewOptions{Head: defaultPreviewRows, Tail: defaultPreviewRows}
+	if v, ok := request.Params.Arguments["head"]; ok {
+		opts.Head = cast.ToInt(v)
+	}
+	if v, ok := request.Params.Arguments["tail"]; ok {
+		opts.Tail = cast.ToInt(v)
+	}
+	if opts.Head < 0 || opts.Head > maxPreviewRows || opts.Tail < 0 || opts.Tail > maxPreviewRows {
+		return mcp.NewToolResultError(fmt.Sprintf("head and tail must be between 0 and %d", maxPreviewRows)), nil
+	}
+	if v, ok := request.Params.Arguments["header"]; ok {
+		header := cast.ToBool(v)
+		opts.Header = &header
+	}
+	opts.Exact = cast.ToBool(request.Params.Arguments["exact"])
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	switch delim := cast.ToString(request.Params.Arguments["delimiter"]); {
+	case delim == "tab" || delim == `\t`:
+		opts.Delimiter = '\t'
+	case delim != "":
+		r, size := utf8.DecodeRuneInString(delim)
+		if size != len(delim) || r == '"' || r == '\r' || r == '\n' || r == utf8.RuneError {
+			return mcp.NewToolResultError(fmt.Sprintf("delimiter must be a single character other than a quote or line break, or 'tab': %q", delim)), nil
+		}
+		opts.Delimiter = r
+	case strings.EqualFold(filepath.Ext(path), ".tsv"):
+		opts.Delimiter = '\t'
+	}
+
+	resolvedPath, err := validateRead(reg, path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	f, err := os.Open(resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to open file: %w", err).Error()), nil
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to stat file: %w", err).Error()), nil
+	}
+	if info.IsDir() {
+		return mcp.NewToolResultError("path is a directory, not a file"), nil
+	}
+	match, err := checkReputation(reg, resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	release, err := reserveMemory(ctx, reg, resolvedPath, min(info.Size(), previewMemory))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	defer release()
+
+	p, err := convert.PreviewTable(ctx, f, info.Size(), opts)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to preview %s: %w", path, err).Error()), nil
+	}
+	for _, rows := range [][][]string{p.Head, p.Tail} {
+		for _, row := range rows {
+			for i, cell := range row {
+				row[i] = truncateText(cell, maxPreviewCell)
+			}
+		}
+	}
+
+	if format == "json" {
+		data, err := json.MarshalIndent(p, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return withReputationWarning(mcp.NewToolResultText(string(data)), match), nil
+	}
+	return withReputationWarning(mcp.NewToolResultText(formatPreview(path, info.Size(), p)), match), nil
+}
+
+// formatPreview renders a table preview as text, with rows in the table's
+// own delimiter under a line of column names.
+func formatPreview(path string, size int64, p *convert.Preview) string {
+	var text strings.Builder
+	rows := fmt.Sprintf("%d rows", p.Rows)
+	if p.Estimated {
+		rows = fmt.Sprintf("about %d rows (estimated from the first %d)", p.Rows, p.Sampled)
+	}
+	delim := p.Delimiter
+	if delim == "\t" {
+		delim = "tab"
+	}
+	headerNote := "no header row"
+	if p.Header {
+		headerNote = "header row"
+	}
+	fmt.Fprintf(&text, "%s: %s, %d columns, %s, delimiter %q, %s\n", path, rows, len(p.Columns), headerNote, delim, stream.FormatSize(size))
+	if len(p.Columns) == 0 {
+		return strings.TrimSuffix(text.String(), "\n")
+	}
+
+	text.WriteString("\nColumns:\n")
+	names := make([]string, len(p.Columns))
+	for i, c := range p.Columns {
+		names[i] = c.Name
+		fmt.Fprintf(&text, "  %s: %s", c.Name, c.Type)
+		if c.Empty > 0 && c.Type != convert.TypeEmpty {
+			fmt.Fprintf(&text, " (%d of %d sampled values empty)", c.Empty, p.Sampled)
+		}
+		text.WriteString("\n")
+	}
+
+	writeRows := func(title string, rows [][]string) {
+		fmt.Fprintf(&text, "\n%s:\n", title)
+		w := csv.NewWriter(&text)
+		w.Comma, _ = utf8.DecodeRuneInString(p.Delimiter)
+		w.Write(names)
+		w.WriteAll(rows) // writes to a strings.Builder cannot fail
+	}
+	if len(p.Head) > 0 {
+		writeRows(fmt.Sprintf("First %d rows", len(p.Head)), p.Head)
+	}
+	// With no more rows than the head shows, the tail repeats them
+	if len(p.Tail) > 0 && (p.Estimated || p.Rows > int64(len(p.Head))) {
+		title := fmt.Sprintf("Last %d rows", len(p.Tail))
+		if p.TailStart > 0 {
+			title += fmt.Sprintf(" (rows %d-%d)", p.TailStart, p.TailStart+int64(len(p.Tail))-1)
+		}
+		writeRows(title, p.Tail)
+	}
+	return strings.TrimSuffix(text.String(), "\n")
+}
+
+// truncateText shortens s to n characters.
+func truncateText(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n]) + "..."
+}
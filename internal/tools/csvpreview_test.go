@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/portertech/filesystem-mcp-server/internal/convert"
+)
+
+func TestHandleCSVPreview(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	path := filepath.Join(tmpDir, "data.tsv")
+	var b strings.Builder
+	b.WriteString("id\tname\tprice\n")
+	for i := 1; i <= 20; i++ {
+		fmt.Fprintf(&b, "%d\titem %d\t%d.5\n", i, i, i)
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	text := resultText(callTool(t, HandleCSVPreview, reg, map[string]any{"path": path, "head": 2, "tail": 1}))
+	for _, want := range []string{
+		"20 rows, 3 columns, header row, delimiter \"tab\"",
+		"  id: integer\n  name: string\n  price: number\n",
+		"First 2 rows:\nid\tname\tprice\n1\titem 1\t1.5\n2\titem 2\t2.5\n",
+		"Last 1 rows (rows 20-20):\nid\tname\tprice\n20\titem 20\t20.5",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+
+	text = resultText(callTool(t, HandleCSVPreview, reg, map[string]any{"path": path, "delimiter": ",", "header": false, "format": "json"}))
+	var p convert.Preview
+	if err := json.Unmarshal([]byte(text), &p); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, text)
+	}
+	if p.Header || p.Rows != 21 || len(p.Columns) != 1 || p.Head[0][0] != "id\tname\tprice" {
+		t.Errorf("unexpected preview %+v", p)
+	}
+
+	for _, args := range []map[string]any{
+		{"path": path, "head": 101},
+		{"path": path, "delimiter": "ab"},
+		{"path": tmpDir},
+	} {
+		if result := callTool(t, HandleCSVPreview, reg, args); !result.IsError {
+			t.Errorf("expected error for %v", args)
+		}
+	}
+}
@@ -2,14 +2,17 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/journal"
 	"github.com/portertech/filesystem-mcp-server/internal/registry"
 	"github.com/portertech/filesystem-mcp-server/internal/security"
+	"github.com/portertech/filesystem-mcp-server/internal/trash"
 	"github.com/spf13/cast"
 )
 
@@ -19,6 +22,9 @@ func NewDeleteFileTool(reg *registry.Registry) mcp.Tool {
 		"delete_file",
 		mcp.WithDescription("Delete a file. Cannot delete directories (use delete_directory instead)."),
 		mcp.WithString("path", mcp.Description("Path to the file to delete"), mcp.Required()),
+		withForce(),
+		withConfirmationToken(),
+		withApprovalToken(),
 		mcp.WithToolAnnotation(mcp.ToolAnnotation{
 			Title:           "Delete File",
 			ReadOnlyHint:    boolPtr(false),
@@ -31,8 +37,9 @@ func NewDeleteFileTool(reg *registry.Registry) mcp.Tool {
 // HandleDeleteFile handles the delete_file tool.
 func HandleDeleteFile(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	path := cast.ToString(request.Params.Arguments["path"])
+	force := cast.ToBool(request.Params.Arguments["force"])
 
-	resolvedPath, err := security.ValidateFinalPath(path, reg.Get())
+	resolvedPath, err := validateFinal(reg, path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return mcp.NewToolResultError("file does not exist"), nil
@@ -40,20 +47,54 @@ func HandleDeleteFile(ctx context.Context, reg *registry.Registry, request mcp.C
 		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
 	}
 
-	info, err := os.Stat(resolvedPath)
+	info, err := statTarget(reg, resolvedPath)
 	if err != nil {
+		if os.IsNotExist(err) {
+			return mcp.NewToolResultError("file does not exist"), nil
+		}
 		return mcp.NewToolResultError(fmt.Errorf("failed to stat path: %w", err).Error()), nil
 	}
 
 	if info.IsDir() {
 		return mcp.NewToolResultError("path is a directory, use delete_directory instead"), nil
 	}
+	if inTrash(reg, resolvedPath) {
+		return mcp.NewToolResultError("path is in the trash, use empty_trash instead"), nil
+	}
+	if inJournal(reg, resolvedPath) {
+		return mcp.NewToolResultError("path is in the undo journal, which prunes itself"), nil
+	}
 
-	if err := os.Remove(resolvedPath); err != nil {
+	if err := checkDeleteLimits(reg, 1, info.Size(), force); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if result := requireApproval(reg, "delete_file", request, fmt.Sprintf("delete %s", resolvedPath), resolvedPath); result != nil {
+		return result, nil
+	}
+	if err := reg.CheckWrite(resolvedPath); err != nil {
 		return mcp.NewToolResultError(fmt.Errorf("failed to delete file: %w", err).Error()), nil
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Successfully deleted %s", resolvedPath)), nil
+	// The trash keeps deleted files itself, so they are not journaled
+	if t := reg.Trash(); t != nil {
+		return putInTrash(reg, t, resolvedPath, false, info.Size())
+	}
+	var note string
+	if ov := reg.Overlay(); ov != nil {
+		if err := ov.Remove(resolvedPath); err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to delete file: %w", err).Error()), nil
+		}
+	} else {
+		op := beginJournal(reg, "delete_file", resolvedPath)
+		op.Save(resolvedPath)
+		if err := os.Remove(resolvedPath); err != nil {
+			op.Discard()
+			return mcp.NewToolResultError(fmt.Errorf("failed to delete file: %w", err).Error()), nil
+		}
+		note = journalNote(op)
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Successfully deleted %s%s", resolvedPath, note)), nil
 }
 
 // NewDeleteDirectoryTool creates the delete_directory tool.
@@ -63,6 +104,9 @@ func NewDeleteDirectoryTool(reg *registry.Registry) mcp.Tool {
 		mcp.WithDescription("Delete a directory. Requires recursive=true for non-empty directories."),
 		mcp.WithString("path", mcp.Description("Path to the directory to delete"), mcp.Required()),
 		mcp.WithBoolean("recursive", mcp.Description("If true, delete directory and all contents")),
+		withForce(),
+		withConfirmationToken(),
+		withApprovalToken(),
 		mcp.WithToolAnnotation(mcp.ToolAnnotation{
 			Title:           "Delete Directory",
 			ReadOnlyHint:    boolPtr(false),
@@ -76,8 +120,9 @@ func NewDeleteDirectoryTool(reg *registry.Registry) mcp.Tool {
 func HandleDeleteDirectory(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	path := cast.ToString(request.Params.Arguments["path"])
 	recursive := cast.ToBool(request.Params.Arguments["recursive"])
+	force := cast.ToBool(request.Params.Arguments["force"])
 
-	resolvedPath, err := security.ValidateFinalPath(path, reg.Get())
+	resolvedPath, err := validateFinal(reg, path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return mcp.NewToolResultError("directory does not exist"), nil
@@ -85,8 +130,11 @@ func HandleDeleteDirectory(ctx context.Context, reg *registry.Registry, request
 		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
 	}
 
-	info, err := os.Stat(resolvedPath)
+	info, err := statTarget(reg, resolvedPath)
 	if err != nil {
+		if os.IsNotExist(err) {
+			return mcp.NewToolResultError("directory does not exist"), nil
+		}
 		return mcp.NewToolResultError(fmt.Errorf("failed to stat path: %w", err).Error()), nil
 	}
 
@@ -96,15 +144,79 @@ func HandleDeleteDirectory(ctx context.Context, reg *registry.Registry, request
 
 	// Prevent deleting an allowed root directory
 	allowedDirs := reg.Get()
-	cleanPath := filepath.Clean(resolvedPath)
-	for _, allowed := range allowedDirs {
-		resolvedAllowed := allowed
-		if r, err := filepath.EvalSymlinks(allowed); err == nil {
-			resolvedAllowed = r
+	if isAllowedRoot(resolvedPath, allowedDirs) {
+		return mcp.NewToolResultError("cannot delete an allowed root directory"), nil
+	}
+	if inTrash(reg, resolvedPath) {
+		return mcp.NewToolResultError("path is in the trash, use empty_trash instead"), nil
+	}
+	if inJournal(reg, resolvedPath) {
+		return mcp.NewToolResultError("path is in the undo journal, which prunes itself"), nil
+	}
+
+	if recursive {
+		if err := checkTreeDeleteLimits(reg, resolvedPath, force); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
 		}
-		if filepath.Clean(resolvedAllowed) == cleanPath {
-			return mcp.NewToolResultError("cannot delete an allowed root directory"), nil
+	}
+	// The two-person rule includes the user's confirmation
+	action := fmt.Sprintf("delete the directory %s", resolvedPath)
+	if recursive {
+		action = fmt.Sprintf("recursively delete %s and all of its contents", resolvedPath)
+	}
+	if isProtected(reg, resolvedPath) {
+		if result := requireApproval(reg, "delete_directory", request, action, resolvedPath); result != nil {
+			return result, nil
+		}
+	} else if recursive {
+		if result := requireConfirmation(reg, "delete_directory", request, action); result != nil {
+			return result, nil
+		}
+	}
+	if err := reg.CheckWrite(resolvedPath); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to delete directory: %w", err).Error()), nil
+	}
+
+	if t := reg.Trash(); t != nil {
+		if !recursive {
+			entries, err := os.ReadDir(resolvedPath)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Errorf("failed to read directory: %w", err).Error()), nil
+			}
+			if len(entries) > 0 {
+				return mcp.NewToolResultError("failed to delete directory (may not be empty, use recursive=true): directory not empty"), nil
+			}
+		} else {
+			if err := rejectSymlinkEntries(resolvedPath); err != nil {
+				return mcp.NewToolResultError(fmt.Errorf("failed to validate directory contents: %w", err).Error()), nil
+			}
+			if err := checkDirectoryRemovable(resolvedPath, allowedDirs); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+		_, size, err := measureTree(reg, resolvedPath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to measure directory: %w", err).Error()), nil
+		}
+		return putInTrash(reg, t, resolvedPath, true, size)
+	}
+
+	if ov := reg.Overlay(); ov != nil {
+		if !recursive {
+			entries, err := ov.ReadDir(resolvedPath)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Errorf("failed to read directory: %w", err).Error()), nil
+			}
+			if len(entries) > 0 {
+				return mcp.NewToolResultError("failed to delete directory (may not be empty, use recursive=true): directory not empty"), nil
+			}
+		} else if err := checkDirectoryRemovable(resolvedPath, allowedDirs); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
 		}
+		if err := ov.Remove(resolvedPath); err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to delete directory: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Successfully deleted %s", resolvedPath)), nil
 	}
 
 	if recursive {
@@ -114,14 +226,8 @@ func HandleDeleteDirectory(ctx context.Context, reg *registry.Registry, request
 
 		// Extra safety check: ensure we're not recursively deleting anything that
 		// contains an allowed directory
-		for _, allowed := range allowedDirs {
-			resolvedAllowed := allowed
-			if r, err := filepath.EvalSymlinks(allowed); err == nil {
-				resolvedAllowed = r
-			}
-			if security.IsPathWithinAllowedDirectories(resolvedAllowed, []string{resolvedPath}) {
-				return mcp.NewToolResultError("cannot recursively delete a directory containing an allowed directory"), nil
-			}
+		if err := checkDirectoryRemovable(resolvedPath, allowedDirs); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
 		}
 
 		if err := os.RemoveAll(resolvedPath); err != nil {
@@ -137,6 +243,53 @@ func HandleDeleteDirectory(ctx context.Context, reg *registry.Registry, request
 	return mcp.NewToolResultText(fmt.Sprintf("Successfully deleted %s", resolvedPath)), nil
 }
 
+// putInTrash moves resolvedPath into the trash of the allowed directory
+// containing it.
+func putInTrash(reg *registry.Registry, t *trash.Trash, resolvedPath string, dir bool, size int64) (*mcp.CallToolResult, error) {
+	root := containingRoot(reg, resolvedPath)
+	if root == "" {
+		return mcp.NewToolResultError("path is not within an allowed directory"), nil
+	}
+	e, err := t.Put(root, resolvedPath, dir, size)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to delete: %w", err).Error()), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Moved %s to the trash as %s; use restore_from_trash to recover it", resolvedPath, e.ID)), nil
+}
+
+// containingRoot returns the innermost resolved allowed directory that
+// contains path, or "" if there is none.
+func containingRoot(reg *registry.Registry, path string) string {
+	var root string
+	for _, dir := range reg.GetResolved() {
+		if len(dir) > len(root) && security.IsPathWithinAllowedDirectories(path, []string{dir}) {
+			root = dir
+		}
+	}
+	return root
+}
+
+// inTrash reports whether path is a trash directory or within one.
+func inTrash(reg *registry.Registry, path string) bool {
+	return inRootDir(reg, path, trash.DirName)
+}
+
+// inJournal reports whether path is a journal directory or within one.
+func inJournal(reg *registry.Registry, path string) bool {
+	return inRootDir(reg, path, journal.DirName)
+}
+
+// inRootDir reports whether path is the directory name at the top of an
+// allowed directory, or within one.
+func inRootDir(reg *registry.Registry, path, name string) bool {
+	for _, dir := range reg.GetResolved() {
+		if security.IsPathWithinAllowedDirectories(path, []string{filepath.Join(dir, name)}) {
+			return true
+		}
+	}
+	return false
+}
+
 func rejectSymlinkEntries(root string) error {
 	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
@@ -148,3 +301,36 @@ func rejectSymlinkEntries(root string) error {
 		return nil
 	})
 }
+
+// isAllowedRoot reports whether path is one of the allowed root directories.
+func isAllowedRoot(path string, allowedDirs []string) bool {
+	cleanPath := filepath.Clean(path)
+	for _, allowed := range allowedDirs {
+		resolvedAllowed := allowed
+		if r, err := filepath.EvalSymlinks(allowed); err == nil {
+			resolvedAllowed = r
+		}
+		if filepath.Clean(resolvedAllowed) == cleanPath {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDirectoryRemovable rejects recursive removal of an allowed root or of
+// any directory that contains one.
+func checkDirectoryRemovable(path string, allowedDirs []string) error {
+	if isAllowedRoot(path, allowedDirs) {
+		return errors.New("cannot delete an allowed root directory")
+	}
+	for _, allowed := range allowedDirs {
+		resolvedAllowed := allowed
+		if r, err := filepath.EvalSymlinks(allowed); err == nil {
+			resolvedAllowed = r
+		}
+		if security.IsPathWithinAllowedDirectories(resolvedAllowed, []string{path}) {
+			return errors.New("cannot recursively delete a directory containing an allowed directory")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,377 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/spf13/cast"
+	"golang.org/x/mod/modfile"
+)
+
+// maxManifestSize is the largest dependency manifest that is parsed.
+const maxManifestSize = 1024 * 1024
+
+// manifestParsers maps manifest file names to their ecosystem and parser.
+var manifestParsers = map[string]struct {
+	ecosystem string
+	parse     func(data []byte, m *dependencyManifest) error
+}{
+	"go.mod":           {"go", parseGoMod},
+	"package.json":     {"npm", parsePackageJSON},
+	"requirements.txt": {"pypi", parseRequirements},
+	"Cargo.toml":       {"cargo", parseCargoToml},
+}
+
+// requirementPattern splits a requirements.txt line into name, extras, and
+// version specifier.
+var requirementPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(\[[^\]]*\])?\s*(.*)$`)
+
+// dependency is one entry of a manifest.
+type dependency struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Scope is empty for regular dependencies, or e.g. "dev", "indirect".
+	Scope string `json:"scope,omitempty"`
+}
+
+// dependencyManifest is a parsed dependency manifest.
+type dependencyManifest struct {
+	Path         string       `json:"path"`
+	Ecosystem    string       `json:"ecosystem"`
+	Name         string       `json:"name,omitempty"`
+	Dependencies []dependency `json:"dependencies"`
+	Error        string       `json:"error,omitempty"`
+}
+
+// consolidatedDependency is a dependency across every manifest using it.
+type consolidatedDependency struct {
+	Ecosystem string   `json:"ecosystem"`
+	Name      string   `json:"name"`
+	Versions  []string `json:"versions"`
+	Manifests []string `json:"manifests"`
+}
+
+// dependencySummary is the result of summarize_dependencies.
+type dependencySummary struct {
+	Root         string                   `json:"root"`
+	Manifests    []dependencyManifest     `json:"manifests"`
+	Dependencies []consolidatedDependency `json:"dependencies"`
+}
+
+// NewSummarizeDependenciesTool creates the summarize_dependencies tool.
+func NewSummarizeDependenciesTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"summarize_dependencies",
+		mcp.WithDescription("Find and parse dependency manifests (go.mod, package.json, requirements.txt, Cargo.toml) under a directory and return a consolidated dependency list with versions, noting which manifests use each dependency. Honors .gitignore and skips node_modules."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path", mcp.Description("Directory to search for manifests"), mcp.Required()),
+		mcp.WithArray("excludePatterns", mcp.Description("Glob patterns to exclude"), mcp.Items(map[string]any{"type": "string"})),
+		mcp.WithBoolean("respectGitignore", mcp.Description("If false, also read manifests ignored by .gitignore (default: true)")),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleSummarizeDependencies handles the summarize_dependencies tool.
+func HandleSummarizeDependencies(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	filter, err := parseTreeFilter(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	nodeModules, err := compileGlobs("**/node_modules")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	filter.excludeGlobs = append(filter.excludeGlobs, nodeModules...)
+
+	resolvedPath, errResult := resolveDirectory(reg, path)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	summary := dependencySummary{
+		Root:         resolvedPath,
+		Manifests:    []dependencyManifest{},
+		Dependencies: []consolidatedDependency{},
+	}
+	err = walkTree(ctx, reg, resolvedPath, filter, func(walkPath, relPath string, entry fs.DirEntry) error {
+		parser, ok := manifestParsers[entry.Name()]
+		if !ok {
+			return nil
+		}
+		m := dependencyManifest{Path: relPath, Ecosystem: parser.ecosystem, Dependencies: []dependency{}}
+		source, err := readTarget(reg, walkPath)
+		if err == nil {
+			err = readManifest(source, parser.parse, &m)
+		}
+		if err != nil {
+			m.Error = err.Error()
+		}
+		sort.Slice(m.Dependencies, func(i, j int) bool {
+			return m.Dependencies[i].Name < m.Dependencies[j].Name
+		})
+		summary.Manifests = append(summary.Manifests, m)
+		return nil
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("search failed: %w", err).Error()), nil
+	}
+	summary.Dependencies = consolidateDependencies(summary.Manifests)
+
+	if format == "json" {
+		jsonResult, err := json.MarshalIndent(summary, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(jsonResult)), nil
+	}
+
+	if len(summary.Manifests) == 0 {
+		return mcp.NewToolResultText("No dependency manifests found"), nil
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "Manifests under %s:\n", resolvedPath)
+	for _, m := range summary.Manifests {
+		name := ""
+		if m.Name != "" {
+			name = " (" + m.Name + ")"
+		}
+		if m.Error != "" {
+			fmt.Fprintf(&result, "  %s%s: failed to parse: %s\n", m.Path, name, m.Error)
+			continue
+		}
+		fmt.Fprintf(&result, "  %s%s: %d dependencies\n", m.Path, name, len(m.Dependencies))
+	}
+
+	ecosystem := ""
+	for _, d := range summary.Dependencies {
+		if d.Ecosystem != ecosystem {
+			ecosystem = d.Ecosystem
+			fmt.Fprintf(&result, "\n%s:\n", ecosystem)
+		}
+		fmt.Fprintf(&result, "  %s %s", d.Name, strings.Join(d.Versions, ", "))
+		if len(d.Manifests) > 1 {
+			fmt.Fprintf(&result, "  [%d manifests]", len(d.Manifests))
+		}
+		result.WriteString("\n")
+	}
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// readManifest reads and parses one manifest into m.
+func readManifest(path string, parse func([]byte, *dependencyManifest) error, m *dependencyManifest) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.Size() > maxManifestSize {
+		return fmt.Errorf("file is larger than %d bytes", maxManifestSize)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return parse(data, m)
+}
+
+// consolidateDependencies merges the dependencies of all manifests by
+// ecosystem and name. Versions carry their scope, e.g. "v1.2.0 (indirect)".
+func consolidateDependencies(manifests []dependencyManifest) []consolidatedDependency {
+	byKey := make(map[string]*consolidatedDependency)
+	for _, m := range manifests {
+		for _, d := range m.Dependencies {
+			key := m.Ecosystem + "\x00" + d.Name
+			c, ok := byKey[key]
+			if !ok {
+				c = &consolidatedDependency{Ecosystem: m.Ecosystem, Name: d.Name}
+				byKey[key] = c
+			}
+			version := d.Version
+			if d.Scope != "" {
+				version += " (" + d.Scope + ")"
+			}
+			c.Versions = appendUnique(c.Versions, version)
+			c.Manifests = appendUnique(c.Manifests, m.Path)
+		}
+	}
+
+	result := make([]consolidatedDependency, 0, len(byKey))
+	for _, c := range byKey {
+		sort.Strings(c.Versions)
+		result = append(result, *c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Ecosystem != result[j].Ecosystem {
+			return result[i].Ecosystem < result[j].Ecosystem
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+func parseGoMod(data []byte, m *dependencyManifest) error {
+	f, err := modfile.Parse("go.mod", data, nil)
+	if err != nil {
+		// Lax parsing tolerates directives this version does not know, but
+		// ignores replacements
+		if f, err = modfile.ParseLax("go.mod", data, nil); err != nil {
+			return err
+		}
+	}
+	if f.Module != nil {
+		m.Name = f.Module.Mod.Path
+	}
+
+	replaced := make(map[string]string)
+	for _, r := range f.Replace {
+		replaced[r.Old.Path] = strings.TrimSpace(r.New.Path + " " + r.New.Version)
+	}
+	for _, r := range f.Require {
+		d := dependency{Name: r.Mod.Path, Version: r.Mod.Version}
+		if r.Indirect {
+			d.Scope = "indirect"
+		}
+		if to, ok := replaced[r.Mod.Path]; ok {
+			d.Version += " => " + to
+		}
+		m.Dependencies = append(m.Dependencies, d)
+	}
+	return nil
+}
+
+func parsePackageJSON(data []byte, m *dependencyManifest) error {
+	var pkg struct {
+		Name                 string            `json:"name"`
+		Dependencies         map[string]string `json:"dependencies"`
+		DevDependencies      map[string]string `json:"devDependencies"`
+		PeerDependencies     map[string]string `json:"peerDependencies"`
+		OptionalDependencies map[string]string `json:"optionalDependencies"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return err
+	}
+	m.Name = pkg.Name
+
+	for _, group := range []struct {
+		deps  map[string]string
+		scope string
+	}{
+		{pkg.Dependencies, ""},
+		{pkg.DevDependencies, "dev"},
+		{pkg.PeerDependencies, "peer"},
+		{pkg.OptionalDependencies, "optional"},
+	} {
+		for name, version := range group.deps {
+			m.Dependencies = append(m.Dependencies, dependency{Name: name, Version: version, Scope: group.scope})
+		}
+	}
+	return nil
+}
+
+func parseRequirements(data []byte, m *dependencyManifest) error {
+	for _, line := range strings.Split(string(data), "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		// Options such as -r other.txt or --index-url are not dependencies
+		if line == "" || strings.HasPrefix(line, "-") {
+			continue
+		}
+		if i := strings.Index(line, ";"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+
+		match := requirementPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		version := strings.ReplaceAll(match[3], " ", "")
+		if version == "" {
+			version = "*"
+		}
+		m.Dependencies = append(m.Dependencies, dependency{Name: match[1], Version: version})
+	}
+	return nil
+}
+
+func parseCargoToml(data []byte, m *dependencyManifest) error {
+	var manifest map[string]any
+	if _, err := toml.Decode(string(data), &manifest); err != nil {
+		return err
+	}
+	if pkg, ok := manifest["package"].(map[string]any); ok {
+		m.Name = cast.ToString(pkg["name"])
+	}
+
+	addTables := func(tables map[string]any) {
+		for _, group := range []struct {
+			key   string
+			scope string
+		}{
+			{"dependencies", ""},
+			{"dev-dependencies", "dev"},
+			{"build-dependencies", "build"},
+		} {
+			deps, _ := tables[group.key].(map[string]any)
+			for name, spec := range deps {
+				m.Dependencies = append(m.Dependencies, dependency{Name: name, Version: cargoVersion(spec), Scope: group.scope})
+			}
+		}
+	}
+	addTables(manifest)
+	if workspace, ok := manifest["workspace"].(map[string]any); ok {
+		addTables(workspace)
+	}
+	if targets, ok := manifest["target"].(map[string]any); ok {
+		for _, t := range targets {
+			if tables, ok := t.(map[string]any); ok {
+				addTables(tables)
+			}
+		}
+	}
+	return nil
+}
+
+// cargoVersion describes a Cargo dependency specification, which is either
+// a version string or a table with a version, path, or git source.
+func cargoVersion(spec any) string {
+	table, ok := spec.(map[string]any)
+	if !ok {
+		return cast.ToString(spec)
+	}
+	switch {
+	case table["version"] != nil:
+		return cast.ToString(table["version"])
+	case table["path"] != nil:
+		return "path:" + filepath.ToSlash(cast.ToString(table["path"]))
+	case table["git"] != nil:
+		return "git:" + cast.ToString(table["git"])
+	case table["workspace"] == true:
+		return "workspace"
+	}
+	return "*"
+}
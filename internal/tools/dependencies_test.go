@@ -0,0 +1,105 @@
+package tools
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSummarizeDependencies(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	writePackTree(t, tmpDir, map[string]string{
+		"go.mod": `module example.com/app
+
+go 1.22
+
+require (
+	github.com/spf13/cast v1.7.1
+	golang.org/x/text v0.14.0 // indirect
+)
+
+replace github.com/spf13/cast => ../cast
+`,
+		"web/package.json": `{
+  "name": "web",
+  "dependencies": {"react": "^18.2.0"},
+  "devDependencies": {"typescript": "~5.4.0"}
+}`,
+		"web/node_modules/react/package.json": `{"name": "react", "dependencies": {"loose-envify": "^1.1.0"}}`,
+		"tools/requirements.txt": `# tooling
+requests==2.31.0
+uvicorn[standard] >= 0.29, < 1 ; python_version >= "3.8"
+-r other.txt
+flask
+`,
+		"crate/Cargo.toml": `[package]
+name = "crate"
+
+[dependencies]
+serde = { version = "1.0", features = ["derive"] }
+local = { path = "../local" }
+regex = "1"
+
+[dev-dependencies]
+react = "0.1"
+`,
+		"admin/package.json":  `{"name": "admin", "dependencies": {"react": "^17.0.0"}}`,
+		"broken/package.json": `{not json`,
+	})
+
+	result := callTool(t, HandleSummarizeDependencies, reg, map[string]any{"path": tmpDir, "format": "json"})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	var summary dependencySummary
+	if err := json.Unmarshal([]byte(resultText(result)), &summary); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(summary.Manifests) != 6 {
+		t.Fatalf("expected 6 manifests, got %+v", summary.Manifests)
+	}
+	var brokenErr string
+	for _, m := range summary.Manifests {
+		if strings.Contains(m.Path, "node_modules") {
+			t.Errorf("node_modules should be skipped: %s", m.Path)
+		}
+		if m.Path == "broken/package.json" {
+			brokenErr = m.Error
+		}
+	}
+	if brokenErr == "" {
+		t.Error("expected a parse error for broken/package.json")
+	}
+
+	versions := map[string]string{}
+	for _, d := range summary.Dependencies {
+		versions[d.Ecosystem+":"+d.Name] = strings.Join(d.Versions, " | ")
+	}
+	want := map[string]string{
+		"go:github.com/spf13/cast": "v1.7.1 => ../cast",
+		"go:golang.org/x/text":     "v0.14.0 (indirect)",
+		"npm:react":                "^17.0.0 | ^18.2.0",
+		"npm:typescript":           "~5.4.0 (dev)",
+		"pypi:requests":            "==2.31.0",
+		"pypi:uvicorn":             ">=0.29,<1",
+		"pypi:flask":               "*",
+		"cargo:serde":              "1.0",
+		"cargo:local":              "path:../local",
+		"cargo:regex":              "1",
+		"cargo:react":              "0.1 (dev)",
+	}
+	for key, v := range want {
+		if versions[key] != v {
+			t.Errorf("%s = %q, want %q", key, versions[key], v)
+		}
+	}
+	if len(versions) != len(want) {
+		t.Errorf("got %d dependencies, want %d: %v", len(versions), len(want), versions)
+	}
+
+	text := resultText(callTool(t, HandleSummarizeDependencies, reg, map[string]any{"path": tmpDir}))
+	if !strings.Contains(text, "react ^17.0.0, ^18.2.0  [2 manifests]") || !strings.Contains(text, "broken/package.json: failed to parse") {
+		t.Errorf("unexpected text output:\n%s", text)
+	}
+}
@@ -0,0 +1,252 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hexops/gotextdiff"
+	"github.com/hexops/gotextdiff/myers"
+	"github.com/hexops/gotextdiff/span"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/spf13/cast"
+)
+
+const (
+	// maxDiffSize caps the size of each file diff_files compares.
+	maxDiffSize = 10 * 1024 * 1024
+	// defaultDiffContext is the number of context lines around each change,
+	// as in diff -u.
+	defaultDiffContext = 3
+	maxDiffContext     = 1000
+)
+
+// diffLine is one line of a diff hunk.
+type diffLine struct {
+	// Kind is "equal", "insert", or "delete".
+	Kind    string `json:"kind"`
+	Content string `json:"content"`
+}
+
+// diffHunk is a run of changes with its surrounding context.
+type diffHunk struct {
+	OriginalStart int        `json:"originalStart"`
+	OriginalLines int        `json:"originalLines"`
+	ModifiedStart int        `json:"modifiedStart"`
+	ModifiedLines int        `json:"modifiedLines"`
+	Lines         []diffLine `json:"lines"`
+}
+
+// fileDiff is the result of diff_files.
+type fileDiff struct {
+	Original  string     `json:"original"`
+	Modified  string     `json:"modified"`
+	Identical bool       `json:"identical"`
+	Binary    bool       `json:"binary,omitempty"`
+	Added     int        `json:"added"`
+	Removed   int        `json:"removed"`
+	Hunks     []diffHunk `json:"hunks,omitempty"`
+}
+
+// NewDiffFilesTool creates the diff_files tool.
+func NewDiffFilesTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"diff_files",
+		mcp.WithDescription("Compare two text files and return a unified diff of the changes from original to modified, with a configurable number of context lines. Binary files are only reported as identical or different."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("original", mcp.Description("Path to the original file; its lines are shown with '-'"), mcp.Required()),
+		mcp.WithString("modified", mcp.Description("Path to the modified file; its lines are shown with '+'"), mcp.Required()),
+		mcp.WithNumber("context", mcp.Description("Lines of context around each change (default: 3, max: 1000)")),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleDiffFiles handles the diff_files tool.
+func HandleDiffFiles(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	original := cast.ToString(request.Params.Arguments["original"])
+	modified := cast.ToString(request.Params.Arguments["modified"])
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	diffContext := defaultDiffContext
+	if v, ok := request.Params.Arguments["context"]; ok {
+		diffContext = cast.ToInt(v)
+	}
+	if diffContext < 0 || diffContext > maxDiffContext {
+		return mcp.NewToolResultError(fmt.Sprintf("context must be between 0 and %d", maxDiffContext)), nil
+	}
+
+	originalPath, originalData, release, err := readDiffInput(ctx, reg, original)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("original: %w", err).Error()), nil
+	}
+	defer release()
+	modifiedPath, modifiedData, release, err := readDiffInput(ctx, reg, modified)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("modified: %w", err).Error()), nil
+	}
+	defer release()
+
+	result := fileDiff{Original: originalPath, Modified: modifiedPath}
+	oldText, newText := string(originalData), string(modifiedData)
+	var unified gotextdiff.Unified
+	switch {
+	case oldText == newText:
+		result.Identical = true
+	case looksBinary(originalData) || looksBinary(modifiedData):
+		result.Binary = true
+	default:
+		edits := myers.ComputeEdits(span.URIFromPath(originalPath), oldText, newText)
+		unified = gotextdiff.ToUnified("a/"+originalPath, "b/"+modifiedPath, oldText, edits)
+		unified.Hunks = regroupHunks(flattenDiff(oldText, unified), diffContext)
+		result.Hunks = make([]diffHunk, 0, len(unified.Hunks))
+		for _, h := range unified.Hunks {
+			result.Hunks = append(result.Hunks, newDiffHunk(h, &result))
+		}
+	}
+
+	if format == "json" {
+		jsonResult, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(jsonResult)), nil
+	}
+
+	switch {
+	case result.Identical:
+		return mcp.NewToolResultText(fmt.Sprintf("Files %s and %s are identical", originalPath, modifiedPath)), nil
+	case result.Binary:
+		return mcp.NewToolResultText(fmt.Sprintf("Binary files %s and %s differ", originalPath, modifiedPath)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("%d lines added, %d lines removed\n\n%v", result.Added, result.Removed, unified)), nil
+}
+
+// readDiffInput validates and reads one side of a diff, returning its
+// resolved path and contents, and a function that releases the memory
+// reserved for them.
+func readDiffInput(ctx context.Context, reg *registry.Registry, path string) (string, []byte, func(), error) {
+	resolvedPath, err := reg.Validate(path)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("path validation failed: %w", err)
+	}
+	source, err := readTarget(reg, resolvedPath)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	info, err := os.Stat(source)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+	if info.IsDir() {
+		return "", nil, nil, fmt.Errorf("%s is a directory, not a file", resolvedPath)
+	}
+	if info.Size() > maxDiffSize {
+		return "", nil, nil, fmt.Errorf("%s is larger than %d bytes", resolvedPath, maxDiffSize)
+	}
+	release, err := reserveMemory(ctx, reg, resolvedPath, info.Size())
+	if err != nil {
+		return "", nil, nil, err
+	}
+	data, err := os.ReadFile(source)
+	if err != nil {
+		release()
+		return "", nil, nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return resolvedPath, data, release, nil
+}
+
+// newDiffHunk converts a hunk for JSON output, adding its changes to the
+// totals in result.
+func newDiffHunk(h *gotextdiff.Hunk, result *fileDiff) diffHunk {
+	dh := diffHunk{OriginalStart: h.FromLine, ModifiedStart: h.ToLine, Lines: make([]diffLine, 0, len(h.Lines))}
+	for _, l := range h.Lines {
+		switch l.Kind {
+		case gotextdiff.Delete:
+			dh.OriginalLines++
+			result.Removed++
+		case gotextdiff.Insert:
+			dh.ModifiedLines++
+			result.Added++
+		default:
+			dh.OriginalLines++
+			dh.ModifiedLines++
+		}
+		dh.Lines = append(dh.Lines, diffLine{Kind: l.Kind.String(), Content: strings.TrimSuffix(l.Content, "\n")})
+	}
+	return dh
+}
+
+// flattenDiff expands the hunks of u, which was computed against oldText,
+// into every line of the diff, filling the gaps between hunks with the
+// unchanged lines of oldText.
+func flattenDiff(oldText string, u gotextdiff.Unified) []gotextdiff.Line {
+	oldLines := strings.SplitAfter(oldText, "\n")
+	if oldLines[len(oldLines)-1] == "" {
+		oldLines = oldLines[:len(oldLines)-1]
+	}
+
+	var lines []gotextdiff.Line
+	next := 0
+	for _, h := range u.Hunks {
+		for ; next < h.FromLine-1 && next < len(oldLines); next++ {
+			lines = append(lines, gotextdiff.Line{Kind: gotextdiff.Equal, Content: oldLines[next]})
+		}
+		for _, l := range h.Lines {
+			lines = append(lines, l)
+			if l.Kind != gotextdiff.Insert {
+				next++
+			}
+		}
+	}
+	for ; next < len(oldLines); next++ {
+		lines = append(lines, gotextdiff.Line{Kind: gotextdiff.Equal, Content: oldLines[next]})
+	}
+	return lines
+}
+
+// regroupHunks splits a flattened diff into hunks with the given number of
+// context lines. Changes separated by no more than twice that many unchanged
+// lines share a hunk, as in diff -u.
+func regroupHunks(lines []gotextdiff.Line, context int) []*gotextdiff.Hunk {
+	// Line numbers, in each file, of every line of the diff
+	fromLine := make([]int, len(lines))
+	toLine := make([]int, len(lines))
+	from, to := 1, 1
+	for i, l := range lines {
+		fromLine[i], toLine[i] = from, to
+		if l.Kind != gotextdiff.Insert {
+			from++
+		}
+		if l.Kind != gotextdiff.Delete {
+			to++
+		}
+	}
+
+	var hunks []*gotextdiff.Hunk
+	for i := 0; i < len(lines); {
+		if lines[i].Kind == gotextdiff.Equal {
+			i++
+			continue
+		}
+		start := max(i-context, 0)
+		end := i + 1
+		for j := i + 1; j < len(lines); j++ {
+			if lines[j].Kind != gotextdiff.Equal {
+				end = j + 1
+			} else if j-end+1 > 2*context {
+				break
+			}
+		}
+		stop := min(end+context, len(lines))
+		hunks = append(hunks, &gotextdiff.Hunk{
+			FromLine: fromLine[start],
+			ToLine:   toLine[start],
+			Lines:    lines[start:stop],
+		})
+		i = stop
+	}
+	return hunks
+}
@@ -0,0 +1,118 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHandleDiffFiles(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	var oldLines, newLines []string
+	for i := 1; i <= 20; i++ {
+		oldLines = append(oldLines, fmt.Sprintf("line %d", i))
+		switch i {
+		case 2:
+			newLines = append(newLines, "line two")
+		case 18:
+		default:
+			newLines = append(newLines, fmt.Sprintf("line %d", i))
+		}
+	}
+	original := filepath.Join(tmpDir, "old.txt")
+	modified := filepath.Join(tmpDir, "new.txt")
+	binary := filepath.Join(tmpDir, "data.bin")
+	for path, content := range map[string]string{
+		original: strings.Join(oldLines, "\n") + "\n",
+		modified: strings.Join(newLines, "\n") + "\n",
+		binary:   "a\x00b",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		args        map[string]any
+		contains    []string
+		notContains []string
+	}{
+		{
+			name: "default context",
+			args: map[string]any{"original": original, "modified": modified},
+			contains: []string{
+				"1 lines added, 2 lines removed",
+				"--- a/" + original + "\n+++ b/" + modified + "\n",
+				"@@ -1,5 +1,5 @@\n line 1\n-line 2\n+line two\n line 3\n",
+				"@@ -15,6 +15,5 @@\n line 15\n line 16\n line 17\n-line 18\n line 19\n line 20\n",
+			},
+		},
+		{
+			name:     "no context",
+			args:     map[string]any{"original": original, "modified": modified, "context": 0},
+			contains: []string{"@@ -2 +2 @@\n-line 2\n+line two\n@@ -18 +18 @@\n-line 18\n"},
+		},
+		{
+			name:        "wide context merges hunks",
+			args:        map[string]any{"original": original, "modified": modified, "context": 8},
+			contains:    []string{"@@ -1,20 +1,19 @@\n line 1\n-line 2\n"},
+			notContains: []string{"@@ -15"},
+		},
+		{
+			name:     "identical",
+			args:     map[string]any{"original": original, "modified": original},
+			contains: []string{"are identical"},
+		},
+		{
+			name:     "binary",
+			args:     map[string]any{"original": original, "modified": binary},
+			contains: []string{"Binary files " + original + " and " + binary + " differ"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := callTool(t, HandleDiffFiles, reg, tt.args)
+			text := resultText(result)
+			if result.IsError {
+				t.Fatalf("diff_files failed: %s", text)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(text, want) {
+					t.Errorf("expected %q in:\n%s", want, text)
+				}
+			}
+			for _, unwanted := range tt.notContains {
+				if strings.Contains(text, unwanted) {
+					t.Errorf("did not expect %q in:\n%s", unwanted, text)
+				}
+			}
+		})
+	}
+
+	result := callTool(t, HandleDiffFiles, reg, map[string]any{"original": original, "modified": modified, "format": "json"})
+	var diff fileDiff
+	if err := json.Unmarshal([]byte(resultText(result)), &diff); err != nil {
+		t.Fatal(err)
+	}
+	if diff.Added != 1 || diff.Removed != 2 || len(diff.Hunks) != 2 {
+		t.Fatalf("unexpected diff: %+v", diff)
+	}
+	if h := diff.Hunks[1]; h.OriginalStart != 15 || h.OriginalLines != 6 || h.ModifiedStart != 15 || h.ModifiedLines != 5 || h.Lines[3] != (diffLine{Kind: "delete", Content: "line 18"}) {
+		t.Errorf("unexpected second hunk: %+v", h)
+	}
+
+	for name, args := range map[string]map[string]any{
+		"directory":    {"original": tmpDir, "modified": modified},
+		"missing":      {"original": original, "modified": filepath.Join(tmpDir, "missing.txt")},
+		"outside":      {"original": original, "modified": "/etc/passwd"},
+		"huge context": {"original": original, "modified": modified, "context": 5000},
+	} {
+		if result := callTool(t, HandleDiffFiles, reg, args); !result.IsError {
+			t.Errorf("%s: expected an error, got %q", name, resultText(result))
+		}
+	}
+}
@@ -3,10 +3,13 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gobwas/glob"
@@ -38,9 +41,24 @@ func HandleCreateDirectory(ctx context.Context, reg *registry.Registry, request
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
 	}
+	if err := reg.CheckWrite(resolvedPath); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to create directory: %w", err).Error()), nil
+	}
+
+	if ov := reg.Overlay(); ov != nil {
+		if target, err := ov.ReadPath(resolvedPath); err == nil {
+			if info, err := os.Stat(target); err == nil && !info.IsDir() {
+				return mcp.NewToolResultError("failed to create directory: path exists and is not a directory"), nil
+			}
+		}
+		if _, err := ov.MkdirAll(resolvedPath); err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to create directory: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Successfully created directory %s", resolvedPath)), nil
+	}
 
 	// Use safeMkdirAll to prevent creating directories through symlinks
-	if err := safeMkdirAll(path, 0755, reg.Get()); err != nil {
+	if err := safeMkdirAll(reg.ExpandPath(path), reg.Modes().Dir, reg.Get()); err != nil {
 		return mcp.NewToolResultError(fmt.Errorf("failed to create directory: %w", err).Error()), nil
 	}
 
@@ -54,7 +72,7 @@ func NewListDirectoryTool(reg *registry.Registry) mcp.Tool {
 		mcp.WithDescription("List contents of a directory with [FILE] and [DIR] prefixes."),
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithString("path", mcp.Description("Path to the directory to list"), mcp.Required()),
-		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+		mcp.WithString("format", mcp.Description("Output format: 'text', 'json', or 'csv'")),
 	)
 }
 
@@ -68,7 +86,11 @@ func HandleListDirectory(ctx context.Context, reg *registry.Registry, request mc
 		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
 	}
 
-	info, err := os.Stat(resolvedPath)
+	target, err := readTarget(reg, resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to stat path: %w", err).Error()), nil
+	}
+	info, err := os.Stat(target)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Errorf("failed to stat path: %w", err).Error()), nil
 	}
@@ -76,7 +98,7 @@ func HandleListDirectory(ctx context.Context, reg *registry.Registry, request mc
 		return mcp.NewToolResultError("path is not a directory"), nil
 	}
 
-	entries, err := os.ReadDir(resolvedPath)
+	entries, err := readDir(reg, resolvedPath)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Errorf("failed to read directory: %w", err).Error()), nil
 	}
@@ -111,6 +133,18 @@ func HandleListDirectory(ctx context.Context, reg *registry.Registry, request mc
 		return mcp.NewToolResultText(string(jsonResult)), nil
 	}
 
+	if format == "csv" {
+		rows := make([][]string, 0, len(entries))
+		for _, entry := range entries {
+			entryType := "file"
+			if entry.IsDir() {
+				entryType = "directory"
+			}
+			rows = append(rows, []string{csvText(entry.Name()), entryType})
+		}
+		return mcp.NewToolResultText(formatCSV([]string{"name", "type"}, rows)), nil
+	}
+
 	var result string
 	for _, entry := range entries {
 		prefix := "[FILE]"
@@ -132,7 +166,7 @@ func NewListDirectoryWithSizesTool(reg *registry.Registry) mcp.Tool {
 		mcp.WithString("path", mcp.Description("Path to the directory to list"), mcp.Required()),
 		mcp.WithString("sortBy", mcp.Description("Sort by 'name', 'size', or 'modified'")),
 		mcp.WithString("order", mcp.Description("Sort order: 'asc' or 'desc'")),
-		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+		mcp.WithString("format", mcp.Description("Output format: 'text', 'json', 'markdown', or 'csv'")),
 	)
 }
 
@@ -148,7 +182,11 @@ func HandleListDirectoryWithSizes(ctx context.Context, reg *registry.Registry, r
 		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
 	}
 
-	info, err := os.Stat(resolvedPath)
+	target, err := readTarget(reg, resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to stat path: %w", err).Error()), nil
+	}
+	info, err := os.Stat(target)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Errorf("failed to stat path: %w", err).Error()), nil
 	}
@@ -156,7 +194,7 @@ func HandleListDirectoryWithSizes(ctx context.Context, reg *registry.Registry, r
 		return mcp.NewToolResultError("path is not a directory"), nil
 	}
 
-	entries, err := os.ReadDir(resolvedPath)
+	entries, err := readDir(reg, resolvedPath)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Errorf("failed to read directory: %w", err).Error()), nil
 	}
@@ -270,6 +308,32 @@ func HandleListDirectoryWithSizes(ctx context.Context, reg *registry.Registry, r
 		return mcp.NewToolResultText(string(jsonResult)), nil
 	}
 
+	if format == "csv" {
+		rows := make([][]string, 0, len(files))
+		for _, f := range files {
+			entryType := "file"
+			if f.isDir {
+				entryType = "directory"
+			}
+			rows = append(rows, []string{csvText(f.name), entryType, strconv.FormatInt(f.size, 10), time.Unix(0, f.modified).UTC().Format(time.RFC3339)})
+		}
+		return mcp.NewToolResultText(formatCSV([]string{"name", "type", "size", "modified"}, rows)), nil
+	}
+
+	if format == "markdown" {
+		rows := make([][]string, 0, len(files))
+		for _, f := range files {
+			entryType, size := "file", stream.FormatSize(f.size)
+			if f.isDir {
+				entryType, size = "directory", ""
+			}
+			rows = append(rows, []string{markdownCode(f.name), entryType, size, time.Unix(0, f.modified).UTC().Format(time.RFC3339)})
+		}
+		result := markdownTable([]string{"Name", "Type", "Size", "Modified"}, rows)
+		result += fmt.Sprintf("\n%d files, %d directories, %s total\n", fileCount, dirCount, stream.FormatSize(totalSize))
+		return mcp.NewToolResultText(result), nil
+	}
+
 	var result string
 	for _, f := range files {
 		if f.isDir {
@@ -293,12 +357,23 @@ func NewDirectoryTreeTool(reg *registry.Registry) mcp.Tool {
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithString("path", mcp.Description("Path to the root directory"), mcp.Required()),
 		mcp.WithArray("excludePatterns", mcp.Description("Glob patterns to exclude"), mcp.Items(map[string]any{"type": "string"})),
+		mcp.WithBoolean("includeMetadata", mcp.Description("If true, add each entry's modification time and permissions, and the size of files")),
+		withMaxFilesParam(),
+		mcp.WithString("format", mcp.Description("Output format: 'json' (default) for the nested tree, or 'flat' for one relative file path per line, the most compact inventory of a tree")),
 	)
 }
 
 // HandleDirectoryTree handles the directory_tree tool.
 func HandleDirectoryTree(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	path := cast.ToString(request.Params.Arguments["path"])
+	metadata := cast.ToBool(request.Params.Arguments["includeMetadata"])
+	format := cast.ToString(request.Params.Arguments["format"])
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "flat" {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid format %q: expected json or flat", format)), nil
+	}
 
 	var excludePatterns []string
 	if patternsArg, ok := request.Params.Arguments["excludePatterns"].([]interface{}); ok {
@@ -306,18 +381,14 @@ func HandleDirectoryTree(ctx context.Context, reg *registry.Registry, request mc
 			excludePatterns = append(excludePatterns, cast.ToString(p))
 		}
 	}
-
-	resolvedPath, err := reg.Validate(path)
+	budget, err := parseMaxFiles(request)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	info, err := os.Stat(resolvedPath)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Errorf("failed to stat path: %w", err).Error()), nil
-	}
-	if !info.IsDir() {
-		return mcp.NewToolResultError("path is not a directory"), nil
+	resolvedPath, errResult := resolveDirectory(reg, path)
+	if errResult != nil {
+		return errResult, nil
 	}
 
 	// Compile exclude patterns
@@ -330,27 +401,54 @@ func HandleDirectoryTree(ctx context.Context, reg *registry.Registry, request mc
 		excludeGlobs = append(excludeGlobs, g)
 	}
 
-	tree, err := buildTree(resolvedPath, excludeGlobs)
+	tree, err := buildTree(reg, resolvedPath, excludeGlobs, budget, metadata)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Errorf("failed to build tree: %w", err).Error()), nil
 	}
 
+	if format == "flat" {
+		var b strings.Builder
+		var walkErrs walkErrors
+		flattenTree(&b, &walkErrs, resolvedPath, "", tree.Children)
+		if b.Len() == 0 {
+			b.WriteString("No files found")
+		}
+		return withWalkErrors(withBudgetNote(mcp.NewToolResultText(b.String()), budget), &walkErrs), nil
+	}
+
 	jsonResult, err := json.MarshalIndent(tree, "", "  ")
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Errorf("failed to marshal tree: %w", err).Error()), nil
 	}
 
-	return mcp.NewToolResultText(string(jsonResult)), nil
+	return withBudgetNote(mcp.NewToolResultText(string(jsonResult)), budget), nil
 }
 
-// buildTree recursively builds a directory tree.
-// Symlinks are skipped during recursion but allowed at the root (already validated by caller).
-func buildTree(path string, excludeGlobs []glob.Glob) (*filesystem.TreeEntry, error) {
-	info, err := os.Stat(path)
-	if err != nil {
-		return nil, err
+// flattenTree writes the slash-separated path of each file in entries,
+// relative to the tree's root, one per line. Inaccessible entries, which
+// have no place in a list of files, are added to errs instead.
+func flattenTree(b *strings.Builder, errs *walkErrors, root, prefix string, entries []*filesystem.TreeEntry) {
+	for _, e := range entries {
+		relPath := prefix + e.Name
+		switch e.Type {
+		case "file":
+			b.WriteString(relPath)
+			b.WriteString("\n")
+		case "directory":
+			flattenTree(b, errs, root, relPath+"/", e.Children)
+		case "inaccessible":
+			errs.add(filepath.Join(root, filepath.FromSlash(relPath)), errors.New(e.Error))
+		}
 	}
+}
 
+// buildTree recursively builds a directory tree, leaving out the entries
+// past the budget, with each entry's metadata if metadata is set. Entries below path that cannot be read are kept with
+// type "inaccessible" and the reason; only an unreadable path itself is an
+// error.
+// Symlinks are skipped during recursion but allowed at the root (already validated by caller).
+// In overlay mode the tree includes pending changes.
+func buildTree(reg *registry.Registry, path string, excludeGlobs []glob.Glob, budget *fileBudget, metadata bool) (*filesystem.TreeEntry, error) {
 	name := filepath.Base(path)
 
 	// Check exclusions
@@ -360,15 +458,24 @@ func buildTree(path string, excludeGlobs []glob.Glob) (*filesystem.TreeEntry, er
 		}
 	}
 
+	info, err := statTarget(reg, path)
+	if err != nil {
+		return nil, err
+	}
+
 	entry := &filesystem.TreeEntry{
 		Name: name,
 	}
+	if metadata {
+		entry.Modified = info.ModTime().Format(time.RFC3339)
+		entry.Permissions = fmt.Sprintf("%04o", info.Mode().Perm())
+	}
 
 	if info.IsDir() {
 		entry.Type = "directory"
 		entry.Children = []*filesystem.TreeEntry{}
 
-		entries, err := os.ReadDir(path)
+		entries, err := readDir(reg, path)
 		if err != nil {
 			return nil, err
 		}
@@ -381,10 +488,13 @@ func buildTree(path string, excludeGlobs []glob.Glob) (*filesystem.TreeEntry, er
 			if isSymlinkDirEntry(e) {
 				continue
 			}
+			if !budget.take() {
+				break
+			}
 			childPath := filepath.Join(path, e.Name())
-			child, err := buildTree(childPath, excludeGlobs)
+			child, err := buildTree(reg, childPath, excludeGlobs, budget, metadata)
 			if err != nil {
-				return nil, err
+				child = &filesystem.TreeEntry{Name: e.Name(), Type: "inaccessible", Error: walkErrorText(err)}
 			}
 			if child != nil {
 				entry.Children = append(entry.Children, child)
@@ -392,6 +502,10 @@ func buildTree(path string, excludeGlobs []glob.Glob) (*filesystem.TreeEntry, er
 		}
 	} else {
 		entry.Type = "file"
+		if metadata {
+			size := info.Size()
+			entry.Size = &size
+		}
 	}
 
 	return entry, nil
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/pkg/filesystem"
 )
 
 func TestHandleCreateDirectory(t *testing.T) {
@@ -304,3 +305,106 @@ func TestHandleDirectoryTreeSkipsSymlinkedDirectories(t *testing.T) {
 		t.Error("symlinked directory should not be in output")
 	}
 }
+
+func TestHandleDirectoryTreeMaxFiles(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	for _, name := range []string{"a.txt", "b.txt", "c.txt", "d.txt"} {
+		os.WriteFile(filepath.Join(tmpDir, name), []byte("x"), 0644)
+	}
+
+	result := callTool(t, HandleDirectoryTree, reg, map[string]any{"path": tmpDir, "maxFiles": 2})
+	if result.IsError || len(result.Content) != 2 {
+		t.Fatalf("expected the tree and a note, got %v", result.Content)
+	}
+	output := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(output, "b.txt") || strings.Contains(output, "c.txt") {
+		t.Errorf("expected the tree to stop after 2 entries:\n%s", output)
+	}
+	if note := result.Content[1].(mcp.TextContent).Text; !strings.Contains(note, "raise maxFiles") {
+		t.Errorf("unexpected note %q", note)
+	}
+
+	result = callTool(t, HandleDirectoryTree, reg, map[string]any{"path": tmpDir, "maxFiles": 4})
+	if len(result.Content) != 1 {
+		t.Errorf("expected no note when every entry fits, got %v", result.Content)
+	}
+	if result := callTool(t, HandleDirectoryTree, reg, map[string]any{"path": tmpDir, "maxFiles": 0}); !result.IsError {
+		t.Error("expected maxFiles 0 to be rejected")
+	}
+}
+
+func TestHandleDirectoryTreeMarksUnreadable(t *testing.T) {
+	if os.Getuid() == 0 {
+		t.Skip("permissions are not enforced for root")
+	}
+	reg, tmpDir := setupTestRegistry(t)
+	os.WriteFile(filepath.Join(tmpDir, "ok.txt"), []byte("x"), 0644)
+	locked := filepath.Join(tmpDir, "locked")
+	os.Mkdir(locked, 0)
+	t.Cleanup(func() { os.Chmod(locked, 0755) })
+
+	result := callTool(t, HandleDirectoryTree, reg, map[string]any{"path": tmpDir})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	var tree filesystem.TreeEntry
+	if err := json.Unmarshal([]byte(resultText(result)), &tree); err != nil {
+		t.Fatal(err)
+	}
+	if len(tree.Children) != 2 {
+		t.Fatalf("expected both entries, got %+v", tree.Children)
+	}
+	if c := tree.Children[0]; c.Name != "locked" || c.Type != "inaccessible" || c.Error != "permission denied" {
+		t.Errorf("unexpected entry %+v", c)
+	}
+	if c := tree.Children[1]; c.Name != "ok.txt" || c.Type != "file" {
+		t.Errorf("unexpected entry %+v", c)
+	}
+}
+
+func TestHandleDirectoryTreeMetadata(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	os.Mkdir(filepath.Join(tmpDir, "sub"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, "sub", "a.txt"), []byte("hello"), 0640)
+
+	result := callTool(t, HandleDirectoryTree, reg, map[string]any{"path": tmpDir})
+	if strings.Contains(resultText(result), "modified") {
+		t.Errorf("expected no metadata by default:\n%s", resultText(result))
+	}
+
+	result = callTool(t, HandleDirectoryTree, reg, map[string]any{"path": tmpDir, "includeMetadata": true})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	var tree filesystem.TreeEntry
+	if err := json.Unmarshal([]byte(resultText(result)), &tree); err != nil {
+		t.Fatal(err)
+	}
+	dir := tree.Children[0]
+	if dir.Size != nil || dir.Modified == "" || dir.Permissions != "0755" {
+		t.Errorf("unexpected directory metadata %+v", dir)
+	}
+	file := dir.Children[0]
+	if file.Size == nil || *file.Size != 5 || file.Modified == "" || file.Permissions != "0640" {
+		t.Errorf("unexpected file metadata %+v", file)
+	}
+}
+
+func TestHandleDirectoryTreeFlat(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	os.MkdirAll(filepath.Join(tmpDir, "src", "empty"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, "src", "main.go"), []byte("x"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "README.md"), []byte("x"), 0644)
+
+	result := callTool(t, HandleDirectoryTree, reg, map[string]any{"path": tmpDir, "format": "flat"})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if got, want := resultText(result), "README.md\nsrc/main.go\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if result := callTool(t, HandleDirectoryTree, reg, map[string]any{"path": tmpDir, "format": "yaml"}); !result.IsError {
+		t.Error("expected an unknown format to be rejected")
+	}
+}
@@ -0,0 +1,208 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/spf13/cast"
+)
+
+const (
+	defaultUsageDepth = 1
+	maxUsageDepth     = 20
+	defaultUsageLimit = 100
+	maxUsageLimit     = 1000
+)
+
+// dirUsage is the recursive size of one directory reported by disk_usage.
+type dirUsage struct {
+	// Path is relative to the measured directory, which is ".".
+	Path  string `json:"path"`
+	Depth int    `json:"depth"`
+	Size  int64  `json:"size"`
+	Files int    `json:"files"`
+	Dirs  int    `json:"dirs"`
+}
+
+// diskUsage is the result of disk_usage.
+type diskUsage struct {
+	Root        string     `json:"root"`
+	Total       dirUsage   `json:"total"`
+	Directories []dirUsage `json:"directories"`
+	// Omitted is the number of directories left out by the limit.
+	Omitted int `json:"omitted,omitempty"`
+	// Unreadable is the number of directories that could not be read and
+	// were counted as empty.
+	Unreadable int `json:"unreadable,omitempty"`
+}
+
+// NewDiskUsageTool creates the disk_usage tool.
+func NewDiskUsageTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"disk_usage",
+		mcp.WithDescription("Report the recursive size of each subdirectory under a directory, like du. Every file below a directory counts towards its size, down to any depth; maxDepth only limits which directories are listed. Sizes are apparent file sizes. Symlinks are not followed."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path", mcp.Description("Directory to measure"), mcp.Required()),
+		mcp.WithNumber("maxDepth", mcp.Description("Deepest level of subdirectories to list; 0 reports only the total (default: 1, max: 20)")),
+		mcp.WithString("sortBy", mcp.Description("Sort by 'size' (largest first, default) or 'name' (tree order)")),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of directories to list (default: 100, max: 1000)")),
+		mcp.WithArray("excludePatterns", mcp.Description("Glob patterns to exclude from the sizes"), mcp.Items(map[string]any{"type": "string"})),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleDiskUsage handles the disk_usage tool.
+func HandleDiskUsage(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	sortBy := cast.ToString(request.Params.Arguments["sortBy"])
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	maxDepth := defaultUsageDepth
+	if v, ok := request.Params.Arguments["maxDepth"]; ok {
+		maxDepth = cast.ToInt(v)
+	}
+	if maxDepth < 0 || maxDepth > maxUsageDepth {
+		return mcp.NewToolResultError(fmt.Sprintf("maxDepth must be between 0 and %d", maxUsageDepth)), nil
+	}
+	limit := cast.ToInt(request.Params.Arguments["limit"])
+	if limit <= 0 {
+		limit = defaultUsageLimit
+	}
+	if limit > maxUsageLimit {
+		limit = maxUsageLimit
+	}
+	if sortBy == "" {
+		sortBy = "size"
+	}
+	if sortBy != "size" && sortBy != "name" {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid sortBy %q: expected size or name", sortBy)), nil
+	}
+
+	filter, err := parseTreeFilter(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	resolvedPath, err := reg.Validate(path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	info, err := statTarget(reg, resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to stat path: %w", err).Error()), nil
+	}
+	if !info.IsDir() {
+		return mcp.NewToolResultError("path is not a directory"), nil
+	}
+
+	m := usageMeasurer{ctx: ctx, reg: reg, excludes: filter.excludeGlobs, maxDepth: maxDepth}
+	total, err := m.measure(resolvedPath, ".", 0)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to measure directory: %w", err).Error()), nil
+	}
+
+	// The total is reported separately
+	dirs := m.dirs[:len(m.dirs)-1]
+	if sortBy == "size" {
+		sort.SliceStable(dirs, func(i, j int) bool {
+			if dirs[i].Size != dirs[j].Size {
+				return dirs[i].Size > dirs[j].Size
+			}
+			return dirs[i].Path < dirs[j].Path
+		})
+	} else {
+		sort.Slice(dirs, func(i, j int) bool { return dirs[i].Path < dirs[j].Path })
+	}
+	result := diskUsage{Root: resolvedPath, Total: total, Directories: dirs, Unreadable: m.unreadable}
+	if len(dirs) > limit {
+		result.Directories, result.Omitted = dirs[:limit], len(dirs)-limit
+	}
+
+	if format == "json" {
+		jsonResult, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(jsonResult)), nil
+	}
+
+	var text strings.Builder
+	for _, d := range result.Directories {
+		fmt.Fprintf(&text, "%10s  %s/\n", stream.FormatSize(d.Size), d.Path)
+	}
+	if result.Omitted > 0 {
+		fmt.Fprintf(&text, "... %d more directories not shown\n", result.Omitted)
+	}
+	fmt.Fprintf(&text, "%10s  %s (%d files, %d directories)\n", stream.FormatSize(total.Size), resolvedPath, total.Files, total.Dirs)
+	if result.Unreadable > 0 {
+		fmt.Fprintf(&text, "Skipped %d unreadable directories\n", result.Unreadable)
+	}
+	return mcp.NewToolResultText(text.String()), nil
+}
+
+// usageMeasurer totals directory sizes for disk_usage.
+type usageMeasurer struct {
+	ctx        context.Context
+	reg        *registry.Registry
+	excludes   []glob.Glob
+	maxDepth   int
+	dirs       []dirUsage
+	unreadable int
+}
+
+// measure totals the directory at dir, whose path relative to the measured
+// root is relPath, recording it and its subdirectories down to maxDepth. A
+// directory is recorded after its subdirectories, so the root comes last.
+func (m *usageMeasurer) measure(dir, relPath string, depth int) (dirUsage, error) {
+	usage := dirUsage{Path: relPath, Depth: depth}
+	entries, err := readDir(m.reg, dir)
+	if err != nil {
+		if depth == 0 {
+			return usage, err
+		}
+		m.unreadable++
+	}
+
+	for _, entry := range entries {
+		if err := m.ctx.Err(); err != nil {
+			return usage, err
+		}
+		childRel := entry.Name()
+		if relPath != "." {
+			childRel = relPath + "/" + entry.Name()
+		}
+		if entry.Type()&os.ModeSymlink != 0 || matchesAny(m.excludes, childRel) {
+			continue
+		}
+		if entry.IsDir() {
+			child, err := m.measure(filepath.Join(dir, entry.Name()), childRel, depth+1)
+			if err != nil {
+				return usage, err
+			}
+			usage.Size += child.Size
+			usage.Files += child.Files
+			usage.Dirs += child.Dirs + 1
+			continue
+		}
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		if info, err := entry.Info(); err == nil {
+			usage.Size += info.Size()
+			usage.Files++
+		}
+	}
+
+	if depth <= m.maxDepth {
+		m.dirs = append(m.dirs, usage)
+	}
+	return usage, nil
+}
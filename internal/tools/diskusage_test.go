@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHandleDiskUsage(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	writePackTree(t, tmpDir, map[string]string{
+		"top.txt":             strings.Repeat("t", 10),
+		"src/main.go":         strings.Repeat("m", 100),
+		"src/pkg/lib.go":      strings.Repeat("l", 1000),
+		"src/pkg/deep/x.go":   strings.Repeat("x", 5),
+		"docs/guide.md":       strings.Repeat("d", 300),
+		"docs/skip/large.bin": strings.Repeat("s", 5000),
+	})
+	if err := os.Symlink(filepath.Join(tmpDir, "src"), filepath.Join(tmpDir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	usage := func(args map[string]any) diskUsage {
+		t.Helper()
+		args["path"] = tmpDir
+		args["format"] = "json"
+		result := callTool(t, HandleDiskUsage, reg, args)
+		if result.IsError {
+			t.Fatalf("disk_usage failed: %s", resultText(result))
+		}
+		var u diskUsage
+		if err := json.Unmarshal([]byte(resultText(result)), &u); err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+
+	u := usage(map[string]any{})
+	if u.Total.Size != 6415 || u.Total.Files != 6 || u.Total.Dirs != 5 {
+		t.Errorf("unexpected total: %+v", u.Total)
+	}
+	var got []string
+	for _, d := range u.Directories {
+		got = append(got, d.Path)
+	}
+	if strings.Join(got, ",") != "docs,src" || u.Directories[0].Size != 5300 || u.Directories[1].Size != 1105 {
+		t.Errorf("unexpected directories: %+v", u.Directories)
+	}
+
+	u = usage(map[string]any{"maxDepth": 2, "sortBy": "name", "excludePatterns": []any{"docs/skip"}})
+	got = nil
+	for _, d := range u.Directories {
+		got = append(got, d.Path)
+	}
+	if strings.Join(got, ",") != "docs,src,src/pkg" || u.Total.Size != 1415 {
+		t.Errorf("unexpected result: total %d, directories %v", u.Total.Size, got)
+	}
+
+	u = usage(map[string]any{"maxDepth": 3, "limit": 2})
+	if len(u.Directories) != 2 || u.Omitted != 3 {
+		t.Errorf("expected 2 directories and 3 omitted, got %+v", u)
+	}
+
+	result := callTool(t, HandleDiskUsage, reg, map[string]any{"path": tmpDir})
+	text := resultText(result)
+	if !strings.Contains(text, "5.2 KB  docs/\n") || !strings.Contains(text, "(6 files, 5 directories)") {
+		t.Errorf("unexpected text output:\n%s", text)
+	}
+
+	for name, args := range map[string]map[string]any{
+		"file":       {"path": filepath.Join(tmpDir, "top.txt")},
+		"deep":       {"path": tmpDir, "maxDepth": 50},
+		"bad sortBy": {"path": tmpDir, "sortBy": "modified"},
+	} {
+		if result := callTool(t, HandleDiskUsage, reg, args); !result.IsError {
+			t.Errorf("%s: expected an error, got %q", name, resultText(result))
+		}
+	}
+}
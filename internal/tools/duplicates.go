@@ -0,0 +1,206 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/spf13/cast"
+)
+
+const (
+	defaultDuplicateSets = 50
+	maxDuplicateSets     = 1000
+)
+
+// duplicateSet is a group of files with identical content.
+type duplicateSet struct {
+	SHA256 string   `json:"sha256"`
+	Size   int64    `json:"size"`
+	Paths  []string `json:"paths"`
+	// Wasted is the space taken by all copies but one.
+	Wasted int64 `json:"wasted"`
+}
+
+// duplicateReport is the result of find_duplicates.
+type duplicateReport struct {
+	Root    string         `json:"root"`
+	Scanned int            `json:"scanned"`
+	Hashed  int            `json:"hashed"`
+	Sets    []duplicateSet `json:"sets"`
+	// TotalSets and Wasted cover every set found, including any left out
+	// by the limit.
+	TotalSets int   `json:"totalSets"`
+	Wasted    int64 `json:"wasted"`
+}
+
+// NewFindDuplicatesTool creates the find_duplicates tool.
+func NewFindDuplicatesTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"find_duplicates",
+		mcp.WithDescription("Find files with identical content under a directory. Files are grouped by size, and only files sharing a size are hashed, streaming them with SHA-256 so memory use stays bounded. Returns the duplicate sets, most wasted space first, with the total bytes taken by redundant copies. Hard links to the same file are not counted as duplicates. .gitignore is honored; symlinks and .git directories are skipped."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path", mcp.Description("Directory to search"), mcp.Required()),
+		mcp.WithString("minSize", mcp.Description("Ignore files smaller than this, in bytes or with a unit suffix (e.g. '500KB', '10MB'); empty files are always ignored")),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Number of duplicate sets to return (default: %d, max: %d)", defaultDuplicateSets, maxDuplicateSets))),
+		mcp.WithArray("patterns", mcp.Description("Glob patterns selecting files, relative to path (default: all files)"), mcp.Items(map[string]any{"type": "string"})),
+		mcp.WithArray("excludePatterns", mcp.Description("Glob patterns to exclude"), mcp.Items(map[string]any{"type": "string"})),
+		mcp.WithBoolean("respectGitignore", mcp.Description("If false, include files ignored by .gitignore (default: true)")),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleFindDuplicates handles the find_duplicates tool.
+func HandleFindDuplicates(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	limit := cast.ToInt(request.Params.Arguments["limit"])
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	if limit <= 0 {
+		limit = defaultDuplicateSets
+	}
+	if limit > maxDuplicateSets {
+		limit = maxDuplicateSets
+	}
+	minSize, err := parseSize(cast.ToString(request.Params.Arguments["minSize"]))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("invalid minSize: %w", err).Error()), nil
+	}
+	minSize = max(minSize, 1)
+
+	filter, err := parseTreeFilter(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	resolvedPath, errResult := resolveDirectory(reg, path)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	report, err := findDuplicates(ctx, reg, resolvedPath, filter, minSize)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to find duplicates: %w", err).Error()), nil
+	}
+	if len(report.Sets) > limit {
+		report.Sets = report.Sets[:limit]
+	}
+
+	if format == "json" {
+		jsonResult, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(jsonResult)), nil
+	}
+
+	if report.TotalSets == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No duplicate files found (%d files scanned)", report.Scanned)), nil
+	}
+	var text strings.Builder
+	fmt.Fprintf(&text, "%d duplicate sets wasting %s (%d files scanned, %d hashed)\n",
+		report.TotalSets, stream.FormatSize(report.Wasted), report.Scanned, report.Hashed)
+	for _, set := range report.Sets {
+		fmt.Fprintf(&text, "\n%d copies of %s, %s wasted (sha256 %s)\n", len(set.Paths), stream.FormatSize(set.Size), stream.FormatSize(set.Wasted), set.SHA256)
+		for _, p := range set.Paths {
+			fmt.Fprintf(&text, "  %s\n", p)
+		}
+	}
+	if len(report.Sets) < report.TotalSets {
+		fmt.Fprintf(&text, "\n... %d more sets not shown\n", report.TotalSets-len(report.Sets))
+	}
+	return mcp.NewToolResultText(text.String()), nil
+}
+
+// findDuplicates walks root and returns every set of files at least minSize
+// bytes with identical content, most wasted space first.
+func findDuplicates(ctx context.Context, reg *registry.Registry, root string, filter treeFilter, minSize int64) (duplicateReport, error) {
+	type candidate struct {
+		path string
+		info fs.FileInfo
+	}
+	report := duplicateReport{Root: root, Sets: []duplicateSet{}}
+
+	// Only files that share a size can be duplicates
+	bySize := make(map[int64][]candidate)
+	err := walkTree(ctx, reg, root, filter, func(walkPath, relPath string, entry fs.DirEntry) error {
+		info, err := entry.Info()
+		if err != nil || info.Size() < minSize {
+			return nil
+		}
+		report.Scanned++
+		bySize[info.Size()] = append(bySize[info.Size()], candidate{walkPath, info})
+		return nil
+	})
+	if err != nil {
+		return duplicateReport{}, err
+	}
+
+	for size, candidates := range bySize {
+		if len(candidates) < 2 {
+			continue
+		}
+		byHash := make(map[string][]candidate)
+		for _, c := range candidates {
+			if err := ctx.Err(); err != nil {
+				return duplicateReport{}, err
+			}
+			source, err := readTarget(reg, c.path)
+			if err != nil {
+				continue
+			}
+			h := sha256.New()
+			if _, err := stream.HashFile(source, h); err != nil {
+				continue
+			}
+			report.Hashed++
+			sum := hex.EncodeToString(h.Sum(nil))
+			byHash[sum] = append(byHash[sum], c)
+		}
+
+		for sum, group := range byHash {
+			set := duplicateSet{SHA256: sum, Size: size}
+			var distinct []fs.FileInfo
+			for _, c := range group {
+				if !containsSameFile(distinct, c.info) {
+					distinct = append(distinct, c.info)
+				}
+				set.Paths = append(set.Paths, c.path)
+			}
+			if len(distinct) < 2 {
+				continue
+			}
+			sort.Strings(set.Paths)
+			set.Wasted = size * int64(len(distinct)-1)
+			report.Sets = append(report.Sets, set)
+			report.Wasted += set.Wasted
+		}
+	}
+
+	sort.Slice(report.Sets, func(i, j int) bool {
+		if report.Sets[i].Wasted != report.Sets[j].Wasted {
+			return report.Sets[i].Wasted > report.Sets[j].Wasted
+		}
+		return report.Sets[i].Paths[0] < report.Sets[j].Paths[0]
+	})
+	report.TotalSets = len(report.Sets)
+	return report, nil
+}
+
+// containsSameFile reports whether info is a hard link to any of files.
+func containsSameFile(files []fs.FileInfo, info fs.FileInfo) bool {
+	for _, f := range files {
+		if os.SameFile(f, info) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHandleFindDuplicates(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	big := strings.Repeat("b", 1000)
+	writePackTree(t, tmpDir, map[string]string{
+		".gitignore":       "ignored/\n",
+		"a.txt":            "same",
+		"dir/a-copy.txt":   "same",
+		"dir/deep/a3.txt":  "same",
+		"diff.txt":         "diff", // same size, different content
+		"big1.bin":         big,
+		"big2.bin":         big,
+		"empty1":           "",
+		"empty2":           "",
+		"ignored/big3.bin": big,
+		"unique.txt":       "unique content",
+	})
+	// A hard link shares its target's space
+	if err := os.Link(filepath.Join(tmpDir, "unique.txt"), filepath.Join(tmpDir, "unique-link.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	result := callTool(t, HandleFindDuplicates, reg, map[string]any{"path": tmpDir, "format": "json"})
+	if result.IsError {
+		t.Fatalf("find_duplicates failed: %s", resultText(result))
+	}
+	var report duplicateReport
+	if err := json.Unmarshal([]byte(resultText(result)), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.TotalSets != 2 || report.Wasted != 1008 || len(report.Sets) != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if set := report.Sets[0]; set.Size != 1000 || len(set.Paths) != 2 || set.Wasted != 1000 {
+		t.Errorf("unexpected first set: %+v", set)
+	}
+	want := []string{filepath.Join(tmpDir, "a.txt"), filepath.Join(tmpDir, "dir", "a-copy.txt"), filepath.Join(tmpDir, "dir", "deep", "a3.txt")}
+	if set := report.Sets[1]; strings.Join(set.Paths, ",") != strings.Join(want, ",") || set.Wasted != 8 {
+		t.Errorf("unexpected second set: %+v", set)
+	}
+	// Only the files sharing a size with another are hashed
+	if report.Hashed != 8 {
+		t.Errorf("hashed = %d, want 8", report.Hashed)
+	}
+
+	result = callTool(t, HandleFindDuplicates, reg, map[string]any{"path": tmpDir, "minSize": "100", "respectGitignore": false, "limit": 1})
+	text := resultText(result)
+	if !strings.Contains(text, "1 duplicate sets wasting 2.0 KB") || !strings.Contains(text, "3 copies of 1000 B") || !strings.Contains(text, "ignored/big3.bin") {
+		t.Errorf("unexpected text result:\n%s", text)
+	}
+
+	result = callTool(t, HandleFindDuplicates, reg, map[string]any{"path": filepath.Join(tmpDir, "dir", "deep")})
+	if text := resultText(result); !strings.HasPrefix(text, "No duplicate files found") {
+		t.Errorf("expected no duplicates, got %q", text)
+	}
+}
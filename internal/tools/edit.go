@@ -11,7 +11,6 @@ import (
 	"github.com/hexops/gotextdiff/span"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/portertech/filesystem-mcp-server/internal/registry"
-	"github.com/portertech/filesystem-mcp-server/internal/security"
 	"github.com/portertech/filesystem-mcp-server/pkg/filesystem"
 	"github.com/spf13/cast"
 )
@@ -36,10 +35,67 @@ func HandleEditFile(ctx context.Context, reg *registry.Registry, request mcp.Cal
 	}
 	dryRun := cast.ToBool(request.Params.Arguments["dryRun"])
 
-	// Parse edits
+	edits := parseEdits(request.Params.Arguments["edits"])
+
+	// Use ValidateFinalPath to reject symlinks - editing through symlinks is a security risk
+	resolvedPath, err := validateFinal(reg, path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+
+	source, err := readTarget(reg, resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to read file: %w", err).Error()), nil
+	}
+
+	// Read original content
+	originalData, err := os.ReadFile(source)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to read file: %w", err).Error()), nil
+	}
+
+	originalContent := string(originalData)
+	newContent, err := applyEdits(originalContent, edits)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// Generate unified diff
+	diff := generateUnifiedDiff(resolvedPath, originalContent, newContent)
+
+	if dryRun {
+		return mcp.NewToolResultText(fmt.Sprintf("Dry run - changes not applied:\n\n%s", diff)), nil
+	}
+
+	// Write the changes atomically
+	info, err := os.Stat(source)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to stat file: %w", err).Error()), nil
+	}
+
+	target, allowedDirs, err := writeTarget(reg, resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to write file: %w", err).Error()), nil
+	}
+
+	op := beginJournal(reg, "edit_file", resolvedPath)
+	op.Save(resolvedPath)
+	if err := atomicWriteFile(target, []byte(newContent), info.Mode().Perm(), allowedDirs); err != nil {
+		op.Discard()
+		return mcp.NewToolResultError(fmt.Errorf("failed to write file: %w", err).Error()), nil
+	}
+	if store := reg.Shadow(); store != nil {
+		store.Record(target, []byte(newContent))
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Successfully edited %s%s\n\n%s", resolvedPath, journalNote(op), diff)), nil
+}
+
+// parseEdits converts the raw edits argument into edit operations.
+func parseEdits(editsArg any) []filesystem.EditOperation {
 	var edits []filesystem.EditOperation
-	if editsArg, ok := request.Params.Arguments["edits"].([]interface{}); ok {
-		for _, e := range editsArg {
+	if arr, ok := editsArg.([]interface{}); ok {
+		for _, e := range arr {
 			if editMap, ok := e.(map[string]interface{}); ok {
 				var requireUnique *bool
 				if val, ok := editMap["requireUnique"]; ok {
@@ -60,26 +116,14 @@ func HandleEditFile(ctx context.Context, reg *registry.Registry, request mcp.Cal
 			}
 		}
 	}
+	return edits
+}
 
-	// Use ValidateFinalPath to reject symlinks - editing through symlinks is a security risk
-	resolvedPath, err := security.ValidateFinalPath(path, reg.Get())
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
-	}
-
-	// Read original content
-	originalData, err := os.ReadFile(resolvedPath)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Errorf("failed to read file: %w", err).Error()), nil
-	}
-
-	originalContent := string(originalData)
-	newContent := originalContent
-
-	// Apply edits sequentially
+// applyEdits applies edits sequentially to content and returns the result.
+func applyEdits(content string, edits []filesystem.EditOperation) (string, error) {
 	for i, edit := range edits {
 		if edit.OldText == "" {
-			return mcp.NewToolResultError(fmt.Sprintf("edit %d: oldText cannot be empty", i+1)), nil
+			return "", fmt.Errorf("edit %d: oldText cannot be empty", i+1)
 		}
 
 		requireUnique := true
@@ -88,12 +132,12 @@ func HandleEditFile(ctx context.Context, reg *registry.Registry, request mcp.Cal
 		}
 
 		if edit.Occurrence != nil && *edit.Occurrence < 1 {
-			return mcp.NewToolResultError(fmt.Sprintf("edit %d: occurrence must be >= 1", i+1)), nil
+			return "", fmt.Errorf("edit %d: occurrence must be >= 1", i+1)
 		}
 
-		matchInfo, matchErr := findMatch(newContent, edit.OldText, requireUnique)
+		matchInfo, matchErr := findMatch(content, edit.OldText, requireUnique)
 		if matchErr != nil {
-			return mcp.NewToolResultError(fmt.Errorf("edit %d: %w", i+1, matchErr).Error()), nil
+			return "", fmt.Errorf("edit %d: %w", i+1, matchErr)
 		}
 
 		occurrence := 1
@@ -101,30 +145,12 @@ func HandleEditFile(ctx context.Context, reg *registry.Registry, request mcp.Cal
 			occurrence = *edit.Occurrence
 		}
 		if occurrence > len(matchInfo.Matches) {
-			return mcp.NewToolResultError(fmt.Sprintf("edit %d: occurrence %d out of range", i+1, occurrence)), nil
+			return "", fmt.Errorf("edit %d: occurrence %d out of range", i+1, occurrence)
 		}
 
-		newContent = applyMatch(newContent, edit.OldText, edit.NewText, matchInfo, occurrence)
+		content = applyMatch(content, edit.OldText, edit.NewText, matchInfo, occurrence)
 	}
-
-	// Generate unified diff
-	diff := generateUnifiedDiff(resolvedPath, originalContent, newContent)
-
-	if dryRun {
-		return mcp.NewToolResultText(fmt.Sprintf("Dry run - changes not applied:\n\n%s", diff)), nil
-	}
-
-	// Write the changes atomically
-	info, err := os.Stat(resolvedPath)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Errorf("failed to stat file: %w", err).Error()), nil
-	}
-
-	if err := atomicWriteFile(resolvedPath, []byte(newContent), info.Mode().Perm(), reg.Get()); err != nil {
-		return mcp.NewToolResultError(fmt.Errorf("failed to write file: %w", err).Error()), nil
-	}
-
-	return mcp.NewToolResultText(fmt.Sprintf("Successfully edited %s\n\n%s", resolvedPath, diff)), nil
+	return content, nil
 }
 
 // normalizeWhitespace normalizes whitespace in text for fuzzy matching.
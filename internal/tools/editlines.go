@@ -0,0 +1,231 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/spf13/cast"
+)
+
+// lineOperation is one line-addressed edit of edit_lines. Line numbers are
+// 1-based and refer to the file as it was before any operation.
+type lineOperation struct {
+	Type    string
+	Line    int
+	EndLine int
+	Text    string
+}
+
+// NewEditLinesTool creates the edit_lines tool.
+func NewEditLinesTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"edit_lines",
+		mcp.WithDescription("Edit a text file by line number: insert lines before or after a line, or replace or delete a range of lines. Line numbers are 1-based, as in read_text_file's line-numbered output, and all refer to the file before the edit, so several operations can be given without recounting. Returns a unified diff."),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithString("path", mcp.Description("Path to the file to edit"), mcp.Required()),
+		mcp.WithArray("operations", mcp.Description("Operations, each with type ('insert_before', 'insert_after', 'replace_range', or 'delete_range'), line, endLine for ranges (default: line), and text for inserts and replacements. insert_after with line 0 inserts at the start of the file"), mcp.Required(), mcp.Items(map[string]any{"type": "object"})),
+		mcp.WithBoolean("dryRun", mcp.Description("If true, preview changes without writing")),
+	)
+}
+
+// HandleEditLines handles the edit_lines tool.
+func HandleEditLines(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	dryRun := cast.ToBool(request.Params.Arguments["dryRun"])
+
+	ops, err := parseLineOperations(request.Params.Arguments["operations"])
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	resolvedPath, err := validateFinal(reg, path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	source, err := readTarget(reg, resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to read file: %w", err).Error()), nil
+	}
+	info, err := os.Stat(source)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to stat file: %w", err).Error()), nil
+	}
+	if info.IsDir() {
+		return mcp.NewToolResultError("path is a directory, not a file"), nil
+	}
+	originalData, err := os.ReadFile(source)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to read file: %w", err).Error()), nil
+	}
+	if looksBinary(originalData) {
+		return mcp.NewToolResultError("cannot edit a binary file by line"), nil
+	}
+
+	originalContent := string(originalData)
+	newContent, err := applyLineOperations(originalContent, ops)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	diff := generateUnifiedDiff(resolvedPath, originalContent, newContent)
+	if dryRun {
+		return mcp.NewToolResultText(fmt.Sprintf("Dry run - changes not applied:\n\n%s", diff)), nil
+	}
+
+	target, allowedDirs, err := writeTarget(reg, resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to write file: %w", err).Error()), nil
+	}
+	if err := atomicWriteFile(target, []byte(newContent), info.Mode().Perm(), allowedDirs); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to write file: %w", err).Error()), nil
+	}
+	if store := reg.Shadow(); store != nil {
+		store.Record(target, []byte(newContent))
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Successfully edited %s\n\n%s", resolvedPath, diff)), nil
+}
+
+// parseLineOperations converts the raw operations argument and checks each
+// operation on its own.
+func parseLineOperations(arg any) ([]lineOperation, error) {
+	arr, ok := arg.([]interface{})
+	if !ok || len(arr) == 0 {
+		return nil, fmt.Errorf("operations must be a non-empty array")
+	}
+	ops := make([]lineOperation, 0, len(arr))
+	for i, v := range arr {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("operation %d: must be an object", i+1)
+		}
+		op := lineOperation{
+			Type: cast.ToString(m["type"]),
+			Line: cast.ToInt(m["line"]),
+			Text: cast.ToString(m["text"]),
+		}
+		if _, ok := m["line"]; !ok {
+			return nil, fmt.Errorf("operation %d: line is required", i+1)
+		}
+		switch op.Type {
+		case "insert_before", "insert_after":
+			if _, ok := m["text"]; !ok {
+				return nil, fmt.Errorf("operation %d: %s needs text", i+1, op.Type)
+			}
+		case "replace_range", "delete_range":
+			op.EndLine = op.Line
+			if v, ok := m["endLine"]; ok {
+				op.EndLine = cast.ToInt(v)
+			}
+			if op.EndLine < op.Line {
+				return nil, fmt.Errorf("operation %d: endLine %d is before line %d", i+1, op.EndLine, op.Line)
+			}
+			if _, ok := m["text"]; op.Type == "replace_range" && !ok {
+				return nil, fmt.Errorf("operation %d: replace_range needs text", i+1)
+			}
+		default:
+			return nil, fmt.Errorf("operation %d: unknown type %q; expected insert_before, insert_after, replace_range, or delete_range", i+1, op.Type)
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// applyLineOperations applies ops, all addressed by the line numbers of
+// content, and returns the result. Ranges may not overlap, and nothing may
+// be inserted inside a range being replaced or deleted. Inserted lines take
+// the dominant line ending of content.
+func applyLineOperations(content string, ops []lineOperation) (string, error) {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	n := len(lines)
+	eol := "\n"
+	crlf, terminated := 0, 0
+	for _, l := range lines {
+		if strings.HasSuffix(l, "\n") {
+			terminated++
+		}
+		if strings.HasSuffix(l, "\r\n") {
+			crlf++
+		}
+	}
+	if crlf*2 > terminated {
+		eol = "\r\n"
+	}
+	toLines := func(text string) []string {
+		if text == "" {
+			return nil
+		}
+		parts := strings.SplitAfter(strings.TrimSuffix(text, "\n"), "\n")
+		for i, p := range parts {
+			parts[i] = strings.TrimRight(p, "\r\n") + eol
+		}
+		return parts
+	}
+
+	// inserts[i] goes before original line i+1 (0-based line i); removed
+	// lines are dropped, and a range's replacement goes where it started
+	inserts := make([][]string, n+1)
+	removed := make([]int, n)
+	replacements := make(map[int][]string)
+	for i, op := range ops {
+		switch op.Type {
+		case "insert_before":
+			if op.Line < 1 || op.Line > n+1 {
+				return "", fmt.Errorf("operation %d: line %d is out of range 1-%d", i+1, op.Line, n+1)
+			}
+			inserts[op.Line-1] = append(inserts[op.Line-1], toLines(op.Text)...)
+		case "insert_after":
+			if op.Line < 0 || op.Line > n {
+				return "", fmt.Errorf("operation %d: line %d is out of range 0-%d", i+1, op.Line, n)
+			}
+			inserts[op.Line] = append(inserts[op.Line], toLines(op.Text)...)
+		default:
+			if op.Line < 1 || op.EndLine > n {
+				return "", fmt.Errorf("operation %d: lines %d-%d are out of range 1-%d", i+1, op.Line, op.EndLine, n)
+			}
+			for l := op.Line - 1; l < op.EndLine; l++ {
+				if removed[l] != 0 {
+					return "", fmt.Errorf("operation %d: lines %d-%d overlap operation %d", i+1, op.Line, op.EndLine, removed[l])
+				}
+				removed[l] = i + 1
+			}
+			if op.Type == "replace_range" {
+				replacements[op.Line-1] = toLines(op.Text)
+			}
+		}
+	}
+	for i := 1; i < n; i++ {
+		if len(inserts[i]) > 0 && removed[i-1] != 0 && removed[i-1] == removed[i] {
+			return "", fmt.Errorf("cannot insert between lines %d and %d, inside the range of operation %d", i, i+1, removed[i])
+		}
+	}
+
+	var out []string
+	emit := func(ls ...string) {
+		for _, l := range ls {
+			// A last line without a newline needs one before more lines
+			if k := len(out); k > 0 && !strings.HasSuffix(out[k-1], "\n") {
+				out[k-1] += eol
+			}
+			out = append(out, l)
+		}
+	}
+	for i := 0; i <= n; i++ {
+		emit(inserts[i]...)
+		if i == n {
+			break
+		}
+		emit(replacements[i]...)
+		if removed[i] == 0 {
+			emit(lines[i])
+		}
+	}
+	return strings.Join(out, ""), nil
+}
@@ -0,0 +1,118 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyLineOperations(t *testing.T) {
+	original := "one\ntwo\nthree\nfour\nfive\n"
+	tests := []struct {
+		name    string
+		content string
+		ops     []lineOperation
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "insert before and after",
+			ops: []lineOperation{
+				{Type: "insert_before", Line: 1, Text: "zero"},
+				{Type: "insert_after", Line: 5, Text: "six\nseven\n"},
+			},
+			want: "zero\none\ntwo\nthree\nfour\nfive\nsix\nseven\n",
+		},
+		{
+			name: "numbers refer to the original file",
+			ops: []lineOperation{
+				{Type: "delete_range", Line: 1, EndLine: 2},
+				{Type: "replace_range", Line: 4, EndLine: 4, Text: "FOUR"},
+				{Type: "insert_after", Line: 4, Text: "after four"},
+			},
+			want: "three\nFOUR\nafter four\nfive\n",
+		},
+		{
+			name: "insert at the start",
+			ops:  []lineOperation{{Type: "insert_after", Line: 0, Text: "first"}},
+			want: "first\none\ntwo\nthree\nfour\nfive\n",
+		},
+		{
+			name:    "keeps CRLF and adds a missing final newline",
+			content: "a\r\nb",
+			ops:     []lineOperation{{Type: "insert_after", Line: 2, Text: "c"}},
+			want:    "a\r\nb\r\nc\r\n",
+		},
+		{
+			name:    "overlapping ranges",
+			ops:     []lineOperation{{Type: "delete_range", Line: 1, EndLine: 3}, {Type: "replace_range", Line: 3, EndLine: 4, Text: "x"}},
+			wantErr: true,
+		},
+		{
+			name:    "insert inside a deleted range",
+			ops:     []lineOperation{{Type: "delete_range", Line: 1, EndLine: 3}, {Type: "insert_after", Line: 2, Text: "x"}},
+			wantErr: true,
+		},
+		{
+			name:    "out of range",
+			ops:     []lineOperation{{Type: "replace_range", Line: 5, EndLine: 6, Text: "x"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := tt.content
+			if content == "" {
+				content = original
+			}
+			got, err := applyLineOperations(content, tt.ops)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleEditLines(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	path := filepath.Join(tmpDir, "test.txt")
+	os.WriteFile(path, []byte("one\ntwo\nthree\n"), 0644)
+
+	ops := []any{map[string]any{"type": "replace_range", "line": 2, "text": "TWO"}}
+	result := callTool(t, HandleEditLines, reg, map[string]any{"path": path, "operations": ops, "dryRun": true})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if text := resultText(result); !strings.Contains(text, "Dry run") || !strings.Contains(text, "+TWO") {
+		t.Errorf("unexpected dry run output:\n%s", text)
+	}
+
+	result = callTool(t, HandleEditLines, reg, map[string]any{"path": path, "operations": ops})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if data, _ := os.ReadFile(path); string(data) != "one\nTWO\nthree\n" {
+		t.Errorf("unexpected content %q", data)
+	}
+
+	for name, ops := range map[string]any{
+		"empty":        []any{},
+		"unknown type": []any{map[string]any{"type": "move", "line": 1}},
+		"missing text": []any{map[string]any{"type": "insert_after", "line": 1}},
+		"reversed":     []any{map[string]any{"type": "delete_range", "line": 3, "endLine": 2}},
+	} {
+		if result := callTool(t, HandleEditLines, reg, map[string]any{"path": path, "operations": ops}); !result.IsError {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/spf13/cast"
+)
+
+// encodingSampleLen is how much of a file detect_encoding looks at.
+const encodingSampleLen = 64 * 1024
+
+var (
+	utf16LEBOM = []byte{0xFF, 0xFE}
+	utf16BEBOM = []byte{0xFE, 0xFF}
+)
+
+// encodingReport is the result of detect_encoding.
+type encodingReport struct {
+	Path string `json:"path"`
+	// Encoding is ascii, utf-8, utf-16le, utf-16be, windows-1252,
+	// iso-8859-1, or binary.
+	Encoding string `json:"encoding"`
+	// Confidence is high, medium, or low.
+	Confidence string `json:"confidence"`
+	BOM        bool   `json:"bom"`
+	Size       int64  `json:"size"`
+	Sampled    int    `json:"sampledBytes"`
+}
+
+// NewDetectEncodingTool creates the detect_encoding tool.
+func NewDetectEncodingTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"detect_encoding",
+		mcp.WithDescription("Detect the character encoding of a text file: ASCII, UTF-8, UTF-16LE or UTF-16BE, or a Latin-1 family encoding (Windows-1252 or ISO-8859-1), with a confidence and whether the file starts with a byte order mark. Check this before read_text_file on files from unknown sources, and pass the encoding found to its encoding parameter. Only the first 64KB is examined."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path", mcp.Description("Path to the file"), mcp.Required()),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleDetectEncoding handles the detect_encoding tool.
+func HandleDetectEncoding(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	resolvedPath, err := validateRead(reg, path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	info, err := os.Stat(resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to stat file: %w", err).Error()), nil
+	}
+	if info.IsDir() {
+		return mcp.NewToolResultError("path is a directory, not a file"), nil
+	}
+	head, err := readHead(resolvedPath, encodingSampleLen)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to read file: %w", err).Error()), nil
+	}
+
+	report := detectEncoding(head, int64(len(head)) < info.Size())
+	report.Path = resolvedPath
+	report.Size = info.Size()
+	report.Sampled = len(head)
+
+	if format == "json" {
+		jsonResult, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(jsonResult)), nil
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "%s: %s", report.Path, report.Encoding)
+	if report.BOM {
+		text.WriteString(" with BOM")
+	}
+	fmt.Fprintf(&text, " (confidence: %s)\n", report.Confidence)
+	if int64(report.Sampled) < report.Size {
+		fmt.Fprintf(&text, "Detected from the first %d of %d bytes\n", report.Sampled, report.Size)
+	}
+	switch report.Encoding {
+	case "ascii", "utf-8", "binary":
+	default:
+		fmt.Fprintf(&text, "This is not UTF-8; read it with read_text_file encoding=%s\n", report.Encoding)
+	}
+	return mcp.NewToolResultText(text.String()), nil
+}
+
+// detectEncoding guesses the encoding of data, the start of a file, or
+// all of it if truncated is false. A byte order mark is trusted. Without
+// one, valid UTF-8 wins over the Latin-1 family, since Latin-1 text with
+// any accented letters is almost never valid UTF-8.
+func detectEncoding(data []byte, truncated bool) encodingReport {
+	switch {
+	case bytes.HasPrefix(data, utf8BOM):
+		return encodingReport{Encoding: "utf-8", Confidence: "high", BOM: true}
+	case bytes.HasPrefix(data, utf16LEBOM):
+		return encodingReport{Encoding: "utf-16le", Confidence: "high", BOM: true}
+	case bytes.HasPrefix(data, utf16BEBOM):
+		return encodingReport{Encoding: "utf-16be", Confidence: "high", BOM: true}
+	}
+
+	if looksBinary(data) {
+		if enc := guessUTF16(data); enc != "" {
+			return encodingReport{Encoding: enc, Confidence: "medium"}
+		}
+		return encodingReport{Encoding: "binary", Confidence: "high"}
+	}
+	high := 0
+	for _, b := range data {
+		if b >= 0x80 {
+			high++
+		}
+	}
+	if high == 0 {
+		return encodingReport{Encoding: "ascii", Confidence: "high"}
+	}
+	if isText(data, truncated) {
+		return encodingReport{Encoding: "utf-8", Confidence: "high"}
+	}
+	return guessLatin1(data, high)
+}
+
+// guessUTF16 recognizes UTF-16 text without a BOM by its NUL bytes, which
+// mostly fall on odd offsets for little-endian ASCII text and on even
+// offsets for big-endian. It returns "" if neither pattern holds.
+func guessUTF16(data []byte) string {
+	var even, odd int
+	for i, b := range data {
+		if b != 0 {
+			continue
+		}
+		if i%2 == 0 {
+			even++
+		} else {
+			odd++
+		}
+	}
+	pairs := len(data) / 2
+	switch {
+	case pairs == 0:
+		return ""
+	case odd*10 >= pairs*7 && even*10 < pairs:
+		return "utf-16le"
+	case even*10 >= pairs*7 && odd*10 < pairs:
+		return "utf-16be"
+	}
+	return ""
+}
+
+// guessLatin1 picks between Windows-1252 and ISO-8859-1 for data that is
+// not UTF-8, given its number of bytes at or above 0x80. Bytes 0x80-0x9F
+// are printable in Windows-1252, such as curly quotes and dashes, but
+// control characters in ISO-8859-1. Confidence is medium when most high
+// bytes look like letters next to ASCII letters or common punctuation,
+// and low otherwise.
+func guessLatin1(data []byte, high int) encodingReport {
+	report := encodingReport{Encoding: "iso-8859-1", Confidence: "low"}
+	plausible := 0
+	c1, undefined := 0, 0
+	for i, b := range data {
+		switch {
+		case b < 0x80:
+			continue
+		case b <= 0x9F:
+			c1++
+			switch b {
+			case 0x81, 0x8D, 0x8F, 0x90, 0x9D:
+				undefined++
+			case 0x85, 0x91, 0x92, 0x93, 0x94, 0x96, 0x97:
+				plausible++
+			}
+		case b >= 0xC0 && b != 0xD7 && b != 0xF7:
+			if (i > 0 && isASCIILetter(data[i-1])) || (i+1 < len(data) && isASCIILetter(data[i+1])) {
+				plausible++
+			}
+		default:
+			// Symbols such as ©, °, and the no-break space
+			plausible++
+		}
+	}
+	if c1 > 0 && undefined == 0 {
+		report.Encoding = "windows-1252"
+	}
+	if plausible*10 >= high*9 {
+		report.Confidence = "medium"
+	}
+	return report
+}
+
+func isASCIILetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectEncoding(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		encoding   string
+		confidence string
+		bom        bool
+	}{
+		{"ascii", "hello\n", "ascii", "high", false},
+		{"utf-8", "café\n", "utf-8", "high", false},
+		{"utf-8 with BOM", "\xEF\xBB\xBFhi", "utf-8", "high", true},
+		{"utf-16le with BOM", "\xFF\xFEh\x00i\x00", "utf-16le", "high", true},
+		{"utf-16be with BOM", "\xFE\xFF\x00h\x00i", "utf-16be", "high", true},
+		{"utf-16le without BOM", "h\x00e\x00l\x00l\x00o\x00", "utf-16le", "medium", false},
+		{"binary", "\x89PNG\x00\x00\x01\x02\x00\x00\x00\x00", "binary", "high", false},
+		{"latin-1", "caf\xe9 cr\xe8me\n", "iso-8859-1", "medium", false},
+		{"windows-1252", "\x93quoted\x94 \x96 caf\xe9\n", "windows-1252", "medium", false},
+		{"unlikely latin-1", "\xe9\xe8\xe0 \xfc", "iso-8859-1", "low", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := detectEncoding([]byte(tt.data), false)
+			if got.Encoding != tt.encoding || got.Confidence != tt.confidence || got.BOM != tt.bom {
+				t.Errorf("detectEncoding() = %+v, want %s/%s bom=%v", got, tt.encoding, tt.confidence, tt.bom)
+			}
+		})
+	}
+}
+
+func TestDetectEncodingTruncatedUTF8(t *testing.T) {
+	// A multibyte rune cut off by the sample is not a sign of Latin-1
+	data := []byte("café")
+	if got := detectEncoding(data[:len(data)-1], true); got.Encoding != "utf-8" {
+		t.Errorf("detectEncoding() = %+v, want utf-8", got)
+	}
+}
+
+func TestHandleDetectEncoding(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	path := filepath.Join(tmpDir, "legacy.txt")
+	os.WriteFile(path, []byte("r\xe9sum\xe9\n"), 0644)
+
+	result := callTool(t, HandleDetectEncoding, reg, map[string]any{"path": path})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	text := resultText(result)
+	if !strings.Contains(text, "iso-8859-1 (confidence: medium)") || !strings.Contains(text, "read_text_file") {
+		t.Errorf("unexpected output:\n%s", text)
+	}
+
+	result = callTool(t, HandleDetectEncoding, reg, map[string]any{"path": path, "format": "json"})
+	if text := resultText(result); !strings.Contains(text, `"sampledBytes": 7`) {
+		t.Errorf("unexpected JSON output:\n%s", text)
+	}
+	if result := callTool(t, HandleDetectEncoding, reg, map[string]any{"path": tmpDir}); !result.IsError {
+		t.Error("expected an error for a directory")
+	}
+}
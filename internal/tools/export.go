@@ -0,0 +1,187 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/oplog"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/spf13/cast"
+)
+
+// operationsExport is the result of export_operations in json format.
+type operationsExport struct {
+	oplog.Plan
+	// Dropped counts operations too old to still be in the log.
+	Dropped int `json:"dropped,omitempty"`
+	// Failed lists the calls that failed, when requested. They are not
+	// part of the plan.
+	Failed []oplog.Operation `json:"failed,omitempty"`
+}
+
+// NewExportOperationsTool creates the export_operations tool.
+func NewExportOperationsTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"export_operations",
+		mcp.WithDescription("Export the file-changing tool calls made in this session, oldest first, for review or replay elsewhere. The json format is a plan listing each tool and its arguments; the shell format renders the calls as commands where there is an equivalent and as comments otherwise."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("format", mcp.Description("Output format: 'json' (default) or 'shell'")),
+		mcp.WithNumber("since", mcp.Description("Only export operations after this sequence number")),
+		mcp.WithBoolean("includeFailed", mcp.Description("Also list calls that failed (default: false)")),
+	)
+}
+
+// HandleExportOperations handles the export_operations tool.
+func HandleExportOperations(ctx context.Context, reg *registry.Registry, log *oplog.Log, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	format := cast.ToString(request.Params.Arguments["format"])
+	since := cast.ToInt(request.Params.Arguments["since"])
+	includeFailed := cast.ToBool(request.Params.Arguments["includeFailed"])
+
+	all, dropped := log.Operations()
+	var ops, failed []oplog.Operation
+	for _, op := range all {
+		switch {
+		case op.Seq <= since:
+		case op.Error == "":
+			ops = append(ops, op)
+		case includeFailed:
+			failed = append(failed, op)
+		}
+	}
+
+	switch format {
+	case "", "json":
+		export := operationsExport{Plan: oplog.ToPlan(ops), Dropped: dropped, Failed: failed}
+		jsonResult, err := json.MarshalIndent(export, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(jsonResult)), nil
+	case "shell":
+		return mcp.NewToolResultText(formatShell(ops, failed, dropped)), nil
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("invalid format %q: expected json or shell", format)), nil
+	}
+}
+
+// formatShell renders operations as a shell script, in sequence order.
+func formatShell(ops, failed []oplog.Operation, dropped int) string {
+	all := append(append([]oplog.Operation(nil), ops...), failed...)
+	sort.Slice(all, func(i, j int) bool { return all[i].Seq < all[j].Seq })
+
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	b.WriteString("# Operations exported by filesystem-mcp-server. Calls without a shell\n")
+	b.WriteString("# equivalent are listed as comments; use the json format to replay them.\n")
+	b.WriteString("set -e\n")
+	if dropped > 0 {
+		fmt.Fprintf(&b, "# %d earlier operations are no longer in the log\n", dropped)
+	}
+	for _, op := range all {
+		fmt.Fprintf(&b, "\n# %d %s %s\n", op.Seq, op.Time.UTC().Format(time.RFC3339), op.Tool)
+		if op.Error != "" {
+			fmt.Fprintf(&b, "# FAILED: %s\n", strings.ReplaceAll(op.Error, "\n", " "))
+			b.WriteString(commentOut(shellCommand(op.Step)))
+			continue
+		}
+		b.WriteString(shellCommand(op.Step))
+	}
+	return b.String()
+}
+
+// shellCommand renders a step as a shell command, or as a comment holding
+// its arguments when the tool has no shell equivalent.
+func shellCommand(step oplog.Step) string {
+	arg := func(name string) string {
+		return shellQuote(cast.ToString(step.Arguments[name]))
+	}
+	switch step.Tool {
+	case "write_file":
+		// printf writes UTF-8 without a byte order mark, so only that
+		// translates directly
+		if enc := strings.ToLower(cast.ToString(step.Arguments["encoding"])); (enc != "" && textEncodingAliases[enc] != "utf-8") || cast.ToBool(step.Arguments["bom"]) {
+			break
+		}
+		content := cast.ToString(step.Arguments["content"])
+		if subs, err := parseSubstitutions(step.Arguments["substitutions"]); err == nil && subs != nil {
+			content, _, _ = substitute(content, subs)
+		}
+		return fmt.Sprintf("mkdir -p %s\nprintf '%%s' %s > %s\n", shellQuote(filepath.Dir(cast.ToString(step.Arguments["path"]))), shellQuote(content), arg("path"))
+	case "write_media_file":
+		data := cast.ToString(step.Arguments["data"])
+		if _, payload, ok := strings.Cut(data, ","); ok && strings.HasPrefix(data, "data:") {
+			data = payload
+		}
+		return fmt.Sprintf("mkdir -p %s\nprintf '%%s' %s | base64 -d > %s\n", shellQuote(filepath.Dir(cast.ToString(step.Arguments["path"]))), shellQuote(data), arg("path"))
+	case "write_file_range":
+		data := cast.ToString(step.Arguments["data"])
+		if cast.ToString(step.Arguments["encoding"]) == "hex" {
+			decoded, err := hex.DecodeString(strings.Join(strings.Fields(data), ""))
+			if err != nil {
+				break
+			}
+			data = base64.StdEncoding.EncodeToString(decoded)
+		}
+		return fmt.Sprintf("printf '%%s' %s | base64 -d | dd of=%s bs=1 seek=%d conv=notrunc 2>/dev/null\n", shellQuote(data), arg("path"), cast.ToInt64(step.Arguments["offset"]))
+	case "touch_file":
+		if ts := cast.ToString(step.Arguments["timestamp"]); ts != "" {
+			return fmt.Sprintf("touch -d %s %s\n", shellQuote(ts), arg("path"))
+		}
+		return fmt.Sprintf("touch %s\n", arg("path"))
+	case "change_owner":
+		ownership := shellQuote(describeOwnership(cast.ToString(step.Arguments["owner"]), cast.ToString(step.Arguments["group"])))
+		if cast.ToBool(step.Arguments["recursive"]) {
+			return fmt.Sprintf("chown -R -h -- %s %s\n", ownership, arg("path"))
+		}
+		return fmt.Sprintf("chown -- %s %s\n", ownership, arg("path"))
+	case "create_symlink":
+		return fmt.Sprintf("ln -s -- %s %s\n", arg("target"), arg("path"))
+	case "create_directory":
+		return fmt.Sprintf("mkdir -p %s\n", arg("path"))
+	case "delete_file":
+		return fmt.Sprintf("rm -- %s\n", arg("path"))
+	case "delete_directory":
+		if cast.ToBool(step.Arguments["recursive"]) {
+			return fmt.Sprintf("rm -r -- %s\n", arg("path"))
+		}
+		return fmt.Sprintf("rmdir -- %s\n", arg("path"))
+	case "move_file":
+		return fmt.Sprintf("mv -- %s %s\n", arg("source"), arg("destination"))
+	case "copy_file":
+		if cast.ToBool(step.Arguments["overwrite"]) {
+			return fmt.Sprintf("cp -- %s %s\n", arg("source"), arg("destination"))
+		}
+		return fmt.Sprintf("cp -n -- %s %s\n", arg("source"), arg("destination"))
+	case "copy_directory":
+		// cp -R copies symlinks as links and ignores excludePatterns, so
+		// only a plain copy translates directly
+		if cast.ToString(step.Arguments["symlinks"]) != "fail" || len(cast.ToStringSlice(step.Arguments["excludePatterns"])) > 0 {
+			break
+		}
+		if cast.ToBool(step.Arguments["overwrite"]) {
+			return fmt.Sprintf("mkdir -p %s\ncp -R -p -- %s/. %s\n", arg("destination"), arg("source"), arg("destination"))
+		}
+		return fmt.Sprintf("cp -R -p -- %s %s\n", arg("source"), arg("destination"))
+	}
+	args, _ := json.Marshal(step.Arguments)
+	return commentOut(fmt.Sprintf("%s %s\n", step.Tool, args))
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// commentOut prefixes every line of s with "# ".
+func commentOut(s string) string {
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	return "# " + strings.Join(lines, "\n# ") + "\n"
+}
@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/oplog"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+)
+
+func TestHandleExportOperations(t *testing.T) {
+	reg, _ := setupTestRegistry(t)
+	log := oplog.New(oplog.DefaultMaxOperations)
+	log.Record("write_file", map[string]any{"path": "/w/it's.txt", "content": "a\nb"}, "")
+	log.Record("move_file", map[string]any{"source": "/w/a", "destination": "/w/b"}, "source not found")
+	log.Record("delete_directory", map[string]any{"path": "/w/d", "recursive": true}, "")
+	log.Record("set_annotation", map[string]any{"path": "/w/x", "note": "hi"}, "")
+
+	call := func(args map[string]any) string {
+		t.Helper()
+		result := callTool(t, func(ctx context.Context, reg *registry.Registry, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return HandleExportOperations(ctx, reg, log, req)
+		}, reg, args)
+		if result.IsError {
+			t.Fatalf("export failed: %s", resultText(result))
+		}
+		return resultText(result)
+	}
+
+	var export struct {
+		oplog.Plan
+		Failed []oplog.Operation `json:"failed"`
+	}
+	if err := json.Unmarshal([]byte(call(map[string]any{})), &export); err != nil {
+		t.Fatal(err)
+	}
+	if len(export.Steps) != 3 || export.Steps[0].Tool != "write_file" || len(export.Failed) != 0 {
+		t.Errorf("expected the 3 successful calls, got %+v", export)
+	}
+
+	if err := json.Unmarshal([]byte(call(map[string]any{"since": 2, "includeFailed": true})), &export); err != nil {
+		t.Fatal(err)
+	}
+	if len(export.Steps) != 2 || export.Steps[0].Tool != "delete_directory" || len(export.Failed) != 0 {
+		t.Errorf("expected calls after 2, got %+v", export)
+	}
+
+	script := call(map[string]any{"format": "shell", "includeFailed": true})
+	for _, want := range []string{
+		`printf '%s' 'a` + "\nb' > '/w/it'\\''s.txt'",
+		"# FAILED: source not found\n# mv -- '/w/a' '/w/b'",
+		"rm -r -- '/w/d'",
+		`# set_annotation {"note":"hi","path":"/w/x"}`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("expected script to contain %q, got:\n%s", want, script)
+		}
+	}
+}
+
+func TestShellCommandWriteMediaFile(t *testing.T) {
+	got := shellCommand(oplog.Step{Tool: "write_media_file", Arguments: map[string]any{"path": "/w/a.png", "data": "data:image/png;base64,iVBORw0K"}})
+	if want := "mkdir -p '/w'\nprintf '%s' 'iVBORw0K' | base64 -d > '/w/a.png'\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestShellCommandWriteFileRange(t *testing.T) {
+	got := shellCommand(oplog.Step{Tool: "write_file_range", Arguments: map[string]any{"path": "/w/a.dat", "offset": 4, "data": "62 62", "encoding": "hex"}})
+	if want := "printf '%s' 'YmI=' | base64 -d | dd of='/w/a.dat' bs=1 seek=4 conv=notrunc 2>/dev/null\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestShellCommandCopyDirectory(t *testing.T) {
+	got := shellCommand(oplog.Step{Tool: "copy_directory", Arguments: map[string]any{"source": "/w/a", "destination": "/w/b", "symlinks": "fail"}})
+	if want := "cp -R -p -- '/w/a' '/w/b'\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	got = shellCommand(oplog.Step{Tool: "copy_directory", Arguments: map[string]any{"source": "/w/a", "destination": "/w/b"}})
+	if !strings.HasPrefix(got, "# copy_directory ") {
+		t.Errorf("expected a copy that skips symlinks to be commented out, got %q", got)
+	}
+}
+
+func TestShellCommandWriteFileEncoding(t *testing.T) {
+	got := shellCommand(oplog.Step{Tool: "write_file", Arguments: map[string]any{"path": "/w/a.txt", "content": "x", "encoding": "utf-16le"}})
+	if !strings.HasPrefix(got, "# write_file ") {
+		t.Errorf("expected a UTF-16 write to be commented out, got %q", got)
+	}
+}
@@ -0,0 +1,133 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/buffer"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/security"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/spf13/cast"
+)
+
+// NewExtractRegionTool creates the extract_region tool.
+func NewExtractRegionTool(reg *registry.Registry) mcp.Tool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Copy a line range or byte range of a file into a new file, or into a buffer for paste_from_buffer, without passing the content through the conversation. Useful for splitting a large file into modules. The region is streamed, so it may be any size when written to a file. The source file is not changed."),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Extract Region",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(true),
+			IdempotentHint:  boolPtr(true),
+		}),
+		mcp.WithString("path", mcp.Description("Path to the file to extract from"), mcp.Required()),
+		mcp.WithString("destination", mcp.Description("Path of the file to write the region to; parent directories are created")),
+		mcp.WithString("buffer", mcp.Description("Name of a buffer to copy the region into instead of a file")),
+		mcp.WithBoolean("overwrite", mcp.Description("Replace the destination if it exists (default: false)")),
+	}
+	return mcp.NewTool("extract_region", append(opts, withRegionParams()...)...)
+}
+
+// HandleExtractRegion handles the extract_region tool.
+func HandleExtractRegion(ctx context.Context, reg *registry.Registry, store *buffer.Store, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	destination := cast.ToString(request.Params.Arguments["destination"])
+	name := cast.ToString(request.Params.Arguments["buffer"])
+	overwrite := cast.ToBool(request.Params.Arguments["overwrite"])
+
+	if (destination == "") == (name == "") {
+		return mcp.NewToolResultError("exactly one of destination and buffer is required"), nil
+	}
+	region, err := parseRegion(request.Params.Arguments)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if region == (fileRegion{startLine: 1}) {
+		return mcp.NewToolResultError("a region is required: startLine/endLine or offset/length; use copy_file to copy a whole file"), nil
+	}
+
+	resolvedSrc, err := validateRead(reg, path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("source path validation failed: %w", err).Error()), nil
+	}
+	if info, err := os.Stat(resolvedSrc); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to stat source: %w", err).Error()), nil
+	} else if info.IsDir() {
+		return mcp.NewToolResultError("source is a directory, not a file"), nil
+	}
+
+	if name != "" {
+		content, described, err := readRegion(resolvedSrc, region, store.MaxBytes())
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to read region: %w", err).Error()), nil
+		}
+		b, err := store.Put(name, content, fmt.Sprintf("%s of %s", described, resolvedSrc))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Extracted %s (%d bytes) to buffer %q; it expires at %s", b.Source, len(content), name, b.Expires.Format(time.RFC3339))), nil
+	}
+
+	resolvedDst, target, err := prepareDestination(reg, destination, overwrite)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var written int64
+	var described string
+	err = stream.WriteFileStreaming(target, reg.Modes().File, func(w io.Writer) error {
+		var err error
+		written, described, err = copyRegion(resolvedSrc, region, w, 0)
+		return err
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to extract region: %w", err).Error()), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Extracted %s of %s (%d bytes) to %s", described, resolvedSrc, written, resolvedDst)), nil
+}
+
+// prepareDestination validates destination as a new file to be written,
+// refusing to replace an existing file unless overwrite is set, and creates
+// its parent directories. It returns the resolved path and the path to
+// write to, which differs in overlay mode.
+func prepareDestination(reg *registry.Registry, destination string, overwrite bool) (string, string, error) {
+	resolvedDst, err := reg.ValidateForCreation(destination)
+	if err != nil {
+		return "", "", fmt.Errorf("destination path validation failed: %w", err)
+	}
+	if err := security.ValidateNoSymlinksInPath(reg.ExpandPath(destination), reg.Get()); err != nil {
+		return "", "", fmt.Errorf("destination path validation failed: %w", err)
+	}
+	if existing, err := readTarget(reg, resolvedDst); err == nil {
+		if _, err := os.Lstat(existing); err == nil {
+			if !overwrite {
+				return "", "", fmt.Errorf("destination already exists, set overwrite=true to replace")
+			}
+			if err := ensureNoSymlink(existing); err != nil {
+				return "", "", fmt.Errorf("destination path validation failed: %w", err)
+			}
+		}
+	}
+
+	target, allowedDirs, err := writeTarget(reg, resolvedDst)
+	if err != nil {
+		return "", "", err
+	}
+	// Create parent directories if needed (the overlay creates its own)
+	if reg.Overlay() == nil {
+		if err := safeMkdirAll(filepath.Dir(reg.ExpandPath(destination)), reg.Modes().Dir, reg.Get()); err != nil {
+			return "", "", fmt.Errorf("failed to create directories: %w", err)
+		}
+	}
+	if _, err := security.ValidateFinalPathForCreation(target, allowedDirs); err != nil {
+		return "", "", fmt.Errorf("destination path validation failed: %w", err)
+	}
+	return resolvedDst, target, nil
+}
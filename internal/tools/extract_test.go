@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/portertech/filesystem-mcp-server/internal/buffer"
+)
+
+func TestExtractRegion(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	store := buffer.NewStore(buffer.DefaultTTL, buffer.DefaultMaxBytes, buffer.DefaultMaxTotalBytes)
+	src := filepath.Join(tmpDir, "main.py")
+	os.WriteFile(src, []byte("import os\n\ndef a():\n    pass\n\ndef b():\n    pass\n"), 0644)
+
+	dst := filepath.Join(tmpDir, "pkg", "a.py")
+	result := callBufferTool(t, HandleExtractRegion, reg, store, map[string]any{"path": src, "destination": dst, "startLine": 3, "endLine": 4})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "def a():\n    pass\n" {
+		t.Errorf("unexpected content: %q", data)
+	}
+	if data, _ := os.ReadFile(src); !strings.Contains(string(data), "def a") {
+		t.Error("expected the source to be left alone")
+	}
+
+	result = callBufferTool(t, HandleExtractRegion, reg, store, map[string]any{"path": src, "destination": dst, "offset": 0, "length": 9})
+	if !result.IsError {
+		t.Error("expected an existing destination to be kept without overwrite")
+	}
+	result = callBufferTool(t, HandleExtractRegion, reg, store, map[string]any{"path": src, "destination": dst, "offset": 0, "length": 9, "overwrite": true})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "import os" {
+		t.Errorf("unexpected content: %q", data)
+	}
+
+	result = callBufferTool(t, HandleExtractRegion, reg, store, map[string]any{"path": src, "buffer": "b", "startLine": 6})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if b, _ := store.Get("b"); string(b.Content) != "def b():\n    pass\n" {
+		t.Errorf("unexpected buffer: %q", b.Content)
+	}
+}
+
+func TestExtractRegionInvalid(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	store := buffer.NewStore(buffer.DefaultTTL, buffer.DefaultMaxBytes, buffer.DefaultMaxTotalBytes)
+	src := filepath.Join(tmpDir, "f.txt")
+	os.WriteFile(src, []byte("one\ntwo\n"), 0644)
+
+	for name, args := range map[string]map[string]any{
+		"no region":  {"path": src, "destination": filepath.Join(tmpDir, "x")},
+		"no target":  {"path": src, "startLine": 1, "endLine": 1},
+		"both":       {"path": src, "destination": filepath.Join(tmpDir, "x"), "buffer": "b", "startLine": 1, "endLine": 1},
+		"past end":   {"path": src, "destination": filepath.Join(tmpDir, "x"), "startLine": 5},
+		"outside":    {"path": src, "destination": "/tmp/outside.txt", "startLine": 1, "endLine": 1},
+		"source dir": {"path": tmpDir, "destination": filepath.Join(tmpDir, "x"), "startLine": 1, "endLine": 1},
+	} {
+		if result := callBufferTool(t, HandleExtractRegion, reg, store, args); !result.IsError {
+			t.Errorf("%s: expected an error, got %s", name, resultText(result))
+		}
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "x")); !os.IsNotExist(err) {
+		t.Error("expected no destination to be written")
+	}
+}
@@ -0,0 +1,172 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/network"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/security"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/spf13/cast"
+)
+
+// NewFetchToFileTool creates the fetch_to_file tool.
+func NewFetchToFileTool(reg *registry.Registry) mcp.Tool {
+	domains := "none"
+	if access := reg.Network(); access != nil {
+		domains = strings.Join(access.Domains(), ", ")
+	}
+	return mcp.NewTool(
+		"fetch_to_file",
+		mcp.WithDescription(fmt.Sprintf("Download a URL into a file in an allowed directory, such as a tarball or dataset to work on. Only http and https URLs of allowed domains can be fetched, including after redirects: %s. The download goes to a temporary file and only replaces the destination once it is complete and, if a checksum is given, verified. Progress is reported to clients that ask for it.", domains)),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Fetch to File",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(true),
+			IdempotentHint:  boolPtr(true),
+			OpenWorldHint:   boolPtr(true),
+		}),
+		mcp.WithString("url", mcp.Description("http or https URL to download"), mcp.Required()),
+		mcp.WithString("path", mcp.Description("Path of the file to save it to; parent directories are created"), mcp.Required()),
+		mcp.WithString("sha256", mcp.Description("Expected SHA-256 of the content, in hex. The file is not saved if it does not match")),
+		mcp.WithNumber("maxBytes", mcp.Description(fmt.Sprintf("Refuse downloads larger than this many bytes (default and maximum: the server's limit, %s unless configured)", stream.FormatSize(network.DefaultMaxBytes)))),
+		mcp.WithBoolean("overwrite", mcp.Description("Replace the file if it exists (default: false)")),
+	)
+}
+
+// HandleFetchToFile handles the fetch_to_file tool.
+func HandleFetchToFile(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	rawURL := cast.ToString(request.Params.Arguments["url"])
+	path := cast.ToString(request.Params.Arguments["path"])
+	checksum := strings.ToLower(strings.TrimPrefix(cast.ToString(request.Params.Arguments["sha256"]), "sha256:"))
+	overwrite := cast.ToBool(request.Params.Arguments["overwrite"])
+
+	access := reg.Network()
+	if access == nil {
+		return mcp.NewToolResultError("network access is not enabled"), nil
+	}
+	maxBytes := access.MaxBytes()
+	if v, ok := request.Params.Arguments["maxBytes"]; ok {
+		n := cast.ToInt64(v)
+		if n <= 0 {
+			return mcp.NewToolResultError("maxBytes must be positive"), nil
+		}
+		maxBytes = min(n, maxBytes)
+	}
+	if checksum != "" {
+		if b, err := hex.DecodeString(checksum); err != nil || len(b) != sha256.Size {
+			return mcp.NewToolResultError("sha256 must be 64 hex digits"), nil
+		}
+	}
+
+	u, err := access.CheckURL(rawURL)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	resolvedPath, err := reg.ValidateForCreation(path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	if info, err := statTarget(reg, resolvedPath); err == nil {
+		if info.IsDir() {
+			return mcp.NewToolResultError("path is a directory"), nil
+		}
+		if !overwrite {
+			return mcp.NewToolResultError("file already exists; set overwrite=true to replace it"), nil
+		}
+	}
+
+	target, allowedDirs, err := writeTarget(reg, resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to write file: %w", err).Error()), nil
+	}
+	// Create parent directories if needed (the overlay creates its own)
+	if reg.Overlay() == nil {
+		if err := safeMkdirAll(filepath.Dir(reg.ExpandPath(path)), reg.Modes().Dir, reg.Get()); err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to create directories: %w", err).Error()), nil
+		}
+	}
+
+	n, sum, status, err := download(ctx, access, u, target, reg.Modes().File, allowedDirs, maxBytes, checksum, newProgress(ctx, request))
+	access.Audit(network.Transfer{Direction: "download", URL: u.Redacted(), Path: resolvedPath, Bytes: n, Status: status, Err: err})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("download failed: %w", err).Error()), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Downloaded %s (%d bytes) from %s to %s\nsha256: %s", stream.FormatSize(n), n, u.Redacted(), resolvedPath, sum)), nil
+}
+
+// download saves the body of a GET of u to target through a temporary
+// file, returning its size, SHA-256, and the response status.
+func download(ctx context.Context, access *network.Access, u *url.URL, target string, perm os.FileMode, allowedDirs []string, maxBytes int64, checksum string, p *progress) (int64, string, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return 0, "", 0, err
+	}
+	resp, err := access.Client().Do(req)
+	if err != nil {
+		return 0, "", 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return 0, "", resp.StatusCode, fmt.Errorf("server returned %s", resp.Status)
+	}
+	if resp.ContentLength > maxBytes {
+		return 0, "", resp.StatusCode, fmt.Errorf("file is %s, over the limit of %s", stream.FormatSize(resp.ContentLength), stream.FormatSize(maxBytes))
+	}
+
+	tmpName, err := batchTempName(filepath.Dir(target), ".tmp-")
+	if err != nil {
+		return 0, "", resp.StatusCode, err
+	}
+	f, err := os.OpenFile(tmpName, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return 0, "", resp.StatusCode, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	success := false
+	defer func() {
+		if !success {
+			os.Remove(tmpName)
+		}
+	}()
+
+	hash := sha256.New()
+	counter := &progressWriter{p: p, total: max(resp.ContentLength, 0)}
+	n, err := io.Copy(io.MultiWriter(f, hash, counter), io.LimitReader(resp.Body, maxBytes+1))
+	if err == nil && n > maxBytes {
+		err = fmt.Errorf("download exceeds the limit of %s", stream.FormatSize(maxBytes))
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return n, "", resp.StatusCode, err
+	}
+	p.report(n, n, true)
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if checksum != "" && sum != checksum {
+		return n, sum, resp.StatusCode, fmt.Errorf("checksum mismatch: expected sha256 %s, got %s", checksum, sum)
+	}
+	if _, err := security.ValidateFinalPathForCreation(target, allowedDirs); err != nil {
+		return n, sum, resp.StatusCode, fmt.Errorf("path validation failed: %w", err)
+	}
+	if err := os.Rename(tmpName, target); err != nil {
+		return n, sum, resp.StatusCode, fmt.Errorf("failed to rename temp file: %w", err)
+	}
+	success = true
+	return n, sum, resp.StatusCode, nil
+}
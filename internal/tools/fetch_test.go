@@ -0,0 +1,100 @@
+package tools
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/portertech/filesystem-mcp-server/internal/network"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+)
+
+func setupFetch(t *testing.T, body string) (*registry.Registry, string, *httptest.Server, *[]network.Transfer) {
+	t.Helper()
+	reg, tmpDir := setupTestRegistry(t)
+	var transfers []network.Transfer
+	access, err := network.New([]string{"127.0.0.1"}, network.DefaultMaxBytes, func(tr network.Transfer) {
+		transfers = append(transfers, tr)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg.SetNetwork(access)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return reg, tmpDir, srv, &transfers
+}
+
+func TestFetchToFile(t *testing.T) {
+	reg, tmpDir, srv, transfers := setupFetch(t, "dataset")
+	sum := sha256.Sum256([]byte("dataset"))
+
+	dest := filepath.Join(tmpDir, "data", "set.csv")
+	result := callTool(t, HandleFetchToFile, reg, map[string]any{
+		"url":    srv.URL + "/set.csv",
+		"path":   dest,
+		"sha256": hex.EncodeToString(sum[:]),
+	})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if data, _ := os.ReadFile(dest); string(data) != "dataset" {
+		t.Errorf("expected the download to be saved, got %q", data)
+	}
+	if len(*transfers) != 1 || (*transfers)[0].Bytes != 7 || (*transfers)[0].Direction != "download" {
+		t.Errorf("expected the transfer to be audited, got %+v", *transfers)
+	}
+
+	result = callTool(t, HandleFetchToFile, reg, map[string]any{"url": srv.URL, "path": dest})
+	if !result.IsError || !strings.Contains(resultText(result), "overwrite") {
+		t.Errorf("expected an existing file to be kept, got %s", resultText(result))
+	}
+}
+
+func TestFetchToFileRefused(t *testing.T) {
+	reg, tmpDir, srv, _ := setupFetch(t, "dataset")
+	dest := filepath.Join(tmpDir, "out.bin")
+
+	for name, args := range map[string]map[string]any{
+		"checksum":  {"url": srv.URL, "path": dest, "sha256": strings.Repeat("0", 64)},
+		"too large": {"url": srv.URL, "path": dest, "maxBytes": 3},
+		"not found": {"url": srv.URL + "/missing", "path": dest},
+		"host":      {"url": strings.Replace(srv.URL, "127.0.0.1", "localhost", 1), "path": dest},
+		"scheme":    {"url": "file:///etc/passwd", "path": dest},
+		"outside":   {"url": srv.URL, "path": "/etc/out.bin"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			result := callTool(t, HandleFetchToFile, reg, args)
+			if !result.IsError {
+				t.Fatalf("expected an error, got %s", resultText(result))
+			}
+			if _, err := os.Stat(dest); !os.IsNotExist(err) {
+				t.Error("expected no file to be saved")
+			}
+		})
+	}
+
+	entries, _ := os.ReadDir(tmpDir)
+	if len(entries) != 0 {
+		t.Errorf("expected temporary files to be removed, found %d entries", len(entries))
+	}
+}
+
+func TestFetchToFileDisabled(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	result := callTool(t, HandleFetchToFile, reg, map[string]any{"url": "https://example.com/", "path": filepath.Join(tmpDir, "f")})
+	if !result.IsError {
+		t.Error("expected an error without network access")
+	}
+}
@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/pathutil"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/spf13/cast"
+)
+
+// sanitizedFilename is the result of sanitize_filename.
+type sanitizedFilename struct {
+	Input    string `json:"input"`
+	Filename string `json:"filename"`
+	Changed  bool   `json:"changed"`
+	Reason   string `json:"reason,omitempty"`
+	Strict   bool   `json:"strict"`
+}
+
+// NewSanitizeFilenameTool creates the sanitize_filename tool.
+func NewSanitizeFilenameTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"sanitize_filename",
+		mcp.WithDescription("Turn an arbitrary string, such as a document title, into a file name that is valid on Linux, macOS, and Windows: invalid characters are replaced, reserved Windows device names (CON, NUL, COM1, ...) are altered, trailing dots and spaces are dropped, and the name is truncated to a length limit keeping its extension. Does not touch the filesystem."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("name", mcp.Description("String to turn into a file name"), mcp.Required()),
+		mcp.WithString("replacement", mcp.Description("Replacement for invalid characters (default: '_'; may be empty)")),
+		mcp.WithNumber("maxLength", mcp.Description("Maximum length in bytes (default and maximum: 255)")),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleSanitizeFilename handles the sanitize_filename tool.
+func HandleSanitizeFilename(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name := cast.ToString(request.Params.Arguments["name"])
+	maxLength := cast.ToInt(request.Params.Arguments["maxLength"])
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	replacement := "_"
+	if v, ok := request.Params.Arguments["replacement"]; ok {
+		replacement = cast.ToString(v)
+	}
+	// The replacement only ever appears inside a name
+	if err := pathutil.ValidateFilename("x" + replacement + "x"); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid replacement %q: %v", replacement, err)), nil
+	}
+	if maxLength < 0 {
+		return mcp.NewToolResultError("maxLength must not be negative"), nil
+	}
+
+	result := sanitizedFilename{
+		Input:    name,
+		Filename: pathutil.SanitizeFilename(name, replacement, maxLength),
+		Strict:   reg.StrictFilenames(),
+	}
+	result.Changed = result.Filename != name
+	if err := pathutil.ValidateFilename(name); err != nil {
+		result.Reason = err.Error()
+	} else if result.Changed && maxLength > 0 && len(name) > maxLength {
+		result.Reason = fmt.Sprintf("file name is longer than %d bytes", maxLength)
+	} else if result.Changed {
+		result.Reason = "file name starts with a space"
+	}
+
+	if format == "json" {
+		jsonResult, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(jsonResult)), nil
+	}
+	return mcp.NewToolResultText(result.Filename), nil
+}
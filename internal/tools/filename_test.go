@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSanitizeFilename(t *testing.T) {
+	reg, _ := setupTestRegistry(t)
+
+	tests := []struct {
+		name     string
+		args     map[string]any
+		expected string
+		wantErr  bool
+	}{
+		{"title", map[string]any{"name": "Q3 Report: Draft?"}, "Q3 Report_ Draft_", false},
+		{"replacement", map[string]any{"name": "a/b", "replacement": "-"}, "a-b", false},
+		{"max length", map[string]any{"name": "abcdefghij.txt", "maxLength": 8}, "abcd.txt", false},
+		{"reserved", map[string]any{"name": "aux"}, "aux_", false},
+		{"invalid replacement", map[string]any{"name": "a/b", "replacement": "/"}, "", true},
+		{"negative max length", map[string]any{"name": "a", "maxLength": -1}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := callTool(t, HandleSanitizeFilename, reg, tt.args)
+			if result.IsError != tt.wantErr {
+				t.Fatalf("IsError = %v, want %v: %s", result.IsError, tt.wantErr, resultText(result))
+			}
+			if !tt.wantErr && resultText(result) != tt.expected {
+				t.Errorf("got %q, want %q", resultText(result), tt.expected)
+			}
+		})
+	}
+
+	t.Run("json", func(t *testing.T) {
+		reg.SetStrictFilenames(true)
+		result := callTool(t, HandleSanitizeFilename, reg, map[string]any{"name": "con.txt", "format": "json"})
+		var got sanitizedFilename
+		if err := json.Unmarshal([]byte(resultText(result)), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Filename != "con_.txt" || !got.Changed || got.Reason == "" || !got.Strict {
+			t.Errorf("unexpected result: %+v", got)
+		}
+	})
+}
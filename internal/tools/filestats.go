@@ -0,0 +1,183 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/spf13/cast"
+)
+
+// errBinaryFile is returned by computeFileStats for a file that looks binary.
+var errBinaryFile = errors.New("file appears to be binary")
+
+// fileStats is the result of file_stats.
+type fileStats struct {
+	Path  string `json:"path"`
+	Lines int64  `json:"lines"`
+	Words int64  `json:"words"`
+	Bytes int64  `json:"bytes"`
+	// LongestLine is in bytes, not counting the line ending.
+	LongestLine int64 `json:"longestLine"`
+	// LineEnding is lf, crlf, cr, mixed, or none.
+	LineEnding string `json:"lineEnding"`
+	LF         int64  `json:"lf"`
+	CRLF       int64  `json:"crlf"`
+	CR         int64  `json:"cr"`
+}
+
+// NewFileStatsTool creates the file_stats tool.
+func NewFileStatsTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"file_stats",
+		mcp.WithDescription("Count the lines, words, and bytes of a text file, like wc, along with its longest line and its line ending style. The file is streamed in a single pass, so use this instead of reading a file just to count its lines."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path", mcp.Description("Path to the file"), mcp.Required()),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleFileStats handles the file_stats tool.
+func HandleFileStats(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	resolvedPath, err := validateRead(reg, path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	info, err := os.Stat(resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to stat file: %w", err).Error()), nil
+	}
+	if info.IsDir() {
+		return mcp.NewToolResultError("path is a directory, use count_lines instead"), nil
+	}
+
+	stats, err := computeFileStats(resolvedPath)
+	if errors.Is(err, errBinaryFile) {
+		return mcp.NewToolResultError("file appears to be binary; use get_file_info for its size"), nil
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to read file: %w", err).Error()), nil
+	}
+
+	if format == "json" {
+		jsonResult, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(jsonResult)), nil
+	}
+
+	var text strings.Builder
+	// Same columns as wc
+	fmt.Fprintf(&text, "%8d %8d %8d %s\n", stats.Lines, stats.Words, stats.Bytes, stats.Path)
+	fmt.Fprintf(&text, "Longest line: %d bytes\n", stats.LongestLine)
+	fmt.Fprintf(&text, "Line endings: %s", stats.LineEnding)
+	if stats.LineEnding == "mixed" {
+		fmt.Fprintf(&text, " (LF %d, CRLF %d, CR %d)", stats.LF, stats.CRLF, stats.CR)
+	}
+	text.WriteString("\n")
+	return mcp.NewToolResultText(text.String()), nil
+}
+
+// computeFileStats streams the file at path and counts its lines, words,
+// and bytes, and the length of its longest line. A line ends at LF, CRLF,
+// or a lone CR, and a final line without an ending still counts. It
+// returns errBinaryFile if the file looks binary.
+func computeFileStats(path string) (fileStats, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return fileStats{}, err
+	}
+	defer f.Close()
+
+	s := fileStats{Path: path}
+	buf := make([]byte, stream.DefaultChunkSize)
+	first := true
+	var lineLen int64
+	// pendingCR is set after a CR whose LF, if any, is in the next chunk
+	var inWord, pendingCR bool
+	endLine := func() {
+		s.Lines++
+		s.LongestLine = max(s.LongestLine, lineLen)
+		lineLen, inWord = 0, false
+	}
+	for {
+		n, err := f.Read(buf)
+		chunk := buf[:n]
+		if first && n > 0 {
+			if looksBinary(chunk) {
+				return fileStats{}, errBinaryFile
+			}
+			first = false
+		}
+		s.Bytes += int64(n)
+		for _, b := range chunk {
+			if pendingCR {
+				pendingCR = false
+				if b == '\n' {
+					s.CRLF++
+					endLine()
+					continue
+				}
+				s.CR++
+				endLine()
+			}
+			switch b {
+			case '\n':
+				s.LF++
+				endLine()
+				continue
+			case '\r':
+				pendingCR = true
+				continue
+			case ' ', '\t', '\v', '\f':
+				inWord = false
+			default:
+				if !inWord {
+					s.Words++
+				}
+				inWord = true
+			}
+			lineLen++
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fileStats{}, err
+		}
+	}
+	if pendingCR {
+		s.CR++
+		endLine()
+	} else if lineLen > 0 {
+		endLine()
+	}
+	s.LineEnding = lineEndingStyle(s.LF, s.CRLF, s.CR)
+	return s, nil
+}
+
+// lineEndingStyle names the line endings of a file from their counts.
+func lineEndingStyle(lf, crlf, cr int64) string {
+	style := "none"
+	for name, n := range map[string]int64{"lf": lf, "crlf": crlf, "cr": cr} {
+		if n == 0 {
+			continue
+		}
+		if style != "none" {
+			return "mixed"
+		}
+		style = name
+	}
+	return style
+}
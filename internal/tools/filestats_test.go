@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestComputeFileStats(t *testing.T) {
+	tmpDir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+		want    fileStats
+	}{
+		{"empty", "", fileStats{LineEnding: "none"}},
+		{"lf", "a b\n\nlonger line\n", fileStats{Lines: 3, Words: 4, Bytes: 17, LongestLine: 11, LineEnding: "lf", LF: 3}},
+		{"crlf without final newline", "one two\r\nthree", fileStats{Lines: 2, Words: 3, Bytes: 14, LongestLine: 7, LineEnding: "crlf", CRLF: 1}},
+		{"cr", "a\rb\r", fileStats{Lines: 2, Words: 2, Bytes: 4, LongestLine: 1, LineEnding: "cr", CR: 2}},
+		{"mixed", "a\nb\r\nc\rd", fileStats{Lines: 4, Words: 4, Bytes: 8, LongestLine: 1, LineEnding: "mixed", LF: 1, CRLF: 1, CR: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tmpDir, tt.name)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := computeFileStats(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.want.Path = path
+			if got != tt.want {
+				t.Errorf("computeFileStats() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleFileStats(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	path := filepath.Join(tmpDir, "test.txt")
+	os.WriteFile(path, []byte("hello world\nbye\n"), 0644)
+
+	result := callTool(t, HandleFileStats, reg, map[string]any{"path": path})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if text := resultText(result); !strings.Contains(text, "       2        3       16 "+path) || !strings.Contains(text, "Line endings: lf") {
+		t.Errorf("unexpected output:\n%s", text)
+	}
+
+	result = callTool(t, HandleFileStats, reg, map[string]any{"path": path, "format": "json"})
+	var stats fileStats
+	if err := json.Unmarshal([]byte(resultText(result)), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Lines != 2 || stats.LongestLine != 11 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	binary := filepath.Join(tmpDir, "data.bin")
+	os.WriteFile(binary, []byte("a\x00b"), 0644)
+	if result := callTool(t, HandleFileStats, reg, map[string]any{"path": binary}); !result.IsError {
+		t.Error("expected an error for a binary file")
+	}
+	if result := callTool(t, HandleFileStats, reg, map[string]any{"path": tmpDir}); !result.IsError {
+		t.Error("expected an error for a directory")
+	}
+}
@@ -0,0 +1,261 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/spf13/cast"
+)
+
+const (
+	// maxFilterLine is the longest line filter_file processes.
+	maxFilterLine = 1024 * 1024
+	// maxFilterSamples is how many changed lines filter_file shows.
+	maxFilterSamples = 5
+	// maxFilterSampleLen caps the characters shown of a changed line.
+	maxFilterSampleLen = 200
+)
+
+// Actions filter_file applies to the lines that match its pattern.
+const (
+	filterReplace = "replace"
+	filterKeep    = "keep"
+	filterDelete  = "delete"
+)
+
+// filterDone is the past tense of each action, for filter_file's result.
+var filterDone = map[string]string{filterReplace: "Replaced", filterKeep: "Kept", filterDelete: "Deleted"}
+
+// errNothingFiltered stops filter_file from replacing a file it did not
+// change.
+var errNothingFiltered = errors.New("no lines changed")
+
+// filterStats counts what filter_file did.
+type filterStats struct {
+	lines   int
+	changed int
+	samples []string
+}
+
+// NewFilterFileTool creates the filter_file tool.
+func NewFilterFileTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"filter_file",
+		mcp.WithDescription("Rewrite a text file line by line on the server: replace regular expression matches, keep only matching lines, or delete matching lines. The file is streamed through a temp file that atomically replaces it, so files far larger than memory can be edited without reading them into the conversation."),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithString("path", mcp.Description("Path to the file to filter"), mcp.Required()),
+		mcp.WithString("pattern", mcp.Description("Regular expression (RE2 syntax) matched against each line, without its line ending"), mcp.Required()),
+		mcp.WithString("action", mcp.Description("What to do with matching lines: 'replace' matches with replacement, 'keep' only them, or 'delete' them (default: replace)")),
+		mcp.WithString("replacement", mcp.Description("Replacement for each match with action 'replace', where $1 or ${name} is a capture group")),
+		mcp.WithBoolean("dryRun", mcp.Description("Count and show the changes without writing the file (default: false)")),
+	)
+}
+
+// HandleFilterFile handles the filter_file tool.
+func HandleFilterFile(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	pattern := cast.ToString(request.Params.Arguments["pattern"])
+	action := cast.ToString(request.Params.Arguments["action"])
+	replacement, hasReplacement := request.Params.Arguments["replacement"]
+	dryRun := cast.ToBool(request.Params.Arguments["dryRun"])
+
+	if action == "" {
+		action = filterReplace
+	}
+	switch {
+	case action != filterReplace && action != filterKeep && action != filterDelete:
+		return mcp.NewToolResultError("action must be 'replace', 'keep', or 'delete'"), nil
+	case action == filterReplace && !hasReplacement:
+		return mcp.NewToolResultError("replacement is required with action 'replace'"), nil
+	case action != filterReplace && hasReplacement:
+		return mcp.NewToolResultError(fmt.Sprintf("replacement cannot be combined with action '%s'", action)), nil
+	}
+	if pattern == "" {
+		return mcp.NewToolResultError("pattern must not be empty"), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid pattern %q: %v", pattern, err)), nil
+	}
+
+	resolvedPath, err := validateFinal(reg, path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	source, err := readTarget(reg, resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to read file: %w", err).Error()), nil
+	}
+	f, err := os.Open(source)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to open file: %w", err).Error()), nil
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to stat file: %w", err).Error()), nil
+	}
+	if !info.Mode().IsRegular() {
+		return mcp.NewToolResultError("path is not a regular file"), nil
+	}
+	br := bufio.NewReaderSize(f, 64*1024)
+	if head, _ := br.Peek(binarySniffLen); looksBinary(head) {
+		return mcp.NewToolResultError("file appears to be binary"), nil
+	}
+
+	filter := func(w io.Writer) (filterStats, error) {
+		return filterLines(ctx, br, w, re, action, []byte(cast.ToString(replacement)))
+	}
+	if dryRun {
+		stats, err := filter(io.Discard)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to filter %s: %w", resolvedPath, err).Error()), nil
+		}
+		return mcp.NewToolResultText(formatFilterStats("Would "+action, resolvedPath, action, stats)), nil
+	}
+
+	target, allowedDirs, err := writeTarget(reg, resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to write file: %w", err).Error()), nil
+	}
+	op := beginJournal(reg, "filter_file", resolvedPath)
+	op.Save(resolvedPath)
+
+	var stats filterStats
+	err = atomicWriteStream(target, info.Mode().Perm(), allowedDirs, func(w io.Writer) error {
+		bw := bufio.NewWriterSize(w, 64*1024)
+		if stats, err = filter(bw); err != nil {
+			return err
+		}
+		// Done reading, so the source can be replaced on Windows too
+		f.Close()
+		if stats.changed == 0 {
+			return errNothingFiltered
+		}
+		return bw.Flush()
+	})
+	if errors.Is(err, errNothingFiltered) {
+		op.Discard()
+		return mcp.NewToolResultText(fmt.Sprintf("No lines of %s matched; the file was not changed", resolvedPath)), nil
+	}
+	if err != nil {
+		op.Discard()
+		return mcp.NewToolResultError(fmt.Errorf("failed to filter %s, which was left as it was: %w", resolvedPath, err).Error()), nil
+	}
+	if store := reg.Shadow(); store != nil {
+		store.Forget(target)
+	}
+	return mcp.NewToolResultText(formatFilterStats(filterDone[action], resolvedPath, action, stats) + journalNote(op)), nil
+}
+
+// filterLines copies the lines of r to w, applying action to those that
+// match re. Line endings are kept as they are.
+func filterLines(ctx context.Context, r *bufio.Reader, w io.Writer, re *regexp.Regexp, action string, replacement []byte) (filterStats, error) {
+	var stats filterStats
+	for {
+		if stats.lines%10000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return stats, err
+			}
+		}
+		line, err := readLine(r)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return stats, err
+		}
+		if len(line) == 0 {
+			return stats, nil
+		}
+		stats.lines++
+
+		content := bytes.TrimSuffix(bytes.TrimSuffix(line, []byte("\n")), []byte("\r"))
+		ending := line[len(content):]
+		out := line
+		switch action {
+		case filterReplace:
+			if !re.Match(content) {
+				break
+			}
+			if replaced := re.ReplaceAll(content, replacement); !bytes.Equal(replaced, content) {
+				out = append(replaced, ending...)
+				stats.add(replaced)
+			}
+		case filterKeep:
+			if !re.Match(content) {
+				out = nil
+				stats.add(content)
+			}
+		case filterDelete:
+			if re.Match(content) {
+				out = nil
+				stats.add(content)
+			}
+		}
+		if _, err := w.Write(out); err != nil {
+			return stats, err
+		}
+	}
+}
+
+// add counts a changed line, keeping the first few as samples: the new
+// content of a replaced line, or a removed line.
+func (s *filterStats) add(line []byte) {
+	s.changed++
+	if len(s.samples) < maxFilterSamples {
+		s.samples = append(s.samples, fmt.Sprintf("%d: %s", s.lines, truncateText(string(line), maxFilterSampleLen)))
+	}
+}
+
+// readLine reads a line, including its ending, of at most maxFilterLine
+// bytes.
+func readLine(r *bufio.Reader) ([]byte, error) {
+	line, err := r.ReadSlice('\n')
+	if !errors.Is(err, bufio.ErrBufferFull) {
+		return line, err
+	}
+	buf := append([]byte(nil), line...)
+	for errors.Is(err, bufio.ErrBufferFull) {
+		if len(buf) > maxFilterLine {
+			return nil, fmt.Errorf("a line is longer than %s", stream.FormatSize(maxFilterLine))
+		}
+		line, err = r.ReadSlice('\n')
+		buf = append(buf, line...)
+	}
+	return buf, err
+}
+
+// formatFilterStats describes what filter_file did, or would do, with verb.
+func formatFilterStats(verb, path, action string, stats filterStats) string {
+	var text strings.Builder
+	switch action {
+	case filterReplace:
+		fmt.Fprintf(&text, "%s matches in %d of %d lines of %s", verb, stats.changed, stats.lines, path)
+	case filterKeep:
+		fmt.Fprintf(&text, "%s %d of %d lines of %s, removing %d", verb, stats.lines-stats.changed, stats.lines, path, stats.changed)
+	case filterDelete:
+		fmt.Fprintf(&text, "%s %d of %d lines of %s", verb, stats.changed, stats.lines, path)
+	}
+	if len(stats.samples) > 0 {
+		label := "\nRemoved lines:"
+		if action == filterReplace {
+			label = "\nChanged lines, as replaced:"
+		}
+		if stats.changed > len(stats.samples) {
+			label = strings.TrimSuffix(label, ":") + fmt.Sprintf(" (first %d):", len(stats.samples))
+		}
+		text.WriteString(label)
+		for _, s := range stats.samples {
+			text.WriteString("\n  " + s)
+		}
+	}
+	return text.String()
+}
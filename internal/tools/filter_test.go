@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHandleFilterFile(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	path := filepath.Join(tmpDir, "app.log")
+	original := "INFO start\r\nDEBUG x=1\r\nWARN disk 91%\r\nDEBUG x=2\r\nINFO stop"
+	if err := os.WriteFile(path, []byte(original), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A dry run reports without writing
+	text := resultText(callTool(t, HandleFilterFile, reg, map[string]any{"path": path, "pattern": `^DEBUG`, "action": "delete", "dryRun": true}))
+	if !strings.HasPrefix(text, "Would delete 2 of 5 lines") || !strings.Contains(text, "\n  2: DEBUG x=1\n  4: DEBUG x=2") {
+		t.Errorf("unexpected dry run: %s", text)
+	}
+	if data, _ := os.ReadFile(path); string(data) != original {
+		t.Errorf("dry run changed the file: %q", data)
+	}
+
+	result := callTool(t, HandleFilterFile, reg, map[string]any{"path": path, "pattern": `^DEBUG`, "action": "delete"})
+	if result.IsError || !strings.HasPrefix(resultText(result), "Deleted 2 of 5 lines") {
+		t.Fatalf("unexpected result: %s", resultText(result))
+	}
+	result = callTool(t, HandleFilterFile, reg, map[string]any{"path": path, "pattern": `^(\w+) `, "replacement": "[$1] "})
+	if result.IsError || !strings.HasPrefix(resultText(result), "Replaced matches in 3 of 3 lines") {
+		t.Fatalf("unexpected result: %s", resultText(result))
+	}
+	want := "[INFO] start\r\n[WARN] disk 91%\r\n[INFO] stop"
+	if data, _ := os.ReadFile(path); string(data) != want {
+		t.Errorf("file = %q, want %q", data, want)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	result = callTool(t, HandleFilterFile, reg, map[string]any{"path": path, "pattern": `WARN`, "action": "keep"})
+	if data, _ := os.ReadFile(path); result.IsError || string(data) != "[WARN] disk 91%\r\n" {
+		t.Errorf("unexpected keep: %s, file %q", resultText(result), data)
+	}
+
+	result = callTool(t, HandleFilterFile, reg, map[string]any{"path": path, "pattern": `ERROR`, "action": "delete"})
+	if result.IsError || !strings.Contains(resultText(result), "not changed") {
+		t.Errorf("unexpected result: %s", resultText(result))
+	}
+	entries, _ := os.ReadDir(tmpDir)
+	if len(entries) != 1 {
+		t.Errorf("expected no temp files left behind, found %d entries", len(entries))
+	}
+
+	binary := filepath.Join(tmpDir, "data.bin")
+	os.WriteFile(binary, []byte("a\x00b\n"), 0644)
+	for _, args := range []map[string]any{
+		{"path": path, "pattern": "x"},
+		{"path": path, "pattern": "x", "action": "keep", "replacement": "y"},
+		{"path": path, "pattern": "(", "action": "keep"},
+		{"path": path, "pattern": "x", "action": "sort"},
+		{"path": binary, "pattern": "a", "action": "delete"},
+		{"path": tmpDir, "pattern": "a", "action": "delete"},
+	} {
+		if result := callTool(t, HandleFilterFile, reg, args); !result.IsError {
+			t.Errorf("expected error for %v", args)
+		}
+	}
+}
+
+func TestFilterFileLargerThanBuffer(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	path := filepath.Join(tmpDir, "big.txt")
+	var b strings.Builder
+	for i := range 50000 {
+		fmt.Fprintf(&b, "row %d\n", i)
+	}
+	long := strings.Repeat("y", 200*1024)
+	b.WriteString(long + "\n")
+	os.WriteFile(path, []byte(b.String()), 0644)
+
+	result := callTool(t, HandleFilterFile, reg, map[string]any{"path": path, "pattern": `^row (\d*)0$`, "replacement": "ten $1"})
+	if result.IsError || !strings.HasPrefix(resultText(result), "Replaced matches in 5000 of 50001 lines") {
+		t.Fatalf("unexpected result: %s", resultText(result))
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(data), "ten \nrow 1\n") || !strings.Contains(string(data), "\nten 4999\nrow 49991\n") || !strings.HasSuffix(string(data), long+"\n") {
+		t.Errorf("unexpected content around the changes")
+	}
+}
@@ -0,0 +1,107 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/font"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/spf13/cast"
+)
+
+// maxFontSize is the largest font file font_info reads. Large CJK fonts
+// run to tens of megabytes.
+const maxFontSize = 64 * 1024 * 1024
+
+// fontInfo is the result of font_info.
+type fontInfo struct {
+	Path string `json:"path"`
+	font.Info
+}
+
+// NewFontInfoTool creates the font_info tool.
+func NewFontInfoTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"font_info",
+		mcp.WithDescription("Report the family and style names, version, glyph count, weight, and outline format of a font file without rendering it. Reads TrueType (.ttf), OpenType (.otf), collections (.ttc), and WOFF; every font of a collection is listed. WOFF2 is not supported."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path", mcp.Description("Path to the font file"), mcp.Required()),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleFontInfo handles the font_info tool.
+func HandleFontInfo(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	readPath, err := validateRead(reg, path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	info, err := os.Stat(readPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to stat font: %w", err).Error()), nil
+	}
+	if info.IsDir() {
+		return mcp.NewToolResultError("path is a directory, not a font file"), nil
+	}
+	if info.Size() > maxFontSize {
+		return mcp.NewToolResultError(fmt.Sprintf("file is %s; fonts up to %s are read", stream.FormatSize(info.Size()), stream.FormatSize(maxFontSize))), nil
+	}
+
+	release, err := reserveMemory(ctx, reg, readPath, info.Size())
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	defer release()
+	data, err := os.ReadFile(readPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to read font: %w", err).Error()), nil
+	}
+	parsed, err := font.Parse(data)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to read font: %w", err).Error()), nil
+	}
+	result := fontInfo{Path: path, Info: parsed}
+
+	if format == "json" {
+		jsonResult, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(jsonResult)), nil
+	}
+
+	var text strings.Builder
+	if result.Format == "collection" {
+		fmt.Fprintf(&text, "%s: font collection of %d faces\n", path, len(result.Faces))
+	} else {
+		fmt.Fprintf(&text, "%s: %s font\n", path, result.Format)
+	}
+	for _, face := range result.Faces {
+		fmt.Fprintf(&text, "\n%s %s\n", face.Family, face.Style)
+		for _, field := range []struct{ label, value string }{
+			{"Full name", face.FullName},
+			{"PostScript name", face.PostScriptName},
+			{"Version", face.Version},
+			{"Copyright", face.Copyright},
+			{"Outlines", face.Outlines},
+		} {
+			if field.value != "" {
+				fmt.Fprintf(&text, "  %s: %s\n", field.label, field.value)
+			}
+		}
+		fmt.Fprintf(&text, "  Glyphs: %d\n", face.Glyphs)
+		if face.Weight != 0 {
+			fmt.Fprintf(&text, "  Weight: %d\n", face.Weight)
+		}
+		fmt.Fprintf(&text, "  Tables: %s\n", strings.Join(face.Tables, ", "))
+	}
+	return mcp.NewToolResultText(text.String()), nil
+}
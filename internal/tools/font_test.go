@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testFont builds a TrueType font with a maxp table of 42 glyphs and a
+// Mac Roman family name.
+func testFont() []byte {
+	maxp := []byte{0, 0, 0x50, 0, 0, 42}
+	family := "Testing Sans"
+	name := binary.BigEndian.AppendUint16(nil, 0) // format
+	name = binary.BigEndian.AppendUint16(name, 1) // count
+	name = binary.BigEndian.AppendUint16(name, 18)
+	for _, v := range []uint16{1, 0, 0, 1, uint16(len(family)), 0} {
+		name = binary.BigEndian.AppendUint16(name, v)
+	}
+	name = append(name, family...)
+
+	out := []byte("\x00\x01\x00\x00")
+	out = binary.BigEndian.AppendUint16(out, 2)
+	out = append(out, make([]byte, 6)...)
+	offset := 12 + 2*16
+	for _, t := range []struct {
+		tag  string
+		data []byte
+	}{{"maxp", maxp}, {"name", name}} {
+		out = append(out, t.tag...)
+		out = binary.BigEndian.AppendUint32(out, 0)
+		out = binary.BigEndian.AppendUint32(out, uint32(offset))
+		out = binary.BigEndian.AppendUint32(out, uint32(len(t.data)))
+		offset += len(t.data)
+	}
+	return append(append(out, maxp...), name...)
+}
+
+func TestHandleFontInfo(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	fontPath := filepath.Join(tmpDir, "test.ttf")
+	if err := os.WriteFile(fontPath, testFont(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := callTool(t, HandleFontInfo, reg, map[string]interface{}{"path": fontPath})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	text := resultText(result)
+	for _, want := range []string{"truetype font", "Testing Sans", "Glyphs: 42", "Tables: maxp, name"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+
+	result = callTool(t, HandleFontInfo, reg, map[string]interface{}{"path": fontPath, "format": "json"})
+	var info fontInfo
+	if err := json.Unmarshal([]byte(resultText(result)), &info); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if info.Format != "truetype" || len(info.Faces) != 1 || info.Faces[0].Glyphs != 42 || info.Faces[0].Family != "Testing Sans" {
+		t.Errorf("unexpected result: %+v", info)
+	}
+
+	notFont := filepath.Join(tmpDir, "notes.txt")
+	if err := os.WriteFile(notFont, []byte("just some text, not a font"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result = callTool(t, HandleFontInfo, reg, map[string]interface{}{"path": notFont})
+	if !result.IsError || !strings.Contains(resultText(result), "unsupported font format") {
+		t.Errorf("expected unsupported format error, got: %s", resultText(result))
+	}
+}
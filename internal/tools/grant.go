@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/grant"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/spf13/cast"
+)
+
+// NewActivateWriteGrantTool creates the activate_write_grant tool.
+func NewActivateWriteGrantTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"activate_write_grant",
+		mcp.WithDescription("Activate a write grant token issued by the server operator, making a read-only allowed directory writable until the grant expires or its operations are used up. Tokens cannot be created through this server; ask the user for one."),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Activate Write Grant",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  boolPtr(false),
+		}),
+		mcp.WithString("token", mcp.Description("Grant token issued by the operator"), mcp.Required()),
+	)
+}
+
+// HandleActivateWriteGrant handles the activate_write_grant tool.
+func HandleActivateWriteGrant(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	token := cast.ToString(request.Params.Arguments["token"])
+
+	s, err := reg.ActivateGrant(token)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to activate write grant: %w", err).Error()), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Activated write grant %s: %s is writable %s", s.ID, s.Root, describeGrantLimits(s))), nil
+}
+
+// describeGrantLimits describes how long and for how many operations a
+// grant remains in effect.
+func describeGrantLimits(s grant.Status) string {
+	desc := fmt.Sprintf("until %s", s.Expires.Local().Format(time.RFC3339))
+	if s.MaxOps > 0 {
+		desc += fmt.Sprintf(" or for %d more operations, whichever comes first", s.Remaining())
+	}
+	return desc
+}
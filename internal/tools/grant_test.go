@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/portertech/filesystem-mcp-server/internal/grant"
+)
+
+func TestHandleActivateWriteGrant(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	if err := reg.SetReadOnly([]string{tmpDir}); err != nil {
+		t.Fatal(err)
+	}
+	existing := filepath.Join(tmpDir, "existing.txt")
+	if err := os.WriteFile(existing, []byte("keep"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	target := filepath.Join(tmpDir, "sub", "fix.txt")
+	result := callTool(t, HandleWriteFile, reg, map[string]any{"path": target, "content": "fixed"})
+	if !result.IsError || !strings.Contains(resultText(result), "read-only") {
+		t.Fatalf("expected read-only error, got %s", resultText(result))
+	}
+	if _, err := os.Stat(filepath.Dir(target)); !os.IsNotExist(err) {
+		t.Error("refused write should not create parent directories")
+	}
+	result = callTool(t, HandleDeleteFile, reg, map[string]any{"path": existing})
+	if !result.IsError {
+		t.Error("expected delete in read-only directory to fail")
+	}
+
+	key := []byte(strings.Repeat("k", grant.MinKeyLength))
+	reg.SetGrants(grant.NewManager(key))
+	token, _, err := grant.Issue(key, reg.Get()[0], time.Hour, 1, "one-off fix")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result = callTool(t, HandleActivateWriteGrant, reg, map[string]any{"token": "bogus"})
+	if !result.IsError {
+		t.Error("expected error for invalid token")
+	}
+	result = callTool(t, HandleActivateWriteGrant, reg, map[string]any{"token": token})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+
+	result = callTool(t, HandleListAllowedDirectories, reg, map[string]any{})
+	if text := resultText(result); !strings.Contains(text, "(read-only)") || !strings.Contains(text, "Active write grants") {
+		t.Errorf("unexpected listing:\n%s", text)
+	}
+
+	result = callTool(t, HandleWriteFile, reg, map[string]any{"path": target, "content": "fixed"})
+	if result.IsError {
+		t.Fatalf("granted write failed: %s", resultText(result))
+	}
+	result = callTool(t, HandleDeleteFile, reg, map[string]any{"path": existing})
+	if !result.IsError {
+		t.Error("grant should be used up after one operation")
+	}
+}
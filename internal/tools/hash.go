@@ -0,0 +1,143 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/reputation"
+	"github.com/spf13/cast"
+)
+
+// fileHash is the result of hash_file.
+type fileHash struct {
+	Path string `json:"path"`
+	reputation.Digests
+	// Reputation is set when hash lists are configured.
+	Reputation *reputation.Match `json:"reputation,omitempty"`
+}
+
+// NewHashFileTool creates the hash_file tool.
+func NewHashFileTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"hash_file",
+		mcp.WithDescription("Compute the MD5, SHA-1, and SHA-256 digests of a file. When the server has hash denylists or allowlists configured, also reports whether the file is known-bad, known-good, or unknown."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path", mcp.Description("Path to the file to hash"), mcp.Required()),
+		mcp.WithString("format", mcp.Description("Output format: 'text', 'json', or 'csv'")),
+	)
+}
+
+// HandleHashFile handles the hash_file tool. Unlike the read tools, it
+// reports denylisted files rather than refusing them, since it returns no
+// file data.
+func HandleHashFile(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	resolvedPath, err := validateRead(reg, path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	info, err := os.Stat(resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to stat file: %w", err).Error()), nil
+	}
+	if info.IsDir() {
+		return mcp.NewToolResultError("path is a directory, not a file"), nil
+	}
+
+	digests, err := reputation.HashFile(resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to hash file: %w", err).Error()), nil
+	}
+	result := fileHash{Path: resolvedPath, Digests: digests}
+	if lists, _ := reg.Reputation(); lists != nil {
+		match := lists.Lookup(digests)
+		result.Reputation = &match
+	}
+
+	if format == "json" {
+		jsonResult, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(jsonResult)), nil
+	}
+
+	if format == "csv" {
+		header := []string{"path", "size", "md5", "sha1", "sha256"}
+		row := []string{csvText(result.Path), strconv.FormatInt(result.Size, 10), result.MD5, result.SHA1, result.SHA256}
+		if m := result.Reputation; m != nil {
+			header = append(header, "reputation", "label")
+			row = append(row, string(m.Verdict), csvText(m.Label))
+		}
+		return mcp.NewToolResultText(formatCSV(header, [][]string{row})), nil
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "%s (%d bytes)\n", result.Path, result.Size)
+	fmt.Fprintf(&text, "MD5:     %s\n", result.MD5)
+	fmt.Fprintf(&text, "SHA-1:   %s\n", result.SHA1)
+	fmt.Fprintf(&text, "SHA-256: %s\n", result.SHA256)
+	if m := result.Reputation; m != nil {
+		fmt.Fprintf(&text, "Reputation: %s", m.Verdict)
+		if m.Label != "" {
+			fmt.Fprintf(&text, " (%s)", m.Label)
+		}
+		text.WriteString("\n")
+	}
+	return mcp.NewToolResultText(text.String()), nil
+}
+
+// checkReputation looks the file at path up in the configured hash lists.
+// It returns an error when the file cannot be hashed or is denylisted and
+// blocking is on, and the match otherwise; the match is nil when no lists
+// are configured.
+func checkReputation(reg *registry.Registry, path string) (*reputation.Match, error) {
+	lists, block := reg.Reputation()
+	if lists == nil {
+		return nil, nil
+	}
+	match, _, err := lists.Check(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check file reputation: %w", err)
+	}
+	if match.Verdict == reputation.KnownBad && block {
+		return nil, fmt.Errorf("blocked: %s", describeReputation(match))
+	}
+	return &match, nil
+}
+
+// reputationWarning returns a warning for a denylisted file let through, or
+// "" for any other match.
+func reputationWarning(m *reputation.Match) string {
+	if m == nil || m.Verdict != reputation.KnownBad {
+		return ""
+	}
+	return "WARNING: " + describeReputation(*m)
+}
+
+// withReputationWarning appends the warning for a denylisted file let
+// through to result as a separate content item, leaving the file data
+// untouched.
+func withReputationWarning(result *mcp.CallToolResult, m *reputation.Match) *mcp.CallToolResult {
+	if warning := reputationWarning(m); warning != "" {
+		result.Content = append(result.Content, mcp.NewTextContent(warning))
+	}
+	return result
+}
+
+// describeReputation describes a denylist match.
+func describeReputation(m reputation.Match) string {
+	desc := fmt.Sprintf("file hash %s is on the denylist", m.Hash)
+	if m.Label != "" {
+		desc += " (" + m.Label + ")"
+	}
+	return desc
+}
@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/reputation"
+)
+
+// helloSHA256 is the SHA-256 of "hello\n".
+const helloSHA256 = "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"
+
+func setReputation(t *testing.T, reg *registry.Registry, deny string, block bool) {
+	t.Helper()
+	denyPath := filepath.Join(t.TempDir(), "deny.txt")
+	if err := os.WriteFile(denyPath, []byte(deny), 0644); err != nil {
+		t.Fatal(err)
+	}
+	lists, err := reputation.Load(denyPath, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg.SetReputation(lists, block)
+}
+
+func TestHandleHashFile(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	testFile := filepath.Join(tmpDir, "hello.txt")
+	if err := os.WriteFile(testFile, []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := callTool(t, HandleHashFile, reg, map[string]any{"path": testFile})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if text := resultText(result); !strings.Contains(text, helloSHA256) || strings.Contains(text, "Reputation") {
+		t.Errorf("unexpected output:\n%s", text)
+	}
+
+	setReputation(t, reg, helloSHA256+" test-indicator\n", true)
+	result = callTool(t, HandleHashFile, reg, map[string]any{"path": testFile, "format": "json"})
+	if result.IsError {
+		t.Fatalf("hash_file should report, not block: %s", resultText(result))
+	}
+	var hash fileHash
+	if err := json.Unmarshal([]byte(resultText(result)), &hash); err != nil {
+		t.Fatal(err)
+	}
+	if hash.Reputation == nil || hash.Reputation.Verdict != reputation.KnownBad || hash.Reputation.Label != "test-indicator" {
+		t.Errorf("unexpected reputation: %+v", hash.Reputation)
+	}
+
+	result = callTool(t, HandleHashFile, reg, map[string]any{"path": tmpDir})
+	if !result.IsError {
+		t.Error("expected error for directory")
+	}
+}
+
+func TestReadToolsReputation(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	bad := filepath.Join(tmpDir, "bad.txt")
+	good := filepath.Join(tmpDir, "good.txt")
+	if err := os.WriteFile(bad, []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(good, []byte("fine\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	setReputation(t, reg, helloSHA256+"\n", true)
+	result := callTool(t, HandleReadTextFile, reg, map[string]any{"path": bad})
+	if !result.IsError || !strings.Contains(resultText(result), "denylist") {
+		t.Errorf("expected blocked read, got %s", resultText(result))
+	}
+	result = callTool(t, HandleReadMultipleFiles, reg, map[string]any{"paths": []any{bad, good}})
+	if text := resultText(result); !strings.Contains(text, "Error: blocked") || !strings.Contains(text, "fine") {
+		t.Errorf("unexpected output:\n%s", text)
+	}
+
+	setReputation(t, reg, helloSHA256+"\n", false)
+	result = callTool(t, HandleReadFile, reg, map[string]any{"path": bad})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if len(result.Content) != 2 || result.Content[0].(mcp.TextContent).Text != "hello\n" {
+		t.Fatalf("expected data and a warning, got %+v", result.Content)
+	}
+	if warning := result.Content[1].(mcp.TextContent).Text; !strings.Contains(warning, "WARNING") {
+		t.Errorf("unexpected warning %q", warning)
+	}
+}
@@ -0,0 +1,129 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/membudget"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/spf13/cast"
+)
+
+// pressureRatio is the fraction of a memory limit above which health reports
+// the server as under pressure.
+const pressureRatio = 0.9
+
+// startTime is when the server process started.
+var startTime = time.Now()
+
+// memoryStats is the Go runtime's view of the server's memory.
+type memoryStats struct {
+	HeapAlloc uint64 `json:"heapAlloc"`
+	HeapSys   uint64 `json:"heapSys"`
+	Sys       uint64 `json:"sys"`
+	NumGC     uint32 `json:"numGC"`
+	// SoftLimit is the GOMEMLIMIT, if one is set.
+	SoftLimit int64 `json:"softLimit,omitempty"`
+}
+
+// healthReport is the result of health.
+type healthReport struct {
+	// Status is "ok", or "pressure" when memory is close to a limit or reads
+	// are waiting for the memory budget.
+	Status     string           `json:"status"`
+	Uptime     string           `json:"uptime"`
+	Goroutines int              `json:"goroutines"`
+	Memory     memoryStats      `json:"memory"`
+	Budget     *membudget.Stats `json:"budget,omitempty"`
+}
+
+// NewHealthTool creates the health tool.
+func NewHealthTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"health",
+		mcp.WithDescription("Report the server's health: uptime, goroutines, Go heap usage, and, when -memory-budget is set, the bytes of file content buffered by in-flight reads against the budget."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleHealth handles the health tool.
+func HandleHealth(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	report := healthReport{
+		Status:     "ok",
+		Uptime:     time.Since(startTime).Round(time.Second).String(),
+		Goroutines: runtime.NumGoroutine(),
+		Memory: memoryStats{
+			HeapAlloc: ms.HeapAlloc,
+			HeapSys:   ms.HeapSys,
+			Sys:       ms.Sys,
+			NumGC:     ms.NumGC,
+		},
+	}
+	if limit := debug.SetMemoryLimit(-1); limit != math.MaxInt64 {
+		report.Memory.SoftLimit = limit
+		if float64(ms.HeapAlloc) >= pressureRatio*float64(limit) {
+			report.Status = "pressure"
+		}
+	}
+	if b := reg.MemoryBudget(); b != nil {
+		stats := b.Stats()
+		report.Budget = &stats
+		if stats.Waiting > 0 || float64(stats.InUse) >= pressureRatio*float64(stats.Limit) {
+			report.Status = "pressure"
+		}
+	}
+
+	if format == "json" {
+		jsonResult, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(jsonResult)), nil
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "Status: %s\n", report.Status)
+	fmt.Fprintf(&text, "Uptime: %s\n", report.Uptime)
+	fmt.Fprintf(&text, "Goroutines: %d\n", report.Goroutines)
+	fmt.Fprintf(&text, "Heap: %s in use, %s reserved, %s from the OS, %d GC cycles\n",
+		stream.FormatSize(int64(ms.HeapAlloc)), stream.FormatSize(int64(ms.HeapSys)), stream.FormatSize(int64(ms.Sys)), ms.NumGC)
+	if report.Memory.SoftLimit > 0 {
+		fmt.Fprintf(&text, "Soft memory limit: %s\n", stream.FormatSize(report.Memory.SoftLimit))
+	}
+	if s := report.Budget; s != nil {
+		fmt.Fprintf(&text, "Read budget: %s of %s in use by %d reads (peak %s), %d waiting, %d refused\n",
+			stream.FormatSize(s.InUse), stream.FormatSize(s.Limit), s.Active, stream.FormatSize(s.Peak), s.Waiting, s.Rejected)
+	} else {
+		text.WriteString("Read budget: unlimited\n")
+	}
+	return mcp.NewToolResultText(text.String()), nil
+}
+
+// reserveMemory reserves n bytes of the memory budget for reading path,
+// waiting for room if it is full. The returned function releases the
+// reservation and must be called once the response is built. Without a
+// budget it reserves nothing.
+func reserveMemory(ctx context.Context, reg *registry.Registry, path string, n int64) (func(), error) {
+	b := reg.MemoryBudget()
+	if b == nil {
+		return func() {}, nil
+	}
+	release, err := b.Reserve(ctx, n)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s (%s) now: %w", path, stream.FormatSize(n), err)
+	}
+	return release, nil
+}
@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/portertech/filesystem-mcp-server/internal/membudget"
+)
+
+func TestHandleHealth(t *testing.T) {
+	reg, _ := setupTestRegistry(t)
+
+	result := callTool(t, HandleHealth, reg, map[string]any{})
+	if text := resultText(result); !strings.Contains(text, "Status: ok") || !strings.Contains(text, "Read budget: unlimited") {
+		t.Errorf("unexpected health report:\n%s", text)
+	}
+
+	reg.SetMemoryBudget(membudget.New(1000, time.Second))
+	release, err := reg.MemoryBudget().Reserve(t.Context(), 950)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	result = callTool(t, HandleHealth, reg, map[string]any{"format": "json"})
+	var report healthReport
+	if err := json.Unmarshal([]byte(resultText(result)), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Status != "pressure" || report.Budget == nil || report.Budget.InUse != 950 || report.Budget.Active != 1 {
+		t.Errorf("unexpected health report: %+v", report)
+	}
+	if report.Goroutines == 0 || report.Memory.HeapAlloc == 0 {
+		t.Errorf("expected runtime stats, got %+v", report)
+	}
+}
+
+func TestReadsReserveMemory(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	small := filepath.Join(tmpDir, "small.txt")
+	large := filepath.Join(tmpDir, "large.txt")
+	for path, size := range map[string]int{small: 10, large: 200} {
+		if err := os.WriteFile(path, []byte(strings.Repeat("x", size)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	budget := membudget.New(100, 10*time.Millisecond)
+	reg.SetMemoryBudget(budget)
+
+	if result := callTool(t, HandleReadTextFile, reg, map[string]any{"path": small}); result.IsError {
+		t.Fatalf("small read failed: %s", resultText(result))
+	}
+	result := callTool(t, HandleReadFile, reg, map[string]any{"path": large})
+	if !result.IsError || !strings.Contains(resultText(result), "larger than the memory budget") {
+		t.Errorf("expected the large read to be refused, got %q", resultText(result))
+	}
+	if result := callTool(t, HandleReadTextFile, reg, map[string]any{"path": large, "head": 1}); result.IsError {
+		t.Errorf("partial reads should not reserve the whole file: %s", resultText(result))
+	}
+
+	result = callTool(t, HandleReadMultipleFiles, reg, map[string]any{"paths": []any{small, large}})
+	if text := resultText(result); result.IsError || !strings.Contains(text, "memory budget") || !strings.Contains(text, strings.Repeat("x", 10)) {
+		t.Errorf("expected the small file and an error for the large one, got:\n%s", text)
+	}
+
+	if stats := budget.Stats(); stats.InUse != 0 || stats.Active != 0 || stats.Peak != 10 {
+		t.Errorf("expected every reservation to be released, got %+v", stats)
+	}
+}
@@ -0,0 +1,37 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/idempotency"
+)
+
+// idempotencyKeyDescription documents the idempotencyKey parameter added to
+// every tool that changes something.
+const idempotencyKeyDescription = "Unique key for this operation. A retry with the same key and arguments returns the original result instead of applying the change again. Keys are remembered for an hour after a successful call."
+
+// WithIdempotencyKey adds the optional idempotencyKey parameter to a tool
+// that is not read-only. It reports false, leaving the tool unchanged, for
+// read-only tools.
+func WithIdempotencyKey(tool mcp.Tool) (mcp.Tool, bool) {
+	if IsReadOnly(tool) {
+		return tool, false
+	}
+	props := tool.InputSchema.Properties
+	if _, ok := props[idempotency.KeyParam]; ok {
+		return tool, false
+	}
+
+	withKey := make(map[string]any, len(props)+1)
+	for k, v := range props {
+		withKey[k] = v
+	}
+	withKey[idempotency.KeyParam] = map[string]any{"type": "string", "description": idempotencyKeyDescription}
+	tool.InputSchema.Properties = withKey
+	return tool, true
+}
+
+// IsReadOnly reports whether tool is annotated as read-only.
+func IsReadOnly(tool mcp.Tool) bool {
+	readOnly := tool.Annotations.ReadOnlyHint
+	return readOnly != nil && *readOnly
+}
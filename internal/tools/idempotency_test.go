@@ -0,0 +1,35 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/idempotency"
+)
+
+func TestWithIdempotencyKey(t *testing.T) {
+	reg, _ := setupTestRegistry(t)
+
+	tests := []struct {
+		name     string
+		tool     mcp.Tool
+		expected bool
+	}{
+		{"write", NewWriteFileTool(reg), true},
+		{"move", NewMoveFileTool(reg), true},
+		{"annotated", NewCleanupOldFilesTool(reg), true},
+		{"read-only", NewReadTextFileTool(reg), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool, ok := WithIdempotencyKey(tt.tool)
+			if ok != tt.expected {
+				t.Fatalf("WithIdempotencyKey() = %v, want %v", ok, tt.expected)
+			}
+			if _, has := tool.InputSchema.Properties[idempotency.KeyParam]; has != ok {
+				t.Errorf("idempotencyKey parameter present = %v, want %v", has, ok)
+			}
+		})
+	}
+}
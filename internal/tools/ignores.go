@@ -0,0 +1,303 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/spf13/cast"
+)
+
+const (
+	defaultIgnoreSizeThreshold = 1024 * 1024
+	// maxIgnoreExamples is the number of example files kept per suggestion.
+	maxIgnoreExamples = 3
+	ignoreBlockHeader = "# Added by analyze_ignores"
+)
+
+// generatedDirs are directory names that hold build output, dependencies,
+// or caches rather than sources.
+var generatedDirs = map[string]bool{
+	"node_modules": true, "dist": true, "build": true, "target": true, "out": true,
+	"coverage": true, "__pycache__": true, ".pytest_cache": true, ".mypy_cache": true,
+	".tox": true, ".venv": true, "venv": true, ".next": true, ".nuxt": true,
+	".cache": true, ".gradle": true, ".terraform": true,
+}
+
+// generatedFiles are file name patterns of build output, logs, and editor or
+// OS clutter.
+var generatedFiles = []string{
+	"*.pyc", "*.pyo", "*.class", "*.o", "*.obj", "*.so", "*.dylib", "*.dll", "*.exe",
+	"*.log", "*.swp", "*.tmp", ".DS_Store", "Thumbs.db",
+}
+
+// ignoreSuggestion is a proposed .gitignore pattern and the files it covers.
+type ignoreSuggestion struct {
+	Pattern  string   `json:"pattern"`
+	Reason   string   `json:"reason"`
+	Files    int      `json:"files"`
+	Bytes    int64    `json:"bytes"`
+	Examples []string `json:"examples"`
+}
+
+// ignoreAnalysis is the result of analyze_ignores.
+type ignoreAnalysis struct {
+	Gitignore   string             `json:"gitignore"`
+	Suggestions []ignoreSuggestion `json:"suggestions"`
+	Applied     bool               `json:"applied"`
+	Diff        string             `json:"diff,omitempty"`
+}
+
+// NewAnalyzeIgnoresTool creates the analyze_ignores tool.
+func NewAnalyzeIgnoresTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"analyze_ignores",
+		mcp.WithDescription("Find large, binary, and generated files (build output, dependency and cache directories, compiled objects, logs) under a directory that are not covered by .gitignore, and propose ignore patterns as a diff of the directory's .gitignore. With apply, the patterns are appended to the .gitignore."),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Analyze Ignores",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  boolPtr(true),
+		}),
+		mcp.WithString("path", mcp.Description("Directory whose .gitignore to analyze, usually a repository root"), mcp.Required()),
+		mcp.WithString("largerThan", mcp.Description("Report files larger than this, e.g. '500KB' (default: 1MB)")),
+		mcp.WithArray("excludePatterns", mcp.Description("Glob patterns to leave out of the analysis"), mcp.Items(map[string]any{"type": "string"})),
+		withMaxFilesParam(),
+		mcp.WithBoolean("apply", mcp.Description("If true, append the suggested patterns to the .gitignore (default: false)")),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleAnalyzeIgnores handles the analyze_ignores tool.
+func HandleAnalyzeIgnores(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	apply := cast.ToBool(request.Params.Arguments["apply"])
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	threshold := int64(defaultIgnoreSizeThreshold)
+	if v := cast.ToString(request.Params.Arguments["largerThan"]); v != "" {
+		size, err := parseSize(v)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("invalid largerThan: %w", err).Error()), nil
+		}
+		threshold = size
+	}
+
+	filter, err := parseTreeFilter(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	// Only files that are not ignored yet are of interest
+	filter.gitignore = true
+	filter.matchGlobs = nil
+	budget, err := parseMaxFiles(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	resolvedPath, errResult := resolveDirectory(reg, path)
+	if errResult != nil {
+		return errResult, nil
+	}
+	gitignorePath, err := reg.ValidateForCreation(filepath.Join(resolvedPath, ".gitignore"))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+
+	var walkErrs walkErrors
+	suggestions, err := findIgnoreCandidates(ctx, reg, resolvedPath, filter, threshold, budget, &walkErrs)
+	if err != nil && !errors.Is(err, errMaxFiles) {
+		return mcp.NewToolResultError(fmt.Errorf("analysis failed: %w", err).Error()), nil
+	}
+	analysis := ignoreAnalysis{Gitignore: gitignorePath, Suggestions: suggestions}
+
+	if len(suggestions) > 0 {
+		var original string
+		perm := reg.Modes().File
+		source, err := readTarget(reg, gitignorePath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to read .gitignore: %w", err).Error()), nil
+		}
+		if data, err := os.ReadFile(source); err == nil {
+			original = string(data)
+			if info, err := os.Stat(source); err == nil {
+				perm = info.Mode().Perm()
+			}
+		} else if !os.IsNotExist(err) {
+			return mcp.NewToolResultError(fmt.Errorf("failed to read .gitignore: %w", err).Error()), nil
+		}
+
+		updated := appendIgnorePatterns(original, suggestions)
+		analysis.Diff = generateUnifiedDiff(gitignorePath, original, updated)
+
+		if apply {
+			target, allowedDirs, err := writeTarget(reg, gitignorePath)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Errorf("failed to write .gitignore: %w", err).Error()), nil
+			}
+			if err := atomicWriteFile(target, []byte(updated), perm, allowedDirs); err != nil {
+				return mcp.NewToolResultError(fmt.Errorf("failed to write .gitignore: %w", err).Error()), nil
+			}
+			analysis.Applied = true
+		}
+	}
+
+	if format == "json" {
+		jsonResult, err := json.MarshalIndent(analysis, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return withWalkErrors(withBudgetNote(mcp.NewToolResultText(string(jsonResult)), budget), &walkErrs), nil
+	}
+
+	if len(suggestions) == 0 {
+		return withWalkErrors(withBudgetNote(mcp.NewToolResultText(fmt.Sprintf("No unignored large, binary, or generated files found under %s", resolvedPath)), budget), &walkErrs), nil
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "Suggested patterns for %s:\n", gitignorePath)
+	for _, s := range suggestions {
+		fmt.Fprintf(&result, "  %-24s %s, %d files, %s (e.g. %s)\n", s.Pattern, s.Reason, s.Files, stream.FormatSize(s.Bytes), strings.Join(s.Examples, ", "))
+	}
+	if analysis.Applied {
+		fmt.Fprintf(&result, "\nUpdated %s\n\n%s", gitignorePath, analysis.Diff)
+	} else {
+		fmt.Fprintf(&result, "\nNot applied; call again with apply=true to update the .gitignore:\n\n%s", analysis.Diff)
+	}
+	return withWalkErrors(withBudgetNote(mcp.NewToolResultText(result.String()), budget), &walkErrs), nil
+}
+
+// findIgnoreCandidates walks the files not covered by .gitignore and groups
+// the generated, binary, and oversized ones by suggested pattern. Paths that
+// cannot be read are added to errs. When the budget runs out it returns the
+// suggestions so far with errMaxFiles.
+func findIgnoreCandidates(ctx context.Context, reg *registry.Registry, root string, filter treeFilter, threshold int64, budget *fileBudget, errs *walkErrors) ([]ignoreSuggestion, error) {
+	byPattern := make(map[string]*ignoreSuggestion)
+	add := func(pattern, reason, relPath string, files int, size int64) {
+		s, ok := byPattern[pattern]
+		if !ok {
+			s = &ignoreSuggestion{Pattern: pattern, Reason: reason, Examples: []string{}}
+			byPattern[pattern] = s
+		}
+		s.Files += files
+		s.Bytes += size
+		if len(s.Examples) < maxIgnoreExamples {
+			s.Examples = append(s.Examples, relPath)
+		}
+	}
+
+	spend := func(dirPath, relPath string) error {
+		if !budget.take() {
+			return errMaxFiles
+		}
+		return nil
+	}
+	filter.onError = errs.add
+	filter.onDir = func(dirPath, relPath string) error {
+		if err := spend(dirPath, relPath); err != nil {
+			return err
+		}
+		name := path.Base(relPath)
+		if !generatedDirs[name] {
+			return nil
+		}
+		// Count the directory as one suggestion without descending further.
+		// Everything in it counts, ignored or not.
+		var size int64
+		var files int
+		err := walkTree(ctx, reg, dirPath, treeFilter{onDir: spend, onError: errs.add}, func(p, _ string, d fs.DirEntry) error {
+			if !budget.take() {
+				return errMaxFiles
+			}
+			info, err := d.Info()
+			if err != nil {
+				errs.add(p, err)
+				return nil
+			}
+			size += info.Size()
+			files++
+			return nil
+		})
+		add(name+"/", "generated directory", relPath+"/", files, size)
+		if err != nil {
+			return err
+		}
+		return filepath.SkipDir
+	}
+
+	err := walkTree(ctx, reg, root, filter, func(walkPath, relPath string, entry fs.DirEntry) error {
+		if !budget.take() {
+			return errMaxFiles
+		}
+		info, err := entry.Info()
+		if err != nil {
+			errs.add(walkPath, err)
+			return nil
+		}
+		name := entry.Name()
+		for _, p := range generatedFiles {
+			if matched, _ := path.Match(p, name); matched {
+				add(p, "generated file", relPath, 1, info.Size())
+				return nil
+			}
+		}
+
+		// Binary and large files may be committed on purpose, so they are
+		// only ever suggested by exact path
+		source, err := readTarget(reg, walkPath)
+		var head []byte
+		if err == nil {
+			head, err = readHead(source, binarySniffLen)
+		}
+		if err != nil {
+			errs.add(walkPath, err)
+		} else if looksBinary(head) {
+			add("/"+relPath, "binary file", relPath, 1, info.Size())
+			return nil
+		}
+		if info.Size() > threshold {
+			add("/"+relPath, "larger than "+stream.FormatSize(threshold), relPath, 1, info.Size())
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errMaxFiles) {
+		return nil, err
+	}
+
+	suggestions := make([]ignoreSuggestion, 0, len(byPattern))
+	for _, s := range byPattern {
+		suggestions = append(suggestions, *s)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		return suggestions[i].Pattern < suggestions[j].Pattern
+	})
+	return suggestions, err
+}
+
+// appendIgnorePatterns adds the suggested patterns to a .gitignore as one
+// commented block.
+func appendIgnorePatterns(content string, suggestions []ignoreSuggestion) string {
+	var b strings.Builder
+	b.WriteString(content)
+	if content != "" {
+		if !strings.HasSuffix(content, "\n") {
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(ignoreBlockHeader + "\n")
+	for _, s := range suggestions {
+		b.WriteString(s.Pattern + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,89 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeIgnores(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	writePackTree(t, tmpDir, map[string]string{
+		".gitignore":              "*.log",
+		"main.go":                 "package main\n",
+		"debug.log":               "already ignored\n",
+		"node_modules/a/index.js": "module.exports = 1\n",
+		"node_modules/b/index.js": "module.exports = 2\n",
+		"pkg/__pycache__/m.pyc":   "\x00\x01",
+		"pkg/util.pyc":            "\x00\x02",
+		"assets/logo.png":         "\x89PNG\x00",
+		"data/dump.sql":           strings.Repeat("x", 3000),
+		"src/.DS_Store":           "x",
+	})
+
+	args := map[string]any{"path": tmpDir, "largerThan": "2KB", "format": "json"}
+	result := callTool(t, HandleAnalyzeIgnores, reg, args)
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	var analysis ignoreAnalysis
+	if err := json.Unmarshal([]byte(resultText(result)), &analysis); err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]int{}
+	for _, s := range analysis.Suggestions {
+		got[s.Pattern] = s.Files
+	}
+	want := map[string]int{
+		"node_modules/":    2,
+		"__pycache__/":     1,
+		"*.pyc":            1,
+		"/assets/logo.png": 1,
+		"/data/dump.sql":   1,
+		".DS_Store":        1,
+	}
+	if len(got) != len(want) {
+		t.Errorf("suggestions = %v, want %v", got, want)
+	}
+	for pattern, files := range want {
+		if got[pattern] != files {
+			t.Errorf("%s: %d files, want %d (all: %v)", pattern, got[pattern], files, got)
+		}
+	}
+	if analysis.Applied || !strings.Contains(analysis.Diff, "+node_modules/") {
+		t.Errorf("expected an unapplied diff, got applied=%v diff:\n%s", analysis.Applied, analysis.Diff)
+	}
+	if data, _ := os.ReadFile(filepath.Join(tmpDir, ".gitignore")); string(data) != "*.log" {
+		t.Errorf(".gitignore modified without apply: %q", data)
+	}
+
+	// Applying appends the block; a second run has nothing left to suggest
+	args["apply"] = true
+	delete(args, "format")
+	result = callTool(t, HandleAnalyzeIgnores, reg, args)
+	if result.IsError || !strings.Contains(resultText(result), "Updated") {
+		t.Fatalf("apply failed: %s", resultText(result))
+	}
+	data, err := os.ReadFile(filepath.Join(tmpDir, ".gitignore"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "*.log\n\n# Added by analyze_ignores\n") || !strings.Contains(string(data), "\n/data/dump.sql\n") {
+		t.Errorf("unexpected .gitignore:\n%s", data)
+	}
+
+	result = callTool(t, HandleAnalyzeIgnores, reg, args)
+	if !strings.Contains(resultText(result), "No unignored") {
+		t.Errorf("expected no further suggestions, got:\n%s", resultText(result))
+	}
+
+	// The files inside generated directories count against maxFiles
+	writePackTree(t, tmpDir, map[string]string{"dist/a.js": "1", "dist/b.js": "2"})
+	result = callTool(t, HandleAnalyzeIgnores, reg, map[string]any{"path": tmpDir, "maxFiles": 3})
+	if text := resultText(result); !strings.Contains(text, "raise maxFiles") {
+		t.Errorf("expected a file limit note: %s", text)
+	}
+}
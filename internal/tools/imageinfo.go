@@ -0,0 +1,120 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/imaging"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/spf13/cast"
+)
+
+// imageInfo is the result of get_image_info.
+type imageInfo struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	*imaging.Info
+}
+
+// NewGetImageInfoTool creates the get_image_info tool.
+func NewGetImageInfoTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"get_image_info",
+		mcp.WithDescription("Get the format, width, and height of a JPEG, PNG, GIF, WebP, or BMP image, and selected EXIF fields (camera, lens, capture time, orientation, exposure, and whether it has a GPS location), from its headers alone. Much cheaper than read_media_file when only the dimensions are needed."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path", mcp.Description("Path to the image"), mcp.Required()),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleGetImageInfo handles the get_image_info tool.
+func HandleGetImageInfo(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	resolvedPath, err := validateRead(reg, path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	f, err := os.Open(resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to open file: %w", err).Error()), nil
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to stat file: %w", err).Error()), nil
+	}
+	if stat.IsDir() {
+		return mcp.NewToolResultError("path is a directory, not a file"), nil
+	}
+
+	img, err := imaging.ReadInfo(f)
+	if errors.Is(err, imaging.ErrUnsupported) {
+		return mcp.NewToolResultError("not a JPEG, PNG, GIF, WebP, or BMP image"), nil
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to read image header: %w", err).Error()), nil
+	}
+	info := imageInfo{Path: path, Size: stat.Size(), Info: img}
+
+	if format == "json" {
+		data, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(data)), nil
+	}
+	return mcp.NewToolResultText(formatImageInfo(info)), nil
+}
+
+// formatImageInfo renders image information as text, one field per line.
+func formatImageInfo(info imageInfo) string {
+	var text strings.Builder
+	fmt.Fprintf(&text, "%s: %s image, %dx%d, %s", info.Path, strings.ToUpper(info.Format), info.Width, info.Height, stream.FormatSize(info.Size))
+	e := info.EXIF
+	if e == nil {
+		return text.String()
+	}
+	if e.Orientation != 0 {
+		fmt.Fprintf(&text, "\nOrientation: %d", e.Orientation)
+		if e.Rotated() {
+			fmt.Fprintf(&text, " (displays as %dx%d)", info.Height, info.Width)
+		}
+	}
+	line := func(label, value string) {
+		if value != "" {
+			fmt.Fprintf(&text, "\n%s: %s", label, value)
+		}
+	}
+	// Models often repeat the make, as in "Canon" and "Canon EOS R5"
+	line("Camera", strings.TrimSpace(e.Make+" "+strings.TrimSpace(strings.TrimPrefix(e.Model, e.Make))))
+	line("Lens", e.LensModel)
+	line("Taken", e.Taken)
+	line("Modified", e.DateTime)
+	var exposure []string
+	if e.ExposureTime != "" {
+		exposure = append(exposure, e.ExposureTime+"s")
+	}
+	if e.FNumber != 0 {
+		exposure = append(exposure, fmt.Sprintf("f/%g", e.FNumber))
+	}
+	if e.ISO != 0 {
+		exposure = append(exposure, fmt.Sprintf("ISO %d", e.ISO))
+	}
+	if e.FocalLength != 0 {
+		exposure = append(exposure, fmt.Sprintf("%gmm", e.FocalLength))
+	}
+	line("Exposure", strings.Join(exposure, ", "))
+	line("Software", e.Software)
+	if e.GPS {
+		line("GPS location", "present (copy_file and read_media_file can remove it with stripMetadata)")
+	}
+	return text.String()
+}
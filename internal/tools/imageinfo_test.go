@@ -0,0 +1,51 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHandleGetImageInfo(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 640, 480))); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(tmpDir, "shot.png")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	text := resultText(callTool(t, HandleGetImageInfo, reg, map[string]any{"path": path}))
+	if !strings.HasPrefix(text, path+": PNG image, 640x480, ") {
+		t.Errorf("unexpected result: %s", text)
+	}
+
+	text = resultText(callTool(t, HandleGetImageInfo, reg, map[string]any{"path": path, "format": "json"}))
+	var info struct {
+		Format string
+		Width  int
+		Height int
+		Size   int64
+	}
+	if err := json.Unmarshal([]byte(text), &info); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, text)
+	}
+	if info.Format != "png" || info.Width != 640 || info.Height != 480 || info.Size != int64(buf.Len()) {
+		t.Errorf("unexpected info %+v", info)
+	}
+
+	notes := filepath.Join(tmpDir, "notes.txt")
+	os.WriteFile(notes, []byte("not an image"), 0644)
+	for _, p := range []string{notes, tmpDir} {
+		if result := callTool(t, HandleGetImageInfo, reg, map[string]any{"path": p}); !result.IsError {
+			t.Errorf("expected error for %s", p)
+		}
+	}
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/mimetype"
 	"github.com/portertech/filesystem-mcp-server/internal/registry"
 	"github.com/portertech/filesystem-mcp-server/pkg/filesystem"
 	"github.com/spf13/cast"
@@ -17,7 +20,7 @@ import (
 func NewGetFileInfoTool(reg *registry.Registry) mcp.Tool {
 	return mcp.NewTool(
 		"get_file_info",
-		mcp.WithDescription("Get detailed metadata about a file or directory."),
+		mcp.WithDescription("Get detailed metadata about a file or directory. For files, also the MIME type detected from the content and whether the file is binary, which read_text_file refuses to read as text."),
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithString("path", mcp.Description("Path to the file or directory"), mcp.Required()),
 	)
@@ -27,7 +30,7 @@ func NewGetFileInfoTool(reg *registry.Registry) mcp.Tool {
 func HandleGetFileInfo(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	path := cast.ToString(request.Params.Arguments["path"])
 
-	resolvedPath, err := reg.Validate(path)
+	resolvedPath, err := validateRead(reg, path)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
 	}
@@ -44,6 +47,15 @@ func HandleGetFileInfo(ctx context.Context, reg *registry.Registry, request mcp.
 		IsFile:      !info.IsDir(),
 		Permissions: fmt.Sprintf("%04o", info.Mode().Perm()),
 	}
+	if !info.IsDir() {
+		mimeType, binary, err := sniffContent(reg, resolvedPath, info.Size())
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to read file: %w", err).Error()), nil
+		}
+		fileInfo.MIMEType = mimeType
+		fileInfo.IsBinary = &binary
+		fileInfo.Lock = lockInfo(reg, resolvedPath)
+	}
 
 	jsonResult, err := json.MarshalIndent(fileInfo, "", "  ")
 	if err != nil {
@@ -53,11 +65,31 @@ func HandleGetFileInfo(ctx context.Context, reg *registry.Registry, request mcp.
 	return mcp.NewToolResultText(string(jsonResult)), nil
 }
 
+// sniffContent detects the MIME type of the file at path from its first
+// bytes, preferring a -mime-type override for its extension and the media
+// formats read_media_file knows, and reports whether it is binary by the
+// same test read_text_file uses.
+func sniffContent(reg *registry.Registry, path string, size int64) (string, bool, error) {
+	head, err := readHead(path, binarySniffLen)
+	if err != nil {
+		return "", false, err
+	}
+	binary := looksBinary(head) || !isText(head, int64(len(head)) < size)
+
+	if t, ok := reg.MIMEType(filepath.Ext(path)); ok {
+		return t, binary, nil
+	}
+	if t := mimetype.Detect(head); t != "" {
+		return t, binary, nil
+	}
+	return http.DetectContentType(head), binary, nil
+}
+
 // NewListAllowedDirectoriesTool creates the list_allowed_directories tool.
 func NewListAllowedDirectoriesTool(reg *registry.Registry) mcp.Tool {
 	return mcp.NewTool(
 		"list_allowed_directories",
-		mcp.WithDescription("List all directories that are allowed to be accessed."),
+		mcp.WithDescription("List all directories that are allowed to be accessed, which of them are read-only, any aliases that can stand in for them in paths, and any active write grants."),
 		mcp.WithReadOnlyHintAnnotation(true),
 	)
 }
@@ -71,8 +103,28 @@ func HandleListAllowedDirectories(ctx context.Context, reg *registry.Registry, r
 	}
 
 	result := "Allowed directories:\n"
-	for _, d := range dirs {
-		result += fmt.Sprintf("  %s\n", d)
+	for i, d := range dirs {
+		result += fmt.Sprintf("  [%d] %s", i, d)
+		if reg.IsReadOnly(d) {
+			result += " (read-only)"
+		}
+		result += "\n"
+	}
+
+	if aliases := reg.Aliases(); len(aliases) > 0 {
+		result += "\nAliases (use as alias:/relative/path):\n"
+		for _, a := range aliases {
+			result += fmt.Sprintf("  %s: %s\n", a.Name, a.Dir)
+		}
+	}
+
+	if grants := reg.Grants(); grants != nil {
+		if active := grants.Active(); len(active) > 0 {
+			result += "\nActive write grants:\n"
+			for _, s := range active {
+				result += fmt.Sprintf("  %s: %s writable %s\n", s.ID, s.Root, describeGrantLimits(s))
+			}
+		}
 	}
 
 	return mcp.NewToolResultText(result), nil
@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/portertech/filesystem-mcp-server/pkg/filesystem"
+)
+
+func TestHandleGetFileInfo(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	reg.SetMIMETypes(map[string]string{".raw": "image/x-raw"})
+	files := map[string]string{
+		"notes":     "plain text\n",
+		"page.html": "<!DOCTYPE html><html></html>",
+		"image.dat": "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR",
+		"doc.pdf":   "%PDF-1.7\n",
+		"latin1":    "caf\xe9\n",
+		"photo.raw": "\x00\x01\x02",
+	}
+	for name, content := range files {
+		os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644)
+	}
+
+	tests := []struct {
+		name     string
+		mimeType string
+		binary   bool
+	}{
+		{"notes", "text/plain; charset=utf-8", false},
+		{"page.html", "text/html; charset=utf-8", false},
+		{"image.dat", "image/png", true},
+		{"doc.pdf", "application/pdf", false},
+		{"latin1", "text/plain; charset=utf-8", true},
+		{"photo.raw", "image/x-raw", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := callTool(t, HandleGetFileInfo, reg, map[string]any{"path": filepath.Join(tmpDir, tt.name)})
+			if result.IsError {
+				t.Fatalf("unexpected error: %s", resultText(result))
+			}
+			var info filesystem.FileInfo
+			if err := json.Unmarshal([]byte(resultText(result)), &info); err != nil {
+				t.Fatal(err)
+			}
+			if info.MIMEType != tt.mimeType || info.IsBinary == nil || *info.IsBinary != tt.binary {
+				t.Errorf("got %s", info)
+			}
+		})
+	}
+
+	result := callTool(t, HandleGetFileInfo, reg, map[string]any{"path": tmpDir})
+	var info filesystem.FileInfo
+	if err := json.Unmarshal([]byte(resultText(result)), &info); err != nil {
+		t.Fatal(err)
+	}
+	if !info.IsDirectory || info.MIMEType != "" || info.IsBinary != nil {
+		t.Errorf("expected no content fields for a directory, got %s", info)
+	}
+}
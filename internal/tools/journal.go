@@ -0,0 +1,203 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/journal"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/security"
+	"github.com/spf13/cast"
+)
+
+// defaultListOperations is how many operations list_operations returns
+// unless the call sets limit.
+const defaultListOperations = 20
+
+// beginJournal starts recording an operation of tool on path in the undo
+// journal. It returns nil, which records nothing, when undo is off.
+func beginJournal(reg *registry.Registry, tool, path string) *journal.Pending {
+	return reg.Journal().Begin(tool, containingRoot(reg, path))
+}
+
+// journalNote commits op and returns a note for the tool's result if the
+// operation could not be recorded for undo.
+func journalNote(op *journal.Pending) string {
+	if err := op.Commit(); err != nil {
+		return fmt.Sprintf(" (undo journal: %v)", err)
+	}
+	return ""
+}
+
+// NewListOperationsTool creates the list_operations tool.
+func NewListOperationsTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"list_operations",
+		mcp.WithDescription("List the changes made by write_file, write_file_range, edit_file, filter_file, move_file, delete_file, batch_operations, and apply_patch that undo_operation can revert, newest first."),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "List Operations",
+			ReadOnlyHint: boolPtr(true),
+		}),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Maximum number of operations to return (default: %d)", defaultListOperations))),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleListOperations handles the list_operations tool.
+func HandleListOperations(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	limit := defaultListOperations
+	if v, ok := request.Params.Arguments["limit"]; ok {
+		limit = cast.ToInt(v)
+	}
+	format := cast.ToString(request.Params.Arguments["format"])
+	if limit <= 0 {
+		return mcp.NewToolResultError("limit must be positive"), nil
+	}
+
+	ops, err := reg.Journal().List(reg.GetResolved())
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to list operations: %w", err).Error()), nil
+	}
+	if len(ops) > limit {
+		ops = ops[:limit]
+	}
+
+	if format == "json" {
+		if ops == nil {
+			ops = []journal.Operation{}
+		}
+		data, _ := json.MarshalIndent(ops, "", "  ")
+		return mcp.NewToolResultText(string(data)), nil
+	}
+
+	if len(ops) == 0 {
+		return mcp.NewToolResultText("No operations recorded"), nil
+	}
+	var text strings.Builder
+	for _, op := range ops {
+		fmt.Fprintf(&text, "%s  %s  %s  %s", op.ID, op.Time.Local().Format("2006-01-02 15:04:05"), op.Tool, describeChanges(op))
+		if op.Error != "" {
+			fmt.Fprintf(&text, " (cannot be undone: %s)", op.Error)
+		}
+		text.WriteString("\n")
+	}
+	return mcp.NewToolResultText(strings.TrimSuffix(text.String(), "\n")), nil
+}
+
+// NewUndoOperationTool creates the undo_operation tool.
+func NewUndoOperationTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"undo_operation",
+		mcp.WithDescription("Revert changes recorded by the undo journal: the operation with the given ID, or the most recent count operations, newest first. Refuses to discard a file that changed again after the operation unless force is set."),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Undo Operation",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(true),
+			IdempotentHint:  boolPtr(false),
+		}),
+		mcp.WithString("id", mcp.Description("ID of the operation to undo, from list_operations")),
+		mcp.WithNumber("count", mcp.Description("Number of most recent operations to undo when no id is given (default: 1)")),
+		mcp.WithBoolean("force", mcp.Description("Undo even if a file changed again since the operation, discarding the later change")),
+		withConfirmationToken(),
+		withApprovalToken(),
+	)
+}
+
+// HandleUndoOperation handles the undo_operation tool.
+func HandleUndoOperation(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	id := cast.ToString(request.Params.Arguments["id"])
+	count := 1
+	if v, ok := request.Params.Arguments["count"]; ok {
+		count = cast.ToInt(v)
+	}
+	force := cast.ToBool(request.Params.Arguments["force"])
+	if id != "" && count != 1 {
+		return mcp.NewToolResultError("count cannot be combined with id"), nil
+	}
+	if count <= 0 {
+		return mcp.NewToolResultError("count must be positive"), nil
+	}
+
+	j := reg.Journal()
+	roots := reg.GetResolved()
+	ops, err := j.List(roots)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to list operations: %w", err).Error()), nil
+	}
+	var selected []journal.Operation
+	if id != "" {
+		for _, op := range ops {
+			if op.ID == id {
+				selected = append(selected, op)
+			}
+		}
+		if len(selected) == 0 {
+			return mcp.NewToolResultError(fmt.Errorf("failed to undo %s: %w", id, journal.ErrNotFound).Error()), nil
+		}
+	} else {
+		if len(ops) == 0 {
+			return mcp.NewToolResultError("no operations to undo"), nil
+		}
+		selected = ops[:min(count, len(ops))]
+	}
+
+	var paths []string
+	for _, op := range selected {
+		for _, c := range op.Changes {
+			paths = append(paths, c.Path)
+			if c.MovedTo != "" {
+				paths = append(paths, c.MovedTo)
+			}
+		}
+	}
+	action := fmt.Sprintf("undo %d operations", len(selected))
+	if len(selected) == 1 {
+		action = fmt.Sprintf("undo %s of %s", selected[0].Tool, describeChanges(selected[0]))
+	}
+	if isProtected(reg, paths...) {
+		if result := requireApproval(reg, "undo_operation", request, action, paths...); result != nil {
+			return result, nil
+		}
+	} else if result := requireConfirmation(reg, "undo_operation", request, action); result != nil {
+		return result, nil
+	}
+
+	check := func(path string) error {
+		if _, err := reg.ValidateForCreation(path); err != nil {
+			return fmt.Errorf("path validation failed: %w", err)
+		}
+		if err := security.ValidateNoSymlinksInPath(path, reg.Get()); err != nil {
+			return fmt.Errorf("path validation failed: %w", err)
+		}
+		return reg.CheckWrite(path)
+	}
+	var text strings.Builder
+	for _, op := range selected {
+		if _, err := j.Undo(roots, op.ID, force, check); err != nil {
+			hint := ""
+			if errors.Is(err, journal.ErrConflict) {
+				hint = "; set force=true to discard the later change"
+			}
+			fmt.Fprintf(&text, "Failed to undo %s: %v%s", op.ID, err, hint)
+			return mcp.NewToolResultError(text.String()), nil
+		}
+		fmt.Fprintf(&text, "Undid %s: %s %s\n", op.ID, op.Tool, describeChanges(op))
+	}
+	return mcp.NewToolResultText(strings.TrimSuffix(text.String(), "\n")), nil
+}
+
+// describeChanges summarizes the paths an operation changed.
+func describeChanges(op journal.Operation) string {
+	parts := make([]string, len(op.Changes))
+	for i, c := range op.Changes {
+		parts[i] = c.Path
+		if c.MovedTo != "" {
+			parts[i] += " -> " + c.MovedTo
+		}
+	}
+	return strings.Join(parts, ", ")
+}
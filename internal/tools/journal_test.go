@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/portertech/filesystem-mcp-server/internal/journal"
+	"github.com/portertech/filesystem-mcp-server/internal/retention"
+)
+
+func TestUndoOperations(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	reg.SetJournal(journal.New(retention.Policy{MaxBytes: journal.DefaultMaxBytes}, nil))
+	a := filepath.Join(tmpDir, "a.txt")
+	b := filepath.Join(tmpDir, "b.txt")
+	os.WriteFile(a, []byte("hello world"), 0644)
+
+	for _, call := range []struct {
+		name   string
+		result func() bool
+	}{
+		{"write", func() bool {
+			return callTool(t, HandleWriteFile, reg, map[string]any{"path": a, "content": "rewritten"}).IsError
+		}},
+		{"edit", func() bool {
+			return callTool(t, HandleEditFile, reg, map[string]any{"path": a, "edits": []any{map[string]any{"oldText": "rewritten", "newText": "edited"}}}).IsError
+		}},
+		{"move", func() bool {
+			return callTool(t, HandleMoveFile, reg, map[string]any{"source": a, "destination": b}).IsError
+		}},
+		{"delete", func() bool {
+			return callTool(t, HandleDeleteFile, reg, map[string]any{"path": b}).IsError
+		}},
+	} {
+		if call.result() {
+			t.Fatalf("%s failed", call.name)
+		}
+	}
+
+	result := callTool(t, HandleListOperations, reg, map[string]any{})
+	if lines := strings.Split(resultText(result), "\n"); len(lines) != 4 || !strings.Contains(lines[0], "delete_file") || !strings.Contains(lines[3], "write_file") {
+		t.Fatalf("unexpected listing: %s", resultText(result))
+	}
+
+	// Undo the delete and the move
+	if result := callTool(t, HandleUndoOperation, reg, map[string]any{"count": 2}); result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if data, _ := os.ReadFile(a); string(data) != "edited" {
+		t.Errorf("unexpected content: %q", data)
+	}
+	if _, err := os.Stat(b); !os.IsNotExist(err) {
+		t.Error("expected the move to be undone")
+	}
+
+	// A change made outside the journal blocks undo without force
+	os.WriteFile(a, []byte("changed by hand"), 0644)
+	if result := callTool(t, HandleUndoOperation, reg, map[string]any{}); !result.IsError || !strings.Contains(resultText(result), "force=true") {
+		t.Fatalf("expected a conflict, got %s", resultText(result))
+	}
+	if result := callTool(t, HandleUndoOperation, reg, map[string]any{"count": 2, "force": true}); result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if data, _ := os.ReadFile(a); string(data) != "hello world" {
+		t.Errorf("unexpected content: %q", data)
+	}
+	if result := callTool(t, HandleUndoOperation, reg, map[string]any{}); !result.IsError {
+		t.Error("expected an empty journal to have nothing to undo")
+	}
+}
+
+func TestUndoBatchAndPatch(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	reg.SetJournal(journal.New(retention.Policy{MaxBytes: journal.DefaultMaxBytes}, nil))
+	a := filepath.Join(tmpDir, "a.txt")
+	b := filepath.Join(tmpDir, "sub", "b.txt")
+	stale := filepath.Join(tmpDir, "stale.txt")
+	os.WriteFile(a, []byte("one\n"), 0644)
+	os.WriteFile(stale, []byte("stale"), 0644)
+
+	ops := []any{
+		map[string]any{"op": "move", "source": a, "destination": b},
+		map[string]any{"op": "edit", "path": b, "edits": []any{map[string]any{"oldText": "one", "newText": "two"}}},
+		map[string]any{"op": "delete", "path": stale},
+	}
+	if result := callTool(t, HandleBatchOperations, reg, map[string]any{"operations": ops}); result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	diff := "--- a/sub/b.txt\n+++ b/sub/b.txt\n@@ -1 +1 @@\n-two\n+three\n"
+	if result := callTool(t, HandleApplyPatch, reg, map[string]any{"patch": diff, "path": tmpDir}); result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+
+	result := callTool(t, HandleListOperations, reg, map[string]any{})
+	if lines := strings.Split(resultText(result), "\n"); len(lines) != 2 || !strings.Contains(lines[0], "apply_patch") || !strings.Contains(lines[1], "batch_operations") {
+		t.Fatalf("unexpected listing: %s", resultText(result))
+	}
+	if result := callTool(t, HandleUndoOperation, reg, map[string]any{}); result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if data, _ := os.ReadFile(b); string(data) != "two\n" {
+		t.Errorf("expected the patch to be undone, got %q", data)
+	}
+	if result := callTool(t, HandleUndoOperation, reg, map[string]any{}); result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if data, _ := os.ReadFile(a); string(data) != "one\n" {
+		t.Errorf("expected the batch to be undone, got %q", data)
+	}
+	if data, _ := os.ReadFile(stale); string(data) != "stale" {
+		t.Errorf("expected the deleted file to be restored, got %q", data)
+	}
+	if _, err := os.Stat(b); !os.IsNotExist(err) {
+		t.Error("expected the move to be undone")
+	}
+}
+
+func TestJournalDirectory(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	reg.SetJournal(journal.New(retention.Policy{}, nil))
+	f := filepath.Join(tmpDir, "f.txt")
+	callTool(t, HandleWriteFile, reg, map[string]any{"path": f, "content": "x"})
+
+	if result := callTool(t, HandleDeleteDirectory, reg, map[string]any{"path": filepath.Join(tmpDir, journal.DirName), "recursive": true}); !result.IsError {
+		t.Error("expected deleting the journal directory to be refused")
+	}
+	if result := callTool(t, HandleUndoOperation, reg, map[string]any{"id": "missing"}); !result.IsError {
+		t.Error("expected an unknown ID to be refused")
+	}
+	result := callTool(t, HandleListOperations, reg, map[string]any{"format": "json"})
+	if result.IsError || !strings.Contains(resultText(result), `"tool": "write_file"`) {
+		t.Errorf("unexpected listing: %s", resultText(result))
+	}
+}
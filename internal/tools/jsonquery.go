@@ -0,0 +1,152 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/jsonquery"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/spf13/cast"
+)
+
+const (
+	defaultQueryMatches = 100
+	// maxQueryOutput caps the total size of the values json_query returns.
+	maxQueryOutput = 1024 * 1024
+)
+
+// errQueryOutput is returned when the matches of a query exceed
+// maxQueryOutput.
+var errQueryOutput = errors.New("output too large")
+
+// NewJSONQueryTool creates the json_query tool.
+func NewJSONQueryTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"json_query",
+		mcp.WithDescription("Evaluate a query against a JSON file and return only the matching values with their paths, instead of reading the whole file. The query is a JSONPath expression starting with $ (for example $.items[?(@.price < 10)].name or $..id), or a gjson path such as items.#.name or items.0.id."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path", mcp.Description("Path to the JSON file"), mcp.Required()),
+		mcp.WithString("query", mcp.Description("JSONPath expression or gjson path"), mcp.Required()),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Maximum number of matches to return (default: %d)", defaultQueryMatches))),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleJSONQuery handles the json_query tool.
+func HandleJSONQuery(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	expr := cast.ToString(request.Params.Arguments["query"])
+	limit := defaultQueryMatches
+	if v, ok := request.Params.Arguments["limit"]; ok {
+		limit = cast.ToInt(v)
+	}
+	format := cast.ToString(request.Params.Arguments["format"])
+	if limit <= 0 {
+		return mcp.NewToolResultError("limit must be positive"), nil
+	}
+	q, err := jsonquery.Parse(expr)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	resolvedPath, err := validateRead(reg, path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	f, err := os.Open(resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to open file: %w", err).Error()), nil
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to stat file: %w", err).Error()), nil
+	}
+	if info.IsDir() {
+		return mcp.NewToolResultError("path is a directory, not a file"), nil
+	}
+	match, err := checkReputation(reg, resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// A streaming query keeps only its matches; any other may hold the
+	// whole document
+	reserve := int64(maxQueryOutput)
+	if !q.Streams() {
+		reserve = max(reserve, info.Size())
+	}
+	release, err := reserveMemory(ctx, reg, resolvedPath, reserve)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	defer release()
+
+	var matches []jsonquery.Match
+	var size int
+	limited := false
+	err = q.Eval(bufio.NewReader(f), func(m jsonquery.Match) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if len(matches) == limit {
+			limited = true
+			return jsonquery.ErrLimit
+		}
+		size += len(m.Path) + len(m.Value)
+		if size > maxQueryOutput {
+			return errQueryOutput
+		}
+		matches = append(matches, m)
+		return nil
+	})
+	if errors.Is(err, errQueryOutput) {
+		return mcp.NewToolResultError(fmt.Sprintf("matches exceed %s; narrow the query or lower limit", stream.FormatSize(maxQueryOutput))), nil
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to query %s: %w", path, err).Error()), nil
+	}
+
+	if format == "json" {
+		if matches == nil {
+			matches = []jsonquery.Match{}
+		}
+		data, err := json.MarshalIndent(matches, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return withReputationWarning(mcp.NewToolResultText(string(data)), match), nil
+	}
+
+	var text strings.Builder
+	switch len(matches) {
+	case 0:
+		fmt.Fprintf(&text, "No matches for %s", q)
+	case 1:
+		var b bytes.Buffer
+		if err := json.Indent(&b, matches[0].Value, "", "  "); err != nil {
+			b.Write(matches[0].Value)
+		}
+		fmt.Fprintf(&text, "%s:\n%s\n", matches[0].Path, b.String())
+	default:
+		for _, m := range matches {
+			var b bytes.Buffer
+			if err := json.Compact(&b, m.Value); err != nil {
+				b.Write(m.Value)
+			}
+			fmt.Fprintf(&text, "%s: %s\n", m.Path, b.String())
+		}
+	}
+	if limited {
+		fmt.Fprintf(&text, "Stopped at %d matches; raise limit to see more\n", limit)
+	}
+	return withReputationWarning(mcp.NewToolResultText(strings.TrimSuffix(text.String(), "\n")), match), nil
+}
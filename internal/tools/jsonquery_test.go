@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHandleJSONQuery(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	path := filepath.Join(tmpDir, "data.json")
+	doc := `{"items": [{"id": 1, "name": "apple", "price": 3}, {"id": 2, "name": "pear", "price": 12}, {"id": 3, "name": "plum", "tags": {"color": "purple"}}]}`
+	if err := os.WriteFile(path, []byte(doc), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	text := resultText(callTool(t, HandleJSONQuery, reg, map[string]any{"path": path, "query": "$.items[2].tags"}))
+	if want := "$.items[2].tags:\n{\n  \"color\": \"purple\"\n}"; text != want {
+		t.Errorf("got %q, want %q", text, want)
+	}
+
+	text = resultText(callTool(t, HandleJSONQuery, reg, map[string]any{"path": path, "query": "items.#.name"}))
+	if want := "$.items[0].name: \"apple\"\n$.items[1].name: \"pear\"\n$.items[2].name: \"plum\""; text != want {
+		t.Errorf("got %q, want %q", text, want)
+	}
+
+	text = resultText(callTool(t, HandleJSONQuery, reg, map[string]any{"path": path, "query": "$..id", "limit": 2}))
+	if !strings.Contains(text, "$.items[1].id: 2") || strings.Contains(text, "$.items[2]") || !strings.Contains(text, "Stopped at 2 matches") {
+		t.Errorf("unexpected limited result:\n%s", text)
+	}
+
+	text = resultText(callTool(t, HandleJSONQuery, reg, map[string]any{"path": path, "query": "$.items[?(@.price > 5)]", "format": "json"}))
+	var matches []struct {
+		Path  string
+		Value map[string]any
+	}
+	if err := json.Unmarshal([]byte(text), &matches); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, text)
+	}
+	if len(matches) != 1 || matches[0].Path != "$.items[1]" || matches[0].Value["name"] != "pear" {
+		t.Errorf("unexpected matches: %+v", matches)
+	}
+
+	if text := resultText(callTool(t, HandleJSONQuery, reg, map[string]any{"path": path, "query": "$.missing"})); text != "No matches for $.missing" {
+		t.Errorf("got %q", text)
+	}
+	if text := resultText(callTool(t, HandleJSONQuery, reg, map[string]any{"path": path, "query": "$.missing", "format": "json"})); text != "[]" {
+		t.Errorf("got %q", text)
+	}
+
+	bad := filepath.Join(tmpDir, "bad.json")
+	if err := os.WriteFile(bad, []byte(`{"a": [1, 2`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for name, args := range map[string]map[string]any{
+		"invalid query": {"path": path, "query": "$["},
+		"invalid JSON":  {"path": bad, "query": "$.a"},
+		"zero limit":    {"path": path, "query": "$", "limit": 0},
+		"directory":     {"path": tmpDir, "query": "$"},
+		"outside":       {"path": "/etc/passwd", "query": "$"},
+	} {
+		if result := callTool(t, HandleJSONQuery, reg, args); !result.IsError {
+			t.Errorf("%s: expected an error, got %s", name, resultText(result))
+		}
+	}
+}
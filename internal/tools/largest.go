@@ -0,0 +1,225 @@
+package tools
+
+import (
+	"container/heap"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gobwas/glob"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/spf13/cast"
+)
+
+const (
+	defaultLargestFiles = 20
+	maxLargestFiles     = 1000
+)
+
+// sizedFile is a file found by find_largest_files.
+type sizedFile struct {
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+// sizedFileHeap is a min-heap by size, so the smallest of the current top-N
+// is evicted first.
+type sizedFileHeap []sizedFile
+
+func (h sizedFileHeap) Len() int { return len(h) }
+func (h sizedFileHeap) Less(i, j int) bool {
+	if h[i].Size != h[j].Size {
+		return h[i].Size < h[j].Size
+	}
+	return h[i].Path > h[j].Path
+}
+func (h sizedFileHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *sizedFileHeap) Push(x any)   { *h = append(*h, x.(sizedFile)) }
+func (h *sizedFileHeap) Pop() any {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// NewFindLargestFilesTool creates the find_largest_files tool.
+func NewFindLargestFilesTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"find_largest_files",
+		mcp.WithDescription("Find the largest files under a directory, biggest first."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path", mcp.Description("Directory to search"), mcp.Required()),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Number of files to return (default: %d, max: %d)", defaultLargestFiles, maxLargestFiles))),
+		mcp.WithString("minSize", mcp.Description("Ignore files smaller than this, in bytes or with a unit suffix (e.g. '500KB', '10MB', '1GB')")),
+		mcp.WithArray("excludePatterns", mcp.Description("Glob patterns to exclude"), mcp.Items(map[string]any{"type": "string"})),
+		withMaxFilesParam(),
+		mcp.WithString("format", mcp.Description("Output format: 'text', 'json', 'markdown', or 'csv'")),
+	)
+}
+
+// HandleFindLargestFiles handles the find_largest_files tool.
+func HandleFindLargestFiles(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	limit := cast.ToInt(request.Params.Arguments["limit"])
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	if limit <= 0 {
+		limit = defaultLargestFiles
+	}
+	if limit > maxLargestFiles {
+		limit = maxLargestFiles
+	}
+
+	minSize, err := parseSize(cast.ToString(request.Params.Arguments["minSize"]))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("invalid minSize: %w", err).Error()), nil
+	}
+
+	var excludeGlobs []glob.Glob
+	if patternsArg, ok := request.Params.Arguments["excludePatterns"].([]interface{}); ok {
+		for _, p := range patternsArg {
+			globs, err := compileGlobs(cast.ToString(p))
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid exclude pattern %q: %v", cast.ToString(p), err)), nil
+			}
+			excludeGlobs = append(excludeGlobs, globs...)
+		}
+	}
+
+	resolvedPath, errResult := resolveDirectory(reg, path)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	budget, err := parseMaxFiles(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	top := &sizedFileHeap{}
+	var scanned int
+	var walkErrs walkErrors
+	// Ignored build output and dependencies are often what takes the space
+	filter := treeFilter{excludeGlobs: excludeGlobs, onError: walkErrs.add}
+	filter.onDir = func(dirPath, relPath string) error {
+		if !budget.take() {
+			return errMaxFiles
+		}
+		return nil
+	}
+	err = walkTree(ctx, reg, resolvedPath, filter, func(walkPath, relPath string, entry fs.DirEntry) error {
+		if !budget.take() {
+			return errMaxFiles
+		}
+		fileInfo, err := entry.Info()
+		if err != nil {
+			walkErrs.add(walkPath, err)
+			return nil
+		}
+		scanned++
+		if fileInfo.Size() < minSize {
+			return nil
+		}
+
+		f := sizedFile{Path: walkPath, Size: fileInfo.Size(), Modified: fileInfo.ModTime()}
+		if top.Len() < limit {
+			heap.Push(top, f)
+		} else if (*top)[0].Size < f.Size {
+			(*top)[0] = f
+			heap.Fix(top, 0)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errMaxFiles) {
+		return mcp.NewToolResultError(fmt.Errorf("search failed: %w", err).Error()), nil
+	}
+
+	files := []sizedFile(*top)
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Size != files[j].Size {
+			return files[i].Size > files[j].Size
+		}
+		return files[i].Path < files[j].Path
+	})
+
+	if format == "json" {
+		if files == nil {
+			files = []sizedFile{}
+		}
+		jsonResult, err := json.MarshalIndent(files, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return withWalkErrors(withBudgetNote(mcp.NewToolResultText(string(jsonResult)), budget), &walkErrs), nil
+	}
+
+	if format == "csv" {
+		rows := make([][]string, 0, len(files))
+		for _, f := range files {
+			rows = append(rows, []string{csvText(f.Path), strconv.FormatInt(f.Size, 10), f.Modified.UTC().Format(time.RFC3339)})
+		}
+		return withWalkErrors(withBudgetNote(mcp.NewToolResultText(formatCSV([]string{"path", "size", "modified"}, rows)), budget), &walkErrs), nil
+	}
+
+	if len(files) == 0 {
+		return withWalkErrors(withBudgetNote(mcp.NewToolResultText("No files found"), budget), &walkErrs), nil
+	}
+
+	var result strings.Builder
+	var total int64
+	if format == "markdown" {
+		rows := make([][]string, 0, len(files))
+		for _, f := range files {
+			rows = append(rows, []string{stream.FormatSize(f.Size), markdownCode(f.Path), f.Modified.UTC().Format(time.RFC3339)})
+			total += f.Size
+		}
+		result.WriteString(markdownTable([]string{"Size", "Path", "Modified"}, rows))
+	} else {
+		for _, f := range files {
+			fmt.Fprintf(&result, "%10s  %s\n", stream.FormatSize(f.Size), f.Path)
+			total += f.Size
+		}
+	}
+	fmt.Fprintf(&result, "\nTop %d of %d files scanned, %s total", len(files), scanned, stream.FormatSize(total))
+
+	return withWalkErrors(withBudgetNote(mcp.NewToolResultText(result.String()), budget), &walkErrs), nil
+}
+
+// parseSize parses a byte count with an optional binary unit suffix
+// (B, KB, MB, GB, TB). An empty string is zero.
+func parseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s == "" {
+		return 0, nil
+	}
+
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{
+		{"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1},
+	} {
+		if strings.HasSuffix(s, unit.suffix) {
+			multiplier = unit.size
+			s = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix))
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("cannot parse %q as a size", s)
+	}
+	return int64(n * float64(multiplier)), nil
+}
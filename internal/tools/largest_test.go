@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"512", 512, false},
+		{"10B", 10, false},
+		{"2KB", 2048, false},
+		{"1.5 MB", 1536 * 1024, false},
+		{"1gb", 1 << 30, false},
+		{"lots", 0, true},
+		{"-1KB", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseSize(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSize(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseSize(%q) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindLargestFiles(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	if err := os.MkdirAll(filepath.Join(tmpDir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, "vendor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	sizes := map[string]int{
+		"small.txt":                        10,
+		"medium.txt":                       500,
+		filepath.Join("sub", "big.bin"):    4000,
+		filepath.Join("sub", "huge.bin"):   9000,
+		filepath.Join("vendor", "lib.bin"): 20000,
+	}
+	for name, size := range sizes {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result := callTool(t, HandleFindLargestFiles, reg, map[string]any{
+		"path":            tmpDir,
+		"limit":           2,
+		"excludePatterns": []interface{}{"vendor"},
+	})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	lines := strings.Split(resultText(result), "\n")
+	if !strings.HasSuffix(lines[0], "huge.bin") || !strings.HasSuffix(lines[1], "big.bin") {
+		t.Errorf("expected huge.bin then big.bin, got %q", resultText(result))
+	}
+
+	result = callTool(t, HandleFindLargestFiles, reg, map[string]any{"path": tmpDir, "minSize": "100", "format": "json"})
+	text := resultText(result)
+	if strings.Contains(text, "small.txt") || !strings.Contains(text, "lib.bin") || !strings.Contains(text, "medium.txt") {
+		t.Errorf("unexpected minSize filtering: %s", text)
+	}
+
+	// .git is skipped, and maxFiles stops the walk with a note
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, ".git", "pack.bin"), make([]byte, 50000), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result = callTool(t, HandleFindLargestFiles, reg, map[string]any{"path": tmpDir, "limit": 1})
+	if text := resultText(result); strings.Contains(text, "pack.bin") {
+		t.Errorf(".git should be skipped: %s", text)
+	}
+	result = callTool(t, HandleFindLargestFiles, reg, map[string]any{"path": tmpDir, "maxFiles": 2})
+	if text := resultText(result); !strings.Contains(text, "raise maxFiles") {
+		t.Errorf("expected a file limit note: %s", text)
+	}
+}
@@ -0,0 +1,307 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/spf13/cast"
+)
+
+const (
+	// headerScanLen is how much of each file is searched for an SPDX header.
+	headerScanLen = 4096
+	// maxListedFiles caps the files listed per section of the text report.
+	maxListedFiles = 100
+	spdxTag        = "SPDX-License-Identifier:"
+)
+
+// defaultHeaderPatterns selects the source files expected to carry a license
+// header when no patterns are given.
+var defaultHeaderPatterns = []string{
+	"**.go", "**.c", "**.h", "**.cc", "**.cpp", "**.hpp", "**.cs", "**.java", "**.kt", "**.scala",
+	"**.js", "**.jsx", "**.ts", "**.tsx", "**.py", "**.rb", "**.rs", "**.php", "**.swift", "**.sh",
+}
+
+// licenseFileNames are the base names, without extension, of license files.
+var licenseFileNames = map[string]bool{
+	"license": true, "licence": true, "copying": true, "copying.lesser": true,
+	"notice": true, "unlicense": true, "license-mit": true, "license-apache": true,
+}
+
+// licenseSignatures identify well-known license texts. All phrases of a
+// signature must appear; earlier signatures take precedence.
+var licenseSignatures = []struct {
+	id      string
+	phrases []string
+}{
+	{"AGPL-3.0", []string{"gnu affero general public license", "version 3"}},
+	{"LGPL-3.0", []string{"gnu lesser general public license", "version 3"}},
+	{"LGPL-2.1", []string{"gnu lesser general public license", "version 2.1"}},
+	{"GPL-3.0", []string{"gnu general public license", "version 3"}},
+	{"GPL-2.0", []string{"gnu general public license", "version 2"}},
+	{"Apache-2.0", []string{"apache license", "version 2.0"}},
+	{"MPL-2.0", []string{"mozilla public license", "2.0"}},
+	{"BSD-3-Clause", []string{"redistribution and use in source and binary forms", "neither the name"}},
+	{"BSD-2-Clause", []string{"redistribution and use in source and binary forms"}},
+	{"ISC", []string{"permission to use, copy, modify, and/or distribute this software"}},
+	{"MIT", []string{"permission is hereby granted, free of charge"}},
+	{"Unlicense", []string{"this is free and unencumbered software released into the public domain"}},
+}
+
+// licenseFile is a license file found by scan_licenses.
+type licenseFile struct {
+	Path    string `json:"path"`
+	License string `json:"license"`
+}
+
+// headerMismatch is a file whose SPDX header differs from the required one.
+type headerMismatch struct {
+	Path    string `json:"path"`
+	License string `json:"license"`
+}
+
+// licenseReport is the result of scan_licenses.
+type licenseReport struct {
+	Root         string           `json:"root"`
+	LicenseFiles []licenseFile    `json:"licenseFiles"`
+	Headers      map[string]int   `json:"headers"`
+	Checked      int              `json:"checked"`
+	Missing      []string         `json:"missing"`
+	Mismatched   []headerMismatch `json:"mismatched,omitempty"`
+}
+
+// NewScanLicensesTool creates the scan_licenses tool.
+func NewScanLicensesTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"scan_licenses",
+		mcp.WithDescription("Find license files (LICENSE, COPYING, NOTICE, ...) under a directory and identify common licenses, count SPDX-License-Identifier headers in source files, and report source files missing a header or carrying a different license than required. Honors .gitignore."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path", mcp.Description("Directory to scan"), mcp.Required()),
+		mcp.WithArray("patterns", mcp.Description("Glob patterns selecting files that need a license header (default: common source file extensions)"), mcp.Items(map[string]any{"type": "string"})),
+		mcp.WithArray("excludePatterns", mcp.Description("Glob patterns to exclude"), mcp.Items(map[string]any{"type": "string"})),
+		mcp.WithString("requiredLicense", mcp.Description("SPDX expression every header must match, e.g. 'Apache-2.0'")),
+		mcp.WithBoolean("respectGitignore", mcp.Description("If false, also scan files ignored by .gitignore (default: true)")),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleScanLicenses handles the scan_licenses tool.
+func HandleScanLicenses(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	required := strings.TrimSpace(cast.ToString(request.Params.Arguments["requiredLicense"]))
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	filter, err := parseTreeFilter(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	// License files are found anywhere; patterns only select header checks
+	headerGlobs := filter.matchGlobs
+	filter.matchGlobs = nil
+	if len(headerGlobs) == 0 {
+		for _, p := range defaultHeaderPatterns {
+			globs, err := compileGlobs(p)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid pattern %q: %v", p, err)), nil
+			}
+			headerGlobs = append(headerGlobs, globs...)
+		}
+	}
+
+	resolvedPath, errResult := resolveDirectory(reg, path)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	report, err := scanLicenses(ctx, reg, resolvedPath, filter, headerGlobs, required)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("scan failed: %w", err).Error()), nil
+	}
+
+	if format == "json" {
+		jsonResult, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(jsonResult)), nil
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "License scan of %s\n", report.Root)
+
+	result.WriteString("\nLicense files:\n")
+	if len(report.LicenseFiles) == 0 {
+		result.WriteString("  none found\n")
+	}
+	for _, f := range report.LicenseFiles {
+		fmt.Fprintf(&result, "  %s: %s\n", f.Path, f.License)
+	}
+
+	fmt.Fprintf(&result, "\nHeaders in %d checked files:\n", report.Checked)
+	ids := make([]string, 0, len(report.Headers))
+	for id := range report.Headers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		fmt.Fprintf(&result, "  %s: %d\n", id, report.Headers[id])
+	}
+	fmt.Fprintf(&result, "  (none): %d\n", len(report.Missing))
+
+	if len(report.Missing) > 0 {
+		fmt.Fprintf(&result, "\nMissing headers (%d):\n", len(report.Missing))
+		for i, p := range report.Missing {
+			if i == maxListedFiles {
+				fmt.Fprintf(&result, "  ... and %d more\n", len(report.Missing)-maxListedFiles)
+				break
+			}
+			fmt.Fprintf(&result, "  %s\n", p)
+		}
+	}
+	if len(report.Mismatched) > 0 {
+		fmt.Fprintf(&result, "\nNot %s (%d):\n", required, len(report.Mismatched))
+		for i, m := range report.Mismatched {
+			if i == maxListedFiles {
+				fmt.Fprintf(&result, "  ... and %d more\n", len(report.Mismatched)-maxListedFiles)
+				break
+			}
+			fmt.Fprintf(&result, "  %s: %s\n", m.Path, m.License)
+		}
+	}
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// scanLicenses walks root collecting license files and SPDX headers.
+func scanLicenses(ctx context.Context, reg *registry.Registry, root string, filter treeFilter, headerGlobs []glob.Glob, required string) (*licenseReport, error) {
+	report := &licenseReport{
+		Root:         root,
+		LicenseFiles: []licenseFile{},
+		Headers:      make(map[string]int),
+		Missing:      []string{},
+	}
+
+	err := walkTree(ctx, reg, root, filter, func(walkPath, relPath string, entry fs.DirEntry) error {
+		source, err := readTarget(reg, walkPath)
+		if err != nil {
+			return nil
+		}
+		if isLicenseFile(entry.Name()) {
+			report.LicenseFiles = append(report.LicenseFiles, licenseFile{Path: relPath, License: identifyLicenseFile(source)})
+			return nil
+		}
+		if !matchesAny(headerGlobs, relPath) {
+			return nil
+		}
+
+		id, ok := readSPDXHeader(source)
+		if !ok {
+			return nil
+		}
+		report.Checked++
+		if id == "" {
+			report.Missing = append(report.Missing, relPath)
+			return nil
+		}
+		report.Headers[id]++
+		if required != "" && !strings.EqualFold(id, required) {
+			report.Mismatched = append(report.Mismatched, headerMismatch{Path: relPath, License: id})
+		}
+		return nil
+	})
+	return report, err
+}
+
+// isLicenseFile reports whether name looks like a license file, such as
+// LICENSE, LICENSE.md, or COPYING.txt.
+func isLicenseFile(name string) bool {
+	name = strings.ToLower(name)
+	if licenseFileNames[name] {
+		return true
+	}
+	ext := path.Ext(name)
+	return (ext == ".md" || ext == ".txt" || ext == ".rst") && licenseFileNames[strings.TrimSuffix(name, ext)]
+}
+
+// identifyLicenseFile names the license in a license file by its SPDX
+// header or by well-known phrases, or returns "unknown".
+func identifyLicenseFile(filePath string) string {
+	data, err := readHead(filePath, 64*1024)
+	if err != nil {
+		return "unreadable"
+	}
+	if id := findSPDX(string(data)); id != "" {
+		return id
+	}
+
+	text := strings.Join(strings.Fields(strings.ToLower(string(data))), " ")
+	for _, sig := range licenseSignatures {
+		matched := true
+		for _, phrase := range sig.phrases {
+			if !strings.Contains(text, phrase) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return sig.id
+		}
+	}
+	return "unknown"
+}
+
+// readSPDXHeader returns the SPDX identifier near the top of a file, or ""
+// if there is none. ok is false for binary or unreadable files.
+func readSPDXHeader(filePath string) (id string, ok bool) {
+	data, err := readHead(filePath, headerScanLen)
+	if err != nil || looksBinary(data) {
+		return "", false
+	}
+	return findSPDX(string(data)), true
+}
+
+// findSPDX extracts the expression following an SPDX-License-Identifier tag,
+// dropping trailing comment closers.
+func findSPDX(text string) string {
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := scanner.Text()
+		i := strings.Index(line, spdxTag)
+		if i < 0 {
+			continue
+		}
+		expr := strings.TrimSpace(line[i+len(spdxTag):])
+		for _, closer := range []string{"*/", "-->", "#}", "--%>"} {
+			expr = strings.TrimSpace(strings.TrimSuffix(expr, closer))
+		}
+		return expr
+	}
+	return ""
+}
+
+// readHead reads up to n bytes from the start of a file.
+func readHead(filePath string, n int) ([]byte, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, n)
+	read, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	return buf[:read], nil
+}
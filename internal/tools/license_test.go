@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestFindSPDX(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"// SPDX-License-Identifier: MIT\npackage a", "MIT"},
+		{"/* SPDX-License-Identifier: Apache-2.0 OR MIT */", "Apache-2.0 OR MIT"},
+		{"<!-- SPDX-License-Identifier: CC-BY-4.0 -->", "CC-BY-4.0"},
+		{"# Copyright 2024\n# SPDX-License-Identifier: GPL-2.0-only WITH Linux-syscall-note\n", "GPL-2.0-only WITH Linux-syscall-note"},
+		{"package a", ""},
+	}
+	for _, tt := range tests {
+		if got := findSPDX(tt.text); got != tt.want {
+			t.Errorf("findSPDX(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestIsLicenseFile(t *testing.T) {
+	for name, want := range map[string]bool{
+		"LICENSE": true, "License.md": true, "COPYING.txt": true, "NOTICE": true, "COPYING.LESSER": true,
+		"LICENSE.go": false, "licenses.md": false, "README.md": false,
+	} {
+		if got := isLicenseFile(name); got != want {
+			t.Errorf("isLicenseFile(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestScanLicenses(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	writePackTree(t, tmpDir, map[string]string{
+		"LICENSE":                "MIT License\n\nPermission is hereby granted, free of charge, to any person\nobtaining a copy",
+		"vendor/dep/LICENSE.txt": "Apache License\n  Version 2.0, January 2004\n",
+		"vendor/odd/COPYING":     "All rights reserved.\n",
+		"main.go":                "// SPDX-License-Identifier: MIT\n\npackage main\n",
+		"util.go":                "package main\n",
+		"script.py":              "#!/usr/bin/env python3\n# SPDX-License-Identifier: Apache-2.0\n",
+		"README.md":              "no header needed\n",
+		"build/gen.go":           "package gen\n",
+		".gitignore":             "build/\n",
+		"vendor/dep/dep.go":      "// SPDX-License-Identifier: Apache-2.0\npackage dep\n",
+		"vendor/odd/odd.go":      "package odd\n",
+	})
+
+	result := callTool(t, HandleScanLicenses, reg, map[string]any{
+		"path":            tmpDir,
+		"requiredLicense": "MIT",
+		"format":          "json",
+	})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+
+	var report licenseReport
+	if err := json.Unmarshal([]byte(resultText(result)), &report); err != nil {
+		t.Fatal(err)
+	}
+
+	licenses := map[string]string{}
+	for _, f := range report.LicenseFiles {
+		licenses[f.Path] = f.License
+	}
+	if licenses["LICENSE"] != "MIT" || licenses["vendor/dep/LICENSE.txt"] != "Apache-2.0" || licenses["vendor/odd/COPYING"] != "unknown" {
+		t.Errorf("unexpected license files: %v", licenses)
+	}
+	if report.Checked != 5 || report.Headers["MIT"] != 1 || report.Headers["Apache-2.0"] != 2 {
+		t.Errorf("unexpected header counts: checked %d, %v", report.Checked, report.Headers)
+	}
+	if strings.Join(report.Missing, ",") != "util.go,vendor/odd/odd.go" {
+		t.Errorf("unexpected missing: %v", report.Missing)
+	}
+	if len(report.Mismatched) != 2 || report.Mismatched[0].Path != "script.py" {
+		t.Errorf("unexpected mismatched: %+v", report.Mismatched)
+	}
+
+	// Custom patterns and excludes narrow the header check
+	result = callTool(t, HandleScanLicenses, reg, map[string]any{
+		"path":            tmpDir,
+		"patterns":        []interface{}{"**.go"},
+		"excludePatterns": []interface{}{"vendor"},
+	})
+	text := resultText(result)
+	if !strings.Contains(text, "Missing headers (1):\n  util.go") || strings.Contains(text, "vendor") {
+		t.Errorf("unexpected report:\n%s", text)
+	}
+}
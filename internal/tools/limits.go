@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+)
+
+// withForce declares the force argument that overrides configured limits.
+func withForce() mcp.ToolOption {
+	return mcp.WithBoolean("force", mcp.Description("If true, proceed even when the operation exceeds the server's configured deletion limits"))
+}
+
+// measureTree counts the files under resolvedPath and their total size, as
+// seen through the overlay if one is active.
+func measureTree(reg *registry.Registry, resolvedPath string) (int, int64, error) {
+	entries, err := readDir(reg, resolvedPath)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var files int
+	var bytes int64
+	for _, entry := range entries {
+		if entry.IsDir() {
+			f, b, err := measureTree(reg, filepath.Join(resolvedPath, entry.Name()))
+			if err != nil {
+				return 0, 0, err
+			}
+			files += f
+			bytes += b
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return 0, 0, err
+		}
+		files++
+		bytes += info.Size()
+	}
+	return files, bytes, nil
+}
+
+// checkDeleteLimits rejects a deletion of files totalling bytes when it
+// exceeds the configured limits, unless force is set.
+func checkDeleteLimits(reg *registry.Registry, files int, bytes int64, force bool) error {
+	if force {
+		return nil
+	}
+	limits := reg.Limits()
+	if limits.MaxDeleteFiles > 0 && files > limits.MaxDeleteFiles {
+		return fmt.Errorf("refusing to delete %d files: exceeds the limit of %d files per call, set force=true to override", files, limits.MaxDeleteFiles)
+	}
+	if limits.MaxDeleteBytes > 0 && bytes > limits.MaxDeleteBytes {
+		return fmt.Errorf("refusing to delete %s: exceeds the limit of %s per call, set force=true to override", stream.FormatSize(bytes), stream.FormatSize(limits.MaxDeleteBytes))
+	}
+	return nil
+}
+
+// checkTreeDeleteLimits applies checkDeleteLimits to everything under
+// resolvedPath, skipping the walk when no limit applies.
+func checkTreeDeleteLimits(reg *registry.Registry, resolvedPath string, force bool) error {
+	limits := reg.Limits()
+	if force || (limits.MaxDeleteFiles <= 0 && limits.MaxDeleteBytes <= 0) {
+		return nil
+	}
+	files, bytes, err := measureTree(reg, resolvedPath)
+	if err != nil {
+		return fmt.Errorf("failed to measure directory: %w", err)
+	}
+	return checkDeleteLimits(reg, files, bytes, force)
+}
@@ -0,0 +1,103 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+)
+
+func TestDeleteDirectoryLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		limits  registry.Limits
+		force   bool
+		wantErr string
+	}{
+		{"no limits", registry.Limits{}, false, ""},
+		{"under file limit", registry.Limits{MaxDeleteFiles: 3}, false, ""},
+		{"over file limit", registry.Limits{MaxDeleteFiles: 2}, false, "refusing to delete 3 files"},
+		{"over byte limit", registry.Limits{MaxDeleteBytes: 20}, false, "refusing to delete 30 B"},
+		{"forced", registry.Limits{MaxDeleteFiles: 1, MaxDeleteBytes: 1}, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg, tmpDir := setupTestRegistry(t)
+			reg.SetLimits(tt.limits)
+
+			dir := filepath.Join(tmpDir, "dir")
+			if err := os.MkdirAll(filepath.Join(dir, "sub"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			for i, p := range []string{"a.txt", "b.txt", filepath.Join("sub", "c.txt")} {
+				if err := os.WriteFile(filepath.Join(dir, p), []byte(strings.Repeat(fmt.Sprint(i), 10)), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			result := callTool(t, HandleDeleteDirectory, reg, map[string]any{"path": dir, "recursive": true, "force": tt.force})
+			if tt.wantErr == "" {
+				if result.IsError {
+					t.Fatalf("unexpected error: %s", resultText(result))
+				}
+				return
+			}
+			if !result.IsError || !strings.Contains(resultText(result), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %s", tt.wantErr, resultText(result))
+			}
+			if _, err := os.Stat(dir); err != nil {
+				t.Errorf("directory should survive a refused delete: %v", err)
+			}
+		})
+	}
+}
+
+func TestDeleteFileByteLimit(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	reg.SetLimits(registry.Limits{MaxDeleteBytes: 4})
+
+	file := filepath.Join(tmpDir, "big.txt")
+	if err := os.WriteFile(file, []byte("too large"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := callTool(t, HandleDeleteFile, reg, map[string]any{"path": file})
+	if !result.IsError {
+		t.Fatal("expected delete over the byte limit to be refused")
+	}
+
+	result = callTool(t, HandleDeleteFile, reg, map[string]any{"path": file, "force": true})
+	if result.IsError {
+		t.Fatalf("forced delete failed: %s", resultText(result))
+	}
+}
+
+func TestApplyPatchDeleteLimit(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	reg.SetLimits(registry.Limits{MaxDeleteFiles: 1})
+
+	var diff strings.Builder
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte("x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(&diff, "--- %s\n+++ /dev/null\n@@ -1 +0,0 @@\n-x\n", name)
+	}
+
+	result := callTool(t, HandleApplyPatch, reg, map[string]any{"path": tmpDir, "patch": diff.String()})
+	if !result.IsError || !strings.Contains(resultText(result), "refusing to delete 2 files") {
+		t.Fatalf("expected patch over the file limit to be refused, got %s", resultText(result))
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "a.txt")); err != nil {
+		t.Errorf("file should survive a refused patch: %v", err)
+	}
+
+	result = callTool(t, HandleApplyPatch, reg, map[string]any{"path": tmpDir, "patch": diff.String(), "force": true})
+	if result.IsError {
+		t.Fatalf("forced patch failed: %s", resultText(result))
+	}
+}
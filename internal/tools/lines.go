@@ -0,0 +1,240 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/spf13/cast"
+)
+
+// lineCounts are the totals count_lines reports for a group of files.
+type lineCounts struct {
+	Files int   `json:"files"`
+	Lines int64 `json:"lines"`
+	Blank int64 `json:"blank"`
+	Words int64 `json:"words"`
+	Bytes int64 `json:"bytes"`
+}
+
+func (c *lineCounts) add(o lineCounts) {
+	c.Files += o.Files
+	c.Lines += o.Lines
+	c.Blank += o.Blank
+	c.Words += o.Words
+	c.Bytes += o.Bytes
+}
+
+// lineGroup is the totals for one extension or top-level directory.
+type lineGroup struct {
+	Name string `json:"name"`
+	lineCounts
+}
+
+// lineReport is the result of count_lines.
+type lineReport struct {
+	Root        string      `json:"root"`
+	Total       lineCounts  `json:"total"`
+	Extensions  []lineGroup `json:"extensions"`
+	Directories []lineGroup `json:"directories"`
+	// SkippedBinary counts files left out because they look binary.
+	SkippedBinary int `json:"skippedBinary"`
+}
+
+// NewCountLinesTool creates the count_lines tool.
+func NewCountLinesTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"count_lines",
+		mcp.WithDescription("Count the lines, blank lines, words, and bytes of the text files under a directory, totaled per file extension and per top-level directory, to answer how big a codebase is. Files are streamed; .gitignore is honored and binary files, symlinks, and .git directories are skipped."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path", mcp.Description("Directory to count"), mcp.Required()),
+		mcp.WithArray("patterns", mcp.Description("Glob patterns selecting files, relative to path (default: all files)"), mcp.Items(map[string]any{"type": "string"})),
+		mcp.WithArray("excludePatterns", mcp.Description("Glob patterns to exclude"), mcp.Items(map[string]any{"type": "string"})),
+		mcp.WithBoolean("respectGitignore", mcp.Description("If false, include files ignored by .gitignore (default: true)")),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleCountLines handles the count_lines tool.
+func HandleCountLines(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	filter, err := parseTreeFilter(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	resolvedPath, errResult := resolveDirectory(reg, path)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	report, err := countLines(ctx, reg, resolvedPath, filter)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to count lines: %w", err).Error()), nil
+	}
+
+	if format == "json" {
+		jsonResult, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(jsonResult)), nil
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "%s: %d lines (%d blank), %d words, %s in %d files\n",
+		report.Root, report.Total.Lines, report.Total.Blank, report.Total.Words, stream.FormatSize(report.Total.Bytes), report.Total.Files)
+	if report.SkippedBinary > 0 {
+		fmt.Fprintf(&text, "Skipped %d binary files\n", report.SkippedBinary)
+	}
+	writeLineGroups(&text, "By extension", report.Extensions)
+	writeLineGroups(&text, "By directory", report.Directories)
+	return mcp.NewToolResultText(text.String()), nil
+}
+
+// writeLineGroups renders groups as an aligned table under a heading.
+func writeLineGroups(b *strings.Builder, heading string, groups []lineGroup) {
+	if len(groups) == 0 {
+		return
+	}
+	width := len("Name")
+	for _, g := range groups {
+		width = max(width, len(g.Name))
+	}
+	fmt.Fprintf(b, "\n%s:\n", heading)
+	fmt.Fprintf(b, "  %-*s  %7s  %10s  %10s  %10s\n", width, "Name", "Files", "Lines", "Blank", "Words")
+	for _, g := range groups {
+		fmt.Fprintf(b, "  %-*s  %7d  %10d  %10d  %10d\n", width, g.Name, g.Files, g.Lines, g.Blank, g.Words)
+	}
+}
+
+// countLines walks root and totals the text files that pass filter.
+func countLines(ctx context.Context, reg *registry.Registry, root string, filter treeFilter) (lineReport, error) {
+	report := lineReport{Root: root}
+	byExt := make(map[string]*lineCounts)
+	byDir := make(map[string]*lineCounts)
+
+	err := walkTree(ctx, reg, root, filter, func(walkPath, relPath string, entry fs.DirEntry) error {
+		source, err := readTarget(reg, walkPath)
+		if err != nil {
+			return nil
+		}
+		counts, binary, err := countFileLines(source)
+		if err != nil {
+			return nil
+		}
+		if binary {
+			report.SkippedBinary++
+			return nil
+		}
+
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if ext == "" || ext == entry.Name() {
+			ext = "(none)"
+		}
+		dir := "."
+		if i := strings.Index(relPath, "/"); i >= 0 {
+			dir = relPath[:i] + "/"
+		}
+		for key, groups := range map[string]map[string]*lineCounts{ext: byExt, dir: byDir} {
+			if groups[key] == nil {
+				groups[key] = &lineCounts{}
+			}
+			groups[key].add(counts)
+		}
+		report.Total.add(counts)
+		return nil
+	})
+	if err != nil {
+		return lineReport{}, err
+	}
+
+	report.Extensions = sortLineGroups(byExt)
+	report.Directories = sortLineGroups(byDir)
+	return report, nil
+}
+
+// sortLineGroups orders groups by line count, largest first.
+func sortLineGroups(groups map[string]*lineCounts) []lineGroup {
+	sorted := make([]lineGroup, 0, len(groups))
+	for name, c := range groups {
+		sorted = append(sorted, lineGroup{Name: name, lineCounts: *c})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Lines != sorted[j].Lines {
+			return sorted[i].Lines > sorted[j].Lines
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+// countFileLines streams a file and counts its lines, blank lines, words,
+// and bytes. A final line without a newline still counts. It reports binary
+// instead if the file looks binary.
+func countFileLines(path string) (lineCounts, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return lineCounts{}, false, err
+	}
+	defer f.Close()
+
+	c := lineCounts{Files: 1}
+	buf := make([]byte, stream.DefaultChunkSize)
+	first := true
+	var lineLen int64
+	var inWord, nonBlank bool
+	for {
+		n, err := f.Read(buf)
+		chunk := buf[:n]
+		if first && n > 0 {
+			if looksBinary(chunk) {
+				return lineCounts{}, true, nil
+			}
+			first = false
+		}
+		c.Bytes += int64(n)
+		for _, b := range chunk {
+			switch b {
+			case '\n':
+				c.Lines++
+				if !nonBlank {
+					c.Blank++
+				}
+				lineLen, inWord, nonBlank = 0, false, false
+				continue
+			case ' ', '\t', '\r', '\v', '\f':
+				inWord = false
+			default:
+				if !inWord {
+					c.Words++
+				}
+				inWord, nonBlank = true, true
+			}
+			lineLen++
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return lineCounts{}, false, err
+		}
+	}
+	if lineLen > 0 {
+		c.Lines++
+		if !nonBlank {
+			c.Blank++
+		}
+	}
+	return c, false, nil
+}
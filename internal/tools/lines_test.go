@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCountFileLines(t *testing.T) {
+	tmpDir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+		want    lineCounts
+	}{
+		{"empty", "", lineCounts{Files: 1}},
+		{"trailing newline", "a b\n\nc\n", lineCounts{Files: 1, Lines: 3, Blank: 1, Words: 3, Bytes: 7}},
+		{"no trailing newline", "one two", lineCounts{Files: 1, Lines: 1, Words: 2, Bytes: 7}},
+		{"whitespace lines are blank", "x\n  \t\r\n", lineCounts{Files: 1, Lines: 2, Blank: 1, Words: 1, Bytes: 7}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tmpDir, tt.name)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, binary, err := countFileLines(path)
+			if err != nil || binary {
+				t.Fatalf("countFileLines() binary=%v err=%v", binary, err)
+			}
+			if got != tt.want {
+				t.Errorf("countFileLines() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountLines(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	writePackTree(t, tmpDir, map[string]string{
+		".gitignore":      "build/\n",
+		"main.go":         "package main\n\nfunc main() {}\n",
+		"Makefile":        "all:\n",
+		"pkg/lib.go":      "package pkg\n",
+		"pkg/README.MD":   "# Pkg\ntext\n",
+		"pkg/data.bin":    "a\x00b\n",
+		"build/out.go":    "package out\n",
+		".git/config":     "[core]\n",
+		"docs/guide/a.md": "one\ntwo\nthree\n",
+	})
+
+	result := callTool(t, HandleCountLines, reg, map[string]any{"path": tmpDir, "format": "json"})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	var report lineReport
+	if err := json.Unmarshal([]byte(resultText(result)), &report); err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Total.Files != 6 || report.Total.Lines != 11 || report.Total.Blank != 1 {
+		t.Errorf("total = %+v, want 6 files, 11 lines, 1 blank", report.Total)
+	}
+	if report.SkippedBinary != 1 {
+		t.Errorf("skippedBinary = %d, want 1", report.SkippedBinary)
+	}
+
+	groups := func(gs []lineGroup) map[string]int64 {
+		m := make(map[string]int64)
+		for _, g := range gs {
+			m[g.Name] = g.Lines
+		}
+		return m
+	}
+	ext := groups(report.Extensions)
+	if ext[".md"] != 5 || ext[".go"] != 4 || ext["(none)"] != 2 || len(ext) != 3 {
+		t.Errorf("extensions = %v", ext)
+	}
+	dir := groups(report.Directories)
+	if dir["."] != 5 || dir["pkg/"] != 3 || dir["docs/"] != 3 || len(dir) != 3 {
+		t.Errorf("directories = %v", dir)
+	}
+	if report.Extensions[0].Name != ".md" {
+		t.Errorf("extensions not ordered by lines: %v", report.Extensions)
+	}
+
+	result = callTool(t, HandleCountLines, reg, map[string]any{"path": tmpDir, "patterns": []any{"**/*.go"}, "respectGitignore": false})
+	text := resultText(result)
+	if !strings.Contains(text, "5 lines") || !strings.Contains(text, "in 3 files") {
+		t.Errorf("unexpected text result:\n%s", text)
+	}
+
+	result = callTool(t, HandleCountLines, reg, map[string]any{"path": filepath.Join(tmpDir, "main.go")})
+	if !result.IsError {
+		t.Error("expected error for a file path")
+	}
+}
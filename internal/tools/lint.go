@@ -0,0 +1,329 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/spf13/cast"
+)
+
+const (
+	// maxLintFileSize is the largest file checked by lint_text.
+	maxLintFileSize = 10 * 1024 * 1024
+	// maxLintLines is the number of line numbers kept per issue.
+	maxLintLines = 5
+)
+
+// utf8BOM is the UTF-8 byte order mark.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// lineIssue counts the lines of a file with one kind of problem.
+type lineIssue struct {
+	Count int   `json:"count"`
+	Lines []int `json:"lines"`
+}
+
+func (li *lineIssue) add(line int) {
+	li.Count++
+	if len(li.Lines) < maxLintLines {
+		li.Lines = append(li.Lines, line)
+	}
+}
+
+// textLint is what lint_text found in one file.
+type textLint struct {
+	Path               string     `json:"path"`
+	BOM                bool       `json:"bom,omitempty"`
+	LF                 int        `json:"lf,omitempty"`
+	CRLF               int        `json:"crlf,omitempty"`
+	CR                 int        `json:"cr,omitempty"`
+	TrailingWhitespace *lineIssue `json:"trailingWhitespace,omitempty"`
+	TabIndented        int        `json:"tabIndented,omitempty"`
+	SpaceIndented      int        `json:"spaceIndented,omitempty"`
+	InvalidUTF8        *lineIssue `json:"invalidUtf8,omitempty"`
+	Fixed              int        `json:"fixedLines,omitempty"`
+}
+
+// mixedLineEndings reports whether more than one line ending style is used.
+func (l *textLint) mixedLineEndings() bool {
+	kinds := 0
+	for _, n := range []int{l.LF, l.CRLF, l.CR} {
+		if n > 0 {
+			kinds++
+		}
+	}
+	return kinds > 1
+}
+
+// mixedIndentation reports whether some lines are indented with tabs and
+// others with spaces.
+func (l *textLint) mixedIndentation() bool {
+	return l.TabIndented > 0 && l.SpaceIndented > 0
+}
+
+func (l *textLint) hasIssues() bool {
+	return l.BOM || l.mixedLineEndings() || l.TrailingWhitespace != nil || l.mixedIndentation() || l.InvalidUTF8 != nil
+}
+
+// fixable reports whether fixing would change the file.
+func (l *textLint) fixable() bool {
+	return l.BOM || l.mixedLineEndings() || l.TrailingWhitespace != nil
+}
+
+// lintReport is the result of lint_text.
+type lintReport struct {
+	Root    string     `json:"root"`
+	Checked int        `json:"checked"`
+	Files   []textLint `json:"files"`
+	Fixed   int        `json:"fixed"`
+}
+
+// NewLintTextTool creates the lint_text tool.
+func NewLintTextTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"lint_text",
+		mcp.WithDescription("Check text files under a directory for mixed line endings, trailing whitespace, mixed tab and space indentation, byte order marks, and invalid UTF-8. With fix, line endings, trailing whitespace, and BOMs are fixed in place atomically; indentation and encoding problems are only reported. Honors .gitignore; binary files are skipped."),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Lint Text",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(true),
+			IdempotentHint:  boolPtr(true),
+		}),
+		mcp.WithString("path", mcp.Description("Directory to check"), mcp.Required()),
+		mcp.WithArray("patterns", mcp.Description("Glob patterns selecting files, relative to path (default: all files)"), mcp.Items(map[string]any{"type": "string"})),
+		mcp.WithArray("excludePatterns", mcp.Description("Glob patterns to exclude"), mcp.Items(map[string]any{"type": "string"})),
+		mcp.WithBoolean("respectGitignore", mcp.Description("If false, also check files ignored by .gitignore (default: true)")),
+		mcp.WithBoolean("fix", mcp.Description("If true, fix line endings, trailing whitespace, and BOMs in place (default: false)")),
+		mcp.WithString("lineEnding", mcp.Description("Line ending used when fixing a file: 'lf' or 'crlf' (default: each file's most common ending)")),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleLintText handles the lint_text tool.
+func HandleLintText(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	fix := cast.ToBool(request.Params.Arguments["fix"])
+	lineEnding := strings.ToLower(cast.ToString(request.Params.Arguments["lineEnding"]))
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	if lineEnding != "" && lineEnding != "lf" && lineEnding != "crlf" {
+		return mcp.NewToolResultError("lineEnding must be 'lf' or 'crlf'"), nil
+	}
+
+	filter, err := parseTreeFilter(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	resolvedPath, errResult := resolveDirectory(reg, path)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	report := lintReport{Root: resolvedPath, Files: []textLint{}}
+	err = walkTree(ctx, reg, resolvedPath, filter, func(walkPath, relPath string, entry fs.DirEntry) error {
+		info, err := entry.Info()
+		if err != nil || info.Size() > maxLintFileSize {
+			return nil
+		}
+		source, err := readTarget(reg, walkPath)
+		if err != nil {
+			return nil
+		}
+		data, err := os.ReadFile(source)
+		if err != nil || looksBinary(data) {
+			return nil
+		}
+		report.Checked++
+
+		lint := lintText(data)
+		lint.Path = relPath
+		if !lint.hasIssues() {
+			return nil
+		}
+
+		if fix && lint.fixable() {
+			fixed, changed := fixText(data, lint, lineEnding)
+			target, allowedDirs, err := writeTarget(reg, walkPath)
+			if err != nil {
+				return fmt.Errorf("failed to fix %s: %w", relPath, err)
+			}
+			if err := atomicWriteFile(target, fixed, info.Mode().Perm(), allowedDirs); err != nil {
+				return fmt.Errorf("failed to fix %s: %w", relPath, err)
+			}
+			lint.Fixed = changed
+			report.Fixed++
+		}
+		report.Files = append(report.Files, lint)
+		return nil
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("lint failed: %w", err).Error()), nil
+	}
+
+	if format == "json" {
+		jsonResult, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(jsonResult)), nil
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "Checked %d files under %s; %d with issues\n", report.Checked, report.Root, len(report.Files))
+	for i, l := range report.Files {
+		if i == maxListedFiles {
+			fmt.Fprintf(&result, "... and %d more\n", len(report.Files)-maxListedFiles)
+			break
+		}
+		fmt.Fprintf(&result, "%s: %s\n", l.Path, strings.Join(describeLint(l), "; "))
+	}
+	if fix {
+		fmt.Fprintf(&result, "\nFixed %d files:\n", report.Fixed)
+		for _, l := range report.Files {
+			if l.Fixed > 0 {
+				fmt.Fprintf(&result, "  %s: %d lines changed\n", l.Path, l.Fixed)
+			}
+		}
+	}
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// lintText inspects the lines of data.
+func lintText(data []byte) textLint {
+	var l textLint
+	if bytes.HasPrefix(data, utf8BOM) {
+		l.BOM = true
+		data = data[len(utf8BOM):]
+	}
+
+	var trailing, invalid lineIssue
+	for lineNo, rest := 1, data; len(rest) > 0; lineNo++ {
+		line, ending, next := splitLine(rest)
+		rest = next
+
+		switch ending {
+		case "\n":
+			l.LF++
+		case "\r\n":
+			l.CRLF++
+		case "\r":
+			l.CR++
+		}
+		if len(line) > 0 && (line[len(line)-1] == ' ' || line[len(line)-1] == '\t') {
+			trailing.add(lineNo)
+		}
+		if len(line) > 0 && len(bytes.TrimLeft(line, " \t")) > 0 {
+			switch line[0] {
+			case '\t':
+				l.TabIndented++
+			case ' ':
+				l.SpaceIndented++
+			}
+		}
+		if !utf8.Valid(line) {
+			invalid.add(lineNo)
+		}
+	}
+	if trailing.Count > 0 {
+		l.TrailingWhitespace = &trailing
+	}
+	if invalid.Count > 0 {
+		l.InvalidUTF8 = &invalid
+	}
+	return l
+}
+
+// fixText removes the BOM and trailing whitespace and normalizes line
+// endings to lineEnding, or to the file's most common ending. It returns
+// the new content and the number of lines changed.
+func fixText(data []byte, l textLint, lineEnding string) ([]byte, int) {
+	eol := "\n"
+	switch {
+	case lineEnding == "crlf":
+		eol = "\r\n"
+	case lineEnding == "" && l.CRLF > l.LF && l.CRLF >= l.CR:
+		eol = "\r\n"
+	case lineEnding == "" && l.CR > l.LF && l.CR > l.CRLF:
+		eol = "\r"
+	}
+
+	var out bytes.Buffer
+	changed := 0
+	if bytes.HasPrefix(data, utf8BOM) {
+		data = data[len(utf8BOM):]
+		changed++
+	}
+	for rest := data; len(rest) > 0; {
+		line, ending, next := splitLine(rest)
+		rest = next
+
+		trimmed := bytes.TrimRight(line, " \t")
+		lineChanged := len(trimmed) != len(line)
+		out.Write(trimmed)
+		if ending != "" {
+			if ending != eol {
+				lineChanged = true
+			}
+			out.WriteString(eol)
+		}
+		if lineChanged {
+			changed++
+		}
+	}
+	return out.Bytes(), changed
+}
+
+// splitLine returns the first line of data, its line ending ("" at the end
+// of data without one), and the remainder.
+func splitLine(data []byte) (line []byte, ending string, rest []byte) {
+	i := bytes.IndexAny(data, "\r\n")
+	if i < 0 {
+		return data, "", nil
+	}
+	if data[i] == '\r' && i+1 < len(data) && data[i+1] == '\n' {
+		return data[:i], "\r\n", data[i+2:]
+	}
+	return data[:i], string(data[i]), data[i+1:]
+}
+
+// describeLint lists the issues in l.
+func describeLint(l textLint) []string {
+	var issues []string
+	if l.BOM {
+		issues = append(issues, "byte order mark")
+	}
+	if l.mixedLineEndings() {
+		issues = append(issues, fmt.Sprintf("mixed line endings (LF %d, CRLF %d, CR %d)", l.LF, l.CRLF, l.CR))
+	}
+	if l.TrailingWhitespace != nil {
+		issues = append(issues, fmt.Sprintf("trailing whitespace on %s", describeLines(l.TrailingWhitespace)))
+	}
+	if l.mixedIndentation() {
+		issues = append(issues, fmt.Sprintf("mixed indentation (tabs %d lines, spaces %d lines)", l.TabIndented, l.SpaceIndented))
+	}
+	if l.InvalidUTF8 != nil {
+		issues = append(issues, fmt.Sprintf("invalid UTF-8 on %s", describeLines(l.InvalidUTF8)))
+	}
+	return issues
+}
+
+func describeLines(li *lineIssue) string {
+	lines := make([]string, len(li.Lines))
+	for i, n := range li.Lines {
+		lines[i] = fmt.Sprint(n)
+	}
+	suffix := ""
+	if li.Count > len(li.Lines) {
+		suffix = ", ..."
+	}
+	return fmt.Sprintf("%d lines (%s%s)", li.Count, strings.Join(lines, ", "), suffix)
+}
@@ -0,0 +1,125 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLintText(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantIssues []string
+		wantFixed  string
+	}{
+		{
+			name:      "clean",
+			content:   "package main\n\nfunc main() {}\n",
+			wantFixed: "package main\n\nfunc main() {}\n",
+		},
+		{
+			name:       "mixed line endings",
+			content:    "a\r\nb\r\nc\n",
+			wantIssues: []string{"mixed line endings (LF 1, CRLF 2, CR 0)"},
+			wantFixed:  "a\r\nb\r\nc\r\n",
+		},
+		{
+			name:       "trailing whitespace",
+			content:    "a \nb\t\nc\n",
+			wantIssues: []string{"trailing whitespace on 2 lines (1, 2)"},
+			wantFixed:  "a\nb\nc\n",
+		},
+		{
+			name:       "bom",
+			content:    "\xEF\xBB\xBFhello\n",
+			wantIssues: []string{"byte order mark"},
+			wantFixed:  "hello\n",
+		},
+		{
+			name:       "mixed indentation",
+			content:    "func f() {\n\tx := 1\n    y := 2\n}\n",
+			wantIssues: []string{"mixed indentation (tabs 1 lines, spaces 1 lines)"},
+			wantFixed:  "func f() {\n\tx := 1\n    y := 2\n}\n",
+		},
+		{
+			name:       "invalid utf-8",
+			content:    "ok\nbad \xff\xfe here\n",
+			wantIssues: []string{"invalid UTF-8 on 1 lines (2)"},
+			wantFixed:  "ok\nbad \xff\xfe here\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lintText([]byte(tt.content))
+			issues := describeLint(l)
+			if strings.Join(issues, "; ") != strings.Join(tt.wantIssues, "; ") {
+				t.Errorf("issues = %q, want %q", issues, tt.wantIssues)
+			}
+			fixed, _ := fixText([]byte(tt.content), l, "")
+			if string(fixed) != tt.wantFixed {
+				t.Errorf("fixed = %q, want %q", fixed, tt.wantFixed)
+			}
+		})
+	}
+}
+
+func TestHandleLintText(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	writePackTree(t, tmpDir, map[string]string{
+		".gitignore":   "ignored.txt\n",
+		"clean.txt":    "fine\n",
+		"dirty.txt":    "one  \r\ntwo\n",
+		"ignored.txt":  "skip me  \n",
+		"image.bin":    "\x00\x01  \n",
+		"docs/note.md": "line  \n",
+	})
+	dirty := filepath.Join(tmpDir, "dirty.txt")
+	if err := os.Chmod(dirty, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	args := map[string]any{"path": tmpDir, "excludePatterns": []interface{}{"**.md"}, "format": "json"}
+	result := callTool(t, HandleLintText, reg, args)
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	var report lintReport
+	if err := json.Unmarshal([]byte(resultText(result)), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Checked != 3 || len(report.Files) != 1 || report.Files[0].Path != "dirty.txt" {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if data, _ := os.ReadFile(dirty); string(data) != "one  \r\ntwo\n" {
+		t.Errorf("file modified without fix: %q", data)
+	}
+
+	args["fix"] = true
+	args["lineEnding"] = "lf"
+	args["format"] = "text"
+	result = callTool(t, HandleLintText, reg, args)
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if !strings.Contains(resultText(result), "dirty.txt: 1 lines changed") {
+		t.Errorf("missing fix summary:\n%s", resultText(result))
+	}
+	if data, _ := os.ReadFile(dirty); string(data) != "one\ntwo\n" {
+		t.Errorf("fixed content = %q", data)
+	}
+	if info, err := os.Stat(dirty); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("permissions not preserved: %v %v", info.Mode(), err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(tmpDir, "docs", "note.md")); string(data) != "line  \n" {
+		t.Errorf("excluded file modified: %q", data)
+	}
+
+	result = callTool(t, HandleLintText, reg, map[string]any{"path": tmpDir, "lineEnding": "cr"})
+	if !result.IsError {
+		t.Error("expected error for invalid lineEnding")
+	}
+}
@@ -0,0 +1,120 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/filelock"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/pkg/filesystem"
+	"github.com/spf13/cast"
+)
+
+// NewLockFileTool creates the lock_file tool.
+func NewLockFileTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"lock_file",
+		mcp.WithDescription("Take an advisory exclusive lock on a file so other agents, and external processes using flock (LockFileEx on Windows), can see it is being edited. The lock is released when the lease runs out unless renewed by calling lock_file again with its token. Locks are advisory: nothing stops a writer that does not check for them."),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Lock File",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  boolPtr(false),
+		}),
+		mcp.WithString("path", mcp.Description("Path to the file to lock"), mcp.Required()),
+		mcp.WithNumber("lease", mcp.Description(fmt.Sprintf("Seconds until the lock is released (default: %d, max: %d)", int(filelock.DefaultLease.Seconds()), int(filelock.MaxLease.Seconds())))),
+		mcp.WithString("owner", mcp.Description("Name to show other agents as the lock's holder")),
+		mcp.WithString("token", mcp.Description("Token of a lock this client holds, to renew its lease")),
+	)
+}
+
+// HandleLockFile handles the lock_file tool.
+func HandleLockFile(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	owner := cast.ToString(request.Params.Arguments["owner"])
+	token := cast.ToString(request.Params.Arguments["token"])
+	lease := filelock.DefaultLease
+	if v, ok := request.Params.Arguments["lease"]; ok {
+		lease = time.Duration(cast.ToFloat64(v) * float64(time.Second))
+	}
+	if lease <= 0 || lease > filelock.MaxLease {
+		return mcp.NewToolResultError(fmt.Sprintf("lease must be between 1 and %d seconds", int(filelock.MaxLease.Seconds()))), nil
+	}
+
+	resolvedPath, err := validateRead(reg, path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	info, err := os.Stat(resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to stat path: %w", err).Error()), nil
+	}
+	if !info.Mode().IsRegular() {
+		return mcp.NewToolResultError("only regular files can be locked"), nil
+	}
+
+	locks := reg.Locks()
+	var l filelock.Lease
+	if token != "" {
+		l, err = locks.Renew(resolvedPath, token, lease)
+	} else {
+		l, err = locks.Lock(resolvedPath, owner, lease)
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to lock %s: %w", resolvedPath, err).Error()), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Locked %s until %s with token %s; release it with unlock_file or renew it with lock_file and the token", resolvedPath, l.Expires.Format(time.RFC3339), l.Token)), nil
+}
+
+// NewUnlockFileTool creates the unlock_file tool.
+func NewUnlockFileTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"unlock_file",
+		mcp.WithDescription("Release a lock taken with lock_file."),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Unlock File",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  boolPtr(false),
+		}),
+		mcp.WithString("path", mcp.Description("Path to the locked file"), mcp.Required()),
+		mcp.WithString("token", mcp.Description("Token lock_file returned"), mcp.Required()),
+	)
+}
+
+// HandleUnlockFile handles the unlock_file tool.
+func HandleUnlockFile(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	token := cast.ToString(request.Params.Arguments["token"])
+
+	resolvedPath, err := validateRead(reg, path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	if _, err := reg.Locks().Unlock(resolvedPath, token); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to unlock %s: %w", resolvedPath, err).Error()), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Unlocked %s", resolvedPath)), nil
+}
+
+// lockInfo returns the lock state of the file at path for get_file_info,
+// or nil if it is not locked or locking is off.
+func lockInfo(reg *registry.Registry, path string) *filesystem.LockInfo {
+	locks := reg.Locks()
+	if locks == nil {
+		return nil
+	}
+	status, err := locks.Status(path)
+	switch {
+	case err != nil:
+		return nil
+	case status.Lease != nil:
+		return &filesystem.LockInfo{Holder: "server", Owner: status.Lease.Owner, Expires: status.Lease.Expires.Format(time.RFC3339)}
+	case status.External:
+		return &filesystem.LockInfo{Holder: "external"}
+	}
+	return nil
+}
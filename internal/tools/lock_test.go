@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/portertech/filesystem-mcp-server/internal/filelock"
+	"github.com/portertech/filesystem-mcp-server/pkg/filesystem"
+)
+
+func TestLockUnlockFile(t *testing.T) {
+	if !filelock.Supported {
+		t.Skip("file locking is not supported on this platform")
+	}
+	reg, tmpDir := setupTestRegistry(t)
+	reg.SetLocks(filelock.NewManager())
+	file := filepath.Join(tmpDir, "config.yaml")
+	os.WriteFile(file, []byte("a: 1\n"), 0644)
+
+	result := callTool(t, HandleLockFile, reg, map[string]any{"path": file, "owner": "agent-a", "lease": 60})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	token := regexp.MustCompile(`token (\S+);`).FindStringSubmatch(resultText(result))
+	if token == nil {
+		t.Fatalf("expected a token in the result: %s", resultText(result))
+	}
+
+	if result := callTool(t, HandleLockFile, reg, map[string]any{"path": file}); !result.IsError {
+		t.Error("expected a second lock to be refused")
+	}
+	if result := callTool(t, HandleLockFile, reg, map[string]any{"path": file, "token": token[1], "lease": 120}); result.IsError {
+		t.Errorf("expected the lease to be renewed: %s", resultText(result))
+	}
+
+	result = callTool(t, HandleGetFileInfo, reg, map[string]any{"path": file})
+	var info filesystem.FileInfo
+	if err := json.Unmarshal([]byte(resultText(result)), &info); err != nil {
+		t.Fatalf("failed to parse file info: %v: %s", err, resultText(result))
+	}
+	if info.Lock == nil || info.Lock.Holder != "server" || info.Lock.Owner != "agent-a" {
+		t.Errorf("expected get_file_info to show the lock, got %+v", info.Lock)
+	}
+
+	if result := callTool(t, HandleUnlockFile, reg, map[string]any{"path": file, "token": "wrong"}); !result.IsError {
+		t.Error("expected the wrong token to be refused")
+	}
+	if result := callTool(t, HandleUnlockFile, reg, map[string]any{"path": file, "token": token[1]}); result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	result = callTool(t, HandleGetFileInfo, reg, map[string]any{"path": file})
+	if strings.Contains(resultText(result), `"lock"`) {
+		t.Errorf("expected no lock after unlocking: %s", resultText(result))
+	}
+}
+
+func TestLockFileValidation(t *testing.T) {
+	if !filelock.Supported {
+		t.Skip("file locking is not supported on this platform")
+	}
+	reg, tmpDir := setupTestRegistry(t)
+	reg.SetLocks(filelock.NewManager())
+	file := filepath.Join(tmpDir, "a.txt")
+	os.WriteFile(file, []byte("a"), 0644)
+
+	if result := callTool(t, HandleLockFile, reg, map[string]any{"path": tmpDir}); !result.IsError {
+		t.Error("expected a directory to be refused")
+	}
+	if result := callTool(t, HandleLockFile, reg, map[string]any{"path": file, "lease": 7200}); !result.IsError {
+		t.Error("expected a lease over the maximum to be refused")
+	}
+	if result := callTool(t, HandleLockFile, reg, map[string]any{"path": filepath.Join(t.TempDir(), "x")}); !result.IsError {
+		t.Error("expected a path outside the allowed directories to be refused")
+	}
+}
@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/spf13/cast"
+)
+
+// logLevels are the levels set_log_level switches between.
+var logLevels = map[string]slog.Level{
+	"debug": slog.LevelDebug,
+	"info":  slog.LevelInfo,
+}
+
+// NewSetLogLevelTool creates the set_log_level tool.
+func NewSetLogLevelTool() mcp.Tool {
+	return mcp.NewTool(
+		"set_log_level",
+		mcp.WithDescription("Switch the server's log level between info and debug without restarting it, to diagnose a problem in a long-lived session. Returns the previous and new level."),
+		mcp.WithString("level", mcp.Description("New log level: 'debug' or 'info'"), mcp.Required()),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Set Log Level",
+			ReadOnlyHint:    boolPtr(false),
+			IdempotentHint:  boolPtr(true),
+			DestructiveHint: boolPtr(false),
+		}),
+	)
+}
+
+// HandleSetLogLevel handles the set_log_level tool, changing level.
+func HandleSetLogLevel(ctx context.Context, level *slog.LevelVar, logger *slog.Logger, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name := strings.ToLower(cast.ToString(request.Params.Arguments["level"]))
+	newLevel, ok := logLevels[name]
+	if !ok {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid level %q: expected debug or info", name)), nil
+	}
+
+	old := level.Level()
+	level.Set(newLevel)
+	logger.Info("log level changed", "from", old.String(), "to", newLevel.String())
+	return mcp.NewToolResultText(fmt.Sprintf("Log level changed from %s to %s", strings.ToLower(old.String()), name)), nil
+}
@@ -0,0 +1,39 @@
+package tools
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleSetLogLevel(t *testing.T) {
+	level := new(slog.LevelVar)
+	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: level}))
+	call := func(name string) *mcp.CallToolResult {
+		var req mcp.CallToolRequest
+		req.Params.Arguments = map[string]any{"level": name}
+		result, err := HandleSetLogLevel(context.Background(), level, logger, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	result := call("DEBUG")
+	if result.IsError || level.Level() != slog.LevelDebug {
+		t.Fatalf("expected the level to be debug, got %v: %s", level.Level(), resultText(result))
+	}
+	if text := resultText(result); !strings.Contains(text, "from info to debug") {
+		t.Errorf("unexpected result %q", text)
+	}
+	if call("info"); level.Level() != slog.LevelInfo {
+		t.Errorf("expected the level to be info, got %v", level.Level())
+	}
+	if result := call("trace"); !result.IsError || level.Level() != slog.LevelInfo {
+		t.Error("expected an unknown level to be rejected")
+	}
+}
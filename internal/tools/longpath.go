@@ -0,0 +1,181 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/pathutil"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/spf13/cast"
+)
+
+const (
+	// defaultLongPathLength leaves some headroom below Windows' MAX_PATH for
+	// checkouts into a deeper directory.
+	defaultLongPathLength = 240
+	defaultLongPaths      = 20
+	maxLongPaths          = 1000
+)
+
+// longPath is a path found by find_long_paths.
+type longPath struct {
+	Path   string `json:"path"`
+	Length int    `json:"length"`
+	Depth  int    `json:"depth"`
+	// LongestComponent is the length in bytes of the longest name in the path.
+	LongestComponent int  `json:"longestComponent"`
+	IsDir            bool `json:"isDir,omitempty"`
+}
+
+// longPathReport is the result of find_long_paths.
+type longPathReport struct {
+	Root      string     `json:"root"`
+	MinLength int        `json:"minLength"`
+	Scanned   int        `json:"scanned"`
+	Total     int        `json:"total"`
+	Longest   int        `json:"longest"`
+	Deepest   int        `json:"deepest"`
+	Paths     []longPath `json:"paths"`
+	// TooLong lists entries the OS refused to read because their path is
+	// too long; their subtrees were not scanned.
+	TooLong []string `json:"tooLong,omitempty"`
+}
+
+// NewFindLongPathsTool creates the find_long_paths tool.
+func NewFindLongPathsTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"find_long_paths",
+		mcp.WithDescription(fmt.Sprintf("Find files and directories under a directory whose absolute paths are long enough to break on Windows (MAX_PATH is %d characters) or in tools with path limits, longest first. Also reports names over %d bytes and entries that could not be read because their path is too long. Includes .gitignored trees such as node_modules unless respectGitignore is set.", pathutil.WindowsMaxPath, pathutil.MaxFilenameLength)),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path", mcp.Description("Directory to scan"), mcp.Required()),
+		mcp.WithNumber("minLength", mcp.Description(fmt.Sprintf("Report paths at least this many characters long (default: %d)", defaultLongPathLength))),
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Number of paths to return (default: %d, max: %d)", defaultLongPaths, maxLongPaths))),
+		mcp.WithArray("excludePatterns", mcp.Description("Glob patterns to exclude"), mcp.Items(map[string]any{"type": "string"})),
+		mcp.WithBoolean("respectGitignore", mcp.Description("If true, skip files ignored by .gitignore (default: false)")),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleFindLongPaths handles the find_long_paths tool.
+func HandleFindLongPaths(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	minLength := cast.ToInt(request.Params.Arguments["minLength"])
+	limit := cast.ToInt(request.Params.Arguments["limit"])
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	if minLength <= 0 {
+		minLength = defaultLongPathLength
+	}
+	if limit <= 0 {
+		limit = defaultLongPaths
+	}
+	if limit > maxLongPaths {
+		limit = maxLongPaths
+	}
+
+	filter, err := parseTreeFilter(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	// Deep dependency trees are usually ignored, and are the likeliest culprits
+	filter.gitignore = cast.ToBool(request.Params.Arguments["respectGitignore"])
+	filter.matchGlobs = nil
+
+	resolvedPath, errResult := resolveDirectory(reg, path)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	report := longPathReport{Root: resolvedPath, MinLength: minLength, Paths: []longPath{}}
+	check := func(walkPath, relPath string, isDir bool) {
+		report.Scanned++
+		entry := longPath{
+			Path:   walkPath,
+			Length: utf8.RuneCountInString(walkPath),
+			Depth:  strings.Count(relPath, "/") + 1,
+			IsDir:  isDir,
+		}
+		for _, name := range strings.Split(relPath, "/") {
+			if len(name) > entry.LongestComponent {
+				entry.LongestComponent = len(name)
+			}
+		}
+		report.Longest = max(report.Longest, entry.Length)
+		report.Deepest = max(report.Deepest, entry.Depth)
+		if entry.Length >= minLength || entry.LongestComponent > pathutil.MaxFilenameLength {
+			report.Paths = append(report.Paths, entry)
+		}
+	}
+	filter.onDir = func(dirPath, relPath string) error {
+		check(dirPath, relPath, true)
+		return nil
+	}
+	filter.onError = func(errPath string, err error) {
+		if pathutil.IsPathTooLong(err) {
+			report.TooLong = append(report.TooLong, errPath)
+		}
+	}
+
+	err = walkTree(ctx, reg, resolvedPath, filter, func(walkPath, relPath string, entry fs.DirEntry) error {
+		check(walkPath, relPath, false)
+		return nil
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("scan failed: %w", err).Error()), nil
+	}
+
+	sort.Slice(report.Paths, func(i, j int) bool {
+		if report.Paths[i].Length != report.Paths[j].Length {
+			return report.Paths[i].Length > report.Paths[j].Length
+		}
+		return report.Paths[i].Path < report.Paths[j].Path
+	})
+	report.Total = len(report.Paths)
+	if len(report.Paths) > limit {
+		report.Paths = report.Paths[:limit]
+	}
+
+	if format == "json" {
+		jsonResult, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(jsonResult)), nil
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "Scanned %d entries under %s; longest path %d characters, deepest %d levels\n", report.Scanned, report.Root, report.Longest, report.Deepest)
+	if report.Total == 0 {
+		fmt.Fprintf(&result, "No paths of %d characters or more\n", minLength)
+	} else {
+		fmt.Fprintf(&result, "%d paths of %d characters or more", report.Total, minLength)
+		if report.Total > len(report.Paths) {
+			fmt.Fprintf(&result, " (showing %d)", len(report.Paths))
+		}
+		result.WriteString(":\n")
+		for _, p := range report.Paths {
+			kind := "[FILE]"
+			if p.IsDir {
+				kind = "[DIR] "
+			}
+			fmt.Fprintf(&result, "  %s %4d  %s", kind, p.Length, p.Path)
+			if p.LongestComponent > pathutil.MaxFilenameLength {
+				fmt.Fprintf(&result, " (name of %d bytes)", p.LongestComponent)
+			}
+			result.WriteString("\n")
+		}
+	}
+	if len(report.TooLong) > 0 {
+		fmt.Fprintf(&result, "\nUnreadable because the path is too long (%d):\n", len(report.TooLong))
+		for _, p := range report.TooLong {
+			fmt.Fprintf(&result, "  %s\n", p)
+		}
+	}
+	return mcp.NewToolResultText(result.String()), nil
+}
@@ -0,0 +1,51 @@
+package tools
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindLongPaths(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	deep := filepath.Join(strings.Repeat("node_modules/pkg/", 6), "index.js")
+	writePackTree(t, tmpDir, map[string]string{
+		".gitignore": "node_modules/\n",
+		"main.go":    "package main\n",
+		deep:         "module.exports = {}\n",
+	})
+	deepPath := filepath.Join(tmpDir, deep)
+	minLength := len(deepPath) - 5
+
+	args := map[string]any{"path": tmpDir, "minLength": minLength, "format": "json"}
+	result := callTool(t, HandleFindLongPaths, reg, args)
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	var report longPathReport
+	if err := json.Unmarshal([]byte(resultText(result)), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Total != 1 || report.Paths[0].Path != deepPath {
+		t.Fatalf("expected only %s, got %+v", deepPath, report.Paths)
+	}
+	if report.Paths[0].Depth != 13 || report.Deepest != 13 || report.Longest != len(deepPath) {
+		t.Errorf("unexpected depth or length: %+v", report)
+	}
+
+	// Ignored trees are skipped only on request
+	args["respectGitignore"] = true
+	result = callTool(t, HandleFindLongPaths, reg, args)
+	if err := json.Unmarshal([]byte(resultText(result)), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Total != 0 {
+		t.Errorf("expected no long paths with respectGitignore, got %+v", report.Paths)
+	}
+
+	result = callTool(t, HandleFindLongPaths, reg, map[string]any{"path": tmpDir})
+	if !strings.Contains(resultText(result), "No paths of 240 characters or more") && !strings.Contains(resultText(result), deepPath) {
+		t.Errorf("unexpected text output:\n%s", resultText(result))
+	}
+}
@@ -0,0 +1,71 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+)
+
+// markdownTable renders a GitHub-flavored Markdown table. Cells must already
+// be escaped, e.g. with markdownCode.
+func markdownTable(header []string, rows [][]string) string {
+	var b strings.Builder
+	b.WriteString("| " + strings.Join(header, " | ") + " |\n")
+	b.WriteString("|")
+	for range header {
+		b.WriteString(" --- |")
+	}
+	b.WriteString("\n")
+	for _, row := range rows {
+		b.WriteString("| " + strings.Join(row, " | ") + " |\n")
+	}
+	return b.String()
+}
+
+// markdownCode renders s as an inline code span that is safe inside a table
+// cell: the fence is longer than any run of backticks in s, pipes are
+// escaped, and line breaks become spaces.
+func markdownCode(s string) string {
+	s = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "|", `\|`).Replace(s)
+	if s == "" {
+		return ""
+	}
+	longest, run := 0, 0
+	for _, r := range s {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", longest+1)
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return fence + s + fence
+}
+
+// markdownMatches renders search results as a table of file, line, and
+// text. Matching line numbers are bold, so they stand out from context
+// lines. Results without a line, from searches without a content pattern,
+// are listed as files only.
+func markdownMatches(matches []searchMatch) string {
+	if len(matches) > 0 && matches[0].Line == 0 {
+		rows := make([][]string, 0, len(matches))
+		for _, m := range matches {
+			rows = append(rows, []string{markdownCode(m.Path)})
+		}
+		return markdownTable([]string{"File"}, rows)
+	}
+	var rows [][]string
+	for _, m := range matches {
+		for _, c := range m.Before {
+			rows = append(rows, []string{markdownCode(m.Path), fmt.Sprint(c.Line), markdownCode(c.Text)})
+		}
+		rows = append(rows, []string{markdownCode(m.Path), fmt.Sprintf("**%d**", m.Line), markdownCode(m.Text)})
+		for _, c := range m.After {
+			rows = append(rows, []string{markdownCode(m.Path), fmt.Sprint(c.Line), markdownCode(c.Text)})
+		}
+	}
+	return markdownTable([]string{"File", "Line", "Text"}, rows)
+}
@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMarkdownCode(t *testing.T) {
+	tests := map[string]string{
+		"main.go":       "`main.go`",
+		"a|b":           "`a\\|b`",
+		"uses `x` here": "``uses `x` here``",
+		"`edge":         "`` `edge ``",
+		"two\nlines":    "`two lines`",
+		"":              "",
+	}
+	for in, want := range tests {
+		if got := markdownCode(in); got != want {
+			t.Errorf("markdownCode(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestMarkdownFormats(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	if err := os.WriteFile(filepath.Join(tmpDir, "big.log"), []byte(strings.Repeat("x", 2048)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "a|b.txt"), []byte("needle here\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		handler func(t *testing.T) string
+		want    []string
+	}{
+		{
+			name: "list_directory_with_sizes",
+			handler: func(t *testing.T) string {
+				return resultText(callTool(t, HandleListDirectoryWithSizes, reg, map[string]any{"path": tmpDir, "format": "markdown"}))
+			},
+			want: []string{"| Name | Type | Size | Modified |", "| `a\\|b.txt` | file | 12 B |", "| `big.log` | file | 2.0 KB |", "2 files, 0 directories"},
+		},
+		{
+			name: "search_files",
+			handler: func(t *testing.T) string {
+				return resultText(callTool(t, HandleSearchFiles, reg, map[string]any{"path": tmpDir, "pattern": "*.log", "format": "markdown"}))
+			},
+			want: []string{"| Path |", "| `" + filepath.Join(tmpDir, "big.log") + "` |"},
+		},
+		{
+			name: "search_content",
+			handler: func(t *testing.T) string {
+				return resultText(callTool(t, HandleSearchContent, reg, map[string]any{"path": tmpDir, "pattern": "needle", "format": "markdown"}))
+			},
+			want: []string{"| File | Line | Text |", "| **1** | `needle here` |"},
+		},
+		{
+			name: "find_largest_files",
+			handler: func(t *testing.T) string {
+				return resultText(callTool(t, HandleFindLargestFiles, reg, map[string]any{"path": tmpDir, "limit": 1, "format": "markdown"}))
+			},
+			want: []string{"| Size | Path | Modified |", "| 2.0 KB | `" + filepath.Join(tmpDir, "big.log") + "` |", "Top 1 of 2 files"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := tt.handler(t)
+			for _, want := range tt.want {
+				if !strings.Contains(text, want) {
+					t.Errorf("expected %q in:\n%s", want, text)
+				}
+			}
+		})
+	}
+}
@@ -1,61 +1,239 @@
 package tools
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"mime"
+	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/audio"
+	"github.com/portertech/filesystem-mcp-server/internal/imaging"
+	"github.com/portertech/filesystem-mcp-server/internal/mimetype"
 	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/reputation"
+	"github.com/portertech/filesystem-mcp-server/internal/scan"
 	"github.com/portertech/filesystem-mcp-server/internal/stream"
 	"github.com/spf13/cast"
 )
 
-// mimeTypes maps file extensions to MIME types for media reads.
-var mimeTypes = map[string]string{
-	".png":  "image/png",
-	".jpg":  "image/jpeg",
-	".jpeg": "image/jpeg",
-	".gif":  "image/gif",
-	".webp": "image/webp",
-	".bmp":  "image/bmp",
-	".svg":  "image/svg+xml",
-	".mp3":  "audio/mpeg",
-	".wav":  "audio/wav",
-	".ogg":  "audio/ogg",
-	".flac": "audio/flac",
+// audioFormats maps audio media types to the ffmpeg output formats that
+// clips are encoded in.
+var audioFormats = map[string]string{
+	"audio/mpeg": "mp3",
+	"audio/wav":  "wav",
+	"audio/ogg":  "ogg",
+	"audio/flac": "flac",
 }
 
+// strippable holds the image types whose metadata stripMetadata removes.
+var strippable = map[string]bool{"image/jpeg": true, "image/png": true, "image/webp": true}
+
+const (
+	// maxMediaSize caps the bytes a single read_media_file call returns.
+	// Larger files are read in chunks with offset and length.
+	maxMediaSize = 10 * 1024 * 1024
+	// defaultMediaChunk is the default length of a chunked read. Chunks
+	// are multiples of three bytes so their encodings concatenate.
+	defaultMediaChunk = 3 * 1024 * 1024
+	// maxFrameSize caps the PNG ffmpeg may produce for a video frame,
+	// before it is resized.
+	maxFrameSize = 64 * 1024 * 1024
+)
+
 // NewReadMediaFileTool creates the read_media_file tool.
 func NewReadMediaFileTool(reg *registry.Registry) mcp.Tool {
 	return mcp.NewTool(
 		"read_media_file",
-		mcp.WithDescription("Read a media file (image or audio) and return it as base64-encoded data."),
+		mcp.WithDescription("Read a media file (image, audio, or video) and return it as base64-encoded data. Files over 10MB are read in chunks with offset and length, across several calls; the encoded chunks concatenate to the encoding of the whole file. JPEG, PNG, and GIF images can be scaled down and re-encoded server-side with maxWidth, maxHeight, and quality, which keeps large screenshots small. Audio can be cut to a clip with startSeconds and durationSeconds and resampled with sampleRate; PCM WAV files are trimmed natively, other formats and resampling need the server's ffmpeg. With the server's ffmpeg, frameAt returns the video frame at a timestamp as a PNG image, which maxWidth, maxHeight, and quality also apply to. When the server quarantines media, the data is served from a recorded copy and its SHA-256 is included. When a content scanner is configured, flagged files are refused or returned with a scan result, depending on the server's settings."),
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithString("path", mcp.Description("Path to the media file to read"), mcp.Required()),
+		mcp.WithNumber("offset", mcp.Description("Read in chunks: byte offset to start at, a multiple of 3. Files over 10MB must be read this way")),
+		mcp.WithNumber("length", mcp.Description("Read in chunks: number of bytes to read, rounded down to a multiple of 3 (default: 3MB, max: 10MB)")),
+		mcp.WithNumber("maxWidth", mcp.Description("Images and frames: scale down to at most this many pixels wide, keeping the aspect ratio")),
+		mcp.WithNumber("maxHeight", mcp.Description("Images and frames: scale down to at most this many pixels high, keeping the aspect ratio")),
+		mcp.WithNumber("quality", mcp.Description("Images and frames: re-encode as JPEG at this quality, 1-100")),
+		mcp.WithNumber("startSeconds", mcp.Description("Audio: start the clip this many seconds into the file")),
+		mcp.WithNumber("durationSeconds", mcp.Description("Audio: length of the clip in seconds (default: to the end)")),
+		mcp.WithNumber("sampleRate", mcp.Description("Audio: resample the clip to this rate in Hz, e.g. 16000 for speech")),
+		mcp.WithNumber("frameAt", mcp.Description("Video: return the frame this many seconds in as an image instead of the video")),
+		mcp.WithBoolean("stripMetadata", mcp.Description("Images: remove EXIF (including GPS location), XMP, IPTC, and comments from JPEG, PNG, and WebP images without re-encoding them. Resized images and frames never carry metadata")),
 	)
 }
 
 // HandleReadMediaFile handles the read_media_file tool.
 func HandleReadMediaFile(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	path := cast.ToString(request.Params.Arguments["path"])
+	_, hasOffset := request.Params.Arguments["offset"]
+	_, hasLength := request.Params.Arguments["length"]
+	chunked := hasOffset || hasLength
+	offset := cast.ToInt64(request.Params.Arguments["offset"])
+	length := int64(defaultMediaChunk)
+	if hasLength {
+		length = cast.ToInt64(request.Params.Arguments["length"])
+		length -= length % 3
+	}
+	if offset < 0 || offset%3 != 0 {
+		return mcp.NewToolResultError("offset must be a non-negative multiple of 3"), nil
+	}
+	if length <= 0 || length > maxMediaSize {
+		return mcp.NewToolResultError(fmt.Sprintf("length must be between 3 and %d bytes", maxMediaSize)), nil
+	}
+	_, hasQuality := request.Params.Arguments["quality"]
+	opts := imaging.Options{
+		MaxWidth:  cast.ToInt(request.Params.Arguments["maxWidth"]),
+		MaxHeight: cast.ToInt(request.Params.Arguments["maxHeight"]),
+		Quality:   cast.ToInt(request.Params.Arguments["quality"]),
+	}
+	resizing := opts.MaxWidth != 0 || opts.MaxHeight != 0 || hasQuality
+	if opts.MaxWidth < 0 || opts.MaxHeight < 0 {
+		return mcp.NewToolResultError("maxWidth and maxHeight must be positive"), nil
+	}
+	if hasQuality && (opts.Quality < 1 || opts.Quality > 100) {
+		return mcp.NewToolResultError("quality must be between 1 and 100"), nil
+	}
+	if resizing && chunked {
+		return mcp.NewToolResultError("maxWidth, maxHeight, and quality cannot be combined with offset and length"), nil
+	}
+	_, hasStart := request.Params.Arguments["startSeconds"]
+	_, hasDuration := request.Params.Arguments["durationSeconds"]
+	clip := audio.Clip{
+		Start:      cast.ToFloat64(request.Params.Arguments["startSeconds"]),
+		Duration:   cast.ToFloat64(request.Params.Arguments["durationSeconds"]),
+		SampleRate: cast.ToInt(request.Params.Arguments["sampleRate"]),
+		MaxBytes:   maxMediaSize,
+	}
+	clipping := hasStart || hasDuration || clip.SampleRate != 0
+	if clip.Start < 0 || clip.Duration < 0 || (hasDuration && clip.Duration == 0) || clip.SampleRate < 0 {
+		return mcp.NewToolResultError("startSeconds must not be negative, and durationSeconds and sampleRate must be positive"), nil
+	}
+	if clipping && chunked {
+		return mcp.NewToolResultError("startSeconds, durationSeconds, and sampleRate cannot be combined with offset and length"), nil
+	}
+	_, extracting := request.Params.Arguments["frameAt"]
+	frameAt := cast.ToFloat64(request.Params.Arguments["frameAt"])
+	if frameAt < 0 {
+		return mcp.NewToolResultError("frameAt must not be negative"), nil
+	}
+	if extracting && chunked {
+		return mcp.NewToolResultError("frameAt cannot be combined with offset and length"), nil
+	}
+	stripping := cast.ToBool(request.Params.Arguments["stripMetadata"])
+	if stripping && chunked {
+		return mcp.NewToolResultError("stripMetadata cannot be combined with offset and length"), nil
+	}
 
-	resolvedPath, err := reg.Validate(path)
+	resolvedPath, err := validateRead(reg, path)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
 	}
+	if info, err := os.Stat(resolvedPath); err == nil && !chunked && !resizing && !clipping && !extracting && info.Size() > maxMediaSize {
+		return mcp.NewToolResultError(fmt.Sprintf("file is %s, larger than the %s a single read returns; read it in chunks with offset and length", stream.FormatSize(info.Size()), stream.FormatSize(maxMediaSize))), nil
+	}
+
+	mimeType, err := mediaType(reg, resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to read media file: %w", err).Error()), nil
+	}
+	if mimeType == "" {
+		return mcp.NewToolResultError("unsupported media type: not a recognized image, audio, or video file"), nil
+	}
+	if extracting && !strings.HasPrefix(mimeType, "video/") {
+		return mcp.NewToolResultError("frameAt only applies to video"), nil
+	}
+	if resizing && !extracting && !strings.HasPrefix(mimeType, "image/") {
+		return mcp.NewToolResultError("maxWidth, maxHeight, and quality only apply to images and video frames"), nil
+	}
+	if stripping && !extracting && !resizing && !strippable[mimeType] {
+		return mcp.NewToolResultError("stripMetadata only applies to JPEG, PNG, and WebP images"), nil
+	}
+	if _, ok := audioFormats[mimeType]; clipping && !ok {
+		return mcp.NewToolResultError("startSeconds, durationSeconds, and sampleRate only apply to MP3, WAV, Ogg, and FLAC audio"), nil
+	}
 
-	// Get MIME type from extension
-	ext := strings.ToLower(filepath.Ext(resolvedPath))
-	mimeType, ok := mimeTypes[ext]
-	if !ok {
-		return mcp.NewToolResultError(fmt.Sprintf("unsupported media type: %s", ext)), nil
+	// With quarantine on, serve the data from a recorded copy
+	readPath := resolvedPath
+	var sha256 string
+	if q := reg.Quarantine(); q != nil {
+		rec, err := q.Ingest("read_media_file", resolvedPath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to quarantine media file: %w", err).Error()), nil
+		}
+		readPath, sha256 = rec.Copy, rec.SHA256
 	}
 
+	// Check and scan what is about to be served, after quarantining so the
+	// checked bytes are exactly the ones returned. Allowlisted files skip
+	// the scanner.
+	match, err := checkReputation(reg, readPath)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	knownGood := match != nil && match.Verdict == reputation.KnownGood
+
+	var verdict *scan.Verdict
+	if scanner, block := reg.Scanner(); scanner != nil && !knownGood {
+		v, err := scanner.Scan(ctx, readPath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("content scan failed: %w", err).Error()), nil
+		}
+		if v.Infected {
+			if block {
+				return mcp.NewToolResultError(fmt.Sprintf("blocked by content scanner: %s", describeDetection(v))), nil
+			}
+			verdict = &v
+		}
+	}
+
+	if extracting {
+		return extractFrame(ctx, reg, resolvedPath, readPath, frameAt, opts, resizing, sha256, verdict, match)
+	}
+	if resizing {
+		f, err := os.Open(readPath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to read media file: %w", err).Error()), nil
+		}
+		defer f.Close()
+		result, errResult := resizeImage(ctx, reg, resolvedPath, f, opts)
+		if errResult != nil {
+			return errResult, nil
+		}
+		return mediaResult(result, sha256, verdict, match)
+	}
+	if clipping {
+		return clipAudio(ctx, reg, resolvedPath, readPath, mimeType, clip, sha256, verdict, match)
+	}
+	if stripping {
+		return stripImage(ctx, reg, resolvedPath, readPath, mimeType, sha256, verdict, match)
+	}
+
+	info, err := os.Stat(readPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to stat media file: %w", err).Error()), nil
+	}
+	size := info.Size()
+	if !chunked {
+		offset, length = 0, size
+	}
+	if offset > size {
+		return mcp.NewToolResultError(fmt.Sprintf("offset %d is past the end of the file (%d bytes)", offset, size)), nil
+	}
+	release, err := reserveMemory(ctx, reg, resolvedPath, int64(base64.StdEncoding.EncodedLen(int(min(length, size-offset)))))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	defer release()
+
 	// Stream to base64
-	base64Data, err := stream.StreamToBase64(resolvedPath)
+	base64Data, n, err := stream.Base64Range(readPath, offset, length)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Errorf("failed to read media file: %w", err).Error()), nil
 	}
@@ -76,6 +254,206 @@ func HandleReadMediaFile(ctx context.Context, reg *registry.Registry, request mc
 		"mimeType": mimeType,
 		"data":     base64Data,
 	}
+	if chunked {
+		result["offset"] = offset
+		result["length"] = n
+		result["size"] = size
+		if offset+n < size {
+			result["nextOffset"] = offset + n
+		}
+	}
+	return mediaResult(result, sha256, verdict, match)
+}
+
+// mediaType returns the media type read_media_file serves the file at path
+// as: the operator's override for its extension, else what its content
+// sniffs as, else what the standard library's table gives its extension,
+// for formats without a reliable signature. It returns "" for anything
+// that is not an image, audio, or video.
+func mediaType(reg *registry.Registry, path string) (string, error) {
+	ext := filepath.Ext(path)
+	if t, ok := reg.MIMEType(ext); ok {
+		return t, nil
+	}
+	t, err := mimetype.DetectFile(path)
+	if err != nil || t != "" {
+		return t, err
+	}
+	t, _, _ = strings.Cut(mime.TypeByExtension(ext), ";")
+	for _, prefix := range []string{"image/", "audio/", "video/"} {
+		if strings.HasPrefix(t, prefix) {
+			return t, nil
+		}
+	}
+	return "", nil
+}
+
+// resizeImage scales down and re-encodes the image read from src, for the
+// file at path. The image is probed before it is decoded, so oversized
+// bitmaps are refused and the memory budget covers the decoded pixels.
+func resizeImage(ctx context.Context, reg *registry.Registry, path string, src io.ReadSeeker, opts imaging.Options) (map[string]interface{}, *mcp.CallToolResult) {
+	cfg, _, err := imaging.Probe(bufio.NewReader(src))
+	if err != nil {
+		return nil, mcp.NewToolResultError(fmt.Errorf("cannot resize image: %w", err).Error())
+	}
+	release, err := reserveMemory(ctx, reg, path, imaging.DecodedSize(cfg))
+	if err != nil {
+		return nil, mcp.NewToolResultError(err.Error())
+	}
+	defer release()
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return nil, mcp.NewToolResultError(fmt.Errorf("failed to read media file: %w", err).Error())
+	}
+
+	img, err := imaging.Resize(bufio.NewReader(src), opts)
+	if err != nil {
+		return nil, mcp.NewToolResultError(fmt.Errorf("cannot resize image: %w", err).Error())
+	}
+	if len(img.Data) > maxMediaSize {
+		return nil, mcp.NewToolResultError(fmt.Sprintf("resized image is %s, larger than the %s a single read returns; pass a smaller maxWidth or maxHeight, or a quality", stream.FormatSize(int64(len(img.Data))), stream.FormatSize(maxMediaSize)))
+	}
+
+	return map[string]interface{}{
+		"type":           "image",
+		"mimeType":       img.MIMEType,
+		"data":           base64.StdEncoding.EncodeToString(img.Data),
+		"width":          img.Width,
+		"height":         img.Height,
+		"originalWidth":  img.OriginalWidth,
+		"originalHeight": img.OriginalHeight,
+	}, nil
+}
+
+// stripImage serves the image at readPath with its metadata removed. The
+// whole-read size cap has already been checked.
+func stripImage(ctx context.Context, reg *registry.Registry, path, readPath, mimeType string, sha256 string, verdict *scan.Verdict, match *reputation.Match) (*mcp.CallToolResult, error) {
+	info, err := os.Stat(readPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to stat media file: %w", err).Error()), nil
+	}
+	// The file, its stripped copy, and the encoding are held at once
+	release, err := reserveMemory(ctx, reg, path, 2*info.Size()+int64(base64.StdEncoding.EncodedLen(int(info.Size()))))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	defer release()
+
+	data, err := os.ReadFile(readPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to read media file: %w", err).Error()), nil
+	}
+	stripped, removed, err := imaging.StripMetadata(data)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to strip metadata: %w", err).Error()), nil
+	}
+
+	result := map[string]interface{}{
+		"type":             "image",
+		"mimeType":         mimeType,
+		"data":             base64.StdEncoding.EncodeToString(stripped),
+		"metadataStripped": removed,
+	}
+	return mediaResult(result, sha256, verdict, match)
+}
+
+// extractFrame serves the frame of the video at readPath at the given time
+// as a PNG, resized with opts when resizing. Frames are extracted by the
+// server's ffmpeg.
+func extractFrame(ctx context.Context, reg *registry.Registry, path, readPath string, at float64, opts imaging.Options, resizing bool, sha256 string, verdict *scan.Verdict, match *reputation.Match) (*mcp.CallToolResult, error) {
+	ff := reg.FFmpeg()
+	if ff == nil {
+		return mcp.NewToolResultError("extracting video frames requires ffmpeg; start the server with -ffmpeg to enable it"), nil
+	}
+	frame, err := ff.Frame(ctx, readPath, at, maxFrameSize)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to extract frame: %w", err).Error()), nil
+	}
+
+	var result map[string]interface{}
+	if resizing {
+		var errResult *mcp.CallToolResult
+		if result, errResult = resizeImage(ctx, reg, path, bytes.NewReader(frame), opts); errResult != nil {
+			return errResult, nil
+		}
+	} else {
+		if len(frame) > maxMediaSize {
+			return mcp.NewToolResultError(fmt.Sprintf("frame is %s, larger than the %s a single read returns; pass maxWidth, maxHeight, or quality to shrink it", stream.FormatSize(int64(len(frame))), stream.FormatSize(maxMediaSize))), nil
+		}
+		release, err := reserveMemory(ctx, reg, path, int64(base64.StdEncoding.EncodedLen(len(frame))))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		defer release()
+		result = map[string]interface{}{
+			"type":     "image",
+			"mimeType": "image/png",
+			"data":     base64.StdEncoding.EncodeToString(frame),
+		}
+	}
+	result["frameAt"] = at
+	return mediaResult(result, sha256, verdict, match)
+}
+
+// clipAudio serves a clip of the audio file at readPath. PCM WAV files are
+// trimmed natively; anything else goes through the server's ffmpeg.
+func clipAudio(ctx context.Context, reg *registry.Registry, path, readPath, mimeType string, clip audio.Clip, sha256 string, verdict *scan.Verdict, match *reputation.Match) (*mcp.CallToolResult, error) {
+	format := audioFormats[mimeType]
+	result := map[string]interface{}{
+		"type":         "audio",
+		"mimeType":     mimeType,
+		"startSeconds": clip.Start,
+	}
+
+	var data []byte
+	err := fmt.Errorf("clipping %s audio %w", format, audio.ErrNeedsFFmpeg)
+	if format == "wav" {
+		var info audio.WAVInfo
+		data, info, err = audio.TrimWAV(readPath, clip)
+		if err == nil {
+			result["durationSeconds"] = info.Duration()
+			result["sampleRate"] = info.SampleRate
+		}
+	}
+	if errors.Is(err, audio.ErrNeedsFFmpeg) {
+		ff := reg.FFmpeg()
+		if ff == nil {
+			return mcp.NewToolResultError(fmt.Sprintf("%v; start the server with -ffmpeg to enable it", err)), nil
+		}
+		data, err = ff.Transcode(ctx, readPath, format, clip)
+		if err == nil && clip.Duration > 0 {
+			result["durationSeconds"] = clip.Duration
+		}
+		if err == nil && clip.SampleRate > 0 {
+			result["sampleRate"] = clip.SampleRate
+		}
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to clip audio: %w", err).Error()), nil
+	}
+
+	// The clip is already in memory; the reservation covers its encoding
+	// until the response is built
+	release, err := reserveMemory(ctx, reg, path, int64(base64.StdEncoding.EncodedLen(len(data))))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	defer release()
+	result["data"] = base64.StdEncoding.EncodeToString(data)
+	return mediaResult(result, sha256, verdict, match)
+}
+
+// mediaResult adds the quarantine, scan, and reputation details common to
+// every read_media_file result and marshals it.
+func mediaResult(result map[string]interface{}, sha256 string, verdict *scan.Verdict, match *reputation.Match) (*mcp.CallToolResult, error) {
+	if sha256 != "" {
+		result["sha256"] = sha256
+	}
+	if verdict != nil {
+		result["scan"] = verdict
+	}
+	if match != nil && match.Verdict == reputation.KnownBad {
+		result["reputation"] = match
+	}
 
 	jsonResult, err := json.Marshal(result)
 	if err != nil {
@@ -84,3 +462,11 @@ func HandleReadMediaFile(ctx context.Context, reg *registry.Registry, request mc
 
 	return mcp.NewToolResultText(string(jsonResult)), nil
 }
+
+// describeDetection formats a scanner verdict for error messages.
+func describeDetection(v scan.Verdict) string {
+	if v.Signature == "" {
+		return "file flagged as malicious"
+	}
+	return v.Signature + " detected"
+}
@@ -1,12 +1,24 @@
 package tools
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"image"
+	"image/jpeg"
+	"image/png"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/ffmpeg"
+	"github.com/portertech/filesystem-mcp-server/internal/quarantine"
+	"github.com/portertech/filesystem-mcp-server/internal/scan"
 )
 
 func TestHandleReadMediaFile(t *testing.T) {
@@ -26,6 +38,14 @@ func TestHandleReadMediaFile(t *testing.T) {
 	if err := os.WriteFile(testFile, pngData, 0644); err != nil {
 		t.Fatal(err)
 	}
+	noExt := filepath.Join(tmpDir, "screenshot")
+	if err := os.WriteFile(noExt, pngData, 0644); err != nil {
+		t.Fatal(err)
+	}
+	textFile := filepath.Join(tmpDir, "notes.xyz")
+	if err := os.WriteFile(textFile, []byte("plain text"), 0644); err != nil {
+		t.Fatal(err)
+	}
 
 	tests := []struct {
 		name    string
@@ -38,8 +58,13 @@ func TestHandleReadMediaFile(t *testing.T) {
 			isError: false,
 		},
 		{
-			name:    "unsupported extension",
-			args:    map[string]any{"path": filepath.Join(tmpDir, "test.xyz")},
+			name:    "png without extension",
+			args:    map[string]any{"path": noExt},
+			isError: false,
+		},
+		{
+			name:    "not media",
+			args:    map[string]any{"path": textFile},
 			isError: true,
 		},
 		{
@@ -71,3 +96,369 @@ func TestHandleReadMediaFile(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleReadMediaFileMIMEType(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	webp := filepath.Join(tmpDir, "photo.jpg")
+	if err := os.WriteFile(webp, []byte("RIFF\x00\x00\x00\x00WEBPVP8 "), 0644); err != nil {
+		t.Fatal(err)
+	}
+	raw := filepath.Join(tmpDir, "frame.zzraw")
+	if err := os.WriteFile(raw, []byte("II*\x00raw data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mimeType := func(path string) string {
+		t.Helper()
+		result := callTool(t, HandleReadMediaFile, reg, map[string]any{"path": path})
+		if result.IsError {
+			return "error: " + resultText(result)
+		}
+		var media struct {
+			MIMEType string `json:"mimeType"`
+		}
+		if err := json.Unmarshal([]byte(resultText(result)), &media); err != nil {
+			t.Fatal(err)
+		}
+		return media.MIMEType
+	}
+
+	// The content wins over a misleading extension
+	if got := mimeType(webp); got != "image/webp" {
+		t.Errorf("mimeType = %q, want image/webp", got)
+	}
+	if got := mimeType(raw); !strings.Contains(got, "unsupported media type") {
+		t.Errorf("expected an unrecognized file to be refused, got %q", got)
+	}
+
+	reg.SetMIMETypes(map[string]string{".ZZRAW": "image/x-canon-cr2"})
+	if got := mimeType(raw); got != "image/x-canon-cr2" {
+		t.Errorf("mimeType = %q, want the override", got)
+	}
+}
+
+func TestHandleReadMediaFileQuarantine(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	q, err := quarantine.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg.SetQuarantine(q)
+
+	testFile := filepath.Join(tmpDir, "sound.wav")
+	if err := os.WriteFile(testFile, []byte("RIFF....WAVE"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := callTool(t, HandleReadMediaFile, reg, map[string]any{"path": testFile})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	var media struct {
+		Data   string `json:"data"`
+		SHA256 string `json:"sha256"`
+	}
+	if err := json.Unmarshal([]byte(resultText(result)), &media); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := q.Records()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Source != testFile || records[0].SHA256 != media.SHA256 || media.SHA256 == "" {
+		t.Errorf("unexpected provenance: %+v, result sha256 %q", records, media.SHA256)
+	}
+	if media.Data != base64.StdEncoding.EncodeToString([]byte("RIFF....WAVE")) {
+		t.Errorf("unexpected data %q", media.Data)
+	}
+}
+
+// flagScanner reports every file as infected.
+type flagScanner struct{}
+
+func (flagScanner) Scan(ctx context.Context, path string) (scan.Verdict, error) {
+	return scan.Verdict{Infected: true, Signature: "Test-Signature"}, nil
+}
+
+func TestHandleReadMediaFileScanner(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	testFile := filepath.Join(tmpDir, "image.png")
+	if err := os.WriteFile(testFile, []byte("\x89PNG"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	reg.SetScanner(flagScanner{}, true)
+	result := callTool(t, HandleReadMediaFile, reg, map[string]any{"path": testFile})
+	if !result.IsError || !strings.Contains(resultText(result), "Test-Signature") {
+		t.Errorf("expected blocked read, got %s", resultText(result))
+	}
+
+	reg.SetScanner(flagScanner{}, false)
+	result = callTool(t, HandleReadMediaFile, reg, map[string]any{"path": testFile})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	var media struct {
+		Data string       `json:"data"`
+		Scan scan.Verdict `json:"scan"`
+	}
+	if err := json.Unmarshal([]byte(resultText(result)), &media); err != nil {
+		t.Fatal(err)
+	}
+	if !media.Scan.Infected || media.Data == "" {
+		t.Errorf("expected data with detection, got %+v", media)
+	}
+}
+
+func TestHandleReadMediaFileChunked(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	data := []byte(strings.Repeat("0123456789", 100))
+	path := filepath.Join(tmpDir, "clip.wav")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Read in chunks until there is no next offset
+	var encoded strings.Builder
+	args := map[string]any{"path": path, "length": 301}
+	for calls := 0; ; calls++ {
+		if calls > 10 {
+			t.Fatal("chunked read did not finish")
+		}
+		result := callTool(t, HandleReadMediaFile, reg, args)
+		if result.IsError {
+			t.Fatalf("chunked read failed: %s", resultText(result))
+		}
+		var chunk map[string]any
+		if err := json.Unmarshal([]byte(resultText(result)), &chunk); err != nil {
+			t.Fatal(err)
+		}
+		if chunk["size"] != float64(len(data)) || chunk["type"] != "audio" {
+			t.Errorf("unexpected chunk metadata: %v", chunk)
+		}
+		encoded.WriteString(chunk["data"].(string))
+		next, ok := chunk["nextOffset"]
+		if !ok {
+			break
+		}
+		args["offset"] = next
+	}
+	if encoded.String() != base64.StdEncoding.EncodeToString(data) {
+		t.Error("concatenated chunks do not match the encoding of the whole file")
+	}
+
+	for name, args := range map[string]map[string]any{
+		"unaligned offset": {"path": path, "offset": 10},
+		"short length":     {"path": path, "length": 2},
+		"huge length":      {"path": path, "length": maxMediaSize + 3},
+		"past the end":     {"path": path, "offset": 3000},
+	} {
+		if result := callTool(t, HandleReadMediaFile, reg, args); !result.IsError {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestHandleReadMediaFileSizeCap(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	path := filepath.Join(tmpDir, "large.png")
+	if err := os.WriteFile(path, make([]byte, maxMediaSize+1), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := callTool(t, HandleReadMediaFile, reg, map[string]any{"path": path})
+	if !result.IsError || !strings.Contains(resultText(result), "read it in chunks") {
+		t.Errorf("expected a whole read to be refused, got %.100q", resultText(result))
+	}
+	result = callTool(t, HandleReadMediaFile, reg, map[string]any{"path": path, "offset": maxMediaSize - 1})
+	if result.IsError {
+		t.Fatalf("chunked read failed: %s", resultText(result))
+	}
+	var chunk map[string]any
+	if err := json.Unmarshal([]byte(resultText(result)), &chunk); err != nil {
+		t.Fatal(err)
+	}
+	if chunk["length"] != float64(2) || chunk["data"] != "AAA=" {
+		t.Errorf("unexpected final chunk: %v", chunk)
+	}
+}
+
+func TestHandleReadMediaFileResize(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 400, 300))); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(tmpDir, "shot.png")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := callTool(t, HandleReadMediaFile, reg, map[string]any{"path": path, "maxWidth": 100, "quality": 70})
+	if result.IsError {
+		t.Fatalf("resize failed: %s", resultText(result))
+	}
+	var got map[string]any
+	if err := json.Unmarshal([]byte(resultText(result)), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["mimeType"] != "image/jpeg" || got["width"] != float64(100) || got["height"] != float64(75) || got["originalWidth"] != float64(400) {
+		t.Errorf("unexpected result: %v", got)
+	}
+	data, err := base64.StdEncoding.DecodeString(got["data"].(string))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width != 100 || cfg.Height != 75 {
+		t.Errorf("returned image is %dx%d (%v)", cfg.Width, cfg.Height, err)
+	}
+
+	for name, args := range map[string]map[string]any{
+		"chunked":     {"path": path, "maxWidth": 100, "offset": 0},
+		"bad quality": {"path": path, "quality": 101},
+		"not decoded": {"path": filepath.Join(tmpDir, "icon.svg"), "maxWidth": 100},
+	} {
+		if name == "not decoded" {
+			if err := os.WriteFile(args["path"].(string), []byte("<svg/>"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if result := callTool(t, HandleReadMediaFile, reg, args); !result.IsError {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestHandleReadMediaFileAudioClip(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+
+	// One second of mono 8-bit PCM at 1000 Hz
+	var wav bytes.Buffer
+	wav.WriteString("RIFF")
+	binary.Write(&wav, binary.LittleEndian, uint32(36+1000))
+	wav.WriteString("WAVEfmt ")
+	binary.Write(&wav, binary.LittleEndian, []uint32{16, 1<<16 | 1, 1000, 1000, 8<<16 | 1})
+	wav.WriteString("data")
+	binary.Write(&wav, binary.LittleEndian, uint32(1000))
+	wav.Write(bytes.Repeat([]byte{0x80}, 1000))
+	path := filepath.Join(tmpDir, "speech.wav")
+	if err := os.WriteFile(path, wav.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := callTool(t, HandleReadMediaFile, reg, map[string]any{"path": path, "startSeconds": 0.25, "durationSeconds": 0.5})
+	if result.IsError {
+		t.Fatalf("clip failed: %s", resultText(result))
+	}
+	var got map[string]any
+	if err := json.Unmarshal([]byte(resultText(result)), &got); err != nil {
+		t.Fatal(err)
+	}
+	data, err := base64.StdEncoding.DecodeString(got["data"].(string))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 44+500 || got["durationSeconds"] != 0.5 || got["sampleRate"] != float64(1000) {
+		t.Errorf("unexpected clip of %d bytes: %v", len(data), got)
+	}
+
+	// Without -ffmpeg, resampling and other formats are refused
+	result = callTool(t, HandleReadMediaFile, reg, map[string]any{"path": path, "sampleRate": 8000})
+	if !result.IsError || !strings.Contains(resultText(result), "-ffmpeg") {
+		t.Errorf("expected resampling to need ffmpeg, got %q", resultText(result))
+	}
+	mp3 := filepath.Join(tmpDir, "speech.mp3")
+	if err := os.WriteFile(mp3, []byte("ID3"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result = callTool(t, HandleReadMediaFile, reg, map[string]any{"path": mp3, "startSeconds": 1})
+	if !result.IsError || !strings.Contains(resultText(result), "clipping mp3 audio requires ffmpeg") {
+		t.Errorf("expected mp3 clipping to need ffmpeg, got %q", resultText(result))
+	}
+}
+
+func TestHandleReadMediaFileFrame(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script in place of ffmpeg")
+	}
+	reg, tmpDir := setupTestRegistry(t)
+	video := filepath.Join(tmpDir, "demo.mp4")
+	if err := os.WriteFile(video, []byte("\x00\x00\x00\x18ftypisom\x00\x00\x02\x00isomiso2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := callTool(t, HandleReadMediaFile, reg, map[string]any{"path": video, "frameAt": 3})
+	if !result.IsError || !strings.Contains(resultText(result), "-ffmpeg") {
+		t.Fatalf("expected frames to need ffmpeg, got %q", resultText(result))
+	}
+
+	// A stand-in for ffmpeg that always produces the same 64x32 frame
+	var frame bytes.Buffer
+	if err := png.Encode(&frame, image.NewGray(image.Rect(0, 0, 64, 32))); err != nil {
+		t.Fatal(err)
+	}
+	framePath := filepath.Join(t.TempDir(), "frame.png")
+	script := filepath.Join(t.TempDir(), "ffmpeg")
+	if err := os.WriteFile(framePath, frame.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ncat "+framePath+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	reg.SetFFmpeg(&ffmpeg.Runner{Path: script})
+
+	var got map[string]any
+	result = callTool(t, HandleReadMediaFile, reg, map[string]any{"path": video, "frameAt": 3})
+	if err := json.Unmarshal([]byte(resultText(result)), &got); err != nil {
+		t.Fatalf("frame failed: %s", resultText(result))
+	}
+	if got["type"] != "image" || got["mimeType"] != "image/png" || got["frameAt"] != float64(3) || got["data"] != base64.StdEncoding.EncodeToString(frame.Bytes()) {
+		t.Errorf("unexpected frame result: %v", got)
+	}
+
+	result = callTool(t, HandleReadMediaFile, reg, map[string]any{"path": video, "frameAt": 3, "maxWidth": 16})
+	if err := json.Unmarshal([]byte(resultText(result)), &got); err != nil {
+		t.Fatalf("resized frame failed: %s", resultText(result))
+	}
+	if got["width"] != float64(16) || got["height"] != float64(8) || got["frameAt"] != float64(3) {
+		t.Errorf("unexpected resized frame result: %v", got)
+	}
+
+	// Video files themselves are readable too
+	result = callTool(t, HandleReadMediaFile, reg, map[string]any{"path": video})
+	if err := json.Unmarshal([]byte(resultText(result)), &got); err != nil || got["mimeType"] != "video/mp4" {
+		t.Errorf("unexpected video result: %s", resultText(result))
+	}
+}
+
+func TestHandleReadMediaFileStripMetadata(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+
+	var plain bytes.Buffer
+	if err := png.Encode(&plain, image.NewGray(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	// An eXIf chunk after IHDR
+	exif := []byte("\x00\x00\x00\x03eXIfGPS\x00\x00\x00\x00")
+	tagged := append(append(append([]byte{}, plain.Bytes()[:33]...), exif...), plain.Bytes()[33:]...)
+	path := filepath.Join(tmpDir, "map.png")
+	if err := os.WriteFile(path, tagged, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := callTool(t, HandleReadMediaFile, reg, map[string]any{"path": path, "stripMetadata": true})
+	var got map[string]any
+	if err := json.Unmarshal([]byte(resultText(result)), &got); err != nil {
+		t.Fatalf("strip failed: %s", resultText(result))
+	}
+	if got["metadataStripped"] != true || got["data"] != base64.StdEncoding.EncodeToString(plain.Bytes()) {
+		t.Errorf("unexpected result: %v", got)
+	}
+
+	result = callTool(t, HandleReadMediaFile, reg, map[string]any{"path": path, "stripMetadata": true, "offset": 0})
+	if !result.IsError {
+		t.Error("expected stripMetadata with offset to fail")
+	}
+}
@@ -0,0 +1,159 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/mimetype"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/spf13/cast"
+)
+
+// mediaTypeAliases maps alternative names for a media type, and the audio
+// and video flavours of containers that hold either, to one name, so that
+// an extension and the content it holds can be compared.
+var mediaTypeAliases = map[string]string{
+	"image/jpg":       "image/jpeg",
+	"image/heif":      "image/heic",
+	"audio/mp3":       "audio/mpeg",
+	"audio/x-wav":     "audio/wav",
+	"audio/wave":      "audio/wav",
+	"audio/x-flac":    "audio/flac",
+	"video/ogg":       "audio/ogg",
+	"application/ogg": "audio/ogg",
+	"audio/mp4":       "video/mp4",
+	"audio/x-m4a":     "video/mp4",
+	"video/3gpp":      "video/mp4",
+	"audio/webm":      "video/webm",
+}
+
+// canonicalMediaType lowercases t, drops its parameters, and resolves
+// aliases.
+func canonicalMediaType(t string) string {
+	t, _, _ = strings.Cut(t, ";")
+	t = strings.ToLower(strings.TrimSpace(t))
+	if alias, ok := mediaTypeAliases[t]; ok {
+		return alias
+	}
+	return t
+}
+
+// NewWriteMediaFileTool creates the write_media_file tool.
+func NewWriteMediaFileTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"write_media_file",
+		mcp.WithDescription("Write base64-encoded binary data, such as an image, audio, or video file, to a file. Creates parent directories if needed. Uses atomic write. The content is checked against the media type of the file extension, so a PNG is not saved as photo.jpg."),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithString("path", mcp.Description("Path to the file to write"), mcp.Required()),
+		mcp.WithString("data", mcp.Description(fmt.Sprintf("Base64-encoded file content, or a base64 data: URL (max %s decoded)", stream.FormatSize(maxMediaSize))), mcp.Required()),
+		mcp.WithString("mimeType", mcp.Description("Media type the data is expected to be, e.g. image/png; checked against the extension and the content")),
+	)
+}
+
+// HandleWriteMediaFile handles the write_media_file tool.
+func HandleWriteMediaFile(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	encoded := cast.ToString(request.Params.Arguments["data"])
+	declared := cast.ToString(request.Params.Arguments["mimeType"])
+
+	if rest, ok := strings.CutPrefix(encoded, "data:"); ok {
+		header, payload, found := strings.Cut(rest, ",")
+		if !found || !strings.HasSuffix(header, ";base64") {
+			return mcp.NewToolResultError("data URLs must be base64-encoded"), nil
+		}
+		if urlType := strings.TrimSuffix(header, ";base64"); declared == "" {
+			declared = urlType
+		} else if canonicalMediaType(urlType) != canonicalMediaType(declared) {
+			return mcp.NewToolResultError(fmt.Sprintf("mimeType %s does not match the data URL's %s", declared, urlType)), nil
+		}
+		encoded = payload
+	}
+	data, err := decodeBase64(encoded)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("invalid base64 data: %w", err).Error()), nil
+	}
+	if len(data) == 0 {
+		return mcp.NewToolResultError("data is empty"), nil
+	}
+	if len(data) > maxMediaSize {
+		return mcp.NewToolResultError(fmt.Sprintf("data is %s, larger than the %s limit", stream.FormatSize(int64(len(data))), stream.FormatSize(maxMediaSize))), nil
+	}
+
+	resolvedPath, err := reg.ValidateForCreation(path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+
+	// The extension, the declared type, and the content must agree where
+	// they are known
+	ext := filepath.Ext(resolvedPath)
+	expected, ok := reg.MIMEType(ext)
+	if !ok {
+		expected = mime.TypeByExtension(ext)
+	}
+	detected := mimetype.Detect(data)
+	if expected != "" && declared != "" && canonicalMediaType(expected) != canonicalMediaType(declared) {
+		return mcp.NewToolResultError(fmt.Sprintf("mimeType %s does not match the %s extension, which is %s", declared, ext, canonicalMediaType(expected))), nil
+	}
+	if detected != "" && declared != "" && canonicalMediaType(detected) != canonicalMediaType(declared) {
+		return mcp.NewToolResultError(fmt.Sprintf("data is %s, not the declared %s", detected, declared)), nil
+	}
+	if detected != "" && expected != "" && canonicalMediaType(detected) != canonicalMediaType(expected) {
+		return mcp.NewToolResultError(fmt.Sprintf("data is %s, which does not match the %s extension (%s)", detected, ext, canonicalMediaType(expected))), nil
+	}
+
+	target, allowedDirs, err := writeTarget(reg, resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to write file: %w", err).Error()), nil
+	}
+
+	// Create parent directories if needed (the overlay creates its own)
+	if reg.Overlay() == nil {
+		dir := filepath.Dir(reg.ExpandPath(path))
+		if err := safeMkdirAll(dir, reg.Modes().Dir, reg.Get()); err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to create directories: %w", err).Error()), nil
+		}
+	}
+
+	// A replaced file keeps its mode
+	perm := reg.Modes().File
+	if info, err := os.Stat(target); err == nil {
+		perm = info.Mode().Perm()
+	}
+	if err := atomicWriteFile(target, data, perm, allowedDirs); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to write file: %w", err).Error()), nil
+	}
+	if store := reg.Shadow(); store != nil {
+		store.Record(target, data)
+	}
+
+	kind := detected
+	if kind == "" {
+		kind = "content type not verified"
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Successfully wrote %s (%s) to %s", stream.FormatSize(int64(len(data))), kind, resolvedPath)), nil
+}
+
+// decodeBase64 decodes standard or URL-safe base64, with or without
+// padding, ignoring line breaks and other whitespace.
+func decodeBase64(s string) ([]byte, error) {
+	s = strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\t' || r == '\r' || r == '\n' {
+			return -1
+		}
+		return r
+	}, s)
+	s = strings.TrimRight(s, "=")
+	if strings.ContainsAny(s, "-_") {
+		return base64.RawURLEncoding.DecodeString(s)
+	}
+	return base64.RawStdEncoding.DecodeString(s)
+}
@@ -0,0 +1,71 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHandleWriteMediaFile(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01")
+	encoded := base64.StdEncoding.EncodeToString(png)
+
+	path := filepath.Join(tmpDir, "images", "pixel.png")
+	result := callTool(t, HandleWriteMediaFile, reg, map[string]any{"path": path, "data": encoded})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if !strings.Contains(resultText(result), "image/png") {
+		t.Errorf("expected the detected type in: %s", resultText(result))
+	}
+	got, err := os.ReadFile(path)
+	if err != nil || !bytes.Equal(got, png) {
+		t.Fatalf("file content = %q, %v", got, err)
+	}
+
+	// Data URLs, unpadded and wrapped base64, and files whose content or
+	// extension is not known are all accepted
+	for name, args := range map[string]map[string]any{
+		"data url":      {"path": filepath.Join(tmpDir, "a.png"), "data": "data:image/png;base64," + encoded},
+		"wrapped":       {"path": filepath.Join(tmpDir, "b.png"), "data": strings.TrimRight(encoded[:8]+"\n"+encoded[8:], "=")},
+		"unknown data":  {"path": filepath.Join(tmpDir, "c.tiff"), "data": base64.StdEncoding.EncodeToString([]byte("II*\x00"))},
+		"no extension":  {"path": filepath.Join(tmpDir, "blob"), "data": encoded},
+		"declared type": {"path": filepath.Join(tmpDir, "d.png"), "data": encoded, "mimeType": "image/png"},
+	} {
+		if result := callTool(t, HandleWriteMediaFile, reg, args); result.IsError {
+			t.Errorf("%s: unexpected error: %s", name, resultText(result))
+		}
+	}
+
+	for name, args := range map[string]map[string]any{
+		"wrong extension":   {"path": filepath.Join(tmpDir, "photo.jpg"), "data": encoded},
+		"text extension":    {"path": filepath.Join(tmpDir, "notes.txt"), "data": encoded},
+		"wrong declared":    {"path": filepath.Join(tmpDir, "e.png"), "data": encoded, "mimeType": "image/gif"},
+		"data url mismatch": {"path": filepath.Join(tmpDir, "f.png"), "data": "data:image/gif;base64," + encoded},
+		"not base64":        {"path": filepath.Join(tmpDir, "g.png"), "data": "not base64!"},
+		"empty":             {"path": filepath.Join(tmpDir, "h.png"), "data": ""},
+		"outside":           {"path": "/tmp/outside.png", "data": encoded},
+	} {
+		if result := callTool(t, HandleWriteMediaFile, reg, args); !result.IsError {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "photo.jpg")); !os.IsNotExist(err) {
+		t.Error("expected a refused write to leave no file")
+	}
+}
+
+func TestHandleWriteMediaFileOverride(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	reg.SetMIMETypes(map[string]string{".raw": "image/png"})
+	encoded := base64.StdEncoding.EncodeToString([]byte("GIF89a\x01\x00\x01\x00"))
+
+	result := callTool(t, HandleWriteMediaFile, reg, map[string]any{"path": filepath.Join(tmpDir, "x.raw"), "data": encoded})
+	if !result.IsError || !strings.Contains(resultText(result), "image/gif") {
+		t.Errorf("expected the override to be checked: %s", resultText(result))
+	}
+}
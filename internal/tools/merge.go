@@ -0,0 +1,191 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/spf13/cast"
+)
+
+// mergeStats counts what merge_lists did with the lines of one input.
+type mergeStats struct {
+	Path       string `json:"path"`
+	Lines      int    `json:"lines"`
+	Kept       int    `json:"kept"`
+	Duplicates int    `json:"duplicates"`
+}
+
+// NewMergeListsTool creates the merge_lists tool.
+func NewMergeListsTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"merge_lists",
+		mcp.WithDescription("Merge line-oriented files, such as .gitignore fragments or requirements files, into one file, keeping the first occurrence of each line in order and dropping later duplicates. Lines are compared without trailing whitespace. The result is written atomically, so a destination that is also an input is replaced only once the merge is complete."),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Merge Lists",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(true),
+			IdempotentHint:  boolPtr(true),
+		}),
+		mcp.WithArray("paths", mcp.Description("Files to merge, in order"), mcp.Required(), mcp.Items(map[string]any{"type": "string"})),
+		mcp.WithString("destination", mcp.Description("Path of the file to write the merged lines to; may be one of the inputs"), mcp.Required()),
+		mcp.WithBoolean("ignoreCase", mcp.Description("Treat lines differing only in case as duplicates (default: false)")),
+		mcp.WithBoolean("keepBlank", mcp.Description("Keep blank lines, which are never duplicates (default: false, blank lines are dropped)")),
+		mcp.WithBoolean("overwrite", mcp.Description("Replace the destination if it exists (default: false)")),
+		mcp.WithBoolean("dryRun", mcp.Description("If true, count the lines without writing")),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleMergeLists handles the merge_lists tool.
+func HandleMergeLists(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	paths := cast.ToStringSlice(request.Params.Arguments["paths"])
+	destination := cast.ToString(request.Params.Arguments["destination"])
+	ignoreCase := cast.ToBool(request.Params.Arguments["ignoreCase"])
+	keepBlank := cast.ToBool(request.Params.Arguments["keepBlank"])
+	overwrite := cast.ToBool(request.Params.Arguments["overwrite"])
+	dryRun := cast.ToBool(request.Params.Arguments["dryRun"])
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	if len(paths) == 0 {
+		return mcp.NewToolResultError("paths must be a non-empty array"), nil
+	}
+	sources := make([]string, len(paths))
+	for i, p := range paths {
+		source, err := validateRead(reg, p)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("path validation failed for %s: %w", p, err).Error()), nil
+		}
+		info, err := os.Stat(source)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to stat %s: %w", p, err).Error()), nil
+		}
+		if info.IsDir() {
+			return mcp.NewToolResultError(fmt.Sprintf("%s is a directory, not a file", p)), nil
+		}
+		if head, err := readHead(source, binarySniffLen); err == nil && looksBinary(head) {
+			return mcp.NewToolResultError(fmt.Sprintf("%s is a binary file", p)), nil
+		}
+		sources[i] = source
+	}
+	eol := "\n"
+	if head, err := readHead(sources[0], binarySniffLen); err == nil {
+		if i := strings.IndexByte(string(head), '\n'); i > 0 && head[i-1] == '\r' {
+			eol = "\r\n"
+		}
+	}
+
+	merge := func(w io.Writer) ([]mergeStats, error) {
+		seen := make(map[string]bool)
+		stats := make([]mergeStats, len(sources))
+		for i, source := range sources {
+			stats[i].Path = source
+			if err := mergeLines(source, w, seen, eol, ignoreCase, keepBlank, &stats[i]); err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", source, err)
+			}
+		}
+		return stats, nil
+	}
+
+	var stats []mergeStats
+	var resolvedDst string
+	var err error
+	if dryRun {
+		if resolvedDst, err = reg.ValidateForCreation(destination); err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("destination path validation failed: %w", err).Error()), nil
+		}
+		if stats, err = merge(io.Discard); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	} else {
+		var target string
+		resolvedDst, target, err = prepareDestination(reg, destination, overwrite)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		perm := reg.Modes().File
+		if info, err := os.Stat(target); err == nil {
+			perm = info.Mode().Perm()
+		}
+		err = stream.WriteFileStreaming(target, perm, func(w io.Writer) error {
+			bw := bufio.NewWriter(w)
+			var err error
+			if stats, err = merge(bw); err != nil {
+				return err
+			}
+			return bw.Flush()
+		})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to merge: %w", err).Error()), nil
+		}
+	}
+
+	kept, duplicates := 0, 0
+	for _, s := range stats {
+		kept += s.Kept
+		duplicates += s.Duplicates
+	}
+	if format == "json" {
+		data, _ := json.MarshalIndent(map[string]any{"destination": resolvedDst, "dryRun": dryRun, "lines": kept, "duplicates": duplicates, "files": stats}, "", "  ")
+		return mcp.NewToolResultText(string(data)), nil
+	}
+
+	var text strings.Builder
+	if dryRun {
+		text.WriteString("Dry run - nothing written\n")
+	}
+	fmt.Fprintf(&text, "Merged %d files into %s: %d lines, %d duplicates removed\n", len(stats), resolvedDst, kept, duplicates)
+	for _, s := range stats {
+		fmt.Fprintf(&text, "  %s: %d of %d lines kept, %d duplicates\n", s.Path, s.Kept, s.Lines, s.Duplicates)
+	}
+	return mcp.NewToolResultText(strings.TrimSuffix(text.String(), "\n")), nil
+}
+
+// mergeLines writes the lines of the file at path to w that are not in
+// seen, adding them to seen, and counts them in stats.
+func mergeLines(path string, w io.Writer, seen map[string]bool, eol string, ignoreCase, keepBlank bool, stats *mergeStats) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			stats.Lines++
+			line = strings.TrimRight(line, " \t\r\n")
+			key := line
+			if ignoreCase {
+				key = strings.ToLower(key)
+			}
+			switch {
+			case line == "" && !keepBlank:
+			case line != "" && seen[key]:
+				stats.Duplicates++
+			default:
+				if line != "" {
+					seen[key] = true
+				}
+				stats.Kept++
+				if _, err := io.WriteString(w, line+eol); err != nil {
+					return err
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMergeLists(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	a := filepath.Join(tmpDir, ".gitignore")
+	b := filepath.Join(tmpDir, "node.gitignore")
+	os.WriteFile(a, []byte("*.log\n\nbuild/\n"), 0600)
+	os.WriteFile(b, []byte("node_modules/\n*.log  \nBuild/\n"), 0644)
+
+	result := callTool(t, HandleMergeLists, reg, map[string]any{"paths": []any{a, b}, "destination": a, "dryRun": true})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if !strings.Contains(resultText(result), "4 lines, 1 duplicates removed") {
+		t.Errorf("unexpected dry run: %s", resultText(result))
+	}
+	if data, _ := os.ReadFile(a); string(data) != "*.log\n\nbuild/\n" {
+		t.Error("expected a dry run to leave the destination alone")
+	}
+
+	result = callTool(t, HandleMergeLists, reg, map[string]any{"paths": []any{a, b}, "destination": a})
+	if !result.IsError {
+		t.Error("expected an existing destination to be kept without overwrite")
+	}
+
+	result = callTool(t, HandleMergeLists, reg, map[string]any{"paths": []any{a, b}, "destination": a, "ignoreCase": true, "overwrite": true})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if data, _ := os.ReadFile(a); string(data) != "*.log\nbuild/\nnode_modules/\n" {
+		t.Errorf("unexpected content: %q", data)
+	}
+	if info, _ := os.Stat(a); info.Mode().Perm() != 0600 {
+		t.Errorf("expected the destination to keep its mode, got %v", info.Mode().Perm())
+	}
+}
+
+func TestMergeListsKeepBlankCRLF(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	a := filepath.Join(tmpDir, "a.txt")
+	b := filepath.Join(tmpDir, "b.txt")
+	os.WriteFile(a, []byte("x\r\n\r\ny\r\n"), 0644)
+	os.WriteFile(b, []byte("y\n\nz"), 0644)
+
+	dst := filepath.Join(tmpDir, "out", "merged.txt")
+	result := callTool(t, HandleMergeLists, reg, map[string]any{"paths": []any{a, b}, "destination": dst, "keepBlank": true})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "x\r\n\r\ny\r\n\r\nz\r\n" {
+		t.Errorf("unexpected content: %q", data)
+	}
+
+	os.WriteFile(b, []byte("bin\x00ary"), 0644)
+	if result := callTool(t, HandleMergeLists, reg, map[string]any{"paths": []any{a, b}, "destination": dst, "overwrite": true}); !result.IsError {
+		t.Error("expected a binary input to be refused")
+	}
+}
@@ -2,12 +2,15 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/overlay"
 	"github.com/portertech/filesystem-mcp-server/internal/registry"
 	"github.com/portertech/filesystem-mcp-server/internal/security"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
 	"github.com/spf13/cast"
 )
 
@@ -18,6 +21,8 @@ func NewMoveFileTool(reg *registry.Registry) mcp.Tool {
 		mcp.WithDescription("Move or rename a file or directory. Fails if destination exists."),
 		mcp.WithString("source", mcp.Description("Path to the source file or directory"), mcp.Required()),
 		mcp.WithString("destination", mcp.Description("Path to the destination"), mcp.Required()),
+		withConfirmationToken(),
+		withApprovalToken(),
 		mcp.WithToolAnnotation(mcp.ToolAnnotation{
 			Title:           "Move File",
 			DestructiveHint: boolPtr(true),
@@ -37,7 +42,11 @@ func HandleMoveFile(ctx context.Context, reg *registry.Registry, request mcp.Cal
 	}
 
 	// Check source exists
-	if _, err := os.Stat(resolvedSrc); err != nil {
+	srcView, err := readTarget(reg, resolvedSrc)
+	if err == nil {
+		_, err = os.Stat(srcView)
+	}
+	if err != nil {
 		return mcp.NewToolResultError(fmt.Errorf("source does not exist: %w", err).Error()), nil
 	}
 
@@ -47,19 +56,62 @@ func HandleMoveFile(ctx context.Context, reg *registry.Registry, request mcp.Cal
 		return mcp.NewToolResultError(fmt.Errorf("destination path validation failed: %w", err).Error()), nil
 	}
 
-	if err := security.ValidateNoSymlinksInPath(destination, reg.Get()); err != nil {
+	if err := security.ValidateNoSymlinksInPath(reg.ExpandPath(destination), reg.Get()); err != nil {
 		return mcp.NewToolResultError(fmt.Errorf("destination path validation failed: %w", err).Error()), nil
 	}
 
 	// Check if destination exists
-	if _, err := os.Lstat(resolvedDst); err == nil {
-		return mcp.NewToolResultError("destination already exists"), nil
+	if dstView, err := readTarget(reg, resolvedDst); err == nil {
+		if _, err := os.Lstat(dstView); err == nil {
+			return mcp.NewToolResultError("destination already exists"), nil
+		}
+	}
+
+	if result := requireApproval(reg, "move_file", request, fmt.Sprintf("move %s to %s", resolvedSrc, resolvedDst), resolvedSrc, resolvedDst); result != nil {
+		return result, nil
+	}
+	for _, p := range []string{resolvedSrc, resolvedDst} {
+		if err := reg.CheckWrite(p); err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to move: %w", err).Error()), nil
+		}
 	}
 
 	// Move the file
-	if err := os.Rename(resolvedSrc, resolvedDst); err != nil {
-		return mcp.NewToolResultError(fmt.Errorf("failed to move: %w", err).Error()), nil
+	var note string
+	if ov := reg.Overlay(); ov != nil {
+		if err := moveInOverlay(ov, srcView, resolvedSrc, resolvedDst); err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to move: %w", err).Error()), nil
+		}
+	} else {
+		op := beginJournal(reg, "move_file", resolvedSrc)
+		op.Move(resolvedSrc, resolvedDst)
+		if err := os.Rename(resolvedSrc, resolvedDst); err != nil {
+			op.Discard()
+			return mcp.NewToolResultError(fmt.Errorf("failed to move: %w", err).Error()), nil
+		}
+		note = journalNote(op)
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Successfully moved %s to %s%s", resolvedSrc, resolvedDst, note)), nil
+}
+
+// moveInOverlay moves a file within the overlay by copying its current content
+// to the destination's shadow path and deleting the source.
+func moveInOverlay(ov *overlay.Overlay, srcView, resolvedSrc, resolvedDst string) error {
+	info, err := os.Stat(srcView)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return errors.New("moving directories is not supported in overlay mode")
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Successfully moved %s to %s", resolvedSrc, resolvedDst)), nil
+	target, err := ov.PrepareWrite(resolvedDst)
+	if err != nil {
+		return err
+	}
+	if err := stream.CopyFileStreaming(srcView, target); err != nil {
+		return err
+	}
+	return ov.Remove(resolvedSrc)
 }
@@ -0,0 +1,433 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/overlay"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/security"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/spf13/cast"
+)
+
+// readTarget returns the path a read of resolvedPath should be served from.
+// In overlay mode this is the shadow copy when one exists.
+func readTarget(reg *registry.Registry, resolvedPath string) (string, error) {
+	ov := reg.Overlay()
+	if ov == nil {
+		return resolvedPath, nil
+	}
+	return ov.ReadPath(resolvedPath)
+}
+
+// validateRead validates path and maps it to where reads should be served from.
+func validateRead(reg *registry.Registry, path string) (string, error) {
+	resolvedPath, err := reg.Validate(path)
+	if err != nil {
+		return "", err
+	}
+	return readTarget(reg, resolvedPath)
+}
+
+// statTarget stats resolvedPath as seen through the overlay, if any.
+func statTarget(reg *registry.Registry, resolvedPath string) (os.FileInfo, error) {
+	target, err := readTarget(reg, resolvedPath)
+	if err != nil {
+		return nil, err
+	}
+	return os.Stat(target)
+}
+
+// writeTarget returns the path a mutation of resolvedPath should be applied to,
+// along with the directories that path must stay within. In overlay mode the
+// write is redirected into the shadow directory. Writes under a read-only
+// directory are refused unless a grant covers them.
+func writeTarget(reg *registry.Registry, resolvedPath string) (string, []string, error) {
+	if err := reg.CheckWrite(resolvedPath); err != nil {
+		return "", nil, err
+	}
+	return checkedWriteTarget(reg, resolvedPath)
+}
+
+// checkedWriteTarget is writeTarget for a path that already passed
+// CheckWrite, which spends an operation of a write grant each time.
+func checkedWriteTarget(reg *registry.Registry, resolvedPath string) (string, []string, error) {
+	ov := reg.Overlay()
+	if ov == nil {
+		return resolvedPath, reg.Get(), nil
+	}
+	target, err := ov.PrepareWrite(resolvedPath)
+	if err != nil {
+		return "", nil, err
+	}
+	return target, []string{ov.FilesDir()}, nil
+}
+
+// readDir lists a directory, merging pending changes in overlay mode.
+func readDir(reg *registry.Registry, resolvedPath string) ([]os.DirEntry, error) {
+	if ov := reg.Overlay(); ov != nil {
+		return ov.ReadDir(resolvedPath)
+	}
+	return os.ReadDir(resolvedPath)
+}
+
+// walkDir is filepath.WalkDir over the tree as the tools see it: in overlay
+// mode, directories are listed with pending changes merged in, and the
+// paths passed to fn are real paths, to be read through readTarget.
+func walkDir(reg *registry.Registry, root string, fn fs.WalkDirFunc) error {
+	ov := reg.Overlay()
+	if ov == nil {
+		return filepath.WalkDir(root, fn)
+	}
+	info, err := statTarget(reg, root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = walkOverlayDir(ov, root, fs.FileInfoToDirEntry(info), fn)
+	}
+	if errors.Is(err, filepath.SkipDir) || errors.Is(err, filepath.SkipAll) {
+		return nil
+	}
+	return err
+}
+
+// walkOverlayDir walks path, whose entry is d, for walkDir.
+func walkOverlayDir(ov *overlay.Overlay, path string, d fs.DirEntry, fn fs.WalkDirFunc) error {
+	if err := fn(path, d, nil); err != nil || !d.IsDir() {
+		if errors.Is(err, filepath.SkipDir) && d.IsDir() {
+			err = nil
+		}
+		return err
+	}
+	entries, err := ov.ReadDir(path)
+	if err != nil {
+		// A second call reports the error, as filepath.WalkDir does
+		if err := fn(path, d, err); err != nil {
+			if errors.Is(err, filepath.SkipDir) {
+				err = nil
+			}
+			return err
+		}
+	}
+	for _, e := range entries {
+		if err := walkOverlayDir(ov, filepath.Join(path, e.Name()), e, fn); err != nil {
+			if errors.Is(err, filepath.SkipDir) {
+				break
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// pendingChanges counts the overlay's changes at or under resolvedPath, or
+// returns 0 outside overlay mode.
+func pendingChanges(reg *registry.Registry, resolvedPath string) int {
+	ov := reg.Overlay()
+	if ov == nil {
+		return 0
+	}
+	changes, err := ov.Changes()
+	if err != nil {
+		return 0
+	}
+	var n int
+	for _, c := range changes {
+		if c.Path == resolvedPath || strings.HasPrefix(c.Path, resolvedPath+string(filepath.Separator)) {
+			n++
+		}
+	}
+	return n
+}
+
+// validateFinal is security.ValidateFinalPath for paths that may only exist in
+// the overlay, which the real-tree check would report as missing.
+func validateFinal(reg *registry.Registry, path string) (string, error) {
+	path = reg.ExpandPath(path)
+	resolvedPath, err := security.ValidateFinalPath(path, reg.Get())
+	if err != nil && os.IsNotExist(err) && reg.Overlay() != nil {
+		return security.ValidateFinalPathForCreation(path, reg.Get())
+	}
+	return resolvedPath, err
+}
+
+// NewOverlayStatusTool creates the overlay_status tool.
+func NewOverlayStatusTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"overlay_status",
+		mcp.WithDescription("List changes pending in the overlay that have not been committed to the real tree."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleOverlayStatus handles the overlay_status tool.
+func HandleOverlayStatus(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	ov := reg.Overlay()
+	if ov == nil {
+		return mcp.NewToolResultError("overlay mode is not enabled"), nil
+	}
+
+	changes, err := ov.Changes()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to list overlay changes: %w", err).Error()), nil
+	}
+
+	if format == "json" {
+		if changes == nil {
+			changes = []overlay.Change{}
+		}
+		jsonResult, err := json.MarshalIndent(changes, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(jsonResult)), nil
+	}
+
+	if len(changes) == 0 {
+		return mcp.NewToolResultText("No pending overlay changes"), nil
+	}
+
+	var result strings.Builder
+	for _, c := range changes {
+		suffix := ""
+		if c.IsDirectory {
+			suffix = string(filepath.Separator)
+		}
+		fmt.Fprintf(&result, "[%s] %s%s\n", strings.ToUpper(c.Kind), c.Path, suffix)
+	}
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// NewOverlayDiffTool creates the overlay_diff tool.
+func NewOverlayDiffTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"overlay_diff",
+		mcp.WithDescription("Show a unified diff of pending overlay changes against the real tree."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithArray("paths", mcp.Description("Limit the diff to these paths (default: all changes)"), mcp.Items(map[string]any{"type": "string"})),
+	)
+}
+
+// HandleOverlayDiff handles the overlay_diff tool.
+func HandleOverlayDiff(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ov := reg.Overlay()
+	if ov == nil {
+		return mcp.NewToolResultError("overlay mode is not enabled"), nil
+	}
+
+	changes, err := selectOverlayChanges(reg, ov, request.Params.Arguments["paths"])
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if len(changes) == 0 {
+		return mcp.NewToolResultText("No pending overlay changes"), nil
+	}
+
+	var result strings.Builder
+	for _, c := range changes {
+		if c.IsDirectory {
+			fmt.Fprintf(&result, "%s directory %s\n\n", strings.ToUpper(c.Kind[:1])+c.Kind[1:], c.Path)
+			continue
+		}
+
+		var oldContent, newContent []byte
+		if c.Kind != overlay.KindAdded {
+			if oldContent, err = os.ReadFile(c.Path); err != nil {
+				return mcp.NewToolResultError(fmt.Errorf("failed to read %s: %w", c.Path, err).Error()), nil
+			}
+		}
+		if c.Kind != overlay.KindDeleted {
+			if newContent, err = os.ReadFile(ov.ShadowPath(c.Path)); err != nil {
+				return mcp.NewToolResultError(fmt.Errorf("failed to read overlay copy of %s: %w", c.Path, err).Error()), nil
+			}
+		}
+		result.WriteString(generateUnifiedDiff(c.Path, string(oldContent), string(newContent)))
+		result.WriteString("\n")
+	}
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// NewOverlayCommitTool creates the overlay_commit tool.
+func NewOverlayCommitTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"overlay_commit",
+		mcp.WithDescription("Apply pending overlay changes to the real tree. Commits everything unless paths are given."),
+		mcp.WithArray("paths", mcp.Description("Limit the commit to these paths (default: all changes)"), mcp.Items(map[string]any{"type": "string"})),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Commit Overlay",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(true),
+			IdempotentHint:  boolPtr(false),
+		}),
+	)
+}
+
+// HandleOverlayCommit handles the overlay_commit tool.
+func HandleOverlayCommit(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ov := reg.Overlay()
+	if ov == nil {
+		return mcp.NewToolResultError("overlay mode is not enabled"), nil
+	}
+
+	changes, err := selectOverlayChanges(reg, ov, request.Params.Arguments["paths"])
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if len(changes) == 0 {
+		return mcp.NewToolResultText("No pending overlay changes"), nil
+	}
+
+	// Apply parents before children, then clear state children-first
+	allowedDirs := reg.Get()
+	var committed []overlay.Change
+	var commitErr error
+	for _, c := range changes {
+		if err := commitOverlayChange(reg, ov, c, allowedDirs); err != nil {
+			commitErr = fmt.Errorf("failed to commit %s (%d of %d changes committed): %w", c.Path, len(committed), len(changes), err)
+			break
+		}
+		committed = append(committed, c)
+	}
+	if err := forgetOverlayChanges(ov, committed); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to clear committed changes: %w", err).Error()), nil
+	}
+	if commitErr != nil {
+		return mcp.NewToolResultError(commitErr.Error()), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Successfully committed %d overlay changes", len(committed))), nil
+}
+
+// NewOverlayDiscardTool creates the overlay_discard tool.
+func NewOverlayDiscardTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"overlay_discard",
+		mcp.WithDescription("Discard pending overlay changes without touching the real tree. Discards everything unless paths are given."),
+		mcp.WithArray("paths", mcp.Description("Limit the discard to these paths (default: all changes)"), mcp.Items(map[string]any{"type": "string"})),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Discard Overlay",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(true),
+			IdempotentHint:  boolPtr(true),
+		}),
+	)
+}
+
+// HandleOverlayDiscard handles the overlay_discard tool.
+func HandleOverlayDiscard(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ov := reg.Overlay()
+	if ov == nil {
+		return mcp.NewToolResultError("overlay mode is not enabled"), nil
+	}
+
+	if _, ok := request.Params.Arguments["paths"]; !ok {
+		if err := ov.Reset(); err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to discard overlay: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText("Discarded all overlay changes"), nil
+	}
+
+	changes, err := selectOverlayChanges(reg, ov, request.Params.Arguments["paths"])
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := forgetOverlayChanges(ov, changes); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to discard changes: %w", err).Error()), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Discarded %d overlay changes", len(changes))), nil
+}
+
+// selectOverlayChanges returns the pending changes at or below the requested
+// paths, or every change when no paths are given.
+func selectOverlayChanges(reg *registry.Registry, ov *overlay.Overlay, pathsArg any) ([]overlay.Change, error) {
+	changes, err := ov.Changes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list overlay changes: %w", err)
+	}
+
+	arr, ok := pathsArg.([]interface{})
+	if !ok || len(arr) == 0 {
+		return changes, nil
+	}
+
+	var prefixes []string
+	for _, v := range arr {
+		resolvedPath, err := reg.ValidateForCreation(cast.ToString(v))
+		if err != nil {
+			return nil, fmt.Errorf("path validation failed: %w", err)
+		}
+		prefixes = append(prefixes, resolvedPath)
+	}
+
+	var selected []overlay.Change
+	for _, c := range changes {
+		if security.IsPathWithinAllowedDirectories(c.Path, prefixes) {
+			selected = append(selected, c)
+		}
+	}
+	return selected, nil
+}
+
+// forgetOverlayChanges clears overlay state for changes sorted by path,
+// deepest first so shadow directories are empty by the time they are dropped.
+func forgetOverlayChanges(ov *overlay.Overlay, changes []overlay.Change) error {
+	for i := len(changes) - 1; i >= 0; i-- {
+		if err := ov.Forget(changes[i].Path); err != nil {
+			return fmt.Errorf("%s: %w", changes[i].Path, err)
+		}
+	}
+	return nil
+}
+
+// commitOverlayChange applies a single overlay change to the real tree.
+func commitOverlayChange(reg *registry.Registry, ov *overlay.Overlay, c overlay.Change, allowedDirs []string) error {
+	if err := reg.CheckWrite(c.Path); err != nil {
+		return err
+	}
+	switch {
+	case c.Kind == overlay.KindDeleted && c.IsDirectory:
+		resolvedPath, err := security.ValidateFinalPath(c.Path, allowedDirs)
+		if err != nil {
+			return err
+		}
+		if err := checkDirectoryRemovable(resolvedPath, allowedDirs); err != nil {
+			return err
+		}
+		if err := rejectSymlinkEntries(resolvedPath); err != nil {
+			return err
+		}
+		return os.RemoveAll(resolvedPath)
+	case c.Kind == overlay.KindDeleted:
+		// Validate the parent so a whited-out symlink removes the link itself
+		resolvedParent, err := security.ValidatePath(filepath.Dir(c.Path), allowedDirs)
+		if err != nil {
+			return err
+		}
+		return os.Remove(filepath.Join(resolvedParent, filepath.Base(c.Path)))
+	case c.IsDirectory:
+		return safeMkdirAll(c.Path, reg.Modes().Dir, allowedDirs)
+	default:
+		resolvedPath, err := security.ValidateFinalPathForCreation(c.Path, allowedDirs)
+		if err != nil {
+			return err
+		}
+		if err := safeMkdirAll(filepath.Dir(resolvedPath), reg.Modes().Dir, allowedDirs); err != nil {
+			return err
+		}
+		return stream.CopyFileStreaming(ov.ShadowPath(c.Path), resolvedPath)
+	}
+}
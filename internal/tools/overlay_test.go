@@ -0,0 +1,242 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/annotation"
+	"github.com/portertech/filesystem-mcp-server/internal/overlay"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+)
+
+func setupOverlayRegistry(t *testing.T) (*registry.Registry, string) {
+	t.Helper()
+	reg, tmpDir := setupTestRegistry(t)
+	ov, err := overlay.New(filepath.Join(t.TempDir(), "overlay"))
+	if err != nil {
+		t.Fatalf("failed to create overlay: %v", err)
+	}
+	reg.SetOverlay(ov)
+	return reg, tmpDir
+}
+
+func TestOverlayWriteAndCommit(t *testing.T) {
+	reg, tmpDir := setupOverlayRegistry(t)
+	existing := filepath.Join(tmpDir, "existing.txt")
+	if err := os.WriteFile(existing, []byte("original\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	newFile := filepath.Join(tmpDir, "sub", "new.txt")
+
+	result := callTool(t, HandleWriteFile, reg, map[string]any{"path": newFile, "content": "hello"})
+	if result.IsError {
+		t.Fatalf("write failed: %s", resultText(result))
+	}
+	result = callTool(t, HandleEditFile, reg, map[string]any{
+		"path":  existing,
+		"edits": []interface{}{map[string]interface{}{"oldText": "original", "newText": "changed"}},
+	})
+	if result.IsError {
+		t.Fatalf("edit failed: %s", resultText(result))
+	}
+
+	if _, err := os.Stat(newFile); !os.IsNotExist(err) {
+		t.Errorf("new file should not exist in the real tree before commit")
+	}
+	data, _ := os.ReadFile(existing)
+	if string(data) != "original\n" {
+		t.Errorf("real file should be untouched before commit, got %q", string(data))
+	}
+
+	result = callTool(t, HandleReadTextFile, reg, map[string]any{"path": existing})
+	if got := resultText(result); got != "changed\n" {
+		t.Errorf("read should see overlay content, got %q", got)
+	}
+
+	result = callTool(t, HandleOverlayStatus, reg, map[string]any{})
+	status := resultText(result)
+	if !strings.Contains(status, "[ADDED] "+newFile) || !strings.Contains(status, "[MODIFIED] "+existing) {
+		t.Errorf("unexpected status output: %s", status)
+	}
+
+	result = callTool(t, HandleOverlayDiff, reg, map[string]any{"paths": []interface{}{existing}})
+	diff := resultText(result)
+	if !strings.Contains(diff, "-original") || !strings.Contains(diff, "+changed") {
+		t.Errorf("unexpected diff output: %s", diff)
+	}
+
+	result = callTool(t, HandleOverlayCommit, reg, map[string]any{})
+	if result.IsError {
+		t.Fatalf("commit failed: %s", resultText(result))
+	}
+
+	data, err := os.ReadFile(newFile)
+	if err != nil || string(data) != "hello" {
+		t.Errorf("expected committed new file, got %q (%v)", string(data), err)
+	}
+	data, _ = os.ReadFile(existing)
+	if string(data) != "changed\n" {
+		t.Errorf("expected committed edit, got %q", string(data))
+	}
+
+	result = callTool(t, HandleOverlayStatus, reg, map[string]any{})
+	if got := resultText(result); got != "No pending overlay changes" {
+		t.Errorf("expected clean overlay after commit, got %q", got)
+	}
+}
+
+func TestOverlayDeleteAndDiscard(t *testing.T) {
+	reg, tmpDir := setupOverlayRegistry(t)
+	file := filepath.Join(tmpDir, "keep.txt")
+	if err := os.WriteFile(file, []byte("keep"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := callTool(t, HandleDeleteFile, reg, map[string]any{"path": file})
+	if result.IsError {
+		t.Fatalf("delete failed: %s", resultText(result))
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Errorf("real file should survive overlay delete: %v", err)
+	}
+
+	result = callTool(t, HandleReadTextFile, reg, map[string]any{"path": file})
+	if !result.IsError {
+		t.Error("expected read of deleted file to fail")
+	}
+
+	result = callTool(t, HandleListDirectory, reg, map[string]any{"path": tmpDir})
+	if strings.Contains(resultText(result), "keep.txt") {
+		t.Errorf("deleted file should not be listed: %s", resultText(result))
+	}
+
+	result = callTool(t, HandleOverlayDiscard, reg, map[string]any{})
+	if result.IsError {
+		t.Fatalf("discard failed: %s", resultText(result))
+	}
+
+	result = callTool(t, HandleReadTextFile, reg, map[string]any{"path": file})
+	if result.IsError || resultText(result) != "keep" {
+		t.Errorf("expected file visible again after discard, got %q", resultText(result))
+	}
+}
+
+func TestOverlayTreeTools(t *testing.T) {
+	reg, tmpDir := setupOverlayRegistry(t)
+	gone := filepath.Join(tmpDir, "gone.txt")
+	if err := os.WriteFile(gone, []byte("twin\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"one.txt", "two.txt"} {
+		result := callTool(t, HandleWriteFile, reg, map[string]any{"path": filepath.Join(tmpDir, "added", name), "content": "twin\n"})
+		if result.IsError {
+			t.Fatalf("write failed: %s", resultText(result))
+		}
+	}
+	result := callTool(t, HandleDeleteFile, reg, map[string]any{"path": gone})
+	if result.IsError {
+		t.Fatalf("delete failed: %s", resultText(result))
+	}
+
+	tests := []struct {
+		name    string
+		handler func(context.Context, *registry.Registry, mcp.CallToolRequest) (*mcp.CallToolResult, error)
+		want    string
+	}{
+		{"directory_tree", HandleDirectoryTree, `"one.txt"`},
+		{"find_largest_files", HandleFindLargestFiles, "one.txt"},
+		{"count_lines", HandleCountLines, "in 2 files"},
+		{"find_duplicates", HandleFindDuplicates, "two.txt"},
+		{"pack_context", HandlePackContext, "## added/one.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := resultText(callTool(t, tt.handler, reg, map[string]any{"path": tmpDir}))
+			if !strings.Contains(text, tt.want) || strings.Contains(text, "gone.txt") {
+				t.Errorf("expected the overlay's files, got: %s", text)
+			}
+		})
+	}
+
+	store, _ := annotation.NewStore("")
+	set := func(ctx context.Context, reg *registry.Registry, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return HandleSetAnnotation(ctx, reg, store, req)
+	}
+	result = callTool(t, set, reg, map[string]any{"path": gone, "note": "reviewed"})
+	if !result.IsError {
+		t.Error("expected annotating a deleted path to fail")
+	}
+}
+
+func TestOverlaySearch(t *testing.T) {
+	reg, tmpDir := setupOverlayRegistry(t)
+	gone := filepath.Join(tmpDir, "gone.txt")
+	if err := os.WriteFile(gone, []byte("needle\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	added := filepath.Join(tmpDir, "sub", "added.txt")
+
+	result := callTool(t, HandleWriteFile, reg, map[string]any{"path": added, "content": "a needle\n"})
+	if result.IsError {
+		t.Fatalf("write failed: %s", resultText(result))
+	}
+	result = callTool(t, HandleDeleteFile, reg, map[string]any{"path": gone})
+	if result.IsError {
+		t.Fatalf("delete failed: %s", resultText(result))
+	}
+
+	result = callTool(t, HandleSearchFiles, reg, map[string]any{"path": tmpDir, "pattern": "*.txt"})
+	text := resultText(result)
+	if !strings.Contains(text, added) || strings.Contains(text, gone) {
+		t.Errorf("search_files should see the overlay: %s", text)
+	}
+
+	result = callTool(t, HandleSearchContent, reg, map[string]any{"path": tmpDir, "pattern": "needle"})
+	text = resultText(result)
+	if !strings.Contains(text, added) || strings.Contains(text, gone) {
+		t.Errorf("search_content should see the overlay: %s", text)
+	}
+	if strings.Contains(text, "overlay") {
+		t.Errorf("search_content should report real paths: %s", text)
+	}
+}
+
+func TestOverlayMoveAndCommitDelete(t *testing.T) {
+	reg, tmpDir := setupOverlayRegistry(t)
+	src := filepath.Join(tmpDir, "src.txt")
+	dst := filepath.Join(tmpDir, "dst.txt")
+	if err := os.WriteFile(src, []byte("content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := callTool(t, HandleMoveFile, reg, map[string]any{"source": src, "destination": dst})
+	if result.IsError {
+		t.Fatalf("move failed: %s", resultText(result))
+	}
+
+	result = callTool(t, HandleOverlayCommit, reg, map[string]any{})
+	if result.IsError {
+		t.Fatalf("commit failed: %s", resultText(result))
+	}
+
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Errorf("source should be removed after commit, got %v", err)
+	}
+	data, err := os.ReadFile(dst)
+	if err != nil || string(data) != "content" {
+		t.Errorf("expected moved content at destination, got %q (%v)", string(data), err)
+	}
+}
+
+func TestOverlayToolsDisabled(t *testing.T) {
+	reg, _ := setupTestRegistry(t)
+
+	result := callTool(t, HandleOverlayStatus, reg, map[string]any{})
+	if !result.IsError {
+		t.Error("expected error when overlay mode is not enabled")
+	}
+}
@@ -0,0 +1,129 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/spf13/cast"
+)
+
+// NewChangeOwnerTool creates the change_owner tool.
+func NewChangeOwnerTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"change_owner",
+		mcp.WithDescription("Set the owner and/or group of a file or directory, by name or numeric id. With recursive, also changes everything beneath a directory; symlinks found while walking are changed themselves, never their targets. Requires the server to run with enough privileges."),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Change Owner",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  boolPtr(true),
+		}),
+		mcp.WithString("path", mcp.Description("Path to the file or directory"), mcp.Required()),
+		mcp.WithString("owner", mcp.Description("User name or numeric uid (default: unchanged)")),
+		mcp.WithString("group", mcp.Description("Group name or numeric gid (default: unchanged)")),
+		mcp.WithBoolean("recursive", mcp.Description("Also change everything beneath a directory (default: false)")),
+	)
+}
+
+// HandleChangeOwner handles the change_owner tool.
+func HandleChangeOwner(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	owner := cast.ToString(request.Params.Arguments["owner"])
+	group := cast.ToString(request.Params.Arguments["group"])
+	recursive := cast.ToBool(request.Params.Arguments["recursive"])
+
+	if owner == "" && group == "" {
+		return mcp.NewToolResultError("at least one of owner or group is required"), nil
+	}
+	if reg.Overlay() != nil {
+		return mcp.NewToolResultError("change_owner is not supported in overlay mode"), nil
+	}
+
+	// -1 leaves the id unchanged
+	uid, gid := -1, -1
+	var err error
+	if owner != "" {
+		if uid, err = lookupUID(owner); err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("unknown owner %q: %w", owner, err).Error()), nil
+		}
+	}
+	if group != "" {
+		if gid, err = lookupGID(group); err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("unknown group %q: %w", group, err).Error()), nil
+		}
+	}
+
+	resolvedPath, err := reg.Validate(path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	if err := reg.CheckWrite(resolvedPath); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to change owner: %w", err).Error()), nil
+	}
+	info, err := os.Stat(resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to stat path: %w", err).Error()), nil
+	}
+
+	if err := os.Chown(resolvedPath, uid, gid); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to change owner: %w", err).Error()), nil
+	}
+	changed := 1
+	if recursive && info.IsDir() {
+		err := filepath.WalkDir(resolvedPath, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if p == resolvedPath {
+				return nil
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			// Lchown, so a symlink cannot redirect the change outside the tree
+			if err := os.Lchown(p, uid, gid); err != nil {
+				return err
+			}
+			changed++
+			return nil
+		})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to change owner after %d entries: %w", changed, err).Error()), nil
+		}
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Changed ownership of %d entries under %s to %s", changed, resolvedPath, describeOwnership(owner, group))), nil
+}
+
+// describeOwnership formats an owner and group as chown(1) would take them.
+func describeOwnership(owner, group string) string {
+	switch {
+	case group == "":
+		return owner
+	case owner == "":
+		return ":" + group
+	}
+	return owner + ":" + group
+}
+
+// lookupUID resolves a user name or numeric uid.
+func lookupUID(owner string) (int, error) {
+	if id, err := strconv.Atoi(owner); err == nil && id >= 0 {
+		return id, nil
+	}
+	return lookupUserID(owner)
+}
+
+// lookupGID resolves a group name or numeric gid.
+func lookupGID(group string) (int, error) {
+	if id, err := strconv.Atoi(group); err == nil && id >= 0 {
+		return id, nil
+	}
+	return lookupGroupID(group)
+}
@@ -0,0 +1,18 @@
+//go:build !unix
+
+package tools
+
+import "errors"
+
+// ChownSupported reports whether change_owner works on this platform.
+const ChownSupported = false
+
+var errChownUnsupported = errors.New("change_owner is only supported on Unix")
+
+func lookupUserID(name string) (int, error) {
+	return 0, errChownUnsupported
+}
+
+func lookupGroupID(name string) (int, error) {
+	return 0, errChownUnsupported
+}
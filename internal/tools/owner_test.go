@@ -0,0 +1,58 @@
+//go:build unix
+
+package tools
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestHandleChangeOwner(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	dir := filepath.Join(tmpDir, "tree")
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub", "a.txt"), []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	uid, gid := strconv.Itoa(os.Getuid()), strconv.Itoa(os.Getgid())
+
+	// Changing to the current ids needs no privileges
+	result := callTool(t, HandleChangeOwner, reg, map[string]any{"path": dir, "owner": uid, "group": gid, "recursive": true})
+	if result.IsError {
+		t.Fatalf("change_owner failed: %s", resultText(result))
+	}
+	if !strings.Contains(resultText(result), "3 entries") {
+		t.Errorf("expected 3 entries changed, got %q", resultText(result))
+	}
+	info, _ := os.Stat(filepath.Join(dir, "sub", "a.txt"))
+	if st := info.Sys().(*syscall.Stat_t); strconv.Itoa(int(st.Uid)) != uid || strconv.Itoa(int(st.Gid)) != gid {
+		t.Errorf("expected %s:%s, got %d:%d", uid, gid, st.Uid, st.Gid)
+	}
+
+	if u, err := user.Current(); err == nil {
+		result = callTool(t, HandleChangeOwner, reg, map[string]any{"path": dir, "owner": u.Username})
+		if result.IsError {
+			t.Errorf("change_owner by name failed: %s", resultText(result))
+		}
+	}
+
+	result = callTool(t, HandleChangeOwner, reg, map[string]any{"path": dir})
+	if !result.IsError {
+		t.Error("expected an error without owner or group")
+	}
+	result = callTool(t, HandleChangeOwner, reg, map[string]any{"path": dir, "owner": "no-such-user-fsmcp"})
+	if !result.IsError || !strings.Contains(resultText(result), "unknown owner") {
+		t.Errorf("expected unknown owner error, got %q", resultText(result))
+	}
+	result = callTool(t, HandleChangeOwner, reg, map[string]any{"path": "/etc/passwd", "owner": uid})
+	if !result.IsError {
+		t.Error("expected an error outside allowed directories")
+	}
+}
@@ -0,0 +1,27 @@
+//go:build unix
+
+package tools
+
+import (
+	"os/user"
+	"strconv"
+)
+
+// ChownSupported reports whether change_owner works on this platform.
+const ChownSupported = true
+
+func lookupUserID(name string) (int, error) {
+	u, err := user.Lookup(name)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(u.Uid)
+}
+
+func lookupGroupID(name string) (int, error) {
+	g, err := user.LookupGroup(name)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(g.Gid)
+}
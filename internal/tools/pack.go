@@ -0,0 +1,340 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/proposal"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/spf13/cast"
+)
+
+const (
+	defaultPackMaxFileSize = 100 * 1024
+	defaultPackMaxTokens   = 50000
+	// bytesPerToken is the rough ratio used to estimate token counts.
+	bytesPerToken = 4
+	// binarySniffLen is how much of a file is checked for NUL bytes.
+	binarySniffLen = 8000
+	// packManifestName is the manifest written alongside chunk files.
+	packManifestName = "manifest.json"
+)
+
+// packOptions controls which files are packed and how.
+type packOptions struct {
+	treeFilter
+	maxFileSize int64
+	minify      bool
+}
+
+// packedFile is a file selected for packing, with its rendered section.
+type packedFile struct {
+	RelPath string
+	Hash    string
+	Size    int64
+	Section string
+	Tokens  int
+}
+
+// skippedFile is a file left out of a pack and why.
+type skippedFile struct {
+	RelPath string `json:"path"`
+	Reason  string `json:"reason"`
+}
+
+// packManifest describes a chunked export written by pack_context.
+type packManifest struct {
+	Root    string        `json:"root"`
+	Files   int           `json:"files"`
+	Tokens  int           `json:"tokens"`
+	Chunks  []packChunk   `json:"chunks"`
+	Skipped []skippedFile `json:"skipped"`
+}
+
+// packChunk is one numbered chunk file of an export.
+type packChunk struct {
+	File   string          `json:"file"`
+	Hash   string          `json:"hash"`
+	Tokens int             `json:"tokens"`
+	Files  []manifestEntry `json:"files"`
+}
+
+// manifestEntry is a packed file and the hash of its original content.
+type manifestEntry struct {
+	Path string `json:"path"`
+	Hash string `json:"hash"`
+	Size int64  `json:"size"`
+}
+
+// NewPackContextTool creates the pack_context tool.
+func NewPackContextTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"pack_context",
+		mcp.WithDescription("Pack the text files under a directory into one document of path headers and file contents, honoring .gitignore, size caps, and a token budget. Files are in stable path order; binary files, symlinks, and .git directories are skipped. With outputDir, the output is instead split into numbered chunk files plus a manifest.json listing the paths and hashes in each chunk."),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Pack Context",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(true),
+			IdempotentHint:  boolPtr(true),
+		}),
+		mcp.WithString("path", mcp.Description("Directory to pack"), mcp.Required()),
+		mcp.WithArray("patterns", mcp.Description("Glob patterns selecting files, relative to path (default: all files)"), mcp.Items(map[string]any{"type": "string"})),
+		mcp.WithArray("excludePatterns", mcp.Description("Glob patterns to exclude"), mcp.Items(map[string]any{"type": "string"})),
+		mcp.WithBoolean("respectGitignore", mcp.Description("If false, include files ignored by .gitignore (default: true)")),
+		mcp.WithString("maxFileSize", mcp.Description("Skip files larger than this, e.g. '100KB' (default: 100KB)")),
+		mcp.WithNumber("maxTokens", mcp.Description(fmt.Sprintf("Approximate token budget for the document, or for each chunk with outputDir, estimated at %d bytes per token (default: %d)", bytesPerToken, defaultPackMaxTokens))),
+		mcp.WithBoolean("minify", mcp.Description("If true, strip trailing whitespace and blank lines from file contents")),
+		mcp.WithString("outputDir", mcp.Description("If set, write chunk-NNNN.md files and manifest.json to this directory instead of returning the document. Existing files with those names are overwritten.")),
+	)
+}
+
+// HandlePackContext handles the pack_context tool.
+func HandlePackContext(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	outputDir := cast.ToString(request.Params.Arguments["outputDir"])
+	maxTokens := cast.ToInt(request.Params.Arguments["maxTokens"])
+	if maxTokens <= 0 {
+		maxTokens = defaultPackMaxTokens
+	}
+
+	resolvedPath, opts, errResult := parsePackRequest(reg, path, request)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	var resolvedOutput string
+	if outputDir != "" {
+		var err error
+		resolvedOutput, err = reg.ValidateForCreation(outputDir)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("outputDir validation failed: %w", err).Error()), nil
+		}
+		// Never pack a previous export
+		opts.skipDir = resolvedOutput
+	}
+
+	files, skipped, err := collectPackFiles(ctx, reg, resolvedPath, opts)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to pack directory: %w", err).Error()), nil
+	}
+
+	if resolvedOutput != "" {
+		return exportPackChunks(reg, resolvedPath, resolvedOutput, files, skipped, maxTokens)
+	}
+
+	var body strings.Builder
+	var included, tokens int
+	for _, f := range files {
+		if tokens+f.Tokens > maxTokens {
+			skipped = append(skipped, skippedFile{RelPath: f.RelPath, Reason: "token budget exhausted"})
+			continue
+		}
+		body.WriteString(f.Section)
+		tokens += f.Tokens
+		included++
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "# Packed context: %s\n%d files, ~%d tokens\n\n", resolvedPath, included, tokens)
+	result.WriteString(body.String())
+	if len(skipped) > 0 {
+		fmt.Fprintf(&result, "## Skipped (%d)\n", len(skipped))
+		for _, s := range skipped {
+			fmt.Fprintf(&result, "- %s (%s)\n", s.RelPath, s.Reason)
+		}
+	}
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// exportPackChunks splits files into chunks of at most maxTokens, writes
+// them to outputDir with a manifest, and summarizes the export. A file is
+// never split; one larger than the budget gets a chunk of its own.
+func exportPackChunks(reg *registry.Registry, root, outputDir string, files []packedFile, skipped []skippedFile, maxTokens int) (*mcp.CallToolResult, error) {
+	manifest := packManifest{Root: root, Chunks: []packChunk{}, Skipped: skipped}
+	if manifest.Skipped == nil {
+		manifest.Skipped = []skippedFile{}
+	}
+
+	var bodies []string
+	var body strings.Builder
+	for _, f := range files {
+		n := len(manifest.Chunks)
+		if n == 0 || (len(manifest.Chunks[n-1].Files) > 0 && manifest.Chunks[n-1].Tokens+f.Tokens > maxTokens) {
+			if n > 0 {
+				bodies = append(bodies, body.String())
+				body.Reset()
+			}
+			manifest.Chunks = append(manifest.Chunks, packChunk{File: fmt.Sprintf("chunk-%04d.md", n+1)})
+			n++
+		}
+		chunk := &manifest.Chunks[n-1]
+		chunk.Files = append(chunk.Files, manifestEntry{Path: f.RelPath, Hash: f.Hash, Size: f.Size})
+		chunk.Tokens += f.Tokens
+		body.WriteString(f.Section)
+		manifest.Files++
+		manifest.Tokens += f.Tokens
+	}
+	if len(manifest.Chunks) > 0 {
+		bodies = append(bodies, body.String())
+	}
+
+	if err := reg.CheckWrite(outputDir); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to create output directory: %w", err).Error()), nil
+	}
+	if reg.Overlay() == nil {
+		if err := safeMkdirAll(reg.ExpandPath(outputDir), reg.Modes().Dir, reg.Get()); err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to create output directory: %w", err).Error()), nil
+		}
+	}
+
+	for i := range manifest.Chunks {
+		chunk := &manifest.Chunks[i]
+		content := fmt.Sprintf("# Packed context: %s (chunk %d of %d)\n\n%s", root, i+1, len(manifest.Chunks), bodies[i])
+		chunk.Hash = proposal.HashContent([]byte(content))
+		if err := writePackFile(reg, filepath.Join(outputDir, chunk.File), []byte(content)); err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to write %s: %w", chunk.File, err).Error()), nil
+		}
+	}
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to marshal manifest: %w", err).Error()), nil
+	}
+	if err := writePackFile(reg, filepath.Join(outputDir, packManifestName), append(manifestJSON, '\n')); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to write manifest: %w", err).Error()), nil
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "Exported %d files (~%d tokens) from %s in %d chunks to %s\n", manifest.Files, manifest.Tokens, root, len(manifest.Chunks), outputDir)
+	for _, c := range manifest.Chunks {
+		fmt.Fprintf(&result, "  %s  %d files, ~%d tokens\n", c.File, len(c.Files), c.Tokens)
+	}
+	if len(skipped) > 0 {
+		fmt.Fprintf(&result, "Skipped %d files; see %s\n", len(skipped), packManifestName)
+	}
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// writePackFile writes one file of an export through the normal write path.
+func writePackFile(reg *registry.Registry, path string, data []byte) error {
+	target, allowedDirs, err := writeTarget(reg, path)
+	if err != nil {
+		return err
+	}
+	return atomicWriteFile(target, data, reg.Modes().File, allowedDirs)
+}
+
+// parsePackRequest validates the directory and reads the packing options.
+func parsePackRequest(reg *registry.Registry, path string, request mcp.CallToolRequest) (string, packOptions, *mcp.CallToolResult) {
+	opts := packOptions{maxFileSize: defaultPackMaxFileSize}
+	opts.minify = cast.ToBool(request.Params.Arguments["minify"])
+
+	if v := cast.ToString(request.Params.Arguments["maxFileSize"]); v != "" {
+		size, err := parseSize(v)
+		if err != nil {
+			return "", opts, mcp.NewToolResultError(fmt.Errorf("invalid maxFileSize: %w", err).Error())
+		}
+		opts.maxFileSize = size
+	}
+
+	filter, err := parseTreeFilter(request)
+	if err != nil {
+		return "", opts, mcp.NewToolResultError(err.Error())
+	}
+	opts.treeFilter = filter
+
+	resolvedPath, errResult := resolveDirectory(reg, path)
+	return resolvedPath, opts, errResult
+}
+
+// collectPackFiles walks root in lexical order and renders a section for
+// every file that passes the filters.
+func collectPackFiles(ctx context.Context, reg *registry.Registry, root string, opts packOptions) ([]packedFile, []skippedFile, error) {
+	var files []packedFile
+	var skipped []skippedFile
+	err := walkTree(ctx, reg, root, opts.treeFilter, func(walkPath, relPath string, entry fs.DirEntry) error {
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		if info.Size() > opts.maxFileSize {
+			skipped = append(skipped, skippedFile{RelPath: relPath, Reason: "larger than " + stream.FormatSize(opts.maxFileSize)})
+			return nil
+		}
+
+		source, err := readTarget(reg, walkPath)
+		if err != nil {
+			return nil
+		}
+		data, err := os.ReadFile(source)
+		if err != nil {
+			skipped = append(skipped, skippedFile{RelPath: relPath, Reason: "unreadable"})
+			return nil
+		}
+		if looksBinary(data) {
+			skipped = append(skipped, skippedFile{RelPath: relPath, Reason: "binary"})
+			return nil
+		}
+
+		content := string(data)
+		if opts.minify {
+			content = minifyText(content)
+		}
+		section := renderPackSection(relPath, content)
+		files = append(files, packedFile{
+			RelPath: relPath,
+			Hash:    proposal.HashContent(data),
+			Size:    info.Size(),
+			Section: section,
+			Tokens:  (len(section) + bytesPerToken - 1) / bytesPerToken,
+		})
+		return nil
+	})
+	return files, skipped, err
+}
+
+// renderPackSection renders one file as a header and a fenced block. The
+// fence is longer than any backtick run in the content.
+func renderPackSection(relPath, content string) string {
+	fence := "```"
+	for strings.Contains(content, fence) {
+		fence += "`"
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return fmt.Sprintf("## %s\n%s\n%s%s\n\n", relPath, fence, content, fence)
+}
+
+// minifyText strips trailing whitespace and blank lines.
+func minifyText(content string) string {
+	var out strings.Builder
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			continue
+		}
+		out.WriteString(line)
+		out.WriteString("\n")
+	}
+	return out.String()
+}
+
+// looksBinary reports whether data appears to be binary, by the presence of
+// a NUL byte near the start.
+func looksBinary(data []byte) bool {
+	if len(data) > binarySniffLen {
+		data = data[:binarySniffLen]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
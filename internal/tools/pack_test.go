@@ -0,0 +1,207 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/portertech/filesystem-mcp-server/internal/proposal"
+)
+
+func writePackTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPackContext(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	writePackTree(t, tmpDir, map[string]string{
+		".gitignore":          "build/\n*.log\n",
+		"main.go":             "package main\n",
+		"README.md":           "Use ```go``` fences\n",
+		"debug.log":           "noise\n",
+		"build/out.txt":       "artifact\n",
+		"pkg/.gitignore":      "gen.go\n",
+		"pkg/gen.go":          "package pkg // generated\n",
+		"pkg/lib.go":          "package pkg\n\n\nfunc F() {}   \n",
+		"pkg/data.bin":        "a\x00b",
+		"pkg/big.txt":         strings.Repeat("x", 2048),
+		".git/config":         "[core]\n",
+		"vendor/dep/dep.go":   "package dep\n",
+		"docs/guide/intro.md": "# Intro\n",
+	})
+
+	tests := []struct {
+		name        string
+		args        map[string]any
+		contains    []string
+		notContains []string
+	}{
+		{
+			name: "honors gitignore and caps",
+			args: map[string]any{"maxFileSize": "1KB"},
+			contains: []string{
+				"## main.go\n```\npackage main\n```",
+				"## README.md\n````\nUse ```go``` fences\n````",
+				"## pkg/lib.go",
+				"## docs/guide/intro.md",
+				"- pkg/big.txt (larger than 1.0 KB)",
+				"- pkg/data.bin (binary)",
+			},
+			notContains: []string{"debug.log", "build/out.txt", "pkg/gen.go", ".git/config"},
+		},
+		{
+			name:     "gitignore disabled",
+			args:     map[string]any{"respectGitignore": false},
+			contains: []string{"## debug.log", "## build/out.txt", "## pkg/gen.go"},
+			notContains: []string{
+				".git/config",
+			},
+		},
+		{
+			name:        "patterns and excludes",
+			args:        map[string]any{"patterns": []interface{}{"**.go"}, "excludePatterns": []interface{}{"vendor"}},
+			contains:    []string{"## main.go", "## pkg/lib.go", "2 files"},
+			notContains: []string{"README.md", "dep.go"},
+		},
+		{
+			name:     "minify",
+			args:     map[string]any{"patterns": []interface{}{"pkg/lib.go"}, "minify": true},
+			contains: []string{"package pkg\nfunc F() {}\n```"},
+		},
+		{
+			name:     "token budget",
+			args:     map[string]any{"patterns": []interface{}{"**.go"}, "maxTokens": 10},
+			contains: []string{"1 files", "(token budget exhausted)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.args["path"] = tmpDir
+			result := callTool(t, HandlePackContext, reg, tt.args)
+			text := resultText(result)
+			if result.IsError {
+				t.Fatalf("unexpected error: %s", text)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(text, want) {
+					t.Errorf("expected %q in output:\n%s", want, text)
+				}
+			}
+			for _, unwanted := range tt.notContains {
+				if strings.Contains(text, unwanted) {
+					t.Errorf("did not expect %q in output:\n%s", unwanted, text)
+				}
+			}
+		})
+	}
+}
+
+func TestPackContextOrder(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	writePackTree(t, tmpDir, map[string]string{
+		"b.txt":   "b\n",
+		"a/z.txt": "z\n",
+		"a.txt":   "a\n",
+	})
+
+	text := resultText(callTool(t, HandlePackContext, reg, map[string]any{"path": tmpDir}))
+	a, az, b := strings.Index(text, "## a.txt"), strings.Index(text, "## a/z.txt"), strings.Index(text, "## b.txt")
+	if az < 0 || !(az < a && a < b) {
+		t.Errorf("files not in lexical walk order:\n%s", text)
+	}
+}
+
+func TestPackContextNotDirectory(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	file := filepath.Join(tmpDir, "file.txt")
+	if err := os.WriteFile(file, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := callTool(t, HandlePackContext, reg, map[string]any{"path": file})
+	if !result.IsError {
+		t.Fatal("expected error for non-directory path")
+	}
+}
+
+func TestPackContextChunks(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	writePackTree(t, tmpDir, map[string]string{
+		"a.txt":     strings.Repeat("a", 40),
+		"b.txt":     strings.Repeat("b", 40),
+		"c/big.txt": strings.Repeat("c", 400),
+		"d.txt":     strings.Repeat("d", 40),
+		"e.bin":     "\x00",
+	})
+	outDir := filepath.Join(tmpDir, "export")
+
+	args := map[string]any{"path": tmpDir, "outputDir": outDir, "maxTokens": 40}
+	result := callTool(t, HandlePackContext, reg, args)
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if !strings.Contains(resultText(result), "4 files") || !strings.Contains(resultText(result), "3 chunks") {
+		t.Errorf("unexpected summary:\n%s", resultText(result))
+	}
+
+	data, err := os.ReadFile(filepath.Join(outDir, "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest packManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	var got [][]string
+	for _, c := range manifest.Chunks {
+		var paths []string
+		for _, f := range c.Files {
+			paths = append(paths, f.Path)
+		}
+		got = append(got, paths)
+
+		chunk, err := os.ReadFile(filepath.Join(outDir, c.File))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if proposal.HashContent(chunk) != c.Hash {
+			t.Errorf("%s: hash does not match contents", c.File)
+		}
+	}
+	want := [][]string{{"a.txt", "b.txt"}, {"c/big.txt"}, {"d.txt"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("chunks = %v, want %v", got, want)
+	}
+	if manifest.Chunks[0].Files[0].Hash != proposal.HashContent([]byte(strings.Repeat("a", 40))) {
+		t.Error("file hash does not match original content")
+	}
+	if len(manifest.Skipped) != 1 || manifest.Skipped[0].RelPath != "e.bin" {
+		t.Errorf("skipped = %v, want e.bin", manifest.Skipped)
+	}
+
+	// Re-exporting must not pick up the previous export and must be stable
+	if result := callTool(t, HandlePackContext, reg, args); result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	again, err := os.ReadFile(filepath.Join(outDir, "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(data) {
+		t.Errorf("manifest changed between runs:\n%s\n---\n%s", data, again)
+	}
+}
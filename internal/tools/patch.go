@@ -0,0 +1,383 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/journal"
+	"github.com/portertech/filesystem-mcp-server/internal/patch"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/spf13/cast"
+)
+
+const (
+	// maxPatchSize caps the size of the patch argument.
+	maxPatchSize = 10 * 1024 * 1024
+	// defaultPatchFuzz and maxPatchFuzz bound how many outer context lines
+	// of a hunk may be ignored, as patch -F does.
+	defaultPatchFuzz = 2
+	maxPatchFuzz     = 3
+)
+
+// patchedFile is a file change worked out by apply_patch before anything
+// is written.
+type patchedFile struct {
+	// path is where the new content goes, or "" if the file is deleted.
+	path string
+	// oldPath is the file the diff was applied to, or "" if it is created.
+	oldPath    string
+	oldContent string
+	newContent string
+	perm       os.FileMode
+	placements []patch.Placement
+}
+
+// NewApplyPatchTool creates the apply_patch tool.
+func NewApplyPatchTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"apply_patch",
+		mcp.WithDescription("Apply a unified diff, such as the output of diff -u or git diff, to one or more files. Hunks that moved are found at their new lines, and with fuzz, hunks whose outermost context lines changed still apply. Every hunk is checked before anything is written, so a patch applies completely or not at all. Files can be created and deleted with /dev/null. Returns where each hunk applied and the resulting diff."),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithString("patch", mcp.Description("The unified diff to apply"), mcp.Required()),
+		mcp.WithString("path", mcp.Description("Directory that relative file names in the patch are resolved against. Required unless every name is absolute")),
+		mcp.WithNumber("strip", mcp.Description("Leading path components to remove from file names, as patch -p does (default: 1 when every name has git's a/ and b/ prefixes, otherwise 0)")),
+		mcp.WithNumber("fuzz", mcp.Description(fmt.Sprintf("Context lines that may be ignored at each end of a hunk that does not match exactly (default: %d, max: %d)", defaultPatchFuzz, maxPatchFuzz))),
+		mcp.WithBoolean("dryRun", mcp.Description("If true, check the patch and preview the result without writing")),
+		withForce(),
+		withConfirmationToken(),
+		withApprovalToken(),
+	)
+}
+
+// HandleApplyPatch handles the apply_patch tool.
+func HandleApplyPatch(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	diff := cast.ToString(request.Params.Arguments["patch"])
+	base := cast.ToString(request.Params.Arguments["path"])
+	dryRun := cast.ToBool(request.Params.Arguments["dryRun"])
+	force := cast.ToBool(request.Params.Arguments["force"])
+	fuzz := defaultPatchFuzz
+	if _, ok := request.Params.Arguments["fuzz"]; ok {
+		fuzz = cast.ToInt(request.Params.Arguments["fuzz"])
+	}
+	if fuzz < 0 || fuzz > maxPatchFuzz {
+		return mcp.NewToolResultError(fmt.Sprintf("fuzz must be between 0 and %d", maxPatchFuzz)), nil
+	}
+	if len(diff) > maxPatchSize {
+		return mcp.NewToolResultError(fmt.Sprintf("patch is larger than %d bytes", maxPatchSize)), nil
+	}
+
+	files, err := patch.Parse(diff)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("invalid patch: %w", err).Error()), nil
+	}
+	if len(files) == 0 {
+		return mcp.NewToolResultError("invalid patch: no file changes found"), nil
+	}
+	strip := 0
+	if _, ok := request.Params.Arguments["strip"]; ok {
+		strip = cast.ToInt(request.Params.Arguments["strip"])
+		if strip < 0 {
+			return mcp.NewToolResultError("strip must not be negative"), nil
+		}
+	} else if patch.GitPrefixed(files) {
+		strip = 1
+	}
+
+	// Work out every change first, so a patch that fails part way writes
+	// nothing
+	var changes []*patchedFile
+	var failures []string
+	planned := map[string]*patchedFile{}
+	for _, f := range files {
+		change, err := planPatch(reg, f, base, strip, fuzz, planned)
+		if err != nil {
+			failures = append(failures, err.Error())
+			continue
+		}
+		changes = append(changes, change)
+	}
+	if len(failures) > 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("patch does not apply; nothing was changed:\n%s", strings.Join(failures, "\n"))), nil
+	}
+
+	report := patchReport(changes)
+	if dryRun {
+		return mcp.NewToolResultText(fmt.Sprintf("Dry run - changes not applied:\n\n%s", report)), nil
+	}
+
+	var removed []string
+	var deleted int
+	var deletedBytes int64
+	for _, c := range changes {
+		if c.oldPath != "" && c.oldPath != c.path {
+			removed = append(removed, c.oldPath)
+		}
+		if c.path == "" {
+			deleted++
+			deletedBytes += int64(len(c.oldContent))
+		}
+	}
+	if err := checkDeleteLimits(reg, deleted, deletedBytes, force); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if len(removed) > 0 {
+		action := fmt.Sprintf("apply a patch that removes %s", strings.Join(removed, ", "))
+		if result := requireApproval(reg, "apply_patch", request, action, removed...); result != nil {
+			return result, nil
+		}
+	}
+
+	for _, c := range changes {
+		for _, p := range []string{c.path, c.oldPath} {
+			if p == "" {
+				continue
+			}
+			if err := reg.CheckWrite(p); err != nil {
+				return mcp.NewToolResultError(fmt.Errorf("nothing was changed: %w", err).Error()), nil
+			}
+		}
+	}
+
+	first := changes[0].path
+	if first == "" {
+		first = changes[0].oldPath
+	}
+	op := beginJournal(reg, "apply_patch", first)
+	for i, c := range changes {
+		err := commitPatch(reg, op, c)
+		if err == nil {
+			continue
+		}
+		// Undo this change, which may be partly done, and those before it
+		var failed []string
+		for j := i; j >= 0; j-- {
+			if err := revertPatch(reg, changes[j]); err != nil {
+				failed = append(failed, err.Error())
+			}
+		}
+		if len(failed) > 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to write patched files: %v\nRolling back failed, the files may be partly patched:\n%s%s", err, strings.Join(failed, "\n"), journalNote(op))), nil
+		}
+		op.Discard()
+		return mcp.NewToolResultError(fmt.Sprintf("failed to write patched files: %v\nRolled back; nothing was changed.", err)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Successfully applied patch%s\n\n%s", journalNote(op), report)), nil
+}
+
+// planPatch validates the paths of one file diff and applies its hunks in
+// memory. planned holds the changes made so far, so that a file patched
+// twice in one patch sees its first change.
+func planPatch(reg *registry.Registry, f patch.File, base string, strip, fuzz int, planned map[string]*patchedFile) (*patchedFile, error) {
+	resolve := func(name string) (string, error) {
+		name = patch.Strip(name, strip)
+		if filepath.IsAbs(name) {
+			return name, nil
+		}
+		if base == "" {
+			return "", fmt.Errorf("%s: relative file name needs the path argument", name)
+		}
+		return filepath.Join(base, filepath.FromSlash(name)), nil
+	}
+
+	change := &patchedFile{perm: reg.Modes().File}
+	display := patch.Strip(f.NewName, strip)
+	if !f.Created() {
+		name, err := resolve(f.OldName)
+		if err != nil {
+			return nil, err
+		}
+		display = name
+		// Changes are keyed by the path writes would go to. A file the patch
+		// created or changed already is only in planned.
+		key, err := reg.ValidateForCreation(name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if prior, ok := planned[key]; ok {
+			if prior.path != key {
+				return nil, fmt.Errorf("%s: removed earlier in the patch", name)
+			}
+			change.oldPath, change.oldContent, change.perm = key, prior.newContent, prior.perm
+		} else {
+			resolvedPath, err := validateFinal(reg, name)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			source, err := readTarget(reg, resolvedPath)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			info, err := os.Stat(source)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			if info.IsDir() {
+				return nil, fmt.Errorf("%s: is a directory", name)
+			}
+			data, err := os.ReadFile(source)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			if looksBinary(data) {
+				return nil, fmt.Errorf("%s: is a binary file", name)
+			}
+			change.oldPath, change.oldContent, change.perm = key, string(data), info.Mode().Perm()
+		}
+	}
+	if !f.Deleted() {
+		name, err := resolve(f.NewName)
+		if err != nil {
+			return nil, err
+		}
+		display = name
+		resolvedPath, err := reg.ValidateForCreation(name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if resolvedPath != change.oldPath {
+			_, seen := planned[resolvedPath]
+			if _, err := statTarget(reg, resolvedPath); seen || err == nil {
+				return nil, fmt.Errorf("%s: already exists", name)
+			}
+		}
+		change.path = resolvedPath
+	}
+
+	newContent, placements, err := patch.Apply(change.oldContent, f.Hunks, fuzz)
+	if err != nil {
+		var hunkErr *patch.HunkError
+		if errors.As(err, &hunkErr) && fuzz < maxPatchFuzz {
+			return nil, fmt.Errorf("%s: %w; check the patch against the current file, or raise fuzz", display, err)
+		}
+		return nil, fmt.Errorf("%s: %w", display, err)
+	}
+	if f.Deleted() && newContent != "" {
+		return nil, fmt.Errorf("%s: file would not be empty after its deletion hunks", display)
+	}
+	change.newContent = newContent
+	change.placements = placements
+
+	if change.oldPath != "" {
+		planned[change.oldPath] = change
+	}
+	if change.path != "" {
+		planned[change.path] = change
+	}
+	return change, nil
+}
+
+// commitPatch writes, moves, or deletes one patched file, noting each
+// file in op just before changing it.
+func commitPatch(reg *registry.Registry, op *journal.Pending, c *patchedFile) error {
+	if c.path != "" {
+		target, allowedDirs, err := checkedWriteTarget(reg, c.path)
+		if err != nil {
+			return err
+		}
+		op.Save(c.path)
+		if reg.Overlay() == nil {
+			if err := safeMkdirAll(filepath.Dir(c.path), reg.Modes().Dir, reg.Get()); err != nil {
+				return fmt.Errorf("failed to create directories: %w", err)
+			}
+		}
+		if err := atomicWriteFile(target, []byte(c.newContent), c.perm, allowedDirs); err != nil {
+			return err
+		}
+		if store := reg.Shadow(); store != nil {
+			store.Record(target, []byte(c.newContent))
+		}
+	}
+	if c.oldPath != "" && c.oldPath != c.path {
+		op.Save(c.oldPath)
+		if t := reg.Trash(); t != nil {
+			_, err := t.Put(containingRoot(reg, c.oldPath), c.oldPath, false, int64(len(c.oldContent)))
+			return err
+		}
+		if ov := reg.Overlay(); ov != nil {
+			return ov.Remove(c.oldPath)
+		}
+		return os.Remove(c.oldPath)
+	}
+	return nil
+}
+
+// revertPatch restores the state before c, which may have been applied in
+// part, from the content planPatch read. A file c deleted into the trash
+// is rewritten, and its trash entry is left behind.
+func revertPatch(reg *registry.Registry, c *patchedFile) error {
+	if c.oldPath != "" {
+		target, allowedDirs, err := checkedWriteTarget(reg, c.oldPath)
+		if err != nil {
+			return fmt.Errorf("%s: %w", c.oldPath, err)
+		}
+		if err := atomicWriteFile(target, []byte(c.oldContent), c.perm, allowedDirs); err != nil {
+			return fmt.Errorf("%s: %w", c.oldPath, err)
+		}
+		if store := reg.Shadow(); store != nil {
+			store.Record(target, []byte(c.oldContent))
+		}
+	}
+	if c.path == "" || c.path == c.oldPath {
+		return nil
+	}
+	if _, err := statTarget(reg, c.path); err != nil {
+		// Never written
+		return nil
+	}
+	var err error
+	if ov := reg.Overlay(); ov != nil {
+		err = ov.Remove(c.path)
+	} else {
+		err = os.Remove(c.path)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", c.path, err)
+	}
+	return nil
+}
+
+// patchReport describes each change and where its hunks applied, followed
+// by the diff of what was changed.
+func patchReport(changes []*patchedFile) string {
+	var b strings.Builder
+	for _, c := range changes {
+		switch {
+		case c.oldPath == "":
+			fmt.Fprintf(&b, "created %s", c.path)
+		case c.path == "":
+			fmt.Fprintf(&b, "deleted %s", c.oldPath)
+		case c.oldPath != c.path:
+			fmt.Fprintf(&b, "renamed %s to %s", c.oldPath, c.path)
+		default:
+			fmt.Fprintf(&b, "modified %s", c.path)
+		}
+		fmt.Fprintf(&b, ": %d hunk(s)", len(c.placements))
+		for i, p := range c.placements {
+			if p.Offset == 0 && p.Fuzz == 0 {
+				continue
+			}
+			fmt.Fprintf(&b, "; hunk %d applied at line %d", i+1, p.Line)
+			if p.Offset != 0 {
+				fmt.Fprintf(&b, " (offset %+d lines)", p.Offset)
+			}
+			if p.Fuzz != 0 {
+				fmt.Fprintf(&b, " with fuzz %d", p.Fuzz)
+			}
+		}
+		b.WriteString("\n")
+	}
+	for _, c := range changes {
+		name := c.path
+		if name == "" {
+			name = c.oldPath
+		}
+		b.WriteString("\n")
+		b.WriteString(generateUnifiedDiff(name, c.oldContent, c.newContent))
+	}
+	return b.String()
+}
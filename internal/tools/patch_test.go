@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHandleApplyPatch(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(tmpDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	write("main.go", "package main\n\n// added above the hunk\n\nfunc main() {\n\tprintln(\"hi\")\n}\n")
+	write("old.txt", "gone\n")
+
+	diff := `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -3,3 +3,3 @@
+ func main() {
+-	println("hi")
++	println("hello")
+ }
+diff --git a/docs/new.md b/docs/new.md
+new file mode 100644
+--- /dev/null
++++ b/docs/new.md
+@@ -0,0 +1 @@
++# New
+diff --git a/old.txt b/old.txt
+deleted file mode 100644
+--- a/old.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-gone
+`
+
+	result := callTool(t, HandleApplyPatch, reg, map[string]any{"patch": diff, "path": tmpDir, "dryRun": true})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if text := resultText(result); !strings.Contains(text, "Dry run") || !strings.Contains(text, "hunk 1 applied at line 5 (offset +2 lines)") {
+		t.Errorf("unexpected dry run report:\n%s", text)
+	}
+	if strings.Contains(read("main.go"), "hello") {
+		t.Fatal("dry run changed the file")
+	}
+
+	result = callTool(t, HandleApplyPatch, reg, map[string]any{"patch": diff, "path": tmpDir})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if got := read("main.go"); !strings.Contains(got, `println("hello")`) {
+		t.Errorf("main.go not patched:\n%s", got)
+	}
+	if got := read("docs/new.md"); got != "# New\n" {
+		t.Errorf("docs/new.md = %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "old.txt")); !os.IsNotExist(err) {
+		t.Error("expected old.txt to be deleted")
+	}
+}
+
+func TestHandleApplyPatchAllOrNothing(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	a := filepath.Join(tmpDir, "a.txt")
+	b := filepath.Join(tmpDir, "b.txt")
+	os.WriteFile(a, []byte("one\ntwo\n"), 0644)
+	os.WriteFile(b, []byte("three\nfour\n"), 0644)
+
+	// Absolute names need no base directory; the second file does not match
+	diff := "--- " + a + "\n+++ " + a + "\n@@ -1,2 +1,2 @@\n one\n-two\n+TWO\n" +
+		"--- " + b + "\n+++ " + b + "\n@@ -1,2 +1,2 @@\n five\n-six\n+SIX\n"
+	result := callTool(t, HandleApplyPatch, reg, map[string]any{"patch": diff})
+	if !result.IsError || !strings.Contains(resultText(result), "b.txt: hunk 1") {
+		t.Fatalf("expected the failing hunk to be reported, got %s", resultText(result))
+	}
+	if data, _ := os.ReadFile(a); string(data) != "one\ntwo\n" {
+		t.Errorf("expected a.txt to be untouched, got %q", data)
+	}
+
+	for name, args := range map[string]map[string]any{
+		"relative without path": {"patch": "--- a/x\n+++ b/x\n@@ -1 +1 @@\n-a\n+b\n"},
+		"outside allowed":       {"patch": "--- /etc/hosts\n+++ /etc/hosts\n@@ -1 +1 @@\n-a\n+b\n"},
+		"create existing":       {"patch": "--- /dev/null\n+++ " + a + "\n@@ -0,0 +1 @@\n+x\n"},
+		"not a patch":           {"patch": "just some text"},
+		"fuzz too high":         {"patch": "--- " + a + "\n+++ " + a + "\n@@ -1 +1 @@\n-one\n+ONE\n", "fuzz": 4},
+	} {
+		if result := callTool(t, HandleApplyPatch, reg, args); !result.IsError {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestHandleApplyPatchRollBack(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	a := filepath.Join(tmpDir, "a.txt")
+	os.WriteFile(a, []byte("one\n"), 0644)
+	// A file where the patch expects a directory fails only when written
+	os.WriteFile(filepath.Join(tmpDir, "docs"), []byte("not a directory"), 0644)
+
+	diff := "--- " + a + "\n+++ " + a + "\n@@ -1 +1 @@\n-one\n+ONE\n" +
+		"--- /dev/null\n+++ " + filepath.Join(tmpDir, "docs", "new.md") + "\n@@ -0,0 +1 @@\n+# New\n"
+	result := callTool(t, HandleApplyPatch, reg, map[string]any{"patch": diff})
+	if !result.IsError || !strings.Contains(resultText(result), "Rolled back") {
+		t.Fatalf("expected the patch to be rolled back, got %s", resultText(result))
+	}
+	if data, _ := os.ReadFile(a); string(data) != "one\n" {
+		t.Errorf("expected a.txt to be restored, got %q", data)
+	}
+}
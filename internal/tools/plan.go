@@ -0,0 +1,251 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/oplog"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/spf13/cast"
+)
+
+// ExecutePlanTool is the name of the execute_plan tool. Plans cannot call
+// it, and its calls are not recorded in the operation log, since each of
+// its steps is.
+const ExecutePlanTool = "execute_plan"
+
+// maxPlanSize caps the size of a plan file.
+const maxPlanSize = 10 * 1024 * 1024
+
+// ToolHandler runs a tool call.
+type ToolHandler func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+
+// ToolLookup finds a registered tool and its handler by name.
+type ToolLookup func(name string) (mcp.Tool, ToolHandler, bool)
+
+// Step statuses reported by execute_plan.
+const (
+	stepOK        = "ok"
+	stepFailed    = "failed"
+	stepSkipped   = "skipped"
+	stepPreviewed = "previewed"
+	stepValidated = "validated"
+)
+
+// stepResult is the outcome of one plan step.
+type stepResult struct {
+	Step   int    `json:"step"`
+	Tool   string `json:"tool"`
+	Status string `json:"status"`
+	Output string `json:"output,omitempty"`
+}
+
+// planResult is the result of execute_plan.
+type planResult struct {
+	Plan      string       `json:"plan"`
+	DryRun    bool         `json:"dryRun"`
+	Succeeded int          `json:"succeeded"`
+	Failed    int          `json:"failed"`
+	Skipped   int          `json:"skipped"`
+	Steps     []stepResult `json:"steps"`
+}
+
+// NewExecutePlanTool creates the execute_plan tool.
+func NewExecutePlanTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		ExecutePlanTool,
+		mcp.WithDescription("Run a JSON plan file of tool calls, such as one written by export_operations: {\"version\": 1, \"steps\": [{\"tool\": ..., \"arguments\": {...}}]}. Every step is validated against its tool's parameters before any runs. Steps run in order with the same checks as direct calls, and the result reports each step's outcome."),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Execute Plan",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(true),
+			IdempotentHint:  boolPtr(false),
+		}),
+		mcp.WithString("path", mcp.Description("Path to the plan file"), mcp.Required()),
+		mcp.WithBoolean("dryRun", mcp.Description("Validate the plan without changing anything. Steps whose tool is read-only or has a dryRun parameter are run as previews (default: false)")),
+		mcp.WithString("onError", mcp.Description("What to do when a step fails: 'stop' (default) skips the remaining steps, 'continue' runs them")),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleExecutePlan handles the execute_plan tool.
+func HandleExecutePlan(ctx context.Context, reg *registry.Registry, lookup ToolLookup, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	dryRun := cast.ToBool(request.Params.Arguments["dryRun"])
+	onError := cast.ToString(request.Params.Arguments["onError"])
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	if onError == "" {
+		onError = "stop"
+	}
+	if onError != "stop" && onError != "continue" {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid onError %q: expected stop or continue", onError)), nil
+	}
+
+	resolvedPath, err := validateRead(reg, path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	plan, err := loadPlan(resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("invalid plan: %w", err).Error()), nil
+	}
+
+	// Validate every step before running any
+	handlers := make([]ToolHandler, len(plan.Steps))
+	readOnly := make([]bool, len(plan.Steps))
+	hasDryRun := make([]bool, len(plan.Steps))
+	for i, step := range plan.Steps {
+		tool, handler, err := validateStep(lookup, step)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid plan: step %d (%s): %v", i+1, step.Tool, err)), nil
+		}
+		handlers[i] = handler
+		readOnly[i] = IsReadOnly(tool)
+		_, hasDryRun[i] = tool.InputSchema.Properties["dryRun"]
+	}
+
+	result := planResult{Plan: resolvedPath, DryRun: dryRun, Steps: make([]stepResult, 0, len(plan.Steps))}
+	stopped := false
+	for i, step := range plan.Steps {
+		sr := stepResult{Step: i + 1, Tool: step.Tool}
+		switch {
+		case stopped || ctx.Err() != nil:
+			sr.Status = stepSkipped
+			result.Skipped++
+		case dryRun && !readOnly[i] && !hasDryRun[i]:
+			sr.Status = stepValidated
+		default:
+			args := step.Arguments
+			if dryRun && hasDryRun[i] {
+				args = withArgument(args, "dryRun", true)
+			}
+			req := mcp.CallToolRequest{}
+			req.Params.Name = step.Tool
+			req.Params.Arguments = args
+			res, err := handlers[i](ctx, req)
+			switch {
+			case err != nil:
+				sr.Status, sr.Output = stepFailed, err.Error()
+			case res.IsError:
+				sr.Status, sr.Output = stepFailed, ContentText(res)
+			case dryRun:
+				sr.Status, sr.Output = stepPreviewed, ContentText(res)
+			default:
+				sr.Status, sr.Output = stepOK, ContentText(res)
+			}
+			if sr.Status == stepFailed {
+				result.Failed++
+				stopped = onError == "stop"
+			} else {
+				result.Succeeded++
+			}
+		}
+		result.Steps = append(result.Steps, sr)
+	}
+
+	if format == "json" {
+		jsonResult, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(jsonResult)), nil
+	}
+
+	var text strings.Builder
+	if dryRun {
+		fmt.Fprintf(&text, "Dry run of %s: %d steps validated\n", result.Plan, len(result.Steps))
+	} else {
+		fmt.Fprintf(&text, "Executed %s: %d succeeded, %d failed, %d skipped\n", result.Plan, result.Succeeded, result.Failed, result.Skipped)
+	}
+	for _, sr := range result.Steps {
+		fmt.Fprintf(&text, "\n[%d] %s: %s\n", sr.Step, sr.Tool, sr.Status)
+		if sr.Output != "" {
+			text.WriteString(sr.Output)
+			text.WriteString("\n")
+		}
+	}
+	return mcp.NewToolResultText(text.String()), nil
+}
+
+// loadPlan reads and parses a plan file.
+func loadPlan(path string) (oplog.Plan, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return oplog.Plan{}, err
+	}
+	if info.Size() > maxPlanSize {
+		return oplog.Plan{}, fmt.Errorf("plan file is larger than %d bytes", maxPlanSize)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return oplog.Plan{}, err
+	}
+	var plan oplog.Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return oplog.Plan{}, err
+	}
+	if plan.Version != oplog.PlanVersion {
+		return oplog.Plan{}, fmt.Errorf("unsupported plan version %d, expected %d", plan.Version, oplog.PlanVersion)
+	}
+	if len(plan.Steps) == 0 {
+		return oplog.Plan{}, errors.New("plan has no steps")
+	}
+	return plan, nil
+}
+
+// validateStep checks that a step names a tool plans may call and that its
+// arguments match the tool's parameters.
+func validateStep(lookup ToolLookup, step oplog.Step) (mcp.Tool, ToolHandler, error) {
+	if step.Tool == ExecutePlanTool {
+		return mcp.Tool{}, nil, fmt.Errorf("plans cannot run %s", ExecutePlanTool)
+	}
+	tool, handler, ok := lookup(step.Tool)
+	if !ok {
+		return mcp.Tool{}, nil, errors.New("unknown tool")
+	}
+	props := tool.InputSchema.Properties
+	var unknown []string
+	for name := range step.Arguments {
+		if _, ok := props[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return mcp.Tool{}, nil, fmt.Errorf("unknown arguments: %s", strings.Join(unknown, ", "))
+	}
+	for _, name := range tool.InputSchema.Required {
+		if _, ok := step.Arguments[name]; !ok {
+			return mcp.Tool{}, nil, fmt.Errorf("missing required argument %s", name)
+		}
+	}
+	return tool, handler, nil
+}
+
+// withArgument returns a copy of args with name set to value.
+func withArgument(args map[string]any, name string, value any) map[string]any {
+	copied := make(map[string]any, len(args)+1)
+	for k, v := range args {
+		copied[k] = v
+	}
+	copied[name] = value
+	return copied
+}
+
+// ContentText joins the text content of a tool result.
+func ContentText(result *mcp.CallToolResult) string {
+	var parts []string
+	for _, c := range result.Content {
+		if text, ok := c.(mcp.TextContent); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
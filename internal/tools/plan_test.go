@@ -0,0 +1,150 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/oplog"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+)
+
+// testLookup serves a few real tools, as the server would.
+func testLookup(reg *registry.Registry) ToolLookup {
+	tools := map[string]struct {
+		tool    mcp.Tool
+		handler func(context.Context, *registry.Registry, mcp.CallToolRequest) (*mcp.CallToolResult, error)
+	}{
+		"write_file":       {NewWriteFileTool(reg), HandleWriteFile},
+		"edit_file":        {NewEditFileTool(reg), HandleEditFile},
+		"delete_file":      {NewDeleteFileTool(reg), HandleDeleteFile},
+		"create_directory": {NewCreateDirectoryTool(reg), HandleCreateDirectory},
+	}
+	return func(name string) (mcp.Tool, ToolHandler, bool) {
+		t, ok := tools[name]
+		if !ok {
+			return mcp.Tool{}, nil, false
+		}
+		return t.tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return t.handler(ctx, reg, req)
+		}, true
+	}
+}
+
+func writePlan(t *testing.T, dir string, steps ...oplog.Step) string {
+	t.Helper()
+	data, err := json.Marshal(oplog.Plan{Version: oplog.PlanVersion, Steps: steps})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "plan.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestHandleExecutePlan(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	lookup := testLookup(reg)
+	execute := func(args map[string]any) *mcp.CallToolResult {
+		return callTool(t, func(ctx context.Context, reg *registry.Registry, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return HandleExecutePlan(ctx, reg, lookup, req)
+		}, reg, args)
+	}
+	file := filepath.Join(tmpDir, "out", "a.txt")
+	plan := writePlan(t, tmpDir,
+		oplog.Step{Tool: "create_directory", Arguments: map[string]any{"path": filepath.Join(tmpDir, "out")}},
+		oplog.Step{Tool: "write_file", Arguments: map[string]any{"path": file, "content": "one"}},
+		oplog.Step{Tool: "edit_file", Arguments: map[string]any{"path": file, "edits": []any{map[string]any{"oldText": "one", "newText": "two"}}}},
+	)
+
+	// A dry run changes nothing
+	result := execute(map[string]any{"path": plan, "dryRun": true, "format": "json"})
+	if result.IsError {
+		t.Fatalf("dry run failed: %s", resultText(result))
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "out")); !os.IsNotExist(err) {
+		t.Fatal("dry run should not change anything")
+	}
+	var pr planResult
+	if err := json.Unmarshal([]byte(resultText(result)), &pr); err != nil {
+		t.Fatal(err)
+	}
+	if len(pr.Steps) != 3 || pr.Steps[0].Status != stepValidated || pr.Steps[2].Status != stepFailed {
+		t.Errorf("unexpected dry run steps: %+v", pr.Steps)
+	}
+
+	result = execute(map[string]any{"path": plan})
+	if result.IsError || !strings.Contains(resultText(result), "3 succeeded, 0 failed") {
+		t.Fatalf("execute failed: %s", resultText(result))
+	}
+	if data, _ := os.ReadFile(file); string(data) != "two" {
+		t.Errorf("expected plan to be applied, got %q", string(data))
+	}
+}
+
+func TestHandleExecutePlanOnError(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	lookup := testLookup(reg)
+	execute := func(args map[string]any) planResult {
+		t.Helper()
+		result := callTool(t, func(ctx context.Context, reg *registry.Registry, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return HandleExecutePlan(ctx, reg, lookup, req)
+		}, reg, args)
+		var pr planResult
+		if err := json.Unmarshal([]byte(resultText(result)), &pr); err != nil {
+			t.Fatalf("unexpected result: %s", resultText(result))
+		}
+		return pr
+	}
+	plan := writePlan(t, tmpDir,
+		oplog.Step{Tool: "delete_file", Arguments: map[string]any{"path": filepath.Join(tmpDir, "missing.txt")}},
+		oplog.Step{Tool: "write_file", Arguments: map[string]any{"path": filepath.Join(tmpDir, "b.txt"), "content": "b"}},
+	)
+
+	pr := execute(map[string]any{"path": plan, "format": "json"})
+	if pr.Failed != 1 || pr.Skipped != 1 || pr.Steps[1].Status != stepSkipped {
+		t.Errorf("expected stop on error, got %+v", pr)
+	}
+	pr = execute(map[string]any{"path": plan, "format": "json", "onError": "continue"})
+	if pr.Failed != 1 || pr.Succeeded != 1 || pr.Steps[1].Status != stepOK {
+		t.Errorf("expected continue on error, got %+v", pr)
+	}
+}
+
+func TestHandleExecutePlanValidation(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	lookup := testLookup(reg)
+	target := filepath.Join(tmpDir, "a.txt")
+
+	tests := []struct {
+		name string
+		step oplog.Step
+		want string
+	}{
+		{"unknown tool", oplog.Step{Tool: "format_disk"}, "unknown tool"},
+		{"recursive", oplog.Step{Tool: ExecutePlanTool, Arguments: map[string]any{"path": "plan.json"}}, "cannot run"},
+		{"missing argument", oplog.Step{Tool: "write_file", Arguments: map[string]any{"path": target}}, "missing required argument content"},
+		{"unknown argument", oplog.Step{Tool: "write_file", Arguments: map[string]any{"path": target, "content": "x", "mode": "0777"}}, "unknown arguments: mode"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A valid first step must not run when a later one is invalid
+			plan := writePlan(t, tmpDir, oplog.Step{Tool: "write_file", Arguments: map[string]any{"path": target, "content": "x"}}, tt.step)
+			result := callTool(t, func(ctx context.Context, reg *registry.Registry, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return HandleExecutePlan(ctx, reg, lookup, req)
+			}, reg, map[string]any{"path": plan})
+			if !result.IsError || !strings.Contains(resultText(result), tt.want) {
+				t.Errorf("expected error containing %q, got %s", tt.want, resultText(result))
+			}
+			if _, err := os.Stat(target); !os.IsNotExist(err) {
+				t.Error("no step should run when the plan is invalid")
+			}
+		})
+	}
+}
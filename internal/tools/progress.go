@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// progressInterval is the least time between two progress notifications.
+const progressInterval = 500 * time.Millisecond
+
+// progress sends progress notifications for a call whose client asked for
+// them with a progress token. A nil progress sends nothing. It is safe for
+// concurrent use.
+type progress struct {
+	ctx   context.Context
+	srv   *server.MCPServer
+	token mcp.ProgressToken
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+// newProgress returns a progress for request, or nil if the client did not
+// ask for progress.
+func newProgress(ctx context.Context, request mcp.CallToolRequest) *progress {
+	if request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil {
+		return nil
+	}
+	srv := server.ServerFromContext(ctx)
+	if srv == nil {
+		return nil
+	}
+	return &progress{ctx: ctx, srv: srv, token: request.Params.Meta.ProgressToken}
+}
+
+// report notifies the client that done of total units are done, where a
+// total of 0 is unknown. Reports closer together than progressInterval are
+// dropped, except the final one.
+func (p *progress) report(done, total int64, final bool) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	now := time.Now()
+	if !final && now.Sub(p.last) < progressInterval {
+		p.mu.Unlock()
+		return
+	}
+	p.last = now
+	p.mu.Unlock()
+	params := map[string]any{"progressToken": p.token, "progress": done}
+	if total > 0 {
+		params["total"] = total
+	}
+	// Progress is best effort; a client that went away will see the result
+	// or nothing at all
+	_ = p.srv.SendNotificationToClient(p.ctx, "notifications/progress", params)
+}
+
+// progressWriter counts and reports the bytes written through it. An HTTP
+// transport may still be reading a request body after the response
+// arrives, so the count is safe to read while writes continue.
+type progressWriter struct {
+	p     *progress
+	done  atomic.Int64
+	total int64
+}
+
+func (w *progressWriter) Write(b []byte) (int, error) {
+	w.p.report(w.done.Add(int64(len(b))), w.total, false)
+	return len(b), nil
+}
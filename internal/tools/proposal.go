@@ -0,0 +1,301 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/proposal"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/spf13/cast"
+)
+
+// proposedFileJSON is the JSON view of a staged file change.
+type proposedFileJSON struct {
+	Path    string `json:"path"`
+	NewFile bool   `json:"newFile"`
+	Diff    string `json:"diff"`
+}
+
+// proposalJSON is the JSON view of a proposal.
+type proposalJSON struct {
+	Token       string             `json:"token"`
+	Description string             `json:"description,omitempty"`
+	Expires     string             `json:"expires"`
+	Files       []proposedFileJSON `json:"files"`
+}
+
+// NewProposeChangesTool creates the propose_changes tool.
+func NewProposeChangesTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"propose_changes",
+		mcp.WithDescription("Stage a set of file writes and edits for review without touching disk. Returns unified diffs and an approval token; call approve_changes with the token to apply them or reject_changes to discard them."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithArray("changes", mcp.Description("Array of changes. Each has a path and either content (full replacement) or edits (oldText/newText operations as in edit_file)."), mcp.Required(), mcp.Items(map[string]any{"type": "object"})),
+		mcp.WithString("description", mcp.Description("Optional summary of the change set for reviewers")),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleProposeChanges handles the propose_changes tool.
+func HandleProposeChanges(ctx context.Context, reg *registry.Registry, store *proposal.Store, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	description := cast.ToString(request.Params.Arguments["description"])
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	changes, ok := request.Params.Arguments["changes"].([]interface{})
+	if !ok || len(changes) == 0 {
+		return mcp.NewToolResultError("changes parameter is required"), nil
+	}
+
+	var files []proposal.File
+	originals := make(map[string]string)
+	index := make(map[string]int)
+
+	for i, c := range changes {
+		change, ok := c.(map[string]interface{})
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("change %d: must be an object", i+1)), nil
+		}
+		path := cast.ToString(change["path"])
+		if path == "" {
+			return mcp.NewToolResultError(fmt.Sprintf("change %d: path is required", i+1)), nil
+		}
+		content, hasContent := change["content"]
+		_, hasEdits := change["edits"]
+		if hasContent == hasEdits {
+			return mcp.NewToolResultError(fmt.Sprintf("change %d: exactly one of content or edits is required", i+1)), nil
+		}
+
+		resolvedPath, err := validateFinal(reg, path)
+		if err != nil && os.IsNotExist(err) {
+			resolvedPath, err = reg.ValidateForCreation(path)
+		}
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("change %d: path validation failed: %w", i+1, err).Error()), nil
+		}
+
+		// Later changes to the same file build on earlier ones
+		pos, staged := index[resolvedPath]
+		if !staged {
+			file, original, err := loadProposalBase(reg, resolvedPath)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Errorf("change %d: %w", i+1, err).Error()), nil
+			}
+			files = append(files, file)
+			pos = len(files) - 1
+			index[resolvedPath] = pos
+			originals[resolvedPath] = original
+		}
+		file := &files[pos]
+
+		if hasContent {
+			file.Content = []byte(cast.ToString(content))
+		} else {
+			if !staged && file.BaseHash == "" {
+				return mcp.NewToolResultError(fmt.Sprintf("change %d: cannot edit %s: file does not exist", i+1, resolvedPath)), nil
+			}
+			newContent, err := applyEdits(string(file.Content), parseEdits(change["edits"]))
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Errorf("change %d: %w", i+1, err).Error()), nil
+			}
+			file.Content = []byte(newContent)
+		}
+	}
+
+	for i := range files {
+		files[i].Diff = generateUnifiedDiff(files[i].Path, originals[files[i].Path], string(files[i].Content))
+	}
+
+	p, err := store.Add(description, files)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to create proposal: %w", err).Error()), nil
+	}
+
+	if format == "json" {
+		out := proposalJSON{
+			Token:       p.Token,
+			Description: p.Description,
+			Expires:     p.Expires.Format(time.RFC3339),
+		}
+		for _, f := range p.Files {
+			out.Files = append(out.Files, proposedFileJSON{Path: f.Path, NewFile: f.BaseHash == "", Diff: f.Diff})
+		}
+		jsonResult, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(jsonResult)), nil
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "Proposal %s (%d file(s), expires %s)\n", p.Token, len(p.Files), p.Expires.Format(time.RFC3339))
+	if p.Description != "" {
+		fmt.Fprintf(&result, "%s\n", p.Description)
+	}
+	for _, f := range p.Files {
+		label := "MODIFIED"
+		if f.BaseHash == "" {
+			label = "ADDED"
+		}
+		fmt.Fprintf(&result, "\n[%s] %s\n%s\n", label, f.Path, f.Diff)
+	}
+	fmt.Fprintf(&result, "\nNo changes have been written. Call approve_changes with token %s to apply them, or reject_changes to discard them.", p.Token)
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// loadProposalBase reads the current state of resolvedPath as the starting
+// point for a proposed change. A missing file yields an empty base.
+func loadProposalBase(reg *registry.Registry, resolvedPath string) (proposal.File, string, error) {
+	file := proposal.File{Path: resolvedPath, Mode: reg.Modes().File}
+
+	source, err := readTarget(reg, resolvedPath)
+	if err == nil {
+		var info os.FileInfo
+		info, err = os.Stat(source)
+		if err == nil {
+			if info.IsDir() {
+				return file, "", fmt.Errorf("path is a directory: %s", resolvedPath)
+			}
+			file.Mode = info.Mode().Perm()
+		}
+	}
+	if err != nil {
+		if os.IsNotExist(err) {
+			return file, "", nil
+		}
+		return file, "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return file, "", fmt.Errorf("failed to read file: %w", err)
+	}
+	file.Content = data
+	file.BaseHash = proposal.HashContent(data)
+	return file, string(data), nil
+}
+
+// NewApproveChangesTool creates the approve_changes tool.
+func NewApproveChangesTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"approve_changes",
+		mcp.WithDescription("Apply a proposal created by propose_changes. Fails without writing anything if any file changed since it was proposed."),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Approve Changes",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(true),
+			IdempotentHint:  boolPtr(false),
+		}),
+		mcp.WithString("token", mcp.Description("Approval token returned by propose_changes"), mcp.Required()),
+		withConfirmationToken(),
+	)
+}
+
+// HandleApproveChanges handles the approve_changes tool.
+func HandleApproveChanges(ctx context.Context, reg *registry.Registry, store *proposal.Store, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	token := cast.ToString(request.Params.Arguments["token"])
+	if token == "" {
+		return mcp.NewToolResultError("token parameter is required"), nil
+	}
+
+	if result := requireConfirmation(reg, "approve_changes", request, fmt.Sprintf("apply proposal %s", token)); result != nil {
+		return result, nil
+	}
+
+	p, err := store.Take(token)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// Check every file before writing any of them
+	for _, f := range p.Files {
+		if err := checkProposalBase(reg, f); err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("proposal %s discarded: %w", p.Token, err).Error()), nil
+		}
+	}
+
+	var result strings.Builder
+	for i, f := range p.Files {
+		if err := writeProposedFile(reg, f); err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to write %s after applying %d of %d file(s): %w", f.Path, i, len(p.Files), err).Error()), nil
+		}
+		fmt.Fprintf(&result, "\n%s", f.Path)
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Applied proposal %s (%d file(s)):%s", p.Token, len(p.Files), result.String())), nil
+}
+
+// checkProposalBase verifies f's target still holds the content it was
+// proposed against.
+func checkProposalBase(reg *registry.Registry, f proposal.File) error {
+	source, err := readTarget(reg, f.Path)
+	var data []byte
+	if err == nil {
+		data, err = os.ReadFile(source)
+	}
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			if f.BaseHash != "" {
+				return fmt.Errorf("conflict: %s was removed since it was proposed", f.Path)
+			}
+			return nil
+		}
+		return fmt.Errorf("failed to read %s: %w", f.Path, err)
+	}
+	if f.BaseHash == "" {
+		return fmt.Errorf("conflict: %s was created since it was proposed", f.Path)
+	}
+	if proposal.HashContent(data) != f.BaseHash {
+		return fmt.Errorf("conflict: %s was modified since it was proposed", f.Path)
+	}
+	return nil
+}
+
+// writeProposedFile writes a staged file through the normal write path.
+func writeProposedFile(reg *registry.Registry, f proposal.File) error {
+	target, allowedDirs, err := writeTarget(reg, f.Path)
+	if err != nil {
+		return err
+	}
+	if reg.Overlay() == nil {
+		if err := safeMkdirAll(filepath.Dir(f.Path), reg.Modes().Dir, reg.Get()); err != nil {
+			return fmt.Errorf("failed to create directories: %w", err)
+		}
+	}
+	return atomicWriteFile(target, f.Content, f.Mode, allowedDirs)
+}
+
+// NewRejectChangesTool creates the reject_changes tool.
+func NewRejectChangesTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"reject_changes",
+		mcp.WithDescription("Discard a proposal created by propose_changes without applying it."),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithString("token", mcp.Description("Approval token returned by propose_changes"), mcp.Required()),
+	)
+}
+
+// HandleRejectChanges handles the reject_changes tool.
+func HandleRejectChanges(ctx context.Context, reg *registry.Registry, store *proposal.Store, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	token := cast.ToString(request.Params.Arguments["token"])
+	if token == "" {
+		return mcp.NewToolResultError("token parameter is required"), nil
+	}
+
+	p, err := store.Take(token)
+	if err != nil && !errors.Is(err, proposal.ErrExpired) {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if p == nil {
+		return mcp.NewToolResultText(fmt.Sprintf("Proposal %s had already expired", token)), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Rejected proposal %s (%d file(s) discarded)", p.Token, len(p.Files))), nil
+}
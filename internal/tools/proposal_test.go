@@ -0,0 +1,179 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/proposal"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+)
+
+// proposalHandler binds a proposal store to a proposal tool handler for callTool.
+func proposalHandler(store *proposal.Store, h func(context.Context, *registry.Registry, *proposal.Store, mcp.CallToolRequest) (*mcp.CallToolResult, error)) func(context.Context, *registry.Registry, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, reg *registry.Registry, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return h(ctx, reg, store, req)
+	}
+}
+
+// proposalToken extracts the approval token from propose_changes text output.
+func proposalToken(t *testing.T, text string) string {
+	t.Helper()
+	fields := strings.Fields(text)
+	if len(fields) < 2 || fields[0] != "Proposal" {
+		t.Fatalf("unexpected propose_changes output: %s", text)
+	}
+	return fields[1]
+}
+
+func TestProposeAndApproveChanges(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	store := proposal.NewStore(proposal.DefaultTTL)
+	existing := filepath.Join(tmpDir, "existing.txt")
+	if err := os.WriteFile(existing, []byte("one\ntwo\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	newFile := filepath.Join(tmpDir, "sub", "new.txt")
+
+	result := callTool(t, proposalHandler(store, HandleProposeChanges), reg, map[string]any{
+		"changes": []interface{}{
+			map[string]interface{}{"path": newFile, "content": "hello\n"},
+			map[string]interface{}{"path": existing, "edits": []interface{}{
+				map[string]interface{}{"oldText": "two", "newText": "2"},
+			}},
+		},
+	})
+	if result.IsError {
+		t.Fatalf("propose failed: %s", resultText(result))
+	}
+	text := resultText(result)
+	if !strings.Contains(text, "[ADDED] "+newFile) || !strings.Contains(text, "+2") {
+		t.Errorf("unexpected proposal output: %s", text)
+	}
+
+	if _, err := os.Stat(newFile); !os.IsNotExist(err) {
+		t.Error("propose_changes should not create files")
+	}
+	data, _ := os.ReadFile(existing)
+	if string(data) != "one\ntwo\n" {
+		t.Errorf("propose_changes should not modify files, got %q", string(data))
+	}
+
+	token := proposalToken(t, text)
+	result = callTool(t, proposalHandler(store, HandleApproveChanges), reg, map[string]any{"token": token})
+	if result.IsError {
+		t.Fatalf("approve failed: %s", resultText(result))
+	}
+
+	data, err := os.ReadFile(newFile)
+	if err != nil || string(data) != "hello\n" {
+		t.Errorf("expected new file content, got %q (%v)", string(data), err)
+	}
+	data, _ = os.ReadFile(existing)
+	if string(data) != "one\n2\n" {
+		t.Errorf("expected edited content, got %q", string(data))
+	}
+	info, _ := os.Stat(existing)
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected permissions to be preserved, got %v", info.Mode().Perm())
+	}
+
+	result = callTool(t, proposalHandler(store, HandleApproveChanges), reg, map[string]any{"token": token})
+	if !result.IsError {
+		t.Error("expected a token to be single-use")
+	}
+}
+
+func TestApproveChangesConflict(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	store := proposal.NewStore(proposal.DefaultTTL)
+	file := filepath.Join(tmpDir, "file.txt")
+	other := filepath.Join(tmpDir, "other.txt")
+	if err := os.WriteFile(file, []byte("base"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := callTool(t, proposalHandler(store, HandleProposeChanges), reg, map[string]any{
+		"changes": []interface{}{
+			map[string]interface{}{"path": other, "content": "other"},
+			map[string]interface{}{"path": file, "content": "proposed"},
+		},
+	})
+	if result.IsError {
+		t.Fatalf("propose failed: %s", resultText(result))
+	}
+	token := proposalToken(t, resultText(result))
+
+	if err := os.WriteFile(file, []byte("concurrent"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result = callTool(t, proposalHandler(store, HandleApproveChanges), reg, map[string]any{"token": token})
+	if !result.IsError || !strings.Contains(resultText(result), "modified since it was proposed") {
+		t.Fatalf("expected conflict error, got %s", resultText(result))
+	}
+	if _, err := os.Stat(other); !os.IsNotExist(err) {
+		t.Error("no file should be written when a conflict is detected")
+	}
+	data, _ := os.ReadFile(file)
+	if string(data) != "concurrent" {
+		t.Errorf("conflicting file should be untouched, got %q", string(data))
+	}
+}
+
+func TestProposeChangesValidation(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	store := proposal.NewStore(proposal.DefaultTTL)
+
+	tests := []struct {
+		name    string
+		changes []interface{}
+	}{
+		{"outside allowed", []interface{}{map[string]interface{}{"path": "/etc/passwd", "content": "x"}}},
+		{"edit missing file", []interface{}{map[string]interface{}{"path": filepath.Join(tmpDir, "missing.txt"), "edits": []interface{}{
+			map[string]interface{}{"oldText": "a", "newText": "b"},
+		}}}},
+		{"content and edits", []interface{}{map[string]interface{}{"path": filepath.Join(tmpDir, "a.txt"), "content": "x", "edits": []interface{}{}}}},
+		{"no changes", []interface{}{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := callTool(t, proposalHandler(store, HandleProposeChanges), reg, map[string]any{"changes": tt.changes})
+			if !result.IsError {
+				t.Errorf("expected error, got %s", resultText(result))
+			}
+		})
+	}
+
+	if got := len(store.List()); got != 0 {
+		t.Errorf("failed proposals should not be stored, got %d", got)
+	}
+}
+
+func TestRejectChanges(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	store := proposal.NewStore(proposal.DefaultTTL)
+	file := filepath.Join(tmpDir, "file.txt")
+
+	result := callTool(t, proposalHandler(store, HandleProposeChanges), reg, map[string]any{
+		"changes": []interface{}{map[string]interface{}{"path": file, "content": "x"}},
+	})
+	token := proposalToken(t, resultText(result))
+
+	result = callTool(t, proposalHandler(store, HandleRejectChanges), reg, map[string]any{"token": token})
+	if result.IsError {
+		t.Fatalf("reject failed: %s", resultText(result))
+	}
+
+	result = callTool(t, proposalHandler(store, HandleApproveChanges), reg, map[string]any{"token": token})
+	if !result.IsError {
+		t.Error("expected approve to fail after reject")
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Error("rejected proposal should not write files")
+	}
+}
@@ -3,12 +3,17 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"sync"
+	"time"
+	"unicode/utf8"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/mimetype"
 	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/shadow"
 	"github.com/portertech/filesystem-mcp-server/internal/stream"
 	"github.com/spf13/cast"
 )
@@ -32,6 +37,8 @@ func NewReadTextFileTool(reg *registry.Registry) mcp.Tool {
 		mcp.WithNumber("start_line", mcp.Description("Starting line number (1-based, inclusive)")),
 		mcp.WithNumber("end_line", mcp.Description("Ending line number (1-based, inclusive)")),
 		mcp.WithBoolean("line_numbers", mcp.Description("Prefix each line with its line number")),
+		mcp.WithBoolean("force", mcp.Description("Read the file as text even if it looks binary")),
+		withEncodingParam("Encoding of the file, decoded to UTF-8 with any byte order mark removed"),
 	)
 }
 
@@ -43,8 +50,16 @@ func HandleReadTextFile(ctx context.Context, reg *registry.Registry, request mcp
 	startLine := cast.ToInt(request.Params.Arguments["start_line"])
 	endLine := cast.ToInt(request.Params.Arguments["end_line"])
 	lineNumbers := cast.ToBool(request.Params.Arguments["line_numbers"])
+	force := cast.ToBool(request.Params.Arguments["force"])
+	encoding, err := parseTextEncoding(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	// A file read with an explicit encoding, even utf-8, is decoded whole
+	// so that its byte order mark is removed
+	_, decode := request.Params.Arguments["encoding"]
 
-	resolvedPath, err := reg.Validate(path)
+	resolvedPath, err := validateRead(reg, path)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
 	}
@@ -58,15 +73,50 @@ func HandleReadTextFile(ctx context.Context, reg *registry.Registry, request mcp
 		return mcp.NewToolResultError("path is a directory, not a file"), nil
 	}
 
+	match, err := checkReputation(reg, resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	// Validate parameter combinations
 	if (head > 0 || tail > 0) && (startLine > 0 || endLine > 0) {
 		return mcp.NewToolResultError("cannot use head/tail with start_line/end_line"), nil
 	}
 
-	var content string
+	// UTF-16 has NUL bytes and Latin-1 is not valid UTF-8, so a file read
+	// with an explicit encoding is not checked for being binary
+	if !force && encoding == "utf-8" {
+		notice, err := binaryNotice(path, resolvedPath, info.Size())
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to read file: %w", err).Error()), nil
+		}
+		if notice != "" {
+			return mcp.NewToolResultError(notice), nil
+		}
+	}
+
+	// Whole file reads hold the file in memory until the response is built.
+	// Files read with an encoding are always read whole to decode them.
+	if (head <= 0 && tail <= 0 && startLine <= 0 && endLine <= 0) || decode {
+		release, err := reserveMemory(ctx, reg, resolvedPath, info.Size())
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		defer release()
+	}
 
-	// Handle start_line/end_line range (most efficient for AI agents)
-	if startLine > 0 || endLine > 0 {
+	var content string
+	var version *shadow.Version
+
+	if decode {
+		var data []byte
+		data, version, err = readWholeFile(reg, resolvedPath)
+		if err == nil {
+			content, _, err = decodeText(data, encoding)
+			content = selectLines(content, head, tail, startLine, endLine, lineNumbers)
+		}
+	} else if startLine > 0 || endLine > 0 {
+		// Handle start_line/end_line range (most efficient for AI agents)
 		if startLine <= 0 {
 			startLine = 1
 		}
@@ -88,7 +138,7 @@ func HandleReadTextFile(ctx context.Context, reg *registry.Registry, request mcp
 			content, err = stream.TailFile(resolvedPath, tail)
 		} else {
 			var data []byte
-			data, err = os.ReadFile(resolvedPath)
+			data, version, err = readWholeFile(reg, resolvedPath)
 			content = string(data)
 		}
 	}
@@ -97,7 +147,7 @@ func HandleReadTextFile(ctx context.Context, reg *registry.Registry, request mcp
 		return mcp.NewToolResultError(fmt.Errorf("failed to read file: %w", err).Error()), nil
 	}
 
-	return mcp.NewToolResultText(content), nil
+	return withVersionNote(withReputationWarning(mcp.NewToolResultText(content), match), version), nil
 }
 
 // NewReadFileTool creates the read_file tool (deprecated alias for read_text_file).
@@ -107,14 +157,16 @@ func NewReadFileTool(reg *registry.Registry) mcp.Tool {
 		mcp.WithDescription("Read the contents of a file. Deprecated: use read_text_file instead."),
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithString("path", mcp.Description("Path to the file to read"), mcp.Required()),
+		mcp.WithBoolean("force", mcp.Description("Read the file as text even if it looks binary")),
 	)
 }
 
 // HandleReadFile handles the read_file tool.
 func HandleReadFile(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	path := cast.ToString(request.Params.Arguments["path"])
+	force := cast.ToBool(request.Params.Arguments["force"])
 
-	resolvedPath, err := reg.Validate(path)
+	resolvedPath, err := validateRead(reg, path)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
 	}
@@ -127,12 +179,33 @@ func HandleReadFile(ctx context.Context, reg *registry.Registry, request mcp.Cal
 		return mcp.NewToolResultError("path is a directory, not a file"), nil
 	}
 
-	data, err := os.ReadFile(resolvedPath)
+	match, err := checkReputation(reg, resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if !force {
+		notice, err := binaryNotice(path, resolvedPath, info.Size())
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to read file: %w", err).Error()), nil
+		}
+		if notice != "" {
+			return mcp.NewToolResultError(notice), nil
+		}
+	}
+
+	release, err := reserveMemory(ctx, reg, resolvedPath, info.Size())
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	defer release()
+
+	data, version, err := readWholeFile(reg, resolvedPath)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Errorf("failed to read file: %w", err).Error()), nil
 	}
 
-	return mcp.NewToolResultText(string(data)), nil
+	return withVersionNote(withReputationWarning(mcp.NewToolResultText(string(data)), match), version), nil
 }
 
 // NewReadMultipleFilesTool creates the read_multiple_files tool.
@@ -143,6 +216,7 @@ func NewReadMultipleFilesTool(reg *registry.Registry) mcp.Tool {
 		mcp.WithReadOnlyHintAnnotation(true),
 		mcp.WithArray("paths", mcp.Description("Array of file paths to read"), mcp.Required(), mcp.Items(map[string]any{"type": "string"})),
 		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+		mcp.WithBoolean("force", mcp.Description("Read files as text even if they look binary")),
 	)
 }
 
@@ -150,6 +224,7 @@ func NewReadMultipleFilesTool(reg *registry.Registry) mcp.Tool {
 func HandleReadMultipleFiles(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	pathsArg := request.Params.Arguments["paths"]
 	format := cast.ToString(request.Params.Arguments["format"])
+	force := cast.ToBool(request.Params.Arguments["force"])
 	var paths []string
 	if arr, ok := pathsArg.([]interface{}); ok {
 		for _, v := range arr {
@@ -164,10 +239,21 @@ func HandleReadMultipleFiles(ctx context.Context, reg *registry.Registry, reques
 	type fileResult struct {
 		path    string
 		content string
+		warning string
+		version *shadow.Version
 		err     error
 	}
 
 	results := make([]fileResult, len(paths))
+	// Reservations are held until the combined response is built
+	releases := make([]func(), len(paths))
+	defer func() {
+		for _, release := range releases {
+			if release != nil {
+				release()
+			}
+		}
+	}()
 	var wg sync.WaitGroup
 	sem := make(chan struct{}, maxConcurrentReads) // Limit concurrency to 10
 
@@ -180,7 +266,7 @@ func HandleReadMultipleFiles(ctx context.Context, reg *registry.Registry, reques
 
 			result := fileResult{path: p}
 
-			resolvedPath, err := reg.Validate(p)
+			resolvedPath, err := validateRead(reg, p)
 			if err != nil {
 				result.err = err
 				results[idx] = result
@@ -199,7 +285,34 @@ func HandleReadMultipleFiles(ctx context.Context, reg *registry.Registry, reques
 				return
 			}
 
-			data, err := os.ReadFile(resolvedPath)
+			match, err := checkReputation(reg, resolvedPath)
+			if err != nil {
+				result.err = err
+				results[idx] = result
+				return
+			}
+			result.warning = reputationWarning(match)
+
+			if !force {
+				notice, err := binaryNotice(p, resolvedPath, info.Size())
+				if err == nil && notice != "" {
+					err = errors.New(notice)
+				}
+				if err != nil {
+					result.err = err
+					results[idx] = result
+					return
+				}
+			}
+
+			releases[idx], err = reserveMemory(ctx, reg, resolvedPath, info.Size())
+			if err != nil {
+				result.err = err
+				results[idx] = result
+				return
+			}
+
+			data, version, err := readWholeFile(reg, resolvedPath)
 			if err != nil {
 				result.err = err
 				results[idx] = result
@@ -207,6 +320,7 @@ func HandleReadMultipleFiles(ctx context.Context, reg *registry.Registry, reques
 			}
 
 			result.content = string(data)
+			result.version = version
 			results[idx] = result
 		}(i, path)
 	}
@@ -215,9 +329,11 @@ func HandleReadMultipleFiles(ctx context.Context, reg *registry.Registry, reques
 
 	if format == "json" {
 		type fileEntry struct {
-			Path    string `json:"path"`
-			Content string `json:"content,omitempty"`
-			Error   string `json:"error,omitempty"`
+			Path    string          `json:"path"`
+			Content string          `json:"content,omitempty"`
+			Warning string          `json:"warning,omitempty"`
+			Version *shadow.Version `json:"version,omitempty"`
+			Error   string          `json:"error,omitempty"`
 		}
 
 		entries := make([]fileEntry, 0, len(results))
@@ -227,6 +343,8 @@ func HandleReadMultipleFiles(ctx context.Context, reg *registry.Registry, reques
 				entry.Error = r.err.Error()
 			} else {
 				entry.Content = r.content
+				entry.Warning = r.warning
+				entry.Version = r.version
 			}
 			entries = append(entries, entry)
 		}
@@ -245,6 +363,12 @@ func HandleReadMultipleFiles(ctx context.Context, reg *registry.Registry, reques
 		if r.err != nil {
 			output += fmt.Sprintf("Error: %v\n", r.err)
 		} else {
+			if r.warning != "" {
+				output += r.warning + "\n"
+			}
+			if note := versionNote(r.version); note != "" {
+				output += note + "\n"
+			}
 			output += r.content
 		}
 		output += "\n\n"
@@ -252,3 +376,80 @@ func HandleReadMultipleFiles(ctx context.Context, reg *registry.Registry, reques
 
 	return mcp.NewToolResultText(output), nil
 }
+
+// readWholeFile reads the file at path. With shadow reads on, a file being
+// written is served from its last committed version and the version served
+// is returned; otherwise the version is nil.
+func readWholeFile(reg *registry.Registry, path string) ([]byte, *shadow.Version, error) {
+	store := reg.Shadow()
+	if store == nil {
+		data, err := os.ReadFile(path)
+		return data, nil, err
+	}
+	data, version, err := store.Read(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, &version, nil
+}
+
+// binaryNotice checks the start of the file at resolvedPath and, if it
+// looks binary, returns a notice naming its size and detected type and
+// pointing at the tools that can read it. It returns "" for text files.
+func binaryNotice(path, resolvedPath string, size int64) (string, error) {
+	head, err := readHead(resolvedPath, binarySniffLen)
+	if err != nil {
+		return "", err
+	}
+	if !looksBinary(head) && isText(head, int64(len(head)) < size) {
+		return "", nil
+	}
+
+	kind := "binary data"
+	suggestion := "Use read_file_bytes to view it as a hex dump"
+	if mediaType := mimetype.Detect(head); mediaType != "" {
+		kind = mediaType
+		suggestion = "Use read_media_file to view it, or read_file_bytes for a hex dump"
+	}
+	return fmt.Sprintf("%s is a binary file (%s, %s), not text. %s, or pass force=true to read it as text anyway.", path, stream.FormatSize(size), kind, suggestion), nil
+}
+
+// isText reports whether data is valid UTF-8. When data is only the start
+// of the file, a rune cut off at its end is allowed.
+func isText(data []byte, truncated bool) bool {
+	if truncated {
+		for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+			if utf8.RuneStart(data[i]) {
+				if !utf8.FullRune(data[i:]) {
+					data = data[:i]
+				}
+				break
+			}
+		}
+	}
+	return utf8.Valid(data)
+}
+
+// versionNote describes a read that did not return the file's current
+// content, or returns "" for a current read.
+func versionNote(v *shadow.Version) string {
+	if v == nil {
+		return ""
+	}
+	switch v.Source {
+	case shadow.Shadow:
+		return fmt.Sprintf("NOTE: file is being written; served the last committed version (%d bytes, modified %s)", v.Size, v.ModTime.Format(time.RFC3339))
+	case shadow.Unstable:
+		return "WARNING: file changed while it was read and no committed version is available; content may be torn"
+	}
+	return ""
+}
+
+// withVersionNote appends the version note, if any, to result as a separate
+// content item.
+func withVersionNote(result *mcp.CallToolResult, v *shadow.Version) *mcp.CallToolResult {
+	if note := versionNote(v); note != "" {
+		result.Content = append(result.Content, mcp.NewTextContent(note))
+	}
+	return result
+}
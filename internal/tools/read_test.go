@@ -3,14 +3,17 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"log/slog"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/shadow"
 )
 
 func setupTestRegistry(t *testing.T) (*registry.Registry, string) {
@@ -20,6 +23,27 @@ func setupTestRegistry(t *testing.T) (*registry.Registry, string) {
 	return reg, tmpDir
 }
 
+func callTool(t *testing.T, handler func(context.Context, *registry.Registry, mcp.CallToolRequest) (*mcp.CallToolResult, error), reg *registry.Registry, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = args
+	result, err := handler(context.Background(), reg, request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result
+}
+
+func resultText(result *mcp.CallToolResult) string {
+	var text strings.Builder
+	for _, c := range result.Content {
+		if tc, ok := c.(mcp.TextContent); ok {
+			text.WriteString(tc.Text)
+		}
+	}
+	return text.String()
+}
+
 func TestHandleReadTextFile(t *testing.T) {
 	reg, tmpDir := setupTestRegistry(t)
 
@@ -358,3 +382,84 @@ func TestHandleReadTextFileFollowsSymlinksWithinAllowed(t *testing.T) {
 		t.Errorf("expected %q, got %q", expectedContent, textContent.Text)
 	}
 }
+
+func TestHandleReadMultipleFilesShadowVersion(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	reg.SetShadow(shadow.New(shadow.DefaultMaxBytes))
+
+	file := filepath.Join(tmpDir, "file.txt")
+	result := callTool(t, HandleWriteFile, reg, map[string]any{"path": file, "content": "committed"})
+	if result.IsError {
+		t.Fatalf("write failed: %s", resultText(result))
+	}
+
+	result = callTool(t, HandleReadMultipleFiles, reg, map[string]any{"paths": []interface{}{file}, "format": "json"})
+	var entries []struct {
+		Content string          `json:"content"`
+		Version *shadow.Version `json:"version"`
+	}
+	if err := json.Unmarshal([]byte(resultText(result)), &entries); err != nil {
+		t.Fatalf("expected valid json output: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Content != "committed" || entries[0].Version == nil || entries[0].Version.Source != shadow.Current {
+		t.Errorf("expected current version to be reported, got %s", resultText(result))
+	}
+
+	// A current read adds no note
+	result = callTool(t, HandleReadFile, reg, map[string]any{"path": file})
+	if len(result.Content) != 1 {
+		t.Errorf("expected only the file content, got %s", resultText(result))
+	}
+}
+
+func TestHandleReadTextFileBinary(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+
+	png := filepath.Join(tmpDir, "image.dat")
+	if err := os.WriteFile(png, []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	latin1 := filepath.Join(tmpDir, "latin1.txt")
+	if err := os.WriteFile(latin1, []byte("caf\xe9\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// A multibyte rune straddling the sniffed prefix is still text
+	utf8File := filepath.Join(tmpDir, "utf8.txt")
+	if err := os.WriteFile(utf8File, []byte(strings.Repeat("a", binarySniffLen-1)+"é"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := callTool(t, HandleReadTextFile, reg, map[string]any{"path": png, "head": 1})
+	if !result.IsError {
+		t.Fatal("expected binary file to be refused")
+	}
+	for _, want := range []string{"binary file", "image/png", "read_media_file", "read_file_bytes", "force=true"} {
+		if !strings.Contains(resultText(result), want) {
+			t.Errorf("expected %q in notice: %s", want, resultText(result))
+		}
+	}
+
+	result = callTool(t, HandleReadFile, reg, map[string]any{"path": latin1})
+	if !result.IsError || !strings.Contains(resultText(result), "binary data") || strings.Contains(resultText(result), "read_media_file") {
+		t.Errorf("expected invalid UTF-8 to be refused without a media suggestion: %s", resultText(result))
+	}
+
+	result = callTool(t, HandleReadTextFile, reg, map[string]any{"path": latin1, "force": true})
+	if result.IsError || resultText(result) != "caf\xe9\n" {
+		t.Errorf("expected force to read the file: %q", resultText(result))
+	}
+
+	result = callTool(t, HandleReadTextFile, reg, map[string]any{"path": utf8File})
+	if result.IsError {
+		t.Errorf("unexpected error for a truncated rune: %s", resultText(result))
+	}
+
+	result = callTool(t, HandleReadMultipleFiles, reg, map[string]any{"paths": []interface{}{png, utf8File}, "format": "json"})
+	var entries []map[string]any
+	if err := json.Unmarshal([]byte(resultText(result)), &entries); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(entries) != 2 || !strings.Contains(fmt.Sprint(entries[0]["error"]), "binary file") || entries[1]["content"] == nil {
+		t.Errorf("expected only the binary file to be flagged: %v", entries)
+	}
+}
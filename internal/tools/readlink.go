@@ -0,0 +1,87 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/security"
+	"github.com/spf13/cast"
+)
+
+// linkInfo is the result of read_link.
+type linkInfo struct {
+	Path   string `json:"path"`
+	Target string `json:"target"`
+	// Resolved is where the link finally leads, following every link on
+	// the way. It is only reported inside allowed directories.
+	Resolved      string `json:"resolved,omitempty"`
+	Exists        bool   `json:"exists"`
+	InsideAllowed bool   `json:"insideAllowed"`
+	Error         string `json:"error,omitempty"`
+}
+
+// NewReadLinkTool creates the read_link tool.
+func NewReadLinkTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"read_link",
+		mcp.WithDescription("Show what a symbolic link points to: its raw target, whether the target exists, and whether it resolves inside the allowed directories. The link itself is inspected, never followed, so this works on links other tools refuse."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path", mcp.Description("Path to the symbolic link"), mcp.Required()),
+	)
+}
+
+// HandleReadLink handles the read_link tool.
+func HandleReadLink(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+
+	// Validate the link's location without following the link itself
+	resolvedPath, err := reg.ValidateForCreation(path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	linkPath, err := readTarget(reg, resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+
+	info, err := os.Lstat(linkPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to stat path: %w", err).Error()), nil
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("%s is not a symbolic link", resolvedPath)), nil
+	}
+	target, err := os.Readlink(linkPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to read link: %w", err).Error()), nil
+	}
+
+	result := linkInfo{Path: resolvedPath, Target: target}
+	resolved, err := filepath.EvalSymlinks(linkPath)
+	if err != nil {
+		// Dangling or looping: judge the target where it would be
+		lexical := target
+		if !filepath.IsAbs(lexical) {
+			lexical = filepath.Join(filepath.Dir(linkPath), lexical)
+		}
+		result.InsideAllowed = security.IsPathWithinAllowedDirectories(filepath.Clean(lexical), reg.GetResolved())
+		result.Error = err.Error()
+	} else {
+		result.Exists = true
+		result.InsideAllowed = security.IsPathWithinAllowedDirectories(resolved, reg.GetResolved())
+		if result.InsideAllowed {
+			result.Resolved = resolved
+		}
+	}
+
+	jsonResult, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+	}
+	return mcp.NewToolResultText(string(jsonResult)), nil
+}
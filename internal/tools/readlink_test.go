@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHandleReadLink(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "real.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	links := map[string]string{
+		"inside":   "real.txt",
+		"outside":  outside,
+		"dangling": "missing.txt",
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(tmpDir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		exists        bool
+		insideAllowed bool
+		resolved      bool
+	}{
+		{"inside", true, true, true},
+		{"outside", true, false, false},
+		{"dangling", false, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := callTool(t, HandleReadLink, reg, map[string]any{"path": filepath.Join(tmpDir, tt.name)})
+			if result.IsError {
+				t.Fatalf("read_link failed: %s", resultText(result))
+			}
+			var info linkInfo
+			if err := json.Unmarshal([]byte(resultText(result)), &info); err != nil {
+				t.Fatal(err)
+			}
+			if info.Target != links[tt.name] {
+				t.Errorf("expected target %q, got %q", links[tt.name], info.Target)
+			}
+			if info.Exists != tt.exists || info.InsideAllowed != tt.insideAllowed || (info.Resolved != "") != tt.resolved {
+				t.Errorf("unexpected result: %+v", info)
+			}
+		})
+	}
+
+	result := callTool(t, HandleReadLink, reg, map[string]any{"path": filepath.Join(tmpDir, "real.txt")})
+	if !result.IsError {
+		t.Error("expected an error for a regular file")
+	}
+}
@@ -0,0 +1,173 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/security"
+	"github.com/spf13/cast"
+)
+
+// backupSuffix names the directory replace_directory moves the previous
+// version of a destination to.
+const backupSuffix = ".old"
+
+// NewReplaceDirectoryTool creates the replace_directory tool.
+func NewReplaceDirectoryTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"replace_directory",
+		mcp.WithDescription("Swap a staged directory into place, such as a generated site or config bundle. The existing destination is renamed to destination.old, the source is renamed to the destination, and the old version is then removed. If the source cannot be moved into place, the old version is moved back. Both paths must be on the same filesystem."),
+		mcp.WithString("source", mcp.Description("Path to the staged directory"), mcp.Required()),
+		mcp.WithString("destination", mcp.Description("Path to the directory to replace; created if it does not exist"), mcp.Required()),
+		mcp.WithBoolean("keepOld", mcp.Description("Keep the previous version at destination.old instead of removing it (default: false)")),
+		withForce(),
+		withConfirmationToken(),
+		withApprovalToken(),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Replace Directory",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(true),
+			IdempotentHint:  boolPtr(false),
+		}),
+	)
+}
+
+// HandleReplaceDirectory handles the replace_directory tool.
+func HandleReplaceDirectory(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	source := cast.ToString(request.Params.Arguments["source"])
+	destination := cast.ToString(request.Params.Arguments["destination"])
+	keepOld := cast.ToBool(request.Params.Arguments["keepOld"])
+	force := cast.ToBool(request.Params.Arguments["force"])
+
+	if reg.Overlay() != nil {
+		return mcp.NewToolResultError("replacing directories is not supported in overlay mode"), nil
+	}
+
+	resolvedSrc, err := validateFinal(reg, source)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return mcp.NewToolResultError("source does not exist"), nil
+		}
+		return mcp.NewToolResultError(fmt.Errorf("source path validation failed: %w", err).Error()), nil
+	}
+	if info, err := os.Stat(resolvedSrc); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to stat source: %w", err).Error()), nil
+	} else if !info.IsDir() {
+		return mcp.NewToolResultError("source is not a directory"), nil
+	}
+
+	resolvedDst, err := reg.ValidateForCreation(destination)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("destination path validation failed: %w", err).Error()), nil
+	}
+	if err := security.ValidateNoSymlinksInPath(reg.ExpandPath(destination), reg.Get()); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("destination path validation failed: %w", err).Error()), nil
+	}
+	exists := false
+	if info, err := os.Lstat(resolvedDst); err == nil {
+		if !info.IsDir() {
+			return mcp.NewToolResultError("destination exists and is not a directory"), nil
+		}
+		exists = true
+	} else if !os.IsNotExist(err) {
+		return mcp.NewToolResultError(fmt.Errorf("failed to stat destination: %w", err).Error()), nil
+	}
+
+	allowedDirs := reg.Get()
+	switch {
+	case security.IsPathWithinAllowedDirectories(resolvedDst, []string{resolvedSrc}) ||
+		security.IsPathWithinAllowedDirectories(resolvedSrc, []string{resolvedDst}):
+		return mcp.NewToolResultError("source and destination must not contain each other"), nil
+	case isAllowedRoot(resolvedSrc, allowedDirs):
+		return mcp.NewToolResultError("cannot move an allowed root directory"), nil
+	}
+	for _, p := range []string{resolvedSrc, resolvedDst} {
+		if inTrash(reg, p) || inJournal(reg, p) {
+			return mcp.NewToolResultError(fmt.Sprintf("%s is in the trash or the undo journal", p)), nil
+		}
+	}
+
+	backup := resolvedDst + backupSuffix
+	var size int64
+	if exists {
+		if err := checkDirectoryRemovable(resolvedDst, allowedDirs); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if _, err := os.Lstat(backup); err == nil {
+			return mcp.NewToolResultError(fmt.Sprintf("%s already exists; remove it before replacing %s", backup, resolvedDst)), nil
+		}
+		if !keepOld {
+			if err := checkTreeDeleteLimits(reg, resolvedDst, force); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if reg.Trash() != nil {
+				if _, size, err = measureTree(reg, resolvedDst); err != nil {
+					return mcp.NewToolResultError(fmt.Errorf("failed to measure directory: %w", err).Error()), nil
+				}
+			}
+		}
+
+		action := fmt.Sprintf("replace %s with %s", resolvedDst, resolvedSrc)
+		if isProtected(reg, resolvedSrc, resolvedDst) {
+			if result := requireApproval(reg, "replace_directory", request, action, resolvedSrc, resolvedDst); result != nil {
+				return result, nil
+			}
+		} else if result := requireConfirmation(reg, "replace_directory", request, action); result != nil {
+			return result, nil
+		}
+	} else if result := requireApproval(reg, "replace_directory", request, fmt.Sprintf("move %s to %s", resolvedSrc, resolvedDst), resolvedSrc, resolvedDst); result != nil {
+		return result, nil
+	}
+	for _, p := range []string{resolvedSrc, resolvedDst} {
+		if err := reg.CheckWrite(p); err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to replace directory: %w", err).Error()), nil
+		}
+	}
+
+	if err := swapDirectory(resolvedSrc, resolvedDst, backup, exists); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	text := fmt.Sprintf("Successfully replaced %s with %s", resolvedDst, resolvedSrc)
+	switch t := reg.Trash(); {
+	case !exists:
+		text = fmt.Sprintf("Successfully moved %s to %s", resolvedSrc, resolvedDst)
+	case keepOld:
+		text += fmt.Sprintf("; the previous version is at %s", backup)
+	case t != nil:
+		e, err := t.PutAs(containingRoot(reg, backup), backup, resolvedDst, true, size)
+		if err != nil {
+			text += fmt.Sprintf("; failed to move the previous version at %s to the trash: %v", backup, err)
+		} else {
+			text += fmt.Sprintf("; moved the previous version to the trash as %s", e.ID)
+		}
+	default:
+		if err := os.RemoveAll(backup); err != nil {
+			text += fmt.Sprintf("; failed to remove the previous version at %s: %v", backup, err)
+		}
+	}
+	return mcp.NewToolResultText(text), nil
+}
+
+// swapDirectory moves the directory at src to dst. If dst exists, it is
+// moved to backup first, and moved back if src cannot take its place.
+func swapDirectory(src, dst, backup string, exists bool) error {
+	if exists {
+		if err := os.Rename(dst, backup); err != nil {
+			return fmt.Errorf("failed to move %s aside: %w", dst, err)
+		}
+	}
+	if err := os.Rename(src, dst); err != nil {
+		if !exists {
+			return fmt.Errorf("failed to move %s into place: %w", src, err)
+		}
+		if rerr := os.Rename(backup, dst); rerr != nil {
+			return fmt.Errorf("failed to move %s into place: %w; restoring the previous version also failed (%v), it is at %s", src, err, rerr, backup)
+		}
+		return fmt.Errorf("failed to move %s into place, restored the previous version: %w", src, err)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/portertech/filesystem-mcp-server/internal/retention"
+	"github.com/portertech/filesystem-mcp-server/internal/trash"
+)
+
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestHandleReplaceDirectory(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	site := filepath.Join(tmpDir, "site")
+	staged := filepath.Join(tmpDir, "site.new")
+	writeTree(t, site, map[string]string{"index.html": "v1", "old.html": "gone"})
+	writeTree(t, staged, map[string]string{"index.html": "v2", "assets/app.js": "js"})
+
+	result := callTool(t, HandleReplaceDirectory, reg, map[string]any{"source": staged, "destination": site})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if data, _ := os.ReadFile(filepath.Join(site, "index.html")); string(data) != "v2" {
+		t.Errorf("index.html = %q, want v2", data)
+	}
+	for _, p := range []string{filepath.Join(site, "old.html"), staged, site + ".old"} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be gone", p)
+		}
+	}
+
+	// keepOld leaves the previous version beside the new one, and a second
+	// replace refuses to overwrite it
+	writeTree(t, staged, map[string]string{"index.html": "v3"})
+	result = callTool(t, HandleReplaceDirectory, reg, map[string]any{"source": staged, "destination": site, "keepOld": true})
+	if result.IsError || !strings.Contains(resultText(result), site+".old") {
+		t.Fatalf("unexpected result: %s", resultText(result))
+	}
+	if data, _ := os.ReadFile(filepath.Join(site+".old", "index.html")); string(data) != "v2" {
+		t.Errorf("kept index.html = %q, want v2", data)
+	}
+	writeTree(t, staged, map[string]string{"index.html": "v4"})
+	if result := callTool(t, HandleReplaceDirectory, reg, map[string]any{"source": staged, "destination": site}); !result.IsError {
+		t.Error("expected an existing backup to be refused")
+	}
+
+	// A missing destination is created
+	fresh := filepath.Join(tmpDir, "fresh")
+	if result := callTool(t, HandleReplaceDirectory, reg, map[string]any{"source": staged, "destination": fresh}); result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if data, _ := os.ReadFile(filepath.Join(fresh, "index.html")); string(data) != "v4" {
+		t.Errorf("index.html = %q, want v4", data)
+	}
+
+	file := filepath.Join(tmpDir, "file.txt")
+	writeTree(t, tmpDir, map[string]string{"file.txt": "x"})
+	for _, args := range []map[string]any{
+		{"source": file, "destination": site},
+		{"source": fresh, "destination": file},
+		{"source": fresh, "destination": filepath.Join(fresh, "inner")},
+		{"source": fresh, "destination": tmpDir},
+	} {
+		if result := callTool(t, HandleReplaceDirectory, reg, args); !result.IsError {
+			t.Errorf("expected error for %v", args)
+		}
+	}
+}
+
+func TestReplaceDirectoryToTrash(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	reg.SetTrash(trash.New(retention.Policy{}, nil))
+	site := filepath.Join(tmpDir, "site")
+	staged := filepath.Join(tmpDir, "staged")
+	writeTree(t, site, map[string]string{"index.html": "v1"})
+	writeTree(t, staged, map[string]string{"index.html": "v2"})
+
+	result := callTool(t, HandleReplaceDirectory, reg, map[string]any{"source": staged, "destination": site})
+	if result.IsError || !strings.Contains(resultText(result), "to the trash") {
+		t.Fatalf("unexpected result: %s", resultText(result))
+	}
+	if _, err := os.Stat(site + ".old"); !os.IsNotExist(err) {
+		t.Error("expected the previous version to be moved to the trash")
+	}
+	if entries, err := reg.Trash().List(reg.GetResolved()); err != nil || len(entries) != 1 || entries[0].Path != site {
+		t.Errorf("expected the trash entry to record %s, got %v, %v", site, entries, err)
+	}
+}
+
+func TestSwapDirectoryRollback(t *testing.T) {
+	tmpDir := t.TempDir()
+	dst := filepath.Join(tmpDir, "site")
+	writeTree(t, dst, map[string]string{"index.html": "v1"})
+
+	err := swapDirectory(filepath.Join(tmpDir, "missing"), dst, dst+".old", true)
+	if err == nil || !strings.Contains(err.Error(), "restored the previous version") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dst, "index.html")); string(data) != "v1" {
+		t.Errorf("index.html = %q after rollback, want v1", data)
+	}
+	if _, err := os.Stat(dst + ".old"); !os.IsNotExist(err) {
+		t.Error("expected the backup to be moved back")
+	}
+}
@@ -0,0 +1,114 @@
+package tools
+
+import (
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/spf13/cast"
+)
+
+// pathArguments are the tool arguments holding a single path.
+var pathArguments = []string{"path", "source", "destination", "trashDir", "outputDir"}
+
+// rootDescription documents the root parameter added to every tool that
+// takes paths.
+const rootDescription = "Allowed directory that relative paths in this call are resolved against: its index in list_allowed_directories, its alias, or its path. When set, all paths must be relative."
+
+// WithRootParameter adds the optional root parameter to a tool that takes
+// paths. It reports false, leaving the tool unchanged, for tools without
+// path arguments or with a root parameter of their own.
+func WithRootParameter(tool mcp.Tool) (mcp.Tool, bool) {
+	props := tool.InputSchema.Properties
+	if _, ok := props["root"]; ok {
+		return tool, false
+	}
+	takesPaths := false
+	for _, name := range append(pathArguments, "paths", "changes") {
+		if _, ok := props[name]; ok {
+			takesPaths = true
+			break
+		}
+	}
+	if !takesPaths {
+		return tool, false
+	}
+
+	withRoot := make(map[string]any, len(props)+1)
+	for k, v := range props {
+		withRoot[k] = v
+	}
+	withRoot["root"] = map[string]any{"type": "string", "description": rootDescription}
+	tool.InputSchema.Properties = withRoot
+	return tool, true
+}
+
+// ApplyRoot rewrites the path arguments of request relative to the allowed
+// directory named by its root argument, if any. The rewritten paths are
+// validated by the tool handler as usual.
+func ApplyRoot(reg *registry.Registry, request *mcp.CallToolRequest) error {
+	root := cast.ToString(request.Params.Arguments["root"])
+	if root == "" {
+		return nil
+	}
+
+	args := make(map[string]interface{}, len(request.Params.Arguments))
+	for k, v := range request.Params.Arguments {
+		args[k] = v
+	}
+	resolve := func(v interface{}) (string, error) {
+		return reg.ResolveRelative(root, cast.ToString(v))
+	}
+
+	for _, name := range pathArguments {
+		v, ok := args[name]
+		if !ok {
+			continue
+		}
+		resolved, err := resolve(v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		args[name] = resolved
+	}
+
+	if paths, ok := args["paths"].([]interface{}); ok {
+		resolvedPaths := make([]interface{}, len(paths))
+		for i, p := range paths {
+			resolved, err := resolve(p)
+			if err != nil {
+				return fmt.Errorf("paths: %w", err)
+			}
+			resolvedPaths[i] = resolved
+		}
+		args["paths"] = resolvedPaths
+	}
+
+	// propose_changes takes paths inside its change objects
+	if changes, ok := args["changes"].([]interface{}); ok {
+		resolvedChanges := make([]interface{}, len(changes))
+		for i, c := range changes {
+			change, ok := c.(map[string]interface{})
+			if !ok {
+				resolvedChanges[i] = c
+				continue
+			}
+			resolvedChange := make(map[string]interface{}, len(change))
+			for k, v := range change {
+				resolvedChange[k] = v
+			}
+			if p, ok := change["path"]; ok {
+				resolved, err := resolve(p)
+				if err != nil {
+					return fmt.Errorf("changes: %w", err)
+				}
+				resolvedChange["path"] = resolved
+			}
+			resolvedChanges[i] = resolvedChange
+		}
+		args["changes"] = resolvedChanges
+	}
+
+	request.Params.Arguments = args
+	return nil
+}
@@ -0,0 +1,156 @@
+package tools
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+)
+
+func TestWithRootParameter(t *testing.T) {
+	reg, _ := setupTestRegistry(t)
+
+	tests := []struct {
+		name     string
+		tool     mcp.Tool
+		expected bool
+	}{
+		{"path tool", NewReadTextFileTool(reg), true},
+		{"source and destination", NewMoveFileTool(reg), true},
+		{"changes", NewProposeChangesTool(reg), true},
+		{"no paths", NewListAllowedDirectoriesTool(reg), false},
+		{"own root", NewGetUsageTrendTool(reg), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool, ok := WithRootParameter(tt.tool)
+			if ok != tt.expected {
+				t.Fatalf("WithRootParameter() = %v, want %v", ok, tt.expected)
+			}
+			if _, has := tool.InputSchema.Properties["root"]; ok && !has {
+				t.Error("root parameter not added")
+			}
+		})
+	}
+}
+
+func TestApplyRoot(t *testing.T) {
+	tmpDir := t.TempDir()
+	first := filepath.Join(tmpDir, "first")
+	second := filepath.Join(tmpDir, "second")
+	for _, d := range []string{first, second} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(second, "notes.txt"), []byte("second root"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	reg := registry.New([]string{first, second}, logger)
+	if err := reg.SetAliases(map[string]string{"docs": second}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    map[string]any
+		want    map[string]any
+		wantErr string
+	}{
+		{
+			name: "no root",
+			args: map[string]any{"path": "/abs/notes.txt"},
+			want: map[string]any{"path": "/abs/notes.txt"},
+		},
+		{
+			name: "index",
+			args: map[string]any{"root": "1", "path": "notes.txt"},
+			want: map[string]any{"path": filepath.Join(second, "notes.txt")},
+		},
+		{
+			name: "alias",
+			args: map[string]any{"root": "docs", "source": "a.txt", "destination": "sub/b.txt"},
+			want: map[string]any{"source": filepath.Join(second, "a.txt"), "destination": filepath.Join(second, "sub", "b.txt")},
+		},
+		{
+			name: "path list and changes",
+			args: map[string]any{
+				"root":    "0",
+				"paths":   []interface{}{"a", "b"},
+				"changes": []interface{}{map[string]interface{}{"path": "c", "content": "x"}},
+			},
+			want: map[string]any{"paths": []interface{}{filepath.Join(first, "a"), filepath.Join(first, "b")}},
+		},
+		{
+			name:    "absolute path",
+			args:    map[string]any{"root": "0", "path": "/etc/passwd"},
+			wantErr: "must be relative",
+		},
+		{
+			name:    "unknown root",
+			args:    map[string]any{"root": "7", "path": "a"},
+			wantErr: "out of range",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Arguments = tt.args
+			err := ApplyRoot(reg, &request)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for k, v := range tt.want {
+				if got := request.Params.Arguments[k]; !equalArg(got, v) {
+					t.Errorf("%s = %v, want %v", k, got, v)
+				}
+			}
+			if changes, ok := request.Params.Arguments["changes"].([]interface{}); ok {
+				if got := changes[0].(map[string]interface{})["path"]; got != filepath.Join(first, "c") {
+					t.Errorf("change path = %v", got)
+				}
+			}
+		})
+	}
+
+	// The rewritten path is served by the handler as usual
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"root": "docs", "path": "notes.txt"}
+	if err := ApplyRoot(reg, &request); err != nil {
+		t.Fatal(err)
+	}
+	result := callTool(t, HandleReadTextFile, reg, request.Params.Arguments)
+	if result.IsError || resultText(result) != "second root" {
+		t.Errorf("unexpected result: %s", resultText(result))
+	}
+}
+
+func equalArg(a, b any) bool {
+	as, aok := a.([]interface{})
+	bs, bok := b.([]interface{})
+	if aok && bok {
+		if len(as) != len(bs) {
+			return false
+		}
+		for i := range as {
+			if as[i] != bs[i] {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
@@ -0,0 +1,543 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gobwas/glob"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/savedsearch"
+	"github.com/spf13/cast"
+)
+
+const (
+	defaultSearchResults = 100
+	maxSearchResults     = 1000
+	// maxMatchLineLength is where matched lines are cut off in results, in
+	// characters.
+	maxMatchLineLength = 200
+	// maxScanLineLength is the longest line scanned for content matches.
+	maxScanLineLength = 1024 * 1024
+	// maxContextLines caps the context lines shown around each match.
+	maxContextLines = 20
+)
+
+// searchMatch is one result of a saved search: a file, or a matching line
+// when the search has a content pattern.
+type searchMatch struct {
+	Path string `json:"path"`
+	Line int    `json:"line,omitempty"`
+	Text string `json:"text,omitempty"`
+	// Before and After are the context lines around a matching line. Lines
+	// already shown with the previous match are not repeated.
+	Before []contextLine `json:"before,omitempty"`
+	After  []contextLine `json:"after,omitempty"`
+}
+
+// contextLine is a line shown around a match.
+type contextLine struct {
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// savedSearchResult is the JSON result of run_saved_search.
+type savedSearchResult struct {
+	Search    savedsearch.Search `json:"search"`
+	Matches   []searchMatch      `json:"matches"`
+	Truncated bool               `json:"truncated"`
+	// FileLimitReached is set when the search stopped at maxFiles.
+	FileLimitReached bool `json:"fileLimitReached,omitempty"`
+	// Errors lists the paths skipped because they could not be read.
+	Errors        []walkError `json:"errors,omitempty"`
+	OmittedErrors int         `json:"omittedErrors,omitempty"`
+}
+
+// compiledSearch is a saved search ready to run.
+type compiledSearch struct {
+	matchGlobs   []glob.Glob
+	excludeGlobs []glob.Glob
+	content      *regexp.Regexp
+	// before and after are the context lines to include around matches.
+	before, after int
+}
+
+// compileSearch compiles the patterns of search, reporting the first that is
+// invalid.
+func compileSearch(search savedsearch.Search) (*compiledSearch, error) {
+	c := &compiledSearch{}
+	for _, p := range search.Patterns {
+		globs, err := compileGlobs(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %v", p, err)
+		}
+		c.matchGlobs = append(c.matchGlobs, globs...)
+	}
+	for _, p := range search.ExcludePatterns {
+		globs, err := compileGlobs(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern %q: %v", p, err)
+		}
+		c.excludeGlobs = append(c.excludeGlobs, globs...)
+	}
+	if search.ContentPattern != "" {
+		expr := search.ContentPattern
+		if search.IgnoreCase {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid content pattern %q: %v", search.ContentPattern, err)
+		}
+		c.content = re
+	}
+	return c, nil
+}
+
+// NewSaveSearchTool creates the save_search tool.
+func NewSaveSearchTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"save_search",
+		mcp.WithDescription("Save a search definition under a name so it can be re-run with run_saved_search. A search selects files under a directory by glob, and optionally lines within them by regular expression. Saving an existing name replaces it."),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Save Search",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  boolPtr(true),
+		}),
+		mcp.WithString("name", mcp.Description("Name to save the search under"), mcp.Required()),
+		mcp.WithString("path", mcp.Description("Directory to search"), mcp.Required()),
+		mcp.WithArray("patterns", mcp.Description("Glob patterns selecting files, relative to path (default: all files)"), mcp.Items(map[string]any{"type": "string"})),
+		mcp.WithArray("excludePatterns", mcp.Description("Glob patterns to exclude"), mcp.Items(map[string]any{"type": "string"})),
+		mcp.WithString("contentPattern", mcp.Description("Regular expression to match against file lines; without it, matching files are listed")),
+		mcp.WithBoolean("ignoreCase", mcp.Description("If true, match contentPattern case-insensitively")),
+	)
+}
+
+// HandleSaveSearch handles the save_search tool.
+func HandleSaveSearch(ctx context.Context, reg *registry.Registry, store *savedsearch.Store, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	search := savedsearch.Search{
+		Name:           cast.ToString(request.Params.Arguments["name"]),
+		ContentPattern: cast.ToString(request.Params.Arguments["contentPattern"]),
+		IgnoreCase:     cast.ToBool(request.Params.Arguments["ignoreCase"]),
+	}
+	if patternsArg, ok := request.Params.Arguments["patterns"].([]interface{}); ok {
+		for _, p := range patternsArg {
+			search.Patterns = append(search.Patterns, cast.ToString(p))
+		}
+	}
+	if patternsArg, ok := request.Params.Arguments["excludePatterns"].([]interface{}); ok {
+		for _, p := range patternsArg {
+			search.ExcludePatterns = append(search.ExcludePatterns, cast.ToString(p))
+		}
+	}
+
+	if _, err := compileSearch(search); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	resolvedPath, err := reg.Validate(cast.ToString(request.Params.Arguments["path"]))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	info, err := statTarget(reg, resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to stat path: %w", err).Error()), nil
+	}
+	if !info.IsDir() {
+		return mcp.NewToolResultError("path is not a directory"), nil
+	}
+	search.Path = resolvedPath
+
+	saved, err := store.Save(search)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to save search: %w", err).Error()), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Saved search %q\n%s", saved.Name, formatSearch(saved))), nil
+}
+
+// NewRunSavedSearchTool creates the run_saved_search tool.
+func NewRunSavedSearchTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"run_saved_search",
+		mcp.WithDescription("Run a search saved with save_search. Returns matching lines as path:line: text, with optional context lines as path-line- text, when the search has a content pattern, and matching file paths otherwise. Binary files and symlinks are skipped."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("name", mcp.Description("Name of the saved search"), mcp.Required()),
+		mcp.WithNumber("maxResults", mcp.Description(fmt.Sprintf("Maximum number of results (default: %d, max: %d)", defaultSearchResults, maxSearchResults))),
+		withMaxFilesParam(),
+		withContextParameters(),
+		mcp.WithString("format", mcp.Description("Output format: 'text', 'json', or 'markdown'")),
+	)
+}
+
+// HandleRunSavedSearch handles the run_saved_search tool.
+func HandleRunSavedSearch(ctx context.Context, reg *registry.Registry, store *savedsearch.Store, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name := cast.ToString(request.Params.Arguments["name"])
+	maxResults := cast.ToInt(request.Params.Arguments["maxResults"])
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	if maxResults <= 0 {
+		maxResults = defaultSearchResults
+	}
+	if maxResults > maxSearchResults {
+		maxResults = maxSearchResults
+	}
+	budget, err := parseMaxFiles(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	search, err := store.Get(name)
+	if err != nil {
+		if errors.Is(err, savedsearch.ErrNotFound) {
+			return mcp.NewToolResultError(fmt.Sprintf("no saved search named %q", name)), nil
+		}
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	compiled, err := compileSearch(search)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	compiled.before, compiled.after = parseContextLines(request)
+
+	// Allowed directories may have changed since the search was saved
+	resolvedPath, err := reg.Validate(search.Path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+
+	var walkErrs walkErrors
+	matches, truncated, err := runSearch(ctx, reg, resolvedPath, compiled, maxResults, budget, &walkErrs)
+	if err != nil && !errors.Is(err, errMaxFiles) {
+		return mcp.NewToolResultError(fmt.Errorf("search failed: %w", err).Error()), nil
+	}
+
+	if format == "json" {
+		jsonResult, err := json.MarshalIndent(savedSearchResult{
+			Search:           search,
+			Matches:          matches,
+			Truncated:        truncated,
+			FileLimitReached: budget.exhausted,
+			Errors:           walkErrs.Errors,
+			OmittedErrors:    walkErrs.Omitted,
+		}, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(jsonResult)), nil
+	}
+
+	if len(matches) == 0 {
+		return withWalkErrors(withBudgetNote(mcp.NewToolResultText("No matches found"), budget), &walkErrs), nil
+	}
+
+	var result strings.Builder
+	if format == "markdown" {
+		result.WriteString(markdownMatches(matches))
+	} else {
+		result.WriteString(formatMatches(matches, compiled.before > 0 || compiled.after > 0))
+	}
+	if truncated {
+		fmt.Fprintf(&result, "\nStopped after %d results; raise maxResults to see more.\n", maxResults)
+	}
+
+	return withWalkErrors(withBudgetNote(mcp.NewToolResultText(result.String()), budget), &walkErrs), nil
+}
+
+// NewListSavedSearchesTool creates the list_saved_searches tool.
+func NewListSavedSearchesTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"list_saved_searches",
+		mcp.WithDescription("List the searches saved with save_search."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleListSavedSearches handles the list_saved_searches tool.
+func HandleListSavedSearches(ctx context.Context, reg *registry.Registry, store *savedsearch.Store, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	format := cast.ToString(request.Params.Arguments["format"])
+	searches := store.List()
+
+	if format == "json" {
+		jsonResult, err := json.MarshalIndent(searches, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(jsonResult)), nil
+	}
+
+	if len(searches) == 0 {
+		return mcp.NewToolResultText("No saved searches"), nil
+	}
+
+	var result strings.Builder
+	for i, s := range searches {
+		if i > 0 {
+			result.WriteString("\n")
+		}
+		fmt.Fprintf(&result, "%s\n%s", s.Name, formatSearch(s))
+	}
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// NewDeleteSavedSearchTool creates the delete_saved_search tool.
+func NewDeleteSavedSearchTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"delete_saved_search",
+		mcp.WithDescription("Delete a search saved with save_search."),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Delete Saved Search",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(true),
+			IdempotentHint:  boolPtr(true),
+		}),
+		mcp.WithString("name", mcp.Description("Name of the saved search"), mcp.Required()),
+	)
+}
+
+// HandleDeleteSavedSearch handles the delete_saved_search tool.
+func HandleDeleteSavedSearch(ctx context.Context, reg *registry.Registry, store *savedsearch.Store, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name := cast.ToString(request.Params.Arguments["name"])
+
+	if err := store.Delete(name); err != nil {
+		if errors.Is(err, savedsearch.ErrNotFound) {
+			return mcp.NewToolResultError(fmt.Sprintf("no saved search named %q", name)), nil
+		}
+		return mcp.NewToolResultError(fmt.Errorf("failed to delete search: %w", err).Error()), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Deleted saved search %q", name)), nil
+}
+
+// runSearch walks root in lexical order, through the overlay if one is
+// active, and collects up to maxResults matches, reporting whether more
+// were left. Paths that cannot be read are skipped and added to errs. When
+// the budget runs out it returns the matches so far with errMaxFiles.
+func runSearch(ctx context.Context, reg *registry.Registry, root string, search *compiledSearch, maxResults int, budget *fileBudget, errs *walkErrors) ([]searchMatch, bool, error) {
+	matches := []searchMatch{}
+	errLimit := errors.New("result limit reached")
+
+	err := walkDir(reg, root, func(walkPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			errs.add(walkPath, err)
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if walkPath != root && !budget.take() {
+			return errMaxFiles
+		}
+		if entry.Type()&os.ModeSymlink != 0 {
+			return nil
+		}
+
+		relPath, relErr := filepath.Rel(root, walkPath)
+		if relErr != nil || relPath == "." {
+			return nil
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		if matchesAny(search.excludeGlobs, relPath) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		if len(search.matchGlobs) > 0 && !matchesAny(search.matchGlobs, relPath) {
+			return nil
+		}
+
+		if search.content == nil {
+			if len(matches) == maxResults {
+				return errLimit
+			}
+			matches = append(matches, searchMatch{Path: walkPath})
+			return nil
+		}
+		source, err := readTarget(reg, walkPath)
+		if err != nil {
+			errs.add(walkPath, err)
+			return nil
+		}
+		err = grepFile(source, search.content, search.before, search.after, func(m searchMatch) error {
+			if len(matches) == maxResults {
+				return errLimit
+			}
+			// The source may be the overlay's copy of the file
+			m.Path = walkPath
+			matches = append(matches, m)
+			return nil
+		})
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			errs.add(walkPath, err)
+			return nil
+		}
+		return err
+	})
+	if errors.Is(err, errLimit) {
+		return matches, true, nil
+	}
+	return matches, false, err
+}
+
+// grepFile calls emit for each line of path matching re, with up to before
+// and after lines of context. Binary files are skipped, and a file that
+// cannot be opened returns the error from os.Open.
+func grepFile(path string, re *regexp.Regexp, before, after int, emit func(searchMatch) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	head, _ := reader.Peek(binarySniffLen)
+	if looksBinary(head) {
+		return nil
+	}
+
+	// recent holds up to before lines not yet shown; pending is a match
+	// still collecting its after context.
+	var recent []contextLine
+	var pending *searchMatch
+	flush := func() error {
+		if pending == nil {
+			return nil
+		}
+		m := *pending
+		pending = nil
+		return emit(m)
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxScanLineLength)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if re.MatchString(text) {
+			if err := flush(); err != nil {
+				return err
+			}
+			pending = &searchMatch{Path: path, Line: line, Text: trimMatchLine(text), Before: recent}
+			recent = nil
+			if after == 0 {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if pending != nil {
+			pending.After = append(pending.After, contextLine{Line: line, Text: trimMatchLine(text)})
+			if len(pending.After) == after {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if before > 0 {
+			if len(recent) == before {
+				recent = append(recent[:0:0], recent[1:]...)
+			}
+			recent = append(recent, contextLine{Line: line, Text: trimMatchLine(text)})
+		}
+	}
+	return flush()
+}
+
+// trimMatchLine trims a line shown in search results and cuts it off at
+// maxMatchLineLength characters.
+func trimMatchLine(text string) string {
+	text = strings.TrimSpace(text)
+	if utf8.RuneCountInString(text) > maxMatchLineLength {
+		text = string([]rune(text)[:maxMatchLineLength]) + "..."
+	}
+	return text
+}
+
+// formatMatches renders search results in grep style: path:line: text for
+// matching lines and path-line- text for context lines, with -- between
+// non-adjacent groups of lines when context was requested.
+func formatMatches(matches []searchMatch, withContext bool) string {
+	var b strings.Builder
+	lastPath, lastLine := "", 0
+	for _, m := range matches {
+		if m.Line == 0 {
+			fmt.Fprintf(&b, "%s\n", m.Path)
+			continue
+		}
+		first := m.Line
+		if len(m.Before) > 0 {
+			first = m.Before[0].Line
+		}
+		if withContext && lastLine > 0 && (m.Path != lastPath || first != lastLine+1) {
+			b.WriteString("--\n")
+		}
+		for _, c := range m.Before {
+			fmt.Fprintf(&b, "%s-%d- %s\n", m.Path, c.Line, c.Text)
+		}
+		fmt.Fprintf(&b, "%s:%d: %s\n", m.Path, m.Line, m.Text)
+		for _, c := range m.After {
+			fmt.Fprintf(&b, "%s-%d- %s\n", m.Path, c.Line, c.Text)
+		}
+		lastPath, lastLine = m.Path, m.Line
+		if len(m.After) > 0 {
+			lastLine = m.After[len(m.After)-1].Line
+		}
+	}
+	return b.String()
+}
+
+// withContextParameters declares the before and after arguments of the
+// content search tools.
+func withContextParameters() mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		mcp.WithNumber("before", mcp.Description(fmt.Sprintf("Lines of context to show before each matching line, like grep -B (default: 0, max: %d)", maxContextLines)))(t)
+		mcp.WithNumber("after", mcp.Description(fmt.Sprintf("Lines of context to show after each matching line, like grep -A (default: 0, max: %d)", maxContextLines)))(t)
+	}
+}
+
+// parseContextLines reads the before and after arguments, clamped to
+// [0, maxContextLines].
+func parseContextLines(request mcp.CallToolRequest) (before, after int) {
+	clamp := func(n int) int {
+		return min(max(n, 0), maxContextLines)
+	}
+	return clamp(cast.ToInt(request.Params.Arguments["before"])), clamp(cast.ToInt(request.Params.Arguments["after"]))
+}
+
+// formatSearch renders the definition of s, indented.
+func formatSearch(s savedsearch.Search) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "  path: %s\n", s.Path)
+	if len(s.Patterns) > 0 {
+		fmt.Fprintf(&b, "  patterns: %s\n", strings.Join(s.Patterns, ", "))
+	}
+	if len(s.ExcludePatterns) > 0 {
+		fmt.Fprintf(&b, "  exclude: %s\n", strings.Join(s.ExcludePatterns, ", "))
+	}
+	if s.ContentPattern != "" {
+		caseNote := ""
+		if s.IgnoreCase {
+			caseNote = " (ignoring case)"
+		}
+		fmt.Fprintf(&b, "  content: %s%s\n", s.ContentPattern, caseNote)
+	}
+	return b.String()
+}
@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/savedsearch"
+)
+
+func TestSavedSearches(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	store, err := savedsearch.NewStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	withStore := func(h func(context.Context, *registry.Registry, *savedsearch.Store, mcp.CallToolRequest) (*mcp.CallToolResult, error)) func(context.Context, *registry.Registry, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, reg *registry.Registry, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return h(ctx, reg, store, req)
+		}
+	}
+	save := withStore(HandleSaveSearch)
+	run := withStore(HandleRunSavedSearch)
+
+	writePackTree(t, tmpDir, map[string]string{
+		"src/a.go":        "package a\n// TODO: handle errors\nfunc A() {}\n",
+		"src/b.go":        "package b\n// todo lowercase\n",
+		"src/c.txt":       "TODO in text\n",
+		"src/gen/g.go":    "// TODO generated\n",
+		"src/bin.go":      "TODO\x00",
+		"docs/readme.txt": "TODO docs\n",
+	})
+	src := filepath.Join(tmpDir, "src")
+
+	for _, args := range []map[string]any{
+		{"name": "todos", "path": src, "patterns": []interface{}{"**.go"}, "excludePatterns": []interface{}{"gen"}, "contentPattern": "TODO"},
+		{"name": "todos-any-case", "path": src, "patterns": []interface{}{"**.go"}, "contentPattern": "todo", "ignoreCase": true},
+		{"name": "go-files", "path": src, "patterns": []interface{}{"*.go"}},
+	} {
+		if result := callTool(t, save, reg, args); result.IsError {
+			t.Fatalf("save_search failed: %s", resultText(result))
+		}
+	}
+
+	tests := []struct {
+		name        string
+		args        map[string]any
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:        "content pattern",
+			args:        map[string]any{"name": "todos"},
+			contains:    []string{"a.go:2: // TODO: handle errors"},
+			notContains: []string{"b.go", "c.txt", "g.go", "bin.go", "readme"},
+		},
+		{
+			name:     "ignore case",
+			args:     map[string]any{"name": "todos-any-case"},
+			contains: []string{"a.go:2:", "b.go:2: // todo lowercase", "g.go:1:"},
+		},
+		{
+			name:        "files only",
+			args:        map[string]any{"name": "go-files"},
+			contains:    []string{filepath.Join(src, "a.go") + "\n", filepath.Join(src, "bin.go")},
+			notContains: []string{"c.txt"},
+		},
+		{
+			name:     "max results",
+			args:     map[string]any{"name": "todos-any-case", "maxResults": 1},
+			contains: []string{"Stopped after 1 results"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := callTool(t, run, reg, tt.args)
+			text := resultText(result)
+			if result.IsError {
+				t.Fatalf("unexpected error: %s", text)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(text, want) {
+					t.Errorf("expected %q in output:\n%s", want, text)
+				}
+			}
+			for _, unwanted := range tt.notContains {
+				if strings.Contains(text, unwanted) {
+					t.Errorf("did not expect %q in output:\n%s", unwanted, text)
+				}
+			}
+		})
+	}
+
+	list := resultText(callTool(t, withStore(HandleListSavedSearches), reg, map[string]any{}))
+	if !strings.Contains(list, "go-files") || !strings.Contains(list, "content: todo (ignoring case)") {
+		t.Errorf("unexpected list:\n%s", list)
+	}
+
+	if result := callTool(t, withStore(HandleDeleteSavedSearch), reg, map[string]any{"name": "todos"}); result.IsError {
+		t.Fatalf("delete failed: %s", resultText(result))
+	}
+	if result := callTool(t, run, reg, map[string]any{"name": "todos"}); !result.IsError {
+		t.Error("expected error running a deleted search")
+	}
+}
+
+func TestSaveSearchValidation(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	store, _ := savedsearch.NewStore("")
+	save := func(ctx context.Context, reg *registry.Registry, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return HandleSaveSearch(ctx, reg, store, req)
+	}
+
+	tests := []struct {
+		name string
+		args map[string]any
+	}{
+		{"bad regex", map[string]any{"name": "x", "path": tmpDir, "contentPattern": "("}},
+		{"bad glob", map[string]any{"name": "x", "path": tmpDir, "patterns": []interface{}{"["}}},
+		{"missing name", map[string]any{"path": tmpDir}},
+		{"outside allowed", map[string]any{"name": "x", "path": t.TempDir()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := callTool(t, save, reg, tt.args); !result.IsError {
+				t.Errorf("expected error, got %s", resultText(result))
+			}
+		})
+	}
+	if len(store.List()) != 0 {
+		t.Errorf("expected nothing saved, got %+v", store.List())
+	}
+}
@@ -3,6 +3,7 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
@@ -12,6 +13,7 @@ import (
 	"github.com/gobwas/glob"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/savedsearch"
 	"github.com/spf13/cast"
 )
 
@@ -24,7 +26,8 @@ func NewSearchFilesTool(reg *registry.Registry) mcp.Tool {
 		mcp.WithString("path", mcp.Description("Starting directory for the search"), mcp.Required()),
 		mcp.WithString("pattern", mcp.Description("Glob pattern to match file names"), mcp.Required()),
 		mcp.WithArray("excludePatterns", mcp.Description("Glob patterns to exclude"), mcp.Items(map[string]any{"type": "string"})),
-		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+		withMaxFilesParam(),
+		mcp.WithString("format", mcp.Description("Output format: 'text', 'json', 'markdown', or 'csv'")),
 	)
 }
 
@@ -40,18 +43,14 @@ func HandleSearchFiles(ctx context.Context, reg *registry.Registry, request mcp.
 			excludePatterns = append(excludePatterns, cast.ToString(p))
 		}
 	}
-
-	resolvedPath, err := reg.Validate(path)
+	budget, err := parseMaxFiles(request)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	info, err := os.Stat(resolvedPath)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Errorf("failed to stat path: %w", err).Error()), nil
-	}
-	if !info.IsDir() {
-		return mcp.NewToolResultError("path is not a directory"), nil
+	resolvedPath, errResult := resolveDirectory(reg, path)
+	if errResult != nil {
+		return errResult, nil
 	}
 
 	// Compile match pattern
@@ -71,10 +70,15 @@ func HandleSearchFiles(ctx context.Context, reg *registry.Registry, request mcp.
 	}
 
 	var matches []string
+	var walkErrs walkErrors
 
-	err = filepath.WalkDir(resolvedPath, func(walkPath string, entry fs.DirEntry, err error) error {
+	err = walkDir(reg, resolvedPath, func(walkPath string, entry fs.DirEntry, err error) error {
 		if err != nil {
-			return nil // Continue on errors
+			walkErrs.add(walkPath, err)
+			return nil
+		}
+		if walkPath != resolvedPath && !budget.take() {
+			return errMaxFiles
 		}
 		if entry.Type()&os.ModeSymlink != 0 {
 			if entry.Type()&os.ModeDir != 0 || entry.IsDir() {
@@ -107,7 +111,7 @@ func HandleSearchFiles(ctx context.Context, reg *registry.Registry, request mcp.
 		return nil
 	})
 
-	if err != nil {
+	if err != nil && !errors.Is(err, errMaxFiles) {
 		return mcp.NewToolResultError(fmt.Errorf("search failed: %w", err).Error()), nil
 	}
 
@@ -116,11 +120,27 @@ func HandleSearchFiles(ctx context.Context, reg *registry.Registry, request mcp.
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
 		}
-		return mcp.NewToolResultText(string(jsonResult)), nil
+		return withWalkErrors(withBudgetNote(mcp.NewToolResultText(string(jsonResult)), budget), &walkErrs), nil
 	}
 
 	if len(matches) == 0 {
-		return mcp.NewToolResultText("No matches found"), nil
+		return withWalkErrors(withBudgetNote(mcp.NewToolResultText("No matches found"), budget), &walkErrs), nil
+	}
+
+	if format == "csv" {
+		rows := make([][]string, 0, len(matches))
+		for _, m := range matches {
+			rows = append(rows, []string{csvText(m)})
+		}
+		return withWalkErrors(withBudgetNote(mcp.NewToolResultText(formatCSV([]string{"path"}, rows)), budget), &walkErrs), nil
+	}
+
+	if format == "markdown" {
+		rows := make([][]string, 0, len(matches))
+		for _, m := range matches {
+			rows = append(rows, []string{markdownCode(m)})
+		}
+		return withWalkErrors(withBudgetNote(mcp.NewToolResultText(markdownTable([]string{"Path"}, rows)), budget), &walkErrs), nil
 	}
 
 	result := ""
@@ -128,7 +148,7 @@ func HandleSearchFiles(ctx context.Context, reg *registry.Registry, request mcp.
 		result += m + "\n"
 	}
 
-	return mcp.NewToolResultText(result), nil
+	return withWalkErrors(withBudgetNote(mcp.NewToolResultText(result), budget), &walkErrs), nil
 }
 
 func compileGlobs(pattern string) ([]glob.Glob, error) {
@@ -162,3 +182,105 @@ func matchesAny(globs []glob.Glob, path string) bool {
 	}
 	return false
 }
+
+// contentSearchResult is the JSON result of search_content.
+type contentSearchResult struct {
+	Matches   []searchMatch `json:"matches"`
+	Truncated bool          `json:"truncated"`
+	// FileLimitReached is set when the search stopped at maxFiles.
+	FileLimitReached bool `json:"fileLimitReached,omitempty"`
+	// Errors lists the paths skipped because they could not be read.
+	Errors        []walkError `json:"errors,omitempty"`
+	OmittedErrors int         `json:"omittedErrors,omitempty"`
+}
+
+// NewSearchContentTool creates the search_content tool.
+func NewSearchContentTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"search_content",
+		mcp.WithDescription("Recursively search file contents for lines matching a regular expression. Returns matching lines as path:line: text, with optional context lines before and after each match as path-line- text, so surrounding code can be seen without reading whole files. Binary files and symlinks are skipped."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path", mcp.Description("Directory to search"), mcp.Required()),
+		mcp.WithString("pattern", mcp.Description("Regular expression to match against file lines"), mcp.Required()),
+		mcp.WithArray("patterns", mcp.Description("Glob patterns selecting files, relative to path (default: all files)"), mcp.Items(map[string]any{"type": "string"})),
+		mcp.WithArray("excludePatterns", mcp.Description("Glob patterns to exclude"), mcp.Items(map[string]any{"type": "string"})),
+		mcp.WithBoolean("ignoreCase", mcp.Description("If true, match pattern case-insensitively")),
+		withContextParameters(),
+		mcp.WithNumber("maxResults", mcp.Description(fmt.Sprintf("Maximum number of matching lines (default: %d, max: %d)", defaultSearchResults, maxSearchResults))),
+		withMaxFilesParam(),
+		mcp.WithString("format", mcp.Description("Output format: 'text', 'json', or 'markdown'")),
+	)
+}
+
+// HandleSearchContent handles the search_content tool.
+func HandleSearchContent(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	pattern := cast.ToString(request.Params.Arguments["pattern"])
+	maxResults := cast.ToInt(request.Params.Arguments["maxResults"])
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	if pattern == "" {
+		return mcp.NewToolResultError("pattern is required"), nil
+	}
+	if maxResults <= 0 {
+		maxResults = defaultSearchResults
+	}
+	if maxResults > maxSearchResults {
+		maxResults = maxSearchResults
+	}
+	budget, err := parseMaxFiles(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	compiled, err := compileSearch(savedsearch.Search{
+		Patterns:        cast.ToStringSlice(request.Params.Arguments["patterns"]),
+		ExcludePatterns: cast.ToStringSlice(request.Params.Arguments["excludePatterns"]),
+		ContentPattern:  pattern,
+		IgnoreCase:      cast.ToBool(request.Params.Arguments["ignoreCase"]),
+	})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	compiled.before, compiled.after = parseContextLines(request)
+
+	resolvedPath, errResult := resolveDirectory(reg, path)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	var walkErrs walkErrors
+	matches, truncated, err := runSearch(ctx, reg, resolvedPath, compiled, maxResults, budget, &walkErrs)
+	if err != nil && !errors.Is(err, errMaxFiles) {
+		return mcp.NewToolResultError(fmt.Errorf("search failed: %w", err).Error()), nil
+	}
+
+	if format == "json" {
+		jsonResult, err := json.MarshalIndent(contentSearchResult{
+			Matches:          matches,
+			Truncated:        truncated,
+			FileLimitReached: budget.exhausted,
+			Errors:           walkErrs.Errors,
+			OmittedErrors:    walkErrs.Omitted,
+		}, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(jsonResult)), nil
+	}
+
+	if len(matches) == 0 {
+		return withWalkErrors(withBudgetNote(mcp.NewToolResultText("No matches found"), budget), &walkErrs), nil
+	}
+
+	var result strings.Builder
+	if format == "markdown" {
+		result.WriteString(markdownMatches(matches))
+	} else {
+		result.WriteString(formatMatches(matches, compiled.before > 0 || compiled.after > 0))
+	}
+	if truncated {
+		fmt.Fprintf(&result, "\nStopped after %d results; raise maxResults to see more.\n", maxResults)
+	}
+	return withWalkErrors(withBudgetNote(mcp.NewToolResultText(result.String()), budget), &walkErrs), nil
+}
@@ -166,3 +166,149 @@ func TestHandleSearchFilesSkipsSymlinks(t *testing.T) {
 		t.Error("symlinked directory contents should not be included in output")
 	}
 }
+
+func TestHandleSearchContent(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	source := "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"a\")\n\tfmt.Println(\"b\")\n}\n\n\n// TODO: tidy\n"
+	path := filepath.Join(tmpDir, "main.go")
+	if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "notes.txt"), []byte("fmt.Println\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := callTool(t, HandleSearchContent, reg, map[string]any{
+		"path":     tmpDir,
+		"pattern":  `fmt\.Println|TODO`,
+		"patterns": []any{"*.go"},
+		"before":   1,
+		"after":    1,
+		"format":   "json",
+	})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	var found contentSearchResult
+	if err := json.Unmarshal([]byte(resultText(result)), &found); err != nil {
+		t.Fatal(err)
+	}
+	if len(found.Matches) != 3 {
+		t.Fatalf("expected 3 matches, got %+v", found.Matches)
+	}
+	// Adjacent matches share no context lines
+	first, second := found.Matches[0], found.Matches[1]
+	if first.Line != 6 || len(first.Before) != 1 || first.Before[0].Line != 5 || len(first.After) != 0 {
+		t.Errorf("unexpected first match: %+v", first)
+	}
+	if second.Line != 7 || len(second.Before) != 0 || len(second.After) != 1 || second.After[0].Text != "}" {
+		t.Errorf("unexpected second match: %+v", second)
+	}
+	if third := found.Matches[2]; third.Line != 11 || len(third.Before) != 1 || third.Before[0].Line != 10 || len(third.After) != 0 {
+		t.Errorf("unexpected third match: %+v", third)
+	}
+
+	result = callTool(t, HandleSearchContent, reg, map[string]any{
+		"path":     tmpDir,
+		"pattern":  `fmt\.Println|TODO`,
+		"patterns": []any{"*.go"},
+		"before":   1,
+		"after":    1,
+	})
+	want := path + "-5- func main() {\n" +
+		path + ":6: fmt.Println(\"a\")\n" +
+		path + ":7: fmt.Println(\"b\")\n" +
+		path + "-8- }\n" +
+		"--\n" +
+		path + "-10- \n" +
+		path + ":11: // TODO: tidy\n"
+	if text := resultText(result); text != want {
+		t.Errorf("unexpected text output:\n%s\nwant:\n%s", text, want)
+	}
+
+	result = callTool(t, HandleSearchContent, reg, map[string]any{"path": tmpDir, "pattern": "Println", "maxResults": 1})
+	if text := resultText(result); !strings.Contains(text, "Stopped after 1 results") {
+		t.Errorf("expected truncation notice, got:\n%s", text)
+	}
+
+	result = callTool(t, HandleSearchContent, reg, map[string]any{"path": tmpDir, "pattern": "("})
+	if !result.IsError {
+		t.Error("expected error for invalid regular expression")
+	}
+}
+
+func TestSearchMaxFiles(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte("needle\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result := callTool(t, HandleSearchFiles, reg, map[string]any{"path": tmpDir, "pattern": "*.go", "maxFiles": 2})
+	if text := resultText(result); !strings.Contains(text, "b.go") || strings.Contains(text, "c.go") || !strings.Contains(text, "raise maxFiles") {
+		t.Errorf("expected search_files to stop after 2 files:\n%s", text)
+	}
+
+	result = callTool(t, HandleSearchContent, reg, map[string]any{"path": tmpDir, "pattern": "needle", "maxFiles": 2, "format": "json"})
+	var found contentSearchResult
+	if err := json.Unmarshal([]byte(resultText(result)), &found); err != nil {
+		t.Fatal(err)
+	}
+	if len(found.Matches) != 2 || !found.FileLimitReached || found.Truncated {
+		t.Errorf("expected search_content to stop after 2 files, got %+v", found)
+	}
+
+	result = callTool(t, HandleSearchContent, reg, map[string]any{"path": tmpDir, "pattern": "needle"})
+	if strings.Contains(resultText(result), "maxFiles") {
+		t.Errorf("expected no note under the default limit:\n%s", resultText(result))
+	}
+	if result := callTool(t, HandleSearchFiles, reg, map[string]any{"path": tmpDir, "pattern": "*.go", "maxFiles": -1}); !result.IsError {
+		t.Error("expected a negative maxFiles to be rejected")
+	}
+}
+
+func TestSearchReportsUnreadablePaths(t *testing.T) {
+	if os.Getuid() == 0 {
+		t.Skip("permissions are not enforced for root")
+	}
+	reg, tmpDir := setupTestRegistry(t)
+	os.WriteFile(filepath.Join(tmpDir, "ok.txt"), []byte("needle\n"), 0644)
+	locked := filepath.Join(tmpDir, "locked")
+	os.Mkdir(locked, 0755)
+	os.WriteFile(filepath.Join(locked, "hidden.txt"), []byte("needle\n"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "secret.txt"), []byte("needle\n"), 0)
+	os.Chmod(locked, 0)
+	t.Cleanup(func() { os.Chmod(locked, 0755) })
+
+	result := callTool(t, HandleSearchFiles, reg, map[string]any{"path": tmpDir, "pattern": "*.txt"})
+	if result.IsError || len(result.Content) != 2 {
+		t.Fatalf("expected the matches and an errors item, got %v", result.Content)
+	}
+	var report walkErrors
+	if err := json.Unmarshal([]byte(result.Content[1].(mcp.TextContent).Text), &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Errors) != 1 || report.Errors[0].Path != locked || report.Errors[0].Error != "permission denied" {
+		t.Errorf("unexpected errors %+v", report.Errors)
+	}
+
+	result = callTool(t, HandleSearchContent, reg, map[string]any{"path": tmpDir, "pattern": "needle", "format": "json"})
+	var parsed contentSearchResult
+	if err := json.Unmarshal([]byte(resultText(result)), &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed.Matches) != 1 || len(parsed.Errors) != 2 {
+		t.Errorf("expected one match and two errors, got %+v", parsed)
+	}
+}
+
+func TestWalkErrorsCap(t *testing.T) {
+	var errs walkErrors
+	for i := 0; i < maxWalkErrors+5; i++ {
+		errs.add("/x", &os.PathError{Op: "open", Path: "/x", Err: os.ErrPermission})
+	}
+	if len(errs.Errors) != maxWalkErrors || errs.Omitted != 5 || errs.Errors[0].Error != "permission denied" {
+		t.Errorf("unexpected errors: %d kept, %d omitted, first %+v", len(errs.Errors), errs.Omitted, errs.Errors[0])
+	}
+}
@@ -0,0 +1,145 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+)
+
+// ServerName is the name the server reports to clients.
+const ServerName = "filesystem-mcp-server"
+
+// serverInfo is the result of get_server_info. Fields are only ever added,
+// so agents can rely on the ones they know.
+type serverInfo struct {
+	Name               string             `json:"name"`
+	Version            string             `json:"version"`
+	Tools              []string           `json:"tools"`
+	AllowedDirectories []allowedDirectory `json:"allowedDirectories"`
+	Aliases            []rootAlias        `json:"aliases"`
+	Limits             serverLimits       `json:"limits"`
+	Features           serverFeatures     `json:"features"`
+	Symlinks           symlinkPolicy      `json:"symlinks"`
+	Permissions        createdModes       `json:"permissions"`
+}
+
+type allowedDirectory struct {
+	Path     string `json:"path"`
+	ReadOnly bool   `json:"readOnly"`
+}
+
+type rootAlias struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// serverLimits are the size and count limits of this deployment. Zero
+// means unlimited.
+type serverLimits struct {
+	MaxDeleteFiles int   `json:"maxDeleteFiles"`
+	MaxDeleteBytes int64 `json:"maxDeleteBytes"`
+	// MemoryBudget caps the file content buffered by in-flight reads.
+	MemoryBudget    int64 `json:"memoryBudget"`
+	MaxMediaSize    int64 `json:"maxMediaSize"`
+	DefaultMaxFiles int   `json:"defaultMaxFiles"`
+}
+
+// serverFeatures are the optional behaviors turned on for this deployment.
+type serverFeatures struct {
+	Overlay              bool     `json:"overlay"`
+	StrictFilenames      bool     `json:"strictFilenames"`
+	ShadowReads          bool     `json:"shadowReads"`
+	WriteGrants          bool     `json:"writeGrants"`
+	ChangeOwner          bool     `json:"changeOwner"`
+	ConfirmedTools       []string `json:"confirmedTools"`
+	ProtectedDirectories []string `json:"protectedDirectories"`
+}
+
+// createdModes are the permissions of files and directories the tools
+// create, in octal.
+type createdModes struct {
+	File string `json:"file"`
+	Dir  string `json:"dir"`
+}
+
+// symlinkPolicy describes how symlinks are treated. It is fixed, and
+// reported so agents need not probe for it.
+type symlinkPolicy struct {
+	Read      string `json:"read"`
+	Write     string `json:"write"`
+	Traversal string `json:"traversal"`
+}
+
+// NewGetServerInfoTool creates the get_server_info tool.
+func NewGetServerInfoTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"get_server_info",
+		mcp.WithDescription("Describe this server's deployment as JSON: version, the tools enabled, allowed directories and which are read-only, root aliases, configured limits, optional features that are on, the symlink policy, and the permissions of created files. Call it once at the start of a session to adapt to the configuration instead of discovering it by trial and error."),
+		mcp.WithReadOnlyHintAnnotation(true),
+	)
+}
+
+// HandleGetServerInfo handles the get_server_info tool. toolNames lists the
+// registered tools.
+func HandleGetServerInfo(ctx context.Context, reg *registry.Registry, version string, toolNames []string, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	info := serverInfo{
+		Name:               ServerName,
+		Version:            version,
+		Tools:              append([]string{}, toolNames...),
+		AllowedDirectories: []allowedDirectory{},
+		Aliases:            []rootAlias{},
+		Limits: serverLimits{
+			MaxDeleteFiles:  reg.Limits().MaxDeleteFiles,
+			MaxDeleteBytes:  reg.Limits().MaxDeleteBytes,
+			MaxMediaSize:    maxMediaSize,
+			DefaultMaxFiles: defaultMaxFiles,
+		},
+		Features: serverFeatures{
+			Overlay:              reg.Overlay() != nil,
+			StrictFilenames:      reg.StrictFilenames(),
+			ShadowReads:          reg.Shadow() != nil,
+			WriteGrants:          reg.Grants() != nil,
+			ChangeOwner:          reg.AllowChown(),
+			ConfirmedTools:       []string{},
+			ProtectedDirectories: []string{},
+		},
+		Symlinks: symlinkPolicy{
+			Read:      "followed when the target is inside an allowed directory",
+			Write:     "rejected",
+			Traversal: "skipped",
+		},
+		Permissions: createdModes{
+			File: fmt.Sprintf("%04o", reg.Modes().File),
+			Dir:  fmt.Sprintf("%04o", reg.Modes().Dir),
+		},
+	}
+	sort.Strings(info.Tools)
+	for _, d := range reg.Get() {
+		info.AllowedDirectories = append(info.AllowedDirectories, allowedDirectory{Path: d, ReadOnly: reg.IsReadOnly(d)})
+	}
+	for _, a := range reg.Aliases() {
+		info.Aliases = append(info.Aliases, rootAlias{Name: a.Name, Path: a.Dir})
+	}
+	if b := reg.MemoryBudget(); b != nil {
+		info.Limits.MemoryBudget = b.Stats().Limit
+	}
+	for name := range ConfirmableTools {
+		if reg.Confirmations().Requires(name) {
+			info.Features.ConfirmedTools = append(info.Features.ConfirmedTools, name)
+		}
+	}
+	sort.Strings(info.Features.ConfirmedTools)
+	if g := reg.Protection(); g != nil {
+		info.Features.ProtectedDirectories = g.Dirs()
+	}
+
+	jsonResult, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+	}
+	return mcp.NewToolResultText(string(jsonResult)), nil
+}
@@ -0,0 +1,51 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/confirm"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+)
+
+func TestHandleGetServerInfo(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	if err := reg.SetReadOnly([]string{tmpDir}); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.SetAliases(map[string]string{"work": tmpDir}); err != nil {
+		t.Fatal(err)
+	}
+	reg.SetLimits(registry.Limits{MaxDeleteFiles: 10})
+	reg.SetConfirmations(confirm.New([]string{"delete_directory"}, confirm.DefaultTTL))
+	reg.SetModes(registry.Modes{File: 0600, Dir: 0700})
+
+	result, err := HandleGetServerInfo(context.Background(), reg, "1.2.3", []string{"read_file", "health"}, mcp.CallToolRequest{})
+	if err != nil || result.IsError {
+		t.Fatalf("unexpected error: %v %s", err, resultText(result))
+	}
+	var info serverInfo
+	if err := json.Unmarshal([]byte(resultText(result)), &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Version != "1.2.3" || len(info.Tools) != 2 || info.Tools[0] != "health" {
+		t.Errorf("unexpected version or tools: %+v", info)
+	}
+	if len(info.AllowedDirectories) != 1 || !info.AllowedDirectories[0].ReadOnly {
+		t.Errorf("unexpected directories %+v", info.AllowedDirectories)
+	}
+	if len(info.Aliases) != 1 || info.Aliases[0].Name != "work" {
+		t.Errorf("unexpected aliases %+v", info.Aliases)
+	}
+	if info.Limits.MaxDeleteFiles != 10 || info.Limits.MaxMediaSize != maxMediaSize {
+		t.Errorf("unexpected limits %+v", info.Limits)
+	}
+	if len(info.Features.ConfirmedTools) != 1 || info.Features.ConfirmedTools[0] != "delete_directory" || info.Features.Overlay {
+		t.Errorf("unexpected features %+v", info.Features)
+	}
+	if info.Permissions.File != "0600" || info.Permissions.Dir != "0700" {
+		t.Errorf("unexpected permissions %+v", info.Permissions)
+	}
+}
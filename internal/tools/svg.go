@@ -0,0 +1,179 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image/png"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/portertech/filesystem-mcp-server/internal/svg"
+	"github.com/spf13/cast"
+)
+
+// maxSVGSize is the largest SVG file sanitize_svg and rasterize_svg read.
+const maxSVGSize = 10 * 1024 * 1024
+
+// sanitizedSVG is the result of sanitize_svg.
+type sanitizedSVG struct {
+	Path    string   `json:"path"`
+	Removed []string `json:"removed"`
+	SVG     string   `json:"svg"`
+}
+
+// NewSanitizeSVGTool creates the sanitize_svg tool.
+func NewSanitizeSVGTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"sanitize_svg",
+		mcp.WithDescription("Return an SVG file with everything that can run scripts or load external resources removed: script and foreignObject elements, event handler attributes, javascript: and external links, external url() references and @import rules in CSS, and DOCTYPE declarations. Local #fragment references and raster image data URLs are kept. Lists what was removed. The file itself is not changed."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path", mcp.Description("Path to the SVG file"), mcp.Required()),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleSanitizeSVG handles the sanitize_svg tool.
+func HandleSanitizeSVG(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	data, errResult := readSVG(reg, path)
+	if errResult != nil {
+		return errResult, nil
+	}
+	clean, removed, err := svg.Sanitize(data)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to sanitize SVG: %w", err).Error()), nil
+	}
+	if removed == nil {
+		removed = []string{}
+	}
+
+	if format == "json" {
+		jsonResult, err := json.MarshalIndent(sanitizedSVG{Path: path, Removed: removed, SVG: string(clean)}, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(jsonResult)), nil
+	}
+
+	var text strings.Builder
+	if len(removed) == 0 {
+		text.WriteString("Nothing unsafe found\n\n")
+	} else {
+		fmt.Fprintf(&text, "Removed: %s\n\n", strings.Join(removed, "; "))
+	}
+	text.Write(clean)
+	return mcp.NewToolResultText(text.String()), nil
+}
+
+// NewRasterizeSVGTool creates the rasterize_svg tool.
+func NewRasterizeSVGTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"rasterize_svg",
+		mcp.WithDescription(fmt.Sprintf("Render an SVG file to a PNG image, for clients that cannot display SVG or should not be handed its markup. Shapes, paths, groups, use, transforms, and solid fills and strokes are drawn; text, embedded images, filters, clipping, masks, and style sheets are skipped, and gradients are drawn in their average color. The result notes anything skipped. Nothing external is loaded. Returns the image in the same form as read_media_file. Images are at most %dx%d.", svg.MaxSize, svg.MaxSize)),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path", mcp.Description("Path to the SVG file"), mcp.Required()),
+		mcp.WithNumber("width", mcp.Description("Width of the image in pixels. With only one of width and height, the other follows the SVG's aspect ratio (default: the SVG's own size)")),
+		mcp.WithNumber("height", mcp.Description("Height of the image in pixels")),
+	)
+}
+
+// HandleRasterizeSVG handles the rasterize_svg tool.
+func HandleRasterizeSVG(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	width := cast.ToInt(request.Params.Arguments["width"])
+	height := cast.ToInt(request.Params.Arguments["height"])
+
+	if width < 0 || height < 0 || width > svg.MaxSize || height > svg.MaxSize {
+		return mcp.NewToolResultError(fmt.Sprintf("width and height must be between 1 and %d", svg.MaxSize)), nil
+	}
+
+	data, errResult := readSVG(reg, path)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	// Settle the image size first so the reservation covers its pixels
+	iw, ih, err := svg.Size(data)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to rasterize SVG: %w", err).Error()), nil
+	}
+	switch {
+	case width > 0 && height > 0:
+	case width > 0:
+		height = max(1, int(math.Round(float64(width)*ih/iw)))
+	case height > 0:
+		width = max(1, int(math.Round(float64(height)*iw/ih)))
+	default:
+		width, height = max(1, int(math.Ceil(iw))), max(1, int(math.Ceil(ih)))
+	}
+	if width > svg.MaxSize || height > svg.MaxSize {
+		return mcp.NewToolResultError(fmt.Sprintf("image would be %dx%d; pass a width or height to fit within %dx%d", width, height, svg.MaxSize, svg.MaxSize)), nil
+	}
+	pixels := int64(width) * int64(height)
+	release, err := reserveMemory(ctx, reg, path, 4*pixels)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	defer release()
+
+	img, notes, err := svg.Rasterize(data, width, height)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to rasterize SVG: %w", err).Error()), nil
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to encode PNG: %w", err).Error()), nil
+	}
+	if buf.Len() > maxMediaSize {
+		return mcp.NewToolResultError(fmt.Sprintf("image is %s, larger than the %s a single read returns; pass a smaller width or height", stream.FormatSize(int64(buf.Len())), stream.FormatSize(maxMediaSize))), nil
+	}
+
+	result := map[string]interface{}{
+		"type":     "image",
+		"mimeType": "image/png",
+		"data":     base64.StdEncoding.EncodeToString(buf.Bytes()),
+		"width":    img.Bounds().Dx(),
+		"height":   img.Bounds().Dy(),
+	}
+	if len(notes) > 0 {
+		result["notes"] = notes
+	}
+	jsonResult, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+	}
+	return mcp.NewToolResultText(string(jsonResult)), nil
+}
+
+// readSVG reads the SVG file at path, refusing directories and files over
+// maxSVGSize.
+func readSVG(reg *registry.Registry, path string) ([]byte, *mcp.CallToolResult) {
+	readPath, err := validateRead(reg, path)
+	if err != nil {
+		return nil, mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error())
+	}
+	info, err := os.Stat(readPath)
+	if err != nil {
+		return nil, mcp.NewToolResultError(fmt.Errorf("failed to stat SVG: %w", err).Error())
+	}
+	if info.IsDir() {
+		return nil, mcp.NewToolResultError("path is a directory, not an SVG file")
+	}
+	if info.Size() > maxSVGSize {
+		return nil, mcp.NewToolResultError(fmt.Sprintf("file is %s; SVGs up to %s are read", stream.FormatSize(info.Size()), stream.FormatSize(maxSVGSize)))
+	}
+	data, err := os.ReadFile(readPath)
+	if err != nil {
+		return nil, mcp.NewToolResultError(fmt.Errorf("failed to read SVG: %w", err).Error())
+	}
+	return data, nil
+}
@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSVG = `<svg xmlns="http://www.w3.org/2000/svg" width="40" height="20" onload="alert(1)">
+  <script>alert(2)</script>
+  <rect width="20" height="20" fill="red"/>
+  <text>label</text>
+</svg>`
+
+func TestHandleSanitizeSVG(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	svgPath := filepath.Join(tmpDir, "icon.svg")
+	if err := os.WriteFile(svgPath, []byte(testSVG), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := callTool(t, HandleSanitizeSVG, reg, map[string]interface{}{"path": svgPath})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	text := resultText(result)
+	if strings.Contains(text, "alert") {
+		t.Errorf("scripts survived sanitizing:\n%s", text)
+	}
+	if !strings.Contains(text, "Removed: onload event handler; <script> element") {
+		t.Errorf("expected a list of removals in:\n%s", text)
+	}
+	if !strings.Contains(text, `<rect width="20" height="20" fill="red"/>`) {
+		t.Errorf("expected shapes to be kept in:\n%s", text)
+	}
+
+	result = callTool(t, HandleSanitizeSVG, reg, map[string]interface{}{"path": svgPath, "format": "json"})
+	var out sanitizedSVG
+	if err := json.Unmarshal([]byte(resultText(result)), &out); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(out.Removed) != 2 || !strings.HasPrefix(out.SVG, "<svg") {
+		t.Errorf("unexpected result: %+v", out)
+	}
+
+	// The file itself is left alone
+	if data, _ := os.ReadFile(svgPath); string(data) != testSVG {
+		t.Error("sanitize_svg modified the file")
+	}
+}
+
+func TestHandleRasterizeSVG(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	svgPath := filepath.Join(tmpDir, "icon.svg")
+	if err := os.WriteFile(svgPath, []byte(testSVG), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := callTool(t, HandleRasterizeSVG, reg, map[string]interface{}{"path": svgPath, "width": 80})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	var out struct {
+		Type     string   `json:"type"`
+		MIMEType string   `json:"mimeType"`
+		Data     string   `json:"data"`
+		Width    int      `json:"width"`
+		Height   int      `json:"height"`
+		Notes    []string `json:"notes"`
+	}
+	if err := json.Unmarshal([]byte(resultText(result)), &out); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if out.Type != "image" || out.MIMEType != "image/png" || out.Width != 80 || out.Height != 40 {
+		t.Errorf("unexpected result: %+v", out)
+	}
+	if len(out.Notes) != 1 || out.Notes[0] != "text is not rendered" {
+		t.Errorf("notes = %q, want a note about text", out.Notes)
+	}
+	data, err := base64.StdEncoding.DecodeString(out.Data)
+	if err != nil {
+		t.Fatalf("invalid base64: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("invalid PNG: %v", err)
+	}
+	if r, _, _, a := img.At(10, 10).RGBA(); r != 0xffff || a != 0xffff {
+		t.Errorf("expected red inside the rect, got %v", img.At(10, 10))
+	}
+	if _, _, _, a := img.At(70, 10).RGBA(); a != 0 {
+		t.Errorf("expected transparency outside the rect, got %v", img.At(70, 10))
+	}
+
+	result = callTool(t, HandleRasterizeSVG, reg, map[string]interface{}{"path": svgPath, "width": 5000})
+	if !result.IsError {
+		t.Error("expected an error for an oversized image")
+	}
+	result = callTool(t, HandleRasterizeSVG, reg, map[string]interface{}{"path": svgPath, "height": 4000})
+	if !result.IsError || !strings.Contains(resultText(result), "8000x4000") {
+		t.Errorf("expected an error for an oversized derived width, got: %s", resultText(result))
+	}
+}
@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/security"
+	"github.com/spf13/cast"
+)
+
+// NewCreateSymlinkTool creates the create_symlink tool.
+func NewCreateSymlinkTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"create_symlink",
+		mcp.WithDescription("Create a symbolic link at path pointing to target. Both the link and the fully resolved target must be inside allowed directories, and the target must exist. A relative target is stored as given and resolved from the link's directory."),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Create Symlink",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  boolPtr(false),
+		}),
+		mcp.WithString("path", mcp.Description("Path of the link to create"), mcp.Required()),
+		mcp.WithString("target", mcp.Description("Path the link points to, absolute or relative to the link's directory"), mcp.Required()),
+	)
+}
+
+// HandleCreateSymlink handles the create_symlink tool.
+func HandleCreateSymlink(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	target := cast.ToString(request.Params.Arguments["target"])
+
+	if target == "" {
+		return mcp.NewToolResultError("target is required"), nil
+	}
+	if reg.Overlay() != nil {
+		return mcp.NewToolResultError("create_symlink is not supported in overlay mode"), nil
+	}
+
+	resolvedPath, err := reg.ValidateForCreation(path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	if err := reg.CheckWrite(resolvedPath); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to create symlink: %w", err).Error()), nil
+	}
+	if _, err := os.Lstat(resolvedPath); err == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to create symlink: %s already exists", resolvedPath)), nil
+	}
+
+	// The target is resolved the way the OS will resolve the link, from the
+	// link's real directory, with every symlink on the way followed. It is
+	// not cleaned first: a ".." after a symlink leaves the symlink's
+	// destination, not the directory holding it.
+	absTarget := target
+	if !filepath.IsAbs(target) {
+		absTarget = filepath.Dir(resolvedPath) + string(os.PathSeparator) + target
+	}
+	realTarget, err := filepath.EvalSymlinks(absTarget)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("target validation failed: %w", err).Error()), nil
+	}
+	resolvedTarget, err := security.ValidatePath(realTarget, reg.Get())
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("target validation failed: %w", err).Error()), nil
+	}
+
+	// Validate the link location again right before creating it, so a
+	// symlinked parent planted since cannot redirect it
+	if _, err := security.ValidateFinalPathForCreation(resolvedPath, reg.Get()); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	if err := security.ValidateNoSymlinksInPath(filepath.Dir(resolvedPath), reg.GetResolved()); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	if err := os.Symlink(target, resolvedPath); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to create symlink: %w", err).Error()), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Created symlink %s -> %s (resolves to %s)", resolvedPath, target, resolvedTarget)), nil
+}
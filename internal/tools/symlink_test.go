@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+)
+
+func TestHandleCreateSymlink(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	if err := os.MkdirAll(filepath.Join(tmpDir, "releases", "v2"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	outside := t.TempDir()
+
+	// Relative targets are stored as given
+	link := filepath.Join(tmpDir, "current")
+	result := callTool(t, HandleCreateSymlink, reg, map[string]any{"path": link, "target": "releases/v2"})
+	if result.IsError {
+		t.Fatalf("create_symlink failed: %s", resultText(result))
+	}
+	if got, _ := os.Readlink(link); got != "releases/v2" {
+		t.Errorf("expected link to releases/v2, got %q", got)
+	}
+
+	result = callTool(t, HandleCreateSymlink, reg, map[string]any{"path": link, "target": "releases"})
+	if !result.IsError || !strings.Contains(resultText(result), "already exists") {
+		t.Errorf("expected existing link to be refused, got %q", resultText(result))
+	}
+
+	tests := []struct {
+		name   string
+		target string
+	}{
+		{"absolute target outside", outside},
+		{"relative target escaping", filepath.Join("..", filepath.Base(outside))},
+		{"missing target", "releases/v3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tmpDir, "bad-link")
+			result := callTool(t, HandleCreateSymlink, reg, map[string]any{"path": path, "target": tt.target})
+			if !result.IsError {
+				t.Errorf("expected target %q to be refused", tt.target)
+			}
+			if _, err := os.Lstat(path); err == nil {
+				t.Error("link should not have been created")
+			}
+		})
+	}
+
+	// A target reached through an existing symlink is checked where it
+	// finally resolves
+	if err := os.Symlink(outside, filepath.Join(tmpDir, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	result = callTool(t, HandleCreateSymlink, reg, map[string]any{"path": filepath.Join(tmpDir, "via"), "target": "escape"})
+	if !result.IsError {
+		t.Error("expected target resolving outside allowed directories to be refused")
+	}
+
+	// A ".." after a symlink is resolved from the symlink's destination, as
+	// the OS does, not removed along with it
+	root := filepath.Join(tmpDir, "rooted")
+	if err := os.MkdirAll(filepath.Join(root, "a", "b"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "a", "b", "secret"), []byte("decoy"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "secret"), []byte("outside"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(root, filepath.Join(root, "a", "b", "up")); err != nil {
+		t.Fatal(err)
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	rootReg := registry.New([]string{root}, logger)
+	esc := filepath.Join(root, "a", "b", "esc")
+	result = callTool(t, HandleCreateSymlink, rootReg, map[string]any{"path": esc, "target": "up/../secret"})
+	if !result.IsError {
+		t.Error("expected target escaping through a symlink and .. to be refused")
+	}
+	if _, err := os.Lstat(esc); err == nil {
+		t.Error("link should not have been created")
+	}
+}
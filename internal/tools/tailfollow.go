@@ -0,0 +1,258 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/spf13/cast"
+)
+
+const (
+	// tailPollInterval is how often tail_follow checks the file for new data.
+	tailPollInterval = 250 * time.Millisecond
+	// defaultFollowSeconds and maxFollowSeconds bound how long tail_follow
+	// follows a file. The call holds up the client's other calls meanwhile.
+	defaultFollowSeconds = 30
+	maxFollowSeconds     = 300
+	// defaultFollowLines is how many new lines tail_follow collects at most.
+	defaultFollowLines = 1000
+	// maxFollowLineBytes caps a line without a newline, which is cut there.
+	maxFollowLineBytes = 64 * 1024
+	// maxFollowReadBytes caps how much is read from the file per poll.
+	maxFollowReadBytes = 1 << 20
+)
+
+// NewTailFollowTool creates the tail_follow tool.
+func NewTailFollowTool() mcp.Tool {
+	return mcp.NewTool(
+		"tail_follow",
+		mcp.WithDescription(fmt.Sprintf("Follow a text file like tail -F, such as a build log, and push lines appended to it to the client as log message notifications (logger \"tail_follow\") while they arrive. Stops after the given duration, after maxLines new lines, or when the call is cancelled, then returns the last lines of the file and every new line. Follows the file across truncation and replacement by rotation. The server handles one call at a time, so other calls wait until following stops; at most %d seconds.", maxFollowSeconds)),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "Follow File",
+			ReadOnlyHint: boolPtr(true),
+		}),
+		mcp.WithString("path", mcp.Description("Path to the file to follow"), mcp.Required()),
+		mcp.WithNumber("lines", mcp.Description("Number of existing lines from the end to include first (default: 10)")),
+		mcp.WithNumber("duration", mcp.Description(fmt.Sprintf("Seconds to follow the file (default: %d, maximum: %d)", defaultFollowSeconds, maxFollowSeconds))),
+		mcp.WithNumber("maxLines", mcp.Description(fmt.Sprintf("Stop after this many new lines (default: %d)", defaultFollowLines))),
+	)
+}
+
+// HandleTailFollow handles the tail_follow tool.
+func HandleTailFollow(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	lines := 10
+	if v, ok := request.Params.Arguments["lines"]; ok {
+		lines = cast.ToInt(v)
+	}
+	seconds := defaultFollowSeconds
+	if v, ok := request.Params.Arguments["duration"]; ok {
+		seconds = cast.ToInt(v)
+	}
+	maxLines := defaultFollowLines
+	if v, ok := request.Params.Arguments["maxLines"]; ok {
+		maxLines = cast.ToInt(v)
+	}
+	if lines < 0 {
+		return mcp.NewToolResultError("lines must not be negative"), nil
+	}
+	if seconds <= 0 || seconds > maxFollowSeconds {
+		return mcp.NewToolResultError(fmt.Sprintf("duration must be between 1 and %d seconds", maxFollowSeconds)), nil
+	}
+	if maxLines <= 0 {
+		return mcp.NewToolResultError("maxLines must be positive"), nil
+	}
+
+	resolvedPath, err := reg.Validate(path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	f, err := openFollowed(reg, resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	defer f.close()
+	// Follow the name as given, so a symlink repointed by rotation is
+	// followed to the new file
+	f.path = path
+
+	var out strings.Builder
+	if lines > 0 {
+		tail, err := stream.TailFile(f.file.Name(), lines)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to read file: %w", err).Error()), nil
+		}
+		out.WriteString(tail)
+		if tail != "" && !strings.HasSuffix(tail, "\n") {
+			out.WriteString("\n")
+		}
+	}
+	fmt.Fprintf(&out, "--- following %s ---\n", resolvedPath)
+
+	start := time.Now()
+	deadline := time.NewTimer(time.Duration(seconds) * time.Second)
+	defer deadline.Stop()
+	ticker := time.NewTicker(tailPollInterval)
+	defer ticker.Stop()
+
+	received := 0
+	stopped := ""
+	for stopped == "" {
+		select {
+		case <-ctx.Done():
+			stopped = "cancelled"
+			continue
+		case <-deadline.C:
+			stopped = "duration elapsed"
+			continue
+		case <-ticker.C:
+		}
+
+		newLines, event := f.poll(reg)
+		if event != "" {
+			notifyFollowers(ctx, map[string]any{"path": resolvedPath, "event": event})
+			fmt.Fprintf(&out, "--- %s ---\n", event)
+		}
+		if len(newLines) == 0 {
+			continue
+		}
+		if received+len(newLines) >= maxLines {
+			newLines = newLines[:maxLines-received]
+			stopped = "line limit reached"
+		}
+		received += len(newLines)
+		notifyFollowers(ctx, map[string]any{"path": resolvedPath, "lines": newLines})
+		for _, line := range newLines {
+			out.WriteString(line)
+			out.WriteString("\n")
+		}
+	}
+
+	fmt.Fprintf(&out, "--- stopped after %s (%s): %d new lines ---", time.Since(start).Round(time.Second), stopped, received)
+	return mcp.NewToolResultText(out.String()), nil
+}
+
+// followedFile is a file being followed and how far it has been read.
+type followedFile struct {
+	// path is the path to validate again on each poll
+	path    string
+	file    *os.File
+	info    os.FileInfo
+	offset  int64
+	partial []byte
+}
+
+// openFollowed opens resolvedPath for following from its current end.
+func openFollowed(reg *registry.Registry, resolvedPath string) (*followedFile, error) {
+	target, err := readTarget(reg, resolvedPath)
+	if err != nil {
+		return nil, fmt.Errorf("path validation failed: %w", err)
+	}
+	file, err := os.Open(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		file.Close()
+		return nil, fmt.Errorf("path is not a regular file")
+	}
+	return &followedFile{path: resolvedPath, file: file, info: info, offset: info.Size()}, nil
+}
+
+func (f *followedFile) close() {
+	f.file.Close()
+}
+
+// poll returns the complete lines appended since the last poll, and an
+// event if the file was truncated or replaced. A file that has gone
+// missing is waited for.
+func (f *followedFile) poll(reg *registry.Registry) ([]string, string) {
+	var lines []string
+	event := ""
+
+	// Validate again, since a file put in place by rotation could be a
+	// symlink leading elsewhere
+	resolvedPath, err := reg.Validate(f.path)
+	var info os.FileInfo
+	if err == nil {
+		info, err = statTarget(reg, resolvedPath)
+	}
+	switch {
+	case err != nil:
+		// Rotated away and not yet recreated; keep reading what was
+		// written to the old file until the new one appears
+	case !os.SameFile(f.info, info):
+		lines = f.read()
+		if next, err := openFollowed(reg, resolvedPath); err == nil {
+			f.file.Close()
+			next.path = f.path
+			next.offset = 0
+			*f = *next
+			event = "file replaced; following the new file"
+		}
+	case info.Size() < f.offset:
+		f.offset = 0
+		f.partial = nil
+		event = "file truncated"
+	}
+	return append(lines, f.read()...), event
+}
+
+// read returns the complete lines written to the open file since the last
+// read, keeping a trailing partial line for next time.
+func (f *followedFile) read() []string {
+	data, err := io.ReadAll(io.NewSectionReader(f.file, f.offset, maxFollowReadBytes))
+	if err != nil || len(data) == 0 {
+		return nil
+	}
+	f.offset += int64(len(data))
+	data = append(f.partial, data...)
+
+	var lines []string
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		lines = append(lines, followedLine(data[:i]))
+		data = data[i+1:]
+	}
+	if len(data) > maxFollowLineBytes {
+		lines = append(lines, followedLine(data))
+		data = nil
+	}
+	f.partial = append([]byte(nil), data...)
+	return lines
+}
+
+func followedLine(b []byte) string {
+	return strings.ToValidUTF8(string(bytes.TrimSuffix(b, []byte("\r"))), "�")
+}
+
+// notifyFollowers sends data to the client as a log message notification
+// from tail_follow. Notifications are best effort.
+func notifyFollowers(ctx context.Context, data map[string]any) {
+	srv := server.ServerFromContext(ctx)
+	if srv == nil {
+		return
+	}
+	_ = srv.SendNotificationToClient(ctx, "notifications/message", map[string]any{
+		"level":  "info",
+		"logger": "tail_follow",
+		"data":   data,
+	})
+}
@@ -0,0 +1,92 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestTailFollow(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	path := filepath.Join(tmpDir, "build.log")
+	os.WriteFile(path, []byte("old 1\nold 2\n"), 0644)
+
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+		f.WriteString("new 1\nnew ")
+		f.Close()
+		time.Sleep(300 * time.Millisecond)
+		f, _ = os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+		f.WriteString("2\nnew 3\n")
+		f.Close()
+	}()
+
+	result := callTool(t, HandleTailFollow, reg, map[string]any{"path": path, "lines": 1, "duration": 5, "maxLines": 3})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	text := resultText(result)
+	if strings.Contains(text, "old 1") || !strings.Contains(text, "old 2") {
+		t.Errorf("expected only the last existing line, got:\n%s", text)
+	}
+	if !strings.Contains(text, "new 1\nnew 2\nnew 3\n") || !strings.Contains(text, "line limit reached") {
+		t.Errorf("expected the appended lines, got:\n%s", text)
+	}
+}
+
+func TestTailFollowRotation(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	path := filepath.Join(tmpDir, "app.log")
+	os.WriteFile(path, []byte("before\n"), 0644)
+
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		os.Rename(path, path+".1")
+		os.WriteFile(path, []byte("after\n"), 0644)
+	}()
+
+	result := callTool(t, HandleTailFollow, reg, map[string]any{"path": path, "lines": 0, "duration": 5, "maxLines": 1})
+	text := resultText(result)
+	if !strings.Contains(text, "file replaced") || !strings.Contains(text, "after\n") {
+		t.Errorf("expected the new file to be followed, got:\n%s", text)
+	}
+}
+
+func TestTailFollowCancelled(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	path := filepath.Join(tmpDir, "quiet.log")
+	os.WriteFile(path, nil, 0644)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"path": path, "duration": 60}
+	start := time.Now()
+	result, err := HandleTailFollow(ctx, reg, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(start) > 5*time.Second || !strings.Contains(resultText(result), "cancelled") {
+		t.Errorf("expected following to stop when cancelled, got %s", resultText(result))
+	}
+}
+
+func TestTailFollowInvalid(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	for _, args := range []map[string]any{
+		{"path": tmpDir},
+		{"path": "/etc/passwd"},
+		{"path": filepath.Join(tmpDir, "missing.log")},
+		{"path": filepath.Join(tmpDir, "x"), "duration": maxFollowSeconds + 1},
+	} {
+		if result := callTool(t, HandleTailFollow, reg, args); !result.IsError {
+			t.Errorf("%v: expected an error, got %s", args, resultText(result))
+		}
+	}
+}
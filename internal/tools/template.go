@@ -0,0 +1,161 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/spf13/cast"
+)
+
+// placeholderPattern matches {{key}}, allowing spaces inside the braces.
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_.-]*)\s*\}\}`)
+
+// substitutionKeyPattern matches a valid placeholder key.
+var substitutionKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// withSubstitutionsParam adds the substitutions parameter shared by the
+// tools that write templated content.
+func withSubstitutionsParam() mcp.ToolOption {
+	return mcp.WithObject("substitutions",
+		mcp.Description("Values for {{key}} placeholders in the content, as an object of key to string. Placeholders without a value are left as they are"),
+		mcp.AdditionalProperties(map[string]any{"type": "string"}),
+	)
+}
+
+// parseSubstitutions reads the substitutions argument.
+func parseSubstitutions(arg any) (map[string]string, error) {
+	if arg == nil {
+		return nil, nil
+	}
+	raw, ok := arg.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("substitutions must be an object, got %T", arg)
+	}
+	subs := make(map[string]string, len(raw))
+	for key, value := range raw {
+		if !substitutionKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("invalid substitution key %q", key)
+		}
+		s, err := cast.ToStringE(value)
+		if err != nil {
+			return nil, fmt.Errorf("substitution %q must be a string", key)
+		}
+		subs[key] = s
+	}
+	return subs, nil
+}
+
+// substitute replaces the placeholders in content that have a value in subs.
+// Replacement is a single pass, so values are never expanded themselves. It
+// also returns how many placeholders were replaced and the sorted keys of
+// those left without a value.
+func substitute(content string, subs map[string]string) (string, int, []string) {
+	replaced := 0
+	missing := make(map[string]bool)
+	result := placeholderPattern.ReplaceAllStringFunc(content, func(match string) string {
+		key := placeholderPattern.FindStringSubmatch(match)[1]
+		value, ok := subs[key]
+		if !ok {
+			missing[key] = true
+			return match
+		}
+		replaced++
+		return value
+	})
+	unresolved := make([]string, 0, len(missing))
+	for key := range missing {
+		unresolved = append(unresolved, key)
+	}
+	sort.Strings(unresolved)
+	return result, replaced, unresolved
+}
+
+// substitutionNote describes the outcome of substitute for a tool result.
+func substitutionNote(replaced int, unresolved []string) string {
+	note := fmt.Sprintf(" (%d placeholders substituted)", replaced)
+	if len(unresolved) > 0 {
+		note += fmt.Sprintf("; no value for: %s", strings.Join(unresolved, ", "))
+	}
+	return note
+}
+
+// NewCreateFromTemplateTool creates the create_from_template tool.
+func NewCreateFromTemplateTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"create_from_template",
+		mcp.WithDescription("Create a file from a template file, replacing {{key}} placeholders with the given substitutions. Keeps boilerplate on disk so requests only carry the values that change. Creates parent directories if needed. Uses atomic write."),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Create From Template",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(true),
+			IdempotentHint:  boolPtr(true),
+		}),
+		mcp.WithString("template", mcp.Description("Path to the template file"), mcp.Required()),
+		mcp.WithString("path", mcp.Description("Path to the file to create"), mcp.Required()),
+		withSubstitutionsParam(),
+		mcp.WithBoolean("overwrite", mcp.Description("Replace the file if it already exists (default: false)")),
+	)
+}
+
+// HandleCreateFromTemplate handles the create_from_template tool.
+func HandleCreateFromTemplate(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	template := cast.ToString(request.Params.Arguments["template"])
+	path := cast.ToString(request.Params.Arguments["path"])
+	overwrite := cast.ToBool(request.Params.Arguments["overwrite"])
+
+	subs, err := parseSubstitutions(request.Params.Arguments["substitutions"])
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	templatePath, err := validateRead(reg, template)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("template validation failed: %w", err).Error()), nil
+	}
+	data, _, err := readWholeFile(reg, templatePath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to read template: %w", err).Error()), nil
+	}
+
+	resolvedPath, err := reg.ValidateForCreation(path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	if !overwrite {
+		if _, err := statTarget(reg, resolvedPath); err == nil {
+			return mcp.NewToolResultError(fmt.Sprintf("%s already exists; set overwrite to replace it", resolvedPath)), nil
+		}
+	}
+
+	target, allowedDirs, err := writeTarget(reg, resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to write file: %w", err).Error()), nil
+	}
+	if reg.Overlay() == nil {
+		if err := safeMkdirAll(filepath.Dir(resolvedPath), reg.Modes().Dir, reg.Get()); err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to create directories: %w", err).Error()), nil
+		}
+	}
+
+	// A replaced file keeps its mode
+	perm := reg.Modes().File
+	if info, err := os.Stat(target); err == nil {
+		perm = info.Mode().Perm()
+	}
+	content, replaced, unresolved := substitute(string(data), subs)
+	if err := atomicWriteFile(target, []byte(content), perm, allowedDirs); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to write file: %w", err).Error()), nil
+	}
+	if store := reg.Shadow(); store != nil {
+		store.Record(target, []byte(content))
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Created %s from %s%s", resolvedPath, templatePath, substitutionNote(replaced, unresolved))), nil
+}
@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSubstitute(t *testing.T) {
+	got, replaced, unresolved := substitute("{{a}} {{ b }} {{a}} {{c}} {{ not a key }}", map[string]string{"a": "{{b}}", "b": "2"})
+	if want := "{{b}} 2 {{b}} {{c}} {{ not a key }}"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if replaced != 3 {
+		t.Errorf("expected 3 replacements, got %d", replaced)
+	}
+	if !reflect.DeepEqual(unresolved, []string{"c"}) {
+		t.Errorf("expected [c] unresolved, got %v", unresolved)
+	}
+}
+
+func TestHandleCreateFromTemplate(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	template := filepath.Join(tmpDir, "templates", "service.yaml")
+	if err := os.MkdirAll(filepath.Dir(template), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(template, []byte("name: {{name}}\nreplicas: {{replicas}}\nimage: {{image}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(tmpDir, "deploy", "api.yaml")
+
+	args := map[string]any{
+		"template":      template,
+		"path":          path,
+		"substitutions": map[string]any{"name": "api", "replicas": 3},
+	}
+	result := callTool(t, HandleCreateFromTemplate, reg, args)
+	if result.IsError {
+		t.Fatalf("create_from_template failed: %s", resultText(result))
+	}
+	if !strings.Contains(resultText(result), "no value for: image") {
+		t.Errorf("expected unresolved placeholder to be reported, got %q", resultText(result))
+	}
+	data, _ := os.ReadFile(path)
+	if want := "name: api\nreplicas: 3\nimage: {{image}}\n"; string(data) != want {
+		t.Errorf("got %q, want %q", string(data), want)
+	}
+
+	result = callTool(t, HandleCreateFromTemplate, reg, args)
+	if !result.IsError || !strings.Contains(resultText(result), "already exists") {
+		t.Errorf("expected existing file to be refused, got %q", resultText(result))
+	}
+
+	os.Chmod(path, 0600)
+	args["overwrite"] = true
+	args["substitutions"] = map[string]any{"name": "web", "replicas": "1", "image": "nginx"}
+	result = callTool(t, HandleCreateFromTemplate, reg, args)
+	if result.IsError {
+		t.Fatalf("overwrite failed: %s", resultText(result))
+	}
+	data, _ = os.ReadFile(path)
+	if want := "name: web\nreplicas: 1\nimage: nginx\n"; string(data) != want {
+		t.Errorf("got %q, want %q", string(data), want)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("expected the replaced file to keep its mode, got %v", info.Mode())
+	}
+
+	result = callTool(t, HandleCreateFromTemplate, reg, map[string]any{"template": "/etc/passwd", "path": path, "overwrite": true})
+	if !result.IsError {
+		t.Error("expected template outside allowed directories to be refused")
+	}
+}
@@ -0,0 +1,135 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/security"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/spf13/cast"
+)
+
+// NewTouchFileTool creates the touch_file tool.
+func NewTouchFileTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"touch_file",
+		mcp.WithDescription("Create an empty file if it does not exist, or set the access and modification times of an existing file or directory. The parent directory must exist. Existing content is never changed."),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Touch File",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  boolPtr(false),
+		}),
+		mcp.WithString("path", mcp.Description("Path to the file to create or touch"), mcp.Required()),
+		mcp.WithString("timestamp", mcp.Description("Time to set as RFC 3339, e.g. 2024-01-02T15:04:05Z (default: now)")),
+	)
+}
+
+// HandleTouchFile handles the touch_file tool.
+func HandleTouchFile(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	timestamp := cast.ToString(request.Params.Arguments["timestamp"])
+
+	t := time.Now()
+	if timestamp != "" {
+		parsed, err := time.Parse(time.RFC3339, timestamp)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid timestamp %q: expected RFC 3339", timestamp)), nil
+		}
+		t = parsed
+	}
+
+	resolvedPath, err := reg.ValidateForCreation(path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+
+	if reg.Overlay() != nil {
+		// Staged files are rewritten when committed, so only the commit
+		// time can reach the real tree
+		if timestamp != "" {
+			return mcp.NewToolResultError("touch_file cannot set an explicit timestamp in overlay mode"), nil
+		}
+		return touchInOverlay(reg, resolvedPath)
+	}
+
+	if err := reg.CheckWrite(resolvedPath); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to touch file: %w", err).Error()), nil
+	}
+
+	created, err := createEmptyFile(resolvedPath, reg.Modes().File, reg.Get())
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to touch file: %w", err).Error()), nil
+	}
+	if err := os.Chtimes(resolvedPath, t, t); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to set file times: %w", err).Error()), nil
+	}
+
+	if created {
+		return mcp.NewToolResultText(fmt.Sprintf("Created empty file %s with timestamp %s", resolvedPath, t.Format(time.RFC3339))), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Set timestamps of %s to %s", resolvedPath, t.Format(time.RFC3339))), nil
+}
+
+// createEmptyFile creates an empty file at path unless something already
+// exists there, and reports whether it did.
+func createEmptyFile(path string, perm os.FileMode, allowedDirs []string) (bool, error) {
+	if _, err := os.Stat(path); err == nil {
+		return false, nil
+	}
+	if _, err := security.ValidateFinalPathForCreation(path, allowedDirs); err != nil {
+		return false, fmt.Errorf("path validation failed: %w", err)
+	}
+	// O_EXCL so a file created concurrently, or a planted symlink, is not
+	// opened
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if errors.Is(err, os.ErrExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, f.Close()
+}
+
+// touchInOverlay stages an empty file, or a copy of the existing one, so the
+// path is written when the overlay is committed.
+func touchInOverlay(reg *registry.Registry, resolvedPath string) (*mcp.CallToolResult, error) {
+	ov := reg.Overlay()
+	source, err := ov.ReadPath(resolvedPath)
+	exists := false
+	if err == nil {
+		info, statErr := os.Stat(source)
+		exists = statErr == nil
+		if exists && info.IsDir() {
+			return mcp.NewToolResultError("touch_file cannot touch a directory in overlay mode"), nil
+		}
+	}
+
+	target, allowedDirs, err := writeTarget(reg, resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to touch file: %w", err).Error()), nil
+	}
+	switch {
+	case !exists:
+		if _, err := createEmptyFile(target, reg.Modes().File, allowedDirs); err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to touch file: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Staged empty file %s", resolvedPath)), nil
+	case source != target:
+		if err := stream.CopyFileStreaming(source, target); err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to touch file: %w", err).Error()), nil
+		}
+	default:
+		now := time.Now()
+		if err := os.Chtimes(target, now, now); err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to set file times: %w", err).Error()), nil
+		}
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Staged touch of %s", resolvedPath)), nil
+}
@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandleTouchFile(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	path := filepath.Join(tmpDir, "marker")
+
+	result := callTool(t, HandleTouchFile, reg, map[string]any{"path": path})
+	if result.IsError {
+		t.Fatalf("touch failed: %s", resultText(result))
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Size() != 0 {
+		t.Fatalf("expected an empty file, got %v, %v", info, err)
+	}
+
+	if err := os.WriteFile(path, []byte("keep"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result = callTool(t, HandleTouchFile, reg, map[string]any{"path": path, "timestamp": "2020-01-02T03:04:05Z"})
+	if result.IsError {
+		t.Fatalf("touch failed: %s", resultText(result))
+	}
+	info, _ = os.Stat(path)
+	if want := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC); !info.ModTime().Equal(want) {
+		t.Errorf("expected mtime %s, got %s", want, info.ModTime())
+	}
+	if data, _ := os.ReadFile(path); string(data) != "keep" {
+		t.Errorf("touch should not change content, got %q", string(data))
+	}
+
+	result = callTool(t, HandleTouchFile, reg, map[string]any{"path": path, "timestamp": "yesterday"})
+	if !result.IsError || !strings.Contains(resultText(result), "invalid timestamp") {
+		t.Errorf("expected invalid timestamp error, got %s", resultText(result))
+	}
+
+	result = callTool(t, HandleTouchFile, reg, map[string]any{"path": filepath.Join(tmpDir, "missing", "marker")})
+	if !result.IsError {
+		t.Error("expected touch in a missing directory to fail")
+	}
+
+	result = callTool(t, HandleTouchFile, reg, map[string]any{"path": filepath.Join(t.TempDir(), "outside")})
+	if !result.IsError {
+		t.Error("expected touch outside allowed directories to fail")
+	}
+}
+
+func TestHandleTouchFileOverlay(t *testing.T) {
+	reg, tmpDir := setupOverlayRegistry(t)
+	path := filepath.Join(tmpDir, "marker")
+
+	result := callTool(t, HandleTouchFile, reg, map[string]any{"path": path})
+	if result.IsError {
+		t.Fatalf("touch failed: %s", resultText(result))
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("touched file should not exist in the real tree before commit")
+	}
+	result = callTool(t, HandleOverlayStatus, reg, map[string]any{})
+	if !strings.Contains(resultText(result), "[ADDED] "+path) {
+		t.Errorf("expected touched file to be staged, got %s", resultText(result))
+	}
+
+	result = callTool(t, HandleTouchFile, reg, map[string]any{"path": path, "timestamp": "2020-01-02T03:04:05Z"})
+	if !result.IsError {
+		t.Error("expected explicit timestamp to be refused in overlay mode")
+	}
+}
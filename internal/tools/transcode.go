@@ -0,0 +1,186 @@
+package tools
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/spf13/cast"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// textEncodingAliases maps the names the encoding parameter accepts to the
+// encodings read_text_file and write_file transcode.
+var textEncodingAliases = map[string]string{
+	"utf-8":        "utf-8",
+	"utf8":         "utf-8",
+	"utf-16le":     "utf-16le",
+	"utf-16be":     "utf-16be",
+	"latin-1":      "latin-1",
+	"latin1":       "latin-1",
+	"iso-8859-1":   "latin-1",
+	"windows-1252": "windows-1252",
+	"cp1252":       "windows-1252",
+}
+
+// textCodecs maps the encodings other than UTF-8 to their codecs. Byte
+// order marks are handled by decodeText and encodeText, so the UTF-16
+// codecs ignore them.
+var textCodecs = map[string]encoding.Encoding{
+	"utf-16le":     unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM),
+	"utf-16be":     unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM),
+	"latin-1":      charmap.ISO8859_1,
+	"windows-1252": charmap.Windows1252,
+}
+
+// withEncodingParam adds the encoding parameter of read_text_file and
+// write_file.
+func withEncodingParam(description string) mcp.ToolOption {
+	return mcp.WithString("encoding", mcp.Description(description+": 'utf-8' (default), 'utf-16le', 'utf-16be', 'latin-1', or 'windows-1252'"))
+}
+
+// parseTextEncoding returns the encoding named by the encoding argument,
+// utf-8 if there is none.
+func parseTextEncoding(request mcp.CallToolRequest) (string, error) {
+	name := strings.ToLower(cast.ToString(request.Params.Arguments["encoding"]))
+	if name == "" {
+		return "utf-8", nil
+	}
+	enc, ok := textEncodingAliases[name]
+	if !ok {
+		return "", fmt.Errorf("invalid encoding %q: expected utf-8, utf-16le, utf-16be, latin-1, or windows-1252", name)
+	}
+	return enc, nil
+}
+
+// encodingBOM returns the byte order mark of enc, or nil if it has none.
+func encodingBOM(enc string) []byte {
+	switch enc {
+	case "utf-8":
+		return utf8BOM
+	case "utf-16le":
+		return utf16LEBOM
+	case "utf-16be":
+		return utf16BEBOM
+	}
+	return nil
+}
+
+// decodeText decodes data from enc to a UTF-8 string, dropping a leading
+// byte order mark for enc and reporting whether there was one. UTF-16 with
+// the byte order mark of the other endianness is refused, since it would
+// decode to garbage.
+func decodeText(data []byte, enc string) (string, bool, error) {
+	bom := encodingBOM(enc)
+	hasBOM := bom != nil && bytes.HasPrefix(data, bom)
+	if hasBOM {
+		data = data[len(bom):]
+	}
+
+	switch enc {
+	case "utf-8":
+		return string(data), hasBOM, nil
+	case "utf-16le", "utf-16be":
+		other := utf16BEBOM
+		if enc == "utf-16be" {
+			other = utf16LEBOM
+		}
+		if !hasBOM && bytes.HasPrefix(data, other) {
+			return "", false, fmt.Errorf("file starts with the byte order mark of the other UTF-16 byte order, not %s", enc)
+		}
+		if len(data)%2 != 0 {
+			return "", false, fmt.Errorf("file has an odd number of bytes, so it is not %s", enc)
+		}
+	}
+	decoded, err := textCodecs[enc].NewDecoder().Bytes(data)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to decode %s: %w", enc, err)
+	}
+	return string(decoded), hasBOM, nil
+}
+
+// encodeText encodes s in enc, starting with enc's byte order mark if bom
+// is set. A character enc cannot represent is an error naming its line.
+func encodeText(s string, enc string, bom bool) ([]byte, error) {
+	var out []byte
+	if bom {
+		out = append(out, encodingBOM(enc)...)
+	}
+
+	switch enc {
+	case "utf-8":
+		return append(out, s...), nil
+	case "latin-1", "windows-1252":
+		// Encoded a rune at a time, so that the error can name the line
+		cm := textCodecs[enc].(*charmap.Charmap)
+		line := 1
+		for _, r := range s {
+			c, ok := cm.EncodeRune(r)
+			if !ok {
+				return nil, fmt.Errorf("character %q on line %d cannot be encoded in %s", r, line, enc)
+			}
+			if r == '\n' {
+				line++
+			}
+			out = append(out, c)
+		}
+		return out, nil
+	}
+	encoded, err := textCodecs[enc].NewEncoder().Bytes([]byte(s))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", enc, err)
+	}
+	return append(out, encoded...), nil
+}
+
+// selectLines applies read_text_file's head, tail, start_line, end_line, and
+// line_numbers options to already decoded text, numbering lines the way
+// the streaming readers do.
+func selectLines(content string, head, tail, startLine, endLine int, lineNumbers bool) string {
+	if head <= 0 && tail <= 0 && startLine <= 0 && endLine <= 0 && !lineNumbers {
+		return content
+	}
+	lines := strings.Split(content, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+
+	first, last, width := 1, len(lines), len(lines)
+	switch {
+	case startLine > 0 || endLine > 0:
+		// Ranges are always numbered
+		lineNumbers = true
+		first = max(startLine, 1)
+		if endLine > 0 {
+			last, width = min(endLine, len(lines)), endLine
+		}
+	case head > 0:
+		last = min(head, len(lines))
+		if lineNumbers {
+			width = head
+		}
+	case tail > 0:
+		first = max(len(lines)-tail+1, 1)
+	}
+	if first > last {
+		return ""
+	}
+
+	selected := lines[first-1 : last]
+	if !lineNumbers {
+		return strings.Join(selected, "\n")
+	}
+	format := fmt.Sprintf("%%%dd | %%s", len(strconv.Itoa(max(width, 1))))
+	numbered := make([]string, len(selected))
+	for i, line := range selected {
+		numbered[i] = fmt.Sprintf(format, first+i, line)
+	}
+	return strings.Join(numbered, "\n")
+}
@@ -0,0 +1,139 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEncodeDecodeText(t *testing.T) {
+	tests := []struct {
+		enc  string
+		text string
+		bom  bool
+		want string
+	}{
+		{"utf-8", "café", true, "\xEF\xBB\xBFcaf\xC3\xA9"},
+		{"utf-16le", "hé😀", false, "h\x00\xe9\x00\x3d\xd8\x00\xde"},
+		{"utf-16be", "hi", true, "\xFE\xFF\x00h\x00i"},
+		{"latin-1", "café", false, "caf\xe9"},
+		{"windows-1252", "“café” – €", false, "\x93caf\xe9\x94 \x96 \x80"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.enc, func(t *testing.T) {
+			data, err := encodeText(tt.text, tt.enc, tt.bom)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("encodeText() = %q, want %q", data, tt.want)
+			}
+			text, bom, err := decodeText(data, tt.enc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if text != tt.text || bom != tt.bom {
+				t.Errorf("decodeText() = %q, %v, want %q, %v", text, bom, tt.text, tt.bom)
+			}
+		})
+	}
+
+	if _, err := encodeText("a\n€", "latin-1", false); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected an error naming line 2, got %v", err)
+	}
+	if _, _, err := decodeText([]byte("\xFE\xFF\x00h"), "utf-16le"); err == nil {
+		t.Error("expected a big-endian BOM to be refused for utf-16le")
+	}
+	if _, _, err := decodeText([]byte("h\x00i"), "utf-16le"); err == nil {
+		t.Error("expected an odd number of bytes to be refused")
+	}
+}
+
+func TestSelectLines(t *testing.T) {
+	content := "one\r\ntwo\nthree\nfour\n"
+	tests := []struct {
+		name                   string
+		head, tail, start, end int
+		lineNumbers            bool
+		want                   string
+	}{
+		{name: "whole", want: content},
+		{name: "head", head: 2, want: "one\ntwo"},
+		{name: "tail", tail: 2, want: "three\nfour"},
+		{name: "numbered tail", tail: 1, lineNumbers: true, want: "4 | four"},
+		{name: "range", start: 2, end: 3, want: "2 | two\n3 | three"},
+		{name: "range past the end", start: 3, end: 10, want: " 3 | three\n 4 | four"},
+		{name: "start past the end", start: 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := selectLines(content, tt.head, tt.tail, tt.start, tt.end, tt.lineNumbers); got != tt.want {
+				t.Errorf("selectLines() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadWriteEncoding(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	path := filepath.Join(tmpDir, "windows.txt")
+
+	result := callTool(t, HandleWriteFile, reg, map[string]any{"path": path, "content": "line one\r\nline two\r\n", "encoding": "utf-16le", "bom": true})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(data), "\xFF\xFEl\x00i\x00") {
+		t.Errorf("unexpected bytes %q", data)
+	}
+
+	result = callTool(t, HandleReadTextFile, reg, map[string]any{"path": path, "encoding": "UTF-16LE"})
+	if result.IsError || resultText(result) != "line one\r\nline two\r\n" {
+		t.Errorf("unexpected read: %q", resultText(result))
+	}
+	result = callTool(t, HandleReadTextFile, reg, map[string]any{"path": path, "encoding": "utf-16le", "tail": 1})
+	if resultText(result) != "line two" {
+		t.Errorf("unexpected tail: %q", resultText(result))
+	}
+	if result := callTool(t, HandleReadTextFile, reg, map[string]any{"path": path}); !result.IsError {
+		t.Error("expected UTF-16 read as UTF-8 to be refused as binary")
+	}
+
+	// Overwriting with an encoding and no bom keeps the existing BOM
+	callTool(t, HandleWriteFile, reg, map[string]any{"path": path, "content": "new", "encoding": "utf-16le"})
+	if data, _ := os.ReadFile(path); string(data) != "\xFF\xFEn\x00e\x00w\x00" {
+		t.Errorf("expected the BOM to be kept, got %q", data)
+	}
+	callTool(t, HandleWriteFile, reg, map[string]any{"path": path, "content": "new", "encoding": "utf-16le", "bom": false})
+	if data, _ := os.ReadFile(path); string(data) != "n\x00e\x00w\x00" {
+		t.Errorf("expected the BOM to be dropped, got %q", data)
+	}
+
+	for name, args := range map[string]map[string]any{
+		"unknown encoding": {"path": path, "content": "x", "encoding": "ebcdic"},
+		"unencodable":      {"path": path, "content": "日本", "encoding": "latin-1"},
+		"bom without one":  {"path": path, "content": "x", "encoding": "latin-1", "bom": true},
+	} {
+		if result := callTool(t, HandleWriteFile, reg, args); !result.IsError {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestReadUTF8BOM(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	path := filepath.Join(tmpDir, "bom.txt")
+	if err := os.WriteFile(path, []byte("\xEF\xBB\xBFone\ntwo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := callTool(t, HandleReadTextFile, reg, map[string]any{"path": path, "encoding": "utf-8"})
+	if result.IsError || resultText(result) != "one\ntwo\n" {
+		t.Errorf("expected the BOM to be removed, got %q", resultText(result))
+	}
+	result = callTool(t, HandleReadTextFile, reg, map[string]any{"path": path, "encoding": "utf8", "head": 1})
+	if result.IsError || resultText(result) != "one" {
+		t.Errorf("expected the BOM to be removed from a partial read, got %q", resultText(result))
+	}
+}
@@ -0,0 +1,212 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/security"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/portertech/filesystem-mcp-server/internal/trash"
+	"github.com/spf13/cast"
+)
+
+// NewListTrashTool creates the list_trash tool.
+func NewListTrashTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"list_trash",
+		mcp.WithDescription("List the files and directories that delete_file and delete_directory moved to the trash, newest first, with the ID restore_from_trash and empty_trash take."),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "List Trash",
+			ReadOnlyHint: boolPtr(true),
+		}),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleListTrash handles the list_trash tool.
+func HandleListTrash(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	entries, err := reg.Trash().List(reg.GetResolved())
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to list trash: %w", err).Error()), nil
+	}
+
+	if format == "json" {
+		if entries == nil {
+			entries = []trash.Entry{}
+		}
+		data, _ := json.MarshalIndent(entries, "", "  ")
+		return mcp.NewToolResultText(string(data)), nil
+	}
+
+	if len(entries) == 0 {
+		return mcp.NewToolResultText("The trash is empty"), nil
+	}
+	var text strings.Builder
+	for _, e := range entries {
+		path := e.Path
+		if e.Dir {
+			path += string(filepath.Separator)
+		}
+		fmt.Fprintf(&text, "%s  %s  %s  %s\n", e.ID, e.Deleted.Local().Format("2006-01-02 15:04"), stream.FormatSize(e.Size), path)
+	}
+	return mcp.NewToolResultText(strings.TrimSuffix(text.String(), "\n")), nil
+}
+
+// NewRestoreFromTrashTool creates the restore_from_trash tool.
+func NewRestoreFromTrashTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"restore_from_trash",
+		mcp.WithDescription("Move a file or directory out of the trash, back to where it was deleted from or to another path. Refuses to replace anything that exists."),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Restore From Trash",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  boolPtr(false),
+		}),
+		mcp.WithString("id", mcp.Description("ID of the trash entry, from list_trash"), mcp.Required()),
+		mcp.WithString("destination", mcp.Description("Path to restore to (default: where it was deleted from); parent directories are created")),
+	)
+}
+
+// HandleRestoreFromTrash handles the restore_from_trash tool.
+func HandleRestoreFromTrash(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	id := cast.ToString(request.Params.Arguments["id"])
+	destination := cast.ToString(request.Params.Arguments["destination"])
+
+	t := reg.Trash()
+	roots := reg.GetResolved()
+	e, err := t.Find(roots, id)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to restore %s: %w", id, err).Error()), nil
+	}
+	if destination == "" {
+		destination = e.Path
+	}
+
+	resolvedDst, err := reg.ValidateForCreation(destination)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("destination path validation failed: %w", err).Error()), nil
+	}
+	if err := security.ValidateNoSymlinksInPath(reg.ExpandPath(destination), reg.Get()); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("destination path validation failed: %w", err).Error()), nil
+	}
+	if inTrash(reg, resolvedDst) {
+		return mcp.NewToolResultError("cannot restore into the trash"), nil
+	}
+	for _, p := range []string{e.Root, resolvedDst} {
+		if err := reg.CheckWrite(p); err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to restore %s: %w", id, err).Error()), nil
+		}
+	}
+	if err := safeMkdirAll(filepath.Dir(resolvedDst), reg.Modes().Dir, reg.Get()); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to create directories: %w", err).Error()), nil
+	}
+	if _, err := security.ValidateFinalPathForCreation(resolvedDst, reg.Get()); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("destination path validation failed: %w", err).Error()), nil
+	}
+
+	if _, err := t.Restore(roots, id, resolvedDst); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to restore %s: %w", id, err).Error()), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Restored %s to %s", id, resolvedDst)), nil
+}
+
+// NewEmptyTrashTool creates the empty_trash tool.
+func NewEmptyTrashTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"empty_trash",
+		mcp.WithDescription("Permanently delete entries from the trash: the given IDs, those deleted longer ago than olderThan, or, with neither, everything. This cannot be undone."),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Empty Trash",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(true),
+			IdempotentHint:  boolPtr(true),
+		}),
+		mcp.WithArray("ids", mcp.Description("IDs of the trash entries to delete, from list_trash"), mcp.Items(map[string]any{"type": "string"})),
+		mcp.WithString("olderThan", mcp.Description("Only delete entries deleted longer ago than this, e.g. '36h', '7d', or '2w'")),
+		withConfirmationToken(),
+		withApprovalToken(),
+	)
+}
+
+// HandleEmptyTrash handles the empty_trash tool.
+func HandleEmptyTrash(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ids := cast.ToStringSlice(request.Params.Arguments["ids"])
+	olderThan := cast.ToString(request.Params.Arguments["olderThan"])
+
+	var cutoff time.Time
+	if olderThan != "" {
+		age, err := parseAge(olderThan)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("invalid olderThan: %w", err).Error()), nil
+		}
+		cutoff = time.Now().Add(-age)
+	}
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	selected := func(e trash.Entry) bool {
+		if len(wanted) > 0 && !wanted[e.ID] {
+			return false
+		}
+		return cutoff.IsZero() || e.Deleted.Before(cutoff)
+	}
+
+	t := reg.Trash()
+	roots := reg.GetResolved()
+	entries, err := t.List(roots)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to list trash: %w", err).Error()), nil
+	}
+	var paths []string
+	found := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		found[e.ID] = true
+		if selected(e) {
+			paths = append(paths, e.Path)
+		}
+	}
+	for _, id := range ids {
+		if !found[id] {
+			return mcp.NewToolResultError(fmt.Errorf("failed to empty trash: %s: %w", id, trash.ErrNotFound).Error()), nil
+		}
+	}
+	if len(paths) == 0 {
+		return mcp.NewToolResultText("Nothing to delete from the trash"), nil
+	}
+
+	action := fmt.Sprintf("permanently delete %d entries from the trash", len(paths))
+	if isProtected(reg, paths...) {
+		if result := requireApproval(reg, "empty_trash", request, action, paths...); result != nil {
+			return result, nil
+		}
+	} else if result := requireConfirmation(reg, "empty_trash", request, action); result != nil {
+		return result, nil
+	}
+	for _, e := range entries {
+		if selected(e) {
+			if err := reg.CheckWrite(e.Root); err != nil {
+				return mcp.NewToolResultError(fmt.Errorf("failed to empty trash: %w", err).Error()), nil
+			}
+		}
+	}
+
+	removed, err := t.Remove(roots, selected)
+	var size int64
+	for _, e := range removed {
+		size += e.Size
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to empty trash after deleting %d entries: %w", len(removed), err).Error()), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Permanently deleted %d entries (%s) from the trash", len(removed), stream.FormatSize(size))), nil
+}
@@ -0,0 +1,102 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/portertech/filesystem-mcp-server/internal/retention"
+	"github.com/portertech/filesystem-mcp-server/internal/trash"
+)
+
+func TestDeleteToTrash(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	reg.SetTrash(trash.New(retention.Policy{}, nil))
+	file := filepath.Join(tmpDir, "notes.txt")
+	os.WriteFile(file, []byte("keep me"), 0644)
+	dir := filepath.Join(tmpDir, "build")
+	os.MkdirAll(filepath.Join(dir, "out"), 0755)
+	os.WriteFile(filepath.Join(dir, "out", "a.o"), []byte("obj"), 0644)
+
+	if result := callTool(t, HandleDeleteFile, reg, map[string]any{"path": file}); result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if result := callTool(t, HandleDeleteDirectory, reg, map[string]any{"path": dir}); !result.IsError {
+		t.Error("expected a non-empty directory to need recursive=true")
+	}
+	if result := callTool(t, HandleDeleteDirectory, reg, map[string]any{"path": dir, "recursive": true}); result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	for _, p := range []string{file, dir} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be moved to the trash", p)
+		}
+	}
+
+	result := callTool(t, HandleListTrash, reg, map[string]any{})
+	if result.IsError || !strings.Contains(resultText(result), "notes.txt") || !strings.Contains(resultText(result), "build"+string(filepath.Separator)) {
+		t.Fatalf("unexpected listing: %s", resultText(result))
+	}
+	entries, _ := reg.Trash().List(reg.GetResolved())
+
+	// The trash itself can only be emptied
+	if result := callTool(t, HandleDeleteDirectory, reg, map[string]any{"path": filepath.Join(tmpDir, trash.DirName), "recursive": true}); !result.IsError {
+		t.Error("expected deleting the trash directory to be refused")
+	}
+
+	var fileID string
+	for _, e := range entries {
+		if !e.Dir {
+			fileID = e.ID
+		}
+	}
+	if result := callTool(t, HandleRestoreFromTrash, reg, map[string]any{"id": fileID}); result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if data, _ := os.ReadFile(file); string(data) != "keep me" {
+		t.Errorf("unexpected restored content: %q", data)
+	}
+	if result := callTool(t, HandleRestoreFromTrash, reg, map[string]any{"id": fileID}); !result.IsError {
+		t.Error("expected a restored entry to be gone from the trash")
+	}
+
+	if result := callTool(t, HandleEmptyTrash, reg, map[string]any{"ids": []any{"missing"}}); !result.IsError {
+		t.Error("expected an unknown ID to be refused")
+	}
+	if result := callTool(t, HandleEmptyTrash, reg, map[string]any{"olderThan": "1d"}); result.IsError || !strings.Contains(resultText(result), "Nothing") {
+		t.Errorf("expected nothing old enough to delete, got %s", resultText(result))
+	}
+	if result := callTool(t, HandleEmptyTrash, reg, map[string]any{}); result.IsError || !strings.Contains(resultText(result), "1 entries") {
+		t.Errorf("unexpected result: %s", resultText(result))
+	}
+	if result := callTool(t, HandleListTrash, reg, map[string]any{}); resultText(result) != "The trash is empty" {
+		t.Errorf("expected an empty trash, got %s", resultText(result))
+	}
+}
+
+func TestRestoreFromTrashDestination(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	reg.SetTrash(trash.New(retention.Policy{}, nil))
+	file := filepath.Join(tmpDir, "a.txt")
+	os.WriteFile(file, []byte("a"), 0644)
+	callTool(t, HandleDeleteFile, reg, map[string]any{"path": file})
+	entries, _ := reg.Trash().List(reg.GetResolved())
+
+	for name, dst := range map[string]string{
+		"outside": "/tmp/restored.txt",
+		"trash":   filepath.Join(filepath.Join(tmpDir, trash.DirName), "x"),
+	} {
+		if result := callTool(t, HandleRestoreFromTrash, reg, map[string]any{"id": entries[0].ID, "destination": dst}); !result.IsError {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	dst := filepath.Join(tmpDir, "restored", "b.txt")
+	if result := callTool(t, HandleRestoreFromTrash, reg, map[string]any{"id": entries[0].ID, "destination": dst}); result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "a" {
+		t.Errorf("unexpected content: %q", data)
+	}
+}
@@ -0,0 +1,185 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/network"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/spf13/cast"
+)
+
+// uploadResponseLimit is how much of the response body upload_file returns.
+const uploadResponseLimit = 4096
+
+// NewUploadFileTool creates the upload_file tool.
+func NewUploadFileTool(reg *registry.Registry) mcp.Tool {
+	domains := "none"
+	if access := reg.Network(); access != nil {
+		domains = strings.Join(access.Domains(), ", ")
+	}
+	return mcp.NewTool(
+		"upload_file",
+		mcp.WithDescription(fmt.Sprintf("Upload a file from an allowed directory to a URL, such as a build artifact or log. PUT sends the file as the request body; POST sends it as a multipart/form-data field. Only http and https URLs of allowed domains can be reached, including after redirects: %s. Returns the response status and the start of the response body. Progress is reported to clients that ask for it.", domains)),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Upload File",
+			ReadOnlyHint:    boolPtr(false),
+			DestructiveHint: boolPtr(true),
+			IdempotentHint:  boolPtr(false),
+			OpenWorldHint:   boolPtr(true),
+		}),
+		mcp.WithString("path", mcp.Description("Path to the file to upload"), mcp.Required()),
+		mcp.WithString("url", mcp.Description("http or https URL to upload to"), mcp.Required()),
+		mcp.WithString("method", mcp.Description("PUT or POST (default: PUT)"), mcp.Enum("PUT", "POST")),
+		mcp.WithString("field", mcp.Description("Form field name of the file for POST (default: file)")),
+		mcp.WithString("contentType", mcp.Description("Content type of the file (default: guessed from the extension, else application/octet-stream)")),
+	)
+}
+
+// HandleUploadFile handles the upload_file tool.
+func HandleUploadFile(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	rawURL := cast.ToString(request.Params.Arguments["url"])
+	method := strings.ToUpper(cast.ToString(request.Params.Arguments["method"]))
+	field := cast.ToString(request.Params.Arguments["field"])
+	contentType := cast.ToString(request.Params.Arguments["contentType"])
+
+	access := reg.Network()
+	if access == nil {
+		return mcp.NewToolResultError("network access is not enabled"), nil
+	}
+	if method == "" {
+		method = http.MethodPut
+	}
+	if method != http.MethodPut && method != http.MethodPost {
+		return mcp.NewToolResultError(fmt.Sprintf("unsupported method %q: expected PUT or POST", method)), nil
+	}
+	if field == "" {
+		field = "file"
+	}
+
+	u, err := access.CheckURL(rawURL)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	resolvedPath, err := reg.Validate(path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	target, err := readTarget(reg, resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	f, err := os.Open(target)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to open file: %w", err).Error()), nil
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to stat file: %w", err).Error()), nil
+	}
+	if !info.Mode().IsRegular() {
+		return mcp.NewToolResultError("path is not a regular file"), nil
+	}
+	if info.Size() > access.MaxBytes() {
+		return mcp.NewToolResultError(fmt.Sprintf("file is %s, over the limit of %s", stream.FormatSize(info.Size()), stream.FormatSize(access.MaxBytes()))), nil
+	}
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(resolvedPath))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+	}
+
+	counter := &progressWriter{p: newProgress(ctx, request), total: info.Size()}
+	body := io.TeeReader(f, counter)
+	status, response, err := upload(ctx, access, method, u, body, info.Size(), filepath.Base(resolvedPath), field, contentType)
+	sent := counter.done.Load()
+	counter.p.report(sent, info.Size(), true)
+	access.Audit(network.Transfer{Direction: "upload", URL: u.Redacted(), Path: resolvedPath, Bytes: sent, Status: status, Err: err})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("upload failed: %w", err).Error()), nil
+	}
+
+	text := fmt.Sprintf("Uploaded %s (%d bytes) from %s to %s with %s\nStatus: %d", stream.FormatSize(sent), sent, resolvedPath, u.Redacted(), method, status)
+	if response != "" {
+		text += "\n\n" + response
+	}
+	return mcp.NewToolResultText(text), nil
+}
+
+// upload sends body to u, returning the response status and the start of
+// the response body. A non-2xx status is an error.
+func upload(ctx context.Context, access *network.Access, method string, u *url.URL, body io.Reader, size int64, name, field, contentType string) (int, string, error) {
+	var req *http.Request
+	var err error
+	if method == http.MethodPut {
+		req, err = http.NewRequestWithContext(ctx, method, u.String(), body)
+		if err != nil {
+			return 0, "", err
+		}
+		req.ContentLength = size
+		req.Header.Set("Content-Type", contentType)
+	} else {
+		pr, pw := io.Pipe()
+		mw := multipart.NewWriter(pw)
+		go func() {
+			pw.CloseWithError(writeMultipart(mw, body, name, field, contentType))
+		}()
+		defer pr.Close()
+		req, err = http.NewRequestWithContext(ctx, method, u.String(), pr)
+		if err != nil {
+			return 0, "", err
+		}
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+	}
+
+	resp, err := access.Client().Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, uploadResponseLimit))
+	response := ""
+	if utf8.Valid(data) {
+		response = strings.TrimSpace(string(data))
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		if response != "" {
+			return resp.StatusCode, response, fmt.Errorf("server returned %s: %s", resp.Status, response)
+		}
+		return resp.StatusCode, response, fmt.Errorf("server returned %s", resp.Status)
+	}
+	return resp.StatusCode, response, nil
+}
+
+// writeMultipart writes body to mw as a single file field.
+func writeMultipart(mw *multipart.Writer, body io.Reader, name, field, contentType string) error {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, escapeQuotes(field), escapeQuotes(name)))
+	header.Set("Content-Type", contentType)
+	part, err := mw.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, body); err != nil {
+		return err
+	}
+	return mw.Close()
+}
+
+// escapeQuotes escapes a multipart header parameter value.
+var escapeQuotes = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace
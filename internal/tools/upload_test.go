@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUploadFile(t *testing.T) {
+	reg, tmpDir, _, transfers := setupFetch(t, "")
+	var method, contentType, received string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		contentType = r.Header.Get("Content-Type")
+		if r.Method == http.MethodPost {
+			f, header, err := r.FormFile("artifact")
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			defer f.Close()
+			data, _ := io.ReadAll(f)
+			received = header.Filename + ":" + string(data)
+		} else {
+			data, _ := io.ReadAll(r.Body)
+			received = string(data)
+		}
+		w.Write([]byte("stored"))
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(tmpDir, "build.log")
+	os.WriteFile(path, []byte("build ok"), 0644)
+
+	result := callTool(t, HandleUploadFile, reg, map[string]any{"path": path, "url": srv.URL + "/logs/build.log"})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if method != http.MethodPut || received != "build ok" || !strings.Contains(resultText(result), "stored") {
+		t.Errorf("unexpected upload: %s %q, result %s", method, received, resultText(result))
+	}
+
+	result = callTool(t, HandleUploadFile, reg, map[string]any{"path": path, "url": srv.URL, "method": "POST", "field": "artifact"})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if !strings.HasPrefix(contentType, "multipart/form-data") || received != "build.log:build ok" {
+		t.Errorf("unexpected multipart upload: %s %q", contentType, received)
+	}
+
+	if len(*transfers) != 2 || (*transfers)[1].Direction != "upload" || (*transfers)[1].Bytes != 8 {
+		t.Errorf("expected the uploads to be audited, got %+v", *transfers)
+	}
+}
+
+func TestUploadFileRefused(t *testing.T) {
+	reg, tmpDir, srv, transfers := setupFetch(t, "")
+	path := filepath.Join(tmpDir, "build.log")
+	os.WriteFile(path, []byte("build ok"), 0644)
+
+	for name, args := range map[string]map[string]any{
+		"host":      {"path": path, "url": strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)},
+		"method":    {"path": path, "url": srv.URL, "method": "DELETE"},
+		"directory": {"path": tmpDir, "url": srv.URL},
+		"outside":   {"path": "/etc/passwd", "url": srv.URL},
+		"status":    {"path": path, "url": srv.URL + "/missing"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if result := callTool(t, HandleUploadFile, reg, args); !result.IsError {
+				t.Errorf("expected an error, got %s", resultText(result))
+			}
+		})
+	}
+	if len(*transfers) != 1 || (*transfers)[0].Status != http.StatusNotFound || (*transfers)[0].Err == nil {
+		t.Errorf("expected only the failed request to be audited, got %+v", *transfers)
+	}
+}
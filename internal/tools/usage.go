@@ -0,0 +1,200 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/portertech/filesystem-mcp-server/internal/usage"
+	"github.com/spf13/cast"
+)
+
+// maxGrowingChildren is the number of top-level entries listed per root.
+const maxGrowingChildren = 10
+
+// usageTrend summarizes the samples recorded for one root.
+type usageTrend struct {
+	Root         string        `json:"root"`
+	Samples      int           `json:"samples"`
+	From         time.Time     `json:"from,omitempty"`
+	To           time.Time     `json:"to,omitempty"`
+	StartBytes   int64         `json:"startBytes"`
+	EndBytes     int64         `json:"endBytes"`
+	Change       int64         `json:"change"`
+	ChangePerDay int64         `json:"changePerDay"`
+	Growing      []childChange `json:"growing,omitempty"`
+}
+
+// childChange is the growth of one top-level entry of a root.
+type childChange struct {
+	Name   string `json:"name"`
+	Bytes  int64  `json:"bytes"`
+	Change int64  `json:"change"`
+}
+
+// NewGetUsageTrendTool creates the get_usage_trend tool.
+func NewGetUsageTrendTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"get_usage_trend",
+		mcp.WithDescription("Report how the disk usage of each allowed directory has changed over time, from periodic size samples, including which top-level entries grew the most."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("root", mcp.Description("Allowed directory to report on, by path, index in list_allowed_directories, or alias (default: all)")),
+		mcp.WithString("since", mcp.Description("Only consider samples newer than this age, e.g. '24h' or '7d'")),
+		mcp.WithBoolean("sampleNow", mcp.Description("If true, record a fresh sample before reporting")),
+		mcp.WithString("format", mcp.Description("Output format: 'text', 'json', or 'csv'")),
+	)
+}
+
+// HandleGetUsageTrend handles the get_usage_trend tool.
+func HandleGetUsageTrend(ctx context.Context, reg *registry.Registry, tracker *usage.Tracker, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	root := cast.ToString(request.Params.Arguments["root"])
+	since := cast.ToString(request.Params.Arguments["since"])
+	sampleNow := cast.ToBool(request.Params.Arguments["sampleNow"])
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	roots := reg.Get()
+	if root != "" {
+		if dir, err := reg.ResolveRoot(root); err == nil {
+			root = dir
+		}
+		resolvedRoot, err := reg.Validate(root)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("root validation failed: %w", err).Error()), nil
+		}
+		roots = nil
+		resolvedDirs := reg.GetResolved()
+		for i, dir := range reg.Get() {
+			if dir == resolvedRoot || resolvedDirs[i] == resolvedRoot {
+				roots = []string{dir}
+			}
+		}
+		if roots == nil {
+			return mcp.NewToolResultError("root must be one of the allowed directories"), nil
+		}
+	}
+
+	var cutoff time.Time
+	if since != "" {
+		age, err := parseAge(since)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("invalid since: %w", err).Error()), nil
+		}
+		cutoff = time.Now().Add(-age)
+	}
+
+	if sampleNow {
+		tracker.SampleRoots(roots)
+	}
+
+	trends := make([]usageTrend, 0, len(roots))
+	for _, r := range roots {
+		var samples []usage.Sample
+		for _, s := range tracker.Samples(r) {
+			if !s.Time.Before(cutoff) {
+				samples = append(samples, s)
+			}
+		}
+		trends = append(trends, summarizeUsage(r, samples))
+	}
+
+	if format == "json" {
+		jsonResult, err := json.MarshalIndent(trends, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(jsonResult)), nil
+	}
+
+	if format == "csv" {
+		return mcp.NewToolResultText(usageCSV(trends)), nil
+	}
+
+	var result strings.Builder
+	for i, t := range trends {
+		if i > 0 {
+			result.WriteString("\n")
+		}
+		if t.Samples == 0 {
+			fmt.Fprintf(&result, "%s: no samples recorded yet\n", t.Root)
+			continue
+		}
+		fmt.Fprintf(&result, "%s: %s -> %s (%s, %s/day) over %d samples from %s to %s\n",
+			t.Root, stream.FormatSize(t.StartBytes), stream.FormatSize(t.EndBytes),
+			formatSizeChange(t.Change), formatSizeChange(t.ChangePerDay), t.Samples,
+			t.From.Format(time.RFC3339), t.To.Format(time.RFC3339))
+		for _, c := range t.Growing {
+			fmt.Fprintf(&result, "  %s  %s (now %s)\n", formatSizeChange(c.Change), c.Name, stream.FormatSize(c.Bytes))
+		}
+	}
+
+	return mcp.NewToolResultText(result.String()), nil
+}
+
+// usageCSV renders trends as CSV with one row per root, followed by a row
+// for each of its growing entries.
+func usageCSV(trends []usageTrend) string {
+	var rows [][]string
+	for _, t := range trends {
+		if t.Samples == 0 {
+			rows = append(rows, []string{csvText(t.Root), "", "0", "", "", "", "", "", ""})
+			continue
+		}
+		rows = append(rows, []string{
+			csvText(t.Root), "", strconv.Itoa(t.Samples),
+			t.From.UTC().Format(time.RFC3339), t.To.UTC().Format(time.RFC3339),
+			strconv.FormatInt(t.StartBytes, 10), strconv.FormatInt(t.EndBytes, 10),
+			strconv.FormatInt(t.Change, 10), strconv.FormatInt(t.ChangePerDay, 10),
+		})
+		for _, c := range t.Growing {
+			rows = append(rows, []string{csvText(t.Root), csvText(c.Name), "", "", "", "", strconv.FormatInt(c.Bytes, 10), strconv.FormatInt(c.Change, 10), ""})
+		}
+	}
+	return formatCSV([]string{"root", "entry", "samples", "from", "to", "startBytes", "endBytes", "change", "changePerDay"}, rows)
+}
+
+// summarizeUsage compares the first and last of samples.
+func summarizeUsage(root string, samples []usage.Sample) usageTrend {
+	t := usageTrend{Root: root, Samples: len(samples)}
+	if len(samples) == 0 {
+		return t
+	}
+
+	first, last := samples[0], samples[len(samples)-1]
+	t.From, t.To = first.Time, last.Time
+	t.StartBytes, t.EndBytes = first.Bytes, last.Bytes
+	t.Change = last.Bytes - first.Bytes
+	if elapsed := last.Time.Sub(first.Time); elapsed > 0 {
+		t.ChangePerDay = int64(float64(t.Change) / elapsed.Hours() * 24)
+	}
+
+	for name, size := range last.Children {
+		if change := size - first.Children[name]; change > 0 {
+			t.Growing = append(t.Growing, childChange{Name: name, Bytes: size, Change: change})
+		}
+	}
+	sort.Slice(t.Growing, func(i, j int) bool {
+		if t.Growing[i].Change != t.Growing[j].Change {
+			return t.Growing[i].Change > t.Growing[j].Change
+		}
+		return t.Growing[i].Name < t.Growing[j].Name
+	})
+	if len(t.Growing) > maxGrowingChildren {
+		t.Growing = t.Growing[:maxGrowingChildren]
+	}
+	return t
+}
+
+// formatSizeChange formats a signed size difference.
+func formatSizeChange(change int64) string {
+	if change < 0 {
+		return "-" + stream.FormatSize(-change)
+	}
+	return "+" + stream.FormatSize(change)
+}
@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/usage"
+)
+
+func TestSummarizeUsage(t *testing.T) {
+	start := time.Now().Add(-48 * time.Hour)
+	samples := []usage.Sample{
+		{Time: start, Bytes: 1000, Children: map[string]int64{"logs": 400, "src": 600}},
+		{Time: start.Add(24 * time.Hour), Bytes: 1500},
+		{Time: start.Add(48 * time.Hour), Bytes: 3000, Children: map[string]int64{"logs": 2300, "src": 600, "cache": 100}},
+	}
+
+	trend := summarizeUsage("/root", samples)
+	if trend.Change != 2000 || trend.ChangePerDay != 1000 {
+		t.Errorf("expected +2000 bytes at 1000/day, got %d at %d/day", trend.Change, trend.ChangePerDay)
+	}
+	if len(trend.Growing) != 2 || trend.Growing[0].Name != "logs" || trend.Growing[1].Name != "cache" {
+		t.Errorf("unexpected growing entries: %+v", trend.Growing)
+	}
+
+	if empty := summarizeUsage("/root", nil); empty.Samples != 0 || empty.Change != 0 {
+		t.Errorf("expected empty trend, got %+v", empty)
+	}
+}
+
+func TestGetUsageTrend(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	tracker, err := usage.NewTracker("", slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := func(ctx context.Context, reg *registry.Registry, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return HandleGetUsageTrend(ctx, reg, tracker, req)
+	}
+
+	result := callTool(t, handler, reg, map[string]any{})
+	if !strings.Contains(resultText(result), "no samples recorded yet") {
+		t.Errorf("expected no samples, got %s", resultText(result))
+	}
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "data.bin"), make([]byte, 2048), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result = callTool(t, handler, reg, map[string]any{"root": tmpDir, "sampleNow": true})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if !strings.Contains(resultText(result), "2.0 KB") {
+		t.Errorf("expected sampled size in output, got %s", resultText(result))
+	}
+
+	result = callTool(t, handler, reg, map[string]any{"root": filepath.Join(tmpDir, "sub")})
+	if !result.IsError {
+		t.Error("expected error for a root that is not an allowed directory")
+	}
+}
@@ -0,0 +1,105 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/portertech/filesystem-mcp-server/internal/validate"
+	"github.com/spf13/cast"
+)
+
+// maxValidateFileSize is the largest file validate_file parses.
+const maxValidateFileSize = 32 * 1024 * 1024
+
+// validation is the result of validate_file.
+type validation struct {
+	Path   string           `json:"path"`
+	Syntax validate.Syntax  `json:"syntax"`
+	Valid  bool             `json:"valid"`
+	Errors []validate.Error `json:"errors,omitempty"`
+}
+
+// NewValidateFileTool creates the validate_file tool.
+func NewValidateFileTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"validate_file",
+		mcp.WithDescription("Check that a JSON, YAML, or TOML file parses, and report where it does not with line, column, and message. A cheap correctness check after writing a config file."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("path", mcp.Description("Path to the file to check"), mcp.Required()),
+		mcp.WithString("syntax", mcp.Description("Syntax to check: 'json', 'yaml', or 'toml' (default: inferred from the file extension)")),
+		mcp.WithString("format", mcp.Description("Output format: 'text' or 'json'")),
+	)
+}
+
+// HandleValidateFile handles the validate_file tool.
+func HandleValidateFile(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	format := cast.ToString(request.Params.Arguments["format"])
+
+	var syntax validate.Syntax
+	var err error
+	if name := cast.ToString(request.Params.Arguments["syntax"]); name != "" {
+		syntax, err = validate.ParseSyntax(name)
+	} else {
+		syntax, err = validate.SyntaxForPath(path)
+	}
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	resolvedPath, err := validateRead(reg, path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	f, err := os.Open(resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to open file: %w", err).Error()), nil
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to stat file: %w", err).Error()), nil
+	}
+	if info.IsDir() {
+		return mcp.NewToolResultError("path is a directory, not a file"), nil
+	}
+	if info.Size() > maxValidateFileSize {
+		return mcp.NewToolResultError(fmt.Sprintf("file is too large to validate: %s exceeds %s", stream.FormatSize(info.Size()), stream.FormatSize(maxValidateFileSize))), nil
+	}
+	release, err := reserveMemory(ctx, reg, resolvedPath, info.Size())
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	defer release()
+	data, err := io.ReadAll(io.LimitReader(f, maxValidateFileSize))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to read file: %w", err).Error()), nil
+	}
+
+	errs := validate.Check(data, syntax)
+	result := validation{Path: path, Syntax: syntax, Valid: len(errs) == 0, Errors: errs}
+	if format == "json" {
+		out, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to marshal result: %w", err).Error()), nil
+		}
+		return mcp.NewToolResultText(string(out)), nil
+	}
+
+	if result.Valid {
+		return mcp.NewToolResultText(fmt.Sprintf("%s: valid %s", path, syntax.Name())), nil
+	}
+	var text strings.Builder
+	fmt.Fprintf(&text, "%s: invalid %s", path, syntax.Name())
+	for _, e := range errs {
+		fmt.Fprintf(&text, "\n  %s", e)
+	}
+	return mcp.NewToolResultText(text.String()), nil
+}
@@ -0,0 +1,52 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHandleValidateFile(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	files := map[string]string{
+		"good.json":   `{"name": "app"}`,
+		"bad.yaml":    "name: app\nname: other\n",
+		"config.conf": "[server]\nport = \n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result := callTool(t, HandleValidateFile, reg, map[string]any{"path": filepath.Join(tmpDir, "good.json")})
+	if result.IsError || resultText(result) != filepath.Join(tmpDir, "good.json")+": valid JSON" {
+		t.Errorf("unexpected result: %s", resultText(result))
+	}
+
+	path := filepath.Join(tmpDir, "bad.yaml")
+	result = callTool(t, HandleValidateFile, reg, map[string]any{"path": path})
+	if want := path + ": invalid YAML\n  line 2: mapping key \"name\" already defined at line 1"; result.IsError || resultText(result) != want {
+		t.Errorf("got %q, want %q", resultText(result), want)
+	}
+
+	result = callTool(t, HandleValidateFile, reg, map[string]any{"path": filepath.Join(tmpDir, "config.conf"), "syntax": "toml", "format": "json"})
+	var v validation
+	if err := json.Unmarshal([]byte(resultText(result)), &v); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, resultText(result))
+	}
+	if v.Valid || v.Syntax != "toml" || len(v.Errors) != 1 || v.Errors[0].Line != 2 || v.Errors[0].Column == 0 {
+		t.Errorf("unexpected validation %+v", v)
+	}
+
+	for _, args := range []map[string]any{
+		{"path": filepath.Join(tmpDir, "config.conf")},
+		{"path": filepath.Join(tmpDir, "good.json"), "syntax": "xml"},
+		{"path": filepath.Join(tmpDir, "missing.json")},
+	} {
+		if result := callTool(t, HandleValidateFile, reg, args); !result.IsError {
+			t.Errorf("expected error for %v", args)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/gobwas/glob"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/ignore"
+	"github.com/portertech/filesystem-mcp-server/internal/journal"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/trash"
+	"github.com/spf13/cast"
+)
+
+// defaultMaxFiles is how many files and directories a recursive tool
+// examines before it stops, unless the call raises maxFiles. It keeps a
+// walk of an unexpectedly huge mount from running for hours.
+const defaultMaxFiles = 100000
+
+// errMaxFiles stops a walk whose fileBudget is spent.
+var errMaxFiles = errors.New("file limit reached")
+
+// fileBudget counts the entries a walk may still examine.
+type fileBudget struct {
+	max  int
+	left int
+	// exhausted is set once an entry was refused.
+	exhausted bool
+}
+
+func newFileBudget(limit int) *fileBudget {
+	return &fileBudget{max: limit, left: limit}
+}
+
+// take spends one entry, reporting false once the budget is spent.
+func (b *fileBudget) take() bool {
+	if b.left <= 0 {
+		b.exhausted = true
+		return false
+	}
+	b.left--
+	return true
+}
+
+// note tells the caller that the walk stopped early, or returns "".
+func (b *fileBudget) note() string {
+	if !b.exhausted {
+		return ""
+	}
+	return fmt.Sprintf("Stopped after examining %d files and directories; raise maxFiles to see more.", b.max)
+}
+
+// withBudgetNote appends the budget's note, if any, to result as a separate
+// content item.
+func withBudgetNote(result *mcp.CallToolResult, b *fileBudget) *mcp.CallToolResult {
+	if note := b.note(); note != "" {
+		result.Content = append(result.Content, mcp.NewTextContent(note))
+	}
+	return result
+}
+
+// withMaxFilesParam declares the maxFiles argument of tools that walk a
+// tree.
+func withMaxFilesParam() mcp.ToolOption {
+	return mcp.WithNumber("maxFiles", mcp.Description(fmt.Sprintf("Stop after examining this many files and directories (default: %d); raise it deliberately to traverse larger trees", defaultMaxFiles)))
+}
+
+// parseMaxFiles reads the maxFiles argument into a fresh budget.
+func parseMaxFiles(request mcp.CallToolRequest) (*fileBudget, error) {
+	v, ok := request.Params.Arguments["maxFiles"]
+	if !ok {
+		return newFileBudget(defaultMaxFiles), nil
+	}
+	n := cast.ToInt(v)
+	if n <= 0 {
+		return nil, errors.New("maxFiles must be positive")
+	}
+	return newFileBudget(n), nil
+}
+
+// maxWalkErrors caps how many unreadable paths a walk reports; the rest are
+// only counted.
+const maxWalkErrors = 100
+
+// walkError is a path a walk skipped because it could not be read.
+type walkError struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// walkErrors collects the paths a walk skipped. The zero value is ready to
+// use.
+type walkErrors struct {
+	Errors []walkError `json:"errors"`
+	// Omitted counts errors past maxWalkErrors.
+	Omitted int `json:"omittedErrors,omitempty"`
+}
+
+// add records that path could not be read.
+func (w *walkErrors) add(path string, err error) {
+	if len(w.Errors) == maxWalkErrors {
+		w.Omitted++
+		return
+	}
+	w.Errors = append(w.Errors, walkError{Path: path, Error: walkErrorText(err)})
+}
+
+// walkErrorText describes err without the path it names, which is
+// reported separately.
+func walkErrorText(err error) string {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
+	}
+	return err.Error()
+}
+
+// withWalkErrors appends the errors, if any, to result as a separate JSON
+// content item.
+func withWalkErrors(result *mcp.CallToolResult, w *walkErrors) *mcp.CallToolResult {
+	if len(w.Errors) == 0 {
+		return result
+	}
+	data, err := json.MarshalIndent(w, "", "  ")
+	if err != nil {
+		return result
+	}
+	result.Content = append(result.Content, mcp.NewTextContent(string(data)))
+	return result
+}
+
+// treeFilter selects the files visited by walkTree.
+type treeFilter struct {
+	matchGlobs   []glob.Glob
+	excludeGlobs []glob.Glob
+	gitignore    bool
+	// skipDir is a directory left out of the walk.
+	skipDir string
+	// onDir, if set, is called for each directory that passes the filter
+	// before it is entered, and may return filepath.SkipDir.
+	onDir func(path, relPath string) error
+	// onError, if set, is called for each entry that cannot be read, which
+	// is otherwise skipped silently.
+	onError func(path string, err error)
+}
+
+// parseTreeFilter reads the patterns, excludePatterns, and respectGitignore
+// arguments shared by tools that walk a tree. respectGitignore defaults to
+// true.
+func parseTreeFilter(request mcp.CallToolRequest) (treeFilter, error) {
+	f := treeFilter{gitignore: true}
+	if v, ok := request.Params.Arguments["respectGitignore"]; ok {
+		f.gitignore = cast.ToBool(v)
+	}
+	if patternsArg, ok := request.Params.Arguments["patterns"].([]interface{}); ok {
+		for _, p := range patternsArg {
+			globs, err := compileGlobs(cast.ToString(p))
+			if err != nil {
+				return f, fmt.Errorf("invalid pattern %q: %v", cast.ToString(p), err)
+			}
+			f.matchGlobs = append(f.matchGlobs, globs...)
+		}
+	}
+	if patternsArg, ok := request.Params.Arguments["excludePatterns"].([]interface{}); ok {
+		for _, p := range patternsArg {
+			globs, err := compileGlobs(cast.ToString(p))
+			if err != nil {
+				return f, fmt.Errorf("invalid exclude pattern %q: %v", cast.ToString(p), err)
+			}
+			f.excludeGlobs = append(f.excludeGlobs, globs...)
+		}
+	}
+	return f, nil
+}
+
+// resolveDirectory validates path and checks that it is a directory, as
+// seen through the overlay in overlay mode.
+func resolveDirectory(reg *registry.Registry, path string) (string, *mcp.CallToolResult) {
+	resolvedPath, err := reg.Validate(path)
+	if err != nil {
+		return "", mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error())
+	}
+	info, err := statTarget(reg, resolvedPath)
+	if err != nil {
+		return "", mcp.NewToolResultError(fmt.Errorf("failed to stat path: %w", err).Error())
+	}
+	if !info.IsDir() {
+		return "", mcp.NewToolResultError("path is not a directory")
+	}
+	return resolvedPath, nil
+}
+
+// walkTree walks root in lexical order and calls fn for each regular file
+// that passes filter, with its path relative to root in slash form.
+// Symlinks, .git directories, and trash and journal directories are always
+// skipped. In overlay mode the walk sees pending changes, and files are to
+// be read through readTarget.
+func walkTree(ctx context.Context, reg *registry.Registry, root string, filter treeFilter, fn func(path, relPath string, entry fs.DirEntry) error) error {
+	var matcher *ignore.Matcher
+	if filter.gitignore {
+		var err error
+		if matcher, err = ignore.NewWithSource(root, func(path string) (string, error) {
+			return readTarget(reg, path)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return walkDir(reg, root, func(walkPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if filter.onError != nil {
+				filter.onError(walkPath, err)
+			}
+			return nil // Continue on errors
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if walkPath == root {
+			return nil
+		}
+		if entry.Type()&os.ModeSymlink != 0 {
+			return nil
+		}
+		if entry.IsDir() && (entry.Name() == ".git" || entry.Name() == trash.DirName || entry.Name() == journal.DirName || walkPath == filter.skipDir) {
+			return filepath.SkipDir
+		}
+
+		relPath, relErr := filepath.Rel(root, walkPath)
+		if relErr != nil {
+			return nil
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		if matchesAny(filter.excludeGlobs, relPath) || (matcher != nil && matcher.Match(walkPath, entry.IsDir())) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() {
+			if filter.onDir != nil {
+				if err := filter.onDir(walkPath, relPath); err != nil {
+					return err
+				}
+			}
+			if matcher != nil {
+				return matcher.LoadDir(walkPath)
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		if len(filter.matchGlobs) > 0 && !matchesAny(filter.matchGlobs, relPath) {
+			return nil
+		}
+		return fn(walkPath, relPath, entry)
+	})
+}
@@ -1,10 +1,12 @@
 package tools
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -20,11 +22,14 @@ import (
 func NewWriteFileTool(reg *registry.Registry) mcp.Tool {
 	return mcp.NewTool(
 		"write_file",
-		mcp.WithDescription("Write content to a file. Creates parent directories if needed. Uses atomic write."),
+		mcp.WithDescription("Write content to a file. Creates parent directories if needed. Uses atomic write. With substitutions, {{key}} placeholders in the content are replaced first."),
 		mcp.WithIdempotentHintAnnotation(true),
 		mcp.WithDestructiveHintAnnotation(true),
 		mcp.WithString("path", mcp.Description("Path to the file to write"), mcp.Required()),
 		mcp.WithString("content", mcp.Description("Content to write to the file"), mcp.Required()),
+		withSubstitutionsParam(),
+		withEncodingParam("Encoding to write the content in"),
+		mcp.WithBoolean("bom", mcp.Description("Whether to start the file with a byte order mark (utf-8 and utf-16 only). With an encoding and no bom, an overwritten file keeps the byte order mark it had")),
 	)
 }
 
@@ -32,28 +37,103 @@ func NewWriteFileTool(reg *registry.Registry) mcp.Tool {
 func HandleWriteFile(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	path := cast.ToString(request.Params.Arguments["path"])
 	content := cast.ToString(request.Params.Arguments["content"])
+	encoding, err := parseTextEncoding(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	bomArg, hasBOMArg := request.Params.Arguments["bom"]
+	if cast.ToBool(bomArg) && encodingBOM(encoding) == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("%s has no byte order mark", encoding)), nil
+	}
+
+	subs, err := parseSubstitutions(request.Params.Arguments["substitutions"])
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	note := ""
+	if subs != nil {
+		var replaced int
+		var unresolved []string
+		content, replaced, unresolved = substitute(content, subs)
+		note = substitutionNote(replaced, unresolved)
+	}
 
 	resolvedPath, err := reg.ValidateForCreation(path)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
 	}
 
-	// Create parent directories if needed
-	dir := filepath.Dir(path)
-	if err := safeMkdirAll(dir, 0755, reg.Get()); err != nil {
-		return mcp.NewToolResultError(fmt.Errorf("failed to create directories: %w", err).Error()), nil
+	target, allowedDirs, err := writeTarget(reg, resolvedPath)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to write file: %w", err).Error()), nil
+	}
+
+	data := []byte(content)
+	if _, hasEncoding := request.Params.Arguments["encoding"]; hasEncoding || hasBOMArg {
+		bom := cast.ToBool(bomArg)
+		if !hasBOMArg {
+			bom = startsWithBOM(reg, resolvedPath, encoding)
+		}
+		if data, err = encodeText(content, encoding, bom); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	// Create parent directories if needed (the overlay creates its own)
+	if reg.Overlay() == nil {
+		dir := filepath.Dir(reg.ExpandPath(path))
+		if err := safeMkdirAll(dir, reg.Modes().Dir, reg.Get()); err != nil {
+			return mcp.NewToolResultError(fmt.Errorf("failed to create directories: %w", err).Error()), nil
+		}
+	}
+
+	op := beginJournal(reg, "write_file", resolvedPath)
+	op.Save(resolvedPath)
+
+	// A replaced file keeps its mode
+	perm := reg.Modes().File
+	if info, err := os.Stat(target); err == nil {
+		perm = info.Mode().Perm()
 	}
 
 	// Atomic write using temp file
-	if err := atomicWriteFile(resolvedPath, []byte(content), 0644, reg.Get()); err != nil {
+	if err := atomicWriteFile(target, data, perm, allowedDirs); err != nil {
+		op.Discard()
 		return mcp.NewToolResultError(fmt.Errorf("failed to write file: %w", err).Error()), nil
 	}
+	if store := reg.Shadow(); store != nil {
+		store.Record(target, data)
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Successfully wrote to %s%s%s", resolvedPath, note, journalNote(op))), nil
+}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Successfully wrote to %s", resolvedPath)), nil
+// startsWithBOM reports whether the existing file at path starts with the byte
+// order mark of enc.
+func startsWithBOM(reg *registry.Registry, path, enc string) bool {
+	bom := encodingBOM(enc)
+	existing, err := readTarget(reg, path)
+	if bom == nil || err != nil {
+		return false
+	}
+	head, err := readHead(existing, len(bom))
+	return err == nil && bytes.Equal(head, bom)
 }
 
 // atomicWriteFile writes data to a file atomically using a temp file and rename.
 func atomicWriteFile(path string, data []byte, perm os.FileMode, allowedDirs []string) error {
+	return atomicWriteStream(path, perm, allowedDirs, func(w io.Writer) error {
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("failed to write data: %w", err)
+		}
+		return nil
+	})
+}
+
+// atomicWriteStream writes a file atomically, like atomicWriteFile, with
+// content that write streams to a temp file. If write fails, the temp file
+// is removed and the file is left as it was.
+func atomicWriteStream(path string, perm os.FileMode, allowedDirs []string, write func(io.Writer) error) error {
 	// Validate destination path before any I/O
 	if _, err := security.ValidateFinalPathForCreation(path, allowedDirs); err != nil {
 		return fmt.Errorf("path validation failed: %w", err)
@@ -81,9 +161,9 @@ func atomicWriteFile(path string, data []byte, perm os.FileMode, allowedDirs []s
 		}
 	}()
 
-	if _, err := f.Write(data); err != nil {
+	if err := write(f); err != nil {
 		f.Close()
-		return fmt.Errorf("failed to write data: %w", err)
+		return err
 	}
 
 	if err := f.Sync(); err != nil {
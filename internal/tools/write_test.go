@@ -4,9 +4,12 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/perms"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
 )
 
 func TestHandleWriteFile(t *testing.T) {
@@ -36,6 +39,30 @@ func TestHandleWriteFile(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "write with substitutions",
+			args: map[string]any{
+				"path":          filepath.Join(tmpDir, "subst.txt"),
+				"content":       "name={{name}} port={{ port }} keep={{other}}",
+				"substitutions": map[string]any{"name": "api", "port": "8080"},
+			},
+			isError: false,
+			validate: func(t *testing.T) {
+				data, _ := os.ReadFile(filepath.Join(tmpDir, "subst.txt"))
+				if want := "name=api port=8080 keep={{other}}"; string(data) != want {
+					t.Errorf("content mismatch: got %q, want %q", string(data), want)
+				}
+			},
+		},
+		{
+			name: "invalid substitutions",
+			args: map[string]any{
+				"path":          filepath.Join(tmpDir, "bad-subst.txt"),
+				"content":       "x",
+				"substitutions": "name=api",
+			},
+			isError: true,
+		},
 		{
 			name: "overwrite existing file",
 			args: map[string]any{
@@ -169,3 +196,50 @@ func TestAtomicWriteFile(t *testing.T) {
 		t.Errorf("permissions mismatch: got %o, want %o", info.Mode().Perm(), 0644)
 	}
 }
+
+func TestCreatedModes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows has no Unix permissions")
+	}
+	reg, tmpDir := setupTestRegistry(t)
+	reg.SetModes(registry.Modes{File: 0640, Dir: 0750})
+	mask := perms.Umask()
+
+	path := filepath.Join(tmpDir, "new", "file.txt")
+	if result := callTool(t, HandleWriteFile, reg, map[string]any{"path": path, "content": "x"}); result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	check := func(path string, want os.FileMode) {
+		t.Helper()
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode().Perm(); got != want&^mask {
+			t.Errorf("%s: mode %o, want %o", path, got, want&^mask)
+		}
+	}
+	check(path, 0640)
+	check(filepath.Dir(path), 0750)
+
+	// Converted files are written through a temporary file and chmod, which
+	// the umask does not restrict; the registry's modes are already masked
+	src := filepath.Join(tmpDir, "data.json")
+	if err := os.WriteFile(src, []byte(`[{"a": 1}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(tmpDir, "data.csv")
+	if result := callTool(t, HandleConvertFile, reg, map[string]any{"source": src, "destination": dst}); result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	check(dst, 0640)
+
+	// Existing files keep their mode
+	if err := os.Chmod(path, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if result := callTool(t, HandleWriteFile, reg, map[string]any{"path": path, "content": "y"}); result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	check(path, 0600)
+}
@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/stream"
+	"github.com/spf13/cast"
+)
+
+// NewWriteFileRangeTool creates the write_file_range tool.
+func NewWriteFileRangeTool(reg *registry.Registry) mcp.Tool {
+	return mcp.NewTool(
+		"write_file_range",
+		mcp.WithDescription("Overwrite bytes of an existing file at an offset, without rewriting the rest of it, to patch large binary or fixed-record files. Writing at the end of the file appends. Unlike write_file, the write is in place, not atomic: a concurrent reader can see it half done, and a failure can leave the range partly written."),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(true),
+		mcp.WithString("path", mcp.Description("Path to the file to write"), mcp.Required()),
+		mcp.WithNumber("offset", mcp.Description("Byte offset to write at, at most the file's size"), mcp.Required()),
+		mcp.WithString("data", mcp.Description(fmt.Sprintf("Bytes to write, encoded as encoding says (max %s decoded)", stream.FormatSize(maxBytesLength))), mcp.Required()),
+		mcp.WithString("encoding", mcp.Description("Encoding of data: 'base64' or 'hex', whose whitespace is ignored (default: base64)")),
+	)
+}
+
+// HandleWriteFileRange handles the write_file_range tool.
+func HandleWriteFileRange(ctx context.Context, reg *registry.Registry, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path := cast.ToString(request.Params.Arguments["path"])
+	offset := cast.ToInt64(request.Params.Arguments["offset"])
+	encoded := cast.ToString(request.Params.Arguments["data"])
+	encoding := cast.ToString(request.Params.Arguments["encoding"])
+
+	if reg.Overlay() != nil {
+		return mcp.NewToolResultError("write_file_range is not supported in overlay mode; use write_file instead"), nil
+	}
+	if offset < 0 {
+		return mcp.NewToolResultError("offset must not be negative"), nil
+	}
+	if encoding == "" {
+		encoding = "base64"
+	}
+	var data []byte
+	var err error
+	switch encoding {
+	case "base64":
+		data, err = decodeBase64(encoded)
+	case "hex":
+		data, err = hex.DecodeString(strings.Join(strings.Fields(encoded), ""))
+	default:
+		return mcp.NewToolResultError("encoding must be 'base64' or 'hex'"), nil
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("invalid %s data: %w", encoding, err).Error()), nil
+	}
+	if len(data) == 0 {
+		return mcp.NewToolResultError("data must not be empty"), nil
+	}
+	if len(data) > maxBytesLength {
+		return mcp.NewToolResultError(fmt.Sprintf("data is larger than %s", stream.FormatSize(maxBytesLength))), nil
+	}
+
+	resolvedPath, err := validateFinal(reg, path)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("path validation failed: %w", err).Error()), nil
+	}
+	if _, _, err := writeTarget(reg, resolvedPath); err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to write file: %w", err).Error()), nil
+	}
+
+	f, err := os.OpenFile(resolvedPath, os.O_WRONLY, 0)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to open file: %w", err).Error()), nil
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Errorf("failed to stat file: %w", err).Error()), nil
+	}
+	if !info.Mode().IsRegular() {
+		return mcp.NewToolResultError("path is not a regular file"), nil
+	}
+	if offset > info.Size() {
+		return mcp.NewToolResultError(fmt.Sprintf("offset %d is past the end of the file (%d bytes)", offset, info.Size())), nil
+	}
+
+	op := beginJournal(reg, "write_file_range", resolvedPath)
+	op.Save(resolvedPath)
+
+	n, err := f.WriteAt(data, offset)
+	if err == nil {
+		err = f.Sync()
+	}
+	if store := reg.Shadow(); store != nil {
+		store.Forget(resolvedPath)
+	}
+	if err == nil {
+		err = f.Close()
+	}
+	if err != nil {
+		// Recorded anyway, since part of the range may have been written
+		return mcp.NewToolResultError(fmt.Errorf("failed to write file after %d of %d bytes, so the range may be partly written: %w%s", n, len(data), err, journalNote(op)).Error()), nil
+	}
+
+	size := max(info.Size(), offset+int64(n))
+	return mcp.NewToolResultText(fmt.Sprintf("Wrote %d bytes to %s at offset %d (bytes %d-%d; the file is %d bytes). Written in place, not atomically.%s", n, resolvedPath, offset, offset, offset+int64(n)-1, size, journalNote(op))), nil
+}
@@ -0,0 +1,74 @@
+package tools
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/portertech/filesystem-mcp-server/internal/journal"
+	"github.com/portertech/filesystem-mcp-server/internal/retention"
+)
+
+func TestWriteFileRange(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	reg.SetJournal(journal.New(retention.Policy{MaxBytes: journal.DefaultMaxBytes}, nil))
+	path := filepath.Join(tmpDir, "records.dat")
+	os.WriteFile(path, []byte("AAAABBBBCCCC"), 0640)
+	before, _ := os.Stat(path)
+
+	result := callTool(t, HandleWriteFileRange, reg, map[string]any{"path": path, "offset": 4, "data": base64.StdEncoding.EncodeToString([]byte("bbbb"))})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if !strings.Contains(resultText(result), "not atomically") {
+		t.Errorf("expected the result to say the write is not atomic: %s", resultText(result))
+	}
+	if data, _ := os.ReadFile(path); string(data) != "AAAAbbbbCCCC" {
+		t.Errorf("unexpected content: %q", data)
+	}
+	after, _ := os.Stat(path)
+	if !os.SameFile(before, after) || after.Mode().Perm() != 0640 {
+		t.Error("expected the file to be written in place")
+	}
+
+	// Writing at the end appends
+	result = callTool(t, HandleWriteFileRange, reg, map[string]any{"path": path, "offset": 12, "data": "44 44", "encoding": "hex"})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if data, _ := os.ReadFile(path); string(data) != "AAAAbbbbCCCCDD" {
+		t.Errorf("unexpected content: %q", data)
+	}
+
+	if result := callTool(t, HandleUndoOperation, reg, map[string]any{"count": 2}); result.IsError {
+		t.Fatalf("unexpected error: %s", resultText(result))
+	}
+	if data, _ := os.ReadFile(path); string(data) != "AAAABBBBCCCC" {
+		t.Errorf("expected undo to restore the file, got %q", data)
+	}
+}
+
+func TestWriteFileRangeValidation(t *testing.T) {
+	reg, tmpDir := setupTestRegistry(t)
+	path := filepath.Join(tmpDir, "a.bin")
+	os.WriteFile(path, []byte("1234"), 0644)
+
+	for _, args := range []map[string]any{
+		{"path": path, "offset": 5, "data": "AA==", "encoding": "base64"},
+		{"path": path, "offset": -1, "data": "AA=="},
+		{"path": path, "offset": 0, "data": "zz", "encoding": "hex"},
+		{"path": path, "offset": 0, "data": "AA==", "encoding": "utf-8"},
+		{"path": path, "offset": 0, "data": ""},
+		{"path": filepath.Join(tmpDir, "missing.bin"), "offset": 0, "data": "AA=="},
+		{"path": tmpDir, "offset": 0, "data": "AA=="},
+	} {
+		if result := callTool(t, HandleWriteFileRange, reg, args); !result.IsError {
+			t.Errorf("expected %v to be refused", args)
+		}
+	}
+	if data, _ := os.ReadFile(path); string(data) != "1234" {
+		t.Errorf("expected the file to be unchanged, got %q", data)
+	}
+}
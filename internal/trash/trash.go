@@ -0,0 +1,371 @@
+// Package trash keeps deleted files and directories in a trash directory
+// for the allowed directory they were deleted from, so they can be
+// restored. The trash directory is in the state directory, or a .mcp-trash
+// directory at the top of the allowed directory. A manifest in each trash
+// directory records where every entry came from. Entries are moved with a
+// rename, so deleting and restoring are cheap and never copy content.
+// Server instances sharing a trash directory take its lock file around
+// every manifest update, so they do not lose each other's entries.
+package trash
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/portertech/filesystem-mcp-server/internal/filelock"
+	"github.com/portertech/filesystem-mcp-server/internal/retention"
+)
+
+// DirName is the name of the trash directory kept in an allowed directory.
+const DirName = ".mcp-trash"
+
+// manifestName is the name of the manifest in a trash directory.
+const manifestName = "manifest.json"
+
+// lockName is the name of the lock file in a trash directory.
+const lockName = "lock"
+
+// lockWait is how long to wait for another server instance to finish
+// updating a trash directory.
+const lockWait = 10 * time.Second
+
+// ErrNotFound is returned for an ID that is not in any trash.
+var ErrNotFound = errors.New("trash entry not found")
+
+// Entry is a deleted file or directory.
+type Entry struct {
+	ID string `json:"id"`
+	// Path is where the entry was deleted from.
+	Path    string    `json:"path"`
+	Root    string    `json:"root"`
+	Deleted time.Time `json:"deleted"`
+	Dir     bool      `json:"dir"`
+	// Size is the total size of the files in the entry.
+	Size int64 `json:"size"`
+}
+
+// Trash moves entries into and out of the trash directories. It is safe for
+// concurrent use.
+type Trash struct {
+	mu     sync.Mutex
+	policy retention.Policy
+	locate func(root string) string
+	now    func() time.Time
+}
+
+// New creates a Trash whose Prune drops entries outside policy. locate
+// gives the trash directory of an allowed directory; if it is nil, that is
+// DirName at its top.
+func New(policy retention.Policy, locate func(root string) string) *Trash {
+	if locate == nil {
+		locate = func(root string) string {
+			return filepath.Join(root, DirName)
+		}
+	}
+	return &Trash{policy: policy, locate: locate, now: time.Now}
+}
+
+// Retention returns the policy Prune applies.
+func (t *Trash) Retention() retention.Policy {
+	return t.policy
+}
+
+// Dir returns the trash directory of root.
+func (t *Trash) Dir(root string) string {
+	return t.locate(root)
+}
+
+// Put moves path, which must be within root, into root's trash.
+func (t *Trash) Put(root, path string, dir bool, size int64) (Entry, error) {
+	return t.PutAs(root, path, path, dir, size)
+}
+
+// PutAs is like Put for an entry already moved aside from origin, such as
+// a backup kept until a batch of changes is done. The entry is recorded as
+// deleted from origin.
+func (t *Trash) PutAs(root, path, origin string, dir bool, size int64) (Entry, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	trashDir := t.Dir(root)
+	if err := os.MkdirAll(trashDir, 0700); err != nil {
+		return Entry{}, fmt.Errorf("failed to create trash directory: %w", err)
+	}
+	release, err := lockDir(trashDir)
+	if err != nil {
+		return Entry{}, err
+	}
+	defer release()
+	entries, err := readManifest(trashDir)
+	if err != nil {
+		return Entry{}, err
+	}
+	id, err := t.newID()
+	if err != nil {
+		return Entry{}, err
+	}
+	e := Entry{ID: id, Path: origin, Root: root, Deleted: t.now().UTC(), Dir: dir, Size: size}
+
+	if err := os.Rename(path, filepath.Join(trashDir, id)); err != nil {
+		return Entry{}, fmt.Errorf("failed to move to trash: %w", err)
+	}
+	if err := writeManifest(trashDir, append(entries, e)); err != nil {
+		if undoErr := os.Rename(filepath.Join(trashDir, id), path); undoErr != nil {
+			return Entry{}, fmt.Errorf("%w; the entry is left in %s", err, filepath.Join(trashDir, id))
+		}
+		return Entry{}, err
+	}
+	return e, nil
+}
+
+// List returns the entries in the trash of each root, newest first.
+func (t *Trash) List(roots []string) ([]Entry, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var all []Entry
+	for _, root := range roots {
+		entries, err := readManifest(t.Dir(root))
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, entries...)
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Deleted.After(all[j].Deleted)
+	})
+	return all, nil
+}
+
+// Find returns the entry with the given ID in the trash of any root.
+func (t *Trash) Find(roots []string, id string) (Entry, error) {
+	entries, err := t.List(roots)
+	if err != nil {
+		return Entry{}, err
+	}
+	for _, e := range entries {
+		if e.ID == id {
+			return e, nil
+		}
+	}
+	return Entry{}, ErrNotFound
+}
+
+// Restore moves the entry with the given ID out of the trash to dest,
+// which must not exist, and drops it from the manifest.
+func (t *Trash) Restore(roots []string, id, dest string) (Entry, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	trashDir, err := t.find(roots, id)
+	if err != nil {
+		return Entry{}, err
+	}
+	release, err := lockDir(trashDir)
+	if err != nil {
+		return Entry{}, err
+	}
+	defer release()
+	// Read again under the lock, which another instance may have held
+	entries, err := readManifest(trashDir)
+	if err != nil {
+		return Entry{}, err
+	}
+	i := slices.IndexFunc(entries, func(e Entry) bool { return e.ID == id })
+	if i < 0 {
+		return Entry{}, ErrNotFound
+	}
+	if _, err := os.Lstat(dest); err == nil {
+		return Entry{}, fmt.Errorf("%s already exists", dest)
+	}
+	if err := os.Rename(filepath.Join(trashDir, id), dest); err != nil {
+		return Entry{}, fmt.Errorf("failed to restore: %w", err)
+	}
+	e := entries[i]
+	if err := writeManifest(trashDir, append(entries[:i:i], entries[i+1:]...)); err != nil {
+		return Entry{}, fmt.Errorf("restored to %s, but failed to update the manifest: %w", dest, err)
+	}
+	return e, nil
+}
+
+// Remove permanently deletes the entries for which drop returns true from
+// the trash of each root, and returns them.
+func (t *Trash) Remove(roots []string, drop func(Entry) bool) ([]Entry, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var removed []Entry
+	for _, root := range roots {
+		r, err := dropFrom(t.Dir(root), func(entries []Entry) []bool {
+			dropped := make([]bool, len(entries))
+			for i, e := range entries {
+				dropped[i] = drop(e)
+			}
+			return dropped
+		})
+		removed = append(removed, r...)
+		if err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// Prune permanently deletes the entries outside the retention policy from
+// the trash of each root, and returns them. The size limit applies to each
+// root's trash separately.
+func (t *Trash) Prune(roots []string) ([]Entry, error) {
+	if !t.policy.Enabled() {
+		return nil, nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var removed []Entry
+	for _, root := range roots {
+		r, err := dropFrom(t.Dir(root), func(entries []Entry) []bool {
+			items := make([]retention.Item, len(entries))
+			for i, e := range entries {
+				items[i] = retention.Item{Time: e.Deleted, Size: e.Size}
+			}
+			return t.policy.Expired(t.now(), items)
+		})
+		removed = append(removed, r...)
+		if err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// dropFrom deletes the entries of trashDir that drop marks, holding its
+// lock file, and returns the ones it deleted. A trash directory that does
+// not exist has nothing to delete.
+func dropFrom(trashDir string, drop func([]Entry) []bool) ([]Entry, error) {
+	if _, err := os.Stat(trashDir); os.IsNotExist(err) {
+		return nil, nil
+	}
+	release, err := lockDir(trashDir)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	entries, err := readManifest(trashDir)
+	if err != nil {
+		return nil, err
+	}
+	return removeLocked(trashDir, entries, drop(entries))
+}
+
+// lockDir takes the lock file of trashDir, waiting for another server
+// instance that holds it.
+func lockDir(trashDir string) (func(), error) {
+	release, err := filelock.Acquire(filepath.Join(trashDir, lockName), lockWait)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock trash directory %s: %w", trashDir, err)
+	}
+	return release, nil
+}
+
+// removeLocked deletes the entries of trashDir marked in dropped, and
+// returns the ones it deleted.
+func removeLocked(trashDir string, entries []Entry, dropped []bool) ([]Entry, error) {
+	var kept, removed []Entry
+	for i, e := range entries {
+		if dropped[i] {
+			removed = append(removed, e)
+		} else {
+			kept = append(kept, e)
+		}
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	// Update the manifest first, so a failed removal leaves unlisted
+	// content rather than entries that cannot be restored
+	if err := writeManifest(trashDir, kept); err != nil {
+		return nil, err
+	}
+	for i, e := range removed {
+		if err := os.RemoveAll(filepath.Join(trashDir, e.ID)); err != nil {
+			return removed[:i], fmt.Errorf("failed to remove %s from the trash: %w", e.ID, err)
+		}
+	}
+	return removed, nil
+}
+
+// find returns the trash directory holding id.
+func (t *Trash) find(roots []string, id string) (string, error) {
+	for _, root := range roots {
+		trashDir := t.Dir(root)
+		entries, err := readManifest(trashDir)
+		if err != nil {
+			return "", err
+		}
+		if slices.ContainsFunc(entries, func(e Entry) bool { return e.ID == id }) {
+			return trashDir, nil
+		}
+	}
+	return "", ErrNotFound
+}
+
+// newID returns a new entry ID that sorts by deletion time.
+func (t *Trash) newID() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return t.now().UTC().Format("20060102T150405") + "-" + hex.EncodeToString(b), nil
+}
+
+// readManifest reads the manifest of trashDir, which may not exist yet.
+func readManifest(trashDir string) ([]Entry, error) {
+	data, err := os.ReadFile(filepath.Join(trashDir, manifestName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash manifest: %w", err)
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse trash manifest %s: %w", filepath.Join(trashDir, manifestName), err)
+	}
+	// IDs name entries in trashDir, so one that is not a plain name could
+	// only come from a tampered manifest
+	for _, e := range entries {
+		if e.ID == "" || e.ID == "." || e.ID == ".." || filepath.Base(e.ID) != e.ID {
+			return nil, fmt.Errorf("trash manifest %s has an invalid entry ID %q", filepath.Join(trashDir, manifestName), e.ID)
+		}
+	}
+	return entries, nil
+}
+
+// writeManifest replaces the manifest of trashDir atomically.
+func writeManifest(trashDir string, entries []Entry) error {
+	if entries == nil {
+		entries = []Entry{}
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(trashDir, manifestName+".tmp")
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write trash manifest: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(trashDir, manifestName)); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write trash manifest: %w", err)
+	}
+	return nil
+}
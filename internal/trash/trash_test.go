@@ -0,0 +1,175 @@
+package trash
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/portertech/filesystem-mcp-server/internal/filelock"
+	"github.com/portertech/filesystem-mcp-server/internal/retention"
+)
+
+func TestPutRestore(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "a.txt")
+	os.WriteFile(path, []byte("hello"), 0644)
+
+	state := t.TempDir()
+	tr := New(retention.Policy{}, func(string) string { return state })
+	e, err := tr.Put(root, path, false, 5)
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expected the file to be moved")
+	}
+	if data, _ := os.ReadFile(filepath.Join(state, e.ID)); string(data) != "hello" {
+		t.Errorf("unexpected trashed content: %q", data)
+	}
+
+	os.WriteFile(path, []byte("new"), 0644)
+	if _, err := tr.Restore([]string{root}, e.ID, path); err == nil {
+		t.Error("expected an existing destination to be refused")
+	}
+	os.Remove(path)
+	if _, err := tr.Restore([]string{root}, e.ID, path); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "hello" {
+		t.Errorf("unexpected restored content: %q", data)
+	}
+	if _, err := tr.Find([]string{root}, e.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the entry to be gone, got %v", err)
+	}
+}
+
+func TestPutAs(t *testing.T) {
+	root := t.TempDir()
+	backup := filepath.Join(root, ".backup-a.txt")
+	os.WriteFile(backup, []byte("hello"), 0644)
+
+	tr := New(retention.Policy{}, nil)
+	e, err := tr.PutAs(root, backup, filepath.Join(root, "a.txt"), false, 5)
+	if err != nil {
+		t.Fatalf("PutAs: %v", err)
+	}
+	if e.Path != filepath.Join(root, "a.txt") {
+		t.Errorf("expected the entry to record its origin, got %s", e.Path)
+	}
+	if _, err := os.Stat(backup); !os.IsNotExist(err) {
+		t.Error("expected the backup to be moved")
+	}
+}
+
+func TestListRemove(t *testing.T) {
+	root := t.TempDir()
+	tr := New(retention.Policy{}, nil)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tr.now = func() time.Time { return now }
+
+	var ids []string
+	for _, name := range []string{"old", "new"} {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Join(path, "sub"), 0755)
+		e, err := tr.Put(root, path, true, 0)
+		if err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+		ids = append(ids, e.ID)
+		now = now.Add(time.Hour)
+	}
+
+	entries, err := tr.List([]string{root})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(entries) != 2 || entries[0].ID != ids[1] {
+		t.Fatalf("expected newest first, got %+v", entries)
+	}
+
+	removed, err := tr.Remove([]string{root}, func(e Entry) bool { return e.ID == ids[0] })
+	if err != nil || len(removed) != 1 {
+		t.Fatalf("Remove: %v, %+v", err, removed)
+	}
+	if _, err := os.Stat(filepath.Join(tr.Dir(root), ids[0])); !os.IsNotExist(err) {
+		t.Error("expected the entry's content to be deleted")
+	}
+	if entries, _ := tr.List([]string{root}); len(entries) != 1 || entries[0].ID != ids[1] {
+		t.Errorf("unexpected entries after Remove: %+v", entries)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	root := t.TempDir()
+	tr := New(retention.Policy{MaxAge: 48 * time.Hour, MaxBytes: 10}, nil)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tr.now = func() time.Time { return now }
+
+	var ids []string
+	for i, size := range []int64{1, 6, 6} {
+		path := filepath.Join(root, string(rune('a'+i)))
+		os.WriteFile(path, make([]byte, size), 0644)
+		e, err := tr.Put(root, path, false, size)
+		if err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+		ids = append(ids, e.ID)
+		now = now.Add(24 * time.Hour)
+	}
+
+	// The first entry is over two days old and the second no longer fits
+	removed, err := tr.Prune([]string{root})
+	if err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if len(removed) != 2 || removed[0].ID != ids[0] || removed[1].ID != ids[1] {
+		t.Fatalf("unexpected entries pruned: %+v", removed)
+	}
+	if entries, _ := tr.List([]string{root}); len(entries) != 1 || entries[0].ID != ids[2] {
+		t.Errorf("unexpected entries after Prune: %+v", entries)
+	}
+}
+
+func TestTamperedManifest(t *testing.T) {
+	root := t.TempDir()
+	tr := New(retention.Policy{}, nil)
+	os.MkdirAll(tr.Dir(root), 0700)
+	os.WriteFile(filepath.Join(tr.Dir(root), manifestName), []byte(`[{"id":"../../etc"}]`), 0600)
+
+	if _, err := tr.Restore([]string{root}, "../../etc", filepath.Join(root, "x")); err == nil {
+		t.Error("expected an invalid ID to be refused")
+	}
+}
+
+func TestSharedTrash(t *testing.T) {
+	if !filelock.Supported {
+		t.Skip(filelock.ErrUnsupported)
+	}
+	root := t.TempDir()
+	// Two instances serving the same root, as two server processes would
+	a, b := New(retention.Policy{}, nil), New(retention.Policy{}, nil)
+	var wg sync.WaitGroup
+	for i := range 20 {
+		path := filepath.Join(root, fmt.Sprintf("f%d", i))
+		os.WriteFile(path, []byte("x"), 0644)
+		tr := a
+		if i%2 == 1 {
+			tr = b
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := tr.Put(root, path, false, 1); err != nil {
+				t.Errorf("Put: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if entries, _ := a.List([]string{root}); len(entries) != 20 {
+		t.Errorf("expected every entry to be kept, got %d", len(entries))
+	}
+}
@@ -0,0 +1,166 @@
+// Package usage records periodic disk-usage samples of the allowed roots so
+// growth can be reported over time. Samples are kept in a small JSON state
+// file when a path is configured, and in memory otherwise.
+package usage
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxSamples is the number of samples retained per root; older samples are
+// dropped first.
+const MaxSamples = 500
+
+// Sample is the measured size of a root at a point in time.
+type Sample struct {
+	Time  time.Time `json:"time"`
+	Bytes int64     `json:"bytes"`
+	Files int       `json:"files"`
+	// Children holds the total size of each top-level entry of the root.
+	Children map[string]int64 `json:"children,omitempty"`
+}
+
+// Tracker stores samples per root.
+type Tracker struct {
+	mu      sync.Mutex
+	path    string
+	samples map[string][]Sample
+	logger  *slog.Logger
+}
+
+// NewTracker creates a tracker persisting to path, loading any samples
+// already stored there. An empty path keeps samples in memory only.
+func NewTracker(path string, logger *slog.Logger) (*Tracker, error) {
+	t := &Tracker{
+		path:    path,
+		samples: make(map[string][]Sample),
+		logger:  logger,
+	}
+	if path == "" {
+		return t, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return t, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &t.samples); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Record appends a sample for root and persists the state file.
+func (t *Tracker) Record(root string, s Sample) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	samples := append(t.samples[root], s)
+	if len(samples) > MaxSamples {
+		samples = samples[len(samples)-MaxSamples:]
+	}
+	t.samples[root] = samples
+	return t.saveLocked()
+}
+
+// Samples returns a copy of the samples recorded for root, oldest first.
+func (t *Tracker) Samples(root string) []Sample {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Sample(nil), t.samples[root]...)
+}
+
+// SampleRoots measures and records each root, logging failures.
+func (t *Tracker) SampleRoots(roots []string) {
+	for _, root := range roots {
+		s, err := Measure(root)
+		if err != nil {
+			t.logger.Warn("failed to measure disk usage", "root", root, "error", err)
+			continue
+		}
+		if err := t.Record(root, s); err != nil {
+			t.logger.Warn("failed to record disk usage", "root", root, "error", err)
+		}
+	}
+}
+
+// Run samples the roots returned by roots immediately and then every
+// interval until ctx is cancelled.
+func (t *Tracker) Run(ctx context.Context, roots func() []string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	t.SampleRoots(roots())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.SampleRoots(roots())
+		}
+	}
+}
+
+// saveLocked writes the state file atomically.
+func (t *Tracker) saveLocked() error {
+	if t.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(t.samples)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(t.path), 0700); err != nil {
+		return err
+	}
+	tmp := t.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, t.path)
+}
+
+// Measure walks root and totals the size of its regular files. Symlinks are
+// not followed and unreadable entries are skipped.
+func Measure(root string) (Sample, error) {
+	s := Sample{
+		Time:     time.Now(),
+		Children: make(map[string]int64),
+	}
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+
+		s.Bytes += info.Size()
+		s.Files++
+		rel, err := filepath.Rel(root, path)
+		if err == nil {
+			child, _, _ := strings.Cut(filepath.ToSlash(rel), "/")
+			s.Children[child] += info.Size()
+		}
+		return nil
+	})
+	return s, err
+}
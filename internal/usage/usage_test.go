@@ -0,0 +1,85 @@
+package usage
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+func TestMeasure(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "a.txt"), []byte("12345"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "sub", "b.txt"), []byte("123"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Measure(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Bytes != 8 || s.Files != 2 {
+		t.Errorf("expected 8 bytes in 2 files, got %d bytes in %d files", s.Bytes, s.Files)
+	}
+	if s.Children["a.txt"] != 5 || s.Children["sub"] != 3 {
+		t.Errorf("unexpected children: %v", s.Children)
+	}
+
+	if _, err := Measure(filepath.Join(root, "missing")); err == nil {
+		t.Error("expected error for missing root")
+	}
+}
+
+func TestTrackerPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "usage.json")
+
+	tr, err := NewTracker(path, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	if err := tr.Record("/root", Sample{Time: now, Bytes: 10}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Record("/root", Sample{Time: now.Add(time.Hour), Bytes: 20}); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewTracker(path, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples := reopened.Samples("/root")
+	if len(samples) != 2 || samples[1].Bytes != 20 {
+		t.Errorf("expected persisted samples, got %+v", samples)
+	}
+}
+
+func TestTrackerCapsSamples(t *testing.T) {
+	tr, err := NewTracker("", testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < MaxSamples+10; i++ {
+		if err := tr.Record("/root", Sample{Bytes: int64(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	samples := tr.Samples("/root")
+	if len(samples) != MaxSamples {
+		t.Fatalf("expected %d samples, got %d", MaxSamples, len(samples))
+	}
+	if samples[0].Bytes != 10 {
+		t.Errorf("expected oldest samples to be dropped, first is %d", samples[0].Bytes)
+	}
+}
@@ -0,0 +1,162 @@
+// Package validate checks the syntax of JSON, YAML, and TOML documents and
+// locates the errors it finds.
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Syntax is a document syntax that can be checked.
+type Syntax string
+
+// Supported syntaxes.
+const (
+	JSON Syntax = "json"
+	YAML Syntax = "yaml"
+	TOML Syntax = "toml"
+)
+
+// ParseSyntax parses a syntax name.
+func ParseSyntax(name string) (Syntax, error) {
+	switch s := Syntax(strings.ToLower(name)); s {
+	case JSON, YAML, TOML:
+		return s, nil
+	case "yml":
+		return YAML, nil
+	}
+	return "", fmt.Errorf("unsupported syntax %q: expected json, yaml, or toml", name)
+}
+
+// SyntaxForPath infers a syntax from a file extension.
+func SyntaxForPath(path string) (Syntax, error) {
+	ext := strings.TrimPrefix(filepath.Ext(path), ".")
+	if ext == "" {
+		return "", fmt.Errorf("cannot infer syntax of %s from its extension", path)
+	}
+	return ParseSyntax(ext)
+}
+
+// Name returns the conventional spelling of s, such as "JSON".
+func (s Syntax) Name() string {
+	return strings.ToUpper(string(s))
+}
+
+// Error is a syntax error. Line and Column count from 1; either is 0 when
+// the parser does not report it.
+type Error struct {
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e Error) String() string {
+	switch {
+	case e.Line > 0 && e.Column > 0:
+		return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+	case e.Line > 0:
+		return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+	}
+	return e.Message
+}
+
+// Check parses data as s and returns the errors found, or nil if it is
+// valid. The parsers stop at the first syntax error, so there is usually
+// at most one; YAML can also report several duplicate keys.
+func Check(data []byte, s Syntax) []Error {
+	switch s {
+	case JSON:
+		return checkJSON(data)
+	case YAML:
+		return checkYAML(data)
+	case TOML:
+		return checkTOML(data)
+	}
+	return []Error{{Message: fmt.Sprintf("unsupported syntax %q", s)}}
+}
+
+func checkJSON(data []byte) []Error {
+	var v any
+	err := json.Unmarshal(data, &v)
+	var syntaxErr *json.SyntaxError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &syntaxErr):
+		// Offset counts the bytes read, including the one that was wrong
+		line, col := position(data, max(0, int(syntaxErr.Offset)-1))
+		if syntaxErr.Offset >= int64(len(data)) {
+			line, col = position(data, len(data))
+		}
+		return []Error{{Line: line, Column: col, Message: syntaxErr.Error()}}
+	}
+	return []Error{{Message: err.Error()}}
+}
+
+// yamlLine matches the line number yaml.v3 puts at the start of messages.
+var yamlLine = regexp.MustCompile(`^(?:yaml: )?line (\d+): `)
+
+func checkYAML(data []byte) []Error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		// Decoding into a value, not a node, also rejects duplicate keys
+		var v any
+		err := dec.Decode(&v)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		var typeErr *yaml.TypeError
+		switch {
+		case err == nil:
+			continue
+		case errors.As(err, &typeErr):
+			errs := make([]Error, len(typeErr.Errors))
+			for i, msg := range typeErr.Errors {
+				errs[i] = yamlError(msg)
+			}
+			return errs
+		}
+		return []Error{yamlError(err.Error())}
+	}
+}
+
+func yamlError(msg string) Error {
+	m := yamlLine.FindStringSubmatch(msg)
+	if m == nil {
+		return Error{Message: strings.TrimPrefix(msg, "yaml: ")}
+	}
+	line, _ := strconv.Atoi(m[1])
+	return Error{Line: line, Message: msg[len(m[0]):]}
+}
+
+func checkTOML(data []byte) []Error {
+	var v map[string]any
+	_, err := toml.Decode(string(data), &v)
+	var parseErr toml.ParseError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &parseErr):
+		return []Error{{Line: parseErr.Position.Line, Column: parseErr.Position.Col, Message: parseErr.Message}}
+	}
+	return []Error{{Message: strings.TrimPrefix(err.Error(), "toml: ")}}
+}
+
+// position returns the line and column, counting characters, of the byte at
+// offset in data.
+func position(data []byte, offset int) (line, col int) {
+	before := data[:offset]
+	start := bytes.LastIndexByte(before, '\n') + 1
+	return bytes.Count(before, []byte{'\n'}) + 1, utf8.RuneCount(before[start:]) + 1
+}
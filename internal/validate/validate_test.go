@@ -0,0 +1,52 @@
+package validate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		syntax Syntax
+		data   string
+		want   []Error
+	}{
+		{"valid json", JSON, `{"a": [1, 2]}`, nil},
+		{"json", JSON, "{\n  \"a\": 1,\n  \"b\" 2\n}", []Error{{Line: 3, Column: 7, Message: "invalid character '2' after object key"}}},
+		{"json truncated", JSON, "{\n  \"a\": [1,", []Error{{Line: 2, Column: 11, Message: "unexpected end of JSON input"}}},
+		{"json trailing", JSON, "{}\n{}", []Error{{Line: 2, Column: 1, Message: "invalid character '{' after top-level value"}}},
+		{"json multibyte", JSON, `{"é": x}`, []Error{{Line: 1, Column: 7, Message: "invalid character 'x' looking for beginning of value"}}},
+		{"valid yaml", YAML, "a: 1\n---\nb: [1, 2]\n", nil},
+		{"empty yaml", YAML, "", nil},
+		{"yaml", YAML, "a: 1\nb: c: 3\n", []Error{{Line: 2, Message: "mapping values are not allowed in this context"}}},
+		{"yaml duplicate", YAML, "a: 1\nb: 2\na: 3\nb: 4\n", []Error{
+			{Line: 3, Message: `mapping key "a" already defined at line 1`},
+			{Line: 4, Message: `mapping key "b" already defined at line 2`},
+		}},
+		{"valid toml", TOML, "title = \"x\"\n[server]\nport = 80\n", nil},
+		{"toml", TOML, "title = \"x\"\nport = \n", []Error{{Line: 2, Column: 8, Message: "expected value but found '\\n' instead"}}},
+		{"toml duplicate", TOML, "a = 1\na = 2\n", []Error{{Line: 2, Column: 7, Message: "Key 'a' has already been defined."}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Check([]byte(tt.data), tt.syntax); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyntaxForPath(t *testing.T) {
+	for path, want := range map[string]Syntax{"a.json": JSON, "b.YML": YAML, "c.yaml": YAML, "Cargo.toml": TOML} {
+		if got, err := SyntaxForPath(path); err != nil || got != want {
+			t.Errorf("SyntaxForPath(%q) = %q, %v; want %q", path, got, err, want)
+		}
+	}
+	for _, path := range []string{"Makefile", "data.csv"} {
+		if _, err := SyntaxForPath(path); err == nil || !strings.Contains(err.Error(), path[strings.LastIndex(path, ".")+1:]) {
+			t.Errorf("SyntaxForPath(%q) = %v, want an error", path, err)
+		}
+	}
+}
@@ -8,27 +8,56 @@ import "fmt"
 // FileInfo contains metadata about a file or directory.
 // It is returned by the get_file_info tool and provides details such as
 // size, timestamps, type (file/directory), and Unix permissions.
+// MIMEType and IsBinary are only set for files, from their content, and
+// Lock only for locked files.
 type FileInfo struct {
-	Size        int64  `json:"size"`
-	Created     string `json:"created,omitempty"`
-	Modified    string `json:"modified"`
-	Accessed    string `json:"accessed,omitempty"`
-	IsDirectory bool   `json:"isDirectory"`
-	IsFile      bool   `json:"isFile"`
-	Permissions string `json:"permissions"`
+	Size        int64     `json:"size"`
+	Created     string    `json:"created,omitempty"`
+	Modified    string    `json:"modified"`
+	Accessed    string    `json:"accessed,omitempty"`
+	IsDirectory bool      `json:"isDirectory"`
+	IsFile      bool      `json:"isFile"`
+	Permissions string    `json:"permissions"`
+	MIMEType    string    `json:"mimeType,omitempty"`
+	IsBinary    *bool     `json:"isBinary,omitempty"`
+	Lock        *LockInfo `json:"lock,omitempty"`
+}
+
+// LockInfo describes an advisory lock on a file. Holder is "server" for a
+// lock taken with lock_file, with its owner and expiry, or "external" for
+// a lock held by another process.
+type LockInfo struct {
+	Holder  string `json:"holder"`
+	Owner   string `json:"owner,omitempty"`
+	Expires string `json:"expires,omitempty"`
 }
 
 func (f FileInfo) String() string {
-	return fmt.Sprintf("FileInfo{Size:%d, Created:%q, Modified:%q, Accessed:%q, IsDirectory:%t, IsFile:%t, Permissions:%q}", f.Size, f.Created, f.Modified, f.Accessed, f.IsDirectory, f.IsFile, f.Permissions)
+	binary := "<nil>"
+	if f.IsBinary != nil {
+		binary = fmt.Sprint(*f.IsBinary)
+	}
+	lock := "<nil>"
+	if f.Lock != nil {
+		lock = fmt.Sprintf("%+v", *f.Lock)
+	}
+	return fmt.Sprintf("FileInfo{Size:%d, Created:%q, Modified:%q, Accessed:%q, IsDirectory:%t, IsFile:%t, Permissions:%q, MIMEType:%q, IsBinary:%s, Lock:%s}", f.Size, f.Created, f.Modified, f.Accessed, f.IsDirectory, f.IsFile, f.Permissions, f.MIMEType, binary, lock)
 }
 
 // TreeEntry represents a node in a directory tree structure.
 // It is returned by the directory_tree tool and recursively contains
-// child entries for directories. Type is either "file" or "directory".
+// child entries for directories. Type is "file", "directory", or
+// "inaccessible" for an entry that could not be read, whose Error says why.
+// Size, Modified, and Permissions are only set when metadata is requested,
+// and Size only for files.
 type TreeEntry struct {
-	Name     string       `json:"name"`
-	Type     string       `json:"type"` // "file" or "directory"
-	Children []*TreeEntry `json:"children,omitempty"`
+	Name        string       `json:"name"`
+	Type        string       `json:"type"` // "file", "directory", or "inaccessible"
+	Error       string       `json:"error,omitempty"`
+	Size        *int64       `json:"size,omitempty"`
+	Modified    string       `json:"modified,omitempty"`
+	Permissions string       `json:"permissions,omitempty"`
+	Children    []*TreeEntry `json:"children,omitempty"`
 }
 
 func (t TreeEntry) String() string {
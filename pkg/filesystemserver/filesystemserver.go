@@ -0,0 +1,279 @@
+// Package filesystemserver builds the filesystem MCP server for use inside
+// other Go programs. New returns a configured MCP server to serve as is,
+// and Tools returns the same tools with their handlers to add to a server
+// of your own. Both apply the same path validation and policies as the
+// filesystem command.
+package filesystemserver
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/portertech/filesystem-mcp-server/internal/confirm"
+	"github.com/portertech/filesystem-mcp-server/internal/membudget"
+	"github.com/portertech/filesystem-mcp-server/internal/overlay"
+	"github.com/portertech/filesystem-mcp-server/internal/registry"
+	"github.com/portertech/filesystem-mcp-server/internal/security"
+	fsserver "github.com/portertech/filesystem-mcp-server/internal/server"
+	"github.com/portertech/filesystem-mcp-server/internal/statedir"
+	"github.com/portertech/filesystem-mcp-server/internal/tools"
+)
+
+// config is what the options set.
+type config struct {
+	dirs            []string
+	logger          *slog.Logger
+	aliases         map[string]string
+	readOnly        []string
+	confirm         []string
+	limits          registry.Limits
+	memoryBudget    int64
+	overlayDir      string
+	strictFilenames bool
+	modes           *registry.Modes
+	allowChown      bool
+	stateDir        string
+	version         string
+	tools           []string
+}
+
+// Option configures the server.
+type Option func(*config)
+
+// WithAllowedDirectories sets the directories the tools may access. Without
+// any, every path is refused.
+func WithAllowedDirectories(dirs ...string) Option {
+	return func(c *config) {
+		c.dirs = append(c.dirs, dirs...)
+	}
+}
+
+// WithLogger sets the logger. Without one, nothing is logged.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *config) {
+		c.logger = logger
+	}
+}
+
+// WithAlias lets clients write paths under dir as name:/relative/path.
+func WithAlias(name, dir string) Option {
+	return func(c *config) {
+		if c.aliases == nil {
+			c.aliases = make(map[string]string)
+		}
+		c.aliases[name] = dir
+	}
+}
+
+// WithReadOnlyDirectories makes allowed directories read-only.
+func WithReadOnlyDirectories(dirs ...string) Option {
+	return func(c *config) {
+		c.readOnly = append(c.readOnly, dirs...)
+	}
+}
+
+// WithConfirmation makes the destructive operations of the named tools
+// require a confirmation token, as -confirm does.
+func WithConfirmation(toolNames ...string) Option {
+	return func(c *config) {
+		c.confirm = append(c.confirm, toolNames...)
+	}
+}
+
+// WithDeleteLimits refuses deletions of more than maxFiles files or
+// maxBytes bytes in one call without force. Zero disables a limit.
+func WithDeleteLimits(maxFiles int, maxBytes int64) Option {
+	return func(c *config) {
+		c.limits = registry.Limits{MaxDeleteFiles: maxFiles, MaxDeleteBytes: maxBytes}
+	}
+}
+
+// WithMemoryBudget caps the bytes of file content that whole file reads
+// buffer at once across in-flight calls.
+func WithMemoryBudget(bytes int64) Option {
+	return func(c *config) {
+		c.memoryBudget = bytes
+	}
+}
+
+// WithOverlay stages all writes in dir, which must be outside the allowed
+// directories, until they are committed.
+func WithOverlay(dir string) Option {
+	return func(c *config) {
+		c.overlayDir = dir
+	}
+}
+
+// WithStrictFilenames refuses to create files and directories whose names
+// are not portable across Linux, macOS, and Windows.
+func WithStrictFilenames() Option {
+	return func(c *config) {
+		c.strictFilenames = true
+	}
+}
+
+// WithModes sets the permissions of files and directories the tools
+// create, in place of 0644 and 0755. The process umask may restrict them
+// further.
+func WithModes(file, dir os.FileMode) Option {
+	return func(c *config) {
+		c.modes = &registry.Modes{File: file, Dir: dir}
+	}
+}
+
+// WithChangeOwner registers the change_owner tool. It is only supported on
+// Unix.
+func WithChangeOwner() Option {
+	return func(c *config) {
+		c.allowChown = true
+	}
+}
+
+// WithStateDir sets the directory where state such as annotations and
+// saved searches persists between runs. A directory written by an earlier
+// release is migrated to the current layout. Without it, state is kept in
+// memory only.
+func WithStateDir(dir string) Option {
+	return func(c *config) {
+		c.stateDir = dir
+	}
+}
+
+// WithVersion sets the version reported to clients.
+func WithVersion(version string) Option {
+	return func(c *config) {
+		c.version = version
+	}
+}
+
+// WithTools registers only the named tools instead of all of them.
+func WithTools(names ...string) Option {
+	return func(c *config) {
+		c.tools = append(c.tools, names...)
+	}
+}
+
+// New returns an MCP server with the filesystem tools, configured by opts.
+// Serve it with any mcp-go transport, such as server.ServeStdio.
+func New(opts ...Option) (*server.MCPServer, error) {
+	s, err := build(opts)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetMCPServer(), nil
+}
+
+// Tools returns the filesystem tools with their handlers, configured by
+// opts, for adding to an existing MCP server with AddTools.
+func Tools(opts ...Option) ([]server.ServerTool, error) {
+	s, err := build(opts)
+	if err != nil {
+		return nil, err
+	}
+	return s.Tools(), nil
+}
+
+// build validates the configuration and creates the server.
+func build(opts []Option) (*fsserver.Server, error) {
+	c := &config{version: "1.0.0"}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.logger == nil {
+		c.logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+
+	reg := registry.New(c.dirs, c.logger)
+	if len(c.aliases) > 0 {
+		if err := reg.SetAliases(c.aliases); err != nil {
+			return nil, fmt.Errorf("invalid alias: %w", err)
+		}
+	}
+	if len(c.readOnly) > 0 {
+		if err := reg.SetReadOnly(c.readOnly); err != nil {
+			return nil, fmt.Errorf("invalid read-only directory: %w", err)
+		}
+	}
+	if c.overlayDir != "" {
+		ov, err := overlay.New(c.overlayDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize overlay: %w", err)
+		}
+		if security.IsPathWithinAllowedDirectories(ov.Dir(), reg.GetResolved()) {
+			return nil, fmt.Errorf("overlay directory must be outside the allowed directories: %s", ov.Dir())
+		}
+		reg.SetOverlay(ov)
+	}
+	if c.memoryBudget < 0 {
+		return nil, fmt.Errorf("memory budget must not be negative: %d", c.memoryBudget)
+	}
+	if c.memoryBudget > 0 {
+		reg.SetMemoryBudget(membudget.New(c.memoryBudget, membudget.DefaultMaxWait))
+	}
+	reg.SetLimits(c.limits)
+	reg.SetStrictFilenames(c.strictFilenames)
+	if c.modes != nil {
+		if c.modes.File&^os.ModePerm != 0 || c.modes.Dir&^os.ModePerm != 0 {
+			return nil, fmt.Errorf("modes must be permission bits only: %v, %v", c.modes.File, c.modes.Dir)
+		}
+		reg.SetModes(*c.modes)
+	}
+	if c.allowChown {
+		if !tools.ChownSupported {
+			return nil, fmt.Errorf("change_owner is only supported on Unix")
+		}
+		reg.SetAllowChown(true)
+	}
+	if len(c.confirm) > 0 {
+		for _, name := range c.confirm {
+			if _, ok := tools.ConfirmableTools[name]; !ok {
+				return nil, fmt.Errorf("tool does not support confirmation: %s", name)
+			}
+		}
+		reg.SetConfirmations(confirm.New(c.confirm, confirm.DefaultTTL))
+	}
+
+	// The server is handed the resolved path, not the one configured,
+	// which may be relative
+	var stateDir string
+	if c.stateDir != "" {
+		state, err := statedir.Open(c.stateDir, c.logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open state directory: %w", err)
+		}
+		if security.IsPathWithinAllowedDirectories(state.Path(), reg.GetResolved()) {
+			return nil, fmt.Errorf("state directory must be outside the allowed directories: %s", state.Path())
+		}
+		stateDir = state.Path()
+	}
+
+	serverOpts := []fsserver.Option{
+		fsserver.WithStateDir(stateDir),
+		fsserver.WithVersion(c.version),
+	}
+	if c.tools != nil {
+		serverOpts = append(serverOpts, fsserver.WithTools(c.tools...))
+	}
+	s := fsserver.New(reg, c.logger, serverOpts...)
+
+	// A misspelled or unavailable tool would otherwise go unnoticed
+	registered := make(map[string]bool)
+	for _, name := range s.ToolNames() {
+		registered[name] = true
+	}
+	var missing []string
+	for _, name := range c.tools {
+		if !registered[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("unknown or unavailable tools: %v", missing)
+	}
+	return s, nil
+}
@@ -0,0 +1,76 @@
+package filesystemserver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestNew(t *testing.T) {
+	dir := t.TempDir()
+	s, err := New(WithAllowedDirectories(dir), WithVersion("2.0.0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s == nil {
+		t.Fatal("expected a server")
+	}
+}
+
+func TestTools(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("hello"), 0644)
+	os.WriteFile(filepath.Join(outside, "b.txt"), []byte("secret"), 0644)
+
+	serverTools, err := Tools(WithAllowedDirectories(dir), WithTools("read_text_file", "write_file"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(serverTools) != 2 || serverTools[0].Tool.Name != "read_text_file" || serverTools[1].Tool.Name != "write_file" {
+		t.Fatalf("unexpected tools: %v", serverTools)
+	}
+
+	read := func(path string) *mcp.CallToolResult {
+		var req mcp.CallToolRequest
+		req.Params.Arguments = map[string]any{"path": path}
+		result, err := serverTools[0].Handler(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+	if result := read(filepath.Join(dir, "a.txt")); result.IsError || result.Content[0].(mcp.TextContent).Text != "hello" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if result := read(filepath.Join(outside, "b.txt")); !result.IsError {
+		t.Error("expected a path outside the allowed directories to be refused")
+	}
+
+	// The tools can be added to another server
+	other := server.NewMCPServer("other", "1.0.0")
+	other.AddTools(serverTools...)
+}
+
+func TestInvalidConfiguration(t *testing.T) {
+	dir := t.TempDir()
+	for name, opts := range map[string][]Option{
+		"unknown tool":        {WithAllowedDirectories(dir), WithTools("read_text_file", "no_such_tool")},
+		"unavailable tool":    {WithAllowedDirectories(dir), WithTools("overlay_status")},
+		"unconfirmable tool":  {WithAllowedDirectories(dir), WithConfirmation("read_text_file")},
+		"overlay inside root": {WithAllowedDirectories(dir), WithOverlay(filepath.Join(dir, "overlay"))},
+		"negative budget":     {WithAllowedDirectories(dir), WithMemoryBudget(-1)},
+		"setuid mode":         {WithAllowedDirectories(dir), WithModes(os.ModeSetuid|0755, 0755)},
+	} {
+		if _, err := New(opts...); err == nil {
+			t.Errorf("%s: expected an error", name)
+		} else if name == "unknown tool" && !strings.Contains(err.Error(), "no_such_tool") {
+			t.Errorf("%s: expected the tool to be named, got %v", name, err)
+		}
+	}
+}